@@ -0,0 +1,456 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/filesync"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	ktypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/docker/buildkit"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The env variable for the address of a standalone buildkitd,
+// for users who don't configure one on the Cluster.
+const BuildkitHostEnvVar = "TILT_BUILDKIT_HOST"
+
+// The address of the buildkitd to use when the Cluster doesn't configure one.
+type BuildkitHost string
+
+func ProvideBuildkitHost() BuildkitHost {
+	return BuildkitHost(os.Getenv(BuildkitHostEnvVar))
+}
+
+// Where BuildKit sends the image once it's named.
+//
+// If neither is set, the image only lives in buildkitd's image store.
+type BuildkitDest struct {
+	// Push the image to its registry.
+	Push bool
+
+	// Write the image to this file as a Docker image archive,
+	// e.g., to load it into a KIND cluster.
+	Archive string
+}
+
+// The subset of the buildkit client that we use, so that tests
+// can fake it out.
+type buildkitSolver interface {
+	Solve(ctx context.Context, def *llb.Definition, opt bkclient.SolveOpt, statusChan chan *bkclient.SolveStatus) (*bkclient.SolveResponse, error)
+	Close() error
+}
+
+type buildkitDialer func(ctx context.Context, address string) (buildkitSolver, error)
+
+// Checks whether an image exists in its registry.
+type registryImageChecker func(ctx context.Context, ref reference.Named) (bool, error)
+
+// BuildkitBuilder builds images by talking directly to a standalone buildkitd
+// over its gRPC API, for machines that don't run a Docker daemon.
+type BuildkitBuilder struct {
+	dial         buildkitDialer
+	resolveIndex indexResolver
+	imageExists  registryImageChecker
+	defaultConn  *v1alpha1.BuildkitConnection
+}
+
+func NewBuildkitBuilder(host BuildkitHost) *BuildkitBuilder {
+	var defaultConn *v1alpha1.BuildkitConnection
+	if host != "" {
+		defaultConn = &v1alpha1.BuildkitConnection{Address: string(host)}
+	}
+	return newBuildkitBuilder(dialBuildkit, resolveRegistryIndex, registryImageExists, defaultConn)
+}
+
+func newBuildkitBuilder(dial buildkitDialer, resolveIndex indexResolver, imageExists registryImageChecker, defaultConn *v1alpha1.BuildkitConnection) *BuildkitBuilder {
+	return &BuildkitBuilder{
+		dial:         dial,
+		resolveIndex: resolveIndex,
+		imageExists:  imageExists,
+		defaultConn:  defaultConn,
+	}
+}
+
+func dialBuildkit(ctx context.Context, address string) (buildkitSolver, error) {
+	return bkclient.New(ctx, address, bkclient.WithFailFast())
+}
+
+// Connection returns the buildkitd that should build images for this cluster,
+// or nil if images should be built with Docker.
+//
+// Docker Compose always builds with Docker, because it can only run
+// images in the Docker image store.
+func (b *BuildkitBuilder) Connection(cluster *v1alpha1.Cluster) *v1alpha1.BuildkitConnection {
+	if isDockerCompose(cluster) {
+		return nil
+	}
+	if cluster != nil && cluster.Spec.Buildkit != nil {
+		return cluster.Spec.Buildkit
+	}
+	return b.defaultConn
+}
+
+// Checks whether an image that BuildKit pushed is still in the registry.
+func (b *BuildkitBuilder) PushedImageExists(ctx context.Context, ref reference.Named) (bool, error) {
+	return b.imageExists(ctx, ref)
+}
+
+// Build the image with buildkitd, then store it under its tagged name.
+//
+// If dest.Push is true, the tagged image is pushed to the registry
+// as part of the build, and reported as a separate stage.
+//
+// If the spec has multiple platforms, builds an image index with one image
+// per platform, and records the digest of each.
+func (b *BuildkitBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs container.RefSet,
+	spec v1alpha1.DockerImageSpec,
+	cluster *v1alpha1.Cluster,
+	imageMaps map[ktypes.NamespacedName]*v1alpha1.ImageMap,
+	filter model.PathMatcher,
+	dest BuildkitDest) (container.TaggedRefs, []v1alpha1.DockerImageStageStatus, error) {
+	conn := b.Connection(cluster)
+	if conn == nil {
+		return container.TaggedRefs{}, nil, fmt.Errorf("no buildkitd address configured")
+	}
+
+	spec = InjectClusterPlatform(spec, cluster)
+	spec, err := InjectImageDependencies(spec, imageMaps)
+	if err != nil {
+		return container.TaggedRefs{}, nil, err
+	}
+
+	platformSuffix := ""
+	if len(spec.Platforms) > 0 {
+		platformSuffix = fmt.Sprintf(" for platforms %s", strings.Join(spec.Platforms, ", "))
+	} else if spec.Platform != "" {
+		platformSuffix = fmt.Sprintf(" for platform %s", spec.Platform)
+	}
+	logger.Get(ctx).Infof("Building Dockerfile%s with BuildKit at %s:\n%s\n",
+		platformSuffix, conn.Address, indent(spec.DockerfileContents, "  "))
+
+	ps.StartBuildStep(ctx, "Building image")
+	ctx = ps.AttachLogger(ctx)
+
+	client, err := b.dial(ctx, conn.Address)
+	if err != nil {
+		return container.TaggedRefs{}, nil, errors.Wrapf(err, "connecting to buildkitd at %s", conn.Address)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	// The image name is derived from the image digest, so we can't name it
+	// until after it's built. The second solve is fully cached, and only does
+	// the naming (and pushing). The cache is exported along with the named image.
+	//
+	// The second solve re-syncs the build context, so we check that it
+	// produced the same image, in case a file changed in between.
+	cacheImports, err := buildkit.ParseCacheSpecs(spec.CacheFrom)
+	if err != nil {
+		return container.TaggedRefs{}, nil, errors.Wrap(err, "cache_from")
+	}
+	cacheExports, err := buildkit.ParseCacheSpecs(spec.CacheTo)
+	if err != nil {
+		return container.TaggedRefs{}, nil, errors.Wrap(err, "cache_to")
+	}
+
+	resp, stages, err := b.solve(ctx, client, spec, filter, cacheImports, nil,
+		bkclient.ExportEntry{Type: bkclient.ExporterImage})
+	if err != nil {
+		return container.TaggedRefs{}, stages, err
+	}
+	printCacheSummary(ctx, stages)
+
+	// Multi-platform images don't have a single image config, so
+	// they're tagged with the digest of the image index.
+	dig, err := digestFromExporterResponse(resp.ExporterResponse,
+		"containerimage.config.digest", "containerimage.digest")
+	if err != nil {
+		return container.TaggedRefs{}, stages, err
+	}
+
+	tag, err := digestAsTag(dig)
+	if err != nil {
+		return container.TaggedRefs{}, stages, errors.Wrap(err, "buildkit tag")
+	}
+
+	tagged, err := refs.AddTagSuffix(tag)
+	if err != nil {
+		return container.TaggedRefs{}, stages, errors.Wrap(err, "buildkit tag")
+	}
+
+	push := dest.Push
+	startTime := apis.NowMicro()
+	if push {
+		ps.Printf(ctx, "Pushing %s with BuildKit", container.FamiliarString(tagged.LocalRef))
+	}
+	exportResp, _, err := b.solve(ctx, client, spec, filter, cacheImports, cacheExports, namedExport(tagged, dest))
+	if push {
+		endTime := apis.NowMicro()
+		stage := v1alpha1.DockerImageStageStatus{
+			Name:       "buildkit push",
+			StartedAt:  &startTime,
+			FinishedAt: &endTime,
+		}
+		if err != nil {
+			stage.Error = fmt.Sprintf("buildkit push: %v", err)
+		}
+		stages = append(stages, stage)
+	}
+	if err != nil {
+		return container.TaggedRefs{}, stages, errors.Wrap(err, "buildkit export")
+	}
+
+	exported, err := digestFromExporterResponse(exportResp.ExporterResponse,
+		"containerimage.config.digest", "containerimage.digest")
+	if err != nil {
+		return container.TaggedRefs{}, stages, err
+	}
+	if exported != dig {
+		return container.TaggedRefs{}, stages, fmt.Errorf(
+			"build context changed during the build: image %s was exported as %s, not %s",
+			container.FamiliarString(tagged.LocalRef), exported, dig)
+	}
+
+	if push {
+		// The registry digest is only meaningful once the image is in the registry.
+		pushed, err := digestFromExporterResponse(exportResp.ExporterResponse, "containerimage.digest")
+		if err != nil {
+			return container.TaggedRefs{}, stages, err
+		}
+		tagged.Digest = pushed
+
+		if len(spec.Platforms) > 0 {
+			tagged.PlatformDigests, err = b.resolveIndex(ctx, tagged.LocalRef, pushed)
+			if err != nil {
+				return container.TaggedRefs{}, stages, errors.Wrap(err, "reading pushed image index")
+			}
+		}
+	}
+
+	return tagged, stages, nil
+}
+
+// The export that names the image, and sends it to its destination.
+func namedExport(tagged container.TaggedRefs, dest BuildkitDest) bkclient.ExportEntry {
+	if dest.Archive != "" {
+		return bkclient.ExportEntry{
+			Type:  bkclient.ExporterDocker,
+			Attrs: map[string]string{"name": tagged.LocalRef.String()},
+			Output: func(map[string]string) (io.WriteCloser, error) {
+				return os.Create(dest.Archive)
+			},
+		}
+	}
+	return bkclient.ExportEntry{
+		Type: bkclient.ExporterImage,
+		Attrs: map[string]string{
+			"name": tagged.LocalRef.String(),
+			"push": fmt.Sprintf("%t", dest.Push),
+		},
+	}
+}
+
+// Runs the dockerfile frontend against the build context, printing
+// progress as it goes.
+func (b *BuildkitBuilder) solve(ctx context.Context, client buildkitSolver,
+	spec v1alpha1.DockerImageSpec, filter model.PathMatcher,
+	cacheImports, cacheExports []bkclient.CacheOptionsEntry,
+	export bkclient.ExportEntry) (*bkclient.SolveResponse, []v1alpha1.DockerImageStageStatus, error) {
+	dockerfileDir, err := writeTempDockerfileSyncdir(spec.DockerfileContents)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dockerfileDir)
+	}()
+
+	attachables, err := buildkitAttachables(ctx, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := session.NewSession(ctx, "tilt", "")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "creating buildkit session")
+	}
+	s.Allow(filesync.NewFSSyncProvider(toSyncedDirs(spec.Context, dockerfileDir, filter)))
+	for _, a := range attachables {
+		s.Allow(a)
+	}
+
+	opt := bkclient.SolveOpt{
+		Exports:       []bkclient.ExportEntry{export},
+		Frontend:      "dockerfile.v0",
+		FrontendAttrs: buildkitFrontendAttrs(spec),
+		CacheImports:  cacheImports,
+		CacheExports:  cacheExports,
+		SharedSession: s,
+	}
+
+	statusCh := make(chan *bkclient.SolveStatus)
+	printer := newBuildkitPrinter(logger.Get(ctx))
+	var resp *bkclient.SolveResponse
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		var err error
+		resp, err = client.Solve(egCtx, nil, opt, statusCh)
+		return err
+	})
+	eg.Go(func() error {
+		// Keep draining the channel even if printing fails,
+		// so that Solve never blocks.
+		var printErr error
+		for status := range statusCh {
+			err := printer.parseAndPrint(toVertexesFromSolveStatus(status))
+			if err != nil && printErr == nil {
+				printErr = err
+			}
+		}
+		return printErr
+	})
+
+	err = eg.Wait()
+	if err != nil {
+		return nil, printer.toStageStatuses(), errors.New(cleanupDockerBuildError(err.Error()))
+	}
+	return resp, printer.toStageStatuses(), nil
+}
+
+func buildkitAttachables(ctx context.Context, spec v1alpha1.DockerImageSpec) ([]session.Attachable, error) {
+	result := []session.Attachable{
+		authprovider.NewDockerAuthProvider(logger.Get(ctx).Writer(logger.InfoLvl)),
+	}
+
+	if len(spec.Secrets) > 0 {
+		ss, err := buildkit.ParseSecretSpecs(spec.Secrets)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse secret: %v", spec.Secrets)
+		}
+		result = append(result, ss)
+	}
+
+	if len(spec.SSHAgentConfigs) > 0 {
+		sshp, err := buildkit.ParseSSHSpecs(spec.SSHAgentConfigs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse ssh: %v", spec.SSHAgentConfigs)
+		}
+		result = append(result, sshp)
+	}
+	return result, nil
+}
+
+// Translates the DockerImageSpec into options for the dockerfile frontend.
+//
+// These are the same options that the Docker CLI sends when it builds
+// with BuildKit.
+func buildkitFrontendAttrs(spec v1alpha1.DockerImageSpec) map[string]string {
+	attrs := map[string]string{
+		"filename": DockerfileName,
+	}
+	if spec.Target != "" {
+		attrs["target"] = spec.Target
+	}
+	if len(spec.Platforms) > 0 {
+		attrs["platform"] = strings.Join(spec.Platforms, ",")
+	} else if spec.Platform != "" {
+		attrs["platform"] = spec.Platform
+	}
+	if spec.Network != "" {
+		attrs["force-network-mode"] = spec.Network
+	}
+	if spec.Pull {
+		attrs["image-resolve-mode"] = "pull"
+	}
+	for _, arg := range spec.Args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) == 2 {
+			attrs["build-arg:"+parts[0]] = parts[1]
+		} else if v, ok := os.LookupEnv(parts[0]); ok {
+			// Match the Docker CLI, which fills in args without
+			// a value from the environment.
+			attrs["build-arg:"+parts[0]] = v
+		}
+	}
+	for k, v := range docker.BuiltByTiltLabel {
+		attrs["label:"+k] = v
+	}
+	return attrs
+}
+
+// The image exporter reports the digest of the image config (i.e., the image
+// ID that Docker would show), and the digest of the image manifest or index.
+//
+// Returns the digest under the first key that's present.
+func digestFromExporterResponse(resp map[string]string, keys ...string) (digest.Digest, error) {
+	for _, key := range keys {
+		if v := resp[key]; v != "" {
+			dig, err := digest.Parse(v)
+			if err != nil {
+				return "", errors.Wrapf(err, "parsing buildkit digest %q", v)
+			}
+			return dig, nil
+		}
+	}
+	return "", fmt.Errorf("buildkit response did not contain an image digest")
+}
+
+func toVertexesFromSolveStatus(s *bkclient.SolveStatus) ([]*vertex, []*vertexLog, []*vertexStatus) {
+	vertexes := []*vertex{}
+	logs := []*vertexLog{}
+	statuses := []*vertexStatus{}
+
+	for _, v := range s.Vertexes {
+		duration := time.Duration(0)
+		started := v.Started != nil
+		completed := v.Completed != nil
+		if started && completed {
+			duration = (*v.Completed).Sub((*v.Started))
+		}
+		vertexes = append(vertexes, &vertex{
+			digest:        v.Digest,
+			name:          v.Name,
+			error:         v.Error,
+			started:       started,
+			completed:     completed,
+			cached:        v.Cached,
+			duration:      duration,
+			startedTime:   v.Started,
+			completedTime: v.Completed,
+		})
+	}
+	for _, v := range s.Logs {
+		logs = append(logs, &vertexLog{
+			vertex: v.Vertex,
+			msg:    v.Data,
+		})
+	}
+	for _, v := range s.Statuses {
+		statuses = append(statuses, &vertexStatus{
+			vertex:    v.Vertex,
+			id:        v.ID,
+			total:     v.Total,
+			current:   v.Current,
+			timestamp: v.Timestamp,
+		})
+	}
+	return vertexes, logs, statuses
+}
@@ -0,0 +1,274 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestBuildkitConnection(t *testing.T) {
+	global := &v1alpha1.BuildkitConnection{Address: "tcp://global:1234"}
+	local := &v1alpha1.BuildkitConnection{Address: "tcp://local:1234"}
+	b := newBuildkitBuilder(nil, nil, nil, global)
+
+	assert.Equal(t, global, b.Connection(nil))
+	assert.Equal(t, local, b.Connection(&v1alpha1.Cluster{
+		Spec: v1alpha1.ClusterSpec{Buildkit: local},
+	}))
+	assert.Nil(t, b.Connection(&v1alpha1.Cluster{
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{Docker: &v1alpha1.DockerClusterConnection{}},
+			Buildkit:   local,
+		},
+	}))
+	assert.Nil(t, newBuildkitBuilder(nil, nil, nil, nil).Connection(nil))
+}
+
+func TestBuildkitBuildAndPush(t *testing.T) {
+	f := newBuildkitFixture(t)
+
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM alpine",
+		Context:            f.Path(),
+		Target:             "dev",
+		Platform:           "linux/arm64",
+		Args:               []string{"GREETING=hello"},
+		CacheFrom:          []string{"gcr.io/foo/cache"},
+		CacheTo:            []string{"type=local,dest=/tmp/cache"},
+	}
+	refs, stages, err := f.b.BuildImage(f.ctx, f.ps, container.MustSimpleRefSet(container.MustParseSelector("gcr.io/foo/bar")),
+		spec, nil, nil, model.EmptyMatcher, BuildkitDest{Push: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "tcp://buildkitd:1234", f.solver.address)
+	assert.Equal(t, "gcr.io/foo/bar:tilt-cc5f4c463f81c551", refs.LocalRef.String())
+
+	require.Len(t, f.solver.opts, 2)
+	build := f.solver.opts[0]
+	assert.Equal(t, "dockerfile.v0", build.Frontend)
+	assert.Equal(t, "dev", build.FrontendAttrs["target"])
+	assert.Equal(t, "linux/arm64", build.FrontendAttrs["platform"])
+	assert.Equal(t, "hello", build.FrontendAttrs["build-arg:GREETING"])
+	assert.Equal(t, []bkclient.CacheOptionsEntry{
+		{Type: "registry", Attrs: map[string]string{"ref": "gcr.io/foo/cache"}},
+	}, build.CacheImports)
+	assert.Empty(t, build.CacheExports)
+	assert.Equal(t, bkclient.ExporterImage, build.Exports[0].Type)
+	assert.Empty(t, build.Exports[0].Attrs["name"])
+
+	export := f.solver.opts[1]
+	assert.Equal(t, []bkclient.CacheOptionsEntry{
+		{Type: "local", Attrs: map[string]string{"dest": "/tmp/cache"}},
+	}, export.CacheExports)
+	assert.Equal(t, map[string]string{
+		"name": "gcr.io/foo/bar:tilt-cc5f4c463f81c551",
+		"push": "true",
+	}, export.Exports[0].Attrs)
+
+	var names []string
+	for _, s := range stages {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"[1/1] FROM alpine", "buildkit push"}, names)
+	assert.True(t, stages[0].Cached)
+
+	assert.Equal(t, "sha256:dd5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1", refs.Digest.String())
+	assert.Empty(t, refs.PlatformDigests)
+}
+
+func TestBuildkitMultiPlatform(t *testing.T) {
+	f := newBuildkitFixture(t)
+	f.solver.response = map[string]string{
+		"containerimage.digest": "sha256:dd5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1",
+	}
+
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM alpine",
+		Context:            f.Path(),
+		Platforms:          []string{"linux/amd64", "linux/arm64"},
+	}
+	cluster := &v1alpha1.Cluster{Status: v1alpha1.ClusterStatus{Arch: "amd64"}}
+	refs, _, err := f.b.BuildImage(f.ctx, f.ps, container.MustSimpleRefSet(container.MustParseSelector("gcr.io/foo/bar")),
+		spec, cluster, nil, model.EmptyMatcher, BuildkitDest{Push: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "linux/amd64,linux/arm64", f.solver.opts[0].FrontendAttrs["platform"])
+
+	// Multi-platform images are tagged by the index digest.
+	assert.Equal(t, "gcr.io/foo/bar:tilt-dd5f4c463f81c551", refs.LocalRef.String())
+	assert.Equal(t, "sha256:dd5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1", refs.Digest.String())
+	assert.Equal(t, []string{
+		"gcr.io/foo/bar:tilt-dd5f4c463f81c551@sha256:dd5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1",
+	}, f.indexQueries)
+	assert.Equal(t, []v1alpha1.ImageMapPlatformDigest{
+		{Platform: "linux/amd64", Digest: "sha256:aaaa"},
+		{Platform: "linux/arm64", Digest: "sha256:bbbb"},
+	}, refs.PlatformDigests)
+}
+
+func TestBuildkitBuildToArchive(t *testing.T) {
+	f := newBuildkitFixture(t)
+
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM alpine",
+		Context:            f.Path(),
+	}
+	archive := f.JoinPath("image.tar")
+	refs, stages, err := f.b.BuildImage(f.ctx, f.ps, container.MustSimpleRefSet(container.MustParseSelector("gcr.io/foo/bar")),
+		spec, nil, nil, model.EmptyMatcher, BuildkitDest{Archive: archive})
+	require.NoError(t, err)
+	assert.Equal(t, "gcr.io/foo/bar:tilt-cc5f4c463f81c551", refs.LocalRef.String())
+
+	require.Len(t, f.solver.opts, 2)
+	export := f.solver.opts[1].Exports[0]
+	assert.Equal(t, bkclient.ExporterDocker, export.Type)
+	assert.Equal(t, map[string]string{"name": "gcr.io/foo/bar:tilt-cc5f4c463f81c551"}, export.Attrs)
+
+	w, err := export.Output(nil)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.FileExists(t, archive)
+
+	for _, s := range stages {
+		assert.NotEqual(t, "buildkit push", s.Name)
+	}
+}
+
+func TestPlatformDigestsFromIndex(t *testing.T) {
+	index := ocispec.Index{
+		Manifests: []ocispec.Descriptor{
+			{Digest: "sha256:aaaa", Platform: &ocispec.Platform{OS: "linux", Architecture: "amd64"}},
+			{Digest: "sha256:bbbb", Platform: &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+			{Digest: "sha256:cccc", Platform: &ocispec.Platform{OS: "unknown", Architecture: "unknown"}},
+		},
+	}
+	assert.Equal(t, []v1alpha1.ImageMapPlatformDigest{
+		{Platform: "linux/amd64", Digest: "sha256:aaaa"},
+		{Platform: "linux/arm64/v8", Digest: "sha256:bbbb"},
+	}, platformDigestsFromIndex(index))
+}
+
+func TestBuildkitBuildNoDigest(t *testing.T) {
+	f := newBuildkitFixture(t)
+	f.solver.response = map[string]string{}
+
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM alpine",
+		Context:            f.Path(),
+	}
+	_, _, err := f.b.BuildImage(f.ctx, f.ps, container.MustSimpleRefSet(container.MustParseSelector("gcr.io/foo/bar")),
+		spec, nil, nil, model.EmptyMatcher, BuildkitDest{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "did not contain an image digest")
+	}
+}
+
+func TestBuildkitContextChangedBetweenSolves(t *testing.T) {
+	f := newBuildkitFixture(t)
+	f.solver.exportResponse = map[string]string{
+		"containerimage.config.digest": "sha256:ee5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1",
+		"containerimage.digest":        "sha256:ff5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1",
+	}
+
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM alpine",
+		Context:            f.Path(),
+	}
+	_, _, err := f.b.BuildImage(f.ctx, f.ps, container.MustSimpleRefSet(container.MustParseSelector("gcr.io/foo/bar")),
+		spec, nil, nil, model.EmptyMatcher, BuildkitDest{Push: true})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "build context changed during the build")
+	}
+}
+
+type fakeBuildkitSolver struct {
+	address  string
+	opts     []bkclient.SolveOpt
+	response map[string]string
+
+	// If set, the response to the export solve.
+	exportResponse map[string]string
+}
+
+func (s *fakeBuildkitSolver) Solve(ctx context.Context, def *llb.Definition, opt bkclient.SolveOpt, statusChan chan *bkclient.SolveStatus) (*bkclient.SolveResponse, error) {
+	defer close(statusChan)
+	s.opts = append(s.opts, opt)
+
+	now := time.Now()
+	statusChan <- &bkclient.SolveStatus{
+		Vertexes: []*bkclient.Vertex{
+			{
+				Digest:    digest.FromString("from"),
+				Name:      "[1/1] FROM alpine",
+				Started:   &now,
+				Completed: &now,
+				Cached:    true,
+			},
+		},
+	}
+	if len(s.opts) > 1 && s.exportResponse != nil {
+		return &bkclient.SolveResponse{ExporterResponse: s.exportResponse}, nil
+	}
+	return &bkclient.SolveResponse{ExporterResponse: s.response}, nil
+}
+
+func (s *fakeBuildkitSolver) Close() error { return nil }
+
+type buildkitFixture struct {
+	*tempdir.TempDirFixture
+	ctx          context.Context
+	ps           *PipelineState
+	solver       *fakeBuildkitSolver
+	b            *BuildkitBuilder
+	indexQueries []string
+	pushedImages map[string]bool
+}
+
+func newBuildkitFixture(t *testing.T) *buildkitFixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	f := &buildkitFixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		ctx:            ctx,
+		ps:             NewPipelineState(ctx, 1, fakeClock{}),
+		pushedImages:   make(map[string]bool),
+		solver: &fakeBuildkitSolver{
+			response: map[string]string{
+				"containerimage.config.digest": "sha256:cc5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1",
+				"containerimage.digest":        "sha256:dd5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1",
+			},
+		},
+	}
+	dial := func(ctx context.Context, address string) (buildkitSolver, error) {
+		f.solver.address = address
+		return f.solver, nil
+	}
+	f.b = newBuildkitBuilder(dial, f.resolveIndex, f.imageExists, &v1alpha1.BuildkitConnection{Address: "tcp://buildkitd:1234"})
+	return f
+}
+
+func (f *buildkitFixture) resolveIndex(ctx context.Context, ref reference.Named, dig digest.Digest) ([]v1alpha1.ImageMapPlatformDigest, error) {
+	f.indexQueries = append(f.indexQueries, fmt.Sprintf("%s@%s", ref, dig))
+	return []v1alpha1.ImageMapPlatformDigest{
+		{Platform: "linux/amd64", Digest: "sha256:aaaa"},
+		{Platform: "linux/arm64", Digest: "sha256:bbbb"},
+	}, nil
+}
+
+func (f *buildkitFixture) imageExists(ctx context.Context, ref reference.Named) (bool, error) {
+	return f.pushedImages[ref.String()], nil
+}
@@ -0,0 +1,108 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/buildstats"
+	"github.com/tilt-dev/tilt/internal/docker/buildkit"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// The Docker API only understands registry refs for --cache-from,
+// and the inline cache (via a build arg) for --cache-to.
+//
+// Everything else needs a standalone BuildKit daemon.
+func validateDockerCacheSpecs(spec v1alpha1.DockerImageSpec) error {
+	from, err := buildkit.ParseCacheSpecs(spec.CacheFrom)
+	if err != nil {
+		return errors.Wrap(err, "cache_from")
+	}
+	for i, e := range from {
+		if e.Type != buildkit.CacheTypeRegistry {
+			return fmt.Errorf("cache_from %q: %s caches need a standalone BuildKit daemon. Set %s, or configure buildkit on the Cluster",
+				spec.CacheFrom[i], e.Type, BuildkitHostEnvVar)
+		}
+	}
+
+	to, err := buildkit.ParseCacheSpecs(spec.CacheTo)
+	if err != nil {
+		return errors.Wrap(err, "cache_to")
+	}
+	for i, e := range to {
+		if e.Type != buildkit.CacheTypeInline {
+			return fmt.Errorf("cache_to %q: %s caches need a standalone BuildKit daemon. Set %s, or configure buildkit on the Cluster",
+				spec.CacheTo[i], e.Type, BuildkitHostEnvVar)
+		}
+	}
+	return nil
+}
+
+// Converts cache_from specs to the image refs that the Docker API expects.
+//
+// Assumes the specs have already been validated.
+func dockerCacheFrom(specs []string) []string {
+	entries, err := buildkit.ParseCacheSpecs(specs)
+	if err != nil {
+		return specs
+	}
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.Attrs["ref"])
+	}
+	return result
+}
+
+// Docker exports an inline cache when BUILDKIT_INLINE_CACHE is set.
+func dockerInlineCache(specs []string) bool {
+	entries, err := buildkit.ParseCacheSpecs(specs)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if e.Type == buildkit.CacheTypeInline {
+			return true
+		}
+	}
+	return false
+}
+
+// Summarizes how many build steps in each Dockerfile stage were cached.
+func CacheHits(stages []v1alpha1.DockerImageStageStatus) []v1alpha1.DockerImageCacheHits {
+	var result []v1alpha1.DockerImageCacheHits
+	index := map[string]int{}
+	for _, s := range stages {
+		name, ok := buildstats.DockerfileStage(s.Name)
+		if !ok {
+			continue
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(result)
+			index[name] = i
+			result = append(result, v1alpha1.DockerImageCacheHits{Stage: name})
+		}
+		result[i].Total++
+		if s.Cached {
+			result[i].Cached++
+		}
+	}
+	return result
+}
+
+func printCacheSummary(ctx context.Context, stages []v1alpha1.DockerImageStageStatus) {
+	summary := CacheHits(stages)
+	if len(summary) == 0 {
+		return
+	}
+
+	parts := make([]string, 0, len(summary))
+	for _, s := range summary {
+		parts = append(parts, fmt.Sprintf("%s %d/%d (%d%%)", s.Stage, s.Cached, s.Total, 100*s.Cached/s.Total))
+	}
+	logger.Get(ctx).Infof("Cache hits: %s", strings.Join(parts, ", "))
+}
@@ -0,0 +1,66 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestValidateDockerCacheSpecs(t *testing.T) {
+	type tc struct {
+		cacheFrom []string
+		cacheTo   []string
+		expected  string
+	}
+	tcs := []tc{
+		{cacheFrom: []string{"gcr.io/foo/cache", "type=registry,ref=gcr.io/foo/cache2"}},
+		{cacheTo: []string{"type=inline"}},
+		{cacheFrom: []string{"type=local,src=/tmp/cache"}, expected: "local caches need a standalone BuildKit daemon"},
+		{cacheTo: []string{"type=registry,ref=gcr.io/foo/cache"}, expected: "registry caches need a standalone BuildKit daemon"},
+		{cacheTo: []string{"type=gha"}, expected: `unsupported cache type "gha"`},
+		{cacheTo: []string{"type=local"}, expected: "requires src or dest"},
+		{cacheFrom: []string{"ref=gcr.io/foo/cache"}, expected: "missing a type"},
+	}
+	for _, tc := range tcs {
+		err := validateDockerCacheSpecs(v1alpha1.DockerImageSpec{CacheFrom: tc.cacheFrom, CacheTo: tc.cacheTo})
+		if tc.expected == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), tc.expected)
+		}
+	}
+}
+
+func TestDockerCacheOptions(t *testing.T) {
+	spec := v1alpha1.DockerImageSpec{
+		Args:      []string{"A=b"},
+		CacheFrom: []string{"gcr.io/foo/cache", "type=registry,ref=gcr.io/foo/cache2"},
+		CacheTo:   []string{"type=inline"},
+	}
+	options := Options(nil, spec)
+	assert.Equal(t, []string{"gcr.io/foo/cache", "gcr.io/foo/cache2"}, options.CacheFrom)
+	require.NotNil(t, options.BuildArgs["BUILDKIT_INLINE_CACHE"])
+	assert.Equal(t, "1", *options.BuildArgs["BUILDKIT_INLINE_CACHE"])
+	assert.Equal(t, "b", *options.BuildArgs["A"])
+}
+
+func TestCacheHits(t *testing.T) {
+	stages := []v1alpha1.DockerImageStageStatus{
+		{Name: "[internal] load build definition from Dockerfile"},
+		{Name: "[builder 1/3] FROM golang", Cached: true},
+		{Name: "[builder 2/3] COPY . .", Cached: true},
+		{Name: "[builder 3/3] RUN go build"},
+		{Name: "[stage-1 1/2] FROM alpine", Cached: true},
+		{Name: "[stage-1 2/2] COPY --from=builder /app /app"},
+		{Name: "[1/1] FROM busybox", Cached: true},
+		{Name: "exporting to image"},
+	}
+	assert.Equal(t, []v1alpha1.DockerImageCacheHits{
+		{Stage: "builder", Cached: 2, Total: 3},
+		{Stage: "stage-1", Cached: 1, Total: 2},
+		{Stage: "default", Cached: 1, Total: 1},
+	}, CacheHits(stages))
+}
@@ -148,8 +148,20 @@ func (d *DockerBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs
 	cluster *v1alpha1.Cluster,
 	imageMaps map[ktypes.NamespacedName]*v1alpha1.ImageMap,
 	filter model.PathMatcher) (container.TaggedRefs, []v1alpha1.DockerImageStageStatus, error) {
+	if len(spec.Platforms) > 0 {
+		// The Docker image store can't hold an image index.
+		return container.TaggedRefs{}, nil, fmt.Errorf(
+			"multi-platform builds (%s) need a standalone BuildKit daemon. Set %s, or configure buildkit on the Cluster",
+			strings.Join(spec.Platforms, ", "), BuildkitHostEnvVar)
+	}
+
+	err := validateDockerCacheSpecs(spec)
+	if err != nil {
+		return container.TaggedRefs{}, nil, err
+	}
+
 	spec = InjectClusterPlatform(spec, cluster)
-	spec, err := InjectImageDependencies(spec, imageMaps)
+	spec, err = InjectImageDependencies(spec, imageMaps)
 	if err != nil {
 		return container.TaggedRefs{}, nil, err
 	}
@@ -191,6 +203,7 @@ func (d *DockerBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs
 			return container.TaggedRefs{}, stages, err
 		}
 	}
+	printCacheSummary(ctx, stages)
 
 	tagged, err := d.TagRefs(ctx, refs, digest)
 	if err != nil {
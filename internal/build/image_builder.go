@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/docker/distribution/reference"
 	"k8s.io/apimachinery/pkg/types"
@@ -19,21 +20,41 @@ import (
 
 type ImageBuilder struct {
 	db    *DockerBuilder
+	bkb   *BuildkitBuilder
 	custb *CustomBuilder
 	kl    KINDLoader
 }
 
-func NewImageBuilder(db *DockerBuilder, custb *CustomBuilder, kl KINDLoader) *ImageBuilder {
+func NewImageBuilder(db *DockerBuilder, bkb *BuildkitBuilder, custb *CustomBuilder, kl KINDLoader) *ImageBuilder {
 	return &ImageBuilder{
 		db:    db,
+		bkb:   bkb,
 		custb: custb,
 		kl:    kl,
 	}
 }
 
-func (ib *ImageBuilder) CanReuseRef(ctx context.Context, iTarget model.ImageTarget, ref reference.NamedTagged) (bool, error) {
+func (ib *ImageBuilder) CanReuseRef(ctx context.Context, iTarget model.ImageTarget, ref reference.NamedTagged, cluster *v1alpha1.Cluster) (bool, error) {
 	switch iTarget.BuildDetails.(type) {
 	case model.DockerBuild:
+		if conn := ib.bkb.Connection(cluster); conn != nil {
+			refs, err := iTarget.Refs(cluster)
+			if err != nil {
+				return false, err
+			}
+			output, err := ib.buildkitOutput(iTarget, refs, conn, cluster)
+			if err != nil {
+				return false, err
+			}
+			if output == buildkitOutputPush {
+				return ib.bkb.PushedImageExists(ctx, ref)
+			}
+
+			// There's no good way to check what's in buildkitd's image store
+			// (or the KIND node's), and it may have been pruned, so rebuild.
+			// BuildKit's cache makes this cheap if nothing changed.
+			return false, nil
+		}
 		return ib.db.ImageExists(ctx, ref)
 	case model.CustomBuild:
 		// Custom build doesn't have a good way to check if the ref still exists in the image
@@ -91,6 +112,23 @@ func (ib *ImageBuilder) buildOnly(ctx context.Context,
 		defer ps.EndPipelineStep(ctx)
 
 		filter := ignore.CreateBuildContextFilter(bd.DockerImageSpec.ContextIgnores)
+		if conn := ib.bkb.Connection(cluster); conn != nil {
+			output, err := ib.buildkitOutput(iTarget, refs, conn, cluster)
+			if err != nil {
+				return container.TaggedRefs{}, nil, err
+			}
+			if output == buildkitOutputKIND {
+				return ib.buildkitBuildAndLoadToKIND(ctx, ps, refs, bd.DockerImageSpec,
+					cluster,
+					imageMaps,
+					filter)
+			}
+			return ib.bkb.BuildImage(ctx, ps, refs, bd.DockerImageSpec,
+				cluster,
+				imageMaps,
+				filter,
+				BuildkitDest{Push: output == buildkitOutputPush})
+		}
 		return ib.db.BuildImage(ctx, ps, refs, bd.DockerImageSpec,
 			cluster,
 			imageMaps,
@@ -109,13 +147,102 @@ func (ib *ImageBuilder) buildOnly(ctx context.Context,
 		"DockerBuild nor CustomBuild)", refs.ConfigurationRef)
 }
 
+// Where an image built by BuildKit needs to go.
+type buildkitOutput int
+
+const (
+	// Leave the image in buildkitd's image store.
+	buildkitOutputStore buildkitOutput = iota
+
+	// Push the image to its registry.
+	buildkitOutputPush
+
+	// Load the image into the KIND cluster's nodes.
+	buildkitOutputKIND
+)
+
+// BuildKit doesn't write to the Docker image store, so the cluster (and any
+// builds that use this image as a base image) can only see images in
+// buildkitd's image store if buildkitd shares the cluster's container runtime.
+//
+// Otherwise, the image needs to go to the registry, or be loaded into KIND.
+func (ib *ImageBuilder) buildkitOutput(iTarget model.ImageTarget, refs container.RefSet,
+	conn *v1alpha1.BuildkitConnection, cluster *v1alpha1.Cluster) (buildkitOutput, error) {
+	if conn.SharesClusterRuntime {
+		return buildkitOutputStore, nil
+	}
+
+	needs := iTarget.ClusterNeeds()
+	if needs != v1alpha1.ClusterImageNeedsPush && needs != v1alpha1.ClusterImageNeedsBase {
+		return buildkitOutputStore, nil
+	}
+
+	if ib.shouldUseKINDLoad(refs.LocalRef(), refs.ClusterRef(), cluster) {
+		if needs == v1alpha1.ClusterImageNeedsBase {
+			// Other builds pull their base images from buildkitd's image store
+			// or a registry, and this image would be in neither.
+			return buildkitOutputStore, fmt.Errorf(
+				"BuildKit can't share base image %s with the images built on it without a registry. "+
+					"Set up a registry for the KIND cluster, or use a buildkitd that shares the cluster's container runtime",
+				container.FamiliarString(refs.ConfigurationRef))
+		}
+		return buildkitOutputKIND, nil
+	}
+	return buildkitOutputPush, nil
+}
+
+// `kind load docker-image` reads from the Docker image store, so
+// BuildKit writes an image archive for KIND to load instead.
+func (ib *ImageBuilder) buildkitBuildAndLoadToKIND(ctx context.Context,
+	ps *PipelineState,
+	refs container.RefSet,
+	spec v1alpha1.DockerImageSpec,
+	cluster *v1alpha1.Cluster,
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
+	filter model.PathMatcher) (container.TaggedRefs, []v1alpha1.DockerImageStageStatus, error) {
+	archive, err := os.CreateTemp("", "tilt-kind-load-*.tar")
+	if err != nil {
+		return container.TaggedRefs{}, nil, err
+	}
+	_ = archive.Close()
+	defer func() {
+		_ = os.Remove(archive.Name())
+	}()
+
+	tagged, stages, err := ib.bkb.BuildImage(ctx, ps, refs, spec, cluster, imageMaps, filter,
+		BuildkitDest{Archive: archive.Name()})
+	if err != nil {
+		return tagged, stages, err
+	}
+
+	startTime := apis.NowMicro()
+	ps.Printf(ctx, "Loading image to KIND")
+	err = ib.kl.LoadArchiveToKIND(ps.AttachLogger(ctx), cluster, archive.Name())
+	endTime := apis.NowMicro()
+	stage := v1alpha1.DockerImageStageStatus{
+		Name:       "kind load",
+		StartedAt:  &startTime,
+		FinishedAt: &endTime,
+	}
+	if err != nil {
+		stage.Error = fmt.Sprintf("Error loading image to KIND: %v", err)
+	}
+	stages = append(stages, stage)
+	if err != nil {
+		return tagged, stages, errors.New(stage.Error)
+	}
+	return tagged, stages, nil
+}
+
 // Push the image if the cluster requires it.
 func (ib *ImageBuilder) push(ctx context.Context, refs container.TaggedRefs, ps *PipelineState, iTarget model.ImageTarget, cluster *v1alpha1.Cluster) *v1alpha1.DockerImageStageStatus {
 	// Skip the push phase entirely if we're on Docker Compose.
-	isDC := cluster != nil &&
-		cluster.Spec.Connection != nil &&
-		cluster.Spec.Connection.Docker != nil
-	if isDC {
+	if isDockerCompose(cluster) {
+		return nil
+	}
+
+	// BuildKit pushes (or loads to KIND) as part of the build.
+	if iTarget.IsDockerBuild() && ib.bkb.Connection(cluster) != nil {
 		return nil
 	}
 
@@ -141,14 +268,14 @@ func (ib *ImageBuilder) push(ctx context.Context, refs container.TaggedRefs, ps
 		return nil
 	}
 
-	if ib.db.WillBuildToKubeContext(k8s.KubeContext(k8sConnStatus(cluster).Context)) {
+	if k8s.BuildsToClusterRuntime(ib.db, cluster, k8s.KubeContext(k8sConnStatus(cluster).Context)) {
 		ps.Printf(ctx, "Skipping push: building on cluster's container runtime")
 		return nil
 	}
 
 	startTime := apis.NowMicro()
 	var err error
-	if ib.shouldUseKINDLoad(refs, cluster) {
+	if ib.shouldUseKINDLoad(refs.LocalRef, refs.ClusterRef, cluster) {
 		ps.Printf(ctx, "Loading image to KIND")
 		err := ib.kl.LoadToKIND(ps.AttachLogger(ctx), cluster, refs.LocalRef)
 		endTime := apis.NowMicro()
@@ -178,16 +305,24 @@ func (ib *ImageBuilder) push(ctx context.Context, refs container.TaggedRefs, ps
 	return stage
 }
 
-func (ib *ImageBuilder) shouldUseKINDLoad(refs container.TaggedRefs, cluster *v1alpha1.Cluster) bool {
-	isKIND := k8sConnStatus(cluster).Product == string(clusterid.ProductKIND)
-	if !isKIND {
+func (ib *ImageBuilder) shouldUseKINDLoad(localRef, clusterRef reference.Named, cluster *v1alpha1.Cluster) bool {
+	conn := k8sConnStatus(cluster)
+	switch conn.ImageLoad {
+	case v1alpha1.ClusterImageLoadKind:
+		// The user told us to use KIND load, even if we don't recognize the cluster.
+		return true
+	case "":
+		if conn.Product != string(clusterid.ProductKIND) {
+			return false
+		}
+	default:
 		return false
 	}
 
 	// if we're using KIND and the image has a separate ref by which it's referred to
 	// in the cluster, that implies that we have a local registry in place, and should
 	// push to that instead of using KIND load.
-	if refs.LocalRef.String() != refs.ClusterRef.String() {
+	if localRef.String() != clusterRef.String() {
 		return false
 	}
 
@@ -199,6 +334,12 @@ func (ib *ImageBuilder) shouldUseKINDLoad(refs container.TaggedRefs, cluster *v1
 	return true
 }
 
+func isDockerCompose(cluster *v1alpha1.Cluster) bool {
+	return cluster != nil &&
+		cluster.Spec.Connection != nil &&
+		cluster.Spec.Connection.Docker != nil
+}
+
 func k8sConnStatus(cluster *v1alpha1.Cluster) *v1alpha1.KubernetesClusterConnectionStatus {
 	if cluster != nil &&
 		cluster.Status.Connection != nil &&
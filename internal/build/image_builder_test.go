@@ -0,0 +1,109 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/clusterid"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestCanReuseRefWithBuildkit(t *testing.T) {
+	f := newBuildkitFixture(t)
+	ib := &ImageBuilder{bkb: f.b}
+	cluster := &v1alpha1.Cluster{
+		Status: v1alpha1.ClusterStatus{
+			Connection: &v1alpha1.ClusterConnectionStatus{
+				Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{
+					Product: string(clusterid.ProductGKE),
+				},
+			},
+		},
+	}
+	iTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/bar")).
+		WithDockerImage(v1alpha1.DockerImageSpec{ClusterNeeds: v1alpha1.ClusterImageNeedsPush})
+	ref := container.MustParseNamedTagged("gcr.io/foo/bar:tilt-cc5f4c463f81c551")
+
+	ok, err := ib.CanReuseRef(f.ctx, iTarget, ref, cluster)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	f.pushedImages[ref.String()] = true
+	ok, err = ib.CanReuseRef(f.ctx, iTarget, ref, cluster)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Images that only live in buildkitd's image store may have been pruned.
+	localTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/bar")).
+		WithDockerImage(v1alpha1.DockerImageSpec{ClusterNeeds: v1alpha1.ClusterImageNeedsBase})
+	cluster.Spec.Buildkit = &v1alpha1.BuildkitConnection{Address: "tcp://buildkitd:1234", SharesClusterRuntime: true}
+	ok, err = ib.CanReuseRef(f.ctx, localTarget, ref, cluster)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestBuildkitOutput(t *testing.T) {
+	kind := &v1alpha1.Cluster{
+		Status: v1alpha1.ClusterStatus{
+			Connection: &v1alpha1.ClusterConnectionStatus{
+				Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{
+					Product: string(clusterid.ProductKIND),
+				},
+			},
+		},
+	}
+	kindWithRegistry := kind.DeepCopy()
+	kindWithRegistry.Status.Registry = &v1alpha1.RegistryHosting{Host: "localhost:5000"}
+	gke := &v1alpha1.Cluster{
+		Status: v1alpha1.ClusterStatus{
+			Connection: &v1alpha1.ClusterConnectionStatus{
+				Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{
+					Product: string(clusterid.ProductGKE),
+				},
+			},
+		},
+	}
+
+	standalone := &v1alpha1.BuildkitConnection{Address: "tcp://buildkitd:1234"}
+	shared := &v1alpha1.BuildkitConnection{Address: "tcp://buildkitd:1234", SharesClusterRuntime: true}
+
+	for _, tc := range []struct {
+		name     string
+		cluster  *v1alpha1.Cluster
+		conn     *v1alpha1.BuildkitConnection
+		needs    v1alpha1.ClusterImageNeeds
+		expected buildkitOutput
+		err      string
+	}{
+		{"push to remote cluster", gke, standalone, v1alpha1.ClusterImageNeedsPush, buildkitOutputPush, ""},
+		{"push base image to remote cluster", gke, standalone, v1alpha1.ClusterImageNeedsBase, buildkitOutputPush, ""},
+		{"shared runtime", gke, shared, v1alpha1.ClusterImageNeedsPush, buildkitOutputStore, ""},
+		{"shared runtime base image", gke, shared, v1alpha1.ClusterImageNeedsBase, buildkitOutputStore, ""},
+		{"kind load", kind, standalone, v1alpha1.ClusterImageNeedsPush, buildkitOutputKIND, ""},
+		{"kind base image", kind, standalone, v1alpha1.ClusterImageNeedsBase, buildkitOutputStore, "without a registry"},
+		{"kind with registry", kindWithRegistry, standalone, v1alpha1.ClusterImageNeedsPush, buildkitOutputPush, ""},
+		{"kind base image with registry", kindWithRegistry, standalone, v1alpha1.ClusterImageNeedsBase, buildkitOutputPush, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			iTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/bar")).
+				WithDockerImage(v1alpha1.DockerImageSpec{ClusterNeeds: tc.needs})
+			refs, err := iTarget.Refs(tc.cluster)
+			require.NoError(t, err)
+
+			ib := &ImageBuilder{}
+			output, err := ib.buildkitOutput(iTarget, refs, tc.conn, tc.cluster)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, output)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/platforms"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Reads the per-platform images of a multi-platform image index.
+type indexResolver func(ctx context.Context, ref reference.Named, dig digest.Digest) ([]v1alpha1.ImageMapPlatformDigest, error)
+
+// A resolver that talks to registries with the credentials in the Docker
+// config file, like the Docker CLI does.
+func newRegistryResolver() remotes.Resolver {
+	cfg := config.LoadDefaultConfigFile(io.Discard)
+	authorizer := docker.NewDockerAuthorizer(docker.WithAuthCreds(func(host string) (string, string, error) {
+		if host == "registry-1.docker.io" {
+			host = "https://index.docker.io/v1/"
+		}
+		auth, err := cfg.GetAuthConfig(host)
+		if err != nil {
+			return "", "", err
+		}
+		if auth.IdentityToken != "" {
+			return "", auth.IdentityToken, nil
+		}
+		return auth.Username, auth.Password, nil
+	}))
+	return docker.NewResolver(docker.ResolverOptions{
+		Hosts: docker.ConfigureDefaultRegistries(
+			docker.WithAuthorizer(authorizer),
+			docker.WithPlainHTTP(docker.MatchLocalhost)),
+	})
+}
+
+// Checks whether an image exists in the registry.
+func registryImageExists(ctx context.Context, ref reference.Named) (bool, error) {
+	_, _, err := newRegistryResolver().Resolve(ctx, ref.String())
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "resolving %s", ref)
+	}
+	return true, nil
+}
+
+// Fetches an image index from the registry, and returns the digest
+// of each platform-specific manifest in it.
+func resolveRegistryIndex(ctx context.Context, ref reference.Named, dig digest.Digest) ([]v1alpha1.ImageMapPlatformDigest, error) {
+	resolver := newRegistryResolver()
+	canonical, err := reference.WithDigest(reference.TrimNamed(ref), dig)
+	if err != nil {
+		return nil, err
+	}
+
+	name, desc, err := resolver.Resolve(ctx, canonical.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving %s", canonical)
+	}
+	if !images.IsIndexType(desc.MediaType) {
+		return nil, fmt.Errorf("%s is not an image index (media type %s)", canonical, desc.MediaType)
+	}
+
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching %s", canonical)
+	}
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching %s", canonical)
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	var index ocispec.Index
+	err = json.NewDecoder(rc).Decode(&index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding image index %s", canonical)
+	}
+	return platformDigestsFromIndex(index), nil
+}
+
+func platformDigestsFromIndex(index ocispec.Index) []v1alpha1.ImageMapPlatformDigest {
+	var result []v1alpha1.ImageMapPlatformDigest
+	for _, m := range index.Manifests {
+		// BuildKit can attach non-image manifests (like attestations)
+		// to an index. They don't have a platform.
+		if m.Platform == nil || m.Platform.OS == "unknown" {
+			continue
+		}
+		result = append(result, v1alpha1.ImageMapPlatformDigest{
+			Platform: platforms.Format(*m.Platform),
+			Digest:   m.Digest.String(),
+		})
+	}
+	return result
+}
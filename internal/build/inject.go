@@ -27,7 +27,7 @@ var validBuildkitArchSet = map[string]bool{
 
 // Create a new ImageTarget with the platform OS/Arch from the target cluster.
 func InjectClusterPlatform(spec v1alpha1.DockerImageSpec, cluster *v1alpha1.Cluster) v1alpha1.DockerImageSpec {
-	if spec.Platform != "" || cluster == nil {
+	if spec.Platform != "" || len(spec.Platforms) > 0 || cluster == nil {
 		return spec
 	}
 
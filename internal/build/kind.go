@@ -14,12 +14,24 @@ import (
 
 type KINDLoader interface {
 	LoadToKIND(ctx context.Context, cluster *v1alpha1.Cluster, ref reference.NamedTagged) error
+
+	// Loads a Docker image archive, for images that aren't in
+	// the Docker image store (e.g., images built by BuildKit).
+	LoadArchiveToKIND(ctx context.Context, cluster *v1alpha1.Cluster, path string) error
 }
 
 type cmdKINDLoader struct {
 }
 
 func (kl *cmdKINDLoader) LoadToKIND(ctx context.Context, cluster *v1alpha1.Cluster, ref reference.NamedTagged) error {
+	return kl.run(ctx, cluster, "docker-image", ref.String())
+}
+
+func (kl *cmdKINDLoader) LoadArchiveToKIND(ctx context.Context, cluster *v1alpha1.Cluster, path string) error {
+	return kl.run(ctx, cluster, "image-archive", path)
+}
+
+func (kl *cmdKINDLoader) run(ctx context.Context, cluster *v1alpha1.Cluster, subcmd string, arg string) error {
 	// In Kind5, --name specifies the name of the cluster in the kubeconfig.
 	// In Kind6, the -name parameter is prefixed with 'kind-' before being written to/read from the kubeconfig
 	k8sConn := k8sConnStatus(cluster)
@@ -28,7 +40,7 @@ func (kl *cmdKINDLoader) LoadToKIND(ctx context.Context, cluster *v1alpha1.Clust
 		kindName = strings.TrimPrefix(kindName, "kind-")
 	}
 
-	cmd := exec.CommandContext(ctx, "kind", "load", "docker-image", ref.String(), "--name", kindName)
+	cmd := exec.CommandContext(ctx, "kind", "load", subcmd, arg, "--name", kindName)
 	w := logger.NewMutexWriter(logger.Get(ctx).Writer(logger.InfoLvl))
 	cmd.Stdout = w
 	cmd.Stderr = w
@@ -11,17 +11,21 @@ import (
 )
 
 func Options(archive io.Reader, spec v1alpha1.DockerImageSpec) docker.BuildOptions {
+	args := spec.Args
+	if dockerInlineCache(spec.CacheTo) {
+		args = append([]string{"BUILDKIT_INLINE_CACHE=1"}, args...)
+	}
 	return docker.BuildOptions{
 		Context:     archive,
 		Dockerfile:  "Dockerfile",
 		Remove:      shouldRemoveImage(),
-		BuildArgs:   opts.ConvertKVStringsToMapWithNil(spec.Args),
+		BuildArgs:   opts.ConvertKVStringsToMapWithNil(args),
 		Target:      spec.Target,
 		SSHSpecs:    spec.SSHAgentConfigs,
 		Network:     spec.Network,
 		ExtraTags:   spec.ExtraTags,
 		SecretSpecs: spec.Secrets,
-		CacheFrom:   spec.CacheFrom,
+		CacheFrom:   dockerCacheFrom(spec.CacheFrom),
 		PullParent:  spec.Pull,
 		Platform:    spec.Platform,
 	}
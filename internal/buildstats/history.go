@@ -0,0 +1,257 @@
+package buildstats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// The history file lives in the Tilt data dir, so that it survives
+// across Tilt sessions.
+const historyFile = "build-stats/history.jsonl"
+
+// When the history file grows past this size, we drop the oldest half.
+const maxHistorySize = 8 * 1024 * 1024
+
+// We only keep a handful of changed files per build, to keep records small.
+const maxChangedFiles = 20
+
+// Why an image build was triggered.
+type Trigger struct {
+	// A human-readable build reason, like "Changed Files".
+	Reason string
+
+	// The files that changed since the last build.
+	ChangedFiles []string
+}
+
+// A single image build.
+type Record struct {
+	// The image name, as written in the Tiltfile.
+	Image string `json:"image"`
+
+	StartTime time.Time     `json:"startTime"`
+	Duration  time.Duration `json:"duration"`
+
+	// If the build failed, the error message.
+	Error string `json:"error,omitempty"`
+
+	Reason       string   `json:"reason,omitempty"`
+	ChangedFiles []string `json:"changedFiles,omitempty"`
+
+	// A hash of the Dockerfile contents, so that we can tell
+	// which builds ran against which Dockerfile.
+	DockerfileDigest string `json:"dockerfileDigest,omitempty"`
+
+	// The size of the build context, after ignores.
+	ContextFiles int   `json:"contextFiles,omitempty"`
+	ContextBytes int64 `json:"contextBytes,omitempty"`
+
+	Stages []StageRecord `json:"stages,omitempty"`
+}
+
+type StageRecord struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Cached   bool          `json:"cached,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func (r Record) Succeeded() bool {
+	return r.Error == ""
+}
+
+// The number of build steps that were cached, out of all build steps.
+func (r Record) CacheHits() (cached int, total int) {
+	for _, s := range r.Stages {
+		if !isBuildStep(s.Name) {
+			continue
+		}
+		total++
+		if s.Cached {
+			cached++
+		}
+	}
+	return cached, total
+}
+
+func NewStageRecords(stages []v1alpha1.DockerImageStageStatus) []StageRecord {
+	result := make([]StageRecord, 0, len(stages))
+	for _, s := range stages {
+		var duration time.Duration
+		if s.StartedAt != nil && s.FinishedAt != nil {
+			duration = s.FinishedAt.Sub(s.StartedAt.Time)
+		}
+		result = append(result, StageRecord{
+			Name:     s.Name,
+			Duration: duration,
+			Cached:   s.Cached,
+			Error:    s.Error,
+		})
+	}
+	return result
+}
+
+// Stores a log of image builds in a local file.
+//
+// Several Tilt processes may share the file (e.g., `tilt up` in two projects),
+// so we take a file lock as well as a mutex before we touch it.
+type History struct {
+	base xdg.Base
+	mu   sync.Mutex
+}
+
+func NewHistory(base xdg.Base) *History {
+	return &History{base: base}
+}
+
+func (h *History) Append(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(r.ChangedFiles) > maxChangedFiles {
+		r.ChangedFiles = r.ChangedFiles[:maxChangedFiles]
+	}
+
+	path, err := h.base.DataFile(historyFile)
+	if err != nil {
+		return err
+	}
+
+	lock := flock.New(path + ".lock")
+	err = lock.Lock()
+	if err != nil {
+		return fmt.Errorf("locking build history: %v", err)
+	}
+	defer func() {
+		_ = lock.Unlock()
+	}()
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	err = h.maybeTruncate(path, len(line))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("writing build history: %v", err)
+	}
+	_, err = f.Write(line)
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing build history: %v", err)
+	}
+	return f.Close()
+}
+
+// If appending to the history file would push it past the size limit,
+// drop the oldest half of the records.
+func (h *History) maybeTruncate(path string, extra int) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Size()+int64(extra) <= maxHistorySize {
+		return nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	keep := contents[len(contents)/2:]
+	i := bytes.IndexByte(keep, '\n')
+	if i == -1 {
+		keep = nil
+	} else {
+		keep = keep[i+1:]
+	}
+
+	// Write to a temp file and rename, so that a crash
+	// never leaves half a history.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(keep)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Read all the records in the history, oldest first.
+//
+// Skips any records that can't be parsed.
+func (h *History) Read() ([]Record, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	path, err := h.base.DataFile(historyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := flock.New(path + ".lock")
+	err = lock.RLock()
+	if err != nil {
+		return nil, fmt.Errorf("locking build history: %v", err)
+	}
+	defer func() {
+		_ = lock.Unlock()
+	}()
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading build history: %v", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var result []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Record
+		err := json.Unmarshal(scanner.Bytes(), &r)
+		if err != nil {
+			continue
+		}
+		result = append(result, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading build history: %v", err)
+	}
+	return result, nil
+}
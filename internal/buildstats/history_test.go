@@ -0,0 +1,126 @@
+package buildstats
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestHistoryAppendAndRead(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	h := NewHistory(xdg.FakeBase{Dir: f.Path()})
+
+	records, err := h.Read()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, h.Append(Record{Image: "fe", StartTime: start, Duration: 3 * time.Second}))
+	require.NoError(t, h.Append(Record{Image: "be", StartTime: start, Error: "oops"}))
+
+	// A fresh History reads what the previous session wrote.
+	records, err = NewHistory(xdg.FakeBase{Dir: f.Path()}).Read()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "fe", records[0].Image)
+	assert.Equal(t, 3*time.Second, records[0].Duration)
+	assert.True(t, records[0].StartTime.Equal(start))
+	assert.False(t, records[1].Succeeded())
+}
+
+func TestHistoryTruncatesOldRecords(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	base := xdg.FakeBase{Dir: f.Path()}
+	h := NewHistory(base)
+
+	path, err := base.DataFile(historyFile)
+	require.NoError(t, err)
+	line := `{"image":"old","error":"` + strings.Repeat("x", 1000) + `"}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(strings.Repeat(line, maxHistorySize/len(line)+1)), 0600))
+
+	require.NoError(t, h.Append(Record{Image: "new"}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Less(t, info.Size(), int64(maxHistorySize/2+len(line)))
+
+	records, err := h.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "old", records[0].Image)
+	assert.Equal(t, "new", records[len(records)-1].Image)
+}
+
+func TestHistoryWaitsForOtherProcesses(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	base := xdg.FakeBase{Dir: f.Path()}
+	h := NewHistory(base)
+
+	// Another Tilt process is writing the history.
+	path, err := base.DataFile(historyFile)
+	require.NoError(t, err)
+	other := flock.New(path + ".lock")
+	require.NoError(t, other.Lock())
+
+	done := make(chan error)
+	go func() {
+		done <- h.Append(Record{Image: "fe"})
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Append didn't wait for the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, other.Unlock())
+	require.NoError(t, <-done)
+
+	records, err := h.Read()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+}
+
+func TestStageRecords(t *testing.T) {
+	start := metav1.NewMicroTime(time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC))
+	end := metav1.NewMicroTime(start.Add(2 * time.Second))
+	stages := NewStageRecords([]v1alpha1.DockerImageStageStatus{
+		{Name: "[internal] load build context", StartedAt: &start, FinishedAt: &end},
+		{Name: "[1/2] FROM alpine", StartedAt: &start, FinishedAt: &end, Cached: true},
+		{Name: "[2/2] RUN make", StartedAt: &start},
+	})
+	assert.Equal(t, []StageRecord{
+		{Name: "[internal] load build context", Duration: 2 * time.Second},
+		{Name: "[1/2] FROM alpine", Duration: 2 * time.Second, Cached: true},
+		{Name: "[2/2] RUN make"},
+	}, stages)
+
+	cached, total := Record{Stages: stages}.CacheHits()
+	assert.Equal(t, 1, cached)
+	assert.Equal(t, 2, total)
+}
+
+func TestContextSize(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("Dockerfile", "FROM alpine")
+	f.WriteFile("src/main.go", "package main")
+	f.WriteFile("node_modules/dep/index.js", "module.exports = {}")
+
+	filter := ignore.CreateBuildContextFilter([]v1alpha1.IgnoreDef{
+		{BasePath: f.JoinPath("node_modules")},
+	})
+	files, bytes, err := ContextSize(f.Path(), filter)
+	require.NoError(t, err)
+	assert.Equal(t, 2, files)
+	assert.Equal(t, int64(len("FROM alpine")+len("package main")), bytes)
+}
@@ -0,0 +1,206 @@
+package buildstats
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+// How many previous builds we compare against when looking for regressions.
+const baselineWindow = 10
+
+// The minimum number of previous builds we need before we flag a regression.
+const minBaselineBuilds = 3
+
+// A build is a regression if it's this much slower than the baseline...
+const regressionFactor = 1.5
+
+// ...and the slowdown is big enough for a human to notice.
+const minRegression = 5 * time.Second
+
+// The number of recent builds that we compare against older builds for trends.
+const trendWindow = 5
+
+type ImageSummary struct {
+	Image    string
+	Builds   int
+	Failures int
+	Last     Record
+
+	// The median duration of successful builds.
+	Median time.Duration
+
+	// How much slower (or faster, if negative) the most recent builds are
+	// than the builds before them, as a fraction. Zero if we don't have
+	// enough builds to tell.
+	Trend float64
+
+	CacheHits  int
+	CacheSteps int
+}
+
+// Summarize build history per image, sorted by image name.
+func Summarize(records []Record) []ImageSummary {
+	byImage := GroupByImage(records)
+	result := make([]ImageSummary, 0, len(byImage))
+	for image, records := range byImage {
+		summary := ImageSummary{
+			Image:  image,
+			Builds: len(records),
+			Last:   records[len(records)-1],
+		}
+
+		var durations []time.Duration
+		for _, r := range records {
+			if !r.Succeeded() {
+				summary.Failures++
+				continue
+			}
+			durations = append(durations, r.Duration)
+
+			cached, total := r.CacheHits()
+			summary.CacheHits += cached
+			summary.CacheSteps += total
+		}
+		summary.Median = median(durations)
+
+		if len(durations) >= 2*trendWindow {
+			recent := median(durations[len(durations)-trendWindow:])
+			before := median(durations[len(durations)-2*trendWindow : len(durations)-trendWindow])
+			if before > 0 {
+				summary.Trend = float64(recent-before) / float64(before)
+			}
+		}
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Image < result[j].Image })
+	return result
+}
+
+// Group records by image name, keeping them in order.
+func GroupByImage(records []Record) map[string][]Record {
+	result := make(map[string][]Record)
+	for _, r := range records {
+		result[r.Image] = append(result[r.Image], r)
+	}
+	return result
+}
+
+// A build that was much slower than the builds before it.
+type Regression struct {
+	Record Record
+
+	// The median duration of the successful builds before this one.
+	Baseline time.Duration
+
+	// The previous successful build.
+	Previous Record
+
+	// The build step that slowed down the most, compared to the previous build.
+	Step       string
+	StepBefore *StageRecord
+	StepAfter  StageRecord
+}
+
+func (r Regression) DockerfileChanged() bool {
+	return r.Record.DockerfileDigest != r.Previous.DockerfileDigest
+}
+
+// Find builds that were much slower than the ones before them.
+//
+// Expects records for a single image, oldest first.
+func FindRegressions(records []Record) []Regression {
+	var result []Regression
+	var previous []Record
+	previousRegressed := false
+	for _, r := range records {
+		if !r.Succeeded() {
+			continue
+		}
+
+		// Once builds get slow, they tend to stay slow. Only report
+		// the build where the slowdown started.
+		if previousRegressed &&
+			float64(r.Duration) <= regressionFactor*float64(previous[len(previous)-1].Duration) {
+			previous = append(previous, r)
+			continue
+		}
+
+		previousRegressed = false
+		if len(previous) >= minBaselineBuilds {
+			window := previous
+			if len(window) > baselineWindow {
+				window = window[len(window)-baselineWindow:]
+			}
+			durations := make([]time.Duration, 0, len(window))
+			for _, p := range window {
+				durations = append(durations, p.Duration)
+			}
+			baseline := median(durations)
+			if float64(r.Duration) > regressionFactor*float64(baseline) &&
+				r.Duration-baseline >= minRegression {
+				result = append(result, newRegression(r, baseline, previous[len(previous)-1]))
+				previousRegressed = true
+			}
+		}
+		previous = append(previous, r)
+	}
+	return result
+}
+
+func newRegression(r Record, baseline time.Duration, previous Record) Regression {
+	result := Regression{
+		Record:   r,
+		Baseline: baseline,
+		Previous: previous,
+	}
+
+	before := make(map[string]StageRecord, len(previous.Stages))
+	for _, s := range previous.Stages {
+		before[stepKey(s.Name)] = s
+	}
+
+	var worst time.Duration
+	for _, s := range r.Stages {
+		if !isBuildStep(s.Name) {
+			continue
+		}
+		delta := s.Duration
+		var stepBefore *StageRecord
+		if b, ok := before[stepKey(s.Name)]; ok {
+			b := b
+			stepBefore = &b
+			delta -= b.Duration
+		}
+		if delta > worst {
+			worst = delta
+			result.Step = s.Name
+			result.StepBefore = stepBefore
+			result.StepAfter = s
+		}
+	}
+	return result
+}
+
+var stepCounterRegexp = regexp.MustCompile(`^\[(?:(\S+) )?\d+/\d+\] `)
+
+// Identifies a build step across builds.
+//
+// When you add a line to a Dockerfile, the step counters shift,
+// so we match steps by their stage and instruction.
+func stepKey(step string) string {
+	return stepCounterRegexp.ReplaceAllString(step, "$1 ")
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
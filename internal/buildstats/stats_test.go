@@ -0,0 +1,91 @@
+package buildstats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerfileStage(t *testing.T) {
+	stage, ok := DockerfileStage("[builder 2/5] RUN go build")
+	assert.True(t, ok)
+	assert.Equal(t, "builder", stage)
+
+	stage, ok = DockerfileStage("[2/5] RUN go build")
+	assert.True(t, ok)
+	assert.Equal(t, "default", stage)
+
+	_, ok = DockerfileStage("[internal] load metadata for docker.io/library/alpine")
+	assert.False(t, ok)
+}
+
+func TestSummarize(t *testing.T) {
+	var records []Record
+	for i := 0; i < 10; i++ {
+		d := 2 * time.Second
+		if i >= 5 {
+			d = 3 * time.Second
+		}
+		records = append(records, Record{Image: "fe", Duration: d, Stages: []StageRecord{
+			{Name: "[1/2] FROM alpine", Cached: true},
+			{Name: "[2/2] RUN make"},
+		}})
+	}
+	records = append(records, Record{Image: "be", Duration: time.Second}, Record{Image: "be", Error: "oops"})
+
+	summaries := Summarize(records)
+	require.Len(t, summaries, 2)
+
+	assert.Equal(t, "be", summaries[0].Image)
+	assert.Equal(t, 2, summaries[0].Builds)
+	assert.Equal(t, 1, summaries[0].Failures)
+	assert.Equal(t, time.Second, summaries[0].Median)
+	assert.Equal(t, "oops", summaries[0].Last.Error)
+	assert.Equal(t, 0.0, summaries[0].Trend)
+
+	assert.Equal(t, "fe", summaries[1].Image)
+	assert.Equal(t, 2500*time.Millisecond, summaries[1].Median)
+	assert.Equal(t, 0.5, summaries[1].Trend)
+	assert.Equal(t, 10, summaries[1].CacheHits)
+	assert.Equal(t, 20, summaries[1].CacheSteps)
+}
+
+func TestFindRegressions(t *testing.T) {
+	fast := []StageRecord{
+		{Name: "[internal] load build context", Duration: time.Second},
+		{Name: "[1/3] FROM node", Duration: 100 * time.Millisecond, Cached: true},
+		{Name: "[2/3] RUN npm install", Duration: 100 * time.Millisecond, Cached: true},
+		{Name: "[3/3] COPY . .", Duration: 2 * time.Second},
+	}
+	slow := []StageRecord{
+		{Name: "[internal] load build context", Duration: time.Second},
+		{Name: "[1/4] FROM node", Duration: 100 * time.Millisecond, Cached: true},
+		{Name: "[2/4] COPY . .", Duration: 2 * time.Second},
+		{Name: "[3/4] RUN npm install", Duration: 30 * time.Second},
+		{Name: "[4/4] RUN npm build", Duration: time.Second},
+	}
+
+	var records []Record
+	for i := 0; i < 4; i++ {
+		records = append(records, Record{Image: "fe", Duration: 4 * time.Second, DockerfileDigest: "aaa", Stages: fast})
+	}
+	records = append(records,
+		Record{Image: "fe", Error: "syntax error", DockerfileDigest: "bbb"},
+		Record{Image: "fe", Duration: 35 * time.Second, DockerfileDigest: "ccc", Stages: slow},
+		Record{Image: "fe", Duration: 34 * time.Second, DockerfileDigest: "ccc", Stages: slow})
+
+	regressions := FindRegressions(records)
+	require.Len(t, regressions, 1, "the second slow build is compared against a baseline that includes the first")
+
+	r := regressions[0]
+	assert.Equal(t, 35*time.Second, r.Record.Duration)
+	assert.Equal(t, 4*time.Second, r.Baseline)
+	assert.True(t, r.DockerfileChanged())
+	assert.Equal(t, "aaa", r.Previous.DockerfileDigest)
+	assert.Equal(t, "[3/4] RUN npm install", r.Step)
+	require.NotNil(t, r.StepBefore)
+	assert.True(t, r.StepBefore.Cached)
+	assert.Equal(t, 30*time.Second, r.StepAfter.Duration)
+}
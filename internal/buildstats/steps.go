@@ -0,0 +1,78 @@
+package buildstats
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Matches build steps like "[builder 2/5] RUN go build",
+// and captures the Dockerfile stage name (if any).
+var buildStepRegexp = regexp.MustCompile(`^\[(?:(\S+) )?\d+/\d+\]`)
+
+// The Dockerfile stage that a BuildKit build step belongs to.
+//
+// Unnamed stages are called "default". Returns false if the name
+// isn't a Dockerfile build step (e.g., "[internal] load metadata").
+func DockerfileStage(step string) (string, bool) {
+	match := buildStepRegexp.FindStringSubmatch(step)
+	if match == nil {
+		return "", false
+	}
+	if match[1] == "" {
+		return "default", true
+	}
+	return match[1], true
+}
+
+func isBuildStep(step string) bool {
+	_, ok := DockerfileStage(step)
+	return ok
+}
+
+func DockerfileDigest(contents string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))[:12]
+}
+
+// Measures the files in a build context that aren't ignored.
+func ContextSize(dir string, filter model.PathMatcher) (files int, bytes int64, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			skip, err := filter.MatchesEntireDir(path)
+			if err != nil {
+				return err
+			}
+			if skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ignored, err := filter.Matches(path)
+		if err != nil {
+			return err
+		}
+		if ignored {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files++
+		bytes += info.Size()
+		return nil
+	})
+	return files, bytes, err
+}
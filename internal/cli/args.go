@@ -18,6 +18,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/analytics"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
+	"github.com/tilt-dev/tilt/internal/tiltfile/config"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -26,11 +27,14 @@ import (
 type argsCmd struct {
 	streams genericclioptions.IOStreams
 	clear   bool
+
+	getArgsSchema func() (model.TiltfileArgsSchema, error)
 }
 
 func newArgsCmd(streams genericclioptions.IOStreams) *argsCmd {
 	return &argsCmd{
-		streams: streams,
+		streams:       streams,
+		getArgsSchema: getTiltfileArgsSchema,
 	}
 }
 
@@ -52,7 +56,10 @@ the editor defined by your TILT_EDITOR or EDITOR environment variables, or fall
 an OS-appropriate default.
 
 Note that Tiltfile arguments do not affect built-in Tilt args (i.e., the things that show up in "tilt up --help", such as "--legacy", "--port"), and they
-are defined after built-in args, following a "--".`,
+are defined after built-in args, following a "--".
+
+If Tilt is running, the args defined by its Tiltfile are listed below. "tilt args -- --help"
+lists only those.`,
 		Example: `# Set new args
 tilt args frontend_service backend_service -- --debug on
 
@@ -60,9 +67,25 @@ tilt args frontend_service backend_service -- --debug on
 tilt args
 
 # Use an alternative editor
-EDITOR=nano tilt args`,
+EDITOR=nano tilt args
+
+# List the args that the Tiltfile defines
+tilt args -- --help`,
 	}
 
+	defaultHelp := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		defaultHelp(cmd, args)
+
+		// Best-effort: if Tilt isn't running, there's nothing to add.
+		schema, err := c.getArgsSchema()
+		if err != nil {
+			return
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout())
+		writeTiltfileArgsHelp(cmd.OutOrStdout(), schema.Args)
+	})
+
 	addConnectServerFlags(cmd)
 	cmd.Flags().BoolVar(&c.clear, "clear", false, "Clear the Tiltfile args, as if you'd run tilt with no args")
 
@@ -97,6 +120,20 @@ func parseEditResult(b []byte) ([]string, error) {
 func (c *argsCmd) run(ctx context.Context, args []string) error {
 	ctx = logger.WithLogger(ctx, logger.NewLogger(logger.Get(ctx).Level(), c.streams.ErrOut))
 
+	if mightBeTiltfileArgsHelp(args) {
+		schema, err := c.getArgsSchema()
+		if err != nil {
+			return err
+		}
+		if config.IsHelp(args, schema.Args) {
+			a := analytics.Get(ctx)
+			a.Incr("cmd.args", engineanalytics.CmdTags{"help": "true"}.AsMap())
+			defer a.Flush(time.Second)
+			writeTiltfileArgsHelp(c.streams.Out, schema.Args)
+			return nil
+		}
+	}
+
 	ctrlclient, err := newClient(ctx)
 	if err != nil {
 		return err
@@ -178,6 +178,76 @@ func TestArgsEdit(t *testing.T) {
 	}
 }
 
+func TestArgsHelp(t *testing.T) {
+	f := newServerFixture(t)
+
+	createTiltfile(f, []string{"foo", "bar"})
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newArgsCmd(streams)
+	cmd.getArgsSchema = func() (model.TiltfileArgsSchema, error) {
+		return model.TiltfileArgsSchema{Args: []model.TiltfileArg{
+			{Name: "env", Type: "choice", Choices: []string{"dev", "prod"}, Usage: "where to deploy"},
+		}}, nil
+	}
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--", "--help"})
+	require.NoError(t, err)
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	require.Equal(t, `Tiltfile args (pass after "--"):
+
+Flags:
+      --env dev|prod   where to deploy
+`, out.String())
+
+	// --help shouldn't be sent to the running Tilt
+	require.Equal(t, []string{"foo", "bar"}, getTiltfile(f).Spec.Args)
+	require.Equal(t, []analytics.CountEvent{
+		{Name: "cmd.args", Tags: map[string]string{"help": "true"}, N: 1},
+	}, f.analytics.Counts)
+}
+
+func TestArgsHelpFlagAsValue(t *testing.T) {
+	f := newServerFixture(t)
+
+	createTiltfile(f, []string{"foo", "bar"})
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newArgsCmd(streams)
+	cmd.getArgsSchema = func() (model.TiltfileArgsSchema, error) {
+		return model.TiltfileArgsSchema{Args: []model.TiltfileArg{
+			{Name: "name", Type: "string"},
+		}}, nil
+	}
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--", "--name", "-h"})
+	require.NoError(t, err)
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	// "-h" is the value of --name, not a request for help.
+	require.Equal(t, "", out.String())
+	require.Equal(t, []string{"--name", "-h"}, getTiltfile(f).Spec.Args)
+	require.Equal(t, []analytics.CountEvent{
+		{Name: "cmd.args", Tags: map[string]string{"set": "true"}, N: 1},
+	}, f.analytics.Counts)
+}
+
+func TestArgsHelpNoArgsDefined(t *testing.T) {
+	f := newServerFixture(t)
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newArgsCmd(streams)
+	cmd.getArgsSchema = func() (model.TiltfileArgsSchema, error) {
+		return model.TiltfileArgsSchema{}, nil
+	}
+	err := cmd.run(f.ctx, []string{"-h"})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "This Tiltfile doesn't define any args")
+}
+
 func createTiltfile(f *serverFixture, args []string) {
 	tf := v1alpha1.Tiltfile{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/buildstats"
+	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type buildStatsCmd struct {
+	streams genericclioptions.IOStreams
+	history *buildstats.History
+	limit   int
+}
+
+var _ tiltCmd = &buildStatsCmd{}
+
+func newBuildStatsCmd(streams genericclioptions.IOStreams) *buildStatsCmd {
+	return &buildStatsCmd{
+		streams: streams,
+	}
+}
+
+func (c *buildStatsCmd) name() model.TiltSubcommand { return "build-stats" }
+
+func (c *buildStatsCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "build-stats [IMAGE]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show how long image builds take over time",
+		Long: `Show how long image builds take over time.
+
+Tilt records every docker_build, across sessions. With no arguments,
+prints a summary of each image: how many times it was built, the median
+build time, and whether recent builds are getting slower.
+
+With an image name, prints the recent builds of that image, and flags
+builds that were much slower than the ones before them, with the build
+step that slowed down and whether the Dockerfile changed.
+
+# summarize all images
+tilt build-stats
+
+# show recent builds of one image
+tilt build-stats gcr.io/my-project/frontend
+`,
+		Args: cobra.MaximumNArgs(1),
+	}
+	cmd.Flags().IntVar(&c.limit, "limit", 20, "Number of recent builds to show for an image")
+	return cmd
+}
+
+func (c *buildStatsCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.build-stats", engineanalytics.CmdTags(map[string]string{}).AsMap())
+	defer a.Flush(time.Second)
+
+	if c.history == nil {
+		c.history = wireBuildStatsHistory()
+	}
+
+	records, err := c.history.Read()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if len(records) == 0 {
+			_, _ = fmt.Fprintln(c.streams.Out, "No image builds recorded yet.")
+			return nil
+		}
+		c.printSummary(records)
+		return nil
+	}
+
+	image := args[0]
+	records = buildstats.GroupByImage(records)[image]
+	if len(records) == 0 {
+		return fmt.Errorf("no builds recorded for image %q", image)
+	}
+	c.printImage(image, records)
+	return nil
+}
+
+func (c *buildStatsCmd) printSummary(records []buildstats.Record) {
+	w := tabwriter.NewWriter(c.streams.Out, 0, 8, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "IMAGE\tBUILDS\tFAILED\tLAST\tMEDIAN\tTREND\tCACHE HITS")
+	for _, s := range buildstats.Summarize(records) {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			s.Image, s.Builds, s.Failures,
+			formatBuildDuration(s.Last),
+			formatDuration(s.Median),
+			formatTrend(s.Trend),
+			formatPercent(s.CacheHits, s.CacheSteps))
+	}
+	_ = w.Flush()
+}
+
+func (c *buildStatsCmd) printImage(image string, records []buildstats.Record) {
+	out := c.streams.Out
+	regressions := buildstats.FindRegressions(records)
+
+	regressed := make(map[time.Time]bool, len(regressions))
+	for _, r := range regressions {
+		regressed[r.Record.StartTime] = true
+	}
+
+	shown := records
+	if c.limit > 0 && len(shown) > c.limit {
+		shown = shown[len(shown)-c.limit:]
+	}
+
+	_, _ = fmt.Fprintf(out, "Builds of %s (oldest first):\n\n", image)
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STARTED\tDURATION\tCACHE HITS\tCONTEXT\tDOCKERFILE\tREASON\t")
+	for _, r := range shown {
+		cached, total := r.CacheHits()
+		flag := ""
+		if regressed[r.StartTime] {
+			flag = "⚠ slow"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.StartTime.Local().Format("2006-01-02 15:04:05"),
+			formatBuildDuration(r),
+			formatPercent(cached, total),
+			formatContext(r),
+			r.DockerfileDigest,
+			r.Reason,
+			flag)
+	}
+	_ = w.Flush()
+
+	if len(regressions) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(out, "\nSlow builds:\n")
+	for _, r := range regressions {
+		printRegression(out, r)
+	}
+}
+
+func printRegression(out io.Writer, r buildstats.Regression) {
+	_, _ = fmt.Fprintf(out, "  %s: took %s, up from a median of %s\n",
+		r.Record.StartTime.Local().Format("2006-01-02 15:04:05"),
+		formatDuration(r.Record.Duration),
+		formatDuration(r.Baseline))
+
+	if r.DockerfileChanged() {
+		_, _ = fmt.Fprintf(out, "    Dockerfile changed (%s → %s)\n",
+			r.Previous.DockerfileDigest, r.Record.DockerfileDigest)
+	}
+
+	if r.Step != "" {
+		before := "not in the previous build"
+		if r.StepBefore != nil {
+			before = "was " + formatDuration(r.StepBefore.Duration)
+			if r.StepBefore.Cached {
+				before += ", cached"
+			}
+		}
+		_, _ = fmt.Fprintf(out, "    Slowest step: %s took %s (%s)\n",
+			r.Step, formatDuration(r.StepAfter.Duration), before)
+	}
+
+	if r.Record.ContextBytes > 2*r.Previous.ContextBytes && r.Previous.ContextBytes > 0 {
+		_, _ = fmt.Fprintf(out, "    Build context grew from %s to %s\n",
+			units.HumanSize(float64(r.Previous.ContextBytes)),
+			units.HumanSize(float64(r.Record.ContextBytes)))
+	}
+
+	if len(r.Record.ChangedFiles) > 0 {
+		_, _ = fmt.Fprintf(out, "    Changed files: %s\n", strings.Join(r.Record.ChangedFiles, ", "))
+	}
+}
+
+func formatBuildDuration(r buildstats.Record) string {
+	if !r.Succeeded() {
+		return "failed"
+	}
+	return formatDuration(r.Duration)
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(10 * time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+func formatTrend(trend float64) string {
+	if trend == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.0f%%", 100*trend)
+}
+
+func formatPercent(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d (%d%%)", n, total, 100*n/total)
+}
+
+func formatContext(r buildstats.Record) string {
+	if r.ContextFiles == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%d files)", units.HumanSize(float64(r.ContextBytes)), r.ContextFiles)
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/buildstats"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/xdg"
+)
+
+func TestBuildStats(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newBuildStatsCmd(streams)
+	cmd.history = buildstats.NewHistory(xdg.FakeBase{Dir: t.TempDir()})
+	cmd.register()
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	require.NoError(t, cmd.run(ctx, nil))
+	assert.Equal(t, "No image builds recorded yet.\n", out.String())
+
+	start := time.Now()
+	fast := []buildstats.StageRecord{
+		{Name: "[1/2] FROM node", Cached: true},
+		{Name: "[2/2] RUN npm install", Duration: time.Second},
+	}
+	for i := 0; i < 4; i++ {
+		require.NoError(t, cmd.history.Append(buildstats.Record{
+			Image:            "frontend",
+			StartTime:        start.Add(time.Duration(i) * time.Minute),
+			Duration:         2 * time.Second,
+			DockerfileDigest: "aaa",
+			Reason:           "Changed Files",
+			Stages:           fast,
+		}))
+	}
+	require.NoError(t, cmd.history.Append(buildstats.Record{
+		Image:            "frontend",
+		StartTime:        start.Add(10 * time.Minute),
+		Duration:         40 * time.Second,
+		DockerfileDigest: "bbb",
+		Reason:           "Changed Files",
+		ChangedFiles:     []string{"Dockerfile"},
+		Stages: []buildstats.StageRecord{
+			{Name: "[1/2] FROM node", Cached: true},
+			{Name: "[2/2] RUN npm install", Duration: 38 * time.Second},
+		},
+	}))
+	require.NoError(t, cmd.history.Append(buildstats.Record{
+		Image:     "backend",
+		StartTime: start,
+		Error:     "exit status 1",
+	}))
+
+	out.Reset()
+	require.NoError(t, cmd.run(ctx, nil))
+	assert.Contains(t, out.String(), "IMAGE")
+	assert.Regexp(t, `backend\s+1\s+1\s+failed\s+0s\s+-\s+-`, out.String())
+	assert.Regexp(t, `frontend\s+5\s+0\s+40s\s+2s\s+-\s+5/10 \(50%\)`, out.String())
+
+	out.Reset()
+	require.NoError(t, cmd.run(ctx, []string{"frontend"}))
+	assert.Contains(t, out.String(), "Builds of frontend (oldest first):")
+	assert.Regexp(t, `40s\s+1/2 \(50%\)\s+-\s+bbb\s+Changed Files\s+⚠ slow`, out.String())
+	assert.Contains(t, out.String(), `Slow builds:`)
+	assert.Contains(t, out.String(), `took 40s, up from a median of 2s
+    Dockerfile changed (aaa → bbb)
+    Slowest step: [2/2] RUN npm install took 38s (was 1s)
+    Changed files: Dockerfile
+`)
+
+	cmd.limit = 2
+	out.Reset()
+	require.NoError(t, cmd.run(ctx, []string{"frontend"}))
+	assert.Equal(t, 2, strings.Count(out.String(), "Changed Files"))
+
+	err := cmd.run(ctx, []string{"other"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `no builds recorded for image "other"`)
+	}
+}
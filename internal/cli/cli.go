@@ -68,6 +68,8 @@ up-to-date in real-time. Think 'docker build && kubectl apply' or 'docker-compos
 	addCommand(rootCmd, newDescribeCmd(streams))
 	addCommand(rootCmd, newGetCmd(streams))
 	addCommand(rootCmd, newExplainCmd(streams))
+	addCommand(rootCmd, newExplainUpdateCmd(streams))
+	addCommand(rootCmd, newBuildStatsCmd(streams))
 	addCommand(rootCmd, newEditCmd(streams))
 	addCommand(rootCmd, newApiresourcesCmd(streams))
 	addCommand(rootCmd, newDeleteCmd(streams))
@@ -83,6 +85,7 @@ up-to-date in real-time. Think 'docker build && kubectl apply' or 'docker-compos
 	rootCmd.AddCommand(analytics.NewCommand())
 	rootCmd.AddCommand(newDumpCmd(rootCmd, streams))
 	rootCmd.AddCommand(newAlphaCmd(streams))
+	rootCmd.AddCommand(newTiltfileCmd(streams))
 	rootCmd.AddCommand(newLspCmd())
 	rootCmd.AddCommand(newSnapshotCmd())
 
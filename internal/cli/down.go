@@ -11,7 +11,9 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/clusterprovision"
 	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/apis/tiltfile"
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -22,6 +24,7 @@ import (
 type downCmd struct {
 	fileName         string
 	deleteNamespaces bool
+	deleteCluster    bool
 	downDepsProvider func(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (DownDeps, error)
 }
 
@@ -49,6 +52,8 @@ Specify additional flags and arguments to control which resources are deleted.
 
 Namespaces are not deleted by default. Use --delete-namespaces to change that.
 
+A local cluster created with k8s_local_cluster() is not deleted by default. Use --delete-cluster to change that.
+
 Kubernetes resources with the annotation 'tilt.dev/down-policy: keep' are not deleted.
 
 For more complex cases, the Tiltfile has APIs to add additional flags and arguments to the Tilt CLI.
@@ -60,6 +65,7 @@ See https://docs.tilt.dev/tiltfile_config.html for examples.
 	addTiltfileFlag(cmd, &c.fileName)
 	addKubeContextFlag(cmd)
 	cmd.Flags().BoolVar(&c.deleteNamespaces, "delete-namespaces", false, "delete namespaces defined in the Tiltfile (by default, don't)")
+	cmd.Flags().BoolVar(&c.deleteCluster, "delete-cluster", false, "delete the local cluster defined in the Tiltfile (by default, don't)")
 
 	return cmd
 }
@@ -105,6 +111,20 @@ func (c *downCmd) down(ctx context.Context, downDeps DownDeps, args []string) er
 		}
 	}
 
+	if c.deleteCluster {
+		if tlr.ClusterProvision == nil {
+			logger.Get(ctx).Infof("No local cluster defined in the Tiltfile, so not deleting a cluster.")
+			return nil
+		}
+
+		logger.Get(ctx).Infof("Deleting %s cluster %s",
+			tlr.ClusterProvision.Product, clusterprovision.ClusterName(*tlr.ClusterProvision))
+		err = downDeps.provisioner.Delete(ctx, *tlr.ClusterProvision)
+		if err != nil {
+			return errors.Wrap(err, "Deleting local cluster")
+		}
+	}
+
 	return nil
 }
 
@@ -157,7 +177,7 @@ func manifestsForNode(node *dependencyNode) []model.Manifest {
 }
 
 func deleteK8sEntities(ctx context.Context, manifests []model.Manifest, updateSettings model.UpdateSettings, downDeps DownDeps, deleteNamespaces bool) error {
-	entities, deleteCmds, err := k8sToDelete(manifests...)
+	entities, deleteCmds, helmReleases, err := k8sToDelete(manifests...)
 	if err != nil {
 		return errors.Wrap(err, "Parsing manifest YAML")
 	}
@@ -208,12 +228,22 @@ func deleteK8sEntities(ctx context.Context, manifests []model.Manifest, updateSe
 		}
 	}
 
+	kubeConfig := helm.KubeConfig{Context: downDeps.kClient.APIConfig().CurrentContext}
+	for _, hr := range helmReleases {
+		logger.Get(ctx).Infof("Uninstalling Helm release %s", hr.ReleaseName)
+		err := downDeps.helmClient.Uninstall(ctx, kubeConfig, hr.ReleaseName, hr.Namespace, updateSettings.K8sUpsertTimeout())
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "Uninstalling Helm release %s", hr.ReleaseName))
+		}
+	}
+
 	return utilerrors.NewAggregate(errs)
 }
 
-func k8sToDelete(manifests ...model.Manifest) ([]k8s.K8sEntity, []model.Cmd, error) {
+func k8sToDelete(manifests ...model.Manifest) ([]k8s.K8sEntity, []model.Cmd, []*v1alpha1.KubernetesApplyHelmRelease, error) {
 	var allEntities []k8s.K8sEntity
 	var deleteCmds []model.Cmd
+	var helmReleases []*v1alpha1.KubernetesApplyHelmRelease
 	for _, m := range manifests {
 		if !m.IsK8s() {
 			continue
@@ -226,13 +256,15 @@ func k8sToDelete(manifests ...model.Manifest) ([]k8s.K8sEntity, []model.Cmd, err
 				Dir:  kt.DeleteCmd.Dir,
 				Env:  kt.DeleteCmd.Env,
 			})
+		} else if kt.HelmRelease != nil {
+			helmReleases = append(helmReleases, kt.HelmRelease)
 		} else {
 			entities, err := k8s.ParseYAMLFromString(kt.YAML)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			allEntities = append(allEntities, k8s.ReverseSortedEntities(entities)...)
 		}
 	}
-	return allEntities, deleteCmds, nil
+	return allEntities, deleteCmds, helmReleases, nil
 }
@@ -10,7 +10,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/clusterprovision"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/localexec"
@@ -265,6 +267,34 @@ func TestDownK8sDeleteCmd_Error(t *testing.T) {
 	}
 }
 
+func TestDownHelmRelease(t *testing.T) {
+	f := newDownFixture(t)
+
+	kaSpec := v1alpha1.KubernetesApplySpec{
+		HelmRelease: &v1alpha1.KubernetesApplyHelmRelease{
+			ReleaseName: "fe",
+			Namespace:   "web",
+			Chart:       "./chart",
+		},
+	}
+
+	kt, err := k8s.NewTarget("fe", kaSpec, model.PodReadinessIgnore, nil)
+	require.NoError(t, err, "Failed to make KubernetesTarget")
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(kt)
+
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{m}}
+	err = f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+
+	if assert.Len(t, f.helm.UninstallCalls, 1) {
+		call := f.helm.UninstallCalls[0]
+		assert.Equal(t, "fe", call.Name)
+		assert.Equal(t, "web", call.Namespace)
+	}
+	assert.Empty(t, f.kCli.DeletedYaml)
+	assert.Empty(t, f.execer.Calls())
+}
+
 func TestDownDCFails(t *testing.T) {
 	f := newDownFixture(t)
 
@@ -276,6 +306,25 @@ func TestDownDCFails(t *testing.T) {
 	}
 }
 
+func TestDownDeleteCluster(t *testing.T) {
+	f := newDownFixture(t)
+
+	provision := v1alpha1.ClusterProvision{Product: v1alpha1.ClusterProvisionProductKind}
+	_, err := f.prov.Ensure(f.ctx, provision)
+	require.NoError(t, err)
+
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: newK8sManifest(), ClusterProvision: &provision}
+	err = f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+	assert.Contains(t, f.prov.Clusters, "kind-tilt", "cluster should be kept without --delete-cluster")
+
+	f.cmd.deleteCluster = true
+	err = f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+	assert.Contains(t, f.kCli.DeletedYaml, "sancho")
+	assert.NotContains(t, f.prov.Clusters, "kind-tilt")
+}
+
 func TestDownArgs(t *testing.T) {
 	f := newDownFixture(t)
 
@@ -439,6 +488,8 @@ type downFixture struct {
 	dcc    *dockercompose.FakeDCClient
 	kCli   *k8s.FakeK8sClient
 	execer *localexec.FakeExecer
+	helm   *helm.FakeReleaseClient
+	prov   *clusterprovision.FakeProvisioner
 }
 
 func newDownFixture(t *testing.T) downFixture {
@@ -448,7 +499,9 @@ func newDownFixture(t *testing.T) downFixture {
 	dcc := dockercompose.NewFakeDockerComposeClient(t, ctx)
 	kCli := k8s.NewFakeK8sClient(t)
 	execer := localexec.NewFakeExecer(t)
-	downDeps := DownDeps{tfl, dcc, kCli, execer}
+	helmClient := helm.NewFakeReleaseClient()
+	provisioner := clusterprovision.NewFakeProvisioner()
+	downDeps := DownDeps{tfl, dcc, kCli, execer, helmClient, provisioner}
 	cmd := &downCmd{downDepsProvider: func(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (deps DownDeps, err error) {
 		return downDeps, nil
 	}}
@@ -462,6 +515,8 @@ func newDownFixture(t *testing.T) downFixture {
 		dcc:    dcc,
 		kCli:   kCli,
 		execer: execer,
+		helm:   helmClient,
+		prov:   provisioner,
 	}
 
 	t.Cleanup(ret.TearDown)
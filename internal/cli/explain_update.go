@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/store/liveupdates"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type explainUpdateCmd struct {
+	streams genericclioptions.IOStreams
+}
+
+var _ tiltCmd = &explainUpdateCmd{}
+
+func newExplainUpdateCmd(streams genericclioptions.IOStreams) *explainUpdateCmd {
+	return &explainUpdateCmd{
+		streams: streams,
+	}
+}
+
+func (c *explainUpdateCmd) name() model.TiltSubcommand { return "explain-update" }
+
+func (c *explainUpdateCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "explain-update RESOURCE FILE...",
+		DisableFlagsInUseLine: true,
+		Short:                 "Explain how a resource would handle changes to the given files",
+		Long: `Explain how a resource would handle changes to the given files.
+
+For each file, prints which live_update sync, run trigger, or fall_back_on
+path it matches, and whether Tilt would live update the container or fall
+back to a full rebuild.
+
+This is a dry-run. It doesn't sync any files or trigger any builds.
+
+# explain what happens when you edit package.json and src/index.js
+tilt explain-update frontend package.json src/index.js
+`,
+		Args: cobra.MinimumNArgs(2),
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func (c *explainUpdateCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.explain-update", engineanalytics.CmdTags(map[string]string{}).AsMap())
+	defer a.Flush(time.Second)
+
+	ctrlclient, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resource := args[0]
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, 0, len(args)-1)
+	for _, f := range args[1:] {
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(wd, f)
+		}
+		files = append(files, filepath.Clean(f))
+	}
+
+	var list v1alpha1.LiveUpdateList
+	err = ctrlclient.List(ctx, &list)
+	if err != nil {
+		return err
+	}
+
+	var lus []v1alpha1.LiveUpdate
+	for _, lu := range list.Items {
+		if lu.Annotations[v1alpha1.AnnotationManifest] == resource {
+			lus = append(lus, lu)
+		}
+	}
+	if len(lus) == 0 {
+		return fmt.Errorf("resource %q has no live_update. Every file change triggers a full rebuild", resource)
+	}
+	sort.Slice(lus, func(i, j int) bool { return lus[i].Name < lus[j].Name })
+
+	for i, lu := range lus {
+		if i > 0 {
+			_, _ = fmt.Fprintln(c.streams.Out)
+		}
+		err := c.explainOne(lu, files, wd)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *explainUpdateCmd) explainOne(lu v1alpha1.LiveUpdate, files []string, wd string) error {
+	out := c.streams.Out
+	decisions, err := liveupdates.ExplainFiles(lu.Spec, files)
+	if err != nil {
+		return fmt.Errorf("explaining %s: %v", lu.Name, err)
+	}
+
+	_, _ = fmt.Fprintf(out, "LiveUpdate %s:\n", lu.Name)
+
+	fullRebuild := false
+	for _, d := range decisions {
+		_, _ = fmt.Fprintf(out, "  %s\n", displayPath(wd, d.LocalPath))
+		switch d.Action {
+		case v1alpha1.LiveUpdateFileActionStop:
+			fullRebuild = true
+			_, _ = fmt.Fprintf(out, "    → full rebuild: matches fall_back_on path %q\n", d.StopPath)
+		case v1alpha1.LiveUpdateFileActionNoMatch:
+			fullRebuild = true
+			_, _ = fmt.Fprintf(out, "    → full rebuild: doesn't match any sync path\n")
+		default:
+			_, _ = fmt.Fprintf(out, "    → sync %s to %s\n", displayPath(wd, d.SyncLocalPath), d.ContainerPath)
+		}
+		if len(d.TriggerPaths) > 0 {
+			_, _ = fmt.Fprintf(out, "    → triggers run steps with paths: %s\n", strings.Join(d.TriggerPaths, ", "))
+		}
+	}
+
+	_, _ = fmt.Fprintln(out)
+	switch {
+	case fullRebuild:
+		_, _ = fmt.Fprintf(out, "Result: Tilt would fall back to a full rebuild.\n")
+	case lu.Status.Failed != nil:
+		_, _ = fmt.Fprintf(out, "Result: Tilt would fall back to a full rebuild, because live update is currently stopped (%s: %s).\n",
+			lu.Status.Failed.Reason, lu.Status.Failed.Message)
+	case len(lu.Status.Containers) == 0:
+		_, _ = fmt.Fprintf(out, "Result: Tilt would wait, because no containers are running yet.\n")
+	default:
+		_, _ = fmt.Fprintf(out, "Result: Tilt would live update %d container(s).\n", len(lu.Status.Containers))
+		for _, ctr := range lu.Status.Containers {
+			if ctr.Waiting != nil {
+				_, _ = fmt.Fprintf(out, "  Container %s (pod %s) is waiting: %s\n",
+					ctr.ContainerName, ctr.PodName, ctr.Waiting.Message)
+			}
+		}
+	}
+	return nil
+}
+
+// Show paths relative to the working directory when we can.
+func displayPath(wd string, p string) string {
+	rel, err := filepath.Rel(wd, p)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return p
+	}
+	return rel
+}
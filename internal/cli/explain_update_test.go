@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestExplainUpdate(t *testing.T) {
+	f := newServerFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	err = f.client.Create(f.ctx, &v1alpha1.LiveUpdate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "frontend:frontend-image",
+			Annotations: map[string]string{v1alpha1.AnnotationManifest: "frontend"},
+		},
+		Spec: v1alpha1.LiveUpdateSpec{
+			BasePath: wd,
+			Selector: v1alpha1.LiveUpdateSelector{
+				Kubernetes: &v1alpha1.LiveUpdateKubernetesSelector{
+					DiscoveryName: "frontend",
+					Image:         "frontend-image",
+				},
+			},
+			Syncs: []v1alpha1.LiveUpdateSync{
+				{LocalPath: "src", ContainerPath: "/app/src"},
+			},
+			StopPaths: []string{"package.json"},
+			Execs: []v1alpha1.LiveUpdateExec{
+				{Args: []string{"yarn", "build"}, TriggerPaths: []string{"src/build.js"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newExplainUpdateCmd(streams)
+	cmd.register()
+
+	err = cmd.run(f.ctx, []string{"frontend", "src/build.js", "package.json", "README.md"})
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), `LiveUpdate frontend:frontend-image:
+  src/build.js
+    → sync src to /app/src/build.js
+    → triggers run steps with paths: src/build.js
+  package.json
+    → full rebuild: matches fall_back_on path "package.json"
+  README.md
+    → full rebuild: doesn't match any sync path
+
+Result: Tilt would fall back to a full rebuild.
+`)
+
+	out.Reset()
+	err = cmd.run(f.ctx, []string{"frontend", filepath.Join(wd, "src", "index.js")})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Result: Tilt would wait, because no containers are running yet.")
+
+	err = cmd.run(f.ctx, []string{"backend", "src/index.js"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `resource "backend" has no live_update`)
+	}
+}
@@ -0,0 +1,17 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newTiltfileCmd(streams genericclioptions.IOStreams) *cobra.Command {
+	result := &cobra.Command{
+		Use:   "tiltfile",
+		Short: "Work with Tiltfiles",
+	}
+
+	addCommand(result, newTiltfileTestCmd(streams))
+
+	return result
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/apis/tiltfile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/config"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const noTiltfileArgsHelp = `This Tiltfile doesn't define any args with config.define_*.
+Tiltfile args are the names of resources to run, e.g., "tilt up -- frontend backend".
+`
+
+// Returns true if the Tiltfile args might ask for help, e.g., `tilt up -- --help`.
+//
+// Whether they do depends on the args the Tiltfile defines: in
+// `tilt up -- --name -h`, "-h" may be the value of --name. So this is only
+// a quick check before we ask config.IsHelp.
+func mightBeTiltfileArgsHelp(args []string) bool {
+	for _, arg := range args {
+		if arg == "--help" || arg == "-h" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTiltfileArgsHelp(w io.Writer, args []model.TiltfileArg) {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(w, noTiltfileArgsHelp)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Tiltfile args (pass after \"--\"):\n\n%s", config.Usage(args))
+}
+
+// Executes the Tiltfile until it calls config.parse(), which fails on --help,
+// then prints help for the args it defined.
+//
+// Returns false, and prints nothing, if the args don't ask for help after all.
+func printLocalTiltfileArgsHelp(ctx context.Context, w io.Writer, subcommand model.TiltSubcommand, fileName string, args []string) (bool, error) {
+	// Tiltfile logs are only interesting if the Tiltfile fails before config.parse()
+	l := logger.NewDeferredLogger(ctx)
+	ctx = logger.WithLogger(ctx, l)
+
+	deps, err := wireTiltfileResult(ctx, analytics.Get(ctx), subcommand)
+	if err != nil {
+		l.SetOutput(l.Original())
+		return false, err
+	}
+
+	tlr := deps.tfl.Load(ctx, ctrltiltfile.MainTiltfile(fileName, args), nil)
+	if !config.IsHelp(args, tlr.TiltfileArgs) {
+		return false, nil
+	}
+	if tlr.Error != nil && !isTiltfileArgsHelpError(tlr.Error) {
+		// The Tiltfile failed before it could define its args,
+		// so show what it printed and why it failed.
+		l.SetOutput(l.Original())
+		l.Original().Warnf("Error loading Tiltfile: %v", tlr.Error)
+	}
+
+	writeTiltfileArgsHelp(w, tlr.TiltfileArgs)
+	return true, nil
+}
+
+// Returns true if the Tiltfile only failed because it was asked for help:
+// either config.parse() rejected --help, or the Tiltfile doesn't call
+// config.parse() and there's no resource named "--help".
+func isTiltfileArgsHelpError(err error) bool {
+	var unknownErr config.UnknownResourcesError
+	return errors.Is(err, pflag.ErrHelp) || errors.As(err, &unknownErr)
+}
+
+// Fetches the args defined by the Tiltfile of a running Tilt.
+func getTiltfileArgsSchema() (model.TiltfileArgsSchema, error) {
+	url := apiURL("tiltfile_args_schema")
+	client := &http.Client{Timeout: 5 * time.Second}
+	res, err := client.Get(url)
+	if err != nil {
+		return model.TiltfileArgsSchema{}, fmt.Errorf("Could not connect to Tilt at %s: %v", url, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return model.TiltfileArgsSchema{}, fmt.Errorf("Request to %s failed with status %q", url, res.Status)
+	}
+
+	var schema model.TiltfileArgsSchema
+	err = json.NewDecoder(res.Body).Decode(&schema)
+	if err != nil {
+		return model.TiltfileArgsSchema{}, fmt.Errorf("Decoding response from %s: %v", url, err)
+	}
+	return schema, nil
+}
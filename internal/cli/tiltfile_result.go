@@ -16,6 +16,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/analytics"
 	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/apis/tiltfile"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -34,6 +35,9 @@ type tiltfileResultCmd struct {
 	// for Builtin Timings mode
 	builtinTimings bool
 	durThreshold   time.Duration
+
+	profilePath   string
+	profileFormat string
 }
 
 var _ tiltCmd = &tiltfileResultCmd{}
@@ -69,13 +73,17 @@ Exit code 0: successful Tiltfile evaluation (data printed to stdout)
 Exit code 1: some failure in setup, printing results, etc. (any logs printed to stderr)
 Exit code 5: error when evaluating the Tiltfile, such as syntax error, illegal Tiltfile operation, etc. (any logs printed to stderr)
 
-Run with -v | --verbose to print Tiltfile execution logs on stderr, regardless of whether there was an error.`,
+Run with -v | --verbose to print Tiltfile execution logs on stderr, regardless of whether there was an error.
+
+Run with --profile to write a profile of where Tiltfile execution spent its time, including Starlark functions, load()ed files, and subprocesses run by local(), helm(), and kustomize(). View it with "go tool pprof -http=: <file>", or with --profile-format=speedscope, at https://www.speedscope.app.`,
 	}
 
 	addTiltfileFlag(cmd, &c.fileName)
 	addKubeContextFlag(cmd)
 	cmd.Flags().BoolVarP(&c.builtinTimings, "builtin-timings", "b", false, "If true, print timing data for Tiltfile builtin calls instead of Tiltfile result JSON")
 	cmd.Flags().DurationVar(&c.durThreshold, "dur-threshold", 0, "Only compatible with Builtin Timings mode. Should be a Go duration string. If passed, only print information about builtin calls lasting this duration and longer.")
+	cmd.Flags().StringVar(&c.profilePath, "profile", "", "If set, write a profile of Tiltfile execution to this file")
+	cmd.Flags().StringVar(&c.profileFormat, "profile-format", "pprof", "Format of the --profile file. One of: pprof, speedscope")
 
 	return cmd
 }
@@ -95,6 +103,13 @@ func (c *tiltfileResultCmd) run(ctx context.Context, args []string) error {
 		ctx = logger.WithLogger(ctx, logger.NewLogger(logLvl, c.streams.ErrOut))
 	}
 
+	if c.profilePath != "" {
+		if c.profileFormat != "pprof" && c.profileFormat != "speedscope" {
+			return fmt.Errorf("--profile-format must be one of: pprof, speedscope")
+		}
+		ctx = starkit.WithProfiling(ctx)
+	}
+
 	deps, err := wireTiltfileResult(ctx, analytics.Get(ctx), "alpha tiltfile-result")
 	if err != nil {
 		c.maybePrintDeferredLogsToStderr(ctx, showTiltfileLogs)
@@ -104,6 +119,14 @@ func (c *tiltfileResultCmd) run(ctx context.Context, args []string) error {
 	start := time.Now()
 	tlr := deps.tfl.Load(ctx, ctrltiltfile.MainTiltfile(c.fileName, args), nil)
 	tflDur := time.Since(start)
+
+	if c.profilePath != "" {
+		err := c.writeProfile(tlr.Profile)
+		if err != nil {
+			return errors.Wrap(err, "writing profile")
+		}
+	}
+
 	if tlr.Error != nil {
 		c.maybePrintDeferredLogsToStderr(ctx, showTiltfileLogs)
 
@@ -139,6 +162,24 @@ func (c *tiltfileResultCmd) run(ctx context.Context, args []string) error {
 	return nil
 }
 
+func (c *tiltfileResultCmd) writeProfile(profile starkit.Profile) error {
+	f, err := os.Create(c.profilePath)
+	if err != nil {
+		return err
+	}
+
+	if c.profileFormat == "speedscope" {
+		err = profile.WriteSpeedscope(f, "Tiltfile")
+	} else {
+		err = profile.WritePprof(f)
+	}
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func (c *tiltfileResultCmd) maybePrintDeferredLogsToStderr(ctx context.Context, showTiltfileLogs bool) {
 	if showTiltfileLogs {
 		// We've already printed the logs elsewhere, do nothing
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type tiltfileTestCmd struct {
+	streams genericclioptions.IOStreams
+
+	junitXML   string
+	runPattern string
+}
+
+var _ tiltCmd = &tiltfileTestCmd{}
+
+func newTiltfileTestCmd(streams genericclioptions.IOStreams) *tiltfileTestCmd {
+	return &tiltfileTestCmd{streams: streams}
+}
+
+func (c *tiltfileTestCmd) name() model.TiltSubcommand { return "tiltfile test" }
+
+func (c *tiltfileTestCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [<path>...]",
+		Short: "Run Tiltfile unit tests",
+		Long: `Run Tiltfile unit tests.
+
+Finds files named *_test.tilt under the given paths (by default, the current
+directory), and calls every top-level function whose name starts with test_.
+
+Each test runs in a fresh Tiltfile environment, with a few extra builtins:
+
+  assert.equals, assert.not_equals, assert.true, assert.false,
+  assert.contains, assert.fails
+
+  fake.local, fake.url, fake.k8s_context
+
+Tests never touch the outside world: local() and read_url() fail unless
+they've been faked, and k8s_context() returns a fake context. helm() and
+kustomize() only work with local charts and bases, and docker_compose()
+reads the project without talking to Docker.
+`,
+		Example: `tilt tiltfile test
+tilt tiltfile test ./tilt_modules/my-extension --run 'test_deploy.*'
+tilt tiltfile test --junit-xml=report.xml`,
+	}
+
+	cmd.Flags().StringVar(&c.junitXML, "junit-xml", "", "If set, write a JUnit XML report of the results to this file")
+	cmd.Flags().StringVar(&c.runPattern, "run", "", "Only run tests whose names match this regular expression")
+
+	return cmd
+}
+
+func (c *tiltfileTestCmd) run(ctx context.Context, args []string) error {
+	var filter *regexp.Regexp
+	if c.runPattern != "" {
+		var err error
+		filter, err = regexp.Compile(c.runPattern)
+		if err != nil {
+			return errors.Wrap(err, "--run")
+		}
+	}
+
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := tiltfile.FindTestFiles(paths)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no *%s files found in %s", tiltfile.TestFileSuffix, strings.Join(paths, ", "))
+	}
+
+	runner, err := wireTiltfileTest(ctx, analytics.Get(ctx))
+	if err != nil {
+		return errors.Wrap(err, "wiring dependencies")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var results []tiltfile.TestResult
+	failed := 0
+	for _, file := range files {
+		rel, err := filepath.Rel(cwd, file)
+		if err != nil {
+			rel = file
+		}
+
+		fileResults := runner.RunFile(ctx, file, filter)
+		for _, r := range fileResults {
+			if r.Passed() {
+				continue
+			}
+			failed++
+			fmt.Fprintf(c.streams.Out, "%s\n", r)
+			fmt.Fprintf(c.streams.Out, "    %s\n", rel)
+			for _, line := range strings.Split(strings.TrimRight(r.Output+r.Failure+r.Error, "\n"), "\n") {
+				fmt.Fprintf(c.streams.Out, "    %s\n", line)
+			}
+		}
+		results = append(results, fileResults...)
+	}
+
+	if c.junitXML != "" {
+		err := c.writeJUnitXML(cwd, results)
+		if err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(c.streams.Out, "FAIL (%d of %d tests failed, %.2fs)\n", failed, len(results), time.Since(start).Seconds())
+		return fmt.Errorf("%d Tiltfile tests failed", failed)
+	}
+	fmt.Fprintf(c.streams.Out, "PASS (%d tests, %.2fs)\n", len(results), time.Since(start).Seconds())
+	return nil
+}
+
+func (c *tiltfileTestCmd) writeJUnitXML(cwd string, results []tiltfile.TestResult) error {
+	f, err := os.Create(c.junitXML)
+	if err != nil {
+		return errors.Wrap(err, "--junit-xml")
+	}
+
+	err = tiltfile.WriteJUnitXML(f, cwd, results)
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "writing JUnit XML")
+	}
+	return f.Close()
+}
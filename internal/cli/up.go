@@ -66,7 +66,8 @@ By default:
 2) Running with no Tiltfile args starts all services defined in the Tiltfile
 
 This default behavior does not apply if the Tiltfile uses config.parse or config.set_enabled_resources.
-In that case, see https://docs.tilt.dev/tiltfile_config.html and/or comments in your Tiltfile
+In that case, see https://docs.tilt.dev/tiltfile_config.html and/or comments in your Tiltfile,
+or run "tilt up -- --help" to list the args that the Tiltfile defines.
 
 When you exit Tilt (using Ctrl+C), Kubernetes resources and Docker Compose resources continue running;
 you can use tilt down (https://docs.tilt.dev/cli/tilt_down.html) to delete these resources. Any long-running
@@ -122,6 +123,15 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 		"term_mode":   strconv.Itoa(int(termMode)),
 	})
 
+	if mightBeTiltfileArgsHelp(args) {
+		printedHelp, err := printLocalTiltfileArgsHelp(ctx, os.Stdout, c.name(), c.fileName, args)
+		if printedHelp || err != nil {
+			cmdUpTags["tiltfile_args_help"] = "true"
+			a.Incr("cmd.up", cmdUpTags.AsMap())
+			return err
+		}
+	}
+
 	generateTiltfileResult, err := maybeGenerateTiltfile(c.fileName)
 	// N.B. report the command before handling the error; result enum is always valid
 	cmdUpTags["generate_tiltfile.result"] = string(generateTiltfileResult)
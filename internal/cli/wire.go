@@ -24,8 +24,10 @@ import (
 	"github.com/tilt-dev/tilt/internal/analytics"
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/buildstats"
 	"github.com/tilt-dev/tilt/internal/cloud"
 	"github.com/tilt-dev/tilt/internal/cloud/cloudurl"
+	"github.com/tilt-dev/tilt/internal/clusterprovision"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers"
 	"github.com/tilt-dev/tilt/internal/controllers/core/kubernetesdiscovery"
@@ -48,6 +50,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
 	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/internal/openurl"
@@ -62,6 +65,8 @@ import (
 
 var K8sWireSet = wire.NewSet(
 	k8s.ProvideClusterProduct,
+	k8s.ProvideClusterProfile,
+	k8s.ProvideClusterConfig,
 	k8s.ProvideClusterName,
 	k8s.ProvideKubeContext,
 	k8s.ProvideAPIConfig,
@@ -74,7 +79,8 @@ var K8sWireSet = wire.NewSet(
 	k8s.ProvideServerVersion,
 	k8s.ProvideK8sClient,
 	ProvideKubeContextOverride,
-	ProvideNamespaceOverride)
+	ProvideNamespaceOverride,
+	xdg.NewTiltDevBase)
 
 var BaseWireSet = wire.NewSet(
 	K8sWireSet,
@@ -144,7 +150,6 @@ var BaseWireSet = wire.NewSet(
 	wire.Bind(new(tracer.SpanSource), new(*tracer.SpanCollector)),
 
 	dirs.UseTiltDevDir,
-	xdg.NewTiltDevBase,
 	token.GetOrCreateToken,
 
 	build.NewKINDLoader,
@@ -167,6 +172,11 @@ func wireTiltfileResult(ctx context.Context, analytics *analytics.TiltAnalytics,
 	return cmdTiltfileResultDeps{}, nil
 }
 
+func wireTiltfileTest(ctx context.Context, analytics *analytics.TiltAnalytics) (*tiltfile.TestRunner, error) {
+	wire.Build(UpWireSet)
+	return nil, nil
+}
+
 func wireDockerPrune(ctx context.Context, analytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (dpDeps, error) {
 	wire.Build(UpWireSet, newDPDeps)
 	return dpDeps{}, nil
@@ -290,22 +300,28 @@ func wireDownDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, s
 }
 
 type DownDeps struct {
-	tfl      tiltfile.TiltfileLoader
-	dcClient dockercompose.DockerComposeClient
-	kClient  k8s.Client
-	execer   localexec.Execer
+	tfl        tiltfile.TiltfileLoader
+	dcClient   dockercompose.DockerComposeClient
+	kClient    k8s.Client
+	execer      localexec.Execer
+	helmClient  helm.ReleaseClient
+	provisioner clusterprovision.Provisioner
 }
 
 func ProvideDownDeps(
 	tfl tiltfile.TiltfileLoader,
 	dcClient dockercompose.DockerComposeClient,
 	kClient k8s.Client,
-	execer localexec.Execer) DownDeps {
+	execer localexec.Execer,
+	helmClient helm.ReleaseClient,
+	provisioner clusterprovision.Provisioner) DownDeps {
 	return DownDeps{
-		tfl:      tfl,
-		dcClient: dcClient,
-		kClient:  kClient,
-		execer:   execer,
+		tfl:         tfl,
+		dcClient:    dcClient,
+		kClient:     kClient,
+		execer:      execer,
+		helmClient:  helmClient,
+		provisioner: provisioner,
 	}
 }
 
@@ -347,6 +363,11 @@ func wireAnalytics(l logger.Logger, cmdName model.TiltSubcommand) (*tiltanalytic
 	return nil, nil
 }
 
+func wireBuildStatsHistory() *buildstats.History {
+	wire.Build(xdg.NewTiltDevBase, buildstats.NewHistory)
+	return nil
+}
+
 func wireClientGetter(ctx context.Context) (*cliclient.Getter, error) {
 	wire.Build(CLIClientWireSet)
 	return nil, nil
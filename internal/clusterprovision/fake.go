@@ -0,0 +1,65 @@
+package clusterprovision
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+type FakeProvisioner struct {
+	mu sync.Mutex
+
+	// Clusters that exist, by kubeconfig context.
+	Clusters map[string]v1alpha1.ClusterProvision
+
+	EnsureError error
+	EnsureCount int
+
+	// If set, Ensure waits for it to be closed, like a cluster
+	// that takes a while to start.
+	Ready <-chan struct{}
+}
+
+var _ Provisioner = &FakeProvisioner{}
+
+func NewFakeProvisioner() *FakeProvisioner {
+	return &FakeProvisioner{Clusters: make(map[string]v1alpha1.ClusterProvision)}
+}
+
+func (p *FakeProvisioner) Ensure(ctx context.Context, spec v1alpha1.ClusterProvision) (Result, error) {
+	p.mu.Lock()
+	p.EnsureCount++
+	ready := p.Ready
+	p.mu.Unlock()
+
+	if ready != nil {
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return Result{}, ctx.Err()
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.EnsureError != nil {
+		return Result{}, p.EnsureError
+	}
+
+	p.Clusters[KubeContext(spec)] = spec
+	result := Result{Context: KubeContext(spec)}
+	if spec.Registry != nil {
+		result.Registry = registryHosting(spec)
+	}
+	return result, nil
+}
+
+func (p *FakeProvisioner) Delete(ctx context.Context, spec v1alpha1.ClusterProvision) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.Clusters, KubeContext(spec))
+	return nil
+}
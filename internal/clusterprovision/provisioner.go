@@ -0,0 +1,421 @@
+package clusterprovision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tilt-dev/localregistry-go"
+
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const (
+	defaultClusterName  = "tilt"
+	defaultRegistryPort = 5005
+
+	// How long kind and k3d wait for the cluster to be ready.
+	readyTimeout = "5m"
+)
+
+// The port that the registry:2 image listens on.
+const registryContainerPort = 5000
+
+// Result describes a cluster that's ready to use.
+type Result struct {
+	// The kubeconfig context of the cluster.
+	Context string
+
+	// The local registry connected to the cluster, if any.
+	Registry *localregistry.LocalRegistryHostingV1
+}
+
+// Provisioner creates and deletes local clusters.
+type Provisioner interface {
+	// Creates the cluster if it doesn't exist, and waits for it to be ready.
+	Ensure(ctx context.Context, spec v1alpha1.ClusterProvision) (Result, error)
+
+	// Deletes the cluster, and the registry that Tilt created for it.
+	Delete(ctx context.Context, spec v1alpha1.ClusterProvision) error
+}
+
+// Provisions clusters with the kind, k3d, and docker CLIs.
+type CLIProvisioner struct {
+	execer localexec.Execer
+}
+
+var _ Provisioner = &CLIProvisioner{}
+
+func NewProvisioner(execer localexec.Execer) *CLIProvisioner {
+	return &CLIProvisioner{execer: execer}
+}
+
+// The name of the cluster, with defaults filled in.
+func ClusterName(spec v1alpha1.ClusterProvision) string {
+	if spec.Name != "" {
+		return spec.Name
+	}
+	return defaultClusterName
+}
+
+// The kubeconfig context that kind and k3d write for the cluster.
+func KubeContext(spec v1alpha1.ClusterProvision) string {
+	return fmt.Sprintf("%s-%s", spec.Product, ClusterName(spec))
+}
+
+func registryName(spec v1alpha1.ClusterProvision) string {
+	if spec.Registry.Name != "" {
+		return spec.Registry.Name
+	}
+	return fmt.Sprintf("%s-registry", ClusterName(spec))
+}
+
+func registryPort(spec v1alpha1.ClusterProvision) int {
+	if spec.Registry.Port != 0 {
+		return int(spec.Registry.Port)
+	}
+	return defaultRegistryPort
+}
+
+func nodeCount(spec v1alpha1.ClusterProvision) int {
+	if spec.Nodes > 0 {
+		return int(spec.Nodes)
+	}
+	return 1
+}
+
+// kind and k3d both tag their node images with a v-prefixed version.
+func normalizeVersion(v string) string {
+	if v == "" || strings.HasPrefix(v, "v") {
+		return v
+	}
+	return "v" + v
+}
+
+func (p *CLIProvisioner) Ensure(ctx context.Context, spec v1alpha1.ClusterProvision) (Result, error) {
+	result := Result{Context: KubeContext(spec)}
+	var err error
+	switch spec.Product {
+	case v1alpha1.ClusterProvisionProductKind:
+		err = p.ensureKind(ctx, spec)
+	case v1alpha1.ClusterProvisionProductK3d:
+		err = p.ensureK3d(ctx, spec)
+	default:
+		err = fmt.Errorf("unsupported cluster product %q", spec.Product)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	if spec.Registry != nil {
+		result.Registry = registryHosting(spec)
+	}
+	return result, nil
+}
+
+func (p *CLIProvisioner) Delete(ctx context.Context, spec v1alpha1.ClusterProvision) error {
+	name := ClusterName(spec)
+	switch spec.Product {
+	case v1alpha1.ClusterProvisionProductKind:
+		err := p.run(ctx, model.Cmd{Argv: []string{"kind", "delete", "cluster", "--name", name}}, nil)
+		if err != nil {
+			return fmt.Errorf("deleting kind cluster %s: %v", name, err)
+		}
+		if spec.Registry != nil {
+			// kind doesn't know about the registry, so we clean it up ourselves.
+			err := p.run(ctx, model.Cmd{Argv: []string{"docker", "rm", "--force", registryName(spec)}}, nil)
+			if err != nil {
+				return fmt.Errorf("deleting registry %s: %v", registryName(spec), err)
+			}
+		}
+		return nil
+	case v1alpha1.ClusterProvisionProductK3d:
+		// k3d deletes the registries it created along with the cluster.
+		err := p.run(ctx, model.Cmd{Argv: []string{"k3d", "cluster", "delete", name}}, nil)
+		if err != nil {
+			return fmt.Errorf("deleting k3d cluster %s: %v", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported cluster product %q", spec.Product)
+}
+
+func (p *CLIProvisioner) ensureKind(ctx context.Context, spec v1alpha1.ClusterProvision) error {
+	name := ClusterName(spec)
+	out, err := p.output(ctx, model.Cmd{Argv: []string{"kind", "get", "clusters"}})
+	if err != nil {
+		return fmt.Errorf("listing kind clusters: %v", err)
+	}
+
+	if spec.Registry != nil {
+		err := p.ensureRegistryContainer(ctx, spec)
+		if err != nil {
+			return err
+		}
+	}
+
+	if containsLine(out, name) {
+		warnIfChanged(ctx, spec, p.inspectKind(ctx, spec))
+	} else {
+		logger.Get(ctx).Infof("Creating kind cluster %s", name)
+		argv := []string{"kind", "create", "cluster", "--name", name, "--config", "-", "--wait", readyTimeout}
+		if v := normalizeVersion(spec.KubernetesVersion); v != "" {
+			argv = append(argv, "--image", fmt.Sprintf("kindest/node:%s", v))
+		}
+		err := p.run(ctx, model.Cmd{Argv: argv}, strings.NewReader(kindConfig(spec)))
+		if err != nil {
+			return fmt.Errorf("creating kind cluster %s: %v", name, err)
+		}
+	}
+
+	if spec.Registry != nil {
+		// Put the registry on the kind network, so that the nodes can pull
+		// from it by name. Fails harmlessly if it's already connected.
+		_, _ = p.output(ctx, model.Cmd{Argv: []string{"docker", "network", "connect", "kind", registryName(spec)}})
+	}
+	return nil
+}
+
+// The kind cluster config, with the nodes and the registry mirror.
+//
+// https://kind.sigs.k8s.io/docs/user/local-registry/
+func kindConfig(spec v1alpha1.ClusterProvision) string {
+	var b strings.Builder
+	b.WriteString("kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n- role: control-plane\n")
+	for i := 1; i < nodeCount(spec); i++ {
+		b.WriteString("- role: worker\n")
+	}
+	if spec.Registry != nil {
+		name := registryName(spec)
+		fmt.Fprintf(&b, "containerdConfigPatches:\n- |-\n"+
+			"  [plugins.\"io.containerd.grpc.v1.cri\".registry.mirrors.\"localhost:%d\"]\n"+
+			"    endpoint = [\"http://%s:%d\"]\n",
+			registryPort(spec), name, registryContainerPort)
+	}
+	return b.String()
+}
+
+// Makes sure the registry container for a kind cluster exists and is running.
+func (p *CLIProvisioner) ensureRegistryContainer(ctx context.Context, spec v1alpha1.ClusterProvision) error {
+	name := registryName(spec)
+	out, err := p.output(ctx, model.Cmd{Argv: []string{
+		"docker", "ps", "--all", "--filter", fmt.Sprintf("name=^%s$", name), "--format", "{{.State}}",
+	}})
+	if err != nil {
+		return fmt.Errorf("looking up registry %s: %v", name, err)
+	}
+
+	switch strings.TrimSpace(out) {
+	case "running":
+		return nil
+	case "":
+		logger.Get(ctx).Infof("Creating registry %s", name)
+		err = p.run(ctx, model.Cmd{Argv: []string{
+			"docker", "run", "--detach", "--restart=always",
+			"--publish", fmt.Sprintf("127.0.0.1:%d:%d", registryPort(spec), registryContainerPort),
+			"--name", name, "registry:2",
+		}}, nil)
+	default:
+		err = p.run(ctx, model.Cmd{Argv: []string{"docker", "start", name}}, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("starting registry %s: %v", name, err)
+	}
+	return nil
+}
+
+func (p *CLIProvisioner) ensureK3d(ctx context.Context, spec v1alpha1.ClusterProvision) error {
+	name := ClusterName(spec)
+	out, err := p.output(ctx, model.Cmd{Argv: []string{"k3d", "cluster", "list", "--output", "json"}})
+	if err != nil {
+		return fmt.Errorf("listing k3d clusters: %v", err)
+	}
+
+	var clusters []struct {
+		Name  string `json:"name"`
+		Nodes []struct {
+			Role  string `json:"role"`
+			Image string `json:"image"`
+		} `json:"nodes"`
+	}
+	err = json.Unmarshal([]byte(out), &clusters)
+	if err != nil {
+		return fmt.Errorf("listing k3d clusters: %v", err)
+	}
+	for _, c := range clusters {
+		if c.Name != name {
+			continue
+		}
+
+		existing := existingCluster{}
+		for _, n := range c.Nodes {
+			switch n.Role {
+			case "server", "agent":
+				existing.nodes++
+				existing.version = imageTag(n.Image)
+			case "registry":
+				existing.hasRegistry = true
+			}
+		}
+		warnIfChanged(ctx, spec, &existing)
+		return nil
+	}
+
+	logger.Get(ctx).Infof("Creating k3d cluster %s", name)
+	argv := []string{"k3d", "cluster", "create", name,
+		"--servers", "1",
+		"--agents", fmt.Sprintf("%d", nodeCount(spec)-1),
+		"--wait", "--timeout", readyTimeout,
+	}
+	if v := normalizeVersion(spec.KubernetesVersion); v != "" {
+		argv = append(argv, "--image", fmt.Sprintf("rancher/k3s:%s-k3s1", v))
+	}
+	if spec.Registry != nil {
+		argv = append(argv, "--registry-create", fmt.Sprintf("%s:127.0.0.1:%d", registryName(spec), registryPort(spec)))
+	}
+	err = p.run(ctx, model.Cmd{Argv: argv}, nil)
+	if err != nil {
+		return fmt.Errorf("creating k3d cluster %s: %v", name, err)
+	}
+	return nil
+}
+
+// What we know about a cluster that already exists.
+type existingCluster struct {
+	nodes int
+
+	// The tag of the node image, e.g., "v1.27.3" for kind
+	// or "v1.27.3-k3s1" for k3d. Empty if we don't know it.
+	version string
+
+	hasRegistry bool
+}
+
+// Finds the nodes of a kind cluster, and whether the nodes pull from the
+// registry. Returns nil if we can't tell.
+func (p *CLIProvisioner) inspectKind(ctx context.Context, spec v1alpha1.ClusterProvision) *existingCluster {
+	name := ClusterName(spec)
+	out, err := p.output(ctx, model.Cmd{Argv: []string{
+		"docker", "ps", "--all", "--filter", fmt.Sprintf("label=io.x-k8s.kind.cluster=%s", name), "--format", "{{.Image}}",
+	}})
+	if err != nil {
+		logger.Get(ctx).Debugf("Inspecting kind cluster %s: %v", name, err)
+		return nil
+	}
+
+	existing := &existingCluster{}
+	for _, image := range strings.Split(strings.TrimSpace(out), "\n") {
+		if image != "" {
+			existing.nodes++
+			existing.version = imageTag(image)
+		}
+	}
+
+	if spec.Registry != nil {
+		// The registry mirror is in the containerd config of each node.
+		config, err := p.output(ctx, model.Cmd{Argv: []string{
+			"docker", "exec", fmt.Sprintf("%s-control-plane", name), "cat", "/etc/containerd/config.toml",
+		}})
+		if err != nil {
+			logger.Get(ctx).Debugf("Inspecting kind cluster %s: %v", name, err)
+			return nil
+		}
+		existing.hasRegistry = strings.Contains(config, fmt.Sprintf("registry.mirrors.\"localhost:%d\"", registryPort(spec)))
+	}
+	return existing
+}
+
+// The tag of an image ref, without the digest.
+func imageTag(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	return image[i+1:]
+}
+
+// We never change a cluster that already exists, so if the spec changed,
+// tell the user how to re-create it.
+func warnIfChanged(ctx context.Context, spec v1alpha1.ClusterProvision, existing *existingCluster) {
+	if existing == nil || existing.nodes == 0 {
+		// We couldn't find the nodes, so we can't tell.
+		return
+	}
+
+	var changes []string
+	if existing.nodes != nodeCount(spec) {
+		changes = append(changes, fmt.Sprintf("it has %d node(s), not %d", existing.nodes, nodeCount(spec)))
+	}
+	if v := normalizeVersion(spec.KubernetesVersion); v != "" && existing.version != "" &&
+		existing.version != v && !strings.HasPrefix(existing.version, v+"-") {
+		changes = append(changes, fmt.Sprintf("it runs Kubernetes %s, not %s", existing.version, v))
+	}
+	if spec.Registry != nil && !existing.hasRegistry {
+		changes = append(changes, "it doesn't use the registry")
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	logger.Get(ctx).Warnf("%s cluster %s already exists, but doesn't match the Tiltfile: %s.\n"+
+		"Tilt doesn't change existing clusters. To re-create it, run `tilt down --delete-cluster`, then `tilt up`.",
+		spec.Product, ClusterName(spec), strings.Join(changes, ", "))
+}
+
+// How tools should reach the registry, in the format of the local registry
+// hosting standard.
+func registryHosting(spec v1alpha1.ClusterProvision) *localregistry.LocalRegistryHostingV1 {
+	port := registryPort(spec)
+	hosting := &localregistry.LocalRegistryHostingV1{
+		Host:                   fmt.Sprintf("localhost:%d", port),
+		HostFromClusterNetwork: fmt.Sprintf("%s:%d", registryName(spec), registryContainerPort),
+		Help:                   "https://docs.tilt.dev/choosing_clusters.html",
+	}
+	if spec.Product == v1alpha1.ClusterProvisionProductK3d {
+		// k3d's registry listens on the published port on both networks.
+		hosting.HostFromClusterNetwork = fmt.Sprintf("%s:%d", registryName(spec), port)
+	}
+	return hosting
+}
+
+// Runs the command, streaming its output to the logger.
+func (p *CLIProvisioner) run(ctx context.Context, cmd model.Cmd, stdin io.Reader) error {
+	l := logger.Get(ctx)
+	out := l.Writer(logger.InfoLvl)
+	runIO := localexec.RunIO{Stdin: stdin, Stdout: out, Stderr: out}
+
+	l.Infof("Running cmd: %s", cmd.String())
+	exitCode, err := p.execer.Run(ctx, cmd, runIO)
+	if err == nil && exitCode != 0 {
+		err = fmt.Errorf("exit status %d", exitCode)
+	}
+	return err
+}
+
+// Runs the command and returns its stdout.
+func (p *CLIProvisioner) output(ctx context.Context, cmd model.Cmd) (string, error) {
+	result, err := localexec.OneShot(ctx, p.execer, cmd)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("%s: exit status %d: %s", cmd.String(), result.ExitCode,
+			strings.TrimSpace(string(result.Stderr)))
+	}
+	return string(result.Stdout), nil
+}
+
+func containsLine(s, line string) bool {
+	for _, l := range strings.Split(s, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package clusterprovision
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestKindCreate(t *testing.T) {
+	f := newFixture(t)
+	f.execer.RegisterCommand("kind get clusters", 0, "other", "")
+
+	result, err := f.p.Ensure(f.ctx, v1alpha1.ClusterProvision{
+		Product:           v1alpha1.ClusterProvisionProductKind,
+		Nodes:             2,
+		KubernetesVersion: "1.27.3",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kind-tilt", result.Context)
+	assert.Nil(t, result.Registry)
+	assert.Equal(t, []string{
+		"kind get clusters",
+		"kind create cluster --name tilt --config - --wait 5m --image kindest/node:v1.27.3",
+	}, f.calls())
+}
+
+func TestKindExists(t *testing.T) {
+	f := newFixture(t)
+	f.execer.RegisterCommand("kind get clusters", 0, "tilt", "")
+	f.execer.RegisterCommand(kindNodesCmd, 0, "kindest/node:v1.27.3@sha256:abc\n", "")
+
+	_, err := f.p.Ensure(f.ctx, v1alpha1.ClusterProvision{
+		Product:           v1alpha1.ClusterProvisionProductKind,
+		KubernetesVersion: "1.27.3",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind get clusters", kindNodesCmd}, f.calls())
+	assert.NotContains(t, f.out.String(), "doesn't match")
+}
+
+func TestKindExistsWithDifferentSpec(t *testing.T) {
+	f := newFixture(t)
+	f.execer.RegisterCommand("kind get clusters", 0, "tilt", "")
+	f.execer.RegisterCommand("docker ps --all --filter name=^tilt-registry$ --format {{.State}}", 0, "running", "")
+	f.execer.RegisterCommand(kindNodesCmd, 0, "kindest/node:v1.25.0\n", "")
+	f.execer.RegisterCommand("docker exec tilt-control-plane cat /etc/containerd/config.toml", 0, "version = 2\n", "")
+
+	_, err := f.p.Ensure(f.ctx, v1alpha1.ClusterProvision{
+		Product:           v1alpha1.ClusterProvisionProductKind,
+		Nodes:             2,
+		KubernetesVersion: "1.27.3",
+		Registry:          &v1alpha1.ClusterProvisionRegistry{},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, f.out.String(),
+		"kind cluster tilt already exists, but doesn't match the Tiltfile: "+
+			"it has 1 node(s), not 2, it runs Kubernetes v1.25.0, not v1.27.3, it doesn't use the registry.")
+	assert.Contains(t, f.out.String(), "tilt down --delete-cluster")
+	assert.NotContains(t, f.calls(), "kind create cluster --name tilt --config - --wait 5m --image kindest/node:v1.27.3")
+}
+
+func TestKindExistsWithRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.execer.RegisterCommand("kind get clusters", 0, "tilt", "")
+	f.execer.RegisterCommand("docker ps --all --filter name=^tilt-registry$ --format {{.State}}", 0, "running", "")
+	f.execer.RegisterCommand(kindNodesCmd, 0, "kindest/node:v1.27.3\n", "")
+	f.execer.RegisterCommand("docker exec tilt-control-plane cat /etc/containerd/config.toml", 0,
+		kindConfig(v1alpha1.ClusterProvision{Registry: &v1alpha1.ClusterProvisionRegistry{}}), "")
+
+	_, err := f.p.Ensure(f.ctx, v1alpha1.ClusterProvision{
+		Product:  v1alpha1.ClusterProvisionProductKind,
+		Registry: &v1alpha1.ClusterProvisionRegistry{},
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, f.out.String(), "doesn't match")
+}
+
+func TestKindRegistry(t *testing.T) {
+	f := newFixture(t)
+	spec := v1alpha1.ClusterProvision{
+		Product:  v1alpha1.ClusterProvisionProductKind,
+		Name:     "dev",
+		Registry: &v1alpha1.ClusterProvisionRegistry{Port: 5001},
+	}
+
+	result, err := f.p.Ensure(f.ctx, spec)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"kind get clusters",
+		"docker ps --all --filter name=^dev-registry$ --format {{.State}}",
+		"docker run --detach --restart=always --publish 127.0.0.1:5001:5000 --name dev-registry registry:2",
+		"kind create cluster --name dev --config - --wait 5m",
+		"docker network connect kind dev-registry",
+	}, f.calls())
+	if assert.NotNil(t, result.Registry) {
+		assert.Equal(t, "localhost:5001", result.Registry.Host)
+		assert.Equal(t, "dev-registry:5000", result.Registry.HostFromClusterNetwork)
+	}
+
+	assert.Contains(t, kindConfig(spec), `registry.mirrors."localhost:5001"]`)
+	assert.Contains(t, kindConfig(spec), `endpoint = ["http://dev-registry:5000"]`)
+}
+
+func TestKindRegistryStopped(t *testing.T) {
+	f := newFixture(t)
+	f.execer.RegisterCommand("kind get clusters", 0, "tilt", "")
+	f.execer.RegisterCommand("docker ps --all --filter name=^tilt-registry$ --format {{.State}}", 0, "exited", "")
+
+	_, err := f.p.Ensure(f.ctx, v1alpha1.ClusterProvision{
+		Product:  v1alpha1.ClusterProvisionProductKind,
+		Registry: &v1alpha1.ClusterProvisionRegistry{},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, f.calls(), "docker start tilt-registry")
+}
+
+func TestKindCreateError(t *testing.T) {
+	f := newFixture(t)
+	f.execer.RegisterCommand("kind create cluster --name tilt --config - --wait 5m", 1, "", "node failed to start")
+
+	_, err := f.p.Ensure(f.ctx, v1alpha1.ClusterProvision{Product: v1alpha1.ClusterProvisionProductKind})
+	assert.EqualError(t, err, "creating kind cluster tilt: exit status 1")
+}
+
+func TestK3dCreate(t *testing.T) {
+	f := newFixture(t)
+	f.execer.RegisterCommand("k3d cluster list --output json", 0, `[{"name":"other"}]`, "")
+
+	result, err := f.p.Ensure(f.ctx, v1alpha1.ClusterProvision{
+		Product:           v1alpha1.ClusterProvisionProductK3d,
+		Nodes:             3,
+		KubernetesVersion: "v1.27.3",
+		Registry:          &v1alpha1.ClusterProvisionRegistry{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "k3d-tilt", result.Context)
+	assert.Equal(t, []string{
+		"k3d cluster list --output json",
+		"k3d cluster create tilt --servers 1 --agents 2 --wait --timeout 5m --image rancher/k3s:v1.27.3-k3s1 --registry-create tilt-registry:127.0.0.1:5005",
+	}, f.calls())
+	if assert.NotNil(t, result.Registry) {
+		assert.Equal(t, "tilt-registry:5005", result.Registry.HostFromClusterNetwork)
+	}
+}
+
+func TestK3dExists(t *testing.T) {
+	f := newFixture(t)
+	f.execer.RegisterCommand("k3d cluster list --output json", 0, `[{"name":"tilt"}]`, "")
+
+	_, err := f.p.Ensure(f.ctx, v1alpha1.ClusterProvision{Product: v1alpha1.ClusterProvisionProductK3d})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k3d cluster list --output json"}, f.calls())
+}
+
+func TestK3dExistsWithDifferentSpec(t *testing.T) {
+	f := newFixture(t)
+	f.execer.RegisterCommand("k3d cluster list --output json", 0, `[{"name":"tilt","nodes":[
+		{"role":"server","image":"docker.io/rancher/k3s:v1.27.3-k3s1"},
+		{"role":"loadbalancer","image":"ghcr.io/k3d-io/k3d-proxy:5.4.6"}
+	]}]`, "")
+
+	_, err := f.p.Ensure(f.ctx, v1alpha1.ClusterProvision{
+		Product:           v1alpha1.ClusterProvisionProductK3d,
+		Nodes:             3,
+		KubernetesVersion: "1.27.3",
+		Registry:          &v1alpha1.ClusterProvisionRegistry{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k3d cluster list --output json"}, f.calls())
+	assert.Contains(t, f.out.String(),
+		"k3d cluster tilt already exists, but doesn't match the Tiltfile: it has 1 node(s), not 3, it doesn't use the registry.")
+}
+
+func TestDelete(t *testing.T) {
+	f := newFixture(t)
+	err := f.p.Delete(f.ctx, v1alpha1.ClusterProvision{
+		Product:  v1alpha1.ClusterProvisionProductKind,
+		Registry: &v1alpha1.ClusterProvisionRegistry{},
+	})
+	require.NoError(t, err)
+	err = f.p.Delete(f.ctx, v1alpha1.ClusterProvision{Product: v1alpha1.ClusterProvisionProductK3d, Name: "dev"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"kind delete cluster --name tilt",
+		"docker rm --force tilt-registry",
+		"k3d cluster delete dev",
+	}, f.calls())
+}
+
+func TestRegistryConfigMap(t *testing.T) {
+	hosting := registryHosting(v1alpha1.ClusterProvision{
+		Product:  v1alpha1.ClusterProvisionProductKind,
+		Registry: &v1alpha1.ClusterProvisionRegistry{},
+	})
+	entity, err := RegistryConfigMap(*hosting)
+	require.NoError(t, err)
+	assert.Equal(t, "local-registry-hosting", entity.Name())
+	assert.Equal(t, "kube-public", entity.Namespace().String())
+}
+
+const kindNodesCmd = "docker ps --all --filter label=io.x-k8s.kind.cluster=tilt --format {{.Image}}"
+
+type fixture struct {
+	ctx    context.Context
+	out    *bytes.Buffer
+	execer *localexec.FakeExecer
+	p      *CLIProvisioner
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	out := &bytes.Buffer{}
+	ctx = logger.WithLogger(ctx, logger.NewTestLogger(out))
+	execer := localexec.NewFakeExecer(t)
+	execer.RegisterCommand("k3d cluster list --output json", 0, "[]", "")
+	return &fixture{
+		ctx:    ctx,
+		out:    out,
+		execer: execer,
+		p:      NewProvisioner(execer),
+	}
+}
+
+func (f *fixture) calls() []string {
+	var result []string
+	for _, c := range f.execer.Calls() {
+		result = append(result, c.Cmd.String())
+	}
+	return result
+}
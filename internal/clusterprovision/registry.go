@@ -0,0 +1,44 @@
+package clusterprovision
+
+import (
+	"fmt"
+
+	"github.com/tilt-dev/localregistry-go"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// RegistryConfigMap advertises the registry in the cluster, so that
+// other tools can discover it.
+//
+// https://github.com/kubernetes/enhancements/tree/master/keps/sig-cluster-lifecycle/generic/1755-communicating-a-local-registry
+func RegistryConfigMap(hosting localregistry.LocalRegistryHostingV1) (k8s.K8sEntity, error) {
+	data, err := yaml.Marshal(hosting)
+	if err != nil {
+		return k8s.K8sEntity{}, fmt.Errorf("encoding local registry hosting: %v", err)
+	}
+	return k8s.NewK8sEntity(&v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      localregistry.ConfigMapName,
+			Namespace: localregistry.ConfigMapNamespace,
+		},
+		Data: map[string]string{
+			localregistry.ConfigMapField: string(data),
+		},
+	}), nil
+}
+
+// RegistryHosting converts the registry hosting standard into Tilt's API.
+func RegistryHosting(hosting localregistry.LocalRegistryHostingV1) *v1alpha1.RegistryHosting {
+	return &v1alpha1.RegistryHosting{
+		Host:                     hosting.Host,
+		HostFromClusterNetwork:   hosting.HostFromClusterNetwork,
+		HostFromContainerRuntime: hosting.HostFromContainerRuntime,
+		Help:                     hosting.Help,
+	}
+}
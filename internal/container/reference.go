@@ -6,6 +6,7 @@ import (
 	"path"
 
 	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -192,4 +193,12 @@ type TaggedRefs struct {
 	//
 	// TODO(milas): Rename to ContainerRuntimeRef
 	ClusterRef reference.NamedTagged
+
+	// Digest is the registry digest of the image manifest (or the image index,
+	// for multi-platform images). Only set if the builder pushed the image.
+	Digest digest.Digest
+
+	// PlatformDigests is the digest of each platform-specific image
+	// in a multi-platform image index.
+	PlatformDigests []v1alpha1.ImageMapPlatformDigest
 }
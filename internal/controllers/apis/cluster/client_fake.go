@@ -147,20 +147,36 @@ func (f *FakeClientProvider) EnsureK8sCluster(
 		kCli = fakeCli
 	}
 
-	f.upsertClusterStatus(ctx, clusterNN,
-		v1alpha1.ClusterStatus{
-			Arch:        "amd64",
-			Version:     "1.23.5",
-			ConnectedAt: &rev,
-			Connection: &v1alpha1.ClusterConnectionStatus{
-				Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{
-					Product: "kind",
-				},
-			},
-		})
+	f.upsertClusterStatus(ctx, clusterNN, connectedStatus(rev))
 	return kCli.(*k8s.FakeK8sClient), rev
 }
 
+// ReconnectK8sCluster simulates the cluster recovering from a connection
+// loss: the client stays the same, but the connection revision moves forward.
+func (f *FakeClientProvider) ReconnectK8sCluster(
+	ctx context.Context,
+	clusterNN types.NamespacedName,
+) metav1.MicroTime {
+	f.t.Helper()
+
+	rev := f.SetK8sClient(clusterNN, f.MustK8sClient(clusterNN))
+	f.upsertClusterStatus(ctx, clusterNN, connectedStatus(rev))
+	return rev
+}
+
+func connectedStatus(rev metav1.MicroTime) v1alpha1.ClusterStatus {
+	return v1alpha1.ClusterStatus{
+		Arch:        "amd64",
+		Version:     "1.23.5",
+		ConnectedAt: &rev,
+		Connection: &v1alpha1.ClusterConnectionStatus{
+			Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{
+				Product: "kind",
+			},
+		},
+	}
+}
+
 func (f *FakeClientProvider) upsertClusterStatus(ctx context.Context, clusterNN types.NamespacedName,
 	status v1alpha1.ClusterStatus) {
 	f.t.Helper()
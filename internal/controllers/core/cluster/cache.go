@@ -39,10 +39,15 @@ type connection struct {
 
 	// createdAt is when the connection object was created.
 	// If initError is empty, it's effectively the time we connected to the
-	// cluster. Otherwise, it's when we _attempted_ to initialize the client
-	// and is used for retry/backoff.
+	// cluster (or last reconnected after failing health checks). Otherwise,
+	// it's when we _attempted_ to initialize the client and is used for
+	// retry/backoff.
 	createdAt time.Time
 
+	// reconnectedAt is the last time the cluster came back after
+	// failing health checks, if it ever did.
+	reconnectedAt time.Time
+
 	// initError is populated when the client cannot be instantiated.
 	// For example, if there's no ~/.kube/config, a Kubernetes client
 	// can't be created.
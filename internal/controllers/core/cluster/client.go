@@ -5,6 +5,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/xdg"
 )
 
 type KubernetesClientFactory interface {
@@ -27,6 +28,13 @@ func (d DockerClientFunc) New(ctx context.Context, env docker.Env) (docker.Clien
 	return d(ctx, env)
 }
 
+// Creates Kubernetes clients that read the user's clusters.yaml from the given base.
+func ProvideKubernetesClientFactory(base xdg.Base) KubernetesClientFactory {
+	return KubernetesClientFunc(func(ctx context.Context, contextOverride k8s.KubeContextOverride, namespaceOverride k8s.NamespaceOverride) (k8s.Client, error) {
+		return KubernetesClientFromEnv(ctx, base, contextOverride, namespaceOverride)
+	})
+}
+
 func DockerClientFromEnv(ctx context.Context, env docker.Env) (docker.Client, error) {
 	client := docker.NewDockerClient(ctx, env)
 	err := client.CheckConnected()
@@ -43,13 +51,15 @@ func DockerClientFromEnv(ctx context.Context, env docker.Env) (docker.Client, er
 //
 // If you have to edit the below, it's easier to let wire generate the
 // factory code for you, then adapt it here.
-func KubernetesClientFromEnv(ctx context.Context, contextOverride k8s.KubeContextOverride, namespaceOverride k8s.NamespaceOverride) (k8s.Client, error) {
+func KubernetesClientFromEnv(ctx context.Context, base xdg.Base, contextOverride k8s.KubeContextOverride, namespaceOverride k8s.NamespaceOverride) (k8s.Client, error) {
 	clientConfig := k8s.ProvideClientConfig(contextOverride, namespaceOverride)
 	apiConfigOrError := k8s.ProvideAPIConfig(clientConfig, contextOverride, namespaceOverride)
 	if apiConfigOrError.Error != nil {
 		return nil, apiConfigOrError.Error
 	}
-	env := k8s.ProvideClusterProduct(apiConfigOrError)
+	clusterConfig := k8s.ProvideClusterConfig(base)
+	clusterProfile := k8s.ProvideClusterProfile(ctx, apiConfigOrError, clusterConfig)
+	env := k8s.ProvideClusterProduct(clusterProfile)
 	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
 
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
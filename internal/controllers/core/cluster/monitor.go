@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/jonboulle/clockwork"
 	"k8s.io/apimachinery/pkg/types"
@@ -62,12 +63,35 @@ func (c *clusterHealthMonitor) UpdateStatus(ctx context.Context, clusterNN types
 		if m.error == error {
 			return
 		}
+		if m.error == "" {
+			m.unhealthySince = c.clock.Now()
+		} else if error == "" {
+			// The cluster recovered, so anything that was talking to it
+			// while it was down needs to resync.
+			m.reconnected = true
+			m.downtime = c.clock.Since(m.unhealthySince)
+		}
 		m.error = error
 		c.monitors[clusterNN] = m
 		c.requeuer.Add(clusterNN)
 	}
 }
 
+// TakeReconnect reports whether the cluster recovered from a health check
+// failure since the last call, and how long it was unhealthy.
+func (c *clusterHealthMonitor) TakeReconnect(clusterNN types.NamespacedName) (bool, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.monitors[clusterNN]
+	if !ok || !m.reconnected {
+		return false, 0
+	}
+	m.reconnected = false
+	c.monitors[clusterNN] = m
+	return true, m.downtime
+}
+
 func (c *clusterHealthMonitor) Stop(clusterNN types.NamespacedName) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -86,6 +110,10 @@ func (c *clusterHealthMonitor) cleanup(clusterNN types.NamespacedName) {
 type monitor struct {
 	cancel context.CancelFunc
 	error  string
+
+	unhealthySince time.Time
+	reconnected    bool
+	downtime       time.Duration
 }
 
 func (c *clusterHealthMonitor) run(ctx context.Context, clusterNN types.NamespacedName, conn connection) {
@@ -0,0 +1,105 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/indexer"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Provisions clusters in the background.
+//
+// Creating a cluster can take minutes (kind and k3d wait up to 5m for it to
+// be ready), so it runs off the reconcile loop. When it's done, the Cluster
+// is requeued, and the next reconcile picks up the result.
+type provisionTracker struct {
+	mu        sync.Mutex
+	globalCtx context.Context
+	requeuer  *indexer.Requeuer
+	jobs      map[types.NamespacedName]*provisionJob
+}
+
+type provisionJob struct {
+	spec   v1alpha1.ClusterSpec
+	cancel context.CancelFunc
+	done   bool
+	result provisionResult
+}
+
+// What we got from provisioning a cluster and connecting to it.
+type provisionResult struct {
+	k8sClient k8s.Client
+	registry  *v1alpha1.RegistryHosting
+	initError string
+}
+
+func newProvisionTracker(globalCtx context.Context, requeuer *indexer.Requeuer) *provisionTracker {
+	return &provisionTracker{
+		globalCtx: globalCtx,
+		requeuer:  requeuer,
+		jobs:      make(map[types.NamespacedName]*provisionJob),
+	}
+}
+
+// Takes the result of provisioning the cluster, if it's ready.
+//
+// If nothing is provisioning the cluster with this spec yet, runs provision
+// in the background and returns false. Each result is only taken once, so
+// calling this again after a failure starts over.
+func (p *provisionTracker) Take(clusterNN types.NamespacedName, spec v1alpha1.ClusterSpec, provision func(ctx context.Context) provisionResult) (provisionResult, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	job, ok := p.jobs[clusterNN]
+	if ok && !apicmp.DeepEqual(job.spec, spec) {
+		job.cancel()
+		ok = false
+	}
+	if !ok {
+		ctx, cancel := context.WithCancel(p.globalCtx)
+		job = &provisionJob{spec: spec, cancel: cancel}
+		p.jobs[clusterNN] = job
+		go p.run(ctx, clusterNN, job, provision)
+		return provisionResult{}, false
+	}
+
+	if !job.done {
+		return provisionResult{}, false
+	}
+
+	job.cancel()
+	delete(p.jobs, clusterNN)
+	return job.result, true
+}
+
+func (p *provisionTracker) run(ctx context.Context, clusterNN types.NamespacedName, job *provisionJob, provision func(ctx context.Context) provisionResult) {
+	result := provision(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.jobs[clusterNN] != job || ctx.Err() != nil {
+		// The cluster was deleted or changed while we were provisioning it,
+		// or Tilt is shutting down.
+		return
+	}
+	job.done = true
+	job.result = result
+	p.requeuer.Add(clusterNN)
+}
+
+func (p *provisionTracker) Stop(clusterNN types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	job, ok := p.jobs[clusterNN]
+	if ok {
+		job.cancel()
+		delete(p.jobs, clusterNN)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/clusterprovision"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
@@ -43,6 +44,7 @@ const ArchUnknown string = "unknown"
 const (
 	clientInitBackoff        = 30 * time.Second
 	clientHealthPollInterval = 15 * time.Second
+	registryPublishTimeout   = 30 * time.Second
 )
 
 type Reconciler struct {
@@ -59,9 +61,11 @@ type Reconciler struct {
 	dockerClientFactory DockerClientFactory
 
 	k8sClientFactory KubernetesClientFactory
+	provisioner      clusterprovision.Provisioner
 	wsList           *server.WebsocketList
 
 	clusterHealth *clusterHealthMonitor
+	provisions    *provisionTracker
 }
 
 func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
@@ -80,6 +84,7 @@ func NewReconciler(
 	localDockerEnv docker.LocalEnv,
 	dockerClientFactory DockerClientFactory,
 	k8sClientFactory KubernetesClientFactory,
+	provisioner clusterprovision.Provisioner,
 	wsList *server.WebsocketList,
 	base xdg.Base,
 	apiServerName model.APIServerName,
@@ -96,8 +101,10 @@ func NewReconciler(
 		localDockerEnv:      localDockerEnv,
 		dockerClientFactory: dockerClientFactory,
 		k8sClientFactory:    k8sClientFactory,
+		provisioner:         provisioner,
 		wsList:              wsList,
 		clusterHealth:       newClusterHealthMonitor(globalCtx, clock, requeuer),
+		provisions:          newProvisionTracker(globalCtx, requeuer),
 		base:                base,
 		apiServerName:       apiServerName,
 	}
@@ -148,7 +155,20 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	if !hasConnection {
 		// Create the initial connection to the cluster.
 		conn = connection{spec: *obj.Spec.DeepCopy(), createdAt: r.clock.Now()}
-		if obj.Spec.Connection != nil && obj.Spec.Connection.Kubernetes != nil {
+		if obj.Spec.Provision != nil {
+			conn.connType = connectionTypeK8s
+			cluster := obj.DeepCopy()
+			result, ok := r.provisions.Take(nn, cluster.Spec, func(ctx context.Context) provisionResult {
+				return r.provisionKubernetesCluster(ctx, cluster)
+			})
+			if !ok {
+				// Still provisioning. We'll be requeued when it's done.
+				return ctrl.Result{}, nil
+			}
+			conn.k8sClient = result.k8sClient
+			conn.registry = result.registry
+			conn.initError = result.initError
+		} else if obj.Spec.Connection != nil && obj.Spec.Connection.Kubernetes != nil {
 			conn.connType = connectionTypeK8s
 			client, err := r.createKubernetesClient(obj.DeepCopy())
 			if err != nil {
@@ -186,6 +206,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		}
 	}
 
+	if hasConnection {
+		r.maybeReconnect(ctx, nn, &conn)
+	}
+
 	r.populateClusterMetadata(ctx, nn, &conn)
 
 	r.connManager.store(nn, conn)
@@ -203,6 +227,23 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
+// If the cluster came back after failing health checks, bump the connection
+// revision. Watches, port forwards, and log streams that were opened before
+// the outage may be silently broken, so everything keyed on the revision
+// (Cluster.Status.ConnectedAt) tears down and re-establishes its state.
+func (r *Reconciler) maybeReconnect(ctx context.Context, nn types.NamespacedName, conn *connection) {
+	reconnected, downtime := r.clusterHealth.TakeReconnect(nn)
+	if !reconnected || conn.initError != "" {
+		return
+	}
+
+	conn.createdAt = r.clock.Now()
+	conn.reconnectedAt = conn.createdAt
+	logger.Get(ctx).Infof("Reconnected to cluster %q after %s. Resyncing watches and deployed objects.",
+		nn.Name, downtime.Round(time.Second))
+	analytics.Get(ctx).Incr("api.cluster.reconnect", map[string]string{"type": string(conn.connType)})
+}
+
 // Creates a docker connection from the spec.
 func (r *Reconciler) createDockerClient(obj *v1alpha1.DockerClusterConnection) (docker.Client, error) {
 	// If no Host is specified, use the default Env from environment variables.
@@ -233,6 +274,49 @@ func (r *Reconciler) createKubernetesClient(cluster *v1alpha1.Cluster) (k8s.Clie
 	return client, nil
 }
 
+// Creates the cluster if it doesn't exist, then connects to it.
+//
+// The provisioned cluster's context takes precedence over any
+// context in the connection spec.
+//
+// Runs in the background (see provisionTracker), so it must not touch
+// the connection cache.
+func (r *Reconciler) provisionKubernetesCluster(ctx context.Context, cluster *v1alpha1.Cluster) provisionResult {
+	ctx = store.WithManifestLogHandler(ctx, r.store, model.MainTiltfileManifestName, "cluster")
+	provision := *cluster.Spec.Provision
+	result, err := r.provisioner.Ensure(ctx, provision)
+	if err != nil {
+		return provisionResult{initError: fmt.Sprintf("Provisioning %s cluster %s: %v",
+			provision.Product, clusterprovision.ClusterName(provision), err)}
+	}
+
+	var namespace string
+	if cluster.Spec.Connection != nil && cluster.Spec.Connection.Kubernetes != nil {
+		namespace = cluster.Spec.Connection.Kubernetes.Namespace
+	}
+	client, err := r.k8sClientFactory.New(r.globalCtx,
+		k8s.KubeContextOverride(result.Context), k8s.NamespaceOverride(namespace))
+	if err != nil {
+		return provisionResult{initError: fmt.Sprintf("Connecting to provisioned cluster %s: %v", result.Context, err)}
+	}
+
+	provisioned := provisionResult{k8sClient: client}
+	if result.Registry != nil {
+		// Advertise the registry in the cluster, so that other tools can
+		// find it, and use it directly rather than waiting for discovery.
+		entity, err := clusterprovision.RegistryConfigMap(*result.Registry)
+		if err == nil {
+			_, err = client.Upsert(ctx, []k8s.K8sEntity{entity}, registryPublishTimeout)
+		}
+		if err != nil {
+			logger.Get(ctx).Warnf("Publishing local registry %s: %v", result.Registry.Host, err)
+		}
+		provisioned.registry = clusterprovision.RegistryHosting(*result.Registry)
+		logger.Get(ctx).Infof("Using local registry from provisioned cluster: %s", provisioned.registry.Host)
+	}
+	return provisioned
+}
+
 // Reads the arch from a kubernetes cluster, or "unknown" if we can't
 // figure out the architecture.
 //
@@ -332,6 +416,11 @@ func (r *Reconciler) populateK8sMetadata(ctx context.Context, clusterNN types.Na
 		conn.arch = r.readKubernetesArch(ctx, conn.k8sClient)
 	}
 
+	var profile k8s.ClusterProfile
+	if conn.registry == nil || conn.connStatus == nil {
+		profile = r.k8sClusterProfile(ctx, conn)
+	}
+
 	if conn.registry == nil {
 		reg := conn.k8sClient.LocalRegistry(ctx)
 		if !container.IsEmptyRegistry(reg) {
@@ -344,7 +433,16 @@ func (r *Reconciler) populateK8sMetadata(ctx context.Context, clusterNN types.Na
 				logger.Get(ctx).Infof("Default registry specified, but will be ignored in favor of auto-detected registry.")
 			}
 		} else if conn.spec.DefaultRegistry != nil {
+			// The Tiltfile is more specific than the user's clusters.yaml,
+			// so its default_registry wins.
+			if profile.Registry != nil {
+				logger.Get(ctx).Infof("Local registry declared for cluster %q in %s (%s) will be ignored in favor of the default registry from the Tiltfile.",
+					clusterNN.Name, k8s.ClusterConfigFileName, profile.Registry.Host)
+			}
 			logger.Get(ctx).Debugf("Using default registry from Tiltfile: %s", conn.spec.DefaultRegistry)
+		} else if profile.Registry != nil {
+			reg = profile.Registry
+			logger.Get(ctx).Infof("Using local registry declared for cluster %q: %s", clusterNN.Name, reg.Host)
 		} else {
 			logger.Get(ctx).Debugf(
 				"No local registry detected and no default registry set for cluster %q",
@@ -357,8 +455,10 @@ func (r *Reconciler) populateK8sMetadata(ctx context.Context, clusterNN types.Na
 	if conn.connStatus == nil {
 		apiConfig := conn.k8sClient.APIConfig()
 		k8sStatus := &v1alpha1.KubernetesClusterConnectionStatus{
-			Context: apiConfig.CurrentContext,
-			Product: string(k8s.ClusterProductFromAPIConfig(apiConfig)),
+			Context:    apiConfig.CurrentContext,
+			Product:    string(profile.Product),
+			DevCluster: profile.DevCluster,
+			ImageLoad:  profile.ImageLoad,
 		}
 		context, ok := apiConfig.Contexts[apiConfig.CurrentContext]
 		if ok {
@@ -380,6 +480,19 @@ func (r *Reconciler) populateK8sMetadata(ctx context.Context, clusterNN types.Na
 	}
 }
 
+func (r *Reconciler) k8sClusterProfile(ctx context.Context, conn *connection) k8s.ClusterProfile {
+	clusterConfig := k8s.ProvideClusterConfig(r.base)
+	if clusterConfig.Error != nil {
+		logger.Get(ctx).Warnf("Loading cluster config: %v", clusterConfig.Error)
+	}
+
+	var spec *v1alpha1.KubernetesClusterConnection
+	if conn.spec.Connection != nil {
+		spec = conn.spec.Connection.Kubernetes
+	}
+	return k8s.ClusterProfileFromAPIConfig(conn.k8sClient.APIConfig(), clusterConfig, spec)
+}
+
 func (r *Reconciler) writeFrozenKubeConfig(ctx context.Context, nn types.NamespacedName, config *api.Config) string {
 	config = config.DeepCopy()
 	err := api.MinifyConfig(config)
@@ -437,6 +550,7 @@ func (r *Reconciler) populateDockerMetadata(ctx context.Context, conn *connectio
 }
 
 func (r *Reconciler) cleanup(clusterNN types.NamespacedName) {
+	r.provisions.Stop(clusterNN)
 	r.clusterHealth.Stop(clusterNN)
 	r.connManager.delete(clusterNN)
 }
@@ -448,17 +562,24 @@ func (c *connection) toStatus(statusErr string) v1alpha1.ClusterStatus {
 		connectedAt = &t
 	}
 
+	var reconnectedAt *metav1.MicroTime
+	if !c.reconnectedAt.IsZero() {
+		t := apis.NewMicroTime(c.reconnectedAt)
+		reconnectedAt = &t
+	}
+
 	clusterError := c.initError
 	if clusterError == "" {
 		clusterError = statusErr
 	}
 
 	return v1alpha1.ClusterStatus{
-		Error:       clusterError,
-		Arch:        c.arch,
-		Version:     c.serverVersion,
-		ConnectedAt: connectedAt,
-		Registry:    c.registry,
-		Connection:  c.connStatus,
+		Error:         clusterError,
+		Arch:          c.arch,
+		Version:       c.serverVersion,
+		ConnectedAt:   connectedAt,
+		ReconnectedAt: reconnectedAt,
+		Registry:      c.registry,
+		Connection:    c.connStatus,
 	}
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 
+	"github.com/tilt-dev/tilt/internal/clusterprovision"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
@@ -193,6 +194,74 @@ users: null
 `, string(contents))
 }
 
+func TestKubernetesConnStatusDeclared(t *testing.T) {
+	f := newFixture(t)
+	configPath, err := f.r.base.ConfigFile(k8s.ClusterConfigFileName)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+clusters:
+- context: def*
+  product: talos
+  devCluster: true
+  imageLoad: docker
+  registry:
+    host: localhost:5005
+`), 0600))
+
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{
+					ImageLoad: v1alpha1.ClusterImageLoadRegistry,
+				},
+			},
+		},
+	}
+
+	nn := types.NamespacedName{Name: "default"}
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+
+	k8sStatus := cluster.Status.Connection.Kubernetes
+	assert.Equal(t, "talos", k8sStatus.Product)
+	assert.True(t, k8sStatus.DevCluster)
+	assert.Equal(t, v1alpha1.ClusterImageLoadRegistry, k8sStatus.ImageLoad,
+		"Cluster spec should take precedence over clusters.yaml")
+	if assert.NotNil(t, cluster.Status.Registry) {
+		assert.Equal(t, "localhost:5005", cluster.Status.Registry.Host)
+	}
+}
+
+func TestKubernetesDeclaredRegistryYieldsToTiltfile(t *testing.T) {
+	f := newFixture(t)
+	configPath, err := f.r.base.ConfigFile(k8s.ClusterConfigFileName)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+clusters:
+- context: def*
+  registry:
+    host: localhost:5005
+`), 0600))
+
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{},
+			},
+			DefaultRegistry: &v1alpha1.RegistryHosting{Host: "gcr.io/my-project"},
+		},
+	}
+
+	nn := types.NamespacedName{Name: "default"}
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+
+	assert.Nil(t, cluster.Status.Registry)
+	f.AssertStdOutContains(`Local registry declared for cluster "default" in clusters.yaml (localhost:5005) will be ignored`)
+}
+
 func TestKubernetesMonitor(t *testing.T) {
 	f := newFixture(t)
 	cluster := &v1alpha1.Cluster{
@@ -208,6 +277,7 @@ func TestKubernetesMonitor(t *testing.T) {
 	f.Create(cluster)
 	f.MustGet(nn, cluster)
 	connectedAt := *cluster.Status.ConnectedAt
+	assert.Nil(t, cluster.Status.ReconnectedAt)
 	f.assertSteadyState(cluster)
 
 	f.k8sClient.ClusterHealthError = errors.New("fake cluster health error")
@@ -219,6 +289,53 @@ func TestKubernetesMonitor(t *testing.T) {
 	timecmp.RequireTimeEqual(t, connectedAt, cluster.Status.ConnectedAt)
 }
 
+func TestKubernetesMonitorReconnect(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{},
+			},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+	connectedAt := *cluster.Status.ConnectedAt
+	assert.Nil(t, cluster.Status.ReconnectedAt)
+	f.assertSteadyState(cluster)
+
+	f.k8sClient.ClusterHealthError = errors.New("fake cluster health error")
+	f.clock.Advance(time.Minute)
+	<-f.requeues
+
+	f.MustGet(nn, cluster)
+	assert.Equal(t, "fake cluster health error", cluster.Status.Error)
+
+	f.k8sClient.ClusterHealthError = nil
+	f.clock.Advance(time.Minute)
+	<-f.requeues
+
+	// The connection revision changes, so that anything watching the
+	// cluster re-establishes its state.
+	f.MustGet(nn, cluster)
+	assert.Equal(t, "", cluster.Status.Error)
+	require.NotNil(t, cluster.Status.ConnectedAt)
+	assert.True(t, cluster.Status.ConnectedAt.Time.After(connectedAt.Time),
+		"ConnectedAt should be updated on reconnect")
+	if assert.NotNil(t, cluster.Status.ReconnectedAt) {
+		assert.True(t, cluster.Status.ReconnectedAt.Time.Equal(cluster.Status.ConnectedAt.Time))
+	}
+	f.AssertStdOutContains(`Reconnected to cluster "default" after 1m0s`)
+	assert.Equal(t, 1, f.countAnalytics("api.cluster.reconnect"))
+
+	_, _, err := f.r.connManager.GetK8sClient(nn)
+	require.NoError(t, err)
+	f.assertSteadyState(cluster)
+}
+
 func TestDockerError(t *testing.T) {
 	f := newFixture(t)
 	cluster := &v1alpha1.Cluster{
@@ -260,6 +377,87 @@ func TestDockerArch(t *testing.T) {
 	}
 }
 
+func TestKubernetesProvision(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Provision: &v1alpha1.ClusterProvision{
+				Product:  v1alpha1.ClusterProvisionProductKind,
+				Registry: &v1alpha1.ClusterProvisionRegistry{},
+			},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	f.Create(cluster)
+	<-f.requeues
+	f.MustGet(nn, cluster)
+	require.Empty(t, cluster.Status.Error)
+	assert.Contains(t, f.provisioner.Clusters, "kind-tilt")
+	if assert.NotNil(t, cluster.Status.Registry) {
+		assert.Equal(t, "localhost:5005", cluster.Status.Registry.Host)
+		assert.Equal(t, "tilt-registry:5000", cluster.Status.Registry.HostFromClusterNetwork)
+	}
+	assert.Contains(t, f.k8sClient.Yaml, "name: local-registry-hosting")
+	assert.Contains(t, f.k8sClient.Yaml, "host: localhost:5005")
+
+	// Once the cluster exists, we don't try to create it again.
+	f.assertSteadyState(cluster)
+	assert.Equal(t, 1, f.provisioner.EnsureCount)
+}
+
+func TestKubernetesProvisionError(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Provision: &v1alpha1.ClusterProvision{Product: v1alpha1.ClusterProvisionProductK3d},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	f.provisioner.EnsureError = errors.New("k3d not found")
+	f.Create(cluster)
+	<-f.requeues
+	f.MustGet(nn, cluster)
+	assert.Equal(t, "Provisioning k3d cluster tilt: k3d not found", cluster.Status.Error)
+	assert.Nil(t, cluster.Status.ConnectedAt)
+}
+
+func TestKubernetesProvisionInBackground(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Provision: &v1alpha1.ClusterProvision{Product: v1alpha1.ClusterProvisionProductKind},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	ready := make(chan struct{})
+	f.provisioner.Ready = ready
+
+	// Reconcile doesn't wait for the cluster to be created.
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+	assert.Empty(t, cluster.Status.Error)
+	assert.Nil(t, cluster.Status.ConnectedAt)
+
+	_, _, err := f.r.connManager.GetK8sClient(nn)
+	require.Error(t, err)
+
+	// Reconciling again doesn't start a second cluster.
+	f.MustReconcile(nn)
+
+	close(ready)
+	<-f.requeues
+	f.MustGet(nn, cluster)
+	assert.Empty(t, cluster.Status.Error)
+	assert.NotNil(t, cluster.Status.ConnectedAt)
+	assert.Equal(t, 1, f.provisioner.EnsureCount)
+}
+
 type fixture struct {
 	*fake.ControllerFixture
 	r            *Reconciler
@@ -267,6 +465,7 @@ type fixture struct {
 	clock        clockwork.FakeClock
 	k8sClient    *k8s.FakeK8sClient
 	dockerClient *docker.FakeClient
+	provisioner  *clusterprovision.FakeProvisioner
 	requeues     <-chan indexer.RequeueForTestResult
 }
 
@@ -277,6 +476,7 @@ func newFixture(t *testing.T) *fixture {
 
 	k8sClient := k8s.NewFakeK8sClient(t)
 	dockerClient := docker.NewFakeClient()
+	provisioner := clusterprovision.NewFakeProvisioner()
 	base := xdg.FakeBase{Dir: tmpf.Path()}
 
 	r := NewReconciler(cfb.Context(),
@@ -287,6 +487,7 @@ func newFixture(t *testing.T) *fixture {
 		docker.LocalEnv{},
 		FakeDockerClientOrError(dockerClient, nil),
 		FakeKubernetesClientOrError(k8sClient, nil),
+		provisioner,
 		server.NewWebsocketList(),
 		base,
 		"tilt-default")
@@ -299,10 +500,21 @@ func newFixture(t *testing.T) *fixture {
 		clock:             clock,
 		k8sClient:         k8sClient,
 		dockerClient:      dockerClient,
+		provisioner:       provisioner,
 		requeues:          requeueChan,
 	}
 }
 
+func (f *fixture) countAnalytics(name string) int {
+	count := 0
+	for _, c := range f.ma.Counts {
+		if c.Name == name {
+			count++
+		}
+	}
+	return count
+}
+
 func (f *fixture) assertSteadyState(o *v1alpha1.Cluster) {
 	f.T().Helper()
 	f.MustReconcile(types.NamespacedName{Name: o.Name})
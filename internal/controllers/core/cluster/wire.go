@@ -3,12 +3,15 @@ package cluster
 import (
 	"github.com/google/wire"
 
+	"github.com/tilt-dev/tilt/internal/clusterprovision"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 )
 
 var WireSet = wire.NewSet(
 	NewConnectionManager,
 	wire.Bind(new(cluster.ClientProvider), new(*ConnectionManager)),
-	wire.InterfaceValue(new(KubernetesClientFactory), KubernetesClientFunc(KubernetesClientFromEnv)),
+	ProvideKubernetesClientFactory,
 	wire.InterfaceValue(new(DockerClientFactory), DockerClientFunc(DockerClientFromEnv)),
+	clusterprovision.NewProvisioner,
+	wire.Bind(new(clusterprovision.Provisioner), new(*clusterprovision.CLIProvisioner)),
 )
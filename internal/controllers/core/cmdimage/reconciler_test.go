@@ -48,6 +48,7 @@ func newFixture(t testing.TB) *fixture {
 	dockerCli := docker.NewFakeClient()
 	ib := build.NewImageBuilder(
 		build.NewDockerBuilder(dockerCli, nil),
+		build.NewBuildkitBuilder(""),
 		build.NewCustomBuilder(dockerCli, clock),
 		build.NewKINDLoader())
 
@@ -92,6 +92,11 @@ func (r *Reconciler) runProjectWatch(pw *ProjectWatch) {
 				continue
 			}
 
+			// The status only tracks the first replica of a scaled service.
+			if containerJSON.Config != nil && !dockercompose.IsFirstReplica(containerJSON.Config.Labels) {
+				continue
+			}
+
 			cState := containerJSON.ContainerJSONBase.State
 			dcState := dockercompose.ToContainerState(cState)
 			r.recordContainerEvent(evt, dcState)
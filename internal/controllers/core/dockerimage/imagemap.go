@@ -42,6 +42,8 @@ func UpdateImageMap(
 	}
 
 	result.ImageMapStatus.BuildStartTime = startTime
+	result.ImageMapStatus.Digest = taggedRefs.Digest.String()
+	result.ImageMapStatus.PlatformDigests = taggedRefs.PlatformDigests
 	nn := types.NamespacedName{Name: iTarget.ImageMapName()}
 	im, ok := imageMaps[nn]
 	if !ok {
@@ -17,13 +17,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/buildstats"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/ignore"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/dockerimages"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -36,23 +39,40 @@ type Reconciler struct {
 	indexer  *indexer.Indexer
 	docker   docker.Client
 	ib       *build.ImageBuilder
+	history  *buildstats.History
 	requeuer *indexer.Requeuer
 
 	mu      sync.Mutex
 	results map[types.NamespacedName]*result
+
+	// The size of each image's build context, as of the last time we
+	// walked it. Protected by mu.
+	contextSizes map[types.NamespacedName]*contextSize
+}
+
+type contextSize struct {
+	files   int
+	bytes   int64
+	walking bool
+
+	// Closed when the first walk finishes.
+	measured chan struct{}
 }
 
 var _ reconcile.Reconciler = &Reconciler{}
 
-func NewReconciler(client ctrlclient.Client, st store.RStore, scheme *runtime.Scheme, docker docker.Client, ib *build.ImageBuilder) *Reconciler {
+func NewReconciler(client ctrlclient.Client, st store.RStore, scheme *runtime.Scheme, docker docker.Client, ib *build.ImageBuilder, history *buildstats.History) *Reconciler {
 	return &Reconciler{
 		client:   client,
 		st:       st,
 		indexer:  indexer.NewIndexer(scheme, indexDockerImage),
 		docker:   docker,
 		ib:       ib,
+		history:  history,
 		results:  make(map[types.NamespacedName]*result),
 		requeuer: indexer.NewRequeuer(),
+
+		contextSizes: make(map[types.NamespacedName]*contextSize),
 	}
 }
 
@@ -70,6 +90,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	if apierrors.IsNotFound(err) || obj.ObjectMeta.DeletionTimestamp != nil {
 		delete(r.results, nn)
+		delete(r.contextSizes, nn)
 		r.st.Dispatch(dockerimages.NewDockerImageDeleteAction(nn.Name))
 		return ctrl.Result{}, nil
 	}
@@ -97,7 +118,8 @@ func (r *Reconciler) ForceApply(
 	iTarget model.ImageTarget,
 	cluster *v1alpha1.Cluster,
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
-	ps *build.PipelineState) (store.ImageBuildResult, error) {
+	ps *build.PipelineState,
+	trigger buildstats.Trigger) (store.ImageBuildResult, error) {
 
 	// TODO(nick): It might make sense to reset the ImageMapStatus here
 	// to an empty image while the image is building. maybe?
@@ -107,6 +129,7 @@ func (r *Reconciler) ForceApply(
 	startTime := apis.NowMicro()
 	nn := types.NamespacedName{Name: iTarget.DockerImageName}
 	r.setImageStatus(nn, ToBuildingStatus(iTarget, startTime))
+	r.measureContext(nn, iTarget)
 
 	// Requeue the reconciler twice: once when the build has started and once
 	// after it has finished.
@@ -115,11 +138,15 @@ func (r *Reconciler) ForceApply(
 
 	refs, stages, err := r.ib.Build(ctx, iTarget, cluster, imageMaps, ps)
 	if err != nil {
-		r.setImageStatus(nn, ToCompletedFailStatus(iTarget, startTime, stages, err))
+		status := ToCompletedFailStatus(iTarget, startTime, stages, err, trigger.Reason)
+		r.setImageStatus(nn, status)
+		r.recordBuild(ctx, nn, iTarget, status, trigger)
 		return store.ImageBuildResult{}, err
 	}
 
-	r.setImageStatus(nn, ToCompletedSuccessStatus(iTarget, startTime, stages, refs))
+	status := ToCompletedSuccessStatus(iTarget, startTime, stages, refs, trigger.Reason)
+	r.setImageStatus(nn, status)
+	r.recordBuild(ctx, nn, iTarget, status, trigger)
 
 	buildResult, err := UpdateImageMap(
 		ctx, r.docker,
@@ -131,6 +158,98 @@ func (r *Reconciler) ForceApply(
 	return buildResult, nil
 }
 
+// Save the build to the local build history, so that
+// `tilt build-stats` can show trends across sessions.
+func (r *Reconciler) recordBuild(ctx context.Context, nn types.NamespacedName, iTarget model.ImageTarget, status v1alpha1.DockerImageStatus, trigger buildstats.Trigger) {
+	completed := status.Completed
+	if r.history == nil || completed == nil {
+		return
+	}
+
+	spec := iTarget.DockerBuildInfo().DockerImageSpec
+	record := buildstats.Record{
+		Image:            iTarget.ImageMapSpec.Selector,
+		StartTime:        completed.StartedAt.Time,
+		Duration:         completed.FinishedAt.Sub(completed.StartedAt.Time),
+		Error:            completed.Error,
+		Reason:           trigger.Reason,
+		ChangedFiles:     trigger.ChangedFiles,
+		DockerfileDigest: buildstats.DockerfileDigest(spec.DockerfileContents),
+		Stages:           buildstats.NewStageRecords(status.StageStatuses),
+	}
+
+	record.ContextFiles, record.ContextBytes = r.lastContextSize(ctx, nn)
+
+	err := r.history.Append(record)
+	if err != nil {
+		logger.Get(ctx).Debugf("Recording build history: %v", err)
+	}
+}
+
+// Walks the build context in the background.
+//
+// Walking a big build context can take a while, so we walk it while the
+// image builds. The build history is about trends across many builds, so
+// it's fine if a record uses a size from an earlier walk. But the first
+// build of an image waits for the first walk, so that it has a size.
+func (r *Reconciler) measureContext(nn types.NamespacedName, iTarget model.ImageTarget) {
+	if r.history == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	size, ok := r.contextSizes[nn]
+	if !ok {
+		size = &contextSize{measured: make(chan struct{})}
+		r.contextSizes[nn] = size
+	}
+	if size.walking {
+		return
+	}
+	size.walking = true
+
+	spec := iTarget.DockerBuildInfo().DockerImageSpec
+	go func() {
+		files, bytes, err := buildstats.ContextSize(spec.Context, ignore.CreateBuildContextFilter(spec.ContextIgnores))
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		select {
+		case <-size.measured:
+		default:
+			close(size.measured)
+		}
+		size.walking = false
+		if err == nil {
+			size.files = files
+			size.bytes = bytes
+		}
+	}()
+}
+
+// The size from the last walk of the build context.
+//
+// If the first walk hasn't finished yet, waits for it.
+func (r *Reconciler) lastContextSize(ctx context.Context, nn types.NamespacedName) (int, int64) {
+	r.mu.Lock()
+	size, ok := r.contextSizes[nn]
+	r.mu.Unlock()
+	if !ok {
+		return 0, 0
+	}
+
+	select {
+	case <-size.measured:
+	case <-ctx.Done():
+		return 0, 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return size.files, size.bytes
+}
+
 func (r *Reconciler) ensureResult(nn types.NamespacedName) *result {
 	res, ok := r.results[nn]
 	if !ok {
@@ -1,7 +1,10 @@
 package dockerimage
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
@@ -10,9 +13,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/buildstats"
+	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 func TestIndexCluster(t *testing.T) {
@@ -36,6 +43,30 @@ func TestIndexCluster(t *testing.T) {
 	require.Empty(t, reqs, "Index result for unknown cluster")
 }
 
+func TestMeasureContextInBackground(t *testing.T) {
+	f := newFixture(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644))
+
+	iTarget := model.MustNewImageTarget(container.MustParseSelector("my-image")).
+		WithDockerImage(v1alpha1.DockerImageSpec{Context: dir})
+	nn := types.NamespacedName{Name: "my-image"}
+	f.r.measureContext(nn, iTarget)
+
+	// The first build waits for the first walk.
+	files, bytes := f.r.lastContextSize(f.Context(), nn)
+	require.Equal(t, 1, files)
+	require.Equal(t, int64(len("package main")), bytes)
+
+	// Later builds use the last size while the context is walked again.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "util.go"), []byte("package main"), 0644))
+	f.r.measureContext(nn, iTarget)
+	require.Eventually(t, func() bool {
+		files, _ := f.r.lastContextSize(f.Context(), nn)
+		return files == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
 type fixture struct {
 	*fake.ControllerFixture
 	r *Reconciler
@@ -48,10 +79,11 @@ func newFixture(t testing.TB) *fixture {
 	dockerCli := docker.NewFakeClient()
 	ib := build.NewImageBuilder(
 		build.NewDockerBuilder(dockerCli, nil),
+		build.NewBuildkitBuilder(""),
 		build.NewCustomBuilder(dockerCli, clock),
 		build.NewKINDLoader())
 
-	r := NewReconciler(cfb.Client, cfb.Store, cfb.Scheme(), dockerCli, ib)
+	r := NewReconciler(cfb.Client, cfb.Store, cfb.Scheme(), dockerCli, ib, buildstats.NewHistory(xdg.FakeBase{Dir: t.TempDir()}))
 	return &fixture{
 		ControllerFixture: cfb.Build(r),
 		r:                 r,
@@ -3,6 +3,7 @@ package dockerimage
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -20,7 +21,7 @@ func ToBuildingStatus(iTarget model.ImageTarget, startTime metav1.MicroTime) v1a
 
 // Return a completed status when the image build failed.
 func ToCompletedFailStatus(iTarget model.ImageTarget, startTime metav1.MicroTime,
-	stages []v1alpha1.DockerImageStageStatus, err error) v1alpha1.DockerImageStatus {
+	stages []v1alpha1.DockerImageStageStatus, err error, reason string) v1alpha1.DockerImageStatus {
 	finishTime := apis.NowMicro()
 
 	// Complete all stages.
@@ -34,17 +35,19 @@ func ToCompletedFailStatus(iTarget model.ImageTarget, startTime metav1.MicroTime
 
 	return v1alpha1.DockerImageStatus{
 		Completed: &v1alpha1.DockerImageStateCompleted{
+			Reason:     reason,
 			StartedAt:  startTime,
 			FinishedAt: finishTime,
 			Error:      err.Error(),
 		},
 		StageStatuses: stages,
+		CacheHits:     build.CacheHits(stages),
 	}
 }
 
 // Return a completed status when the image build succeeded.
 func ToCompletedSuccessStatus(iTarget model.ImageTarget, startTime metav1.MicroTime,
-	stages []v1alpha1.DockerImageStageStatus, refs container.TaggedRefs) v1alpha1.DockerImageStatus {
+	stages []v1alpha1.DockerImageStageStatus, refs container.TaggedRefs, reason string) v1alpha1.DockerImageStatus {
 	finishTime := apis.NowMicro()
 
 	// Complete all stages.
@@ -58,9 +61,11 @@ func ToCompletedSuccessStatus(iTarget model.ImageTarget, startTime metav1.MicroT
 	return v1alpha1.DockerImageStatus{
 		Ref: container.FamiliarString(refs.LocalRef),
 		Completed: &v1alpha1.DockerImageStateCompleted{
+			Reason:     reason,
 			StartedAt:  startTime,
 			FinishedAt: finishTime,
 		},
 		StageStatuses: stages,
+		CacheHits:     build.CacheHits(stages),
 	}
 }
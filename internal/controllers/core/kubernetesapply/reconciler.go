@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
@@ -25,10 +30,12 @@ import (
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/imagemap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/trigger"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -41,9 +48,10 @@ import (
 )
 
 type deleteSpec struct {
-	entities  []k8s.K8sEntity
-	deleteCmd *v1alpha1.KubernetesApplyCmd
-	cluster   *v1alpha1.Cluster
+	entities    []k8s.K8sEntity
+	deleteCmd   *v1alpha1.KubernetesApplyCmd
+	helmRelease *v1alpha1.KubernetesApplyHelmRelease
+	cluster     *v1alpha1.Cluster
 
 	// waits for the entities to fully delete
 	wait bool
@@ -53,9 +61,11 @@ type Reconciler struct {
 	st         store.RStore
 	dkc        build.DockerKubeConnection
 	k8sClient  k8s.Client
+	clients    cluster.ClientProvider
 	ctrlClient ctrlclient.Client
 	indexer    *indexer.Indexer
 	execer     localexec.Execer
+	helm       helm.ReleaseClient
 	requeuer   *indexer.Requeuer
 
 	mu sync.Mutex
@@ -83,12 +93,14 @@ func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
 	return b, nil
 }
 
-func NewReconciler(ctrlClient ctrlclient.Client, k8sClient k8s.Client, scheme *runtime.Scheme, dkc build.DockerKubeConnection, st store.RStore, execer localexec.Execer) *Reconciler {
+func NewReconciler(ctrlClient ctrlclient.Client, k8sClient k8s.Client, clients cluster.ClientProvider, scheme *runtime.Scheme, dkc build.DockerKubeConnection, st store.RStore, execer localexec.Execer, helmClient helm.ReleaseClient) *Reconciler {
 	return &Reconciler{
 		ctrlClient: ctrlClient,
 		k8sClient:  k8sClient,
+		clients:    clients,
 		indexer:    indexer.NewIndexer(scheme, indexKubernetesApply),
 		execer:     execer,
+		helm:       helmClient,
 		dkc:        dkc,
 		st:         st,
 		results:    make(map[types.NamespacedName]*Result),
@@ -137,6 +149,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	// Delete kubernetesapply if it's disabled
 	isDisabling := false
 	gcReason := "garbage collecting Kubernetes objects"
+	retryResync := false
 	if disableStatus.State == v1alpha1.DisableStateDisabled {
 		gcReason = "deleting disabled Kubernetes objects"
 		isDisabling = true
@@ -168,6 +181,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		if r.shouldDeployOnReconcile(request.NamespacedName, &ka, &cluster, imageMaps, lastRestartEvent) {
 			_ = r.forceApplyHelper(ctx, nn, ka.Spec, &cluster, imageMaps)
 			gcReason = "garbage collecting removed Kubernetes objects"
+		} else {
+			var resync bool
+			resync, retryResync = r.shouldResyncAfterReconnect(ctx, nn, &ka, &cluster, imageMaps)
+			if resync {
+				_ = r.forceApplyHelper(ctx, nn, ka.Spec, &cluster, imageMaps)
+			}
 		}
 	}
 
@@ -179,7 +198,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		return ctrl.Result{}, err
 	}
 
-	return r.manageOwnedKubernetesDiscovery(ctx, nn, newKA)
+	result, err := r.manageOwnedKubernetesDiscovery(ctx, nn, newKA)
+	if err == nil && retryResync && result.RequeueAfter == 0 {
+		result.RequeueAfter = time.Second
+	}
+	return result, err
 }
 
 // Determine if we should deploy the current YAML.
@@ -256,6 +279,101 @@ func (r *Reconciler) shouldDeployOnReconcile(
 	return false
 }
 
+// Determine if we should re-apply after the cluster reconnected.
+//
+// While the cluster was unreachable (e.g., the laptop was asleep), it may have
+// been reset, so the objects we applied are gone even though nothing changed
+// on our side. Once per connection revision, check that the objects are still
+// there. If any are missing, and the inputs still match AppliedInputHash,
+// apply them again.
+//
+// If we can't finish the check (e.g., the cluster is still waking up), we
+// don't record the revision, and ask to be requeued so that we check again.
+//
+// Objects managed by the buildcontrol engine are included: they've already
+// been deployed once, so their resource dependencies were satisfied.
+func (r *Reconciler) shouldResyncAfterReconnect(
+	ctx context.Context,
+	nn types.NamespacedName,
+	ka *v1alpha1.KubernetesApply,
+	cluster *v1alpha1.Cluster,
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
+) (resync bool, retry bool) {
+	if ka.Spec.Cluster == "" || cluster.Name == "" ||
+		cluster.Status.Error != "" || cluster.Status.ConnectedAt == nil {
+		return false, false
+	}
+
+	revision := *cluster.Status.ConnectedAt
+	r.mu.Lock()
+	result, ok := r.results[nn]
+	if !ok || result.Status.LastApplyTime.IsZero() || result.Status.Error != "" {
+		r.mu.Unlock()
+		return false, false
+	}
+	lastRevision := result.ClusterRevision
+	appliedInputHash := result.Status.AppliedInputHash
+	var applied []k8s.K8sEntity
+	for _, e := range result.AppliedObjects {
+		applied = append(applied, e)
+	}
+	r.mu.Unlock()
+
+	if timecmp.Equal(lastRevision, revision) {
+		// We haven't seen a reconnect since the last apply or check.
+		return false, false
+	}
+	if lastRevision.IsZero() {
+		// We applied before the cluster connected, so there's nothing to check.
+		r.recordClusterRevision(nn, revision)
+		return false, false
+	}
+
+	inputHash, err := ComputeInputHash(ka.Spec, imageMaps)
+	if err != nil || inputHash != appliedInputHash {
+		// The inputs changed, so the next deploy will apply them anyway.
+		r.recordClusterRevision(nn, revision)
+		return false, false
+	}
+
+	kCli, err := r.k8sClientForCluster(cluster)
+	if err != nil {
+		r.recordClusterRevision(nn, revision)
+		return false, false
+	}
+
+	var missing []string
+	for _, e := range applied {
+		_, err := kCli.GetMetaByReference(ctx, e.ToObjectReference())
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, fmt.Sprintf("%s:%s", e.Name(), e.GVK().Kind))
+		} else if err != nil {
+			logger.Get(ctx).Debugf("Verifying %s after reconnect: %v", e.Name(), err)
+			return false, true
+		}
+	}
+
+	r.recordClusterRevision(nn, revision)
+	if len(missing) == 0 {
+		return false, false
+	}
+
+	sort.Strings(missing)
+	logger.Get(ctx).Infof("Objects missing from cluster %q after reconnecting: %s. Re-applying.",
+		cluster.Name, strings.Join(missing, ", "))
+	return true, false
+}
+
+// Remember that we've checked the applied objects for this connection revision.
+func (r *Reconciler) recordClusterRevision(nn types.NamespacedName, revision metav1.MicroTime) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok := r.results[nn]
+	if ok {
+		result.ClusterRevision = revision
+	}
+}
+
 // Inject the images into the YAML and apply it to the cluster, unconditionally.
 //
 // Does not update the API server, but does trigger a re-reconcile
@@ -305,7 +423,12 @@ func (r *Reconciler) forceApplyHelper(
 	var deployed []k8s.K8sEntity
 	deployCtx := r.indentLogger(ctx)
 	if spec.YAML != "" {
-		deployed, err = r.runYAMLDeploy(deployCtx, spec, imageMaps)
+		deployed, err = r.runYAMLDeploy(deployCtx, spec, cluster, imageMaps)
+		if err != nil {
+			return recordErrorStatus(err)
+		}
+	} else if spec.HelmRelease != nil {
+		deployed, status.HelmRelease, err = r.runHelmDeploy(deployCtx, spec, cluster, imageMaps)
 		if err != nil {
 			return recordErrorStatus(err)
 		}
@@ -344,9 +467,14 @@ func (r *Reconciler) printAppliedReport(ctx context.Context, msg string, deploye
 	}
 }
 
-func (r *Reconciler) runYAMLDeploy(ctx context.Context, spec v1alpha1.KubernetesApplySpec, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) ([]k8s.K8sEntity, error) {
+func (r *Reconciler) runYAMLDeploy(ctx context.Context, spec v1alpha1.KubernetesApplySpec, cluster *v1alpha1.Cluster, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) ([]k8s.K8sEntity, error) {
+	kCli, err := r.k8sClientForCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create API objects.
-	newK8sEntities, err := r.createEntitiesToDeploy(ctx, imageMaps, spec)
+	newK8sEntities, err := r.createEntitiesToDeploy(ctx, kCli, cluster, imageMaps, spec)
 	if err != nil {
 		return newK8sEntities, err
 	}
@@ -358,7 +486,7 @@ func (r *Reconciler) runYAMLDeploy(ctx context.Context, spec v1alpha1.Kubernetes
 		timeout = v1alpha1.KubernetesApplyTimeoutDefault
 	}
 
-	deployed, err := r.k8sClient.Upsert(ctx, newK8sEntities, timeout)
+	deployed, err := kCli.Upsert(ctx, newK8sEntities, timeout)
 	if err != nil {
 		r.printAppliedReport(ctx, "Tried to apply objects to cluster:", newK8sEntities)
 		return nil, err
@@ -434,6 +562,124 @@ func (r *Reconciler) runCmdDeploy(ctx context.Context, spec v1alpha1.KubernetesA
 	return entities, nil
 }
 
+// The Helm SDK counterpart of runCmdDeploy.
+//
+// Returns the status of the new revision even if the deploy failed, so that
+// a failed upgrade shows up on the resource.
+func (r *Reconciler) runHelmDeploy(ctx context.Context, spec v1alpha1.KubernetesApplySpec,
+	cluster *v1alpha1.Cluster,
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) ([]k8s.K8sEntity, *v1alpha1.KubernetesApplyHelmReleaseStatus, error) {
+	hr := spec.HelmRelease
+	imageValues, err := helmImageValues(hr.ImageKeys, imageMaps)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := logger.Get(ctx)
+	l.Infof("Upgrading Helm release %s", hr.ReleaseName)
+	rel, err := r.helm.Upgrade(ctx, r.helmKubeConfig(cluster), helm.ReleaseOptions{
+		ReleaseName:     hr.ReleaseName,
+		Namespace:       hr.Namespace,
+		Chart:           hr.Chart,
+		ValueFiles:      hr.ValueFiles,
+		Set:             hr.Set,
+		SetString:       imageValues,
+		CreateNamespace: hr.CreateNamespace,
+		Atomic:          hr.Atomic,
+		Timeout:         spec.Timeout.Duration,
+	})
+
+	var status *v1alpha1.KubernetesApplyHelmReleaseStatus
+	if rel != nil {
+		status = &v1alpha1.KubernetesApplyHelmReleaseStatus{
+			Namespace: rel.Namespace,
+			Revision:  int32(rel.Revision),
+			Status:    rel.Status,
+		}
+		for _, hook := range rel.Hooks {
+			l.Infof("Hook %s", hook)
+		}
+		l.Infof("Release %s revision %d: %s", rel.Name, rel.Revision, rel.Status)
+	}
+	if err != nil {
+		return nil, status, fmt.Errorf("helm release %s: %v", hr.ReleaseName, err)
+	}
+
+	entities := make([]k8s.K8sEntity, 0, len(rel.Objects))
+	for _, obj := range rel.Objects {
+		entities = append(entities, k8s.NewK8sEntity(obj))
+	}
+	r.printAppliedReport(ctx, "Objects applied to cluster:", entities)
+
+	return entities, status, nil
+}
+
+// Chart values that point the release at the images we built,
+// in --set-string syntax.
+func helmImageValues(imageKeys []v1alpha1.KubernetesApplyHelmImageKeys, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) ([]string, error) {
+	var result []string
+	for _, keys := range imageKeys {
+		imageMap, ok := imageMaps[types.NamespacedName{Name: keys.ImageMap}]
+		if !ok {
+			// Live Update-only images aren't deployed.
+			continue
+		}
+
+		image := imageMap.Status.ImageFromCluster
+		if keys.Image != "" {
+			result = append(result, fmt.Sprintf("%s=%s", keys.Image, image))
+			continue
+		}
+
+		ref, err := container.ParseNamedTagged(image)
+		if err != nil {
+			return nil, fmt.Errorf("parsing image map status: %v", err)
+		}
+		result = append(result,
+			fmt.Sprintf("%s=%s", keys.Repository, ref.Name()),
+			fmt.Sprintf("%s=%s", keys.Tag, ref.Tag()))
+	}
+	return result, nil
+}
+
+// The client for the cluster that objects are applied to.
+//
+// Every cluster has its own connection. The default cluster falls back
+// to the global client when it isn't connected yet, because the global
+// client talks to the same context. A provisioned cluster never talks to
+// the startup context, so it has nothing to fall back to.
+func (r *Reconciler) k8sClientForCluster(obj *v1alpha1.Cluster) (k8s.Client, error) {
+	name := v1alpha1.ClusterNameDefault
+	if obj != nil && obj.Name != "" {
+		name = obj.Name
+	}
+
+	kCli, _, err := r.clients.GetK8sClient(types.NamespacedName{Name: name})
+	if err == nil {
+		return kCli, nil
+	}
+	isProvisioned := obj != nil && obj.Spec.Provision != nil
+	if name == v1alpha1.ClusterNameDefault && !isProvisioned {
+		return r.k8sClient, nil
+	}
+	return nil, fmt.Errorf("cluster %s not connected: %v", name, err)
+}
+
+// Helm talks to the cluster on its own, so tell it which cluster
+// we're connected to.
+func (r *Reconciler) helmKubeConfig(cluster *v1alpha1.Cluster) helm.KubeConfig {
+	if cluster != nil &&
+		cluster.Status.Connection != nil &&
+		cluster.Status.Connection.Kubernetes != nil &&
+		cluster.Status.Connection.Kubernetes.ConfigPath != "" {
+		return helm.KubeConfig{
+			Path:    cluster.Status.Connection.Kubernetes.ConfigPath,
+			Context: cluster.Status.Connection.Kubernetes.Context,
+		}
+	}
+	return helm.KubeConfig{Context: r.k8sClient.APIConfig().CurrentContext}
+}
+
 const maxOverflow = 500
 
 // The stdout of a well-behaved apply function can be 100K+ (especially for CRDs)
@@ -456,6 +702,8 @@ type injectResult struct {
 }
 
 func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
+	kCli k8s.Client,
+	cluster *v1alpha1.Cluster,
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
 	spec v1alpha1.KubernetesApplySpec) ([]k8s.K8sEntity, error) {
 	newK8sEntities := []k8s.K8sEntity{}
@@ -504,7 +752,7 @@ func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
 		// When working with a local k8s cluster, we set the pull policy to Never,
 		// to ensure that k8s fails hard if the image is missing from docker.
 		policy := v1.PullIfNotPresent
-		if r.dkc.WillBuildToKubeContext(k8s.KubeContext(r.k8sClient.APIConfig().CurrentContext)) {
+		if k8s.BuildsToClusterRuntime(r.dkc, cluster, k8s.KubeContext(kCli.APIConfig().CurrentContext)) {
 			policy = v1.PullNever
 		}
 
@@ -526,6 +774,16 @@ func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
 				return nil, fmt.Errorf("parsing image map status: %v", err)
 			}
 
+			// If the image was pushed, pin it by digest. For multi-platform images,
+			// this is the digest of the image index, so each node pulls
+			// the image for its own platform.
+			if imageMap.Status.Digest != "" && policy != v1.PullNever {
+				ref, err = reference.WithDigest(ref, digest.Digest(imageMap.Status.Digest))
+				if err != nil {
+					return nil, fmt.Errorf("parsing image map status digest: %v", err)
+				}
+			}
+
 			var replaced bool
 			e, replaced, err = k8s.InjectImageDigest(e, selector, ref, locators, matchInEnvVars, policy)
 			if err != nil {
@@ -595,6 +853,7 @@ type applyResult struct {
 	LastApplyStartTime metav1.MicroTime
 	AppliedInputHash   string
 	Objects            []k8s.K8sEntity
+	HelmRelease        *v1alpha1.KubernetesApplyHelmReleaseStatus
 }
 
 // conditionsFromApply extracts any conditions based on the result.
@@ -661,11 +920,15 @@ func (r *Reconciler) recordApplyResult(
 	updatedStatus.LastApplyTime = applyResult.LastApplyTime
 	updatedStatus.AppliedInputHash = applyResult.AppliedInputHash
 	updatedStatus.Conditions = conditionsFromApply(applyResult)
+	updatedStatus.HelmRelease = applyResult.HelmRelease
 
 	result.Cluster = cluster
+	if cluster != nil && cluster.Status.ConnectedAt != nil {
+		result.ClusterRevision = *cluster.Status.ConnectedAt
+	}
 	result.Spec = spec
 	result.Status = *updatedStatus
-	if spec.ApplyCmd != nil {
+	if spec.ApplyCmd != nil || spec.HelmRelease != nil {
 		result.CmdApplied = true
 	}
 	result.SetAppliedObjects(newObjectRefSet(applyResult.Objects))
@@ -776,11 +1039,14 @@ func (r *Reconciler) garbageCollect(nn types.NamespacedName, isDeleting bool) de
 		return deleteSpec{}
 	}
 
-	if result.Spec.DeleteCmd != nil {
+	if result.Spec.DeleteCmd != nil || result.Spec.HelmRelease != nil {
 		if !isDeleting || !result.CmdApplied {
 			// If there's a custom apply + delete command, GC only happens if
 			// the KubernetesApply object is being deleted (or disabled) and
 			// the apply command was actually executed (by Tilt).
+			//
+			// Helm releases work the same way: Helm deletes objects that
+			// are removed from the chart on upgrade.
 			return deleteSpec{}
 		}
 
@@ -793,8 +1059,9 @@ func (r *Reconciler) garbageCollect(nn types.NamespacedName, isDeleting bool) de
 		}
 		result.clearApplyStatus()
 		return deleteSpec{
-			deleteCmd: result.Spec.DeleteCmd,
-			cluster:   result.Cluster,
+			deleteCmd:   result.Spec.DeleteCmd,
+			helmRelease: result.Spec.HelmRelease,
+			cluster:     result.Cluster,
 		}
 	}
 
@@ -852,6 +1119,8 @@ func (r *Reconciler) ForceDelete(ctx context.Context, nn types.NamespacedName,
 		toDelete.entities = k8s.ReverseSortedEntities(entities)
 	} else if spec.DeleteCmd != nil {
 		toDelete.deleteCmd = spec.DeleteCmd
+	} else if spec.HelmRelease != nil {
+		toDelete.helmRelease = spec.HelmRelease
 	}
 
 	r.recordDelete(nn)
@@ -890,7 +1159,7 @@ func (r *Reconciler) maybeUpdateStatus(ctx context.Context, nn types.NamespacedN
 }
 
 func (r *Reconciler) bestEffortDelete(ctx context.Context, nn types.NamespacedName, toDelete deleteSpec, reason string) {
-	if len(toDelete.entities) == 0 && toDelete.deleteCmd == nil {
+	if len(toDelete.entities) == 0 && toDelete.deleteCmd == nil && toDelete.helmRelease == nil {
 		return
 	}
 
@@ -905,7 +1174,10 @@ func (r *Reconciler) bestEffortDelete(ctx context.Context, nn types.NamespacedNa
 			l.Infof("→ %s", displayName)
 		}
 
-		err := r.k8sClient.Delete(ctx, toDelete.entities, toDelete.wait)
+		kCli, err := r.k8sClientForCluster(toDelete.cluster)
+		if err == nil {
+			err = kCli.Delete(ctx, toDelete.entities, toDelete.wait)
+		}
 		if err != nil {
 			l.Errorf("Error %s: %v", reason, err)
 		}
@@ -919,6 +1191,16 @@ func (r *Reconciler) bestEffortDelete(ctx context.Context, nn types.NamespacedNa
 		}
 		r.recordDeleteCmdRun(nn)
 	}
+
+	if toDelete.helmRelease != nil {
+		hr := toDelete.helmRelease
+		l.Infof("→ Helm release %s", hr.ReleaseName)
+		err := r.helm.Uninstall(ctx, r.helmKubeConfig(toDelete.cluster), hr.ReleaseName, hr.Namespace, 0)
+		if err != nil {
+			l.Errorf("Error %s: %v", reason, err)
+		}
+		r.recordDeleteCmdRun(nn)
+	}
 }
 
 var imGVK = v1alpha1.SchemeGroupVersion.WithKind("ImageMap")
@@ -956,8 +1238,13 @@ func indexKubernetesApply(obj client.Object) []indexer.Key {
 
 // Keeps track of the state we currently know about.
 type Result struct {
-	Spec             v1alpha1.KubernetesApplySpec
-	Cluster          *v1alpha1.Cluster
+	Spec    v1alpha1.KubernetesApplySpec
+	Cluster *v1alpha1.Cluster
+
+	// The connection revision of the cluster (Cluster.Status.ConnectedAt)
+	// when we last applied or verified the applied objects.
+	ClusterRevision metav1.MicroTime
+
 	ImageMapSpecs    []v1alpha1.ImageMapSpec
 	ImageMapStatuses []v1alpha1.ImageMapStatus
 
@@ -979,6 +1266,7 @@ func (r *Result) clearApplyStatus() {
 	update.LastApplyStartTime = metav1.MicroTime{}
 	update.Error = ""
 	update.ResultYAML = ""
+	update.HelmRelease = nil
 	r.Status = *update
 }
 
@@ -13,14 +13,17 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/localexec"
@@ -183,6 +186,44 @@ func TestApplyCmdWithImages(t *testing.T) {
 	}
 }
 
+func TestApplyYAMLInjectsImageDigest(t *testing.T) {
+	f := newFixture(t)
+
+	dig := "sha256:dd5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1"
+	f.Create(&v1alpha1.ImageMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "sancho-image",
+		},
+		Spec: v1alpha1.ImageMapSpec{
+			Selector: "gcr.io/some-project-162817/sancho",
+		},
+		Status: v1alpha1.ImageMapStatus{
+			Image:            "gcr.io/some-project-162817/sancho:tilt-dd5f4c463f81c551",
+			ImageFromCluster: "gcr.io/some-project-162817/sancho:tilt-dd5f4c463f81c551",
+			Digest:           dig,
+			PlatformDigests: []v1alpha1.ImageMapPlatformDigest{
+				{Platform: "linux/amd64", Digest: "sha256:aaaa"},
+				{Platform: "linux/arm64", Digest: "sha256:bbbb"},
+			},
+		},
+	})
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML:      testyaml.SanchoYAML,
+			ImageMaps: []string{"sancho-image"},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml,
+		"image: gcr.io/some-project-162817/sancho:tilt-dd5f4c463f81c551@"+dig)
+}
+
 func TestApplyCmdWithKubeconfig(t *testing.T) {
 	f := newFixture(t)
 
@@ -232,6 +273,181 @@ func TestApplyCmdWithKubeconfig(t *testing.T) {
 
 }
 
+func TestHelmRelease(t *testing.T) {
+	f := newFixture(t)
+
+	entities, err := k8s.ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+	entities[0].SetUID("sancho-uid")
+	f.helm.Objects["sancho"] = []runtime.Object{entities[0].Obj}
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			HelmRelease: &v1alpha1.KubernetesApplyHelmRelease{
+				ReleaseName: "sancho",
+				Namespace:   "sancho-ns",
+				Chart:       "/path/to/chart",
+				ValueFiles:  []string{"/path/to/values.yaml"},
+				Set:         []string{"replicas=2"},
+				Atomic:      true,
+			},
+		},
+	}
+	f.Create(&ka)
+
+	if assert.Len(t, f.helm.UpgradeCalls, 1) {
+		opts := f.helm.UpgradeCalls[0].Options
+		assert.Equal(t, "sancho", opts.ReleaseName)
+		assert.Equal(t, "sancho-ns", opts.Namespace)
+		assert.Equal(t, "/path/to/chart", opts.Chart)
+		assert.Equal(t, []string{"/path/to/values.yaml"}, opts.ValueFiles)
+		assert.Equal(t, []string{"replicas=2"}, opts.Set)
+		assert.True(t, opts.Atomic)
+	}
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(t, "", ka.Status.Error)
+	assert.Contains(t, ka.Status.ResultYAML, "uid: sancho-uid")
+	assert.Equal(t, &v1alpha1.KubernetesApplyHelmReleaseStatus{
+		Namespace: "sancho-ns",
+		Revision:  1,
+		Status:    "deployed",
+	}, ka.Status.HelmRelease)
+	assert.Contains(t, f.Stdout(), "Release sancho revision 1: deployed")
+
+	f.Delete(&ka)
+	if assert.Len(t, f.helm.UninstallCalls, 1) {
+		call := f.helm.UninstallCalls[0]
+		assert.Equal(t, "sancho", call.Name)
+		assert.Equal(t, "sancho-ns", call.Namespace)
+	}
+	assert.Equal(t, 0, f.helm.Revision("sancho"))
+}
+
+func TestHelmReleaseFailure(t *testing.T) {
+	f := newFixture(t)
+	f.helm.UpgradeErr = fmt.Errorf("pre-upgrade hooks failed: job failed: BackoffLimitExceeded")
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			HelmRelease: &v1alpha1.KubernetesApplyHelmRelease{
+				ReleaseName: "sancho",
+				Chart:       "/path/to/chart",
+			},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(t, "helm release sancho: pre-upgrade hooks failed: job failed: BackoffLimitExceeded", ka.Status.Error)
+	assert.Equal(t, &v1alpha1.KubernetesApplyHelmReleaseStatus{
+		Namespace: "default",
+		Revision:  1,
+		Status:    "failed",
+	}, ka.Status.HelmRelease)
+
+	// A failed release is still uninstalled.
+	f.Delete(&ka)
+	assert.Len(t, f.helm.UninstallCalls, 1)
+}
+
+func TestHelmReleaseWithImages(t *testing.T) {
+	f := newFixture(t)
+
+	f.Create(&v1alpha1.ImageMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "image-a",
+		},
+		Status: v1alpha1.ImageMapStatus{
+			Image:            "localhost:5000/image-a:tilt-123",
+			ImageFromCluster: "registry:5000/image-a:tilt-123",
+		},
+	})
+	f.Create(&v1alpha1.ImageMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "image-b",
+		},
+		Status: v1alpha1.ImageMapStatus{
+			Image:            "image-b:tilt-456",
+			ImageFromCluster: "image-b:tilt-456",
+		},
+	})
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			ImageMaps: []string{"image-a", "image-b"},
+			HelmRelease: &v1alpha1.KubernetesApplyHelmRelease{
+				ReleaseName: "sancho",
+				Chart:       "/path/to/chart",
+				ImageKeys: []v1alpha1.KubernetesApplyHelmImageKeys{
+					{ImageMap: "image-a", Repository: "image.repository", Tag: "image.tag"},
+					{ImageMap: "image-b", Image: "sidecar.image"},
+				},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	if assert.Len(t, f.helm.UpgradeCalls, 1) {
+		assert.Equal(t, []string{
+			"image.repository=registry:5000/image-a",
+			"image.tag=tilt-123",
+			"sidecar.image=image-b:tilt-456",
+		}, f.helm.UpgradeCalls[0].Options.SetString)
+	}
+}
+
+func TestHelmReleaseWithKubeconfig(t *testing.T) {
+	f := newFixture(t)
+
+	f.Create(&v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default-cluster",
+		},
+		Status: v1alpha1.ClusterStatus{
+			Connection: &v1alpha1.ClusterConnectionStatus{
+				Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{
+					Context:    "my-context",
+					ConfigPath: "/path/to/my/kubeconfig",
+				},
+			},
+		},
+	})
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			Cluster: "default-cluster",
+			HelmRelease: &v1alpha1.KubernetesApplyHelmRelease{
+				ReleaseName: "sancho",
+				Chart:       "/path/to/chart",
+			},
+		},
+	}
+	f.Create(&ka)
+
+	expected := helm.KubeConfig{Path: "/path/to/my/kubeconfig", Context: "my-context"}
+	if assert.Len(t, f.helm.UpgradeCalls, 1) {
+		assert.Equal(t, expected, f.helm.UpgradeCalls[0].Kube)
+	}
+
+	f.Delete(&ka)
+	if assert.Len(t, f.helm.UninstallCalls, 1) {
+		assert.Equal(t, expected, f.helm.UninstallCalls[0].Kube)
+	}
+}
+
 func TestBasicApplyCmd_ExecError(t *testing.T) {
 	f := newFixture(t)
 
@@ -367,6 +583,163 @@ func TestGarbageCollectAllOnDelete_YAML(t *testing.T) {
 	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: sancho")
 }
 
+func TestApplyYAMLToNonDefaultCluster(t *testing.T) {
+	f := newFixture(t)
+	edge, _ := f.clients.EnsureK8sCluster(f.Context(), types.NamespacedName{Name: "edge"})
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			Cluster: "edge",
+			YAML:    testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), edge.Yaml, "name: sancho")
+	assert.Equal(f.T(), "", f.kClient.Yaml)
+
+	f.Delete(&ka)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), edge.DeletedYaml, "name: sancho")
+	assert.Equal(f.T(), "", f.kClient.DeletedYaml)
+}
+
+func TestReapplyMissingObjectsAfterReconnect(t *testing.T) {
+	f := newFixture(t)
+	clusterNN := types.NamespacedName{Name: "edge"}
+	edge, _ := f.clients.EnsureK8sCluster(f.Context(), clusterNN)
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			Cluster: "edge",
+			YAML:    testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+	assert.Contains(f.T(), edge.Yaml, "name: sancho")
+
+	// Nothing happens until the cluster reconnects.
+	edge.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", edge.Yaml)
+
+	// The cluster was reset during the outage, so the objects are gone.
+	f.clients.ReconnectK8sCluster(f.Context(), clusterNN)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), edge.Yaml, "name: sancho")
+	f.AssertStdOutContains(`Objects missing from cluster "edge" after reconnecting: sancho:Deployment. Re-applying.`)
+
+	// Only verified once per reconnect.
+	edge.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", edge.Yaml)
+}
+
+func TestRecheckAfterReconnectIfVerifyFails(t *testing.T) {
+	f := newFixture(t)
+	clusterNN := types.NamespacedName{Name: "edge"}
+	edge, _ := f.clients.EnsureK8sCluster(f.Context(), clusterNN)
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			Cluster: "edge",
+			YAML:    testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+	assert.Contains(f.T(), edge.Yaml, "name: sancho")
+
+	// The cluster isn't answering yet, so we can't tell if the objects are there.
+	edge.Yaml = ""
+	edge.GetMetaByReferenceError = fmt.Errorf("connection refused")
+	f.clients.ReconnectK8sCluster(f.Context(), clusterNN)
+	result := f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", edge.Yaml)
+	assert.Equal(f.T(), time.Second, result.RequeueAfter)
+
+	// Once it answers, we find out the objects are gone.
+	edge.GetMetaByReferenceError = nil
+	result = f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), edge.Yaml, "name: sancho")
+	assert.Equal(f.T(), time.Duration(0), result.RequeueAfter)
+}
+
+func TestNoReapplyAfterReconnectIfObjectsExist(t *testing.T) {
+	f := newFixture(t)
+	clusterNN := types.NamespacedName{Name: "edge"}
+	edge, _ := f.clients.EnsureK8sCluster(f.Context(), clusterNN)
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			Cluster: "edge",
+			YAML:    testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+	assert.Contains(f.T(), edge.Yaml, "name: sancho")
+	edge.Inject(edge.LastUpsertResult...)
+
+	edge.Yaml = ""
+	f.clients.ReconnectK8sCluster(f.Context(), clusterNN)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", edge.Yaml)
+}
+
+func TestApplyYAMLToProvisionedCluster(t *testing.T) {
+	f := newFixture(t)
+	f.provisionDefaultCluster()
+	provisioned := f.clients.EnsureDefaultK8sCluster(f.Context())
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			Cluster: v1alpha1.ClusterNameDefault,
+			YAML:    testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), provisioned.Yaml, "name: sancho")
+	assert.Equal(f.T(), "", f.kClient.Yaml, "should not apply to the startup context")
+}
+
+func TestNoApplyToStartupContextIfProvisionedClusterNotConnected(t *testing.T) {
+	f := newFixture(t)
+	f.provisionDefaultCluster()
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			Cluster: v1alpha1.ClusterNameDefault,
+			YAML:    testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Contains(f.T(), ka.Status.Error, "cluster default not connected")
+	assert.Equal(f.T(), "", f.kClient.Yaml, "should not apply to the startup context")
+}
+
 func TestGarbageCollectAllOnDelete_Cmd(t *testing.T) {
 	f := newFixture(t)
 
@@ -772,6 +1145,8 @@ type fixture struct {
 	r       *Reconciler
 	kClient *k8s.FakeK8sClient
 	execer  *localexec.FakeExecer
+	helm    *helm.FakeReleaseClient
+	clients *cluster.FakeClientProvider
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -786,13 +1161,17 @@ func newFixture(t *testing.T) *fixture {
 	execer := localexec.NewFakeExecer(t)
 
 	db := build.NewDockerBuilder(dockerClient, dockerfile.Labels{})
-	r := NewReconciler(cfb.Client, kClient, v1alpha1.NewScheme(), db, cfb.Store, execer)
+	helmClient := helm.NewFakeReleaseClient()
+	clients := cluster.NewFakeClientProvider(t, cfb.Client)
+	r := NewReconciler(cfb.Client, kClient, clients, v1alpha1.NewScheme(), db, cfb.Store, execer, helmClient)
 
 	f := &fixture{
 		ControllerFixture: cfb.Build(r),
 		r:                 r,
 		kClient:           kClient,
 		execer:            execer,
+		helm:              helmClient,
+		clients:           clients,
 	}
 	f.Create(&v1alpha1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -810,6 +1189,13 @@ func newFixture(t *testing.T) *fixture {
 	return f
 }
 
+func (f *fixture) provisionDefaultCluster() {
+	var c v1alpha1.Cluster
+	f.MustGet(types.NamespacedName{Name: v1alpha1.ClusterNameDefault}, &c)
+	c.Spec.Provision = &v1alpha1.ClusterProvision{Product: v1alpha1.ClusterProvisionProductKind}
+	f.Update(&c)
+}
+
 // createApplyCmd creates a KubernetesApplyCmd that use the passed YAML to generate simulated stdout via the FakeExecer.
 func (f *fixture) createApplyCmd(name string, yaml string) (v1alpha1.KubernetesApplyCmd, string) {
 	f.T().Helper()
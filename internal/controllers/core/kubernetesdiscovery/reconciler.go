@@ -711,6 +711,7 @@ func (w *Reconciler) createPodLogStream(ctx context.Context, kd *v1alpha1.Kubern
 			},
 		},
 		Spec: v1alpha1.PodLogStreamSpec{
+			Cluster:          kd.Spec.Cluster,
 			Pod:              pod.Name,
 			Namespace:        pod.Namespace,
 			SinceTime:        plsTemplate.SinceTime,
@@ -333,6 +333,7 @@ func TestReconcileManagesPodLogStream(t *testing.T) {
 	assert.Equal(t, "pod2", podLogStreams.Items[1].Spec.Pod)
 
 	for _, pls := range podLogStreams.Items {
+		assert.Equal(t, v1alpha1.ClusterNameDefault, pls.Spec.Cluster)
 		assert.Equal(t, ns.String(), pls.Spec.Namespace)
 
 		timecmp.AssertTimeEqual(t, sinceTime, pls.Spec.SinceTime)
@@ -499,6 +500,44 @@ func TestKubernetesDiscoveryClusterError(t *testing.T) {
 	require.Nil(t, kd.Status.Running, "Running should not be populated")
 }
 
+func TestClusterReconnect(t *testing.T) {
+	f := newFixture(t)
+
+	kd := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "some-ns", Name: "kd"},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Watches: []v1alpha1.KubernetesWatchRef{
+				{
+					UID:       "pod1-uid",
+					Namespace: "pod-ns",
+				},
+			},
+			Cluster: "default",
+		},
+	}
+	key := apis.Key(kd)
+	f.Create(kd)
+	f.requireMonitorStarted(key)
+
+	// the same client comes back with a new connection revision
+	newRev := f.clients.ReconnectK8sCluster(f.ctx, clusterNN(*kd))
+	f.MustReconcile(key)
+
+	f.r.mu.Lock()
+	var revisions []time.Time
+	for nsKey := range f.r.watchedNamespaces {
+		revisions = append(revisions, nsKey.cluster.revision)
+	}
+	f.r.mu.Unlock()
+	require.Len(t, revisions, 1, "Old namespace watch should have been replaced")
+	timecmp.RequireTimeEqual(t, newRev, revisions[0])
+
+	// pods are observed through the new watch
+	pod := f.buildPod("pod-ns", "pod1", nil, nil)
+	f.clients.MustK8sClient(clusterNN(*kd)).UpsertPod(pod)
+	f.requireObservedPods(key, ancestorMap{pod.UID: pod.UID}, podNameMap{pod.UID: pod.Name})
+}
+
 func TestClusterChange(t *testing.T) {
 	f := newFixture(t)
 
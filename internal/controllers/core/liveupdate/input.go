@@ -11,6 +11,9 @@ type Input struct {
 	// Derived from DockerResource
 	IsDC bool
 
+	// Derived from KubernetesDiscovery
+	Cluster string
+
 	// Derived from KubernetesResource + KubenetesSelector + DockerResource
 	Containers []liveupdates.Container
 
@@ -30,6 +30,9 @@ type monitor struct {
 	// History of container updates.
 	hasChangesToSync bool
 	containers       map[monitorContainerKey]monitorContainerStatus
+
+	// How the most recent batch of changed files was handled.
+	lastDecisions []v1alpha1.LiveUpdateFileDecision
 }
 
 type monitorSource struct {
@@ -56,3 +59,11 @@ type monitorContainerStatus struct {
 	failedReason       string
 	failedMessage      string
 }
+
+// The cluster that the discovered containers are running in.
+func (m *monitor) cluster() string {
+	if m.lastKubernetesDiscovery == nil {
+		return ""
+	}
+	return m.lastKubernetesDiscovery.Spec.Cluster
+}
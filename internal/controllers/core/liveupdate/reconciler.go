@@ -25,6 +25,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/containerupdate"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
@@ -62,6 +63,7 @@ type Reconciler struct {
 	DockerUpdater containerupdate.ContainerUpdater
 	updateMode    liveupdates.UpdateMode
 	kubeContext   k8s.KubeContext
+	clients       cluster.ClientProvider
 	startedTime   metav1.MicroTime
 
 	monitors map[string]*monitor
@@ -80,6 +82,7 @@ func NewReconciler(
 	ecu *containerupdate.ExecUpdater,
 	updateMode liveupdates.UpdateMode,
 	kubeContext k8s.KubeContext,
+	clients cluster.ClientProvider,
 	client ctrlclient.Client,
 	scheme *runtime.Scheme) *Reconciler {
 	return &Reconciler{
@@ -87,6 +90,7 @@ func NewReconciler(
 		ExecUpdater:   ecu,
 		updateMode:    updateMode,
 		kubeContext:   kubeContext,
+		clients:       clients,
 		client:        client,
 		indexer:       indexer.NewIndexer(scheme, indexLiveUpdate),
 		store:         st,
@@ -651,6 +655,7 @@ func (r *Reconciler) maybeSync(ctx context.Context, lu *v1alpha1.LiveUpdate, mon
 	})
 
 	if status.Failed != nil {
+		status.Decisions = monitor.lastDecisions
 		return status
 	}
 
@@ -659,6 +664,7 @@ func (r *Reconciler) maybeSync(ctx context.Context, lu *v1alpha1.LiveUpdate, mon
 	// Visit all containers, apply changes, and return their statuses.
 	terminatedContainerPodName := ""
 	hasAnyFilesToSync := false
+	allFilesChanged := []string{}
 	resource.visitSelectedContainers(func(pod v1alpha1.Pod, cInfo v1alpha1.Container) bool {
 		c := liveupdates.Container{
 			ContainerID:   container.ID(cInfo.ID),
@@ -702,6 +708,7 @@ func (r *Reconciler) maybeSync(ctx context.Context, lu *v1alpha1.LiveUpdate, mon
 		filesChanged = sliceutils.DedupedAndSorted(filesChanged)
 		if len(filesChanged) > 0 {
 			hasAnyFilesToSync = true
+			allFilesChanged = append(allFilesChanged, filesChanged...)
 		}
 
 		// Ignore completed pods/containers.
@@ -776,6 +783,7 @@ func (r *Reconciler) maybeSync(ctx context.Context, lu *v1alpha1.LiveUpdate, mon
 			// Apply the change to the container.
 			oneUpdateStatus = r.applyInternal(ctx, lu.Spec, Input{
 				IsDC:               lu.Spec.Selector.DockerCompose != nil,
+				Cluster:            monitor.cluster(),
 				ChangedFiles:       plan.SyncPaths,
 				Containers:         []liveupdates.Container{c},
 				LastFileTimeSynced: newHighWaterMark,
@@ -823,6 +831,16 @@ func (r *Reconciler) maybeSync(ctx context.Context, lu *v1alpha1.LiveUpdate, mon
 			fmt.Sprintf("Container for live update is stopped. Pod name: %s", terminatedContainerPodName))
 	}
 
+	// Keep a record of how we handled the most recent batch of files,
+	// so that users can see why an update did (or didn't) happen.
+	if hasAnyFilesToSync {
+		decisions, err := liveupdates.ExplainFiles(lu.Spec, sliceutils.DedupedAndSorted(allFilesChanged))
+		if err == nil {
+			monitor.lastDecisions = decisions
+		}
+	}
+	status.Decisions = monitor.lastDecisions
+
 	if updateEventDispatched {
 		r.dispatchCompleteBuildAction(lu, status)
 	}
@@ -886,7 +904,15 @@ func (r *Reconciler) applyInternal(
 	input Input) v1alpha1.LiveUpdateStatus {
 
 	var result v1alpha1.LiveUpdateStatus
-	cu := r.containerUpdater(input)
+	cu, err := r.containerUpdater(input)
+	if err != nil {
+		result.Failed = &v1alpha1.LiveUpdateStateFailed{
+			Reason:  "ClusterUnavailable",
+			Message: err.Error(),
+		}
+		return result
+	}
+
 	l := logger.Get(ctx)
 	containers := input.Containers
 	names := liveupdates.ContainerDisplayNames(containers)
@@ -996,22 +1022,36 @@ func (r *Reconciler) applyInternal(
 	return result
 }
 
-func (r *Reconciler) containerUpdater(input Input) containerupdate.ContainerUpdater {
+func (r *Reconciler) containerUpdater(input Input) (containerupdate.ContainerUpdater, error) {
 	isDC := input.IsDC
-	if isDC || r.updateMode == liveupdates.UpdateModeContainer {
-		return r.DockerUpdater
+	if isDC {
+		return r.DockerUpdater, nil
+	}
+
+	// The updaters talk to the default cluster. Containers in
+	// other clusters can only be reached through that cluster's client.
+	if input.Cluster != "" && input.Cluster != v1alpha1.ClusterNameDefault && r.clients != nil {
+		kCli, _, err := r.clients.GetK8sClient(types.NamespacedName{Name: input.Cluster})
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s not connected: %v", input.Cluster, err)
+		}
+		return containerupdate.NewExecUpdater(kCli), nil
+	}
+
+	if r.updateMode == liveupdates.UpdateModeContainer {
+		return r.DockerUpdater, nil
 	}
 
 	if r.updateMode == liveupdates.UpdateModeKubectlExec {
-		return r.ExecUpdater
+		return r.ExecUpdater, nil
 	}
 
 	dcu, ok := r.DockerUpdater.(*containerupdate.DockerUpdater)
 	if ok && dcu.WillBuildToKubeContext(r.kubeContext) {
-		return r.DockerUpdater
+		return r.DockerUpdater, nil
 	}
 
-	return r.ExecUpdater
+	return r.ExecUpdater, nil
 }
 
 func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
@@ -17,6 +17,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/containerupdate"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
@@ -128,6 +129,12 @@ func TestConsumeFileEvents(t *testing.T) {
 	if assert.Equal(t, 1, len(lu.Status.Containers)) {
 		assert.Equal(t, txtChangeTime, lu.Status.Containers[0].LastFileTimeSynced)
 	}
+	assert.Equal(t, []v1alpha1.LiveUpdateFileDecision{{
+		LocalPath:     txtPath,
+		Action:        v1alpha1.LiveUpdateFileActionSync,
+		SyncLocalPath: p,
+		ContainerPath: "/app/a.txt",
+	}}, lu.Status.Decisions)
 
 	// Also make sure the sync gets pulled into the monitor.
 	assert.Equal(t, map[string]metav1.MicroTime{
@@ -148,6 +155,52 @@ func TestConsumeFileEvents(t *testing.T) {
 	assert.NotNil(t, f.st.lastCompletedAction)
 }
 
+func TestConsumeFileEventsNonDefaultCluster(t *testing.T) {
+	f := newFixture(t)
+	clients := cluster.NewFakeClientProvider(t, f.Client)
+	f.r.clients = clients
+	edge, _ := clients.EnsureK8sCluster(f.Context(), types.NamespacedName{Name: "edge"})
+
+	p, _ := os.Getwd()
+	txtPath := filepath.Join(p, "a.txt")
+	txtChangeTime := metav1.MicroTime{Time: apis.NowMicro().Add(time.Second)}
+
+	f.setupFrontend()
+	f.kdUpdateCluster("frontend-discovery", "edge")
+
+	f.addFileEvent("frontend-fw", txtPath, txtChangeTime)
+	f.MustReconcile(types.NamespacedName{Name: "frontend-liveupdate"})
+
+	var lu v1alpha1.LiveUpdate
+	f.MustGet(types.NamespacedName{Name: "frontend-liveupdate"}, &lu)
+	assert.Nil(t, lu.Status.Failed)
+	assert.Equal(t, 0, len(f.cu.Calls))
+	assert.NotEmpty(t, edge.ExecCalls)
+}
+
+func TestConsumeFileEventsDisconnectedCluster(t *testing.T) {
+	f := newFixture(t)
+	f.r.clients = cluster.NewFakeClientProvider(t, f.Client)
+
+	p, _ := os.Getwd()
+	txtPath := filepath.Join(p, "a.txt")
+	txtChangeTime := metav1.MicroTime{Time: apis.NowMicro().Add(time.Second)}
+
+	f.setupFrontend()
+	f.kdUpdateCluster("frontend-discovery", "edge")
+
+	f.addFileEvent("frontend-fw", txtPath, txtChangeTime)
+	f.MustReconcile(types.NamespacedName{Name: "frontend-liveupdate"})
+
+	var lu v1alpha1.LiveUpdate
+	f.MustGet(types.NamespacedName{Name: "frontend-liveupdate"}, &lu)
+	if assert.NotNil(t, lu.Status.Failed) {
+		assert.Equal(t, "ClusterUnavailable", lu.Status.Failed.Reason)
+		assert.Contains(t, lu.Status.Failed.Message, "cluster edge not connected")
+	}
+	assert.Equal(t, 0, len(f.cu.Calls))
+}
+
 func TestConsumeFileEventsDockerCompose(t *testing.T) {
 	f := newFixture(t)
 
@@ -564,6 +617,10 @@ func TestStopPathConsumedByImageBuild(t *testing.T) {
 	if assert.NotNil(t, lu.Status.Failed) {
 		assert.Equal(t, "UpdateStopped", lu.Status.Failed.Reason)
 	}
+	if assert.Equal(t, 1, len(lu.Status.Decisions)) {
+		assert.Equal(t, v1alpha1.LiveUpdateFileActionStop, lu.Status.Decisions[0].Action)
+		assert.Equal(t, "stop.txt", lu.Status.Decisions[0].StopPath)
+	}
 
 	f.assertSteadyState(&lu)
 
@@ -1134,6 +1191,14 @@ func (f *fixture) assertSteadyState(lu *v1alpha1.LiveUpdate) {
 	assert.Equal(f.T(), startCalls, len(f.cu.Calls))
 }
 
+func (f *fixture) kdUpdateCluster(name string, cluster string) {
+	var kd v1alpha1.KubernetesDiscovery
+	f.MustGet(types.NamespacedName{Name: name}, &kd)
+	update := kd.DeepCopy()
+	update.Spec.Cluster = cluster
+	f.Update(update)
+}
+
 func (f *fixture) kdUpdateStatus(name string, status v1alpha1.KubernetesDiscoveryStatus) {
 	var kd v1alpha1.KubernetesDiscovery
 	f.MustGet(types.NamespacedName{Name: name}, &kd)
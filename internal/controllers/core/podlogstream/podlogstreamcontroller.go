@@ -18,6 +18,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +31,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/k8sconv"
+	"github.com/tilt-dev/tilt/internal/timecmp"
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
@@ -48,7 +50,6 @@ type Controller struct {
 	client    ctrlclient.Client
 	indexer   *indexer.Indexer
 	st        store.RStore
-	kClient   k8s.Client
 	podSource *PodSource
 	mu        sync.Mutex
 	clock     clockwork.Clock
@@ -62,13 +63,12 @@ type Controller struct {
 var _ reconcile.Reconciler = &Controller{}
 var _ store.TearDowner = &Controller{}
 
-func NewController(ctx context.Context, client ctrlclient.Client, scheme *runtime.Scheme, st store.RStore, kClient k8s.Client, podSource *PodSource, clock clockwork.Clock) *Controller {
+func NewController(ctx context.Context, client ctrlclient.Client, scheme *runtime.Scheme, st store.RStore, podSource *PodSource, clock clockwork.Clock) *Controller {
 	return &Controller{
 		ctx:             ctx,
 		client:          client,
 		indexer:         indexer.NewIndexer(scheme, indexPodLogStreamForTiltAPI),
 		st:              st,
-		kClient:         kClient,
 		podSource:       podSource,
 		watches:         make(map[podLogKey]*podLogWatch),
 		hasClosedStream: make(map[podLogKey]bool),
@@ -148,7 +148,7 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	if apierrors.IsNotFound(err) {
 		// handleReconcileRequest returns errors that should be published
 		// to status.error. But the pod log stream is deleted! so can ignore.
-		_ = c.podSource.handleReconcileRequest(ctx, req.NamespacedName, stream)
+		_ = c.podSource.handleReconcileRequest(ctx, req.NamespacedName, stream, nil)
 		c.deleteStreams(streamName)
 		return reconcile.Result{}, nil
 	} else if err != nil {
@@ -157,19 +157,28 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 
 	result := reconcile.Result{}
 	ctx = store.MustObjectLogHandler(ctx, c.st, stream)
-	err = c.podSource.handleReconcileRequest(ctx, streamName, stream)
+
+	var cluster v1alpha1.Cluster
+	err = c.client.Get(ctx, types.NamespacedName{Name: clusterName(stream)}, &cluster)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+
+	err = c.podSource.handleReconcileRequest(ctx, streamName, stream, &cluster)
 	if err != nil {
 		result = c.setErrorStatus(streamName, err)
+	} else if kCli, revision, err := c.podSource.k8sClient(stream, &cluster); err != nil {
+		result = c.setErrorStatus(streamName, err)
 	} else {
 		podNN := types.NamespacedName{Name: stream.Spec.Pod, Namespace: stream.Spec.Namespace}
-		pod, err := c.kClient.PodFromInformerCache(ctx, podNN)
+		pod, err := kCli.PodFromInformerCache(ctx, podNN)
 		if err != nil && apierrors.IsNotFound(err) {
 			c.deleteStreams(streamName)
 			result = c.setErrorStatus(streamName, fmt.Errorf("pod not found: %s", podNN))
 		} else if err != nil {
 			result = c.setErrorStatus(streamName, fmt.Errorf("reading pod: %v", err))
 		} else if pod != nil {
-			result = c.addOrUpdateContainerWatches(ctx, streamName, stream, podNN, pod)
+			result = c.addOrUpdateContainerWatches(ctx, kCli, revision, streamName, stream, podNN, pod)
 		}
 	}
 
@@ -181,7 +190,7 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	return result, nil
 }
 
-func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName types.NamespacedName, stream *v1alpha1.PodLogStream, podNN types.NamespacedName, pod *v1.Pod) reconcile.Result {
+func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, kCli k8s.Client, revision metav1.MicroTime, streamName types.NamespacedName, stream *v1alpha1.PodLogStream, podNN types.NamespacedName, pod *v1.Pod) reconcile.Result {
 	initContainers := c.filterContainers(stream, k8sconv.PodContainers(ctx, pod, pod.Status.InitContainerStatuses))
 	runContainers := c.filterContainers(stream, k8sconv.PodContainers(ctx, pod, pod.Status.ContainerStatuses))
 	containers := []v1alpha1.Container{}
@@ -227,8 +236,21 @@ func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName
 		debounce := time.Second
 
 		if isActive {
-			if existing.ctx.Err() == nil {
-				// The active pod watcher is still tailing the logs,
+			reconnected := !timecmp.Equal(existing.revision, revision)
+			if existing.ctx.Err() == nil && reconnected {
+				// The cluster reconnected since we started tailing, so
+				// the stream may be stuck on a dead connection. Cancel it,
+				// and the requeue when it finishes will pick up where it
+				// left off.
+				existing.cancel()
+				continue
+			}
+
+			select {
+			case <-existing.doneCh:
+			default:
+				// The active pod watcher is still tailing the logs
+				// (or is shutting down, and will requeue when it's done),
 				// nothing to do.
 				continue
 			}
@@ -236,7 +258,6 @@ func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName
 			// The active pod watcher got canceled somehow,
 			// so we need to create a new one that picks up
 			// where it left off.
-			<-existing.doneCh
 			startWatchTime = existing.doneWatchTime
 			debounce = existing.debounce
 			c.hasClosedStream[key] = true
@@ -249,7 +270,10 @@ func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName
 				continue
 			}
 
-			if c.clock.Since(existing.doneWatchTime) < debounce {
+			if reconnected {
+				// Don't wait out any backoff from errors during the outage.
+				debounce = time.Second
+			} else if c.clock.Since(existing.doneWatchTime) < debounce {
 				requeueAfter := debounce - c.clock.Since(existing.doneWatchTime)
 				if requeueAfter > result.RequeueAfter {
 					result.RequeueAfter = requeueAfter
@@ -263,6 +287,8 @@ func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName
 			streamName:     streamName,
 			ctx:            ctx,
 			cancel:         cancel,
+			kClient:        kCli,
+			revision:       revision,
 			podID:          k8s.PodID(podNN.Name),
 			cName:          container.Name(co.Name),
 			namespace:      k8s.Namespace(podNN.Namespace),
@@ -351,7 +377,7 @@ func (c *Controller) consumeLogs(watch *podLogWatch, st store.RStore) {
 	for retry {
 		retry = false
 		ctx, cancel := context.WithCancel(ctx)
-		readCloser, err := c.kClient.ContainerLogs(ctx, pID, containerName, ns, startReadTime)
+		readCloser, err := watch.kClient.ContainerLogs(ctx, pID, containerName, ns, startReadTime)
 		if err != nil {
 			if ctx.Err() == nil {
 				exitError = err
@@ -537,6 +563,8 @@ type podLogWatch struct {
 	cancel func()
 
 	streamName     types.NamespacedName
+	kClient        k8s.Client
+	revision       metav1.MicroTime
 	podID          k8s.PodID
 	namespace      k8s.Namespace
 	cName          container.Name
@@ -563,9 +591,9 @@ type podLogKey struct {
 func indexPodLogStreamForTiltAPI(obj ctrlclient.Object) []indexer.Key {
 	var results []indexer.Key
 	pls := obj.(*v1alpha1.PodLogStream)
-	if pls != nil && pls.Spec.Cluster != "" {
+	if pls != nil && pls.Spec.Pod != "" {
 		results = append(results, indexer.Key{
-			Name: types.NamespacedName{Namespace: pls.Namespace, Name: pls.Spec.Cluster},
+			Name: types.NamespacedName{Namespace: pls.Namespace, Name: clusterName(pls)},
 			GVK:  clusterGVK,
 		})
 	}
@@ -24,6 +24,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/k8s"
@@ -63,6 +64,109 @@ func TestLogs(t *testing.T) {
 	}, f.plsc.podSource.indexer.EnqueueKey(indexer.Key{Name: podNN, GVK: podGVK}))
 }
 
+func TestLogsFromNonDefaultCluster(t *testing.T) {
+	f := newPLMFixture(t)
+	edge, _ := f.clients.EnsureK8sCluster(f.Context(), types.NamespacedName{Name: "edge"})
+
+	edge.SetLogsForPodContainer(podID, cName, "hello from the edge!")
+
+	pb := newPodBuilder(podID).addRunningContainer(cName, cID)
+	edge.UpsertPod(pb.toPod())
+
+	pls := plsFromPod("server", pb, time.Time{})
+	pls.Spec.Cluster = "edge"
+	f.Create(pls)
+
+	f.triggerPodEvent(podID)
+	f.AssertOutputContains("hello from the edge!")
+}
+
+func TestLogsFromDisconnectedCluster(t *testing.T) {
+	f := newPLMFixture(t)
+
+	pb := newPodBuilder(podID).addRunningContainer(cName, cID)
+	pls := plsFromPod("server", pb, time.Time{})
+	pls.Spec.Cluster = "edge"
+	f.Create(pls)
+
+	f.MustReconcile(types.NamespacedName{Name: pls.Name})
+	f.MustGet(types.NamespacedName{Name: pls.Name}, pls)
+	assert.Contains(t, pls.Status.Error, "cluster edge not connected")
+}
+
+func TestLogsResumeAfterClusterReconnect(t *testing.T) {
+	f := newPLMFixture(t)
+	clusterNN := types.NamespacedName{Name: "edge"}
+	edge, _ := f.clients.EnsureK8sCluster(f.Context(), clusterNN)
+
+	pb := newPodBuilder(podID).addRunningContainer(cName, cID)
+	edge.UpsertPod(pb.toPod())
+
+	reader, writer := io.Pipe()
+	edge.SetLogReaderForPodContainer(podID, cName, reader)
+
+	pls := plsFromPod("server", pb, f.clock.Now())
+	pls.Spec.Cluster = "edge"
+	f.Create(pls)
+
+	_, err := writer.Write([]byte("before the outage\n"))
+	require.NoError(t, err)
+	f.AssertOutputContains("before the outage")
+
+	// The old stream is stuck on a dead connection; once the
+	// cluster reconnects, we should stream from a new one.
+	edge.SetLogsForPodContainer(podID, cName, "after the outage\n")
+	f.clients.ReconnectK8sCluster(f.Context(), clusterNN)
+	f.MustReconcile(types.NamespacedName{Name: pls.Name})
+	assert.Error(t, edge.LastPodLogContext.Err())
+
+	// Canceling the request closes the old connection.
+	require.NoError(t, writer.Close())
+	f.AssertOutputContains("after the outage")
+}
+
+func TestLogsResumeAfterDefaultClusterReconnect(t *testing.T) {
+	f := newPLMFixture(t)
+
+	// The default cluster isn't in the client provider, so the stream
+	// falls back to the global client, but still follows the Cluster's
+	// connection revision.
+	connectedAt := apis.NewMicroTime(f.clock.Now())
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.ClusterNameDefault},
+	}
+	f.Create(cluster)
+	cluster.Status.ConnectedAt = &connectedAt
+	f.UpdateStatus(cluster)
+
+	pb := newPodBuilder(podID).addRunningContainer(cName, cID)
+	f.kClient.UpsertPod(pb.toPod())
+
+	reader, writer := io.Pipe()
+	f.kClient.SetLogReaderForPodContainer(podID, cName, reader)
+
+	pls := plsFromPod("server", pb, f.clock.Now())
+	f.Create(pls)
+
+	_, err := writer.Write([]byte("before the outage\n"))
+	require.NoError(t, err)
+	f.AssertOutputContains("before the outage")
+
+	// Reconciling again on the same revision keeps the stream.
+	f.MustReconcile(types.NamespacedName{Name: pls.Name})
+	assert.NoError(t, f.kClient.LastPodLogContext.Err())
+
+	f.kClient.SetLogsForPodContainer(podID, cName, "after the outage\n")
+	reconnectedAt := apis.NewMicroTime(f.clock.Now().Add(time.Minute))
+	cluster.Status.ConnectedAt = &reconnectedAt
+	f.UpdateStatus(cluster)
+	f.MustReconcile(types.NamespacedName{Name: pls.Name})
+	assert.Error(t, f.kClient.LastPodLogContext.Err())
+
+	require.NoError(t, writer.Close())
+	f.AssertOutputContains("after the outage")
+}
+
 func TestLogCleanup(t *testing.T) {
 	f := newPLMFixture(t)
 
@@ -516,6 +620,7 @@ type plmFixture struct {
 	t       testing.TB
 	ctx     context.Context
 	kClient *k8s.FakeK8sClient
+	clients *cluster.FakeClientProvider
 	plsc    *Controller
 	out     *bufsync.ThreadSafeBuffer
 	store   *plmStore
@@ -534,14 +639,16 @@ func newPLMFixture(t testing.TB) *plmFixture {
 
 	clock := clockwork.NewFakeClock()
 	st := newPLMStore(t, out)
-	podSource := NewPodSource(ctx, kClient, cfb.Client.Scheme(), clock)
-	plsc := NewController(ctx, cfb.Client, cfb.Scheme(), st, kClient, podSource, clock)
+	clients := cluster.NewFakeClientProvider(t, cfb.Client)
+	podSource := NewPodSource(ctx, kClient, clients, cfb.Client.Scheme(), clock)
+	plsc := NewController(ctx, cfb.Client, cfb.Scheme(), st, podSource, clock)
 	indexer.StartSourceForTesting(cfb.Context(), plsc.podSource, plsc, nil)
 
 	return &plmFixture{
 		t:                 t,
 		ControllerFixture: cfb.Build(plsc),
 		kClient:           kClient,
+		clients:           clients,
 		plsc:              plsc,
 		ctx:               ctx,
 		out:               out,
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -18,8 +19,10 @@ import (
 
 	"github.com/jonboulle/clockwork"
 
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/timecmp"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 )
 
@@ -32,19 +35,29 @@ type PodSource struct {
 	ctx     context.Context
 	indexer *indexer.Indexer
 	kClient k8s.Client
+	clients cluster.ClientProvider
 	handler handler.EventHandler
 	q       workqueue.RateLimitingInterface
 	clock   clockwork.Clock
 
-	watchesByNamespace map[string]*podWatch
+	watchesByNamespace map[podWatchKey]*podWatch
 	mu                 sync.Mutex
 }
 
+type podWatchKey struct {
+	cluster   string
+	namespace string
+}
+
 type podWatch struct {
 	ctx       context.Context
 	cancel    func()
+	kClient   k8s.Client
 	namespace string
 
+	// The connection revision of the cluster when the watch started.
+	revision metav1.MicroTime
+
 	// Only populated if ctx.Err() != nil (the context has been cancelled)
 	finishedAt time.Time
 	error      error
@@ -52,12 +65,13 @@ type podWatch struct {
 
 var _ source.Source = &PodSource{}
 
-func NewPodSource(ctx context.Context, kClient k8s.Client, scheme *runtime.Scheme, clock clockwork.Clock) *PodSource {
+func NewPodSource(ctx context.Context, kClient k8s.Client, clients cluster.ClientProvider, scheme *runtime.Scheme, clock clockwork.Clock) *PodSource {
 	return &PodSource{
 		ctx:                ctx,
 		indexer:            indexer.NewIndexer(scheme, indexPodLogStreamForKubernetes),
 		kClient:            kClient,
-		watchesByNamespace: make(map[string]*podWatch),
+		clients:            clients,
+		watchesByNamespace: make(map[podWatchKey]*podWatch),
 		clock:              clock,
 	}
 }
@@ -83,7 +97,7 @@ func (s *PodSource) TearDown() {
 // Register the pods for this stream.
 //
 // Set up any watches we need.
-func (s *PodSource) handleReconcileRequest(ctx context.Context, name types.NamespacedName, pls *PodLogStream) error {
+func (s *PodSource) handleReconcileRequest(ctx context.Context, name types.NamespacedName, pls *PodLogStream, cluster *v1alpha1.Cluster) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -92,11 +106,24 @@ func (s *PodSource) handleReconcileRequest(ctx context.Context, name types.Names
 	var err error
 	ns := pls.Spec.Namespace
 	if ns != "" {
-		pw, ok := s.watchesByNamespace[ns]
+		kCli, revision, err := s.k8sClient(pls, cluster)
+		if err != nil {
+			return err
+		}
+
+		key := podWatchKey{cluster: clusterName(pls), namespace: ns}
+		pw, ok := s.watchesByNamespace[key]
+		if ok && !timecmp.Equal(pw.revision, revision) {
+			// The cluster reconnected since the watch started, so the watch
+			// may have died (or be silently stuck) during the outage.
+			pw.cancel()
+			ok = false
+		}
+
 		if !ok {
 			ctx, cancel := context.WithCancel(ctx)
-			pw = &podWatch{ctx: ctx, cancel: cancel, namespace: ns}
-			s.watchesByNamespace[ns] = pw
+			pw = &podWatch{ctx: ctx, cancel: cancel, kClient: kCli, namespace: ns, revision: revision}
+			s.watchesByNamespace[key] = pw
 			go s.doWatch(pw)
 		}
 
@@ -110,6 +137,35 @@ func (s *PodSource) handleReconcileRequest(ctx context.Context, name types.Names
 	return err
 }
 
+// The client for the cluster that the stream reads from, and the
+// revision of its connection.
+//
+// The default cluster falls back to the global client when it isn't
+// connected yet, because the global client talks to the same context.
+// Its revision still comes from the Cluster object (if any), so that
+// we notice when the cluster reconnects.
+func (s *PodSource) k8sClient(pls *PodLogStream, cluster *v1alpha1.Cluster) (k8s.Client, metav1.MicroTime, error) {
+	name := clusterName(pls)
+	kCli, revision, err := s.clients.GetK8sClient(types.NamespacedName{Name: name})
+	if err == nil {
+		return kCli, revision, nil
+	}
+	if name == v1alpha1.ClusterNameDefault {
+		if cluster != nil && cluster.Status.ConnectedAt != nil {
+			revision = *cluster.Status.ConnectedAt
+		}
+		return s.kClient, revision, nil
+	}
+	return nil, metav1.MicroTime{}, fmt.Errorf("cluster %s not connected: %v", name, err)
+}
+
+func clusterName(pls *PodLogStream) string {
+	if pls.Spec.Cluster == "" {
+		return v1alpha1.ClusterNameDefault
+	}
+	return pls.Spec.Cluster
+}
+
 // Process pod events and make sure they trigger a reconcile.
 func (s *PodSource) doWatch(pw *podWatch) {
 	defer func() {
@@ -127,7 +183,7 @@ func (s *PodSource) doWatch(pw *podWatch) {
 	pw.finishedAt = time.Time{}
 	pw.error = nil
 
-	podCh, err := s.kClient.WatchPods(s.ctx, k8s.Namespace(pw.namespace))
+	podCh, err := pw.kClient.WatchPods(s.ctx, k8s.Namespace(pw.namespace))
 	if err != nil {
 		pw.error = fmt.Errorf("watching pods: %v", err)
 		return
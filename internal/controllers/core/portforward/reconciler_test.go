@@ -340,6 +340,34 @@ func TestClusterChange(t *testing.T) {
 	assert.Equal(t, 8080, kCli.LastForwardPortRemotePort())
 }
 
+func TestClusterReconnect(t *testing.T) {
+	f := newPFRFixture(t)
+
+	pf := f.makeSimplePF(pfFooName, 8000, 8080)
+	f.Create(pf)
+	clusterKey := clusterNN(pf)
+
+	f.requirePortForwardStarted(pfFooName, 8000, 8080)
+	kCli := f.clients.MustK8sClient(clusterKey)
+	require.Equal(t, 1, kCli.CreatePortForwardCallCount())
+
+	// the same client comes back with a new connection revision, so the
+	// forward that was open during the outage gets restarted
+	f.clients.ReconnectK8sCluster(f.Context(), clusterKey)
+	f.MustReconcile(apis.Key(pf))
+	f.requirePortForwardStarted(pfFooName, 8000, 8080)
+	require.Equal(t, 1, len(f.r.activeForwards))
+	require.Eventually(t, func() bool {
+		return kCli.CreatePortForwardCallCount() == 2
+	}, time.Second, 10*time.Millisecond, "Port forward should have been restarted")
+
+	// no further changes once the forward is on the new revision
+	f.MustReconcile(apis.Key(pf))
+	require.Never(t, func() bool {
+		return kCli.CreatePortForwardCallCount() > 2
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
 type pfrFixture struct {
 	*fake.ControllerFixture
 	t       *testing.T
@@ -39,6 +39,7 @@ type ConfigsReloadedAction struct {
 	VersionSettings      model.VersionSettings
 	UpdateSettings       model.UpdateSettings
 	WatchSettings        model.WatchSettings
+	TiltfileArgs         []model.TiltfileArg
 
 	// A checkpoint into the logstore when Tiltfile execution started.
 	// Useful for knowing how far back in time we have to scrub secrets.
@@ -599,7 +599,7 @@ func toClusterObjects(nn types.NamespacedName, tlr *tiltfile.TiltfileLoadResult,
 		}
 	}
 
-	if tlr.HasOrchestrator(model.OrchestratorK8s) {
+	if tlr.HasOrchestrator(model.OrchestratorK8s) || tlr.ClusterProvision != nil {
 		name := v1alpha1.ClusterNameDefault
 		result[name] = &v1alpha1.Cluster{
 			ObjectMeta: metav1.ObjectMeta{
@@ -611,6 +611,21 @@ func toClusterObjects(nn types.NamespacedName, tlr *tiltfile.TiltfileLoadResult,
 					Kubernetes: defaultK8sConnection.DeepCopy(),
 				},
 				DefaultRegistry: tlr.DefaultRegistry,
+				Provision:       tlr.ClusterProvision.DeepCopy(),
+			},
+		}
+	}
+
+	for name, conn := range tlr.K8sClusters {
+		result[name] = &v1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: annotations,
+			},
+			Spec: v1alpha1.ClusterSpec{
+				Connection: &v1alpha1.ClusterConnection{
+					Kubernetes: conn.DeepCopy(),
+				},
 			},
 		}
 	}
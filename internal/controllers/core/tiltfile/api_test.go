@@ -279,6 +279,54 @@ func TestCreateClusterDefaultRegistry(t *testing.T) {
 	require.Equal(t, "fake-repo", cluster.Spec.DefaultRegistry.SingleName, "Default registry single name")
 }
 
+func TestCreateClusterProvision(t *testing.T) {
+	f := newAPIFixture(t)
+	tf := &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+	}
+	nn := apis.Key(tf)
+	tlr := &tiltfile.TiltfileLoadResult{
+		ClusterProvision: &v1alpha1.ClusterProvision{Product: v1alpha1.ClusterProvisionProductKind},
+	}
+	err := f.updateOwnedObjects(nn, tf, tlr)
+	assert.NoError(t, err)
+
+	// The cluster is created even before there's anything to deploy to it.
+	var cluster v1alpha1.Cluster
+	require.NoError(t, f.Get(types.NamespacedName{Name: "default"}, &cluster))
+	require.NotNil(t, cluster.Spec.Provision, ".Spec.Provision was nil")
+	require.Equal(t, "kind", cluster.Spec.Provision.Product)
+}
+
+func TestCreateAdditionalK8sClusters(t *testing.T) {
+	f := newAPIFixture(t)
+	tf := &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+	}
+	nn := apis.Key(tf)
+	tlr := &tiltfile.TiltfileLoadResult{
+		K8sClusters: map[string]*v1alpha1.KubernetesClusterConnection{
+			"edge": {Context: "kind-edge", Namespace: "edge-ns"},
+		},
+	}
+	err := f.updateOwnedObjects(nn, tf, tlr)
+	assert.NoError(t, err)
+
+	var cluster v1alpha1.Cluster
+	require.NoError(t, f.Get(types.NamespacedName{Name: "edge"}, &cluster))
+	require.NotNil(t, cluster.Spec.Connection.Kubernetes, ".Spec.Connection.Kubernetes was nil")
+	require.Equal(t, "kind-edge", cluster.Spec.Connection.Kubernetes.Context)
+	require.Equal(t, "edge-ns", cluster.Spec.Connection.Kubernetes.Namespace)
+
+	// Dropping the cluster from the Tiltfile deletes it.
+	err = f.updateOwnedObjects(nn, tf, &tiltfile.TiltfileLoadResult{})
+	assert.NoError(t, err)
+	err = f.Get(types.NamespacedName{Name: "edge"}, &v1alpha1.Cluster{})
+	if assert.Error(t, err) {
+		assert.True(t, apierrors.IsNotFound(err))
+	}
+}
+
 // Ensure that we emit disable-related objects/field appropriately
 func TestDisableObjects(t *testing.T) {
 	f := newAPIFixture(t)
@@ -386,6 +386,7 @@ func (r *Reconciler) handleLoaded(
 		VersionSettings:       tlr.VersionSettings,
 		UpdateSettings:        tlr.UpdateSettings,
 		WatchSettings:         tlr.WatchSettings,
+		TiltfileArgs:          tlr.TiltfileArgs,
 	})
 
 	run, ok := r.runs[nn]
@@ -93,6 +93,12 @@ func HandleConfigsReloaded(
 	// Retroactively scrub secrets
 	state.LogStore.ScrubSecretsStartingAt(newSecrets, event.CheckpointAtExecStart)
 
+	// Keep the arg definitions even if the Tiltfile failed after config.parse(),
+	// e.g., because the args were invalid.
+	if isMainTiltfile && (len(event.TiltfileArgs) > 0 || event.Err == nil) {
+		state.TiltfileArgs = event.TiltfileArgs
+	}
+
 	// Add team id if it exists, even if execution failed.
 	if isMainTiltfile && (event.TeamID != "" || event.Err == nil) {
 		state.TeamID = event.TeamID
@@ -25,6 +25,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/core/uibutton"
 	"github.com/tilt-dev/tilt/internal/controllers/core/uiresource"
 	"github.com/tilt-dev/tilt/internal/controllers/core/uisession"
+	"github.com/tilt-dev/tilt/internal/helm"
 )
 
 var controllerSet = wire.NewSet(
@@ -34,6 +35,7 @@ var controllerSet = wire.NewSet(
 	podlogstream.NewController,
 	podlogstream.NewPodSource,
 	kubernetesapply.NewReconciler,
+	helm.NewReleaseClient,
 	cluster.NewReconciler,
 
 	ProvideControllers,
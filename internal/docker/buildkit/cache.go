@@ -0,0 +1,128 @@
+/**
+Code for parsing Buildkit cache arguments adapted from Docker Buildx
+
+Adapted from
+https://github.com/docker/buildx/blob/v0.8.2/util/buildflags/cache.go
+
+
+   Copyright 2013-2017 Docker, Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package buildkit
+
+import (
+	"encoding/csv"
+	"sort"
+	"strings"
+
+	"github.com/moby/buildkit/client"
+	"github.com/pkg/errors"
+)
+
+const (
+	CacheTypeRegistry = "registry"
+	CacheTypeLocal    = "local"
+	CacheTypeInline   = "inline"
+)
+
+// ParseCacheSpecs parses cache import/export specs, in the same format
+// as `docker buildx build --cache-from` and `--cache-to`.
+//
+// A spec without any key=value fields is an image ref, for compatibility
+// with `docker build --cache-from`.
+func ParseCacheSpecs(sl []string) ([]client.CacheOptionsEntry, error) {
+	var result []client.CacheOptionsEntry
+	for _, v := range sl {
+		e, err := parseCache(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func parseCache(value string) (client.CacheOptionsEntry, error) {
+	if !strings.Contains(value, "=") {
+		return client.CacheOptionsEntry{
+			Type:  CacheTypeRegistry,
+			Attrs: map[string]string{"ref": value},
+		}, nil
+	}
+
+	csvReader := csv.NewReader(strings.NewReader(value))
+	fields, err := csvReader.Read()
+	if err != nil {
+		return client.CacheOptionsEntry{}, errors.Wrap(err, "failed to parse csv cache")
+	}
+
+	e := client.CacheOptionsEntry{Attrs: map[string]string{}}
+	for _, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return client.CacheOptionsEntry{}, errors.Errorf("invalid field '%s' must be a key=value pair", field)
+		}
+		key := strings.ToLower(parts[0])
+		value := parts[1]
+		if key == "type" {
+			e.Type = value
+		} else {
+			e.Attrs[key] = value
+		}
+	}
+
+	switch e.Type {
+	case "":
+		return client.CacheOptionsEntry{}, errors.Errorf("cache '%s' is missing a type", value)
+	case CacheTypeRegistry:
+		if e.Attrs["ref"] == "" {
+			return client.CacheOptionsEntry{}, errors.Errorf("registry cache '%s' requires ref", value)
+		}
+	case CacheTypeLocal:
+		if e.Attrs["src"] == "" && e.Attrs["dest"] == "" {
+			return client.CacheOptionsEntry{}, errors.Errorf("local cache '%s' requires src or dest", value)
+		}
+	case CacheTypeInline:
+	default:
+		return client.CacheOptionsEntry{}, errors.Errorf("unsupported cache type %q", e.Type)
+	}
+	return e, nil
+}
+
+// FormatCacheSpec is the inverse of ParseCacheSpecs. Fields that contain
+// commas or quotes are quoted, so that they survive the round trip.
+func FormatCacheSpec(e client.CacheOptionsEntry) (string, error) {
+	fields := []string{"type=" + e.Type}
+	keys := make([]string, 0, len(e.Attrs))
+	for k := range e.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields = append(fields, k+"="+e.Attrs[k])
+	}
+
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	err := w.Write(fields)
+	if err != nil {
+		return "", err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/tilt-dev/clusterid"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 )
 
 type buildkitTestCase struct {
@@ -93,6 +94,7 @@ func TestSupported(t *testing.T) {
 
 type provideEnvTestCase struct {
 	env             clusterid.Product
+	imageLoad       v1alpha1.ClusterImageLoad
 	runtime         container.Runtime
 	minikubeV       string
 	osEnv           map[string]string
@@ -349,6 +351,48 @@ func TestProvideClusterProduct(t *testing.T) {
 				Client: hostClient{Host: "unix:///var/run/docker.sock"},
 			},
 		},
+		{
+			env:     k8s.ProductOrbStack,
+			runtime: container.RuntimeDocker,
+			osEnv: map[string]string{
+				"DOCKER_HOST": "unix:///Users/tilt/.orbstack/run/docker.sock",
+			},
+			expectedCluster: Env{
+				Client:              hostClient{Host: "unix:///Users/tilt/.orbstack/run/docker.sock"},
+				BuildToKubeContexts: []string{"orbstack-me"},
+			},
+			expectedLocal: Env{
+				Client:              hostClient{Host: "unix:///Users/tilt/.orbstack/run/docker.sock"},
+				BuildToKubeContexts: []string{"orbstack-me"},
+			},
+		},
+		{
+			// The user declared that an unknown cluster shares the local Docker daemon.
+			env:       clusterid.ProductUnknown,
+			imageLoad: v1alpha1.ClusterImageLoadDocker,
+			runtime:   container.RuntimeContainerd,
+			expectedCluster: Env{
+				Client:              hostClient{Host: "unix:///var/run/docker.sock"},
+				BuildToKubeContexts: []string{"unknown-me"},
+			},
+			expectedLocal: Env{
+				Client:              hostClient{Host: "unix:///var/run/docker.sock"},
+				BuildToKubeContexts: []string{"unknown-me"},
+			},
+		},
+		{
+			// The user declared that images should go through a registry,
+			// even though the cluster shares the local Docker daemon.
+			env:       clusterid.ProductDockerDesktop,
+			imageLoad: v1alpha1.ClusterImageLoadRegistry,
+			runtime:   container.RuntimeDocker,
+			expectedCluster: Env{
+				Client: hostClient{Host: "unix:///var/run/docker.sock"},
+			},
+			expectedLocal: Env{
+				Client: hostClient{Host: "unix:///var/run/docker.sock"},
+			},
+		},
 	}
 
 	for i, c := range cases {
@@ -372,10 +416,12 @@ func TestProvideClusterProduct(t *testing.T) {
 
 			mkClient := k8s.FakeMinikube{DockerEnvMap: c.mkEnv, FakeVersion: minikubeV}
 			kubeContext := k8s.KubeContext(fmt.Sprintf("%s-me", c.env))
-			cluster := ProvideClusterEnv(context.Background(), fakeClientCreator{}, kubeContext, c.env, c.runtime, mkClient)
+			profile := k8s.ProfileForProduct(c.env)
+			profile.ImageLoad = c.imageLoad
+			cluster := ProvideClusterEnv(context.Background(), fakeClientCreator{}, kubeContext, profile, c.runtime, mkClient)
 			assert.Equal(t, c.expectedCluster, Env(cluster))
 
-			local := ProvideLocalEnv(context.Background(), fakeClientCreator{}, kubeContext, c.env, cluster)
+			local := ProvideLocalEnv(context.Background(), fakeClientCreator{}, kubeContext, profile, cluster)
 			assert.Equal(t, c.expectedLocal, Env(local))
 		})
 	}
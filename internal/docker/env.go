@@ -21,6 +21,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
@@ -138,7 +139,7 @@ func ProvideLocalEnv(
 	ctx context.Context,
 	creator ClientCreator,
 	kubeContext k8s.KubeContext,
-	product clusterid.Product,
+	profile k8s.ClusterProfile,
 	clusterEnv ClusterEnv,
 ) LocalEnv {
 	result := Env{}
@@ -159,7 +160,7 @@ func ProvideLocalEnv(
 	//  kubecontext twice - the logic above should already have copied it
 	// 	from the cluster env (this is harmless though because
 	// 	Env::WillBuildToKubeContext still works fine)
-	if product == clusterid.ProductDockerDesktop && isDefaultHost(result) {
+	if profile.Product == clusterid.ProductDockerDesktop && profile.ImageLoad == "" && isDefaultHost(result) {
 		result.BuildToKubeContexts = append(result.BuildToKubeContexts, string(kubeContext))
 	}
 
@@ -170,10 +171,12 @@ func ProvideClusterEnv(
 	ctx context.Context,
 	creator ClientCreator,
 	kubeContext k8s.KubeContext,
-	product clusterid.Product,
+	profile k8s.ClusterProfile,
 	runtime container.Runtime,
 	minikubeClient k8s.MinikubeClient,
 ) ClusterEnv {
+	product := profile.Product
+
 	// start with an empty env, then populate with cluster-specific values if
 	// available, and then potentially throw that all away if there are OS env
 	// vars overriding those
@@ -261,8 +264,20 @@ func ProvideClusterEnv(
 	// currently, we handle this by inspecting the Docker + K8s configs to see
 	// if they're matched up, but with the exception of microk8s (handled above),
 	// we don't override the environmental Docker config
-	if runtime == container.RuntimeDocker && willBuildToKubeContext(product, kubeContext, env) {
-		env.BuildToKubeContexts = append(env.BuildToKubeContexts, string(kubeContext))
+	//
+	// users can also declare how images get into the cluster, which
+	// takes precedence over anything we detect
+	switch profile.ImageLoad {
+	case v1alpha1.ClusterImageLoadDocker:
+		if !env.WillBuildToKubeContext(kubeContext) {
+			env.BuildToKubeContexts = append(env.BuildToKubeContexts, string(kubeContext))
+		}
+	case v1alpha1.ClusterImageLoadRegistry, v1alpha1.ClusterImageLoadKind:
+		env.BuildToKubeContexts = nil
+	default:
+		if runtime == container.RuntimeDocker && willBuildToKubeContext(product, kubeContext, env) {
+			env.BuildToKubeContexts = append(env.BuildToKubeContexts, string(kubeContext))
+		}
 	}
 
 	return ClusterEnv(env)
@@ -326,6 +341,10 @@ func willBuildToKubeContext(product clusterid.Product, kubeContext k8s.KubeConte
 		// N.B. Rancher Desktop creates a Docker socket at /var/run/docker.sock
 		// (the same as Docker Desktop)
 		return isDefaultHost(env)
+	case k8s.ProductOrbStack:
+		// N.B. OrbStack creates a Docker socket at ~/.orbstack/run/docker.sock
+		// and (by default) links /var/run/docker.sock to it
+		return isDefaultHost(env) || strings.HasSuffix(env.DaemonHost(), "/.orbstack/run/docker.sock")
 	case clusterid.ProductColima:
 		if _, host, ok := strings.Cut(env.DaemonHost(), "unix://"); ok {
 			// Socket is stored in a directory named `.colima[-$profile]`
@@ -1,22 +1,29 @@
 package dockercompose
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
-	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/compose-spec/compose-go/loader"
-	"golang.org/x/mod/semver"
+	"github.com/docker/distribution/reference"
+	dtypes "github.com/docker/docker/api/types"
+	mobycontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/pkg/stdcopy"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/compose-spec/compose-go/types"
-	"github.com/pkg/errors"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/docker"
@@ -26,13 +33,9 @@ import (
 	compose "github.com/compose-spec/compose-go/cli"
 )
 
-// versionRegex handles both v1 and v2 version outputs, which have several variations.
-// (See TestParseComposeVersionOutput for various cases.)
-var versionRegex = regexp.MustCompile(`(?mi)^docker[ -]compose(?: version)?:? v?([^\s,]+),?(?: build ([a-z0-9-]+))?`)
-
 // dcProjectOptions are used when loading Docker Compose projects via the Go library.
 //
-// See also: dcLoaderOption which is used for loading projects from the CLI fallback and for tests, which should
+// See also: dcLoaderOption which is used for loading inline YAML and for tests, which should
 // be kept in sync behavior-wise.
 var dcProjectOptions = []compose.ProjectOptionsFn{
 	compose.WithResolvedPaths(true),
@@ -48,191 +51,277 @@ type DockerComposeClient interface {
 	StreamEvents(ctx context.Context, spec v1alpha1.DockerComposeProject) (<-chan string, error)
 	Project(ctx context.Context, spec v1alpha1.DockerComposeProject) (*types.Project, error)
 	ContainerID(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (container.ID, error)
-	Version(ctx context.Context) (canonicalVersion string, build string, err error)
 }
 
-type cmdDCClient struct {
-	env     docker.Env
-	mu      *sync.Mutex
-	initCmd *sync.Once
+// The parts of the Docker API that we use to manage the containers,
+// networks, and volumes of a project.
+type dockerAPI interface {
+	ContainerCreate(ctx context.Context, config *mobycontainer.Config, hostConfig *mobycontainer.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (mobycontainer.ContainerCreateCreatedBody, error)
+	ContainerInspect(ctx context.Context, containerID string) (dtypes.ContainerJSON, error)
+	ContainerList(ctx context.Context, options dtypes.ContainerListOptions) ([]dtypes.Container, error)
+	ContainerLogs(ctx context.Context, containerID string, options dtypes.ContainerLogsOptions) (io.ReadCloser, error)
+	ContainerRemove(ctx context.Context, containerID string, options dtypes.ContainerRemoveOptions) error
+	ContainerStart(ctx context.Context, containerID string, options dtypes.ContainerStartOptions) error
+	ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error
+	Events(ctx context.Context, options dtypes.EventsOptions) (<-chan events.Message, <-chan error)
+	ImageInspectWithRaw(ctx context.Context, imageID string) (dtypes.ImageInspect, []byte, error)
+	NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error
+	NetworkCreate(ctx context.Context, name string, options dtypes.NetworkCreate) (dtypes.NetworkCreateResponse, error)
+	NetworkInspect(ctx context.Context, networkID string, options dtypes.NetworkInspectOptions) (dtypes.NetworkResource, error)
+	NetworkList(ctx context.Context, options dtypes.NetworkListOptions) ([]dtypes.NetworkResource, error)
+	NetworkRemove(ctx context.Context, networkID string) error
+	VolumeCreate(ctx context.Context, options volumetypes.VolumeCreateBody) (dtypes.Volume, error)
+	VolumeInspect(ctx context.Context, volumeID string) (dtypes.Volume, error)
+}
+
+// The parts of Tilt's Docker client that we use to get images,
+// because it knows how to authenticate with registries.
+type imageClient interface {
+	ImagePull(ctx context.Context, ref reference.Named) (reference.Canonical, error)
+	ImageBuild(ctx context.Context, buildContext io.Reader, options docker.BuildOptions) (dtypes.ImageBuildResponse, error)
+}
 
-	composeCmd []string
-	version    string
-	build      string
-	err        error
+// Manages Docker Compose projects with the Docker API, the same way that
+// `docker compose` does, so that we don't need the Compose CLI.
+//
+// We label everything we create with the standard Compose labels, so
+// `docker compose ps` (and friends) work on our projects, and vice versa.
+type dcClient struct {
+	env docker.Env
+
+	// Networks and volumes are shared by all the services in a project, and
+	// the Docker API doesn't stop us from creating two with the same name.
+	// So we lock each project while we create or remove them. Containers
+	// belong to one service, so we don't lock while we change them.
+	mu           sync.Mutex
+	projectLocks map[string]*sync.Mutex
+
+	initMu sync.Mutex
+	api    dockerAPI
+	images imageClient
 }
 
-// TODO(dmiller): we might want to make this take a path to the docker-compose config so we don't
-// have to keep passing it in.
 func NewDockerComposeClient(lenv docker.LocalEnv) DockerComposeClient {
-	return &cmdDCClient{
-		env:     docker.Env(lenv),
-		mu:      &sync.Mutex{},
-		initCmd: &sync.Once{},
-	}
+	return &dcClient{env: docker.Env(lenv)}
 }
 
-func (c *cmdDCClient) projectArgs(p v1alpha1.DockerComposeProject) []string {
-	result := []string{}
+// Connects to Docker the first time we need it, so that loading a project
+// never needs a Docker server.
+//
+// If we can't connect, we try again on the next call, so that a cancelled
+// context or a Docker server that's still starting doesn't break the client
+// for good.
+func (c *dcClient) init(ctx context.Context) error {
+	c.initMu.Lock()
+	defer c.initMu.Unlock()
+	if c.api != nil {
+		return nil
+	}
 
-	if p.Name != "" {
-		result = append(result, "--project-name", p.Name)
+	if c.env.Error != nil {
+		return c.env.Error
+	}
+	if c.env.Client == nil {
+		return fmt.Errorf("no Docker server configured")
 	}
 
-	if p.ProjectPath != "" {
-		result = append(result, "--project-directory", p.ProjectPath)
+	dCli := docker.NewDockerClient(ctx, c.env)
+	err := dCli.CheckConnected()
+	if err != nil {
+		return err
+	}
+	cli, ok := dCli.(*docker.Cli)
+	if !ok {
+		return fmt.Errorf("unexpected Docker client: %T", dCli)
 	}
+	c.api = cli.Client
+	c.images = dCli
+	return nil
+}
 
-	if p.EnvFile != "" {
-		result = append(result, "--env-file", p.EnvFile)
+func (c *dcClient) Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, shouldBuild bool, stdout, stderr io.Writer) error {
+	err := c.init(ctx)
+	if err != nil {
+		return err
 	}
 
-	if p.YAML != "" {
-		result = append(result, "-f", "-")
+	proj, err := c.Project(ctx, spec.Project)
+	if err != nil {
+		return err
+	}
+	svc, err := serviceForUp(proj, spec.Project, spec.Service)
+	if err != nil {
+		return err
 	}
 
-	for _, cp := range p.ConfigPaths {
-		result = append(result, "-f", cp)
+	image := serviceImage(proj.Name, svc)
+	if shouldBuild && svc.Build != nil {
+		err = c.buildImage(ctx, image, svc, stdout)
+	} else {
+		err = c.ensureImage(ctx, image, svc, stdout)
+	}
+	if err != nil {
+		return err
 	}
 
-	return result
-}
+	err = c.waitForDependencies(ctx, proj, svc, stdout)
+	if err != nil {
+		return err
+	}
 
-func (c *cmdDCClient) Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, shouldBuild bool, stdout, stderr io.Writer) error {
-	genArgs := c.projectArgs(spec.Project)
-	// TODO(milas): this causes docker-compose to output a truly excessive amount of logging; it might
-	// 	make sense to hide it behind a special environment variable instead or something
-	if logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
-		genArgs = append(genArgs, "--verbose")
-	}
-
-	if shouldBuild {
-		var buildArgs = append([]string{}, genArgs...)
-		buildArgs = append(buildArgs, "build", spec.Service)
-		cmd := c.dcCommand(ctx, buildArgs)
-		cmd.Stdin = strings.NewReader(spec.Project.YAML)
-		cmd.Stdout = stdout
-		cmd.Stderr = stderr
-		err := cmd.Run()
-		if err != nil {
-			return FormatError(cmd, nil, err)
-		}
+	err = c.ensureNetworksAndVolumes(ctx, proj, svc, stdout)
+	if err != nil {
+		return err
 	}
+	return c.ensureContainers(ctx, proj, svc, image, serviceReplicas(spec, svc), stdout)
+}
 
-	// docker-compose up is not thread-safe, because network operations are non-atomic. See:
-	// https://github.com/tilt-dev/tilt/issues/2817
-	//
-	// docker-compose build can run in parallel fine, so we only want the mutex on the 'up' call.
-	//
-	// TODO(nick): It might make sense to use a CondVar so that we can log a message
-	// when we're waiting on another build...
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	runArgs := append([]string{}, genArgs...)
-	runArgs = append(runArgs, "up", "--no-deps")
-	// Omit --no-build for now to get v2 working.
-	// https://github.com/docker/compose/issues/8785
-	if semver.Major(c.version) != "v2" {
-		runArgs = append(runArgs, "--no-build")
-	}
-	runArgs = append(runArgs, "-d", spec.Service)
-	cmd := c.dcCommand(ctx, runArgs)
-	cmd.Stdin = strings.NewReader(spec.Project.YAML)
-	cmd.Stdout = stdout
-	cmd.Stderr = stderr
-
-	return FormatError(cmd, nil, cmd.Run())
+func (c *dcClient) ensureNetworksAndVolumes(ctx context.Context, proj *types.Project, svc types.ServiceConfig, stdout io.Writer) error {
+	unlock := c.lockProject(proj.Name)
+	defer unlock()
+
+	err := c.ensureNetworks(ctx, proj, svc, stdout)
+	if err != nil {
+		return err
+	}
+	return c.ensureVolumes(ctx, proj, svc, stdout)
 }
 
-func (c *cmdDCClient) Down(ctx context.Context, p v1alpha1.DockerComposeProject, stdout, stderr io.Writer) error {
-	// To be safe, we try not to run two docker-compose downs in parallel,
-	// because we know docker-compose up is not thread-safe.
+// Locks the project's networks and volumes. Returns a func to unlock them.
+func (c *dcClient) lockProject(name string) func() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	args := c.projectArgs(p)
-	if logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
-		args = append(args, "--verbose")
+	if c.projectLocks == nil {
+		c.projectLocks = make(map[string]*sync.Mutex)
 	}
+	l, ok := c.projectLocks[name]
+	if !ok {
+		l = &sync.Mutex{}
+		c.projectLocks[name] = l
+	}
+	c.mu.Unlock()
 
-	args = append(args, "down")
-	cmd := c.dcCommand(ctx, args)
-	cmd.Stdin = strings.NewReader(p.YAML)
-	cmd.Stdout = stdout
-	cmd.Stderr = stderr
+	l.Lock()
+	return l.Unlock
+}
 
-	err := cmd.Run()
-	if err != nil {
-		return FormatError(cmd, nil, err)
+// Finds the service to start, and disables services that aren't
+// in an active profile, like `docker compose up <service>`.
+func serviceForUp(proj *types.Project, spec v1alpha1.DockerComposeProject, name string) (types.ServiceConfig, error) {
+	var svc types.ServiceConfig
+	found := false
+	for _, s := range proj.AllServices() {
+		if s.Name == name {
+			svc = s
+			found = true
+			break
+		}
+	}
+	if !found {
+		return types.ServiceConfig{}, fmt.Errorf("no such service: %s", name)
 	}
 
-	return nil
+	// Naming a service enables its profiles, like `docker compose up SERVICE`.
+	profiles := append(activeProfiles(proj, spec), svc.Profiles...)
+	proj.Services = proj.AllServices()
+	proj.DisabledServices = nil
+	proj.ApplyProfiles(profiles)
+	return svc, nil
 }
 
-func (c *cmdDCClient) Rm(ctx context.Context, specs []v1alpha1.DockerComposeServiceSpec, stdout, stderr io.Writer) error {
-	if len(specs) == 0 {
-		return nil
+// The profiles in COMPOSE_PROFILES, like the Compose CLI,
+// and the profiles enabled in the spec.
+func activeProfiles(proj *types.Project, spec v1alpha1.DockerComposeProject) []string {
+	var result []string
+	for _, p := range strings.Split(proj.Environment["COMPOSE_PROFILES"], ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			result = append(result, p)
+		}
 	}
+	return append(result, spec.Profiles...)
+}
 
-	// To be safe, we try not to run two docker-compose downs in parallel,
-	// because we know docker-compose up is not thread-safe.
-	c.mu.Lock()
-	defer c.mu.Unlock()
+func (c *dcClient) Down(ctx context.Context, p v1alpha1.DockerComposeProject, stdout, stderr io.Writer) error {
+	err := c.init(ctx)
+	if err != nil {
+		return err
+	}
 
-	p := specs[0].Project
-	args := c.projectArgs(p)
-	if logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
-		args = append(args, "--verbose")
+	// We find everything by its labels, so we can clean up a project
+	// even if its config has changed.
+	projectName := p.Name
+	if projectName == "" {
+		proj, err := c.Project(ctx, p)
+		if err != nil {
+			return err
+		}
+		projectName = proj.Name
 	}
 
-	var serviceNames []string
-	for _, s := range specs {
-		serviceNames = append(serviceNames, s.Service)
+	containers, err := c.api.ContainerList(ctx, dtypes.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(projectFilter(projectName)),
+	})
+	if err != nil {
+		return fmt.Errorf("listing containers: %v", err)
+	}
+	for _, ctr := range containers {
+		err := c.removeContainer(ctx, ctr, stdout)
+		if err != nil {
+			return err
+		}
 	}
 
-	// `docker-compose rm` does not support a `--timeout` option, so it possibly defaults to 10,
-	// like `docker-compose stop` or `docker-compose down`.
-	// If it turns out this command's timeout is too long, we might want to change this to first
-	// call `docker-compose stop --timeout $NUM`, to do the presumably slow part under a smaller
-	// timeout.
-	args = append(args, []string{"rm", "--stop", "--force"}...)
-	args = append(args, serviceNames...)
-	cmd := c.dcCommand(ctx, args)
-	cmd.Stdin = strings.NewReader(p.YAML)
-	cmd.Stdout = stdout
-	cmd.Stderr = stderr
+	unlock := c.lockProject(projectName)
+	defer unlock()
 
-	err := cmd.Run()
+	networks, err := c.api.NetworkList(ctx, dtypes.NetworkListOptions{
+		Filters: filters.NewArgs(projectFilter(projectName)),
+	})
 	if err != nil {
-		return FormatError(cmd, nil, err)
+		return fmt.Errorf("listing networks: %v", err)
+	}
+	for _, n := range networks {
+		err := c.api.NetworkRemove(ctx, n.ID)
+		if err != nil {
+			return fmt.Errorf("removing network %s: %v", n.Name, err)
+		}
+		_, _ = fmt.Fprintf(stdout, "Network %s Removed\n", n.Name)
 	}
-
 	return nil
 }
 
-func (c *cmdDCClient) StreamLogs(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) io.ReadCloser {
-	args := c.projectArgs(spec.Project)
-
-	r, w := io.Pipe()
+func (c *dcClient) Rm(ctx context.Context, specs []v1alpha1.DockerComposeServiceSpec, stdout, stderr io.Writer) error {
+	if len(specs) == 0 {
+		return nil
+	}
 
-	// NOTE: we can't practically remove "--no-color" due to the way that Docker Compose formats colorful lines; it
-	// 		 will wrap the entire line (including the \n) with the color codes, so you end up with something like:
-	//			\u001b[36mmyproject_my-container_1 exited with code 0\n\u001b[0m
-	// 		 where the ANSI reset (\u001b[0m) is _AFTER_ the \n, which doesn't play nice with our log segment logic
-	// 		 under some conditions - adding a final \n after stdout is closed would probably be sufficient given the
-	// 		 current pattern of how Compose colorizes stuff, but it's really not worth the headache to find out
-	args = append(args, "logs", "--no-color", "--no-log-prefix", "--timestamps", "--follow", spec.Service)
-	cmd := c.dcCommand(ctx, args)
-	cmd.Stdin = strings.NewReader(spec.Project.YAML)
-	cmd.Stdout = w
+	err := c.init(ctx)
+	if err != nil {
+		return err
+	}
 
-	errBuf := bytes.Buffer{}
-	cmd.Stderr = &errBuf
+	for _, spec := range specs {
+		containers, err := c.serviceContainers(ctx, spec)
+		if err != nil {
+			return err
+		}
+		for _, ctr := range containers {
+			err := c.removeContainer(ctx, ctr, stdout)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
+func (c *dcClient) StreamLogs(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) io.ReadCloser {
+	r, w := io.Pipe()
 	go func() {
-		if cmdErr := cmd.Run(); cmdErr != nil {
-			_ = w.CloseWithError(fmt.Errorf("cmd `docker-compose %s` exited with error: \"%v\" (stderr: %s)",
-				strings.Join(args, " "), cmdErr, errBuf.String()))
+		err := c.streamLogs(ctx, spec, w)
+		if err != nil {
+			_ = w.CloseWithError(fmt.Errorf("streaming logs for %s: %v", spec.Service, err))
 		} else {
 			_ = w.Close()
 		}
@@ -240,251 +329,247 @@ func (c *cmdDCClient) StreamLogs(ctx context.Context, spec v1alpha1.DockerCompos
 	return r
 }
 
-func (c *cmdDCClient) StreamEvents(ctx context.Context, p v1alpha1.DockerComposeProject) (<-chan string, error) {
-	ch := make(chan string)
-
-	args := c.projectArgs(p)
-	args = append(args, "events", "--json")
-	cmd := c.dcCommand(ctx, args)
-	cmd.Stdin = strings.NewReader(p.YAML)
-	stdout, err := cmd.StdoutPipe()
+// Follows the logs of the service's containers, with timestamps, until they stop.
+func (c *dcClient) streamLogs(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, w io.Writer) error {
+	err := c.init(ctx)
 	if err != nil {
-		return ch, errors.Wrap(err, "making stdout pipe for `docker-compose events`")
+		return err
 	}
 
-	err = cmd.Start()
+	containers, err := c.serviceContainers(ctx, spec)
 	if err != nil {
-		return ch, errors.Wrapf(err, "`docker-compose %s`",
-			strings.Join(args, " "))
+		return err
+	}
+	if len(containers) == 0 {
+		// Nothing to follow. We'll be called again when a container starts.
+		return nil
+	}
+	if len(containers) == 1 {
+		return c.streamContainerLogs(ctx, containers[0].ID, w)
 	}
-	go func() {
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			ch <- scanner.Text()
-		}
-
-		if err := scanner.Err(); err != nil {
-			logger.Get(ctx).Infof("[DOCKER-COMPOSE WATCHER] scanning `events` output: %v", err)
-		}
-
-		err = cmd.Wait()
-		if err != nil {
-			logger.Get(ctx).Infof("[DOCKER-COMPOSE WATCHER] exited with error: %v", err)
-		}
-	}()
 
-	return ch, nil
+	// Interleave the replicas' logs a line at a time, so that
+	// every line still starts with its timestamp.
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	for _, ctr := range containers {
+		id := ctr.ID
+		g.Go(func() error {
+			lw := &lineWriter{mu: &mu, w: w}
+			err := c.streamContainerLogs(ctx, id, lw)
+			lw.flush()
+			return err
+		})
+	}
+	return g.Wait()
 }
 
-func (c *cmdDCClient) Project(ctx context.Context, spec v1alpha1.DockerComposeProject) (*types.Project, error) {
-	var proj *types.Project
-	var err error
+func (c *dcClient) streamContainerLogs(ctx context.Context, id string, w io.Writer) error {
+	containerJSON, err := c.api.ContainerInspect(ctx, id)
+	if err != nil {
+		return err
+	}
 
-	// First, use compose-go to natively load the project.
-	if len(spec.ConfigPaths) > 0 {
-		parsed, err := c.loadProjectNative(spec)
-		if err == nil {
-			proj = parsed
-		}
+	logs, err := c.api.ContainerLogs(ctx, containerJSON.ID, dtypes.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+	})
+	if err != nil {
+		return err
 	}
+	defer func() {
+		_ = logs.Close()
+	}()
 
-	// HACK(milas): compose-go has known regressions with resolving variables during YAML loading
-	// 	if it fails, attempt to fallback to using the CLI to resolve the YAML and then parse
-	// 	it with compose-go
-	// 	see https://github.com/tilt-dev/tilt/issues/4795
-	if proj == nil {
-		proj, err = c.loadProjectCLI(ctx, spec)
-		if err != nil {
-			return nil, err
-		}
+	// Without a TTY, stdout and stderr are multiplexed on the same stream.
+	if containerJSON.Config != nil && containerJSON.Config.Tty {
+		_, err = io.Copy(w, logs)
+	} else {
+		_, err = stdcopy.StdCopy(w, w, logs)
 	}
+	return err
+}
 
-	return proj, nil
+// Writes whole lines to a writer that's shared with other lineWriters.
+type lineWriter struct {
+	mu  *sync.Mutex
+	w   io.Writer
+	buf []byte
 }
 
-func (c *cmdDCClient) ContainerID(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (container.ID, error) {
-	id, err := c.dcOutput(ctx, spec.Project, "ps", "-q", spec.Service)
-	if err != nil {
-		return container.ID(""), err
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.buf = append(lw.buf, p...)
+	i := bytes.LastIndexByte(lw.buf, '\n')
+	if i == -1 {
+		return len(p), nil
 	}
 
-	return container.ID(id), nil
-}
-
-// Version returns the parsed output of `docker compose version`, the canonical version and build (if present).
-//
-// NOTE: The version subcommand was added in Docker Compose v1.4.0 (released 2015-08-04), so this won't work for
-//
-//	truly ancient versions, but handles both v1 and v2.
-func (c *cmdDCClient) Version(ctx context.Context) (string, string, error) {
-	c.initDcCommand()
-	return c.version, c.build, c.err
+	lw.mu.Lock()
+	_, err := lw.w.Write(lw.buf[:i+1])
+	lw.mu.Unlock()
+	lw.buf = append([]byte(nil), lw.buf[i+1:]...)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
 }
 
-func composeProjectOptions(modelProj v1alpha1.DockerComposeProject) (*compose.ProjectOptions, error) {
-	// NOTE: take care to keep behavior in sync with loadProjectCLI()
-	allProjectOptions := append(dcProjectOptions,
-		compose.WithWorkingDirectory(modelProj.ProjectPath),
-		compose.WithName(modelProj.Name))
-	if modelProj.EnvFile != "" {
-		allProjectOptions = append(allProjectOptions, compose.WithEnvFile(modelProj.EnvFile))
+// Writes the last line, if it didn't end with a newline.
+func (lw *lineWriter) flush() {
+	if len(lw.buf) == 0 {
+		return
 	}
-	allProjectOptions = append(allProjectOptions, compose.WithDotEnv)
-	return compose.NewProjectOptions(modelProj.ConfigPaths, allProjectOptions...)
+	lw.mu.Lock()
+	_, _ = lw.w.Write(lw.buf)
+	lw.mu.Unlock()
+	lw.buf = nil
 }
 
-func (c *cmdDCClient) loadProjectNative(modelProj v1alpha1.DockerComposeProject) (*types.Project, error) {
-	opts, err := composeProjectOptions(modelProj)
+func (c *dcClient) StreamEvents(ctx context.Context, p v1alpha1.DockerComposeProject) (<-chan string, error) {
+	err := c.init(ctx)
 	if err != nil {
 		return nil, err
 	}
-	proj, err := compose.ProjectFromOptions(opts)
-	if err != nil {
-		return nil, err
+
+	projectName := p.Name
+	if projectName == "" {
+		proj, err := c.Project(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		projectName = proj.Name
 	}
-	return proj, nil
+
+	messages, errs := c.api.Events(ctx, dtypes.EventsOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", events.ContainerEventType),
+			projectFilter(projectName)),
+	})
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case msg := <-messages:
+				evt, err := json.Marshal(eventFromMessage(msg))
+				if err != nil {
+					continue
+				}
+				select {
+				case ch <- string(evt):
+				case <-ctx.Done():
+					return
+				}
+			case err := <-errs:
+				if err != nil && ctx.Err() == nil {
+					logger.Get(ctx).Debugf("[dcwatch] streaming events: %v", err)
+				}
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
 }
 
-func (c *cmdDCClient) loadProjectCLI(ctx context.Context, proj v1alpha1.DockerComposeProject) (*types.Project, error) {
-	resolvedYAML, err := c.dcOutput(ctx, proj, "config")
-	if err != nil {
-		return nil, err
+// Converts a Docker event to the format of `docker compose events --json`.
+func eventFromMessage(msg events.Message) Event {
+	attrs := msg.Actor.Attributes
+	return Event{
+		Time:    time.Unix(0, msg.TimeNano).Format(time.RFC3339Nano),
+		Type:    stringToType[msg.Type],
+		Action:  msg.Action,
+		ID:      msg.Actor.ID,
+		Service: attrs[serviceLabel],
+		Attributes: Attributes{
+			Name:  attrs["name"],
+			Image: attrs["image"],
+		},
 	}
+}
 
-	// docker-compose is very inconsistent about whether it fully resolves paths or not via CLI, both between
-	// v1 and v2 as well as even different releases within v2, so set the workdir and force the loader to resolve
-	// any relative paths
-	return loader.Load(types.ConfigDetails{
-		WorkingDir: proj.ProjectPath,
-		ConfigFiles: []types.ConfigFile{
-			{
-				Content: []byte(resolvedYAML),
-			},
-		},
-		// no environment specified because the CLI call will already have resolved all variables
-	}, dcLoaderOption(proj.Name))
+func (c *dcClient) Project(ctx context.Context, spec v1alpha1.DockerComposeProject) (*types.Project, error) {
+	return LoadProject(spec)
 }
 
-// dcLoaderOption is used when loading Docker Compose projects via the CLI and fallback and for tests.
+// Reads the compose project from its config files (and inline YAML).
 //
-// See also: dcProjectOptions which is used for loading projects from the Go library, which should
-// be kept in sync behavior-wise.
-func dcLoaderOption(name string) func(opts *loader.Options) {
-	return func(opts *loader.Options) {
-		opts.SetProjectName(name, true)
-		opts.ResolvePaths = true
-		opts.SkipNormalization = false
-		opts.SkipInterpolation = false
+// Doesn't talk to Docker.
+func LoadProject(spec v1alpha1.DockerComposeProject) (*types.Project, error) {
+	opts, err := composeProjectOptions(spec)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func dcExecutableVersion(environ []string) ([]string, string, string, error) {
-	execVersion := func(names []string) (string, string, error) {
-		args := append(names, "version")
-		cmd := exec.Command(args[0], args[1:]...)
-		cmd.Env = append(os.Environ(), environ...)
-		stdout, err := cmd.Output()
-		if err != nil {
-			return "", "", FormatError(cmd, stdout, err)
+	var proj *types.Project
+	if spec.YAML == "" {
+		proj, err = compose.ProjectFromOptions(opts)
+	} else {
+		// Inline YAML comes first, like `docker compose -f - -f ...`.
+		configFiles := []types.ConfigFile{{Content: []byte(spec.YAML)}}
+		for _, path := range spec.ConfigPaths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			configFiles = append(configFiles, types.ConfigFile{Filename: path, Content: content})
 		}
-		ver, build, err := parseComposeVersionOutput(stdout)
-		return ver, build, err
-	}
-
-	var cmd []string
-	if cmdstr := os.Getenv("TILT_DOCKER_COMPOSE_CMD"); cmdstr != "" {
-		cmd = []string{cmdstr}
-		ver, build, err := execVersion(cmd)
-		return cmd, ver, build, err
+		proj, err = loader.Load(types.ConfigDetails{
+			WorkingDir:  opts.WorkingDir,
+			ConfigFiles: configFiles,
+			Environment: opts.Environment,
+		}, dcLoaderOption(spec.Name))
 	}
-
-	cmd = []string{"docker", "compose"}
-	ver, build, err := execVersion(cmd)
 	if err != nil {
-		cmd = []string{"docker-compose"}
-		ver, build, err = execVersion(cmd)
+		return nil, err
 	}
 
-	return cmd, ver, build, err
-}
-
-func (c *cmdDCClient) initDcCommand() {
-	c.initCmd.Do(func() {
-		cmd, version, build, err := dcExecutableVersion(c.env.AsEnviron())
-		c.composeCmd = cmd
-		c.version = version
-		c.build = build
-		c.err = err
-	})
+	// Services that aren't in an enabled profile are in DisabledServices.
+	proj.ApplyProfiles(activeProfiles(proj, spec))
+	return proj, nil
 }
 
-func (c *cmdDCClient) dcCommand(ctx context.Context, args []string) *exec.Cmd {
-	c.initDcCommand()
-	composeCmd := c.composeCmd[0]
-	composeArgs := c.composeCmd[1:]
-	if len(composeArgs) > 0 {
-		args = append(composeArgs, args...)
+func (c *dcClient) ContainerID(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (container.ID, error) {
+	err := c.init(ctx)
+	if err != nil {
+		return "", err
 	}
-	cmd := exec.CommandContext(ctx, composeCmd, args...)
-	cmd.Env = append(os.Environ(), c.env.AsEnviron()...)
-	return cmd
-}
-
-func (c *cmdDCClient) dcOutput(ctx context.Context, p v1alpha1.DockerComposeProject, args ...string) (string, error) {
 
-	tempArgs := c.projectArgs(p)
-	args = append(tempArgs, args...)
-	cmd := c.dcCommand(ctx, args)
-	cmd.Stdin = strings.NewReader(p.YAML)
-
-	output, err := cmd.Output()
+	containers, err := c.serviceContainers(ctx, spec)
 	if err != nil {
-		errorMessage := fmt.Sprintf("command %q failed.\nerror: %v\nstdout: %q", cmd.Args, err, string(output))
-		if err, ok := err.(*exec.ExitError); ok {
-			errorMessage += fmt.Sprintf("\nstderr: '%v'", string(err.Stderr))
-		}
-		err = fmt.Errorf("%s", errorMessage)
+		return "", err
 	}
-	return strings.TrimSpace(string(output)), err
+	if len(containers) == 0 {
+		return "", fmt.Errorf("no container found for service %s", spec.Service)
+	}
+	return container.ID(firstReplica(containers).ID), nil
 }
 
-// parseComposeVersionOutput parses the raw output of `docker-compose version` for both v1.x + v2.x Compose
-// and returns the canonical semver + build (might be blank) or an error.
-func parseComposeVersionOutput(stdout []byte) (string, string, error) {
-	// match 0: raw output
-	// match 1: version w/o leading v (required)
-	// match 2: build (optional)
-	m := versionRegex.FindSubmatch(bytes.TrimSpace(stdout))
-	if len(m) < 2 {
-		return "", "", fmt.Errorf("could not parse version from output: %q", string(stdout))
-	}
-	rawVersion := "v" + string(m[1])
-	canonicalVersion := semver.Canonical(rawVersion)
-	if canonicalVersion == "" {
-		return "", "", fmt.Errorf("invalid version: %q", rawVersion)
-	}
-	build := semver.Build(rawVersion)
-	if build != "" {
-		// prefer semver build if present, but strip off the leading `+`
-		// (currently, Docker Compose has not made use of this, preferring to list the build independently if at all)
-		build = strings.TrimPrefix(build, "+")
-	} else if len(m) > 2 {
-		// otherwise, fall back to regex match if possible
-		build = string(m[2])
-	}
-	return canonicalVersion, build, nil
+func composeProjectOptions(modelProj v1alpha1.DockerComposeProject) (*compose.ProjectOptions, error) {
+	// NOTE: take care to keep behavior in sync with dcLoaderOption()
+	allProjectOptions := append(dcProjectOptions,
+		compose.WithWorkingDirectory(modelProj.ProjectPath),
+		compose.WithName(modelProj.Name))
+	if modelProj.EnvFile != "" {
+		allProjectOptions = append(allProjectOptions, compose.WithEnvFile(modelProj.EnvFile))
+	}
+	allProjectOptions = append(allProjectOptions, compose.WithDotEnv)
+	return compose.NewProjectOptions(modelProj.ConfigPaths, allProjectOptions...)
 }
 
-func FormatError(cmd *exec.Cmd, stdout []byte, err error) error {
-	if err == nil {
-		return nil
-	}
-	errorMessage := fmt.Sprintf("command %q failed.\nerror: %v\n", cmd.Args, err)
-	if len(stdout) > 0 {
-		errorMessage += fmt.Sprintf("\nstdout: '%v'", string(stdout))
-	}
-	if err, ok := err.(*exec.ExitError); ok && len(err.Stderr) > 0 {
-		errorMessage += fmt.Sprintf("\nstderr: '%v'", string(err.Stderr))
+// dcLoaderOption is used when loading Docker Compose projects from inline YAML and for tests.
+//
+// See also: dcProjectOptions which is used for loading projects from the Go library, which should
+// be kept in sync behavior-wise.
+func dcLoaderOption(name string) func(opts *loader.Options) {
+	return func(opts *loader.Options) {
+		opts.SetProjectName(name, true)
+		opts.ResolvePaths = true
+		opts.SkipNormalization = false
+		opts.SkipInterpolation = false
 	}
-	return fmt.Errorf("%s", errorMessage)
 }
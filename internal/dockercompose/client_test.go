@@ -2,6 +2,7 @@ package dockercompose
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/compose-spec/compose-go/types"
@@ -15,17 +16,8 @@ import (
 )
 
 // TestVariableInterpolation both ensures Tilt properly passes environment to Compose for interpolation
-// as well as catches potential regressions in the upstream YAML parsing from compose-go (currently, a
-// fallback mechanism relies on the user having the v1 (Python) docker-compose CLI installed to work
-// around bugs in the compose-go library (also used by the v2 CLI, and is susceptible to the same
-// issues)
+// as well as catches potential regressions in the upstream YAML parsing from compose-go.
 func TestVariableInterpolation(t *testing.T) {
-	if testing.Short() {
-		// remove this once the fallback to docker-compose CLI for YAML parse is eliminated
-		// (dependent upon compose-go upstream bugs being fixed)
-		t.Skip("skipping test that invokes docker-compose CLI in short mode")
-	}
-
 	f := newDCFixture(t)
 
 	output := `services:
@@ -68,62 +60,7 @@ func TestVariableInterpolation(t *testing.T) {
 	}
 }
 
-func TestParseComposeVersionOutput(t *testing.T) {
-	type tc struct {
-		version string
-		build   string
-		output  []byte
-	}
-	tcs := []tc{
-		{
-			version: "v1.4.0",
-			output: []byte(`docker-compose version: 1.4.0
-docker-py version: 1.3.1
-CPython version: 2.7.9
-OpenSSL version: OpenSSL 1.0.1e 11 Feb 2013
-`),
-		},
-		{
-			version: "v1.29.2",
-			build:   "5becea4c",
-			output: []byte(`docker-compose version 1.29.2, build 5becea4c
-docker-py version: 5.0.0
-CPython version: 3.7.10
-OpenSSL version: OpenSSL 1.1.0l  10 Sep 2019
-`),
-		},
-		{
-			version: "v2.0.0-rc.3",
-			output:  []byte("Docker Compose version v2.0.0-rc.3\n"),
-		},
-		{
-			version: "v2.0.0-rc.3",
-			build:   "bu1ld-info",
-			// NOTE: this format is valid semver but as of v2.0.0, has not been used by Compose but is supported
-			output: []byte("Docker Compose version v2.0.0-rc.3+bu1ld-info\n"),
-		},
-	}
-	for _, tc := range tcs {
-		name := tc.version
-		if tc.build != "" {
-			name += "+" + tc.build
-		}
-		t.Run(name, func(t *testing.T) {
-			version, build, err := parseComposeVersionOutput(tc.output)
-			require.NoError(t, err)
-			require.Equal(t, tc.version, version)
-			require.Equal(t, tc.build, build)
-		})
-	}
-}
-
 func TestLoadEnvFile(t *testing.T) {
-	if testing.Short() {
-		// remove this once the fallback to docker-compose CLI for YAML parse is eliminated
-		// (dependent upon compose-go upstream bugs being fixed)
-		t.Skip("skipping test that invokes docker-compose CLI in short mode")
-	}
-
 	f := newDCFixture(t)
 	f.tmpdir.WriteFile(".env", "COMMAND=foo")
 
@@ -136,6 +73,18 @@ func TestLoadEnvFile(t *testing.T) {
 	require.Equal(t, types.ShellCommand{"foo"}, proj.Services[0].Command)
 }
 
+func TestInitRetriesAfterError(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	c := &dcClient{env: docker.Env{Error: fmt.Errorf("docker is starting")}}
+
+	err := c.init(ctx)
+	require.EqualError(t, err, "docker is starting")
+
+	c.env.Error = nil
+	err = c.init(ctx)
+	require.EqualError(t, err, "no Docker server configured")
+}
+
 type dcFixture struct {
 	t      testing.TB
 	ctx    context.Context
@@ -0,0 +1,282 @@
+package dockercompose
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/types"
+	dtypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// The labels that Compose puts on the objects it creates.
+// https://github.com/docker/compose/blob/v2/pkg/api/labels.go
+const (
+	projectLabel      = "com.docker.compose.project"
+	serviceLabel      = "com.docker.compose.service"
+	numberLabel       = "com.docker.compose.container-number"
+	oneoffLabel       = "com.docker.compose.oneoff"
+	configHashLabel   = "com.docker.compose.config-hash"
+	imageLabel        = "com.docker.compose.image"
+	workingDirLabel   = "com.docker.compose.project.working_dir"
+	configFilesLabel  = "com.docker.compose.project.config_files"
+	dependenciesLabel = "com.docker.compose.depends_on"
+	networkLabel      = "com.docker.compose.network"
+	volumeLabel       = "com.docker.compose.volume"
+)
+
+// ImageName is the image that Compose builds for a service
+// that doesn't name one.
+//
+// See https://github.com/docker/compose/blob/7b84f2c2a538a1241dcf65f4b2828232189ef0ad/pkg/compose/create.go#L221-L227
+func ImageName(projectName, serviceName string) string {
+	return fmt.Sprintf("%s_%s", projectName, serviceName)
+}
+
+func serviceImage(projectName string, svc types.ServiceConfig) string {
+	if svc.Image != "" {
+		return svc.Image
+	}
+	return ImageName(projectName, svc.Name)
+}
+
+func containerName(projectName string, svc types.ServiceConfig, number int) string {
+	if svc.ContainerName != "" {
+		return svc.ContainerName
+	}
+	return fmt.Sprintf("%s-%s-%d", projectName, svc.Name, number)
+}
+
+// The number of containers to run for the service. The spec
+// overrides the service's deploy.replicas (or scale).
+func serviceReplicas(spec v1alpha1.DockerComposeServiceSpec, svc types.ServiceConfig) int {
+	if spec.Replicas > 0 {
+		return int(spec.Replicas)
+	}
+	if svc.Deploy != nil && svc.Deploy.Replicas != nil {
+		return int(*svc.Deploy.Replicas)
+	}
+	return 1
+}
+
+// The replica number that Compose puts on the container, or 0 if it doesn't have one.
+func containerNumber(labels map[string]string) int {
+	n, err := strconv.Atoi(labels[numberLabel])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// The container we report on for the service: the first replica,
+// or the newest container if there isn't one.
+func firstReplica(containers []dtypes.Container) dtypes.Container {
+	for _, ctr := range containers {
+		if IsFirstReplica(ctr.Labels) {
+			return ctr
+		}
+	}
+	return containers[0]
+}
+
+// Whether this is the first container of its service.
+//
+// When a service has more than one replica, Tilt reports on the first one.
+func IsFirstReplica(labels map[string]string) bool {
+	n, ok := labels[numberLabel]
+	return !ok || n == "1"
+}
+
+func projectFilter(projectName string) filters.KeyValuePair {
+	return filters.Arg("label", fmt.Sprintf("%s=%s", projectLabel, projectName))
+}
+
+// Finds the containers of a service, newest first.
+func (c *dcClient) serviceContainers(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) ([]dtypes.Container, error) {
+	projectName := spec.Project.Name
+	if projectName == "" {
+		proj, err := c.Project(ctx, spec.Project)
+		if err != nil {
+			return nil, err
+		}
+		projectName = proj.Name
+	}
+	return c.containersForService(ctx, projectName, spec.Service)
+}
+
+func (c *dcClient) containersForService(ctx context.Context, projectName, service string) ([]dtypes.Container, error) {
+	containers, err := c.api.ContainerList(ctx, dtypes.ContainerListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			projectFilter(projectName),
+			filters.Arg("label", fmt.Sprintf("%s=%s", serviceLabel, service)),
+			filters.Arg("label", fmt.Sprintf("%s=False", oneoffLabel))),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing containers for service %s: %v", service, err)
+	}
+	return containers, nil
+}
+
+// A hash of the service config and its image. When it changes,
+// we recreate the container, like `docker compose up`.
+func configHash(svc types.ServiceConfig, imageID string) (string, error) {
+	// These fields don't change the container.
+	svc.Build = nil
+	svc.PullPolicy = ""
+	svc.DependsOn = nil
+	svc.Scale = 0
+	if svc.Deploy != nil {
+		deploy := *svc.Deploy
+		deploy.Replicas = nil
+		svc.Deploy = &deploy
+	}
+
+	content, err := json.Marshal(struct {
+		Service types.ServiceConfig
+		ImageID string
+	}{svc, imageID})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
+
+// Makes sure the service has the given number of running containers with
+// the current config, like `docker compose up --scale SERVICE=N`.
+func (c *dcClient) ensureContainers(ctx context.Context, proj *types.Project, svc types.ServiceConfig, image string, replicas int, stdout io.Writer) error {
+	if replicas > 1 && svc.ContainerName != "" {
+		return fmt.Errorf("service %s: can't run %d replicas of a service with a container_name", svc.Name, replicas)
+	}
+
+	imageInspect, _, err := c.api.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		return fmt.Errorf("inspecting image %s: %v", image, err)
+	}
+	hash, err := configHash(svc, imageInspect.ID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := c.containersForService(ctx, proj.Name, svc.Name)
+	if err != nil {
+		return err
+	}
+
+	byNumber := map[int][]dtypes.Container{}
+	for _, ctr := range existing {
+		n := containerNumber(ctr.Labels)
+		if n < 1 || n > replicas {
+			// Scaled down, or left behind by something else.
+			err := c.removeContainer(ctx, ctr, stdout)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		byNumber[n] = append(byNumber[n], ctr)
+	}
+
+	for n := 1; n <= replicas; n++ {
+		err := c.ensureContainer(ctx, proj, svc, n, image, imageInspect.ID, hash, byNumber[n], stdout)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Makes sure one replica of the service is running with the current config.
+// Existing holds the containers that already have the replica's number.
+func (c *dcClient) ensureContainer(ctx context.Context, proj *types.Project, svc types.ServiceConfig, number int, image, imageID, hash string, existing []dtypes.Container, stdout io.Writer) error {
+	var current *dtypes.Container
+	recreate := false
+	for i, ctr := range existing {
+		if current == nil && ctr.Labels[configHashLabel] == hash {
+			current = &existing[i]
+			continue
+		}
+		err := c.removeContainer(ctx, ctr, io.Discard)
+		if err != nil {
+			return err
+		}
+		recreate = true
+	}
+
+	if current != nil {
+		name := displayName(current.Names)
+		if current.State == ContainerStatusRunning {
+			_, _ = fmt.Fprintf(stdout, "Container %s Running\n", name)
+			return nil
+		}
+		err := c.api.ContainerStart(ctx, current.ID, dtypes.ContainerStartOptions{})
+		if err != nil {
+			return fmt.Errorf("starting container %s: %v", name, err)
+		}
+		_, _ = fmt.Fprintf(stdout, "Container %s Started\n", name)
+		return nil
+	}
+
+	cfg, err := c.createConfig(ctx, proj, svc, number, image, imageID, hash)
+	if err != nil {
+		return fmt.Errorf("service %s: %v", svc.Name, err)
+	}
+
+	created, err := c.api.ContainerCreate(ctx, cfg.config, cfg.hostConfig, cfg.networkingConfig, cfg.platform, cfg.name)
+	if err != nil {
+		return fmt.Errorf("creating container %s: %v", cfg.name, err)
+	}
+	for _, n := range cfg.extraNetworks {
+		err := c.api.NetworkConnect(ctx, n.name, created.ID, n.endpoint)
+		if err != nil {
+			return fmt.Errorf("connecting container %s to network %s: %v", cfg.name, n.name, err)
+		}
+	}
+	if recreate {
+		_, _ = fmt.Fprintf(stdout, "Container %s Recreated\n", cfg.name)
+	} else {
+		_, _ = fmt.Fprintf(stdout, "Container %s Created\n", cfg.name)
+	}
+
+	err = c.api.ContainerStart(ctx, created.ID, dtypes.ContainerStartOptions{})
+	if err != nil {
+		return fmt.Errorf("starting container %s: %v", cfg.name, err)
+	}
+	_, _ = fmt.Fprintf(stdout, "Container %s Started\n", cfg.name)
+	return nil
+}
+
+// Stops and removes the container, like `docker compose rm --stop --force`.
+func (c *dcClient) removeContainer(ctx context.Context, ctr dtypes.Container, stdout io.Writer) error {
+	name := displayName(ctr.Names)
+	if ctr.State == ContainerStatusRunning || ctr.State == ContainerStatusRestarting || ctr.State == ContainerStatusPaused {
+		// Use the container's own stop timeout.
+		err := c.api.ContainerStop(ctx, ctr.ID, nil)
+		if err != nil {
+			return fmt.Errorf("stopping container %s: %v", name, err)
+		}
+		_, _ = fmt.Fprintf(stdout, "Container %s Stopped\n", name)
+	}
+	err := c.api.ContainerRemove(ctx, ctr.ID, dtypes.ContainerRemoveOptions{Force: true})
+	if err != nil {
+		return fmt.Errorf("removing container %s: %v", name, err)
+	}
+	_, _ = fmt.Fprintf(stdout, "Container %s Removed\n", name)
+	return nil
+}
+
+// The API's container names start with "/", but
+// Docker prints them without it.
+func displayName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(names[0], "/")
+}
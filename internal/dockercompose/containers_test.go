@@ -0,0 +1,782 @@
+package dockercompose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	dtypes "github.com/docker/docker/api/types"
+	mobycontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+const engineConfig = `services:
+  db:
+    image: postgres
+    volumes:
+    - data:/var/lib/postgresql/data
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+  app:
+    image: app
+    depends_on:
+      db:
+        condition: service_healthy
+    ports:
+    - "8080:80"
+volumes:
+  data: {}
+`
+
+func TestUpCreatesNetworkVolumeAndContainer(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	out := f.up("db")
+	assert.Contains(t, out, "Network proj_default Created")
+	assert.Contains(t, out, "Volume proj_data Created")
+	assert.Contains(t, out, "Container proj-db-1 Created")
+	assert.Contains(t, out, "Container proj-db-1 Started")
+
+	require.Len(t, f.api.containers, 1)
+	ctr := f.api.containers[0]
+	assert.Equal(t, ContainerStatusRunning, ctr.State)
+	assert.Equal(t, "proj", ctr.Labels[projectLabel])
+	assert.Equal(t, "db", ctr.Labels[serviceLabel])
+	assert.Equal(t, "False", ctr.Labels[oneoffLabel])
+	assert.Equal(t, "sha256:postgres", ctr.Labels[imageLabel])
+
+	assert.Equal(t, "proj", f.api.networks[0].Labels[projectLabel])
+	assert.Equal(t, "default", f.api.networks[0].Labels[networkLabel])
+	assert.Equal(t, "data", f.api.volumes["proj_data"].Labels[volumeLabel])
+}
+
+func TestUpSameConfigKeepsContainer(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	f.up("db")
+	out := f.up("db")
+	assert.Contains(t, out, "Container proj-db-1 Running")
+	assert.NotContains(t, out, "Created")
+	assert.Equal(t, 1, f.api.createCount)
+}
+
+func TestUpStartsStoppedContainer(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	f.up("db")
+	f.api.containers[0].State = ContainerStatusExited
+	out := f.up("db")
+	assert.Contains(t, out, "Container proj-db-1 Started")
+	assert.Equal(t, 1, f.api.createCount)
+}
+
+func TestUpRecreatesWhenImageChanges(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	f.up("db")
+	f.api.images["postgres"] = "sha256:postgres-v2"
+	out := f.up("db")
+	assert.Contains(t, out, "Container proj-db-1 Recreated")
+	require.Len(t, f.api.containers, 1)
+	assert.Equal(t, "sha256:postgres-v2", f.api.containers[0].Labels[imageLabel])
+	assert.Equal(t, 2, f.api.createCount)
+}
+
+func TestUpPullsMissingImage(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+	delete(f.api.images, "postgres")
+
+	out := f.up("db")
+	assert.Contains(t, out, "Pulling image postgres")
+	assert.Equal(t, []string{"docker.io/library/postgres"}, f.images.pulls)
+}
+
+func TestUpUnknownService(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	err := f.upErr("cache")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such service: cache")
+}
+
+func TestUpWaitsForHealthyDependency(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	f.up("db")
+	f.api.setHealth("proj-db-1", dtypes.Starting)
+	f.api.onInspect = func(n int) {
+		if n == 3 {
+			f.api.setHealth("proj-db-1", dtypes.Healthy)
+		}
+	}
+
+	out := f.up("app")
+	assert.Contains(t, out, "Waiting for dependency db (service_healthy)")
+	assert.Contains(t, out, "Container proj-app-1 Started")
+}
+
+func TestUpUnhealthyDependency(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	f.up("db")
+	f.api.setHealth("proj-db-1", dtypes.Unhealthy)
+
+	err := f.upErr("app")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency db is unhealthy")
+	assert.Equal(t, 1, f.api.createCount)
+}
+
+func TestUpDependencyWithoutContainer(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	out := f.up("app")
+	assert.Contains(t, out, "Dependency db has no container, not waiting for it")
+	assert.Contains(t, out, "Container proj-app-1 Started")
+}
+
+func TestUpScalesService(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	spec := f.spec("db")
+	spec.Replicas = 3
+	out := f.upSpec(spec)
+	assert.Contains(t, out, "Container proj-db-1 Started")
+	assert.Contains(t, out, "Container proj-db-2 Started")
+	assert.Contains(t, out, "Container proj-db-3 Started")
+	require.Len(t, f.api.containers, 3)
+
+	id, err := f.client.ContainerID(f.ctx, spec)
+	require.NoError(t, err)
+	first := f.api.containerNamed("proj-db-1")
+	assert.Equal(t, first.ID, id.String())
+	assert.Equal(t, "1", first.Labels[numberLabel])
+
+	spec.Replicas = 1
+	out = f.upSpec(spec)
+	assert.Contains(t, out, "Container proj-db-1 Running")
+	assert.Contains(t, out, "Container proj-db-2 Removed")
+	assert.Contains(t, out, "Container proj-db-3 Removed")
+	require.Len(t, f.api.containers, 1)
+	assert.Equal(t, first.ID, f.api.containers[0].ID)
+}
+
+func TestUpDeployReplicas(t *testing.T) {
+	f := newEngineFixture(t, `services:
+  worker:
+    image: app
+    deploy:
+      replicas: 2
+`)
+
+	out := f.up("worker")
+	assert.Contains(t, out, "Container proj-worker-1 Started")
+	assert.Contains(t, out, "Container proj-worker-2 Started")
+	assert.Len(t, f.api.containers, 2)
+}
+
+func TestUpReplicasWithContainerName(t *testing.T) {
+	f := newEngineFixture(t, `services:
+  worker:
+    image: app
+    container_name: worker
+`)
+
+	spec := f.spec("worker")
+	spec.Replicas = 2
+	err := f.client.Up(f.ctx, spec, false, io.Discard, io.Discard)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't run 2 replicas of a service with a container_name")
+}
+
+func TestProjectProfiles(t *testing.T) {
+	f := newEngineFixture(t, `services:
+  app:
+    image: app
+  debugger:
+    image: debugger
+    profiles: [debug]
+`)
+
+	spec := f.spec("app").Project
+	proj, err := f.client.Project(f.ctx, spec)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app"}, proj.ServiceNames())
+
+	spec.Profiles = []string{"debug"}
+	proj, err = f.client.Project(f.ctx, spec)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"app", "debugger"}, proj.ServiceNames())
+}
+
+func TestUpServiceInDisabledProfile(t *testing.T) {
+	f := newEngineFixture(t, `services:
+  debugger:
+    image: debugger
+    profiles: [debug]
+`)
+	f.api.images["debugger"] = "sha256:debugger"
+
+	// Naming a service enables its profile, like `docker compose up debugger`.
+	out := f.up("debugger")
+	assert.Contains(t, out, "Container proj-debugger-1 Started")
+}
+
+func TestDependencyConditionMet(t *testing.T) {
+	state := func(s dtypes.ContainerState) dtypes.ContainerJSON {
+		return dtypes.ContainerJSON{ContainerJSONBase: &dtypes.ContainerJSONBase{State: &s}}
+	}
+	health := func(status string) *dtypes.Health {
+		return &dtypes.Health{Status: status}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		condition string
+		state     dtypes.ContainerState
+		done      bool
+		err       string
+	}{
+		{"healthy", "service_healthy", dtypes.ContainerState{Running: true, Health: health(dtypes.Healthy)}, true, ""},
+		{"starting", "service_healthy", dtypes.ContainerState{Running: true, Health: health(dtypes.Starting)}, false, ""},
+		{"unhealthy", "service_healthy", dtypes.ContainerState{Running: true, Health: health(dtypes.Unhealthy)}, false, "dependency db is unhealthy"},
+		{"no healthcheck", "service_healthy", dtypes.ContainerState{Running: true}, false, "dependency db has no healthcheck configured"},
+		{"exited before healthy", "service_healthy", dtypes.ContainerState{ExitCode: 1, Health: health(dtypes.Starting)}, false, "dependency db exited with code 1 before it was healthy"},
+		{"completed", "service_completed_successfully", dtypes.ContainerState{Status: "exited"}, true, ""},
+		{"still running", "service_completed_successfully", dtypes.ContainerState{Running: true}, false, ""},
+		{"failed", "service_completed_successfully", dtypes.ContainerState{Status: "exited", ExitCode: 2}, false, "dependency db didn't complete successfully: exit 2"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			done, err := dependencyConditionMet("db", state(tc.state), tc.condition)
+			assert.Equal(t, tc.done, done)
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Equal(t, tc.err, err.Error())
+			}
+		})
+	}
+}
+
+func TestRm(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	f.up("db")
+	f.api.setHealth("proj-db-1", dtypes.Healthy)
+	f.up("app")
+
+	out := &bytes.Buffer{}
+	err := f.client.Rm(f.ctx, []v1alpha1.DockerComposeServiceSpec{f.spec("app")}, out, out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Container proj-app-1 Stopped")
+	assert.Contains(t, out.String(), "Container proj-app-1 Removed")
+	require.Len(t, f.api.containers, 1)
+	assert.Equal(t, "db", f.api.containers[0].Labels[serviceLabel])
+}
+
+func TestDown(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	f.up("db")
+	f.api.setHealth("proj-db-1", dtypes.Healthy)
+	f.up("app")
+
+	out := &bytes.Buffer{}
+	err := f.client.Down(f.ctx, f.spec("db").Project, out, out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Container proj-db-1 Removed")
+	assert.Contains(t, out.String(), "Container proj-app-1 Removed")
+	assert.Contains(t, out.String(), "Network proj_default Removed")
+	assert.Empty(t, f.api.containers)
+	assert.Empty(t, f.api.networks)
+
+	// Like `docker compose down`, volumes are kept.
+	assert.Contains(t, f.api.volumes, "proj_data")
+}
+
+func TestUpDoesntWaitForSlowStop(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+	f.up("db")
+
+	stopping := make(chan struct{})
+	release := make(chan struct{})
+	f.api.mu.Lock()
+	f.api.onStop = func(id string) {
+		close(stopping)
+		<-release
+	}
+	f.api.mu.Unlock()
+
+	rmDone := make(chan error)
+	go func() {
+		out := &bytes.Buffer{}
+		rmDone <- f.client.Rm(f.ctx, []v1alpha1.DockerComposeServiceSpec{f.spec("db")}, out, out)
+	}()
+	<-stopping
+
+	// A container that takes a while to stop shouldn't hold up other services.
+	other := f.spec("db")
+	other.Project.Name = "other"
+	upDone := make(chan error)
+	go func() {
+		out := &bytes.Buffer{}
+		upDone <- f.client.Up(f.ctx, other, false, out, out)
+	}()
+	select {
+	case err := <-upDone:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Up waited for another service's container to stop")
+	}
+
+	close(release)
+	require.NoError(t, <-rmDone)
+	assert.Equal(t, "other", f.api.containers[0].Labels[projectLabel])
+}
+
+func TestContainerID(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	_, err := f.client.ContainerID(f.ctx, f.spec("db"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no container found for service db")
+
+	f.up("db")
+	id, err := f.client.ContainerID(f.ctx, f.spec("db"))
+	require.NoError(t, err)
+	assert.Equal(t, f.api.containers[0].ID, id.String())
+}
+
+func TestStreamEvents(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+	ch, err := f.client.StreamEvents(ctx, f.spec("db").Project)
+	require.NoError(t, err)
+
+	f.api.events <- events.Message{
+		Type:   events.ContainerEventType,
+		Action: "start",
+		Actor: events.Actor{
+			ID: "abc123",
+			Attributes: map[string]string{
+				serviceLabel: "db",
+				"name":       "proj-db-1",
+				"image":      "postgres",
+			},
+		},
+		TimeNano: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano(),
+	}
+
+	evt, err := EventFromJsonStr(<-ch)
+	require.NoError(t, err)
+	assert.Equal(t, Event{
+		Time:       "2022-01-02T03:04:05Z",
+		Type:       TypeContainer,
+		Action:     "start",
+		ID:         "abc123",
+		Service:    "db",
+		Attributes: Attributes{Name: "proj-db-1", Image: "postgres"},
+	}, evt)
+}
+
+func TestStreamLogs(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	// No container yet, so there's nothing to follow.
+	out, err := io.ReadAll(f.client.StreamLogs(f.ctx, f.spec("db")))
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	f.up("db")
+	f.api.logs = "2022-01-02T03:04:05.000000000Z ready to accept connections\n"
+	out, err = io.ReadAll(f.client.StreamLogs(f.ctx, f.spec("db")))
+	require.NoError(t, err)
+	assert.Equal(t, f.api.logs, string(out))
+}
+
+func TestStreamLogsReplicas(t *testing.T) {
+	f := newEngineFixture(t, engineConfig)
+
+	spec := f.spec("db")
+	spec.Replicas = 2
+	f.upSpec(spec)
+	f.api.logs = "2022-01-02T03:04:05.000000000Z first\n2022-01-02T03:04:06.000000000Z second\n"
+
+	out, err := io.ReadAll(f.client.StreamLogs(f.ctx, spec))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	assert.Len(t, lines, 4)
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "2022-01-02T03:04:0"), "line without a timestamp: %q", line)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var mu sync.Mutex
+	out := &bytes.Buffer{}
+	lw := &lineWriter{mu: &mu, w: out}
+
+	_, _ = lw.Write([]byte("hello "))
+	assert.Equal(t, "", out.String())
+	_, _ = lw.Write([]byte("world\nnext"))
+	assert.Equal(t, "hello world\n", out.String())
+	lw.flush()
+	assert.Equal(t, "hello world\nnext", out.String())
+}
+
+type engineFixture struct {
+	t      *testing.T
+	ctx    context.Context
+	tmpdir *tempdir.TempDirFixture
+	api    *fakeDockerAPI
+	images *fakeImageClient
+	client *dcClient
+}
+
+func newEngineFixture(t *testing.T, config string) *engineFixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	tmpdir := tempdir.NewTempDirFixture(t)
+	tmpdir.WriteFile("docker-compose.yml", config)
+
+	oldInterval := dependencyPollInterval
+	dependencyPollInterval = time.Millisecond
+	t.Cleanup(func() {
+		dependencyPollInterval = oldInterval
+	})
+
+	api := newFakeDockerAPI()
+	api.images["postgres"] = "sha256:postgres"
+	api.images["app"] = "sha256:app"
+	images := &fakeImageClient{api: api}
+	client := &dcClient{api: api, images: images}
+
+	return &engineFixture{
+		t:      t,
+		ctx:    ctx,
+		tmpdir: tmpdir,
+		api:    api,
+		images: images,
+		client: client,
+	}
+}
+
+func (f *engineFixture) spec(service string) v1alpha1.DockerComposeServiceSpec {
+	return v1alpha1.DockerComposeServiceSpec{
+		Service: service,
+		Project: v1alpha1.DockerComposeProject{
+			Name:        "proj",
+			ProjectPath: f.tmpdir.Path(),
+			ConfigPaths: []string{f.tmpdir.JoinPath("docker-compose.yml")},
+		},
+	}
+}
+
+func (f *engineFixture) up(service string) string {
+	f.t.Helper()
+	return f.upSpec(f.spec(service))
+}
+
+func (f *engineFixture) upSpec(spec v1alpha1.DockerComposeServiceSpec) string {
+	f.t.Helper()
+	out := &bytes.Buffer{}
+	err := f.client.Up(f.ctx, spec, false, out, out)
+	require.NoError(f.t, err)
+	return out.String()
+}
+
+func (f *engineFixture) upErr(service string) error {
+	out := &bytes.Buffer{}
+	return f.client.Up(f.ctx, f.spec(service), false, out, out)
+}
+
+// An in-memory Docker server, with just enough behavior to run a project.
+type fakeDockerAPI struct {
+	mu sync.Mutex
+
+	containers  []dtypes.Container
+	health      map[string]string
+	images      map[string]string
+	networks    []dtypes.NetworkResource
+	volumes     map[string]dtypes.Volume
+	events      chan events.Message
+	logs        string
+	createCount int
+
+	inspectCount int
+	onInspect    func(n int)
+	onStop       func(id string)
+}
+
+var _ dockerAPI = &fakeDockerAPI{}
+
+func newFakeDockerAPI() *fakeDockerAPI {
+	return &fakeDockerAPI{
+		health:  make(map[string]string),
+		images:  make(map[string]string),
+		volumes: make(map[string]dtypes.Volume),
+		events:  make(chan events.Message, 10),
+	}
+}
+
+func (a *fakeDockerAPI) setHealth(name, status string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.health[name] = status
+}
+
+func (a *fakeDockerAPI) ContainerCreate(ctx context.Context, config *mobycontainer.Config, hostConfig *mobycontainer.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (mobycontainer.ContainerCreateCreatedBody, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, ctr := range a.containers {
+		if ctr.Names[0] == "/"+containerName {
+			return mobycontainer.ContainerCreateCreatedBody{}, errdefs.Conflict(fmt.Errorf("name %s in use", containerName))
+		}
+	}
+	a.createCount++
+	id := fmt.Sprintf("container-%d", a.createCount)
+	a.containers = append([]dtypes.Container{{
+		ID:     id,
+		Names:  []string{"/" + containerName},
+		Image:  config.Image,
+		Labels: config.Labels,
+		State:  ContainerStatusCreated,
+	}}, a.containers...)
+	return mobycontainer.ContainerCreateCreatedBody{ID: id}, nil
+}
+
+func (a *fakeDockerAPI) containerNamed(name string) dtypes.Container {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, ctr := range a.containers {
+		if ctr.Names[0] == "/"+name {
+			return ctr
+		}
+	}
+	return dtypes.Container{}
+}
+
+func (a *fakeDockerAPI) find(id string) (*dtypes.Container, error) {
+	for i, ctr := range a.containers {
+		if ctr.ID == id {
+			return &a.containers[i], nil
+		}
+	}
+	return nil, errdefs.NotFound(fmt.Errorf("no such container: %s", id))
+}
+
+func (a *fakeDockerAPI) ContainerInspect(ctx context.Context, containerID string) (dtypes.ContainerJSON, error) {
+	a.mu.Lock()
+	a.inspectCount++
+	n := a.inspectCount
+	onInspect := a.onInspect
+	a.mu.Unlock()
+	if onInspect != nil {
+		onInspect(n)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ctr, err := a.find(containerID)
+	if err != nil {
+		return dtypes.ContainerJSON{}, err
+	}
+	state := &dtypes.ContainerState{
+		Status:  ctr.State,
+		Running: ctr.State == ContainerStatusRunning,
+	}
+	if status, ok := a.health[displayName(ctr.Names)]; ok {
+		state.Health = &dtypes.Health{Status: status}
+	}
+	return dtypes.ContainerJSON{
+		ContainerJSONBase: &dtypes.ContainerJSONBase{ID: ctr.ID, Name: ctr.Names[0], State: state},
+		Config:            &mobycontainer.Config{Tty: true},
+	}, nil
+}
+
+func (a *fakeDockerAPI) ContainerList(ctx context.Context, options dtypes.ContainerListOptions) ([]dtypes.Container, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var result []dtypes.Container
+	for _, ctr := range a.containers {
+		if matchesLabels(ctr.Labels, options.Filters.Get("label")) {
+			result = append(result, ctr)
+		}
+	}
+	return result, nil
+}
+
+func matchesLabels(labels map[string]string, selectors []string) bool {
+	for _, s := range selectors {
+		parts := strings.SplitN(s, "=", 2)
+		if labels[parts[0]] != parts[1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *fakeDockerAPI) ContainerLogs(ctx context.Context, containerID string, options dtypes.ContainerLogsOptions) (io.ReadCloser, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return io.NopCloser(strings.NewReader(a.logs)), nil
+}
+
+func (a *fakeDockerAPI) ContainerRemove(ctx context.Context, containerID string, options dtypes.ContainerRemoveOptions) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, ctr := range a.containers {
+		if ctr.ID == containerID {
+			a.containers = append(a.containers[:i], a.containers[i+1:]...)
+			return nil
+		}
+	}
+	return errdefs.NotFound(fmt.Errorf("no such container: %s", containerID))
+}
+
+func (a *fakeDockerAPI) ContainerStart(ctx context.Context, containerID string, options dtypes.ContainerStartOptions) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ctr, err := a.find(containerID)
+	if err != nil {
+		return err
+	}
+	ctr.State = ContainerStatusRunning
+	return nil
+}
+
+func (a *fakeDockerAPI) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
+	a.mu.Lock()
+	onStop := a.onStop
+	a.mu.Unlock()
+	if onStop != nil {
+		onStop(containerID)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ctr, err := a.find(containerID)
+	if err != nil {
+		return err
+	}
+	ctr.State = ContainerStatusExited
+	return nil
+}
+
+func (a *fakeDockerAPI) Events(ctx context.Context, options dtypes.EventsOptions) (<-chan events.Message, <-chan error) {
+	return a.events, make(chan error)
+}
+
+func (a *fakeDockerAPI) ImageInspectWithRaw(ctx context.Context, imageID string) (dtypes.ImageInspect, []byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	id, ok := a.images[imageID]
+	if !ok {
+		return dtypes.ImageInspect{}, nil, errdefs.NotFound(fmt.Errorf("no such image: %s", imageID))
+	}
+	return dtypes.ImageInspect{ID: id}, nil, nil
+}
+
+func (a *fakeDockerAPI) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	return nil
+}
+
+func (a *fakeDockerAPI) NetworkCreate(ctx context.Context, name string, options dtypes.NetworkCreate) (dtypes.NetworkCreateResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.networks = append(a.networks, dtypes.NetworkResource{ID: "network-" + name, Name: name, Labels: options.Labels})
+	return dtypes.NetworkCreateResponse{ID: "network-" + name}, nil
+}
+
+func (a *fakeDockerAPI) NetworkInspect(ctx context.Context, networkID string, options dtypes.NetworkInspectOptions) (dtypes.NetworkResource, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, n := range a.networks {
+		if n.ID == networkID || n.Name == networkID {
+			return n, nil
+		}
+	}
+	return dtypes.NetworkResource{}, errdefs.NotFound(fmt.Errorf("no such network: %s", networkID))
+}
+
+func (a *fakeDockerAPI) NetworkList(ctx context.Context, options dtypes.NetworkListOptions) ([]dtypes.NetworkResource, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var result []dtypes.NetworkResource
+	for _, n := range a.networks {
+		nameMatches := true
+		for _, name := range options.Filters.Get("name") {
+			nameMatches = nameMatches && strings.Contains(n.Name, name)
+		}
+		if nameMatches && matchesLabels(n.Labels, options.Filters.Get("label")) {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (a *fakeDockerAPI) NetworkRemove(ctx context.Context, networkID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, n := range a.networks {
+		if n.ID == networkID {
+			a.networks = append(a.networks[:i], a.networks[i+1:]...)
+			return nil
+		}
+	}
+	return errdefs.NotFound(fmt.Errorf("no such network: %s", networkID))
+}
+
+func (a *fakeDockerAPI) VolumeCreate(ctx context.Context, options volumetypes.VolumeCreateBody) (dtypes.Volume, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	v := dtypes.Volume{Name: options.Name, Driver: options.Driver, Labels: options.Labels}
+	a.volumes[options.Name] = v
+	return v, nil
+}
+
+func (a *fakeDockerAPI) VolumeInspect(ctx context.Context, volumeID string) (dtypes.Volume, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	v, ok := a.volumes[volumeID]
+	if !ok {
+		return dtypes.Volume{}, errdefs.NotFound(fmt.Errorf("no such volume: %s", volumeID))
+	}
+	return v, nil
+}
+
+type fakeImageClient struct {
+	api   *fakeDockerAPI
+	pulls []string
+}
+
+func (c *fakeImageClient) ImagePull(ctx context.Context, ref reference.Named) (reference.Canonical, error) {
+	c.pulls = append(c.pulls, ref.String())
+
+	c.api.mu.Lock()
+	defer c.api.mu.Unlock()
+	c.api.images[reference.FamiliarString(ref)] = "sha256:" + reference.FamiliarString(ref)
+	return nil, nil
+}
+
+func (c *fakeImageClient) ImageBuild(ctx context.Context, buildContext io.Reader, options docker.BuildOptions) (dtypes.ImageBuildResponse, error) {
+	return dtypes.ImageBuildResponse{}, fmt.Errorf("not implemented")
+}
@@ -0,0 +1,638 @@
+package dockercompose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/types"
+	"github.com/containerd/containerd/platforms"
+	"github.com/docker/cli/opts"
+	mobycontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/go-connections/nat"
+	units "github.com/docker/go-units"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Everything we need to create the container for a service.
+type createConfig struct {
+	name             string
+	config           *mobycontainer.Config
+	hostConfig       *mobycontainer.HostConfig
+	networkingConfig *network.NetworkingConfig
+	platform         *specs.Platform
+
+	// The API only connects a new container to one network,
+	// so we connect it to the rest before we start it.
+	extraNetworks []networkAttachment
+}
+
+type networkAttachment struct {
+	name     string
+	endpoint *network.EndpointSettings
+}
+
+// Looks up the name of the container of another service, for
+// network_mode, volumes_from, and links.
+type containerLookup func(service string) (string, error)
+
+func (c *dcClient) createConfig(ctx context.Context, proj *types.Project, svc types.ServiceConfig, number int, image, imageID, hash string) (createConfig, error) {
+	lookup := func(service string) (string, error) {
+		containers, err := c.containersForService(ctx, proj.Name, service)
+		if err != nil {
+			return "", err
+		}
+		if len(containers) == 0 {
+			return "", fmt.Errorf("service %s has no container", service)
+		}
+		return displayName(firstReplica(containers).Names), nil
+	}
+	return toCreateConfig(proj, svc, number, image, imageID, hash, lookup)
+}
+
+// Converts a service to the Docker API's container config, like `docker compose create`.
+func toCreateConfig(proj *types.Project, svc types.ServiceConfig, number int, image, imageID, hash string, lookup containerLookup) (createConfig, error) {
+	labels := map[string]string{}
+	for k, v := range svc.Labels {
+		labels[k] = v
+	}
+	labels[projectLabel] = proj.Name
+	labels[serviceLabel] = svc.Name
+	labels[numberLabel] = strconv.Itoa(number)
+	labels[oneoffLabel] = "False"
+	labels[configHashLabel] = hash
+	labels[imageLabel] = imageID
+	labels[workingDirLabel] = proj.WorkingDir
+	labels[configFilesLabel] = strings.Join(proj.ComposeFiles, ",")
+	if len(svc.DependsOn) > 0 {
+		labels[dependenciesLabel] = strings.Join(dependencyNames(svc), ",")
+	}
+
+	exposed, bindings, err := toPorts(svc)
+	if err != nil {
+		return createConfig{}, err
+	}
+
+	config := &mobycontainer.Config{
+		Hostname:     svc.Hostname,
+		Domainname:   svc.DomainName,
+		User:         svc.User,
+		ExposedPorts: exposed,
+		Tty:          svc.Tty,
+		OpenStdin:    svc.StdinOpen,
+		Env:          toEnv(svc.Environment),
+		Cmd:          strslice.StrSlice(svc.Command),
+		Healthcheck:  toHealthConfig(svc.HealthCheck),
+		Image:        image,
+		WorkingDir:   svc.WorkingDir,
+		Entrypoint:   strslice.StrSlice(svc.Entrypoint),
+		MacAddress:   svc.MacAddress,
+		Labels:       labels,
+		StopSignal:   svc.StopSignal,
+	}
+	if svc.StopGracePeriod != nil {
+		timeout := int(time.Duration(*svc.StopGracePeriod).Seconds())
+		config.StopTimeout = &timeout
+	}
+
+	restart, err := toRestartPolicy(svc)
+	if err != nil {
+		return createConfig{}, err
+	}
+	resources, err := toResources(svc)
+	if err != nil {
+		return createConfig{}, err
+	}
+	mounts, binds, err := toMounts(proj, svc)
+	if err != nil {
+		return createConfig{}, err
+	}
+	volumesFrom, err := toVolumesFrom(svc, lookup)
+	if err != nil {
+		return createConfig{}, err
+	}
+
+	hostConfig := &mobycontainer.HostConfig{
+		Binds:          binds,
+		Mounts:         mounts,
+		PortBindings:   bindings,
+		RestartPolicy:  restart,
+		VolumeDriver:   svc.VolumeDriver,
+		VolumesFrom:    volumesFrom,
+		CapAdd:         strslice.StrSlice(svc.CapAdd),
+		CapDrop:        strslice.StrSlice(svc.CapDrop),
+		DNS:            svc.DNS,
+		DNSOptions:     svc.DNSOpts,
+		DNSSearch:      svc.DNSSearch,
+		ExtraHosts:     svc.ExtraHosts,
+		GroupAdd:       svc.GroupAdd,
+		IpcMode:        mobycontainer.IpcMode(svc.Ipc),
+		OomScoreAdj:    int(svc.OomScoreAdj),
+		PidMode:        mobycontainer.PidMode(svc.Pid),
+		Privileged:     svc.Privileged,
+		ReadonlyRootfs: svc.ReadOnly,
+		SecurityOpt:    svc.SecurityOpt,
+		Tmpfs:          toTmpfs(svc.Tmpfs),
+		UTSMode:        mobycontainer.UTSMode(svc.Uts),
+		UsernsMode:     mobycontainer.UsernsMode(svc.UserNSMode),
+		ShmSize:        int64(svc.ShmSize),
+		Sysctls:        svc.Sysctls,
+		Runtime:        svc.Runtime,
+		Isolation:      mobycontainer.Isolation(svc.Isolation),
+		Init:           svc.Init,
+		Resources:      resources,
+	}
+	if svc.Logging != nil {
+		hostConfig.LogConfig = mobycontainer.LogConfig{
+			Type:   svc.Logging.Driver,
+			Config: svc.Logging.Options,
+		}
+	}
+
+	result := createConfig{
+		name:       containerName(proj.Name, svc, number),
+		config:     config,
+		hostConfig: hostConfig,
+	}
+
+	if svc.Platform != "" {
+		p, err := platforms.Parse(svc.Platform)
+		if err != nil {
+			return createConfig{}, err
+		}
+		result.platform = &p
+	}
+
+	err = addNetworks(&result, proj, svc, lookup)
+	if err != nil {
+		return createConfig{}, err
+	}
+	return result, nil
+}
+
+// Attaches the container to its networks, or sets its network_mode.
+func addNetworks(result *createConfig, proj *types.Project, svc types.ServiceConfig, lookup containerLookup) error {
+	if svc.NetworkMode != "" {
+		mode := svc.NetworkMode
+		if strings.HasPrefix(mode, types.NetworkModeServicePrefix) {
+			name, err := lookup(strings.TrimPrefix(mode, types.NetworkModeServicePrefix))
+			if err != nil {
+				return fmt.Errorf("network_mode: %v", err)
+			}
+			mode = types.NetworkModeContainerPrefix + name
+		}
+		result.hostConfig.NetworkMode = mobycontainer.NetworkMode(mode)
+		return nil
+	}
+
+	links, err := toLinks(svc, lookup)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(svc.Networks))
+	for key := range svc.Networks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// Connect to the networks with the highest priority first.
+	sort.SliceStable(keys, func(i, j int) bool {
+		return networkPriority(svc.Networks[keys[i]]) > networkPriority(svc.Networks[keys[j]])
+	})
+
+	for i, key := range keys {
+		n, ok := proj.Networks[key]
+		if !ok {
+			return fmt.Errorf("service %s refers to undefined network %s", svc.Name, key)
+		}
+
+		endpoint := &network.EndpointSettings{
+			Aliases: []string{svc.Name},
+			Links:   links,
+		}
+		if cfg := svc.Networks[key]; cfg != nil {
+			endpoint.Aliases = append(endpoint.Aliases, cfg.Aliases...)
+			if cfg.Ipv4Address != "" || cfg.Ipv6Address != "" {
+				endpoint.IPAMConfig = &network.EndpointIPAMConfig{
+					IPv4Address: cfg.Ipv4Address,
+					IPv6Address: cfg.Ipv6Address,
+				}
+			}
+		}
+
+		if i == 0 {
+			result.hostConfig.NetworkMode = mobycontainer.NetworkMode(n.Name)
+			result.networkingConfig = &network.NetworkingConfig{
+				EndpointsConfig: map[string]*network.EndpointSettings{n.Name: endpoint},
+			}
+			continue
+		}
+		result.extraNetworks = append(result.extraNetworks, networkAttachment{name: n.Name, endpoint: endpoint})
+	}
+	return nil
+}
+
+func networkPriority(cfg *types.ServiceNetworkConfig) int {
+	if cfg == nil {
+		return 0
+	}
+	return cfg.Priority
+}
+
+// Converts links like "db" or "db:database" to container links.
+func toLinks(svc types.ServiceConfig, lookup containerLookup) ([]string, error) {
+	var result []string
+	for _, link := range svc.Links {
+		parts := strings.SplitN(link, ":", 2)
+		alias := parts[0]
+		if len(parts) == 2 {
+			alias = parts[1]
+		}
+		name, err := lookup(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("links: %v", err)
+		}
+		result = append(result, fmt.Sprintf("%s:%s", name, alias))
+	}
+	result = append(result, svc.ExternalLinks...)
+	return result, nil
+}
+
+// Converts volumes_from entries like "db", "db:ro", or "container:name" to containers.
+func toVolumesFrom(svc types.ServiceConfig, lookup containerLookup) ([]string, error) {
+	var result []string
+	for _, from := range svc.VolumesFrom {
+		if strings.HasPrefix(from, types.NetworkModeContainerPrefix) {
+			result = append(result, strings.TrimPrefix(from, types.NetworkModeContainerPrefix))
+			continue
+		}
+		parts := strings.SplitN(from, ":", 2)
+		name, err := lookup(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("volumes_from: %v", err)
+		}
+		if len(parts) == 2 {
+			name = fmt.Sprintf("%s:%s", name, parts[1])
+		}
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+func toEnv(env types.MappingWithEquals) []string {
+	var result []string
+	for k, v := range env {
+		if v == nil {
+			// An unset variable with no default isn't passed to the container.
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s=%s", k, *v))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func toPorts(svc types.ServiceConfig) (nat.PortSet, nat.PortMap, error) {
+	exposed := nat.PortSet{}
+	bindings := nat.PortMap{}
+	for _, p := range svc.Ports {
+		proto := p.Protocol
+		if proto == "" {
+			proto = "tcp"
+		}
+		port, err := nat.NewPort(proto, strconv.Itoa(int(p.Target)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("ports: %v", err)
+		}
+		exposed[port] = struct{}{}
+		bindings[port] = append(bindings[port], nat.PortBinding{
+			HostIP:   p.HostIP,
+			HostPort: p.Published,
+		})
+	}
+
+	for _, e := range svc.Expose {
+		ports, _, err := nat.ParsePortSpecs([]string{e})
+		if err != nil {
+			return nil, nil, fmt.Errorf("expose: %v", err)
+		}
+		for port := range ports {
+			exposed[port] = struct{}{}
+		}
+	}
+	return exposed, bindings, nil
+}
+
+func toHealthConfig(hc *types.HealthCheckConfig) *mobycontainer.HealthConfig {
+	if hc == nil {
+		return nil
+	}
+	if hc.Disable {
+		return &mobycontainer.HealthConfig{Test: []string{"NONE"}}
+	}
+
+	result := &mobycontainer.HealthConfig{Test: hc.Test}
+	if hc.Interval != nil {
+		result.Interval = time.Duration(*hc.Interval)
+	}
+	if hc.Timeout != nil {
+		result.Timeout = time.Duration(*hc.Timeout)
+	}
+	if hc.StartPeriod != nil {
+		result.StartPeriod = time.Duration(*hc.StartPeriod)
+	}
+	if hc.Retries != nil {
+		result.Retries = int(*hc.Retries)
+	}
+	return result
+}
+
+func toRestartPolicy(svc types.ServiceConfig) (mobycontainer.RestartPolicy, error) {
+	if svc.Restart != "" {
+		return opts.ParseRestartPolicy(svc.Restart)
+	}
+	if svc.Deploy != nil && svc.Deploy.RestartPolicy != nil {
+		policy := svc.Deploy.RestartPolicy
+		result := mobycontainer.RestartPolicy{}
+		switch policy.Condition {
+		case "", "any":
+			result.Name = "always"
+		case "on-failure":
+			result.Name = "on-failure"
+		case "none":
+			result.Name = "no"
+		default:
+			return result, fmt.Errorf("unknown restart_policy condition: %s", policy.Condition)
+		}
+		if policy.MaxAttempts != nil {
+			result.MaximumRetryCount = int(*policy.MaxAttempts)
+		}
+		return result, nil
+	}
+	return mobycontainer.RestartPolicy{}, nil
+}
+
+func toResources(svc types.ServiceConfig) (mobycontainer.Resources, error) {
+	result := mobycontainer.Resources{
+		CgroupParent:       svc.CgroupParent,
+		CPUCount:           svc.CPUCount,
+		CPUPercent:         int64(svc.CPUPercent),
+		CPUPeriod:          svc.CPUPeriod,
+		CPUQuota:           svc.CPUQuota,
+		CPURealtimePeriod:  svc.CPURTPeriod,
+		CPURealtimeRuntime: svc.CPURTRuntime,
+		CPUShares:          svc.CPUShares,
+		CpusetCpus:         svc.CPUSet,
+		NanoCPUs:           int64(svc.CPUS * 1e9),
+		Memory:             int64(svc.MemLimit),
+		MemoryReservation:  int64(svc.MemReservation),
+		MemorySwap:         int64(svc.MemSwapLimit),
+		DeviceCgroupRules:  svc.DeviceCgroupRules,
+	}
+	if svc.MemSwappiness != 0 {
+		swappiness := int64(svc.MemSwappiness)
+		result.MemorySwappiness = &swappiness
+	}
+	if svc.OomKillDisable {
+		result.OomKillDisable = &svc.OomKillDisable
+	}
+	if svc.PidsLimit != 0 {
+		result.PidsLimit = &svc.PidsLimit
+	}
+
+	// deploy.resources.limits, like `docker compose` without swarm.
+	if svc.Deploy != nil && svc.Deploy.Resources.Limits != nil {
+		limits := svc.Deploy.Resources.Limits
+		if limits.NanoCPUs != "" {
+			cpus, err := strconv.ParseFloat(limits.NanoCPUs, 64)
+			if err != nil {
+				return result, fmt.Errorf("deploy.resources.limits.cpus: %v", err)
+			}
+			result.NanoCPUs = int64(cpus * 1e9)
+		}
+		if limits.MemoryBytes != 0 {
+			result.Memory = int64(limits.MemoryBytes)
+		}
+		if limits.PIds != 0 {
+			result.PidsLimit = &limits.PIds
+		}
+	}
+
+	for name, u := range svc.Ulimits {
+		soft, hard := int64(u.Soft), int64(u.Hard)
+		if u.Single != 0 {
+			soft, hard = int64(u.Single), int64(u.Single)
+		}
+		result.Ulimits = append(result.Ulimits, &units.Ulimit{Name: name, Soft: soft, Hard: hard})
+	}
+	sort.Slice(result.Ulimits, func(i, j int) bool {
+		return result.Ulimits[i].Name < result.Ulimits[j].Name
+	})
+
+	for _, d := range svc.Devices {
+		device, err := toDeviceMapping(d)
+		if err != nil {
+			return result, err
+		}
+		result.Devices = append(result.Devices, device)
+	}
+	return result, nil
+}
+
+// Parses a device like "/dev/ttyUSB0", "/dev/ttyUSB0:/dev/ttyUSB1", or "/dev/ttyUSB0:/dev/ttyUSB1:rw".
+func toDeviceMapping(device string) (mobycontainer.DeviceMapping, error) {
+	parts := strings.Split(device, ":")
+	result := mobycontainer.DeviceMapping{
+		PathOnHost:        parts[0],
+		PathInContainer:   parts[0],
+		CgroupPermissions: "rwm",
+	}
+	switch len(parts) {
+	case 1:
+	case 2:
+		result.PathInContainer = parts[1]
+	case 3:
+		result.PathInContainer = parts[1]
+		result.CgroupPermissions = parts[2]
+	default:
+		return result, fmt.Errorf("invalid device: %s", device)
+	}
+	return result, nil
+}
+
+func toTmpfs(tmpfs types.StringList) map[string]string {
+	if len(tmpfs) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(tmpfs))
+	for _, t := range tmpfs {
+		parts := strings.SplitN(t, ":", 2)
+		if len(parts) == 2 {
+			result[parts[0]] = parts[1]
+		} else {
+			result[parts[0]] = ""
+		}
+	}
+	return result
+}
+
+// Converts the service's volumes, secrets, and configs to mounts.
+//
+// Bind mounts that may create their host path (which includes every
+// bind mount in the short syntax) are binds, because the mount API
+// fails if the path doesn't exist.
+func toMounts(proj *types.Project, svc types.ServiceConfig) ([]mount.Mount, []string, error) {
+	var mounts []mount.Mount
+	var binds []string
+	for _, v := range svc.Volumes {
+		switch v.Type {
+		case types.VolumeTypeBind:
+			if v.Bind == nil || v.Bind.CreateHostPath {
+				binds = append(binds, toBind(v))
+				continue
+			}
+			mounts = append(mounts, mount.Mount{
+				Type:        mount.TypeBind,
+				Source:      v.Source,
+				Target:      v.Target,
+				ReadOnly:    v.ReadOnly,
+				Consistency: mount.Consistency(v.Consistency),
+				BindOptions: &mount.BindOptions{Propagation: mount.Propagation(v.Bind.Propagation)},
+			})
+
+		case types.VolumeTypeVolume:
+			source := v.Source
+			if source != "" {
+				vol, ok := proj.Volumes[source]
+				if !ok {
+					return nil, nil, fmt.Errorf("service %s refers to undefined volume %s", svc.Name, source)
+				}
+				source = vol.Name
+			}
+			m := mount.Mount{
+				Type:        mount.TypeVolume,
+				Source:      source,
+				Target:      v.Target,
+				ReadOnly:    v.ReadOnly,
+				Consistency: mount.Consistency(v.Consistency),
+			}
+			if v.Volume != nil && v.Volume.NoCopy {
+				m.VolumeOptions = &mount.VolumeOptions{NoCopy: true}
+			}
+			mounts = append(mounts, m)
+
+		case types.VolumeTypeTmpfs:
+			m := mount.Mount{
+				Type:     mount.TypeTmpfs,
+				Target:   v.Target,
+				ReadOnly: v.ReadOnly,
+			}
+			if v.Tmpfs != nil && v.Tmpfs.Size != 0 {
+				m.TmpfsOptions = &mount.TmpfsOptions{SizeBytes: int64(v.Tmpfs.Size)}
+			}
+			mounts = append(mounts, m)
+
+		case types.VolumeTypeNamedPipe:
+			mounts = append(mounts, mount.Mount{
+				Type:     mount.TypeNamedPipe,
+				Source:   v.Source,
+				Target:   v.Target,
+				ReadOnly: v.ReadOnly,
+			})
+
+		default:
+			return nil, nil, fmt.Errorf("unsupported volume type: %s", v.Type)
+		}
+	}
+
+	for _, s := range svc.Secrets {
+		secret, ok := proj.Secrets[s.Source]
+		if !ok {
+			return nil, nil, fmt.Errorf("service %s refers to undefined secret %s", svc.Name, s.Source)
+		}
+		target := s.Target
+		if target == "" {
+			target = "/run/secrets/" + s.Source
+		} else if !strings.HasPrefix(target, "/") {
+			target = "/run/secrets/" + target
+		}
+		m, err := fileObjectMount(types.FileObjectConfig(secret), "secret", s.Source, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		mounts = append(mounts, m)
+	}
+
+	for _, c := range svc.Configs {
+		config, ok := proj.Configs[c.Source]
+		if !ok {
+			return nil, nil, fmt.Errorf("service %s refers to undefined config %s", svc.Name, c.Source)
+		}
+		target := c.Target
+		if target == "" {
+			target = "/" + c.Source
+		}
+		m, err := fileObjectMount(types.FileObjectConfig(config), "config", c.Source, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts, binds, nil
+}
+
+func toBind(v types.ServiceVolumeConfig) string {
+	var options []string
+	if v.ReadOnly {
+		options = append(options, "ro")
+	}
+	if v.Bind != nil {
+		if v.Bind.SELinux != "" {
+			options = append(options, v.Bind.SELinux)
+		}
+		if v.Bind.Propagation != "" {
+			options = append(options, v.Bind.Propagation)
+		}
+	}
+	if v.Consistency != "" {
+		options = append(options, v.Consistency)
+	}
+
+	bind := fmt.Sprintf("%s:%s", v.Source, v.Target)
+	if len(options) > 0 {
+		bind += ":" + strings.Join(options, ",")
+	}
+	return bind
+}
+
+// Without swarm, Compose mounts secrets and configs from files.
+func fileObjectMount(obj types.FileObjectConfig, kind, name, target string) (mount.Mount, error) {
+	if obj.External.External {
+		return mount.Mount{}, fmt.Errorf("%s %s: external %ss need swarm, which isn't supported", kind, name, kind)
+	}
+	if obj.File == "" {
+		return mount.Mount{}, fmt.Errorf("%s %s: only file-based %ss are supported", kind, name, kind)
+	}
+	return mount.Mount{
+		Type:     mount.TypeBind,
+		Source:   obj.File,
+		Target:   target,
+		ReadOnly: true,
+	}, nil
+}
+
+// The services that this service depends on, in a stable order.
+func dependencyNames(svc types.ServiceConfig) []string {
+	names := make([]string, 0, len(svc.DependsOn))
+	for name := range svc.DependsOn {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,364 @@
+package dockercompose
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/loader"
+	"github.com/compose-spec/compose-go/types"
+	mobycontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestCreateConfigBasics(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    command: ["nginx", "-g", "daemon off;"]
+    environment:
+      B: "2"
+      A: "1"
+      UNSET:
+    labels:
+      team: frontend
+    ports:
+    - "8080:80"
+    - "127.0.0.1:8443:443/udp"
+    expose:
+    - "9000"
+    restart: on-failure:3
+    stop_grace_period: 30s
+    healthcheck:
+      test: ["CMD", "curl", "-f", "http://localhost"]
+      interval: 5s
+      retries: 3
+    tmpfs:
+    - /run:size=64m
+    ulimits:
+      nproc: 512
+      nofile:
+        soft: 1024
+        hard: 2048
+`)
+
+	cfg := f.createConfig("web")
+	assert.Equal(t, "proj-web-1", cfg.name)
+	assert.Equal(t, "nginx", cfg.config.Image)
+	assert.Equal(t, []string{"nginx", "-g", "daemon off;"}, []string(cfg.config.Cmd))
+	assert.Equal(t, []string{"A=1", "B=2"}, cfg.config.Env)
+
+	assert.Equal(t, "frontend", cfg.config.Labels["team"])
+	assert.Equal(t, "proj", cfg.config.Labels[projectLabel])
+	assert.Equal(t, "web", cfg.config.Labels[serviceLabel])
+	assert.Equal(t, "False", cfg.config.Labels[oneoffLabel])
+	assert.Equal(t, "hash", cfg.config.Labels[configHashLabel])
+	assert.Equal(t, "sha256:image", cfg.config.Labels[imageLabel])
+	assert.Equal(t, f.tmpdir.Path(), cfg.config.Labels[workingDirLabel])
+
+	assert.Equal(t, nat.PortSet{"80/tcp": {}, "443/udp": {}, "9000/tcp": {}}, cfg.config.ExposedPorts)
+	assert.Equal(t, nat.PortMap{
+		"80/tcp":  {{HostPort: "8080"}},
+		"443/udp": {{HostIP: "127.0.0.1", HostPort: "8443"}},
+	}, cfg.hostConfig.PortBindings)
+
+	assert.Equal(t, mobycontainer.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3}, cfg.hostConfig.RestartPolicy)
+	require.NotNil(t, cfg.config.StopTimeout)
+	assert.Equal(t, 30, *cfg.config.StopTimeout)
+	assert.Equal(t, &mobycontainer.HealthConfig{
+		Test:     []string{"CMD", "curl", "-f", "http://localhost"},
+		Interval: 5 * time.Second,
+		Retries:  3,
+	}, cfg.config.Healthcheck)
+	assert.Equal(t, map[string]string{"/run": "size=64m"}, cfg.hostConfig.Tmpfs)
+
+	require.Len(t, cfg.hostConfig.Ulimits, 2)
+	assert.Equal(t, "nofile", cfg.hostConfig.Ulimits[0].Name)
+	assert.Equal(t, int64(1024), cfg.hostConfig.Ulimits[0].Soft)
+	assert.Equal(t, int64(2048), cfg.hostConfig.Ulimits[0].Hard)
+	assert.Equal(t, "nproc", cfg.hostConfig.Ulimits[1].Name)
+	assert.Equal(t, int64(512), cfg.hostConfig.Ulimits[1].Hard)
+}
+
+func TestCreateConfigContainerName(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    container_name: my-web
+`)
+	assert.Equal(t, "my-web", f.createConfig("web").name)
+}
+
+func TestCreateConfigDisabledHealthcheck(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    healthcheck:
+      disable: true
+`)
+	assert.Equal(t, []string{"NONE"}, f.createConfig("web").config.Healthcheck.Test)
+}
+
+func TestCreateConfigDeployResources(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    deploy:
+      restart_policy:
+        condition: on-failure
+        max_attempts: 5
+      resources:
+        limits:
+          cpus: "0.5"
+          memory: 64M
+`)
+	cfg := f.createConfig("web")
+	assert.Equal(t, mobycontainer.RestartPolicy{Name: "on-failure", MaximumRetryCount: 5}, cfg.hostConfig.RestartPolicy)
+	assert.Equal(t, int64(5e8), cfg.hostConfig.NanoCPUs)
+	assert.Equal(t, int64(64*1024*1024), cfg.hostConfig.Memory)
+}
+
+func TestCreateConfigMounts(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    volumes:
+    - ./html:/usr/share/nginx/html:ro
+    - type: bind
+      source: ./conf
+      target: /etc/nginx/conf.d
+      bind:
+        create_host_path: false
+    - data:/data
+    - type: tmpfs
+      target: /cache
+      tmpfs:
+        size: 1000
+    secrets:
+    - token
+    configs:
+    - source: settings
+      target: /etc/settings.json
+volumes:
+  data: {}
+secrets:
+  token:
+    file: ./token.txt
+configs:
+  settings:
+    file: ./settings.json
+`)
+	cfg := f.createConfig("web")
+	assert.Equal(t, []string{fmt.Sprintf("%s:/usr/share/nginx/html:ro", f.tmpdir.JoinPath("html"))}, cfg.hostConfig.Binds)
+	assert.Equal(t, []mount.Mount{
+		{
+			Type:        mount.TypeBind,
+			Source:      f.tmpdir.JoinPath("conf"),
+			Target:      "/etc/nginx/conf.d",
+			BindOptions: &mount.BindOptions{},
+		},
+		{Type: mount.TypeVolume, Source: "proj_data", Target: "/data"},
+		{Type: mount.TypeTmpfs, Target: "/cache", TmpfsOptions: &mount.TmpfsOptions{SizeBytes: 1000}},
+		{Type: mount.TypeBind, Source: f.tmpdir.JoinPath("token.txt"), Target: "/run/secrets/token", ReadOnly: true},
+		{Type: mount.TypeBind, Source: f.tmpdir.JoinPath("settings.json"), Target: "/etc/settings.json", ReadOnly: true},
+	}, cfg.hostConfig.Mounts)
+}
+
+func TestCreateConfigExternalSecret(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    secrets:
+    - token
+secrets:
+  token:
+    external: true
+`)
+	_, err := f.createConfigErr("web")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret token: external secrets need swarm")
+}
+
+func TestCreateConfigNetworks(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    networks:
+      front:
+        aliases: [www]
+      back:
+        priority: 100
+        ipv4_address: 172.16.238.10
+networks:
+  front: {}
+  back: {}
+`)
+	cfg := f.createConfig("web")
+
+	// The network with the highest priority comes first.
+	assert.Equal(t, mobycontainer.NetworkMode("proj_back"), cfg.hostConfig.NetworkMode)
+	require.Contains(t, cfg.networkingConfig.EndpointsConfig, "proj_back")
+	back := cfg.networkingConfig.EndpointsConfig["proj_back"]
+	assert.Equal(t, []string{"web"}, back.Aliases)
+	assert.Equal(t, "172.16.238.10", back.IPAMConfig.IPv4Address)
+
+	require.Len(t, cfg.extraNetworks, 1)
+	assert.Equal(t, "proj_front", cfg.extraNetworks[0].name)
+	assert.Equal(t, []string{"web", "www"}, cfg.extraNetworks[0].endpoint.Aliases)
+}
+
+func TestCreateConfigDefaultNetwork(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    links:
+    - db:database
+  db:
+    image: postgres
+`)
+	cfg := f.createConfig("web")
+	assert.Equal(t, mobycontainer.NetworkMode("proj_default"), cfg.hostConfig.NetworkMode)
+	endpoint := cfg.networkingConfig.EndpointsConfig["proj_default"]
+	assert.Equal(t, []string{"web"}, endpoint.Aliases)
+	assert.Equal(t, []string{"proj-db-1:database"}, endpoint.Links)
+	assert.Empty(t, cfg.extraNetworks)
+}
+
+func TestCreateConfigNetworkModeService(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    network_mode: service:vpn
+    volumes_from:
+    - vpn:ro
+    - container:other
+  vpn:
+    image: vpn
+`)
+	cfg := f.createConfig("web")
+	assert.Equal(t, mobycontainer.NetworkMode("container:proj-vpn-1"), cfg.hostConfig.NetworkMode)
+	assert.Nil(t, cfg.networkingConfig)
+	assert.Equal(t, []string{"proj-vpn-1:ro", "other"}, cfg.hostConfig.VolumesFrom)
+}
+
+func TestCreateConfigNetworkModeMissingService(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    network_mode: service:vpn
+  vpn:
+    image: vpn
+`)
+	f.containers = map[string]string{}
+	_, err := f.createConfigErr("web")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "network_mode: service vpn has no container")
+}
+
+func TestConfigHash(t *testing.T) {
+	f := newConvertFixture(t, `services:
+  web:
+    image: nginx
+    environment:
+      A: "1"
+`)
+	svc := f.service("web")
+	hash, err := configHash(svc, "sha256:a")
+	require.NoError(t, err)
+
+	same, err := configHash(svc, "sha256:a")
+	require.NoError(t, err)
+	assert.Equal(t, hash, same)
+
+	newImage, err := configHash(svc, "sha256:b")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, newImage)
+
+	// Changing the pull policy doesn't change the container.
+	svc.PullPolicy = types.PullPolicyAlways
+	pull, err := configHash(svc, "sha256:a")
+	require.NoError(t, err)
+	assert.Equal(t, hash, pull)
+
+	value := "2"
+	svc.Environment = types.MappingWithEquals{"A": &value}
+	newEnv, err := configHash(svc, "sha256:a")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, newEnv)
+}
+
+func TestToDeviceMapping(t *testing.T) {
+	for _, tc := range []struct {
+		device   string
+		expected mobycontainer.DeviceMapping
+	}{
+		{"/dev/fuse", mobycontainer.DeviceMapping{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"}},
+		{"/dev/a:/dev/b", mobycontainer.DeviceMapping{PathOnHost: "/dev/a", PathInContainer: "/dev/b", CgroupPermissions: "rwm"}},
+		{"/dev/a:/dev/b:r", mobycontainer.DeviceMapping{PathOnHost: "/dev/a", PathInContainer: "/dev/b", CgroupPermissions: "r"}},
+	} {
+		t.Run(tc.device, func(t *testing.T) {
+			actual, err := toDeviceMapping(tc.device)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	_, err := toDeviceMapping("/dev/a:/dev/b:r:x")
+	assert.EqualError(t, err, "invalid device: /dev/a:/dev/b:r:x")
+}
+
+type convertFixture struct {
+	t          *testing.T
+	tmpdir     *tempdir.TempDirFixture
+	proj       *types.Project
+	containers map[string]string
+}
+
+func newConvertFixture(t *testing.T, config string) *convertFixture {
+	tmpdir := tempdir.NewTempDirFixture(t)
+	proj, err := loader.Load(types.ConfigDetails{
+		WorkingDir: tmpdir.Path(),
+		ConfigFiles: []types.ConfigFile{{
+			Filename: tmpdir.JoinPath("docker-compose.yml"),
+			Content:  []byte(config),
+		}},
+		Environment: map[string]string{},
+	}, dcLoaderOption("proj"))
+	require.NoError(t, err)
+
+	containers := map[string]string{}
+	for _, name := range proj.ServiceNames() {
+		containers[name] = fmt.Sprintf("proj-%s-1", name)
+	}
+	return &convertFixture{t: t, tmpdir: tmpdir, proj: proj, containers: containers}
+}
+
+func (f *convertFixture) service(name string) types.ServiceConfig {
+	svc, err := f.proj.GetService(name)
+	require.NoError(f.t, err)
+	return svc
+}
+
+func (f *convertFixture) createConfigErr(name string) (createConfig, error) {
+	lookup := func(service string) (string, error) {
+		c, ok := f.containers[service]
+		if !ok {
+			return "", fmt.Errorf("service %s has no container", service)
+		}
+		return c, nil
+	}
+	svc := f.service(name)
+	return toCreateConfig(f.proj, svc, 1, svc.Image, "sha256:image", "hash", lookup)
+}
+
+func (f *convertFixture) createConfig(name string) createConfig {
+	f.t.Helper()
+	cfg, err := f.createConfigErr(name)
+	require.NoError(f.t, err)
+	return cfg
+}
@@ -31,7 +31,6 @@ type FakeDCClient struct {
 	ContainerIdOutput container.ID
 	eventJson         chan string
 	ConfigOutput      string
-	VersionOutput     string
 
 	upCalls   []UpCall
 	downCalls []DownCall
@@ -210,14 +209,6 @@ func (c *FakeDCClient) ContainerID(ctx context.Context, spec v1alpha1.DockerComp
 	return c.ContainerIdOutput, nil
 }
 
-func (c *FakeDCClient) Version(_ context.Context) (string, string, error) {
-	if c.VersionOutput != "" {
-		return c.VersionOutput, "tilt-fake", nil
-	}
-	// default to a "known good" version that won't produce warnings
-	return "v1.29.2", "tilt-fake", nil
-}
-
 func (c *FakeDCClient) UpCalls() []UpCall {
 	c.mu.Lock()
 	defer c.mu.Unlock()
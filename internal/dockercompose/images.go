@@ -0,0 +1,103 @@
+package dockercompose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/compose-spec/compose-go/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+)
+
+// Pulls the service's image if we need to, according to its pull_policy.
+func (c *dcClient) ensureImage(ctx context.Context, image string, svc types.ServiceConfig, stdout io.Writer) error {
+	switch svc.PullPolicy {
+	case types.PullPolicyAlways:
+		return c.pullImage(ctx, image, stdout)
+	case types.PullPolicyNever:
+		return nil
+	}
+
+	_, _, err := c.api.ImageInspectWithRaw(ctx, image)
+	if err == nil {
+		return nil
+	}
+	if !client.IsErrNotFound(err) {
+		return fmt.Errorf("inspecting image %s: %v", image, err)
+	}
+	if svc.PullPolicy == types.PullPolicyBuild && svc.Build != nil {
+		return c.buildImage(ctx, image, svc, stdout)
+	}
+	return c.pullImage(ctx, image, stdout)
+}
+
+func (c *dcClient) pullImage(ctx context.Context, image string, stdout io.Writer) error {
+	ref, err := container.ParseNamed(image)
+	if err != nil {
+		return fmt.Errorf("parsing image %s: %v", image, err)
+	}
+	_, _ = fmt.Fprintf(stdout, "Pulling image %s\n", image)
+	_, err = c.images.ImagePull(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("pulling image %s: %v", image, err)
+	}
+	return nil
+}
+
+// Builds the service's image, like `docker compose build`.
+func (c *dcClient) buildImage(ctx context.Context, image string, svc types.ServiceConfig, stdout io.Writer) error {
+	cfg := svc.Build
+	_, _ = fmt.Fprintf(stdout, "Building image %s\n", image)
+
+	filter, err := dockerignore.NewDockerIgnoreTester(cfg.Context)
+	if err != nil {
+		return err
+	}
+	buildContext := build.TarArchiveForPaths(ctx, []build.PathMapping{
+		{LocalPath: cfg.Context, ContainerPath: "/"},
+	}, filter)
+	defer func() {
+		_ = buildContext.Close()
+	}()
+
+	// The Dockerfile is relative to the context.
+	dockerfile := cfg.Dockerfile
+	if filepath.IsAbs(dockerfile) {
+		dockerfile, err = filepath.Rel(cfg.Context, dockerfile)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.images.ImageBuild(ctx, buildContext, docker.BuildOptions{
+		Context:    buildContext,
+		Dockerfile: filepath.ToSlash(dockerfile),
+		Remove:     true,
+		BuildArgs:  cfg.Args,
+		Target:     cfg.Target,
+		Network:    cfg.Network,
+		CacheFrom:  cfg.CacheFrom,
+		PullParent: cfg.Pull,
+		Platform:   svc.Platform,
+		ExtraTags:  []string{image},
+	})
+	if err != nil {
+		return fmt.Errorf("building image %s: %v", image, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	err = jsonmessage.DisplayJSONMessagesStream(resp.Body, stdout, 0, false, nil)
+	if err != nil {
+		return fmt.Errorf("building image %s: %v", image, err)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package dockercompose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/compose-spec/compose-go/types"
+	dtypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+)
+
+// How often we check on the dependencies of a service while we wait for them.
+var dependencyPollInterval = 500 * time.Millisecond
+
+// Creates the networks that the service is attached to, unless they're external.
+func (c *dcClient) ensureNetworks(ctx context.Context, proj *types.Project, svc types.ServiceConfig, stdout io.Writer) error {
+	for key := range svc.Networks {
+		n, ok := proj.Networks[key]
+		if !ok {
+			return fmt.Errorf("service %s refers to undefined network %s", svc.Name, key)
+		}
+
+		existing, err := c.api.NetworkList(ctx, dtypes.NetworkListOptions{
+			Filters: filters.NewArgs(filters.Arg("name", n.Name)),
+		})
+		if err != nil {
+			return fmt.Errorf("listing networks: %v", err)
+		}
+		// The name filter matches on substrings.
+		found := false
+		for _, e := range existing {
+			if e.Name == n.Name {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if n.External.External {
+			return fmt.Errorf("network %s declared as external, but could not be found", n.Name)
+		}
+
+		create := dtypes.NetworkCreate{
+			CheckDuplicate: true,
+			Driver:         n.Driver,
+			Options:        n.DriverOpts,
+			Internal:       n.Internal,
+			Attachable:     n.Attachable,
+			EnableIPv6:     n.EnableIPv6,
+			Labels:         withLabels(n.Labels, proj.Name, networkLabel, key),
+		}
+		if n.Ipam.Driver != "" || len(n.Ipam.Config) > 0 {
+			create.IPAM = &network.IPAM{Driver: n.Ipam.Driver}
+			for _, pool := range n.Ipam.Config {
+				create.IPAM.Config = append(create.IPAM.Config, network.IPAMConfig{
+					Subnet:     pool.Subnet,
+					IPRange:    pool.IPRange,
+					Gateway:    pool.Gateway,
+					AuxAddress: pool.AuxiliaryAddresses,
+				})
+			}
+		}
+
+		_, err = c.api.NetworkCreate(ctx, n.Name, create)
+		if err != nil {
+			return fmt.Errorf("creating network %s: %v", n.Name, err)
+		}
+		_, _ = fmt.Fprintf(stdout, "Network %s Created\n", n.Name)
+	}
+	return nil
+}
+
+// Creates the named volumes that the service mounts, unless they're external.
+func (c *dcClient) ensureVolumes(ctx context.Context, proj *types.Project, svc types.ServiceConfig, stdout io.Writer) error {
+	for _, v := range svc.Volumes {
+		if v.Type != types.VolumeTypeVolume || v.Source == "" {
+			continue
+		}
+		vol, ok := proj.Volumes[v.Source]
+		if !ok {
+			return fmt.Errorf("service %s refers to undefined volume %s", svc.Name, v.Source)
+		}
+
+		_, err := c.api.VolumeInspect(ctx, vol.Name)
+		if err == nil {
+			continue
+		}
+		if !client.IsErrNotFound(err) {
+			return fmt.Errorf("inspecting volume %s: %v", vol.Name, err)
+		}
+		if vol.External.External {
+			return fmt.Errorf("volume %s declared as external, but could not be found", vol.Name)
+		}
+
+		_, err = c.api.VolumeCreate(ctx, volumetypes.VolumeCreateBody{
+			Name:       vol.Name,
+			Driver:     vol.Driver,
+			DriverOpts: vol.DriverOpts,
+			Labels:     withLabels(vol.Labels, proj.Name, volumeLabel, v.Source),
+		})
+		if err != nil {
+			return fmt.Errorf("creating volume %s: %v", vol.Name, err)
+		}
+		_, _ = fmt.Fprintf(stdout, "Volume %s Created\n", vol.Name)
+	}
+	return nil
+}
+
+func withLabels(labels types.Labels, projectName, key, value string) map[string]string {
+	result := map[string]string{}
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[projectLabel] = projectName
+	result[key] = value
+	return result
+}
+
+// Waits until the services that this service depends on meet their
+// depends_on condition, like `docker compose up`.
+//
+// Tilt starts each service on its own, so a dependency may not have a
+// container yet. We don't wait for those, like `docker compose up --no-deps`.
+func (c *dcClient) waitForDependencies(ctx context.Context, proj *types.Project, svc types.ServiceConfig, stdout io.Writer) error {
+	enabled := map[string]bool{}
+	for _, name := range proj.ServiceNames() {
+		enabled[name] = true
+	}
+
+	for _, dep := range dependencyNames(svc) {
+		if !enabled[dep] {
+			// Not in an active profile.
+			continue
+		}
+		condition := svc.DependsOn[dep].Condition
+		if condition == "" || condition == types.ServiceConditionStarted {
+			continue
+		}
+
+		containers, err := c.containersForService(ctx, proj.Name, dep)
+		if err != nil {
+			return err
+		}
+		if len(containers) == 0 {
+			_, _ = fmt.Fprintf(stdout, "Dependency %s has no container, not waiting for it\n", dep)
+			continue
+		}
+
+		err = c.waitForCondition(ctx, dep, containers[0].ID, condition, stdout)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *dcClient) waitForCondition(ctx context.Context, dep, containerID, condition string, stdout io.Writer) error {
+	waiting := false
+	for {
+		containerJSON, err := c.api.ContainerInspect(ctx, containerID)
+		if err != nil {
+			return fmt.Errorf("inspecting dependency %s: %v", dep, err)
+		}
+		done, err := dependencyConditionMet(dep, containerJSON, condition)
+		if err != nil || done {
+			return err
+		}
+
+		if !waiting {
+			waiting = true
+			_, _ = fmt.Fprintf(stdout, "Waiting for dependency %s (%s)\n", dep, condition)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(dependencyPollInterval):
+		}
+	}
+}
+
+// Whether the dependency's container meets the depends_on condition,
+// or an error if it never will.
+func dependencyConditionMet(dep string, containerJSON dtypes.ContainerJSON, condition string) (bool, error) {
+	if containerJSON.ContainerJSONBase == nil || containerJSON.State == nil {
+		return false, fmt.Errorf("dependency %s: no container state", dep)
+	}
+	state := containerJSON.State
+
+	switch condition {
+	case types.ServiceConditionHealthy:
+		if state.Health == nil {
+			return false, fmt.Errorf("dependency %s has no healthcheck configured", dep)
+		}
+		switch state.Health.Status {
+		case dtypes.Healthy:
+			return true, nil
+		case dtypes.Unhealthy:
+			return false, fmt.Errorf("dependency %s is unhealthy", dep)
+		}
+		if !state.Running && !state.Restarting {
+			return false, fmt.Errorf("dependency %s exited with code %d before it was healthy", dep, state.ExitCode)
+		}
+		return false, nil
+
+	case types.ServiceConditionCompletedSuccessfully:
+		if state.Running || state.Restarting || state.Status == ContainerStatusCreated {
+			return false, nil
+		}
+		if state.ExitCode != 0 {
+			return false, fmt.Errorf("dependency %s didn't complete successfully: exit %d", dep, state.ExitCode)
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("dependency %s: unknown depends_on condition: %s", dep, condition)
+}
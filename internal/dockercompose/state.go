@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -43,6 +44,15 @@ func (s State) RuntimeStatus() v1alpha1.RuntimeStatus {
 	if s.ContainerState.Error != "" || s.ContainerState.ExitCode != 0 {
 		return v1alpha1.RuntimeStatusError
 	}
+	if s.ContainerState.Running {
+		// A container with a healthcheck isn't ready until it's healthy.
+		switch s.ContainerState.HealthStatus {
+		case types.Unhealthy:
+			return v1alpha1.RuntimeStatusError
+		case types.Starting:
+			return v1alpha1.RuntimeStatusPending
+		}
+	}
 	if s.ContainerState.Running ||
 		s.ContainerState.Status == ContainerStatusRunning ||
 		s.ContainerState.Status == ContainerStatusExited {
@@ -65,6 +75,12 @@ func (s State) RuntimeStatusError() error {
 	if s.ContainerState.ExitCode != 0 {
 		return fmt.Errorf("Container %s exited with %d", s.ContainerID, s.ContainerState.ExitCode)
 	}
+	if s.ContainerState.HealthStatus == types.Unhealthy {
+		if s.ContainerState.HealthLog != "" {
+			return fmt.Errorf("Container %s is unhealthy: %s", s.ContainerID, s.ContainerState.HealthLog)
+		}
+		return fmt.Errorf("Container %s is unhealthy", s.ContainerID)
+	}
 	return fmt.Errorf("Container %s error status: %s", s.ContainerID, s.ContainerState.Status)
 }
 
@@ -122,7 +138,7 @@ func ToContainerState(state *types.ContainerState) *v1alpha1.DockerContainerStat
 		}
 	}
 
-	return &v1alpha1.DockerContainerState{
+	result := &v1alpha1.DockerContainerState{
 		Status:     state.Status,
 		Running:    state.Running,
 		Error:      state.Error,
@@ -130,6 +146,24 @@ func ToContainerState(state *types.ContainerState) *v1alpha1.DockerContainerStat
 		StartedAt:  metav1.NewMicroTime(startedAt),
 		FinishedAt: metav1.NewMicroTime(finishedAt),
 	}
+	if state.Health != nil {
+		result.HealthStatus = state.Health.Status
+		result.HealthLog = lastFailedHealthcheck(state.Health)
+	}
+	return result
+}
+
+// Docker keeps the last few healthcheck results. We only
+// need the output of the most recent one, if it failed.
+func lastFailedHealthcheck(health *types.Health) string {
+	if len(health.Log) == 0 {
+		return ""
+	}
+	last := health.Log[len(health.Log)-1]
+	if last == nil || last.ExitCode == 0 {
+		return ""
+	}
+	return strings.TrimSpace(last.Output)
 }
 
 // Convert a full into an apiserver-compatible status model.
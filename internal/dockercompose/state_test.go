@@ -0,0 +1,68 @@
+package dockercompose
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestToContainerStateHealth(t *testing.T) {
+	state := ToContainerState(&types.ContainerState{
+		Status:  ContainerStatusRunning,
+		Running: true,
+		Health: &types.Health{
+			Status: types.Unhealthy,
+			Log: []*types.HealthcheckResult{
+				{ExitCode: 0, Output: "ok"},
+				{ExitCode: 1, Output: "connection refused\n"},
+			},
+		},
+	})
+	assert.Equal(t, types.Unhealthy, state.HealthStatus)
+	assert.Equal(t, "connection refused", state.HealthLog)
+
+	state = ToContainerState(&types.ContainerState{
+		Status:  ContainerStatusRunning,
+		Running: true,
+		Health: &types.Health{
+			Status: types.Healthy,
+			Log:    []*types.HealthcheckResult{{ExitCode: 0, Output: "ok"}},
+		},
+	})
+	assert.Equal(t, types.Healthy, state.HealthStatus)
+	assert.Equal(t, "", state.HealthLog)
+}
+
+func TestRuntimeStatusHealth(t *testing.T) {
+	for _, tc := range []struct {
+		health   string
+		expected v1alpha1.RuntimeStatus
+	}{
+		{"", v1alpha1.RuntimeStatusOK},
+		{types.Starting, v1alpha1.RuntimeStatusPending},
+		{types.Healthy, v1alpha1.RuntimeStatusOK},
+		{types.Unhealthy, v1alpha1.RuntimeStatusError},
+	} {
+		t.Run(tc.health, func(t *testing.T) {
+			s := State{ContainerID: "abc"}.WithContainerState(v1alpha1.DockerContainerState{
+				Status:       ContainerStatusRunning,
+				Running:      true,
+				HealthStatus: tc.health,
+				HealthLog:    "connection refused",
+			})
+			assert.Equal(t, tc.expected, s.RuntimeStatus())
+			assert.Equal(t, tc.expected == v1alpha1.RuntimeStatusOK, s.HasEverBeenReadyOrSucceeded())
+		})
+	}
+
+	s := State{ContainerID: "abc"}.WithContainerState(v1alpha1.DockerContainerState{
+		Status:       ContainerStatusRunning,
+		Running:      true,
+		HealthStatus: types.Unhealthy,
+		HealthLog:    "connection refused",
+	})
+	assert.EqualError(t, s.RuntimeStatusError(), "Container abc is unhealthy: connection refused")
+}
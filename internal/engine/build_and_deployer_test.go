@@ -21,6 +21,7 @@ import (
 	ktypes "k8s.io/apimachinery/pkg/types"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
@@ -341,7 +342,7 @@ func newBDFixtureWithUpdateMode(t *testing.T, env clusterid.Product, runtime con
 	ctrlClient := fake.NewFakeTiltClient()
 	st := NewTestingStore(logs)
 	execer := localexec.NewFakeExecer(t)
-	bd, err := provideFakeBuildAndDeployer(ctx, dockerClient, k8s, dir, env, mode, dcc,
+	bd, err := provideFakeBuildAndDeployer(ctx, dockerClient, k8s, cluster.NewFakeClientProvider(t, ctrlClient), dir, env, mode, dcc,
 		fakeClock{now: time.Unix(1551202573, 0)}, kl, ta, ctrlClient, st, execer)
 	require.NoError(t, err)
 
@@ -506,3 +507,8 @@ func (kl *fakeKINDLoader) LoadToKIND(ctx context.Context, cluster *v1alpha1.Clus
 	kl.loadCount++
 	return nil
 }
+
+func (kl *fakeKINDLoader) LoadArchiveToKIND(ctx context.Context, cluster *v1alpha1.Cluster, path string) error {
+	kl.loadCount++
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/core/dockerimage"
 
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/buildstats"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -167,7 +168,7 @@ func (bd *DockerComposeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 		}
 
 		cluster := currentState[target.ID()].ClusterOrEmpty()
-		return bd.build(ctx, iTarget, cluster, imageMapSet, ps)
+		return bd.build(ctx, iTarget, cluster, imageMapSet, ps, buildTrigger(currentState[target.ID()]))
 	})
 
 	newResults := q.NewResults().ToBuildResultSet()
@@ -202,10 +203,11 @@ func (bd *DockerComposeBuildAndDeployer) build(
 	iTarget model.ImageTarget,
 	cluster *v1alpha1.Cluster,
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
-	ps *build.PipelineState) (store.ImageBuildResult, error) {
+	ps *build.PipelineState,
+	trigger buildstats.Trigger) (store.ImageBuildResult, error) {
 	switch iTarget.BuildDetails.(type) {
 	case model.DockerBuild:
-		return bd.dr.ForceApply(ctx, iTarget, cluster, imageMaps, ps)
+		return bd.dr.ForceApply(ctx, iTarget, cluster, imageMaps, ps, trigger)
 	case model.CustomBuild:
 		return bd.cr.ForceApply(ctx, iTarget, cluster, imageMaps, ps)
 	}
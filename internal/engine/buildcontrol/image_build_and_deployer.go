@@ -10,6 +10,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/buildstats"
 	"github.com/tilt-dev/tilt/internal/controllers/core/cmdimage"
 	"github.com/tilt-dev/tilt/internal/controllers/core/dockerimage"
 	"github.com/tilt-dev/tilt/internal/controllers/core/kubernetesapply"
@@ -128,7 +129,7 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 		}
 
 		cluster := stateSet[target.ID()].ClusterOrEmpty()
-		return ibd.build(ctx, iTarget, cluster, imageMapSet, ps)
+		return ibd.build(ctx, iTarget, cluster, imageMapSet, ps, buildTrigger(stateSet[target.ID()]))
 	})
 
 	newResults := q.NewResults().ToBuildResultSet()
@@ -151,16 +152,24 @@ func (ibd *ImageBuildAndDeployer) build(
 	iTarget model.ImageTarget,
 	cluster *v1alpha1.Cluster,
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
-	ps *build.PipelineState) (store.ImageBuildResult, error) {
+	ps *build.PipelineState,
+	trigger buildstats.Trigger) (store.ImageBuildResult, error) {
 	switch iTarget.BuildDetails.(type) {
 	case model.DockerBuild:
-		return ibd.dr.ForceApply(ctx, iTarget, cluster, imageMaps, ps)
+		return ibd.dr.ForceApply(ctx, iTarget, cluster, imageMaps, ps, trigger)
 	case model.CustomBuild:
 		return ibd.cr.ForceApply(ctx, iTarget, cluster, imageMaps, ps)
 	}
 	return store.ImageBuildResult{}, fmt.Errorf("invalid image spec")
 }
 
+func buildTrigger(state store.BuildState) buildstats.Trigger {
+	return buildstats.Trigger{
+		Reason:       state.BuildReason.String(),
+		ChangedFiles: state.FilesChanged(),
+	}
+}
+
 // Returns: the entities deployed and the namespace of the pod with the given image name/tag.
 func (ibd *ImageBuildAndDeployer) deploy(
 	ctx context.Context,
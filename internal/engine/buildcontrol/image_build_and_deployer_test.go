@@ -23,6 +23,7 @@ import (
 
 	"github.com/tilt-dev/clusterid"
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/k8s"
@@ -433,6 +434,51 @@ func TestKINDLoad(t *testing.T) {
 	assert.Equal(t, 0, f.docker.PushCount)
 }
 
+func TestKINDLoadDeclared(t *testing.T) {
+	f := newIBDFixture(t, clusterid.ProductUnknown)
+	f.cluster.Status.Connection.Kubernetes.ImageLoad = v1alpha1.ClusterImageLoadKind
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	_, err := f.BuildAndDeploy(BuildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 1, f.kl.loadCount)
+	assert.Equal(t, 0, f.docker.PushCount)
+}
+
+func TestDockerPushIfKINDAndRegistryDeclared(t *testing.T) {
+	f := newIBDFixture(t, clusterid.ProductKIND)
+	f.cluster.Status.Connection.Kubernetes.ImageLoad = v1alpha1.ClusterImageLoadRegistry
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	_, err := f.BuildAndDeploy(BuildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 0, f.kl.loadCount)
+	assert.Equal(t, 1, f.docker.PushCount)
+}
+
+func TestNoPushIfDockerImageLoadDeclared(t *testing.T) {
+	f := newIBDFixture(t, clusterid.ProductUnknown)
+	f.cluster.Status.Connection.Kubernetes.ImageLoad = v1alpha1.ClusterImageLoadDocker
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	_, err := f.BuildAndDeploy(BuildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 0, f.kl.loadCount)
+	assert.Equal(t, 0, f.docker.PushCount)
+}
+
 func TestDockerPushIfKINDAndClusterRef(t *testing.T) {
 	f := newIBDFixture(t, clusterid.ProductKIND)
 	f.cluster.Spec.DefaultRegistry = &v1alpha1.RegistryHosting{
@@ -1074,7 +1120,7 @@ func newIBDFixture(t *testing.T, env clusterid.Product) *ibdFixture {
 	ctrlClient := fake.NewFakeTiltClient()
 	st := store.NewTestingStore()
 	execer := localexec.NewFakeExecer(t)
-	ibd, err := ProvideImageBuildAndDeployer(ctx, dockerClient, kClient, env, kubeContext,
+	ibd, err := ProvideImageBuildAndDeployer(ctx, dockerClient, kClient, cluster.NewFakeClientProvider(t, ctrlClient), env, kubeContext,
 		clusterEnv, dir, clock, kl, ta, ctrlClient, st, execer)
 	if err != nil {
 		t.Fatal(err)
@@ -1210,6 +1256,11 @@ func (kl *fakeKINDLoader) LoadToKIND(ctx context.Context, cluster *v1alpha1.Clus
 	return nil
 }
 
+func (kl *fakeKINDLoader) LoadArchiveToKIND(ctx context.Context, cluster *v1alpha1.Cluster, path string) error {
+	kl.loadCount++
+	return nil
+}
+
 type fakeClock struct {
 	now time.Time
 }
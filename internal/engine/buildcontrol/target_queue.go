@@ -9,6 +9,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
@@ -18,7 +19,7 @@ type BuildHandler func(
 	target model.TargetSpec,
 	depResults []store.ImageBuildResult) (store.ImageBuildResult, error)
 
-type ReuseRefChecker func(ctx context.Context, iTarget model.ImageTarget, namedTagged reference.NamedTagged) (bool, error)
+type ReuseRefChecker func(ctx context.Context, iTarget model.ImageTarget, namedTagged reference.NamedTagged, cluster *v1alpha1.Cluster) (bool, error)
 
 // A little data structure to help iterate through dirty targets in dependency order.
 type TargetQueue struct {
@@ -69,7 +70,7 @@ func NewImageTargetQueue(ctx context.Context, iTargets []model.ImageTarget, stat
 			if err != nil {
 				return nil, errors.Wrapf(err, "parsing image")
 			}
-			ok, err := canReuseRef(ctx, target.(model.ImageTarget), imageRef)
+			ok, err := canReuseRef(ctx, target.(model.ImageTarget), imageRef, state[id].ClusterOrEmpty())
 			if err != nil {
 				return nil, errors.Wrapf(err, "error looking up whether last image built for %s exists", image)
 			}
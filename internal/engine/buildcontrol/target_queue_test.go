@@ -12,6 +12,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -230,7 +231,7 @@ func newTargetQueueFixture(t *testing.T) *targetQueueFixture {
 	}
 }
 
-func (f *targetQueueFixture) imageExists(ctx context.Context, iTarget model.ImageTarget, namedTagged reference.NamedTagged) (b bool, e error) {
+func (f *targetQueueFixture) imageExists(ctx context.Context, iTarget model.ImageTarget, namedTagged reference.NamedTagged, cluster *v1alpha1.Cluster) (b bool, e error) {
 	for _, ref := range f.missingImages {
 		if ref == namedTagged {
 			return false, nil
@@ -17,7 +17,9 @@ import (
 	"github.com/tilt-dev/clusterid"
 	"github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/buildstats"
 	"github.com/tilt-dev/tilt/internal/containerupdate"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/core/cmdimage"
 	"github.com/tilt-dev/tilt/internal/controllers/core/dockercomposeservice"
 	"github.com/tilt-dev/tilt/internal/controllers/core/dockerimage"
@@ -25,11 +27,13 @@ import (
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/liveupdates"
 	"github.com/tilt-dev/tilt/internal/tracer"
+	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 )
 
@@ -40,7 +44,10 @@ var BaseWireSet = wire.NewSet(
 	v1alpha1.NewScheme,
 	k8s.ProvideMinikubeClient,
 	build.NewDockerBuilder,
+	build.ProvideBuildkitHost,
+	build.NewBuildkitBuilder,
 	build.NewCustomBuilder,
+	buildstats.NewHistory,
 	wire.Bind(new(build.DockerKubeConnection), new(*build.DockerBuilder)),
 
 	// BuildOrder
@@ -60,6 +67,7 @@ func ProvideImageBuildAndDeployer(
 	ctx context.Context,
 	docker docker.Client,
 	kClient k8s.Client,
+	clients cluster.ClientProvider,
 	env clusterid.Product,
 	kubeContext k8s.KubeContext,
 	clusterEnv docker.ClusterEnv,
@@ -72,7 +80,9 @@ func ProvideImageBuildAndDeployer(
 	execer localexec.Execer) (*ImageBuildAndDeployer, error) {
 	wire.Build(
 		BaseWireSet,
+		provideFakeBase,
 		kubernetesapply.NewReconciler,
+		helm.NewReleaseClient,
 		dockerimage.NewReconciler,
 		cmdimage.NewReconciler,
 	)
@@ -90,6 +100,7 @@ func ProvideDockerComposeBuildAndDeployer(
 	dir *dirs.TiltDevDir) (*DockerComposeBuildAndDeployer, error) {
 	wire.Build(
 		BaseWireSet,
+		provideFakeBase,
 		dockercomposeservice.WireSet,
 		build.ProvideClock,
 		build.NewKINDLoader,
@@ -99,3 +110,7 @@ func ProvideDockerComposeBuildAndDeployer(
 
 	return nil, nil
 }
+
+func provideFakeBase(dir *dirs.TiltDevDir) xdg.Base {
+	return xdg.FakeBase{Dir: dir.Root()}
+}
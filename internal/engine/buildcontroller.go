@@ -230,6 +230,7 @@ func buildStateSet(ctx context.Context, manifest model.Manifest,
 
 		state := store.NewBuildState(status.LastResult, filesChanged, depsChanged)
 		state.Cluster = cluster
+		state.BuildReason = reason
 		result[id] = state
 	}
 
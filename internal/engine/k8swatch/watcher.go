@@ -7,7 +7,6 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store"
-	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -60,8 +59,7 @@ func (ks *watcherKnownState) createTaskList(state store.EngineState) watcherTask
 			continue
 		}
 
-		// TODO(milas): read the Cluster object name from the spec once available
-		clusterNN := types.NamespacedName{Name: v1alpha1.ClusterNameDefault}
+		clusterNN := types.NamespacedName{Name: mt.Manifest.ClusterName()}
 
 		name := mt.Manifest.Name
 
@@ -32,7 +32,9 @@ import (
 	"github.com/tilt-dev/clusterid"
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/buildstats"
 	"github.com/tilt-dev/tilt/internal/cloud"
+	"github.com/tilt-dev/tilt/internal/clusterprovision"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/containerupdate"
 	"github.com/tilt-dev/tilt/internal/controllers"
@@ -74,6 +76,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/engine/uiresource"
 	"github.com/tilt-dev/tilt/internal/engine/uisession"
 	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
 	"github.com/tilt-dev/tilt/internal/hud/server"
@@ -336,7 +339,7 @@ func (b *fakeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.RSto
 	}
 
 	iTargets := model.ExtractImageTargets(specs)
-	fakeImageExistsCheck := func(ctx context.Context, iTarget model.ImageTarget, namedTagged reference.NamedTagged) (bool, error) {
+	fakeImageExistsCheck := func(ctx context.Context, iTarget model.ImageTarget, namedTagged reference.NamedTagged, cluster *v1alpha1.Cluster) (bool, error) {
 		return true, nil
 	}
 	queue, err := buildcontrol.NewImageTargetQueue(ctx, iTargets, state, fakeImageExistsCheck)
@@ -3322,12 +3325,12 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 
 	clock := clockwork.NewRealClock()
 	env := clusterid.ProductDockerDesktop
-	podSource := podlogstream.NewPodSource(ctx, kClient, v1alpha1.NewScheme(), clock)
-	plsc := podlogstream.NewController(ctx, cdc, sch, st, kClient, podSource, clock)
+	podSource := podlogstream.NewPodSource(ctx, kClient, clusterClients, v1alpha1.NewScheme(), clock)
+	plsc := podlogstream.NewController(ctx, cdc, sch, st, podSource, clock)
 	au := engineanalytics.NewAnalyticsUpdater(ta, engineanalytics.CmdTags{}, engineMode)
 	ar := engineanalytics.ProvideAnalyticsReporter(ta, st, kClient, env, feature.MainDefaults)
 	fakeDcc := dockercompose.NewFakeDockerComposeClient(t, ctx)
-	k8sContextPlugin := k8scontext.NewPlugin("fake-context", env)
+	k8sContextPlugin := k8scontext.NewPlugin("fake-context", k8s.ProfileForProduct(env))
 	versionPlugin := version.NewPlugin(model.TiltBuild{Version: "0.5.0"})
 	configPlugin := config.NewPlugin("up")
 	execer := localexec.NewFakeExecer(t)
@@ -3336,7 +3339,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 		tiltextension.NewFakeExtRepoReconciler(f.Path()),
 		tiltextension.NewFakeExtReconciler(f.Path()))
 	realTFL := tiltfile.ProvideTiltfileLoader(ta, k8sContextPlugin, versionPlugin, configPlugin, extPlugin,
-		fakeDcc, "localhost", execer, feature.MainDefaults, env)
+		fakeDcc, "localhost", execer, feature.MainDefaults, env, base)
 	tfl := tiltfile.NewFakeTiltfileLoader()
 	cc := configs.NewConfigsController(cdc)
 	tqs := configs.NewTriggerQueueSubscriber(cdc)
@@ -3380,7 +3383,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 
 	wsl := server.NewWebsocketList()
 
-	kar := kubernetesapply.NewReconciler(cdc, kClient, sch, docker.Env{}, st, execer)
+	kar := kubernetesapply.NewReconciler(cdc, kClient, clusterClients, sch, docker.Env{}, st, execer, helm.NewFakeReleaseClient())
 	dcds := dockercomposeservice.NewDisableSubscriber(ctx, fakeDcc, clock)
 	dcr := dockercomposeservice.NewReconciler(cdc, fakeDcc, dockerClient, st, sch, dcds)
 
@@ -3394,14 +3397,16 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 	cu := &containerupdate.FakeContainerUpdater{}
 	lur := liveupdate.NewFakeReconciler(st, cu, cdc)
 	dockerBuilder := build.NewDockerBuilder(dockerClient, nil)
+	buildkitBuilder := build.NewBuildkitBuilder("")
 	customBuilder := build.NewCustomBuilder(dockerClient, clock)
 	kp := build.NewKINDLoader()
-	ib := build.NewImageBuilder(dockerBuilder, customBuilder, kp)
-	dir := dockerimage.NewReconciler(cdc, st, sch, dockerClient, ib)
+	ib := build.NewImageBuilder(dockerBuilder, buildkitBuilder, customBuilder, kp)
+	dir := dockerimage.NewReconciler(cdc, st, sch, dockerClient, ib, buildstats.NewHistory(base))
 	cir := cmdimage.NewReconciler(cdc, st, sch, dockerClient, ib)
 	clr := cluster.NewReconciler(ctx, cdc, st, clock, clusterClients, docker.LocalEnv{},
 		cluster.FakeDockerClientOrError(dockerClient, nil),
 		cluster.FakeKubernetesClientOrError(kClient, nil),
+		clusterprovision.NewFakeProvisioner(),
 		wsl, base, "tilt-default")
 	dclsr := dockercomposelogstream.NewReconciler(cdc, st)
 
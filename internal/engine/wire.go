@@ -19,6 +19,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/core/cmd"
 	"github.com/tilt-dev/tilt/internal/controllers/core/cmdimage"
 	"github.com/tilt-dev/tilt/internal/controllers/core/dockercomposeservice"
@@ -27,10 +28,12 @@ import (
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/liveupdates"
+	"github.com/tilt-dev/tilt/internal/xdg"
 )
 
 var DeployerBaseWireSet = wire.NewSet(
@@ -57,6 +60,7 @@ func provideFakeBuildAndDeployer(
 	ctx context.Context,
 	docker docker.Client,
 	kClient k8s.Client,
+	clients cluster.ClientProvider,
 	dir *dirs.TiltDevDir,
 	env clusterid.Product,
 	updateMode liveupdates.UpdateModeFlag,
@@ -72,7 +76,9 @@ func provideFakeBuildAndDeployer(
 		k8s.ProvideContainerRuntime,
 		provideFakeKubeContext,
 		provideFakeDockerClusterEnv,
+		provideFakeBase,
 		kubernetesapply.NewReconciler,
+		helm.NewReleaseClient,
 		dockerimage.NewReconciler,
 		cmdimage.NewReconciler,
 		dockercomposeservice.WireSet,
@@ -88,6 +94,10 @@ func provideFakeEnv() *localexec.Env {
 	return localexec.EmptyEnv()
 }
 
+func provideFakeBase(dir *dirs.TiltDevDir) xdg.Base {
+	return xdg.FakeBase{Dir: dir.Root()}
+}
+
 func provideFakeKubeContext(env clusterid.Product) k8s.KubeContext {
 	return k8s.KubeContext(string(env))
 }
@@ -0,0 +1,120 @@
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+type ChartRefKind int
+
+const (
+	// A chart directory or packaged chart on disk.
+	ChartRefLocal ChartRefKind = iota
+
+	// A chart in an OCI registry, e.g., oci://ghcr.io/org/charts/app
+	ChartRefOCI
+
+	// A chart in a chart repository from the user's Helm config,
+	// e.g., bitnami/nginx
+	ChartRefRepo
+
+	// A chart in the chart repository at RepoURL, like
+	// `helm pull --repo https://charts.bitnami.com/bitnami nginx`
+	ChartRefRepoURL
+)
+
+// A chart to render.
+type ChartRef struct {
+	Kind ChartRefKind
+
+	// For local charts, the absolute path. Otherwise, the chart's URL or
+	// repo/chart name, without the version.
+	Name string
+
+	// A version or semver range. If empty, the latest version.
+	// Ignored for local charts.
+	Version string
+
+	// For ChartRefRepoURL, the URL of the chart repository.
+	RepoURL string
+}
+
+// Matches repo/chart, with an optional @version.
+var repoChartRefRE = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*/[a-zA-Z0-9][a-zA-Z0-9._-]*(@\S+)?$`)
+
+func NewLocalChartRef(path string) ChartRef {
+	return ChartRef{Kind: ChartRefLocal, Name: path}
+}
+
+// Parses a reference to a remote chart, like oci://ghcr.io/org/charts/app@1.2.3
+// or bitnami/nginx@13.2.0.
+//
+// Returns false if s isn't shaped like a remote chart. Note that repo/chart
+// refs look like relative paths, so callers should check for a local chart
+// first.
+func ParseRemoteChartRef(s string) (ChartRef, bool) {
+	if registry.IsOCI(s) {
+		name, version := splitVersion(strings.TrimPrefix(s, registry.OCIScheme+"://"))
+		return ChartRef{Kind: ChartRefOCI, Name: registry.OCIScheme + "://" + name, Version: version}, true
+	}
+
+	if repoChartRefRE.MatchString(s) {
+		name, version := splitVersion(s)
+		return ChartRef{Kind: ChartRefRepo, Name: name, Version: version}, true
+	}
+
+	return ChartRef{}, false
+}
+
+// A chart in the repository at repoURL, as in a kustomization's helmCharts.
+func NewRepoURLChartRef(repoURL, name, version string) ChartRef {
+	if registry.IsOCI(repoURL) {
+		return ChartRef{Kind: ChartRefOCI, Name: strings.TrimSuffix(repoURL, "/") + "/" + name, Version: version}
+	}
+	return ChartRef{Kind: ChartRefRepoURL, Name: name, Version: version, RepoURL: repoURL}
+}
+
+func splitVersion(s string) (string, string) {
+	i := strings.LastIndex(s, "@")
+	if i == -1 {
+		return s, ""
+	}
+	return s[:i], s[i+1:]
+}
+
+func (r ChartRef) String() string {
+	name := r.Name
+	if r.Kind == ChartRefRepoURL {
+		name = strings.TrimSuffix(r.RepoURL, "/") + "/" + r.Name
+	}
+	if r.Version == "" || r.Kind == ChartRefLocal {
+		return name
+	}
+	return fmt.Sprintf("%s@%s", name, r.Version)
+}
+
+// The repository name of a repo/chart ref.
+func (r ChartRef) repoName() string {
+	return strings.SplitN(r.Name, "/", 2)[0]
+}
+
+// Whether the version names exactly one chart, so that once we've
+// downloaded it, we never need to download it again.
+func (r ChartRef) hasExactVersion() bool {
+	if r.Version == "" {
+		return false
+	}
+	_, err := semver.StrictNewVersion(strings.TrimPrefix(r.Version, "v"))
+	return err == nil
+}
+
+// Loads a chart directory or packaged chart from disk.
+func LoadChart(path string) (*chart.Chart, error) {
+	return loader.Load(path)
+}
@@ -0,0 +1,51 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRemoteChartRef(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected ChartRef
+		ok       bool
+	}{
+		{"oci://ghcr.io/org/charts/app", ChartRef{Kind: ChartRefOCI, Name: "oci://ghcr.io/org/charts/app"}, true},
+		{"oci://ghcr.io/org/charts/app@1.2.3", ChartRef{Kind: ChartRefOCI, Name: "oci://ghcr.io/org/charts/app", Version: "1.2.3"}, true},
+		{"oci://localhost:5000/app@^1.0", ChartRef{Kind: ChartRefOCI, Name: "oci://localhost:5000/app", Version: "^1.0"}, true},
+		{"bitnami/nginx", ChartRef{Kind: ChartRefRepo, Name: "bitnami/nginx"}, true},
+		{"bitnami/nginx@13.2.0", ChartRef{Kind: ChartRefRepo, Name: "bitnami/nginx", Version: "13.2.0"}, true},
+		{"helm", ChartRef{}, false},
+		{"./helm/chart", ChartRef{}, false},
+		{"/abs/chart", ChartRef{}, false},
+		{"deploy/helm/chart", ChartRef{}, false},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			ref, ok := ParseRemoteChartRef(tc.input)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, ref)
+			if ok {
+				assert.Equal(t, tc.input, ref.String())
+			}
+		})
+	}
+}
+
+func TestChartRefHasExactVersion(t *testing.T) {
+	assert.True(t, ChartRef{Kind: ChartRefRepo, Name: "a/b", Version: "1.2.3"}.hasExactVersion())
+	assert.True(t, ChartRef{Kind: ChartRefRepo, Name: "a/b", Version: "v1.2.3"}.hasExactVersion())
+	assert.False(t, ChartRef{Kind: ChartRefRepo, Name: "a/b", Version: "^1.2"}.hasExactVersion())
+	assert.False(t, ChartRef{Kind: ChartRefRepo, Name: "a/b", Version: "1.2"}.hasExactVersion())
+	assert.False(t, ChartRef{Kind: ChartRefRepo, Name: "a/b"}.hasExactVersion())
+}
+
+func TestNewRepoURLChartRef(t *testing.T) {
+	ref := NewRepoURLChartRef("https://charts.bitnami.com/bitnami/", "nginx", "13.2.0")
+	assert.Equal(t, ChartRef{Kind: ChartRefRepoURL, Name: "nginx", Version: "13.2.0", RepoURL: "https://charts.bitnami.com/bitnami/"}, ref)
+	assert.Equal(t, "https://charts.bitnami.com/bitnami/nginx@13.2.0", ref.String())
+
+	ref = NewRepoURLChartRef("oci://ghcr.io/org/charts", "app", "")
+	assert.Equal(t, ChartRef{Kind: ChartRefOCI, Name: "oci://ghcr.io/org/charts/app"}, ref)
+}
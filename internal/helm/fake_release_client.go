@@ -0,0 +1,90 @@
+package helm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type FakeUpgradeCall struct {
+	Kube    KubeConfig
+	Options ReleaseOptions
+}
+
+type FakeUninstallCall struct {
+	Kube      KubeConfig
+	Name      string
+	Namespace string
+}
+
+// A ReleaseClient that records calls, and keeps releases in memory.
+type FakeReleaseClient struct {
+	mu sync.Mutex
+
+	UpgradeCalls   []FakeUpgradeCall
+	UninstallCalls []FakeUninstallCall
+
+	// Returned by the next Upgrade, which then records a failed revision.
+	UpgradeErr error
+
+	// The objects that Upgrade reports for each release.
+	Objects map[string][]runtime.Object
+
+	revisions map[string]int
+}
+
+var _ ReleaseClient = &FakeReleaseClient{}
+
+func NewFakeReleaseClient() *FakeReleaseClient {
+	return &FakeReleaseClient{
+		Objects:   make(map[string][]runtime.Object),
+		revisions: make(map[string]int),
+	}
+}
+
+func (c *FakeReleaseClient) Upgrade(ctx context.Context, kube KubeConfig, opts ReleaseOptions) (*Release, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.UpgradeCalls = append(c.UpgradeCalls, FakeUpgradeCall{Kube: kube, Options: opts})
+
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	c.revisions[opts.ReleaseName]++
+	rel := &Release{
+		Name:      opts.ReleaseName,
+		Namespace: namespace,
+		Revision:  c.revisions[opts.ReleaseName],
+		Status:    "deployed",
+	}
+
+	if c.UpgradeErr != nil {
+		err := c.UpgradeErr
+		c.UpgradeErr = nil
+		rel.Status = "failed"
+		return rel, err
+	}
+
+	rel.Objects = c.Objects[opts.ReleaseName]
+	return rel, nil
+}
+
+func (c *FakeReleaseClient) Uninstall(ctx context.Context, kube KubeConfig, name, namespace string, timeout time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.UninstallCalls = append(c.UninstallCalls, FakeUninstallCall{Kube: kube, Name: name, Namespace: namespace})
+	delete(c.revisions, name)
+	return nil
+}
+
+// The latest revision of the release, or 0 if it's not installed.
+func (c *FakeReleaseClient) Revision(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.revisions[name]
+}
@@ -0,0 +1,207 @@
+package helm
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// Downloads remote charts, and keeps them in a local cache.
+//
+// A chart with an exact version is only downloaded once. A chart with a
+// version range, or no version, is resolved again on every fetch, so that
+// it picks up new releases.
+//
+// Credentials and repositories come from the user's Helm config
+// (e.g., ~/.config/helm/repositories.yaml), as they would for the helm CLI.
+type ChartFetcher struct {
+	dir      string
+	settings *cli.EnvSettings
+	download ChartDownloadFunc
+}
+
+// Downloads a remote chart into cacheDir, and returns the path
+// of the packaged chart.
+type ChartDownloadFunc func(ref ChartRef, cacheDir string) (string, error)
+
+func NewChartFetcher(dir string) *ChartFetcher {
+	f := &ChartFetcher{dir: dir, settings: cli.New()}
+	f.download = f.downloadChart
+	return f
+}
+
+// A ChartFetcher that downloads remote charts with the given function
+// instead of the network, e.g., in tests.
+func NewChartFetcherWithDownload(dir string, download ChartDownloadFunc) *ChartFetcher {
+	return &ChartFetcher{dir: dir, settings: cli.New(), download: download}
+}
+
+// Returns the path of the packaged chart.
+func (f *ChartFetcher) Fetch(ref ChartRef) (string, error) {
+	if ref.Kind == ChartRefLocal {
+		return ref.Name, nil
+	}
+
+	cacheDir := filepath.Join(f.dir, cacheKey(ref))
+	if ref.hasExactVersion() {
+		path, ok, err := findPackagedChart(cacheDir)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return path, nil
+		}
+	}
+
+	path, err := f.download(ref, cacheDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching chart %s", ref)
+	}
+	return path, nil
+}
+
+func (f *ChartFetcher) downloadChart(ref ChartRef, cacheDir string) (string, error) {
+	registryClient, err := registry.NewClient(
+		registry.ClientOptCredentialsFile(f.settings.RegistryConfig),
+		registry.ClientOptWriter(io.Discard))
+	if err != nil {
+		return "", err
+	}
+
+	dl := downloader.ChartDownloader{
+		Out:              io.Discard,
+		Verify:           downloader.VerifyNever,
+		Getters:          getter.All(f.settings),
+		RegistryClient:   registryClient,
+		RepositoryConfig: f.settings.RepositoryConfig,
+		RepositoryCache:  f.settings.RepositoryCache,
+	}
+	if ref.Kind == ChartRefOCI {
+		dl.Options = append(dl.Options, getter.WithRegistryClient(registryClient))
+	}
+
+	// Download to a temp dir and move it into place, so that concurrent
+	// fetches never see half a chart.
+	err = os.MkdirAll(f.dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	tmpDir, err := os.MkdirTemp(f.dir, ".download-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	var path string
+	switch ref.Kind {
+	case ChartRefRepo:
+		path, err = f.downloadFromRepo(dl, ref, tmpDir)
+	case ChartRefRepoURL:
+		// The repo is given by URL, so it doesn't need to be in the
+		// user's Helm config, or have an index in their cache.
+		dl.RepositoryConfig = ""
+		var chartURL string
+		chartURL, err = repo.FindChartInRepoURL(ref.RepoURL, ref.Name, ref.Version, "", "", "", getter.All(f.settings))
+		if err == nil {
+			path, _, err = dl.DownloadTo(chartURL, ref.Version, tmpDir)
+		}
+	default:
+		path, _, err = dl.DownloadTo(ref.Name, ref.Version, tmpDir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = os.RemoveAll(cacheDir)
+	if err != nil {
+		return "", err
+	}
+	err = os.Rename(tmpDir, cacheDir)
+	if err != nil {
+		// Another fetch of the same chart may have won the race.
+		if path, ok, _ := findPackagedChart(cacheDir); ok {
+			return path, nil
+		}
+		return "", err
+	}
+	return filepath.Join(cacheDir, filepath.Base(path)), nil
+}
+
+// Charts in a repository are found through the repository's index. We
+// download the index if we don't have it yet, and download it again if the
+// chart isn't in it, in case the chart is newer than our copy.
+func (f *ChartFetcher) downloadFromRepo(dl downloader.ChartDownloader, ref ChartRef, dest string) (string, error) {
+	repoFile, err := repo.LoadFile(f.settings.RepositoryConfig)
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return "", err
+	}
+	entry := repoFile.Get(ref.repoName())
+	if entry == nil {
+		return "", fmt.Errorf("no Helm repository named %q in %s. Add it with `helm repo add`, or use an oci:// chart",
+			ref.repoName(), f.settings.RepositoryConfig)
+	}
+
+	indexPath := filepath.Join(f.settings.RepositoryCache, helmpath.CacheIndexFile(entry.Name))
+	_, err = os.Stat(indexPath)
+	if os.IsNotExist(err) {
+		err = f.updateRepoIndex(entry)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	path, _, err := dl.DownloadTo(ref.Name, ref.Version, dest)
+	if err == nil {
+		return path, nil
+	}
+
+	updateErr := f.updateRepoIndex(entry)
+	if updateErr != nil {
+		return "", err
+	}
+	path, _, err = dl.DownloadTo(ref.Name, ref.Version, dest)
+	return path, err
+}
+
+func (f *ChartFetcher) updateRepoIndex(entry *repo.Entry) error {
+	r, err := repo.NewChartRepository(entry, getter.All(f.settings))
+	if err != nil {
+		return err
+	}
+	r.CachePath = f.settings.RepositoryCache
+	_, err = r.DownloadIndexFile()
+	if err != nil {
+		return errors.Wrapf(err, "updating index of Helm repository %q", entry.Name)
+	}
+	return nil
+}
+
+var unsafePathCharsRE = regexp.MustCompile(`[^a-zA-Z0-9._@-]+`)
+
+// A directory name for the chart in the cache.
+func cacheKey(ref ChartRef) string {
+	return unsafePathCharsRE.ReplaceAllString(ref.String(), "_")
+}
+
+func findPackagedChart(dir string) (string, bool, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	if err != nil {
+		return "", false, err
+	}
+	if len(matches) != 1 {
+		return "", false, nil
+	}
+	return matches[0], true, nil
+}
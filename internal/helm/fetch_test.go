@@ -0,0 +1,162 @@
+package helm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestFetchRepoChart(t *testing.T) {
+	f := newFetchFixture(t)
+	f.publish("1.0.0")
+
+	path, err := f.fetcher.Fetch(ChartRef{Kind: ChartRefRepo, Name: "test/hello", Version: "1.0.0"})
+	require.NoError(t, err)
+	ch, err := LoadChart(path)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", ch.Metadata.Version)
+
+	// An exact version comes from the cache the second time.
+	requests := f.requests()
+	path2, err := f.fetcher.Fetch(ChartRef{Kind: ChartRefRepo, Name: "test/hello", Version: "1.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, path, path2)
+	assert.Equal(t, requests, f.requests())
+}
+
+func TestFetchRepoChartLatest(t *testing.T) {
+	f := newFetchFixture(t)
+	f.publish("1.0.0")
+
+	path, err := f.fetcher.Fetch(ChartRef{Kind: ChartRefRepo, Name: "test/hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello-1.0.0.tgz", filepath.Base(path))
+
+	// Without an exact version, we check the repo again.
+	requests := f.requests()
+	_, err = f.fetcher.Fetch(ChartRef{Kind: ChartRefRepo, Name: "test/hello"})
+	require.NoError(t, err)
+	assert.Greater(t, f.requests(), requests)
+}
+
+func TestFetchRepoChartNewerThanIndex(t *testing.T) {
+	f := newFetchFixture(t)
+	f.publish("1.0.0")
+
+	_, err := f.fetcher.Fetch(ChartRef{Kind: ChartRefRepo, Name: "test/hello", Version: "1.0.0"})
+	require.NoError(t, err)
+
+	// Our copy of the index doesn't have 1.1.0 yet.
+	f.publish("1.1.0")
+	path, err := f.fetcher.Fetch(ChartRef{Kind: ChartRefRepo, Name: "test/hello", Version: "1.1.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello-1.1.0.tgz", filepath.Base(path))
+}
+
+func TestFetchRepoChartMissingVersion(t *testing.T) {
+	f := newFetchFixture(t)
+	f.publish("1.0.0")
+
+	_, err := f.fetcher.Fetch(ChartRef{Kind: ChartRefRepo, Name: "test/hello", Version: "2.0.0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fetching chart test/hello@2.0.0")
+}
+
+func TestFetchRepoURLChart(t *testing.T) {
+	f := newFetchFixture(t)
+	f.publish("1.0.0")
+
+	// The repo doesn't need to be in the Helm config.
+	ref := NewRepoURLChartRef(f.server.URL, "hello", "1.0.0")
+	path, err := f.fetcher.Fetch(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "hello-1.0.0.tgz", filepath.Base(path))
+
+	requests := f.requests()
+	path2, err := f.fetcher.Fetch(ref)
+	require.NoError(t, err)
+	assert.Equal(t, path, path2)
+	assert.Equal(t, requests, f.requests())
+}
+
+func TestFetchUnknownRepo(t *testing.T) {
+	f := newFetchFixture(t)
+
+	_, err := f.fetcher.Fetch(ChartRef{Kind: ChartRefRepo, Name: "nope/hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no Helm repository named "nope"`)
+}
+
+type fetchFixture struct {
+	*tempdir.TempDirFixture
+	t        *testing.T
+	server   *httptest.Server
+	count    int64
+	index    *repo.IndexFile
+	fetcher  *ChartFetcher
+	settings *cli.EnvSettings
+}
+
+func newFetchFixture(t *testing.T) *fetchFixture {
+	f := &fetchFixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		t:              t,
+		index:          repo.NewIndexFile(),
+	}
+
+	fileServer := http.FileServer(http.Dir(f.JoinPath("server")))
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&f.count, 1)
+		fileServer.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	require.NoError(t, os.MkdirAll(f.JoinPath("server"), 0755))
+	require.NoError(t, f.index.WriteFile(f.JoinPath("server", "index.yaml"), 0644))
+
+	f.settings = &cli.EnvSettings{
+		RepositoryConfig: f.JoinPath("helm", "repositories.yaml"),
+		RepositoryCache:  f.JoinPath("helm", "cache"),
+		RegistryConfig:   f.JoinPath("helm", "registry.json"),
+	}
+	repoFile := repo.NewFile()
+	repoFile.Update(&repo.Entry{Name: "test", URL: f.server.URL})
+	require.NoError(t, os.MkdirAll(f.JoinPath("helm"), 0755))
+	require.NoError(t, repoFile.WriteFile(f.settings.RepositoryConfig, 0644))
+
+	f.fetcher = &ChartFetcher{dir: f.JoinPath("charts"), settings: f.settings}
+	f.fetcher.download = f.fetcher.downloadChart
+	return f
+}
+
+// Adds a version of the hello chart to the repo.
+func (f *fetchFixture) publish(version string) {
+	newTestChart(f.TempDirFixture, "src/"+version)
+	ch, err := LoadChart(f.JoinPath("src", version))
+	require.NoError(f.t, err)
+	ch.Metadata.Version = version
+
+	path, err := chartutil.Save(ch, f.JoinPath("server"))
+	require.NoError(f.t, err)
+	digest, err := provenance.DigestFile(path)
+	require.NoError(f.t, err)
+
+	require.NoError(f.t, f.index.MustAdd(ch.Metadata, filepath.Base(path), f.server.URL, digest))
+	f.index.SortEntries()
+	require.NoError(f.t, f.index.WriteFile(f.JoinPath("server", "index.yaml"), 0644))
+}
+
+func (f *fetchFixture) requests() int64 {
+	return atomic.LoadInt64(&f.count)
+}
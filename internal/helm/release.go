@@ -0,0 +1,307 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Helm's default timeout for hooks and waits.
+const DefaultReleaseTimeout = 5 * time.Minute
+
+// The cluster that a release lives on.
+//
+// Empty fields fall back to the same defaults as kubectl.
+type KubeConfig struct {
+	// Path to the kubeconfig file.
+	Path string
+
+	// The kubeconfig context.
+	Context string
+}
+
+type ReleaseOptions struct {
+	ReleaseName string
+
+	// Defaults to the namespace of the kubeconfig context.
+	Namespace string
+
+	// Path to a chart directory or packaged chart.
+	Chart string
+
+	// Values files, applied in order after the chart's values.yaml.
+	ValueFiles []string
+
+	// Values in --set syntax, e.g., "image.tag=dev".
+	Set []string
+
+	// Values in --set-string syntax. Applied last.
+	SetString []string
+
+	CreateNamespace bool
+
+	// Roll back a failed upgrade to the last deployed revision, and uninstall
+	// a failed install, like `helm upgrade --atomic`. Also waits for the
+	// release's objects to be ready.
+	Atomic bool
+
+	// How long to wait for each hook. Defaults to DefaultReleaseTimeout.
+	Timeout time.Duration
+}
+
+// A revision of a release.
+type Release struct {
+	Name      string
+	Namespace string
+	Revision  int
+
+	// e.g., "deployed" or "failed".
+	Status string
+
+	// The hooks that ran, e.g., "Job/db-migrate: pre-upgrade Succeeded".
+	Hooks []string
+
+	// The objects in the release, as they are on the cluster.
+	Objects []runtime.Object
+}
+
+// Manages releases on a cluster with the Helm SDK.
+//
+// Releases are stored the same way the helm CLI stores them (secrets, unless
+// HELM_DRIVER says otherwise), so `helm list` and `helm history` work on
+// releases that Tilt deployed.
+type ReleaseClient interface {
+	// Installs the release if it doesn't exist yet, and upgrades it otherwise,
+	// like `helm upgrade --install`. Waits for hooks to finish.
+	//
+	// If the install or upgrade fails after Helm recorded a new revision,
+	// returns the failed revision along with the error. With Atomic, returns
+	// the revision that the release was rolled back to instead (or nil, if the
+	// failed install was uninstalled).
+	//
+	// A release left pending by an interrupted install or upgrade is marked
+	// failed first, so that it doesn't block the upgrade.
+	Upgrade(ctx context.Context, kube KubeConfig, opts ReleaseOptions) (*Release, error)
+
+	// Uninstalls the release. Does nothing if the release doesn't exist.
+	Uninstall(ctx context.Context, kube KubeConfig, name, namespace string, timeout time.Duration) error
+}
+
+type releaseClient struct {
+	// Creates the Helm action config, and resolves the namespace.
+	// Replaced in tests.
+	newActionConfig func(ctx context.Context, kube KubeConfig, namespace string) (*action.Configuration, string, error)
+}
+
+var _ ReleaseClient = releaseClient{}
+
+func NewReleaseClient() ReleaseClient {
+	return releaseClient{newActionConfig: newActionConfig}
+}
+
+func newActionConfig(ctx context.Context, kube KubeConfig, namespace string) (*action.Configuration, string, error) {
+	flags := genericclioptions.NewConfigFlags(false)
+	if kube.Path != "" {
+		flags.KubeConfig = &kube.Path
+	}
+	if kube.Context != "" {
+		flags.Context = &kube.Context
+	}
+	if namespace == "" {
+		ns, _, err := flags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return nil, "", err
+		}
+		namespace = ns
+	}
+	flags.Namespace = &namespace
+
+	cfg := &action.Configuration{}
+	l := logger.Get(ctx)
+	err := cfg.Init(flags, namespace, os.Getenv("HELM_DRIVER"), func(format string, v ...interface{}) {
+		l.Debugf(format, v...)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, namespace, nil
+}
+
+func (c releaseClient) Upgrade(ctx context.Context, kube KubeConfig, opts ReleaseOptions) (*Release, error) {
+	ch, err := LoadChart(opts.Chart)
+	if err != nil {
+		return nil, err
+	}
+
+	if ch.Metadata.Dependencies != nil {
+		err := action.CheckDependencies(ch, ch.Metadata.Dependencies)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	vals, err := (&values.Options{
+		ValueFiles:   opts.ValueFiles,
+		Values:       opts.Set,
+		StringValues: opts.SetString,
+	}).MergeValues(getter.Providers{})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, namespace, err := c.newActionConfig(ctx, kube, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultReleaseTimeout
+	}
+
+	history := action.NewHistory(cfg)
+	history.Max = 1
+	_, err = history.Run(opts.ReleaseName)
+
+	var rel *release.Release
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		install := action.NewInstall(cfg)
+		install.ReleaseName = opts.ReleaseName
+		install.Namespace = namespace
+		install.CreateNamespace = opts.CreateNamespace
+		install.Atomic = opts.Atomic
+		install.Timeout = timeout
+		rel, err = install.RunWithContext(ctx, ch, vals)
+	} else if err != nil {
+		return nil, err
+	} else {
+		err = recoverPendingRelease(ctx, cfg, opts.ReleaseName)
+		if err != nil {
+			return nil, err
+		}
+
+		upgrade := action.NewUpgrade(cfg)
+		upgrade.Namespace = namespace
+		upgrade.Atomic = opts.Atomic
+		upgrade.Timeout = timeout
+		rel, err = upgrade.RunWithContext(ctx, opts.ReleaseName, ch, vals)
+	}
+
+	if err != nil && opts.Atomic {
+		// The failed revision was rolled back or uninstalled,
+		// so report what's on the cluster now.
+		rel, _ = cfg.Releases.Last(opts.ReleaseName)
+	}
+
+	if rel == nil {
+		return nil, err
+	}
+
+	result := toRelease(rel)
+	if err != nil {
+		return result, err
+	}
+
+	result.Objects, err = liveObjects(cfg, rel)
+	if err != nil {
+		return result, errors.Wrap(err, "reading release objects")
+	}
+	return result, nil
+}
+
+// An install or upgrade that's interrupted (e.g., because Tilt exited)
+// leaves the release pending, and Helm refuses to upgrade a pending release.
+//
+// Tilt runs one operation at a time on each of its releases, so a pending
+// release was interrupted. Mark it failed, so that the upgrade can fix it.
+func recoverPendingRelease(ctx context.Context, cfg *action.Configuration, name string) error {
+	last, err := cfg.Releases.Last(name)
+	if err != nil {
+		return err
+	}
+	if last.Info == nil || !last.Info.Status.IsPending() {
+		return nil
+	}
+
+	logger.Get(ctx).Warnf("Release %s revision %d was left %s by an interrupted operation. Marking it failed.",
+		name, last.Version, last.Info.Status)
+	last.SetStatus(release.StatusFailed, fmt.Sprintf("Interrupted while %s", last.Info.Status))
+	err = cfg.Releases.Update(last)
+	if err != nil {
+		return errors.Wrapf(err, "recovering pending release %s", name)
+	}
+	return nil
+}
+
+func (c releaseClient) Uninstall(ctx context.Context, kube KubeConfig, name, namespace string, timeout time.Duration) error {
+	cfg, _, err := c.newActionConfig(ctx, kube, namespace)
+	if err != nil {
+		return err
+	}
+
+	uninstall := action.NewUninstall(cfg)
+	uninstall.Timeout = timeout
+	if uninstall.Timeout == 0 {
+		uninstall.Timeout = DefaultReleaseTimeout
+	}
+	_, err = uninstall.Run(name)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil
+	}
+	return err
+}
+
+func toRelease(rel *release.Release) *Release {
+	result := &Release{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+	}
+	if rel.Info != nil {
+		result.Status = rel.Info.Status.String()
+	}
+	for _, h := range rel.Hooks {
+		if isTestHook(h) || h.LastRun.Phase == "" || h.LastRun.Phase == release.HookPhaseUnknown {
+			continue
+		}
+		events := make([]string, 0, len(h.Events))
+		for _, e := range h.Events {
+			events = append(events, e.String())
+		}
+		result.Hooks = append(result.Hooks,
+			fmt.Sprintf("%s/%s: %s %s", h.Kind, h.Name, strings.Join(events, ","), h.LastRun.Phase))
+	}
+	return result
+}
+
+// Reads the objects in the release from the cluster, so that they have UIDs.
+func liveObjects(cfg *action.Configuration, rel *release.Release) ([]runtime.Object, error) {
+	resources, err := cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]runtime.Object, 0, len(resources))
+	for _, info := range resources {
+		err := info.Get()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, info.Object)
+	}
+	return result, nil
+}
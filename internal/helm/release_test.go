@@ -0,0 +1,248 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestUpgradeInstallsThenUpgrades(t *testing.T) {
+	f := newReleaseFixture(t)
+
+	rel, err := f.client.Upgrade(context.Background(), KubeConfig{}, f.opts())
+	require.NoError(t, err)
+	assert.Equal(t, "rose-quartz", rel.Name)
+	assert.Equal(t, "garnet", rel.Namespace)
+	assert.Equal(t, 1, rel.Revision)
+	assert.Equal(t, "deployed", rel.Status)
+	assert.Equal(t, []string{"Job/rose-quartz-migrate: pre-install Succeeded"}, rel.Hooks)
+
+	rel, err = f.client.Upgrade(context.Background(), KubeConfig{}, f.opts())
+	require.NoError(t, err)
+	assert.Equal(t, 2, rel.Revision)
+	assert.Equal(t, "deployed", rel.Status)
+
+	// The hook only runs on install.
+	assert.Empty(t, rel.Hooks)
+}
+
+func TestUpgradeValues(t *testing.T) {
+	f := newReleaseFixture(t)
+	f.WriteFile("dev-values.yaml", "greeting: howdy\n")
+
+	opts := f.opts()
+	opts.ValueFiles = []string{f.JoinPath("dev-values.yaml")}
+	opts.SetString = []string{"image=localhost:5000/app:tilt-123"}
+	_, err := f.client.Upgrade(context.Background(), KubeConfig{}, opts)
+	require.NoError(t, err)
+
+	rel, err := f.store.Last("rose-quartz")
+	require.NoError(t, err)
+	assert.Contains(t, rel.Manifest, "greeting: howdy")
+	assert.Equal(t, "localhost:5000/app:tilt-123", rel.Config["image"])
+}
+
+func TestUpgradeHookFailure(t *testing.T) {
+	f := newReleaseFixture(t)
+	f.kubeClient = &kubefake.FailingKubeClient{
+		PrintingKubeClient:   kubefake.PrintingKubeClient{Out: io.Discard},
+		WatchUntilReadyError: fmt.Errorf("job failed: BackoffLimitExceeded"),
+	}
+
+	rel, err := f.client.Upgrade(context.Background(), KubeConfig{}, f.opts())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "BackoffLimitExceeded")
+	require.NotNil(t, rel)
+	assert.Equal(t, 1, rel.Revision)
+	assert.Equal(t, "failed", rel.Status)
+
+	// Once the hook is fixed, the release recovers with a new revision.
+	f.kubeClient = &kubefake.PrintingKubeClient{Out: io.Discard}
+	rel, err = f.client.Upgrade(context.Background(), KubeConfig{}, f.opts())
+	require.NoError(t, err)
+	assert.Equal(t, 2, rel.Revision)
+	assert.Equal(t, "deployed", rel.Status)
+}
+
+func TestUpgradeAtomicRollsBack(t *testing.T) {
+	f := newReleaseFixture(t)
+	opts := f.opts()
+	opts.Atomic = true
+
+	_, err := f.client.Upgrade(f.ctx, KubeConfig{}, opts)
+	require.NoError(t, err)
+
+	f.kubeClient = &failingWaitKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+		failures:           1,
+	}
+	rel, err := f.client.Upgrade(f.ctx, KubeConfig{}, opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has been rolled back due to atomic being set")
+
+	// Revision 2 failed, and revision 3 rolled back to revision 1.
+	require.NotNil(t, rel)
+	assert.Equal(t, 3, rel.Revision)
+	assert.Equal(t, "deployed", rel.Status)
+
+	failed, err := f.store.Get("rose-quartz", 2)
+	require.NoError(t, err)
+	assert.Equal(t, release.StatusFailed, failed.Info.Status)
+}
+
+func TestInstallAtomicUninstalls(t *testing.T) {
+	f := newReleaseFixture(t)
+	f.kubeClient = &kubefake.FailingKubeClient{
+		PrintingKubeClient:   kubefake.PrintingKubeClient{Out: io.Discard},
+		WatchUntilReadyError: fmt.Errorf("job failed: BackoffLimitExceeded"),
+	}
+	opts := f.opts()
+	opts.Atomic = true
+
+	rel, err := f.client.Upgrade(f.ctx, KubeConfig{}, opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has been uninstalled due to atomic being set")
+	assert.Nil(t, rel)
+
+	_, err = f.store.History("rose-quartz")
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+}
+
+func TestUpgradeRecoversPendingRelease(t *testing.T) {
+	for _, status := range []release.Status{
+		release.StatusPendingInstall,
+		release.StatusPendingUpgrade,
+		release.StatusPendingRollback,
+	} {
+		t.Run(status.String(), func(t *testing.T) {
+			f := newReleaseFixture(t)
+
+			_, err := f.client.Upgrade(f.ctx, KubeConfig{}, f.opts())
+			require.NoError(t, err)
+
+			// Simulate Tilt exiting in the middle of an operation.
+			last, err := f.store.Last("rose-quartz")
+			require.NoError(t, err)
+			last.Info.Status = status
+			require.NoError(t, f.store.Update(last))
+
+			rel, err := f.client.Upgrade(f.ctx, KubeConfig{}, f.opts())
+			require.NoError(t, err)
+			assert.Equal(t, 2, rel.Revision)
+			assert.Equal(t, "deployed", rel.Status)
+			assert.Contains(t, f.out.String(), fmt.Sprintf("Release rose-quartz revision 1 was left %s", status))
+
+			interrupted, err := f.store.Get("rose-quartz", 1)
+			require.NoError(t, err)
+			assert.False(t, interrupted.Info.Status.IsPending())
+		})
+	}
+}
+
+func TestUpgradeChartError(t *testing.T) {
+	f := newReleaseFixture(t)
+	f.WriteFile("chart/templates/bad.yaml", "{{ .Values.nope.nope }}")
+
+	rel, err := f.client.Upgrade(context.Background(), KubeConfig{}, f.opts())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "templates/bad.yaml")
+
+	// Helm records the failed install.
+	require.NotNil(t, rel)
+	assert.Equal(t, 1, rel.Revision)
+	assert.Equal(t, "failed", rel.Status)
+}
+
+func TestUninstall(t *testing.T) {
+	f := newReleaseFixture(t)
+
+	_, err := f.client.Upgrade(context.Background(), KubeConfig{}, f.opts())
+	require.NoError(t, err)
+
+	err = f.client.Uninstall(context.Background(), KubeConfig{}, "rose-quartz", "garnet", 0)
+	require.NoError(t, err)
+
+	_, err = f.store.History("rose-quartz")
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+}
+
+func TestUninstallMissingRelease(t *testing.T) {
+	f := newReleaseFixture(t)
+
+	err := f.client.Uninstall(context.Background(), KubeConfig{}, "rose-quartz", "garnet", 0)
+	require.NoError(t, err)
+}
+
+// Fails the first few waits for the release's objects to be ready.
+type failingWaitKubeClient struct {
+	kubefake.PrintingKubeClient
+	failures int
+}
+
+func (c *failingWaitKubeClient) Wait(resources kube.ResourceList, d time.Duration) error {
+	if c.failures > 0 {
+		c.failures--
+		return fmt.Errorf("timed out waiting for the condition")
+	}
+	return c.PrintingKubeClient.Wait(resources, d)
+}
+
+type releaseFixture struct {
+	*tempdir.TempDirFixture
+	ctx        context.Context
+	out        *bytes.Buffer
+	store      *storage.Storage
+	kubeClient kube.Interface
+	client     releaseClient
+}
+
+func newReleaseFixture(t *testing.T) *releaseFixture {
+	out := &bytes.Buffer{}
+	f := &releaseFixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		ctx:            logger.WithLogger(context.Background(), logger.NewTestLogger(out)),
+		out:            out,
+		store:          storage.Init(driver.NewMemory()),
+		kubeClient:     &kubefake.PrintingKubeClient{Out: io.Discard},
+	}
+	newTestChart(f.TempDirFixture, "chart")
+
+	// Installing CRDs needs a real cluster.
+	f.Rm("chart/crds")
+
+	f.client = releaseClient{
+		newActionConfig: func(ctx context.Context, _ KubeConfig, namespace string) (*action.Configuration, string, error) {
+			return &action.Configuration{
+				Releases:     f.store,
+				KubeClient:   f.kubeClient,
+				Capabilities: chartutil.DefaultCapabilities,
+				Log:          func(string, ...interface{}) {},
+			}, namespace, nil
+		},
+	}
+	return f
+}
+
+func (f *releaseFixture) opts() ReleaseOptions {
+	return ReleaseOptions{
+		ReleaseName: "rose-quartz",
+		Namespace:   "garnet",
+		Chart:       f.JoinPath("chart"),
+	}
+}
@@ -0,0 +1,109 @@
+package helm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Helm's default namespace, when there's no kubeconfig to say otherwise.
+const defaultNamespace = "default"
+
+type TemplateOptions struct {
+	// Defaults to "chart", so that the name is stable across renders.
+	ReleaseName string
+
+	// Defaults to "default".
+	Namespace string
+
+	// Values files, applied in order after the chart's values.yaml.
+	ValueFiles []string
+
+	// Values in --set syntax, e.g., "image.tag=dev".
+	Set []string
+
+	// e.g., "1.22.0". Defaults to Helm's default.
+	KubeVersion string
+
+	// Leaves out the chart's crds/ directory, like `helm template`
+	// without --include-crds.
+	SkipCRDs bool
+}
+
+// Renders a chart, like `helm template --include-crds`.
+//
+// Rendering never talks to a cluster. Test hooks are left out.
+func Template(ch *chart.Chart, opts TemplateOptions) (string, error) {
+	vals, err := (&values.Options{ValueFiles: opts.ValueFiles, Values: opts.Set}).MergeValues(getter.Providers{})
+	if err != nil {
+		return "", err
+	}
+
+	if ch.Metadata.Dependencies != nil {
+		err := action.CheckDependencies(ch, ch.Metadata.Dependencies)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	install := action.NewInstall(&action.Configuration{
+		Log: func(string, ...interface{}) {},
+	})
+	install.DryRun = true
+	install.ClientOnly = true
+	install.Replace = true
+	install.IncludeCRDs = !opts.SkipCRDs
+	install.ReleaseName = opts.ReleaseName
+	if install.ReleaseName == "" {
+		// This looks like what helm does.
+		// https://github.com/helm/helm/blob/e672a42efae30d45ddd642a26557dcdbf5a9f5f0/pkg/action/install.go#L562
+		install.ReleaseName = "chart"
+	}
+	install.Namespace = opts.Namespace
+	if install.Namespace == "" {
+		install.Namespace = defaultNamespace
+	}
+	if opts.KubeVersion != "" {
+		kubeVersion, err := chartutil.ParseKubeVersion(opts.KubeVersion)
+		if err != nil {
+			return "", fmt.Errorf("invalid kube_version %q: %v", opts.KubeVersion, err)
+		}
+		install.KubeVersion = kubeVersion
+	}
+
+	rel, err := install.Run(ch, vals)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	writeManifests(&out, rel)
+	return out.String(), nil
+}
+
+// Writes the manifests in the same format as `helm template`.
+func writeManifests(w io.Writer, rel *release.Release) {
+	_, _ = fmt.Fprintln(w, strings.TrimSpace(rel.Manifest))
+	for _, h := range rel.Hooks {
+		if isTestHook(h) {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+}
+
+func isTestHook(h *release.Hook) bool {
+	for _, e := range h.Events {
+		if e == release.HookTest {
+			return true
+		}
+	}
+	return false
+}
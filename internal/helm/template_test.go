@@ -0,0 +1,155 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+const testChartYAML = `apiVersion: v2
+name: hello
+version: 1.0.0
+`
+
+const testValuesYAML = `greeting: hello
+`
+
+const testConfigMapYAML = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-config
+  namespace: {{ .Release.Namespace }}
+data:
+  greeting: {{ .Values.greeting }}
+  kubeVersion: {{ .Capabilities.KubeVersion.Version }}
+`
+
+const testHookYAML = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{ .Release.Name }}-migrate
+  annotations:
+    "helm.sh/hook": pre-install
+`
+
+const testTestHookYAML = `apiVersion: v1
+kind: Pod
+metadata:
+  name: {{ .Release.Name }}-test
+  annotations:
+    "helm.sh/hook": test
+`
+
+const testCRDYAML = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+`
+
+func newTestChart(f *tempdir.TempDirFixture, dir string) {
+	f.WriteFile(dir+"/Chart.yaml", testChartYAML)
+	f.WriteFile(dir+"/values.yaml", testValuesYAML)
+	f.WriteFile(dir+"/templates/configmap.yaml", testConfigMapYAML)
+	f.WriteFile(dir+"/templates/migrate.yaml", testHookYAML)
+	f.WriteFile(dir+"/templates/test.yaml", testTestHookYAML)
+	f.WriteFile(dir+"/templates/NOTES.txt", "Thanks for installing!")
+	f.WriteFile(dir+"/crds/widget.yaml", testCRDYAML)
+}
+
+func loadTestChart(t *testing.T) (*tempdir.TempDirFixture, *chart.Chart) {
+	f := tempdir.NewTempDirFixture(t)
+	newTestChart(f, "chart")
+	ch, err := LoadChart(f.JoinPath("chart"))
+	require.NoError(t, err)
+	return f, ch
+}
+
+func TestTemplate(t *testing.T) {
+	_, ch := loadTestChart(t)
+
+	out, err := Template(ch, TemplateOptions{})
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "# Source: hello/templates/configmap.yaml")
+	assert.Contains(t, out, "name: chart-config")
+	assert.Contains(t, out, "namespace: default")
+	assert.Contains(t, out, "greeting: hello")
+
+	// CRDs and hooks are included, but tests and notes aren't.
+	assert.Contains(t, out, "name: widgets.example.com")
+	assert.Contains(t, out, "name: chart-migrate")
+	assert.NotContains(t, out, "chart-test")
+	assert.NotContains(t, out, "Thanks for installing")
+}
+
+func TestTemplateOptions(t *testing.T) {
+	f, ch := loadTestChart(t)
+	f.WriteFile("dev-values.yaml", "greeting: howdy\n")
+
+	out, err := Template(ch, TemplateOptions{
+		ReleaseName: "rose-quartz",
+		Namespace:   "garnet",
+		ValueFiles:  []string{f.JoinPath("dev-values.yaml")},
+		Set:         []string{"extra=1"},
+		KubeVersion: "1.99.0",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "name: rose-quartz-config")
+	assert.Contains(t, out, "namespace: garnet")
+	assert.Contains(t, out, "greeting: howdy")
+	assert.Contains(t, out, "kubeVersion: v1.99.0")
+}
+
+func TestTemplateSkipCRDs(t *testing.T) {
+	_, ch := loadTestChart(t)
+
+	out, err := Template(ch, TemplateOptions{SkipCRDs: true})
+	require.NoError(t, err)
+	assert.NotContains(t, out, "name: widgets.example.com")
+	assert.Contains(t, out, "name: chart-config")
+}
+
+func TestTemplateSetOverridesValueFiles(t *testing.T) {
+	f, ch := loadTestChart(t)
+	f.WriteFile("dev-values.yaml", "greeting: howdy\n")
+
+	out, err := Template(ch, TemplateOptions{
+		ValueFiles: []string{f.JoinPath("dev-values.yaml")},
+		Set:        []string{"greeting=hiya"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "greeting: hiya")
+}
+
+func TestTemplateMissingDependency(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	newTestChart(f, "chart")
+	f.WriteFile("chart/Chart.yaml", testChartYAML+`dependencies:
+- name: redis
+  version: 1.0.0
+  repository: https://charts.example.com
+`)
+	ch, err := LoadChart(f.JoinPath("chart"))
+	require.NoError(t, err)
+
+	_, err = Template(ch, TemplateOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing in charts/ directory: redis")
+}
+
+func TestTemplateError(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	newTestChart(f, "chart")
+	f.WriteFile("chart/templates/bad.yaml", "{{ .Values.nope.nope }}")
+	ch, err := LoadChart(f.JoinPath("chart"))
+	require.NoError(t, err)
+
+	_, err = Template(ch, TemplateOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "templates/bad.yaml")
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/hud/webview"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/tiltfiles"
+	"github.com/tilt-dev/tilt/internal/tiltfile/config"
 	"github.com/tilt-dev/tilt/pkg/assets"
 	"github.com/tilt-dev/tilt/pkg/model"
 	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
@@ -94,6 +95,7 @@ func ProvideHeadsUpServer(
 	r.HandleFunc("/api/websocket_token", s.WebsocketToken)
 	r.HandleFunc("/ws/view", s.ViewWebsocket)
 	r.HandleFunc("/api/set_tiltfile_args", s.HandleSetTiltfileArgs).Methods("POST")
+	r.HandleFunc("/api/tiltfile_args_schema", s.TiltfileArgsSchemaJSON).Methods("GET")
 
 	r.PathPrefix("/").Handler(s.cookieWrapper(assetServer))
 
@@ -238,6 +240,32 @@ func (s *HeadsUpServer) HandleSetTiltfileArgs(w http.ResponseWriter, req *http.R
 	}
 }
 
+// Describes the args defined by the main Tiltfile with config.define_*,
+// so that clients can print help or render a form for them.
+func (s *HeadsUpServer) TiltfileArgsSchemaJSON(w http.ResponseWriter, req *http.Request) {
+	state := s.store.RLockState()
+	schema := model.TiltfileArgsSchema{Args: state.TiltfileArgs}
+	args := state.UserConfigState.Args
+	s.store.RUnlockState()
+
+	if schema.Args == nil {
+		schema.Args = []model.TiltfileArg{}
+	}
+
+	// If the args don't parse, the Tiltfile shows the error,
+	// so we just leave out the values.
+	values, err := config.ArgValues(args, schema.Args)
+	if err == nil && len(values) > 0 {
+		schema.Values = values
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(schema)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering args schema: %v", err), http.StatusInternalServerError)
+	}
+}
+
 // Responds with:
 // * 200/empty body on success
 // * 200/error message in body on well-formed, unservicable requests (e.g. resource is disabled or doesn't exist)
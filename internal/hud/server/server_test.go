@@ -312,6 +312,37 @@ func TestSetTiltfileArgs(t *testing.T) {
 	)
 }
 
+func TestTiltfileArgsSchema(t *testing.T) {
+	f := newTestFixture(t)
+
+	status, resp := f.makeReq("/api/tiltfile_args_schema", f.serv.TiltfileArgsSchemaJSON, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"args": []}`, resp)
+
+	state := f.st.LockMutableStateForTesting()
+	state.TiltfileArgs = []model.TiltfileArg{
+		{Name: "env", Type: "choice", Choices: []string{"dev", "prod"}, Default: "dev"},
+		{Name: "resources", Type: "list[string]", Positional: true, Usage: "resources to enable"},
+	}
+	f.st.UnlockMutableState()
+
+	status, resp = f.makeReq("/api/tiltfile_args_schema", f.serv.TiltfileArgsSchemaJSON, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"args": [
+  {"name": "env", "type": "choice", "choices": ["dev", "prod"], "default": "dev"},
+  {"name": "resources", "type": "list[string]", "positional": true, "usage": "resources to enable"}
+]}`, resp)
+
+	// The current args are split up by arg, so the web UI can fill in its form.
+	state = f.st.LockMutableStateForTesting()
+	state.UserConfigState = model.NewUserConfigState([]string{"--env", "prod", "frontend", "backend"})
+	f.st.UnlockMutableState()
+
+	status, resp = f.makeReq("/api/tiltfile_args_schema", f.serv.TiltfileArgsSchemaJSON, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, resp, `"values":{"env":["prod"],"resources":["frontend","backend"]}`)
+}
+
 type serverFixture struct {
 	t            *testing.T
 	ctx          context.Context
@@ -1,6 +1,7 @@
 package hud
 
 import (
+	"fmt"
 	"os"
 	"sort"
 	"sync"
@@ -122,8 +123,12 @@ func resourceInfoView(mt *store.ManifestTarget) view.ResourceInfoView {
 	runStatus := mt.RuntimeStatus()
 	switch state := mt.State.RuntimeState.(type) {
 	case dockercompose.State:
+		status := state.ContainerState.Status
+		if state.ContainerState.Running && state.ContainerState.HealthStatus != "" {
+			status = fmt.Sprintf("%s (%s)", status, state.ContainerState.HealthStatus)
+		}
 		return view.NewDCResourceInfo(
-			state.ContainerState.Status, state.ContainerID, state.SpanID, state.ContainerState.StartedAt.Time, runStatus)
+			status, state.ContainerID, state.SpanID, state.ContainerState.StartedAt.Time, runStatus)
 	case store.K8sRuntimeState:
 		if mt.Manifest.PodReadinessMode() == model.PodReadinessIgnore {
 			return view.YAMLResourceInfo{
@@ -0,0 +1,287 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// Helpers for editing entities in place of hand-editing the YAML.
+//
+// Each helper returns a copy of the entity, and whether anything changed.
+
+// Sets the image of containers in the entity's pod specs.
+//
+// If containerName is empty, every pod spec in the entity must have exactly
+// one container, so that we don't silently give a sidecar the wrong image.
+func SetImage(entity K8sEntity, containerName string, image string) (K8sEntity, bool, error) {
+	entity = entity.DeepCopy()
+	pods, err := ExtractPods(&entity)
+	if err != nil {
+		return K8sEntity{}, false, err
+	}
+
+	changed := false
+	for _, pod := range pods {
+		if containerName == "" {
+			if len(pod.Containers) != 1 {
+				return K8sEntity{}, false, fmt.Errorf(
+					"%s %s has %d containers; specify which container to set the image on",
+					entity.GVK().Kind, entity.Name(), len(pod.Containers))
+			}
+			pod.Containers[0].Image = image
+			changed = true
+			continue
+		}
+
+		for i := range pod.InitContainers {
+			if pod.InitContainers[i].Name == containerName {
+				pod.InitContainers[i].Image = image
+				changed = true
+			}
+		}
+		for i := range pod.Containers {
+			if pod.Containers[i].Name == containerName {
+				pod.Containers[i].Image = image
+				changed = true
+			}
+		}
+	}
+	return entity, changed, nil
+}
+
+// Sets environment variables on containers in the entity's pod specs,
+// overwriting any existing variable with the same name.
+//
+// If containerName is empty, sets them on all (non-init) containers.
+func SetEnv(entity K8sEntity, containerName string, env map[string]string) (K8sEntity, bool, error) {
+	entity = entity.DeepCopy()
+	containers, err := matchingContainers(&entity, containerName)
+	if err != nil {
+		return K8sEntity{}, false, err
+	}
+
+	for _, c := range containers {
+		for _, name := range sortedKeys(env) {
+			c.Env = setEnvVar(c.Env, v1.EnvVar{Name: name, Value: env[name]})
+		}
+	}
+	return entity, len(containers) > 0 && len(env) > 0, nil
+}
+
+func setEnvVar(env []v1.EnvVar, ev v1.EnvVar) []v1.EnvVar {
+	for i := range env {
+		if env[i].Name == ev.Name {
+			env[i] = ev
+			return env
+		}
+	}
+	return append(env, ev)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Sets resource requests and limits on containers in the entity's pod specs.
+// Resources that aren't mentioned are left alone.
+//
+// If containerName is empty, sets them on all (non-init) containers.
+func SetResources(entity K8sEntity, containerName string, requests, limits map[string]string) (K8sEntity, bool, error) {
+	requestList, err := parseResourceList(requests)
+	if err != nil {
+		return K8sEntity{}, false, errors.Wrap(err, "requests")
+	}
+	limitList, err := parseResourceList(limits)
+	if err != nil {
+		return K8sEntity{}, false, errors.Wrap(err, "limits")
+	}
+
+	entity = entity.DeepCopy()
+	containers, err := matchingContainers(&entity, containerName)
+	if err != nil {
+		return K8sEntity{}, false, err
+	}
+
+	for _, c := range containers {
+		c.Resources.Requests = mergeResourceList(c.Resources.Requests, requestList)
+		c.Resources.Limits = mergeResourceList(c.Resources.Limits, limitList)
+	}
+	return entity, len(containers) > 0 && (len(requestList) > 0 || len(limitList) > 0), nil
+}
+
+func parseResourceList(m map[string]string) (v1.ResourceList, error) {
+	result := v1.ResourceList{}
+	for name, q := range m {
+		quantity, err := resource.ParseQuantity(q)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity for %s: %q", name, q)
+		}
+		result[v1.ResourceName(name)] = quantity
+	}
+	return result, nil
+}
+
+func mergeResourceList(existing, update v1.ResourceList) v1.ResourceList {
+	if len(update) == 0 {
+		return existing
+	}
+	if existing == nil {
+		existing = v1.ResourceList{}
+	}
+	for name, q := range update {
+		existing[name] = q
+	}
+	return existing
+}
+
+func matchingContainers(entity *K8sEntity, containerName string) ([]*v1.Container, error) {
+	pods, err := ExtractPods(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*v1.Container
+	for _, pod := range pods {
+		for i := range pod.Containers {
+			if containerName == "" || pod.Containers[i].Name == containerName {
+				result = append(result, &pod.Containers[i])
+			}
+		}
+	}
+	return result, nil
+}
+
+// Sets the replica count on workloads that have one
+// (Deployments, StatefulSets, ReplicaSets, etc).
+//
+// We don't have a schema for custom resources, so we only edit them if they
+// already have spec.replicas. Custom resources with a pod template but no
+// spec.replicas might not accept one (e.g., a Knative Service), so we ask the
+// user to patch them explicitly.
+func SetReplicas(entity K8sEntity, replicas int32) (K8sEntity, bool, error) {
+	entity = entity.DeepCopy()
+
+	if u, ok := entity.Obj.(*unstructured.Unstructured); ok {
+		_, found, err := unstructured.NestedFieldNoCopy(u.Object, "spec", "replicas")
+		if err != nil {
+			return entity, false, err
+		}
+		if !found {
+			_, hasTemplate, _ := unstructured.NestedMap(u.Object, "spec", "template")
+			if hasTemplate {
+				return entity, false, fmt.Errorf(
+					"%s %s has a pod template but no spec.replicas, so Tilt doesn't know if it supports replicas. "+
+						"Use k8s.patch() to set them",
+					u.GetKind(), u.GetName())
+			}
+			return entity, false, nil
+		}
+		err = unstructured.SetNestedField(u.Object, int64(replicas), "spec", "replicas")
+		if err != nil {
+			return K8sEntity{}, false, err
+		}
+		return entity, true, nil
+	}
+
+	v := reflect.ValueOf(entity.Obj)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return entity, false, nil
+	}
+	spec := v.Elem().FieldByName("Spec")
+	if !spec.IsValid() || spec.Kind() != reflect.Struct {
+		return entity, false, nil
+	}
+	field := spec.FieldByName("Replicas")
+	if !field.IsValid() || field.Type() != reflect.TypeOf((*int32)(nil)) {
+		return entity, false, nil
+	}
+	field.Set(reflect.ValueOf(&replicas))
+	return entity, true, nil
+}
+
+// Adds annotations to the entity's metadata, overwriting existing
+// annotations with the same key.
+func SetAnnotations(entity K8sEntity, annotations map[string]string) (K8sEntity, bool) {
+	if len(annotations) == 0 {
+		return entity, false
+	}
+
+	entity = entity.DeepCopy()
+	meta := entity.Meta()
+	result := meta.GetAnnotations()
+	if result == nil {
+		result = make(map[string]string, len(annotations))
+	}
+	for k, v := range annotations {
+		result[k] = v
+	}
+	meta.SetAnnotations(result)
+	return entity, true
+}
+
+type PatchType string
+
+const (
+	PatchTypeStrategic PatchType = "strategic"
+	PatchTypeMerge     PatchType = "merge"
+	PatchTypeJSON      PatchType = "json"
+)
+
+// Applies a patch to the entity, with the same semantics as `kubectl patch --type`.
+//
+// Strategic merge patches need to know the Go type of the object. Objects
+// we don't have a type for (e.g., custom resources) get a JSON merge patch
+// instead, like kustomize does.
+func Patch(entity K8sEntity, patchType PatchType, patch []byte) (K8sEntity, error) {
+	original, err := json.Marshal(entity.Obj)
+	if err != nil {
+		return K8sEntity{}, errors.Wrap(err, "serializing object")
+	}
+
+	var patched []byte
+	switch patchType {
+	case PatchTypeStrategic:
+		_, isUnstructured := entity.Obj.(*unstructured.Unstructured)
+		if isUnstructured {
+			patched, err = jsonpatch.MergePatch(original, patch)
+		} else {
+			patched, err = strategicpatch.StrategicMergePatch(original, patch, entity.Obj)
+		}
+	case PatchTypeMerge:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case PatchTypeJSON:
+		var p jsonpatch.Patch
+		p, err = jsonpatch.DecodePatch(patch)
+		if err == nil {
+			patched, err = p.Apply(original)
+		}
+	default:
+		return K8sEntity{}, fmt.Errorf("unknown patch type %q", patchType)
+	}
+	if err != nil {
+		return K8sEntity{}, errors.Wrapf(err, "applying %s patch", patchType)
+	}
+
+	entities, err := ParseYAMLFromString(string(patched))
+	if err != nil {
+		return K8sEntity{}, errors.Wrap(err, "decoding patched object")
+	}
+	if len(entities) != 1 {
+		return K8sEntity{}, fmt.Errorf("patch produced %d objects, expected 1", len(entities))
+	}
+	return entities[0], nil
+}
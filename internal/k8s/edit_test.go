@@ -0,0 +1,199 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func mustParseOne(t *testing.T, yaml string) K8sEntity {
+	entities, err := ParseYAMLFromString(yaml)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+	return entities[0]
+}
+
+func TestSetImage(t *testing.T) {
+	entity := mustParseOne(t, testyaml.SanchoYAML)
+
+	result, changed, err := SetImage(entity, "", "sancho:dev")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "sancho:dev", result.Obj.(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Image)
+
+	// The original is untouched.
+	assert.Equal(t, testyaml.SanchoImage, entity.Obj.(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestSetImageAmbiguous(t *testing.T) {
+	entity := mustParseOne(t, testyaml.SanchoSidecarYAML)
+
+	_, _, err := SetImage(entity, "", "sancho:dev")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Deployment sancho has 2 containers")
+
+	result, changed, err := SetImage(entity, "sancho-sidecar", "sidecar:dev")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	containers := result.Obj.(*appsv1.Deployment).Spec.Template.Spec.Containers
+	assert.Equal(t, testyaml.SanchoImage, containers[0].Image)
+	assert.Equal(t, "sidecar:dev", containers[1].Image)
+
+	_, changed, err = SetImage(entity, "missing", "sidecar:dev")
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestSetEnv(t *testing.T) {
+	entity := mustParseOne(t, testyaml.SanchoYAML)
+
+	result, changed, err := SetEnv(entity, "", map[string]string{"token": "abc", "DEBUG": "1"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []v1.EnvVar{
+		{Name: "token", Value: "abc"},
+		{Name: "DEBUG", Value: "1"},
+	}, result.Obj.(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Env)
+}
+
+func TestSetResources(t *testing.T) {
+	entity := mustParseOne(t, testyaml.SanchoSidecarYAML)
+
+	result, changed, err := SetResources(entity, "sancho",
+		map[string]string{"cpu": "100m"},
+		map[string]string{"memory": "1Gi"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	containers := result.Obj.(*appsv1.Deployment).Spec.Template.Spec.Containers
+	assert.Equal(t, "100m", containers[0].Resources.Requests.Cpu().String())
+	assert.Equal(t, "1Gi", containers[0].Resources.Limits.Memory().String())
+	assert.Empty(t, containers[1].Resources.Requests)
+
+	_, _, err = SetResources(entity, "", map[string]string{"cpu": "lots"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `requests: invalid quantity for cpu: "lots"`)
+}
+
+func TestSetReplicas(t *testing.T) {
+	entity := mustParseOne(t, testyaml.SanchoYAML)
+
+	result, changed, err := SetReplicas(entity, 3)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, int32(3), *result.Obj.(*appsv1.Deployment).Spec.Replicas)
+
+	_, changed, err = SetReplicas(mustParseOne(t, testyaml.SecretYaml), 3)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestSetReplicasCustomResource(t *testing.T) {
+	rollout := mustParseOne(t, `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: foo
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo
+`)
+	_, _, err := SetReplicas(rollout, 3)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Rollout foo has a pod template but no spec.replicas")
+		assert.Contains(t, err.Error(), "k8s.patch()")
+	}
+
+	rollout = mustParseOne(t, `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: foo
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo
+`)
+	result, changed, err := SetReplicas(rollout, 3)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	replicas, _, err := unstructured.NestedInt64(result.Obj.(*unstructured.Unstructured).Object, "spec", "replicas")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), replicas)
+
+	_, changed, err = SetReplicas(mustParseOne(t, testyaml.CRDImageObjectYAML), 3)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestSetAnnotations(t *testing.T) {
+	entity := mustParseOne(t, testyaml.SanchoYAML)
+
+	result, changed := SetAnnotations(entity, map[string]string{"a": "b"})
+	assert.True(t, changed)
+	assert.Equal(t, map[string]string{"a": "b"}, result.Annotations())
+	assert.Empty(t, entity.Annotations())
+}
+
+func TestPatchStrategic(t *testing.T) {
+	entity := mustParseOne(t, testyaml.SanchoSidecarYAML)
+
+	// Strategic merge patches merge containers by name.
+	result, err := Patch(entity, PatchTypeStrategic, []byte(`
+{"spec": {"template": {"spec": {"containers": [{"name": "sancho-sidecar", "image": "sidecar:dev"}]}}}}`))
+	require.NoError(t, err)
+
+	containers := result.Obj.(*appsv1.Deployment).Spec.Template.Spec.Containers
+	require.Len(t, containers, 2)
+	assert.Equal(t, testyaml.SanchoImage, containers[0].Image)
+	assert.Equal(t, "sidecar:dev", containers[1].Image)
+}
+
+func TestPatchMerge(t *testing.T) {
+	entity := mustParseOne(t, testyaml.SanchoSidecarYAML)
+
+	// JSON merge patches replace lists wholesale.
+	result, err := Patch(entity, PatchTypeMerge, []byte(`
+{"spec": {"template": {"spec": {"containers": [{"name": "only", "image": "only:dev"}]}}}}`))
+	require.NoError(t, err)
+
+	containers := result.Obj.(*appsv1.Deployment).Spec.Template.Spec.Containers
+	require.Len(t, containers, 1)
+	assert.Equal(t, "only", containers[0].Name)
+}
+
+func TestPatchJSON(t *testing.T) {
+	entity := mustParseOne(t, testyaml.SanchoYAML)
+
+	result, err := Patch(entity, PatchTypeJSON, []byte(`
+[{"op": "replace", "path": "/spec/replicas", "value": 5}]`))
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), *result.Obj.(*appsv1.Deployment).Spec.Replicas)
+
+	_, err = Patch(entity, PatchTypeJSON, []byte(`[{"op": "remove", "path": "/spec/nope"}]`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "applying json patch")
+}
+
+func TestPatchStrategicCustomResource(t *testing.T) {
+	entity := mustParseOne(t, testyaml.CRDImageObjectYAML)
+	_, ok := entity.Obj.(*unstructured.Unstructured)
+	require.True(t, ok)
+
+	result, err := Patch(entity, PatchTypeStrategic, []byte(`{"spec": {"imageObject": {"tag": "dev"}}}`))
+	require.NoError(t, err)
+
+	tag, _, err := unstructured.NestedString(result.Obj.(*unstructured.Unstructured).Object, "spec", "imageObject", "tag")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", tag)
+}
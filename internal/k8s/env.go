@@ -68,12 +68,8 @@ func ProvideClusterName(configOrError APIConfigOrError) ClusterName {
 
 const ProductNone = clusterid.Product("")
 
-func ProvideClusterProduct(configOrError APIConfigOrError) clusterid.Product {
-	config := configOrError.Config
-	if config == nil {
-		return ProductNone
-	}
-	return ClusterProductFromAPIConfig(config)
+func ProvideClusterProduct(profile ClusterProfile) clusterid.Product {
+	return profile.Product
 }
 
 func ClusterProductFromAPIConfig(config *api.Config) clusterid.Product {
@@ -89,6 +85,9 @@ func ClusterProductFromAPIConfig(config *api.Config) clusterid.Product {
 
 	cn := c.Cluster
 	cl := config.Clusters[cn]
+	if p := productFromClusterName(cn); p != ProductNone {
+		return p
+	}
 	return clusterid.ProductFromContext(c, cl)
 }
 
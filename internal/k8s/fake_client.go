@@ -73,6 +73,8 @@ type FakeK8sClient struct {
 
 	EventsWatchErr error
 
+	GetMetaByReferenceError error
+
 	UpsertError      error
 	UpsertResult     []K8sEntity
 	LastUpsertResult []K8sEntity
@@ -467,6 +469,9 @@ func (c *FakeK8sClient) GetMetaByReference(ctx context.Context, ref v1.ObjectRef
 	defer c.mu.Unlock()
 
 	c.getByReferenceCallCount++
+	if c.GetMetaByReferenceError != nil {
+		return nil, c.GetMetaByReferenceError
+	}
 	resp, ok := c.entities[ref.UID]
 	if !ok {
 		logger.Get(ctx).Infof("FakeK8sClient.GetMetaByReference: resource not found: %s", ref.Name)
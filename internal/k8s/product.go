@@ -0,0 +1,232 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+
+	"github.com/tilt-dev/clusterid"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Products that Tilt detects on top of the ones that clusterid knows about.
+const (
+	// vcluster names its contexts vcluster_<name>_<namespace>_<host-context>
+	// https://www.vcluster.com/docs/using-vclusters/access
+	ProductVCluster = clusterid.Product("vcluster")
+
+	// OrbStack's Kubernetes runs on the same Docker daemon as its containers.
+	// https://docs.orbstack.dev/kubernetes/
+	ProductOrbStack = clusterid.Product("orbstack")
+)
+
+// The file (relative to the tilt-dev config dir) where users declare
+// properties of clusters that Tilt can't detect on its own.
+const ClusterConfigFileName = "clusters.yaml"
+
+func productFromClusterName(cn string) clusterid.Product {
+	if strings.HasPrefix(cn, "vcluster_") {
+		return ProductVCluster
+	} else if cn == "orbstack" {
+		return ProductOrbStack
+	}
+	return ProductNone
+}
+
+// IsDevCluster extends clusterid's list of dev clusters with the
+// products that Tilt detects itself.
+func IsDevCluster(p clusterid.Product) bool {
+	return p.IsDevCluster() || p == ProductVCluster || p == ProductOrbStack
+}
+
+// ClusterProfile is everything Tilt knows about what kind of cluster it's
+// talking to: the product, and the defaults that follow from it.
+type ClusterProfile struct {
+	Product clusterid.Product
+
+	// Whether it's safe to deploy to this cluster without allow_k8s_contexts().
+	DevCluster bool
+
+	// How images get into the cluster. Empty means Tilt decides
+	// based on the product.
+	ImageLoad v1alpha1.ClusterImageLoad
+
+	// The registry declared for this cluster, if any.
+	Registry *v1alpha1.RegistryHosting
+}
+
+// The profile Tilt uses for a product when nothing else is declared.
+func ProfileForProduct(p clusterid.Product) ClusterProfile {
+	return ClusterProfile{Product: p, DevCluster: IsDevCluster(p)}
+}
+
+// Anything that knows whether the images it builds end up in the
+// container runtime of a kube context (e.g., a Docker client).
+type ClusterRuntimeBuilder interface {
+	WillBuildToKubeContext(kctx KubeContext) bool
+}
+
+// Checks whether images built by the local Docker daemon are already
+// visible to the cluster, either because the user declared it (with the
+// cluster's image load strategy) or because the builder detected it.
+func BuildsToClusterRuntime(b ClusterRuntimeBuilder, cluster *v1alpha1.Cluster, kctx KubeContext) bool {
+	if cluster != nil && cluster.Status.Connection != nil && cluster.Status.Connection.Kubernetes != nil {
+		switch cluster.Status.Connection.Kubernetes.ImageLoad {
+		case v1alpha1.ClusterImageLoadDocker:
+			return true
+		case v1alpha1.ClusterImageLoadRegistry, v1alpha1.ClusterImageLoadKind:
+			return false
+		}
+	}
+	return b.WillBuildToKubeContext(kctx)
+}
+
+func (p ClusterProfile) with(o ClusterOverride) ClusterProfile {
+	if o.Product != "" {
+		p.Product = clusterid.Product(o.Product)
+		p.DevCluster = IsDevCluster(p.Product)
+	}
+	if o.DevCluster != nil {
+		p.DevCluster = *o.DevCluster
+	}
+	if o.ImageLoad != "" {
+		p.ImageLoad = o.ImageLoad
+	}
+	if o.Registry != nil {
+		p.Registry = o.Registry.DeepCopy()
+	}
+	return p
+}
+
+// ClusterOverride declares properties of the clusters whose kubeconfig
+// context matches a pattern.
+type ClusterOverride struct {
+	// A kubeconfig context name, or a glob pattern like "talos-*".
+	Context string `json:"context"`
+
+	Product    string                    `json:"product,omitempty"`
+	DevCluster *bool                     `json:"devCluster,omitempty"`
+	ImageLoad  v1alpha1.ClusterImageLoad `json:"imageLoad,omitempty"`
+	Registry   *v1alpha1.RegistryHosting `json:"registry,omitempty"`
+}
+
+// Layers the overrides in a Cluster spec on top of this one.
+func (o ClusterOverride) withSpec(conn *v1alpha1.KubernetesClusterConnection) ClusterOverride {
+	if conn == nil {
+		return o
+	}
+	if conn.Product != "" {
+		o.Product = conn.Product
+	}
+	if conn.DevCluster != nil {
+		o.DevCluster = conn.DevCluster
+	}
+	if conn.ImageLoad != "" {
+		o.ImageLoad = conn.ImageLoad
+	}
+	return o
+}
+
+// ClusterConfig is the contents of the user's clusters.yaml, e.g.,
+//
+//	clusters:
+//	- context: talos-*
+//	  product: talos
+//	  devCluster: true
+//	  imageLoad: registry
+//	  registry:
+//	    host: localhost:5005
+type ClusterConfig struct {
+	Clusters []ClusterOverride `json:"clusters,omitempty"`
+
+	// If the file failed to load, propagate that error so that
+	// the cluster controller can report it.
+	Error error `json:"-"`
+}
+
+// Returns the first override that matches the given context.
+func (c ClusterConfig) ForContext(kctx string) (ClusterOverride, bool) {
+	for _, o := range c.Clusters {
+		ok, err := path.Match(o.Context, kctx)
+		if err == nil && ok {
+			return o, true
+		}
+	}
+	return ClusterOverride{}, false
+}
+
+func ProvideClusterConfig(base xdg.Base) ClusterConfig {
+	p, err := base.ConfigFile(ClusterConfigFileName)
+	if err != nil {
+		return ClusterConfig{Error: err}
+	}
+	return LoadClusterConfig(p)
+}
+
+func LoadClusterConfig(p string) ClusterConfig {
+	contents, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ClusterConfig{}
+		}
+		return ClusterConfig{Error: fmt.Errorf("reading %s: %v", p, err)}
+	}
+
+	var config ClusterConfig
+	err = yaml.UnmarshalStrict(contents, &config)
+	if err != nil {
+		return ClusterConfig{Error: fmt.Errorf("parsing %s: %v", p, err)}
+	}
+
+	for i, o := range config.Clusters {
+		if _, err := path.Match(o.Context, ""); err != nil || o.Context == "" {
+			return ClusterConfig{Error: fmt.Errorf("parsing %s: clusters[%d]: invalid context pattern %q", p, i, o.Context)}
+		}
+		switch o.ImageLoad {
+		case "", v1alpha1.ClusterImageLoadRegistry, v1alpha1.ClusterImageLoadKind, v1alpha1.ClusterImageLoadDocker:
+		default:
+			return ClusterConfig{Error: fmt.Errorf("parsing %s: clusters[%d]: unsupported imageLoad %q", p, i, o.ImageLoad)}
+		}
+	}
+	return config
+}
+
+func ProvideClusterProfile(ctx context.Context, configOrError APIConfigOrError, clusterConfig ClusterConfig) ClusterProfile {
+	if clusterConfig.Error != nil {
+		// We can still detect the cluster without the user's declarations,
+		// so don't fail, but let them know their clusters.yaml is being ignored.
+		logger.Get(ctx).Warnf("Loading cluster config: %v", clusterConfig.Error)
+	}
+
+	config := configOrError.Config
+	if config == nil {
+		return ProfileForProduct(ProductNone)
+	}
+	return ClusterProfileFromAPIConfig(config, clusterConfig, nil)
+}
+
+// Detects the cluster product from the kubeconfig, then applies anything
+// the user declared about the cluster, first in their clusters.yaml,
+// then in the Cluster spec (if any).
+func ClusterProfileFromAPIConfig(config *api.Config, clusterConfig ClusterConfig, conn *v1alpha1.KubernetesClusterConnection) ClusterProfile {
+	o, _ := clusterConfig.ForContext(config.CurrentContext)
+	return ProfileForProduct(ClusterProductFromAPIConfig(config)).with(o.withSpec(conn))
+}
+
+// Like ClusterProfileFromAPIConfig, but for any context in the kubeconfig,
+// not just the current one.
+func ClusterProfileForContext(config *api.Config, clusterConfig ClusterConfig, kctx KubeContext, conn *v1alpha1.KubernetesClusterConnection) ClusterProfile {
+	if config == nil {
+		config = api.NewConfig()
+	}
+	withContext := *config
+	withContext.CurrentContext = string(kctx)
+	return ClusterProfileFromAPIConfig(&withContext, clusterConfig, conn)
+}
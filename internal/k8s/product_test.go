@@ -0,0 +1,199 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/tilt-dev/clusterid"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestProductFromAPIConfig(t *testing.T) {
+	cases := []struct {
+		context string
+		cluster string
+		product clusterid.Product
+	}{
+		{"kind-kind", "kind-kind", clusterid.ProductKIND},
+		{"vcluster_my-vcluster_vcluster-my-vcluster_kind-kind", "vcluster_my-vcluster_vcluster-my-vcluster_kind-kind", ProductVCluster},
+		{"orbstack", "orbstack", ProductOrbStack},
+		{"admin@talos-dev", "talos-dev", clusterid.ProductUnknown},
+	}
+	for _, c := range cases {
+		t.Run(c.context, func(t *testing.T) {
+			config := apiConfigForCluster(c.context, c.cluster)
+			assert.Equal(t, c.product, ClusterProductFromAPIConfig(config))
+		})
+	}
+}
+
+func TestIsDevCluster(t *testing.T) {
+	assert.True(t, IsDevCluster(clusterid.ProductKIND))
+	assert.True(t, IsDevCluster(ProductVCluster))
+	assert.True(t, IsDevCluster(ProductOrbStack))
+	assert.False(t, IsDevCluster(clusterid.ProductGKE))
+	assert.False(t, IsDevCluster(clusterid.ProductUnknown))
+}
+
+func TestClusterProfileDeclared(t *testing.T) {
+	path := writeClusterConfig(t, `
+clusters:
+- context: admin@talos-*
+  product: talos
+  devCluster: true
+  imageLoad: registry
+  registry:
+    host: localhost:5005
+- context: "*"
+  devCluster: false
+`)
+	clusterConfig := LoadClusterConfig(path)
+	require.NoError(t, clusterConfig.Error)
+
+	profile := ClusterProfileFromAPIConfig(apiConfigForCluster("admin@talos-dev", "talos-dev"), clusterConfig, nil)
+	assert.Equal(t, clusterid.Product("talos"), profile.Product)
+	assert.True(t, profile.DevCluster)
+	assert.Equal(t, v1alpha1.ClusterImageLoadRegistry, profile.ImageLoad)
+	if assert.NotNil(t, profile.Registry) {
+		assert.Equal(t, "localhost:5005", profile.Registry.Host)
+	}
+
+	// The first matching entry wins.
+	profile = ClusterProfileFromAPIConfig(apiConfigForCluster("kind-kind", "kind-kind"), clusterConfig, nil)
+	assert.Equal(t, clusterid.ProductKIND, profile.Product)
+	assert.False(t, profile.DevCluster)
+	assert.Equal(t, v1alpha1.ClusterImageLoad(""), profile.ImageLoad)
+}
+
+func TestClusterProfileSpecOverridesConfig(t *testing.T) {
+	path := writeClusterConfig(t, `
+clusters:
+- context: my-kind
+  devCluster: true
+  imageLoad: registry
+`)
+	clusterConfig := LoadClusterConfig(path)
+	require.NoError(t, clusterConfig.Error)
+
+	profile := ClusterProfileFromAPIConfig(apiConfigForCluster("my-kind", "my-kind"), clusterConfig,
+		&v1alpha1.KubernetesClusterConnection{
+			Product:   "kind",
+			ImageLoad: v1alpha1.ClusterImageLoadKind,
+		})
+	assert.Equal(t, clusterid.ProductKIND, profile.Product)
+	assert.True(t, profile.DevCluster)
+	assert.Equal(t, v1alpha1.ClusterImageLoadKind, profile.ImageLoad)
+
+	// A declared product brings its own defaults.
+	profile = ClusterProfileFromAPIConfig(apiConfigForCluster("my-vcluster", "my-vcluster"), ClusterConfig{},
+		&v1alpha1.KubernetesClusterConnection{Product: string(ProductVCluster)})
+	assert.Equal(t, ProductVCluster, profile.Product)
+	assert.True(t, profile.DevCluster)
+}
+
+func TestProvideClusterProfileWarnsOnConfigError(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(out))
+	clusterConfig := ClusterConfig{Error: fmt.Errorf("clusters[0]: unsupported imageLoad \"scp\"")}
+
+	profile := ProvideClusterProfile(ctx, APIConfigOrError{Config: apiConfigForCluster("kind-kind", "kind-kind")}, clusterConfig)
+	assert.Equal(t, clusterid.ProductKIND, profile.Product)
+	assert.Contains(t, out.String(), `Loading cluster config: clusters[0]: unsupported imageLoad "scp"`)
+}
+
+func TestClusterProfileForContext(t *testing.T) {
+	config := apiConfigForCluster("gke_prod", "gke_prod")
+	config.Contexts["kind-edge"] = &api.Context{Cluster: "kind-edge"}
+	config.Clusters["kind-edge"] = &api.Cluster{Server: "https://127.0.0.1:6444"}
+
+	profile := ClusterProfileForContext(config, ClusterConfig{}, "kind-edge", nil)
+	assert.Equal(t, clusterid.ProductKIND, profile.Product)
+	assert.True(t, profile.DevCluster)
+	assert.Equal(t, "gke_prod", config.CurrentContext)
+
+	profile = ClusterProfileForContext(config, ClusterConfig{}, "missing", nil)
+	assert.Equal(t, clusterid.ProductUnknown, profile.Product)
+	assert.False(t, profile.DevCluster)
+
+	profile = ClusterProfileForContext(nil, ClusterConfig{}, "kind-edge", nil)
+	assert.Equal(t, clusterid.ProductUnknown, profile.Product)
+}
+
+type fakeRuntimeBuilder struct {
+	kctx KubeContext
+}
+
+func (b fakeRuntimeBuilder) WillBuildToKubeContext(kctx KubeContext) bool {
+	return kctx == b.kctx
+}
+
+func TestBuildsToClusterRuntime(t *testing.T) {
+	b := fakeRuntimeBuilder{kctx: "docker-desktop"}
+	clusterWithImageLoad := func(imageLoad v1alpha1.ClusterImageLoad) *v1alpha1.Cluster {
+		return &v1alpha1.Cluster{Status: v1alpha1.ClusterStatus{
+			Connection: &v1alpha1.ClusterConnectionStatus{
+				Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{ImageLoad: imageLoad},
+			},
+		}}
+	}
+
+	assert.True(t, BuildsToClusterRuntime(b, nil, "docker-desktop"))
+	assert.False(t, BuildsToClusterRuntime(b, nil, "gke_prod"))
+	assert.True(t, BuildsToClusterRuntime(b, clusterWithImageLoad(""), "docker-desktop"))
+	assert.True(t, BuildsToClusterRuntime(b, clusterWithImageLoad(v1alpha1.ClusterImageLoadDocker), "gke_prod"))
+	assert.False(t, BuildsToClusterRuntime(b, clusterWithImageLoad(v1alpha1.ClusterImageLoadRegistry), "docker-desktop"))
+	assert.False(t, BuildsToClusterRuntime(b, clusterWithImageLoad(v1alpha1.ClusterImageLoadKind), "docker-desktop"))
+}
+
+func TestLoadClusterConfigMissing(t *testing.T) {
+	clusterConfig := LoadClusterConfig(filepath.Join(t.TempDir(), ClusterConfigFileName))
+	assert.NoError(t, clusterConfig.Error)
+	assert.Empty(t, clusterConfig.Clusters)
+}
+
+func TestLoadClusterConfigInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		contents string
+		err      string
+	}{
+		{"unknown field", "clusters:\n- context: foo\n  dev: true\n", `unknown field "dev"`},
+		{"no context", "clusters:\n- product: talos\n", `clusters[0]: invalid context pattern ""`},
+		{"bad image load", "clusters:\n- context: foo\n  imageLoad: scp\n", `clusters[0]: unsupported imageLoad "scp"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clusterConfig := LoadClusterConfig(writeClusterConfig(t, c.contents))
+			if assert.Error(t, clusterConfig.Error) {
+				assert.Contains(t, clusterConfig.Error.Error(), c.err)
+			}
+		})
+	}
+}
+
+func apiConfigForCluster(context, cluster string) *api.Config {
+	return &api.Config{
+		CurrentContext: context,
+		Contexts: map[string]*api.Context{
+			context: {Cluster: cluster},
+		},
+		Clusters: map[string]*api.Cluster{
+			cluster: {Server: "https://127.0.0.1:6443"},
+		},
+	}
+}
+
+func writeClusterConfig(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), ClusterConfigFileName)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
@@ -10,11 +10,27 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
 	yamlEncoder "sigs.k8s.io/yaml"
 )
 
+// Decodes the built-in Kubernetes types.
+//
+// We keep our own scheme rather than using scheme.Scheme, because libraries
+// register extra types in scheme.Scheme on init. (The Helm SDK adds the
+// apiextensions types, which would make us reject CRDs that the apiserver
+// would happily accept.)
+var builtinDecoder = newBuiltinDecoder()
+
+func newBuiltinDecoder() runtime.Decoder {
+	s := runtime.NewScheme()
+	utilruntime.Must(scheme.AddToScheme(s))
+	return serializer.NewCodecFactory(s).UniversalDeserializer()
+}
+
 func ParseYAMLFromString(yaml string) ([]K8sEntity, error) {
 	buf := bytes.NewBuffer([]byte(yaml))
 	return ParseYAML(buf)
@@ -45,7 +61,7 @@ func decodeToRuntimeObj(ext runtime.RawExtension) (runtime.Object, error) {
 	}
 
 	obj, _, decodeErr :=
-		scheme.Codecs.UniversalDeserializer().Decode(ext.Raw, nil, nil)
+		builtinDecoder.Decode(ext.Raw, nil, nil)
 	if decodeErr == nil {
 		return obj, nil
 	}
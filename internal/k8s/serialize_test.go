@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/client-go/kubernetes/scheme"
 
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 )
@@ -74,6 +77,16 @@ func TestHelmGeneratedRedisYAML(t *testing.T) {
 	}, kinds)
 }
 
+// Libraries like the Helm SDK register the apiextensions types in
+// scheme.Scheme. That shouldn't change how we parse CRDs.
+func TestCRDYAMLWithApiextensionsInScheme(t *testing.T) {
+	require.NoError(t, apiextensionsv1beta1.AddToScheme(scheme.Scheme))
+
+	entities := assertRoundTripYAML(t, testyaml.CRDYAML)
+	assert.Equal(t, 2, len(entities))
+	assert.Equal(t, "CustomResourceDefinition", entities[0].GVK().Kind)
+}
+
 func TestCRDYAML(t *testing.T) {
 	entities := assertRoundTripYAML(t, testyaml.CRDYAML)
 	assert.Equal(t, 2, len(entities))
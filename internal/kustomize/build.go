@@ -0,0 +1,372 @@
+package kustomize
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/imdario/mergo"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+
+	"github.com/tilt-dev/tilt/internal/helm"
+)
+
+// Builds kustomizations in-process with the kustomize API, like
+// `kustomize build`, so that we don't depend on a kustomize binary.
+//
+// Two features of `kustomize build` shell out to other tools, so we handle
+// them ourselves:
+//
+// - Remote bases are cloned with git into a local cache, instead of a temp
+// dir on every build.
+//
+// - Charts in helmCharts are rendered with the Helm SDK, like
+// `kustomize build --enable-helm`, but without the helm binary.
+//
+// We do this by rewriting each kustomization as kustomize reads it, so that
+// remote bases and charts become local bases.
+type Builder struct {
+	cacheDir string
+	charts   *helm.ChartFetcher
+	git      GitRunner
+
+	// Guards the cache of remote bases.
+	mu sync.Mutex
+}
+
+// Runs git with the given args in dir, to clone remote bases.
+type GitRunner func(ctx context.Context, dir string, args ...string) error
+
+func NewBuilder(cacheDir string, charts *helm.ChartFetcher) *Builder {
+	return NewBuilderWithGit(cacheDir, charts, runGit)
+}
+
+// A Builder that clones remote bases with the given git runner,
+// e.g., in tests.
+func NewBuilderWithGit(cacheDir string, charts *helm.ChartFetcher, git GitRunner) *Builder {
+	return &Builder{cacheDir: cacheDir, charts: charts, git: git}
+}
+
+// Builds the kustomization in dir, and returns the YAML.
+func (b *Builder) Build(ctx context.Context, dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	fSys := &rewritingFS{
+		FileSystem: filesys.MakeFsOnDisk(),
+		ctx:        ctx,
+		builder:    b,
+		fetched:    make(map[string]bool),
+	}
+
+	opts := krusty.MakeDefaultOptions()
+	// Match the default output order of `kustomize build`.
+	opts.DoLegacyResourceSort = true
+	m, err := krusty.MakeKustomizer(opts).Run(fSys, dir)
+	if err != nil {
+		// kustomize treats a kustomization that it can't read as missing,
+		// so our own errors are more helpful.
+		if fSys.err != nil {
+			return "", fSys.err
+		}
+		return "", err
+	}
+
+	out, err := m.AsYaml()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// A FileSystem that rewrites kustomizations as kustomize reads them.
+type rewritingFS struct {
+	filesys.FileSystem
+
+	ctx     context.Context
+	builder *Builder
+
+	mu  sync.Mutex
+	err error
+
+	// Remote bases that we've cloned during this build.
+	fetched map[string]bool
+}
+
+func (fs *rewritingFS) ReadFile(path string) ([]byte, error) {
+	content, err := fs.FileSystem.ReadFile(path)
+	if err != nil || !isKustomizationFile(path) {
+		return content, err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	content, err = fs.builder.rewrite(fs.ctx, filepath.Dir(path), content, fs.fetched)
+	if err != nil {
+		err = fmt.Errorf("%s: %v", path, err)
+		if fs.err == nil {
+			fs.err = err
+		}
+		return nil, err
+	}
+	return content, nil
+}
+
+func isKustomizationFile(path string) bool {
+	base := filepath.Base(path)
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Replaces remote bases with paths to our clones, and charts with bases
+// that hold the rendered chart. Returns the content unchanged if there's
+// nothing to replace.
+func (b *Builder) rewrite(ctx context.Context, dir string, content []byte, fetched map[string]bool) ([]byte, error) {
+	var k types.Kustomization
+	var raw map[string]interface{}
+	if yaml.Unmarshal(content, &k) != nil || yaml.Unmarshal(content, &raw) != nil {
+		// Let kustomize report the error.
+		return content, nil
+	}
+
+	changed := false
+	for _, field := range []string{"resources", "bases", "components"} {
+		entries, ok := raw[field].([]interface{})
+		if !ok {
+			continue
+		}
+		for i, entry := range entries {
+			s, ok := entry.(string)
+			if !ok {
+				continue
+			}
+			remote, ok := parseRemoteBase(s)
+			if !ok {
+				continue
+			}
+			cloneDir, err := b.cloneRemoteBase(ctx, remote, fetched)
+			if err != nil {
+				return nil, err
+			}
+			path, err := relBase(dir, filepath.Join(cloneDir, filepath.FromSlash(remote.subPath)))
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = path
+			changed = true
+		}
+	}
+
+	charts, globals := k.HelmCharts, k.HelmGlobals
+	if len(k.HelmChartInflationGenerator) > 0 {
+		oldCharts, oldGlobals := types.SplitHelmParameters(k.HelmChartInflationGenerator)
+		charts = append(charts, oldCharts...)
+		if globals == nil {
+			globals = &oldGlobals
+		}
+	}
+	if len(charts) > 0 {
+		resources, _ := raw["resources"].([]interface{})
+		for i, chart := range charts {
+			baseDir, err := b.inflateHelmChart(dir, i, chart, globals)
+			if err != nil {
+				return nil, fmt.Errorf("helm chart %s: %v", chart.Name, err)
+			}
+			path, err := relBase(dir, baseDir)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, path)
+		}
+		raw["resources"] = resources
+		delete(raw, "helmCharts")
+		delete(raw, "helmGlobals")
+		delete(raw, "helmChartInflationGenerator")
+		changed = true
+	}
+
+	if !changed {
+		return content, nil
+	}
+	return yaml.Marshal(raw)
+}
+
+// kustomize only accepts relative paths to bases.
+func relBase(dir, base string) (string, error) {
+	rel, err := filepath.Rel(dir, base)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Renders a chart into a base in the cache, and returns the base's path.
+//
+// Follows the semantics of kustomize's HelmChartInflationGenerator:
+// https://github.com/kubernetes-sigs/kustomize/blob/master/examples/chart.md
+func (b *Builder) inflateHelmChart(dir string, index int, chart types.HelmChart, globals *types.HelmGlobals) (string, error) {
+	if chart.Name == "" {
+		return "", fmt.Errorf("chart name cannot be empty")
+	}
+
+	chartHome := "charts"
+	if globals != nil && globals.ChartHome != "" {
+		chartHome = globals.ChartHome
+	}
+	if !filepath.IsAbs(chartHome) {
+		chartHome = filepath.Join(dir, chartHome)
+	}
+
+	// A chart in the chart home is used as-is. Otherwise, we fetch it.
+	chartPath := filepath.Join(chartHome, chart.Name)
+	defaultValuesFile := filepath.Join(chartPath, "values.yaml")
+	_, err := os.Stat(chartPath)
+	if os.IsNotExist(err) {
+		if chart.Repo == "" {
+			return "", fmt.Errorf("no repo specified for pull, no chart found at '%s'", chartPath)
+		}
+		if b.charts == nil {
+			return "", fmt.Errorf("fetching remote charts is not supported")
+		}
+		chartPath, err = b.charts.Fetch(helm.NewRepoURLChartRef(chart.Repo, chart.Name, chart.Version))
+		if err != nil {
+			return "", err
+		}
+		// The chart's own values.yaml is always applied.
+		defaultValuesFile = ""
+	} else if err != nil {
+		return "", err
+	}
+
+	ch, err := helm.LoadChart(chartPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Put the base in a directory that's stable across builds, so that
+	// renders of the same chart don't pile up in the cache.
+	baseDir := filepath.Join(b.cacheDir, "helm",
+		fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", dir, index))))[:16])
+	err = os.MkdirAll(baseDir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	valuesFile := chart.ValuesFile
+	if valuesFile == "" {
+		valuesFile = defaultValuesFile
+	} else if !filepath.IsAbs(valuesFile) {
+		valuesFile = filepath.Join(dir, valuesFile)
+	}
+
+	var valueFiles []string
+	if len(chart.ValuesInline) == 0 {
+		if valuesFile != "" {
+			valueFiles = append(valueFiles, valuesFile)
+		}
+	} else {
+		values, err := mergeInlineValues(valuesFile, chart.ValuesInline, chart.ValuesMerge)
+		if err != nil {
+			return "", err
+		}
+		contents, err := yaml.Marshal(values)
+		if err != nil {
+			return "", err
+		}
+		path := filepath.Join(baseDir, "values.yaml")
+		err = writeFileAtomic(path, contents)
+		if err != nil {
+			return "", err
+		}
+		valueFiles = append(valueFiles, path)
+	}
+
+	out, err := helm.Template(ch, helm.TemplateOptions{
+		ReleaseName: chart.ReleaseName,
+		Namespace:   chart.Namespace,
+		ValueFiles:  valueFiles,
+		SkipCRDs:    !chart.IncludeCRDs,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = writeFileAtomic(filepath.Join(baseDir, "chart.yaml"), []byte(out))
+	if err != nil {
+		return "", err
+	}
+	err = writeFileAtomic(filepath.Join(baseDir, konfig.DefaultKustomizationFileName()),
+		[]byte("resources:\n- chart.yaml\n"))
+	if err != nil {
+		return "", err
+	}
+	return baseDir, nil
+}
+
+// Combines a chart's values file with its valuesInline, according to its valuesMerge.
+func mergeInlineValues(valuesFile string, inline map[string]interface{}, mergeOption string) (map[string]interface{}, error) {
+	switch mergeOption {
+	case "", "override", "merge":
+	case "replace":
+		return inline, nil
+	default:
+		return nil, fmt.Errorf("valuesMerge must be one of [merge override replace]")
+	}
+
+	values := make(map[string]interface{})
+	if valuesFile != "" {
+		contents, err := os.ReadFile(valuesFile)
+		if err != nil {
+			return nil, err
+		}
+		err = yaml.Unmarshal(contents, &values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", valuesFile, err)
+		}
+	}
+
+	var err error
+	if mergeOption == "merge" {
+		err = mergo.Merge(&values, inline)
+	} else {
+		err = mergo.Merge(&values, inline, mergo.WithOverride)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Writes the file, so that a concurrent build of the same
+// kustomization never reads half of it.
+func writeFileAtomic(path string, contents []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(contents)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,347 @@
+package kustomize
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/tilt-dev/tilt/internal/helm"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+const buildDeploymentYAML = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: the-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+`
+
+const buildServiceYAML = `apiVersion: v1
+kind: Service
+metadata:
+  name: the-service
+`
+
+const buildChartYAML = `apiVersion: v2
+name: hello
+version: 1.0.0
+`
+
+const buildChartValuesYAML = `greeting: hello
+color: blue
+`
+
+const buildChartConfigMapYAML = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-config
+data:
+  greeting: {{ .Values.greeting }}
+  color: {{ .Values.color }}
+`
+
+const buildChartCRDYAML = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+`
+
+func TestBuild(t *testing.T) {
+	f := newBuildFixture(t)
+	f.WriteFile("app/deployment.yaml", buildDeploymentYAML)
+	f.WriteFile("app/service.yaml", buildServiceYAML)
+	f.WriteFile("app/kustomization.yaml", `namePrefix: dev-
+resources:
+- deployment.yaml
+- service.yaml
+images:
+- name: app
+  newTag: v2
+`)
+
+	out := f.build("app")
+
+	// Sorted like `kustomize build`, with services first.
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: dev-the-service
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dev-the-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - image: app:v2
+        name: app
+`, out)
+}
+
+func TestBuildMissingKustomization(t *testing.T) {
+	f := newBuildFixture(t)
+	f.WriteFile("app/deployment.yaml", buildDeploymentYAML)
+
+	_, err := f.builder.Build(context.Background(), f.JoinPath("app"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to find one of 'kustomization.yaml', 'kustomization.yml' or 'Kustomization'")
+}
+
+func TestBuildHelmChart(t *testing.T) {
+	f := newBuildFixture(t)
+	f.writeChart("app/charts/hello")
+	f.WriteFile("app/kustomization.yaml", `namespace: garnet
+helmCharts:
+- name: hello
+  releaseName: rose-quartz
+  valuesInline:
+    greeting: howdy
+`)
+
+	out := f.build("app")
+	assert.Contains(t, out, "name: rose-quartz-config")
+	assert.Contains(t, out, "namespace: garnet")
+	assert.Contains(t, out, "greeting: howdy")
+	assert.Contains(t, out, "color: blue")
+
+	// Like kustomize, CRDs are left out unless asked for.
+	assert.NotContains(t, out, "widgets.example.com")
+}
+
+func TestBuildHelmChartIncludeCRDs(t *testing.T) {
+	f := newBuildFixture(t)
+	f.writeChart("app/charts/hello")
+	f.WriteFile("app/kustomization.yaml", `helmCharts:
+- name: hello
+  includeCRDs: true
+`)
+
+	out := f.build("app")
+	assert.Contains(t, out, "name: widgets.example.com")
+}
+
+func TestBuildHelmChartValuesFile(t *testing.T) {
+	f := newBuildFixture(t)
+	f.writeChart("app/charts/hello")
+	f.WriteFile("app/dev-values.yaml", "greeting: hiya\ncolor: red\n")
+	f.WriteFile("app/kustomization.yaml", `helmCharts:
+- name: hello
+  valuesFile: dev-values.yaml
+  valuesInline:
+    color: green
+  valuesMerge: merge
+`)
+
+	out := f.build("app")
+	assert.Contains(t, out, "greeting: hiya")
+	// With merge, the values file wins over the inline values.
+	assert.Contains(t, out, "color: red")
+}
+
+func TestBuildHelmChartValuesReplace(t *testing.T) {
+	f := newBuildFixture(t)
+	f.writeChart("app/charts/hello")
+	f.WriteFile("app/dev-values.yaml", "greeting: hiya\n")
+	f.WriteFile("app/kustomization.yaml", `helmCharts:
+- name: hello
+  valuesFile: dev-values.yaml
+  valuesInline:
+    color: green
+  valuesMerge: replace
+`)
+
+	out := f.build("app")
+	// The values file is ignored, but the chart's defaults still apply.
+	assert.Contains(t, out, "greeting: hello")
+	assert.Contains(t, out, "color: green")
+}
+
+func TestBuildHelmChartWithResources(t *testing.T) {
+	f := newBuildFixture(t)
+	f.writeChart("app/charts/hello")
+	f.WriteFile("app/service.yaml", buildServiceYAML)
+	f.WriteFile("app/kustomization.yaml", `commonLabels:
+  team: gems
+resources:
+- service.yaml
+helmCharts:
+- name: hello
+`)
+
+	out := f.build("app")
+	assert.Contains(t, out, "name: the-service")
+	assert.Contains(t, out, "name: chart-config")
+	assert.Contains(t, out, "team: gems")
+}
+
+func TestBuildHelmChartMissing(t *testing.T) {
+	f := newBuildFixture(t)
+	f.WriteFile("app/kustomization.yaml", `helmCharts:
+- name: hello
+`)
+
+	_, err := f.builder.Build(context.Background(), f.JoinPath("app"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "helm chart hello: no repo specified for pull, no chart found at")
+}
+
+func TestBuildHelmChartFromRepo(t *testing.T) {
+	f := newBuildFixture(t)
+	t.Setenv("HELM_CONFIG_HOME", f.JoinPath("helm-config"))
+	t.Setenv("HELM_CACHE_HOME", f.JoinPath("helm-cache"))
+	f.builder = NewBuilder(f.JoinPath("cache"), helm.NewChartFetcher(f.JoinPath("charts-cache")))
+
+	f.writeChart("src/hello")
+	ch, err := helm.LoadChart(f.JoinPath("src/hello"))
+	require.NoError(t, err)
+	chartPath, err := chartutil.Save(ch, f.JoinPath("server"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.FileServer(http.Dir(f.JoinPath("server"))))
+	t.Cleanup(server.Close)
+
+	digest, err := provenance.DigestFile(chartPath)
+	require.NoError(t, err)
+	index := repo.NewIndexFile()
+	require.NoError(t, index.MustAdd(ch.Metadata, filepath.Base(chartPath), server.URL, digest))
+	require.NoError(t, index.WriteFile(f.JoinPath("server", "index.yaml"), 0644))
+
+	f.WriteFile("app/kustomization.yaml", `helmCharts:
+- name: hello
+  repo: `+server.URL+`
+  version: 1.0.0
+  releaseName: rose-quartz
+`)
+
+	out := f.build("app")
+	assert.Contains(t, out, "name: rose-quartz-config")
+
+	// The chart isn't written to the chart home, like kustomize would.
+	assert.NoFileExists(t, f.JoinPath("app/charts/hello/Chart.yaml"))
+}
+
+func TestBuildRemoteBase(t *testing.T) {
+	f := newBuildFixture(t)
+	repoURL := f.gitRepo("remote", map[string]string{
+		"base/deployment.yaml":    buildDeploymentYAML,
+		"base/kustomization.yaml": "resources:\n- deployment.yaml\n",
+	})
+
+	f.WriteFile("app/kustomization.yaml", `namePrefix: dev-
+resources:
+- `+repoURL+`//base
+`)
+
+	out := f.build("app")
+	assert.Contains(t, out, "name: dev-the-deployment")
+
+	// The clone is cached.
+	entries, err := os.ReadDir(f.JoinPath("cache", "git"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+}
+
+func TestBuildRemoteBaseCloneError(t *testing.T) {
+	f := newBuildFixture(t)
+	f.WriteFile("app/kustomization.yaml", `resources:
+- file://`+filepath.ToSlash(f.JoinPath("nope"))+`//base
+`)
+
+	_, err := f.builder.Build(context.Background(), f.JoinPath("app"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cloning file://")
+	assert.Contains(t, err.Error(), "git fetch")
+}
+
+func TestParseRemoteBase(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected remoteBase
+		ok       bool
+	}{
+		{"github.com/org/repo/deploy/base?ref=v1.0.0", remoteBase{repoURL: "https://github.com/org/repo", ref: "v1.0.0", subPath: "deploy/base"}, true},
+		{"https://github.com/org/repo/deploy/base?version=main", remoteBase{repoURL: "https://github.com/org/repo", ref: "main", subPath: "deploy/base"}, true},
+		{"https://github.com/org/repo", remoteBase{repoURL: "https://github.com/org/repo"}, true},
+		{"git::https://example.com/org/repo.git//deploy/base?ref=abc", remoteBase{repoURL: "https://example.com/org/repo.git", ref: "abc", subPath: "deploy/base"}, true},
+		{"https://example.com/org/repo.git/deploy", remoteBase{repoURL: "https://example.com/org/repo.git", subPath: "deploy"}, true},
+		{"git@github.com:org/repo.git/deploy?ref=v2", remoteBase{repoURL: "git@github.com:org/repo.git", ref: "v2", subPath: "deploy"}, true},
+		{"ssh://git@example.com/org/repo.git", remoteBase{repoURL: "ssh://git@example.com/org/repo.git"}, true},
+		{"https://raw.githubusercontent.com/org/repo/main/deploy.yaml", remoteBase{}, false},
+		{"../base", remoteBase{}, false},
+		{"deployment.yaml", remoteBase{}, false},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, ok := parseRemoteBase(tc.input)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestRemoteBaseHasExactRef(t *testing.T) {
+	assert.True(t, remoteBase{ref: "v1.2.3"}.hasExactRef())
+	assert.True(t, remoteBase{ref: "0123456789abcdef0123456789abcdef01234567"}.hasExactRef())
+	assert.False(t, remoteBase{ref: "main"}.hasExactRef())
+	assert.False(t, remoteBase{}.hasExactRef())
+}
+
+type buildFixture struct {
+	*tempdir.TempDirFixture
+	builder *Builder
+}
+
+func newBuildFixture(t *testing.T) *buildFixture {
+	f := tempdir.NewTempDirFixture(t)
+	return &buildFixture{
+		TempDirFixture: f,
+		builder:        NewBuilder(f.JoinPath("cache"), nil),
+	}
+}
+
+func (f *buildFixture) build(dir string) string {
+	out, err := f.builder.Build(context.Background(), f.JoinPath(dir))
+	require.NoError(f.T(), err)
+	return out
+}
+
+func (f *buildFixture) writeChart(dir string) {
+	f.WriteFile(dir+"/Chart.yaml", buildChartYAML)
+	f.WriteFile(dir+"/values.yaml", buildChartValuesYAML)
+	f.WriteFile(dir+"/templates/configmap.yaml", buildChartConfigMapYAML)
+	f.WriteFile(dir+"/crds/widget.yaml", buildChartCRDYAML)
+}
+
+// Creates a git repo with one commit, and returns its URL.
+func (f *buildFixture) gitRepo(dir string, files map[string]string) string {
+	for path, contents := range files {
+		f.WriteFile(filepath.Join(dir, path), contents)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = f.JoinPath(dir)
+		out, err := cmd.CombinedOutput()
+		require.NoError(f.T(), err, string(out))
+	}
+	return "file://" + filepath.ToSlash(f.JoinPath(dir))
+}
@@ -87,8 +87,14 @@ func dependenciesForKustomization(dir string) ([]string, error) {
 
 	paths := append([]string{}, content.Bases...)
 	paths = append(paths, content.Resources...)
+	paths = append(paths, content.Components...)
 
 	for _, p := range paths {
+		// Remote bases are fetched into a cache, so there's nothing to watch.
+		if _, ok := parseRemoteBase(p); ok {
+			continue
+		}
+
 		abs := filepath.Join(dir, p)
 		if ospath.IsDir(abs) {
 			curDeps, err := dependenciesForKustomization(filepath.Join(dir, p))
@@ -117,10 +123,48 @@ func dependenciesForKustomization(dir string) ([]string, error) {
 	for _, generator := range content.ConfigMapGenerator {
 		deps = append(deps, joinPaths(dir, generator.FileSources)...)
 	}
+	deps = append(deps, helmChartDependencies(dir, content)...)
 
 	return deps, nil
 }
 
+// Local charts and values files for helmCharts. Charts that we fetch
+// from a repo don't need to be watched.
+func helmChartDependencies(dir string, content types.Kustomization) []string {
+	charts, globals := content.HelmCharts, content.HelmGlobals
+	if len(content.HelmChartInflationGenerator) > 0 {
+		oldCharts, oldGlobals := types.SplitHelmParameters(content.HelmChartInflationGenerator)
+		charts = append(charts, oldCharts...)
+		if globals == nil {
+			globals = &oldGlobals
+		}
+	}
+
+	chartHome := "charts"
+	if globals != nil && globals.ChartHome != "" {
+		chartHome = globals.ChartHome
+	}
+	if !filepath.IsAbs(chartHome) {
+		chartHome = filepath.Join(dir, chartHome)
+	}
+
+	var deps []string
+	for _, chart := range charts {
+		chartPath := filepath.Join(chartHome, chart.Name)
+		if ospath.IsDir(chartPath) {
+			deps = append(deps, chartPath)
+		}
+		if chart.ValuesFile != "" {
+			if filepath.IsAbs(chart.ValuesFile) {
+				deps = append(deps, chart.ValuesFile)
+			} else {
+				deps = append(deps, filepath.Join(dir, chart.ValuesFile))
+			}
+		}
+	}
+	return deps
+}
+
 func Deps(baseDir string) ([]string, error) {
 	deps, err := dependenciesForKustomization(baseDir)
 	if err != nil {
@@ -161,6 +161,34 @@ patches:
 	f.assertDeps(expected)
 }
 
+func TestRemoteBases(t *testing.T) {
+	f := newKustomizeFixture(t)
+	kustomizeFile := `
+resources:
+- deployment.yaml
+- github.com/org/repo/deploy/base?ref=v1.0.0
+- https://example.com/org/repo.git//base`
+	f.writeRootKustomize(kustomizeFile)
+
+	expected := []string{"kustomization.yaml", "deployment.yaml"}
+	f.assertDeps(expected)
+}
+
+func TestHelmCharts(t *testing.T) {
+	f := newKustomizeFixture(t)
+	f.writeBaseFile("charts/hello", "Chart.yaml", "name: hello")
+	kustomizeFile := `
+helmCharts:
+- name: hello
+  valuesFile: dev-values.yaml
+- name: nginx
+  repo: https://charts.bitnami.com/bitnami`
+	f.writeRootKustomize(kustomizeFile)
+
+	expected := []string{"kustomization.yaml", "charts/hello", "dev-values.yaml"}
+	f.assertDeps(expected)
+}
+
 type kustomizeFixture struct {
 	t       *testing.T
 	tempdir *tempdir.TempDirFixture
@@ -0,0 +1,218 @@
+package kustomize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// A base in a git repo, like
+// https://github.com/org/repo/deploy/base?ref=v1.0.0
+//
+// We understand the same forms as kustomize:
+// https://github.com/kubernetes-sigs/kustomize/blob/master/examples/remoteBuild.md
+type remoteBase struct {
+	// The URL that we pass to git.
+	repoURL string
+
+	// A branch, tag, or commit. If empty, the default branch.
+	ref string
+
+	// The path of the kustomization in the repo.
+	subPath string
+}
+
+// Hosts where a repo is always host/org/repo.
+var knownGitHosts = []string{"github.com", "gitlab.com", "bitbucket.org"}
+
+// Parses a resource, base, or component that lives in a git repo.
+//
+// Returns false for anything else, including http(s) URLs of single files,
+// which kustomize downloads itself.
+func parseRemoteBase(s string) (remoteBase, bool) {
+	rest := s
+	var query string
+	if i := strings.Index(rest, "?"); i != -1 {
+		rest, query = rest[:i], rest[i+1:]
+	}
+	rest = strings.TrimPrefix(rest, "git::")
+
+	// Split the URL into the part that names the host, and the path.
+	var prefix string
+	switch {
+	case strings.Contains(rest, "://"):
+		i := strings.Index(rest, "://") + len("://")
+		j := strings.Index(rest[i:], "/")
+		if j == -1 {
+			return remoteBase{}, false
+		}
+		prefix, rest = rest[:i+j+1], rest[i+j+1:]
+	case strings.HasPrefix(rest, "git@"):
+		i := strings.Index(rest, ":")
+		if i == -1 {
+			return remoteBase{}, false
+		}
+		prefix, rest = rest[:i+1], rest[i+1:]
+	default:
+		for _, host := range knownGitHosts {
+			if strings.HasPrefix(rest, host+"/") {
+				prefix, rest = "https://"+host+"/", strings.TrimPrefix(rest, host+"/")
+				break
+			}
+		}
+		if prefix == "" {
+			return remoteBase{}, false
+		}
+	}
+
+	var repoPath, subPath string
+	if i := strings.Index(rest, "//"); i != -1 {
+		repoPath, subPath = rest[:i], rest[i+2:]
+	} else if i := strings.Index(rest, ".git/"); i != -1 {
+		repoPath, subPath = rest[:i+len(".git")], rest[i+len(".git/"):]
+	} else if strings.HasSuffix(rest, ".git") {
+		repoPath = rest
+	} else if isKnownGitHost(prefix) {
+		parts := strings.SplitN(rest, "/", 3)
+		if len(parts) < 2 {
+			return remoteBase{}, false
+		}
+		repoPath = parts[0] + "/" + parts[1]
+		if len(parts) == 3 {
+			subPath = parts[2]
+		}
+	} else {
+		return remoteBase{}, false
+	}
+
+	result := remoteBase{
+		repoURL: prefix + repoPath,
+		subPath: strings.Trim(subPath, "/"),
+	}
+
+	values, err := url.ParseQuery(query)
+	if err == nil {
+		result.ref = values.Get("ref")
+		if result.ref == "" {
+			result.ref = values.Get("version")
+		}
+	}
+	return result, true
+}
+
+func isKnownGitHost(prefix string) bool {
+	for _, host := range knownGitHosts {
+		if strings.HasSuffix(prefix, "://"+host+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+var commitRE = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// Whether the ref always names the same commit, so that once we've cloned
+// it, we never need to fetch it again.
+func (r remoteBase) hasExactRef() bool {
+	if commitRE.MatchString(r.ref) {
+		return true
+	}
+	_, err := semver.StrictNewVersion(strings.TrimPrefix(r.ref, "v"))
+	return err == nil
+}
+
+func (r remoteBase) String() string {
+	if r.ref == "" {
+		return r.repoURL
+	}
+	return fmt.Sprintf("%s@%s", r.repoURL, r.ref)
+}
+
+var unsafePathCharsRE = regexp.MustCompile(`[^a-zA-Z0-9._@-]+`)
+
+// A directory name for the repo in the cache.
+func (r remoteBase) cacheKey() string {
+	return unsafePathCharsRE.ReplaceAllString(r.String(), "_")
+}
+
+// Clones the repo into the cache, and returns the path of the clone.
+//
+// A repo at a commit or a version tag is only cloned once. Otherwise,
+// we clone it again, so that we pick up changes to the branch.
+func (b *Builder) cloneRemoteBase(ctx context.Context, r remoteBase, fetched map[string]bool) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cloneDir := filepath.Join(b.cacheDir, "git", r.cacheKey())
+	if r.hasExactRef() || fetched[cloneDir] {
+		_, err := os.Stat(cloneDir)
+		if err == nil {
+			return cloneDir, nil
+		}
+	}
+
+	// Clone into a temp dir and move it into place, so that we never
+	// leave half a repo in the cache.
+	err := os.MkdirAll(filepath.Dir(cloneDir), 0755)
+	if err != nil {
+		return "", err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(cloneDir), ".clone-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	ref := r.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"remote", "add", "origin", r.repoURL},
+		{"fetch", "--depth=1", "origin", ref},
+		{"checkout", "FETCH_HEAD"},
+		{"submodule", "update", "--init", "--recursive"},
+	} {
+		err := b.git(ctx, tmpDir, args...)
+		if err != nil {
+			return "", fmt.Errorf("cloning %s: %v", r, err)
+		}
+	}
+
+	err = os.RemoveAll(cloneDir)
+	if err != nil {
+		return "", err
+	}
+	err = os.Rename(tmpDir, cloneDir)
+	if err != nil {
+		return "", err
+	}
+	fetched[cloneDir] = true
+	return cloneDir, nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Never prompt for credentials. We're not attached to a terminal.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("git %s: %v\n%s", strings.Join(args, " "), err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
@@ -199,6 +199,9 @@ type BuildState struct {
 
 	// The default cluster.
 	Cluster *v1alpha1.Cluster
+
+	// Why the manifest is being built.
+	BuildReason model.BuildReason
 }
 
 func NewBuildState(result BuildResult, files []string, pendingDeps []model.TargetID) BuildState {
@@ -3,10 +3,13 @@ package dockercomposeservices
 import (
 	"fmt"
 
+	"github.com/docker/docker/api/types"
+
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
@@ -18,6 +21,7 @@ func SpanIDForDCService(mn model.ManifestName) logstore.SpanID {
 func HandleDockerComposeServiceUpsertAction(state *store.EngineState, action DockerComposeServiceUpsertAction) {
 	obj := action.DockerComposeService
 	n := obj.Name
+	old := state.DockerComposeServices[n]
 	state.DockerComposeServices[n] = obj
 
 	mn := model.ManifestName(obj.GetAnnotations()[v1alpha1.AnnotationManifest])
@@ -26,6 +30,8 @@ func HandleDockerComposeServiceUpsertAction(state *store.EngineState, action Doc
 		return
 	}
 
+	logHealthTransition(state, mn, old, obj)
+
 	dcs, ok := mt.State.RuntimeState.(dockercompose.State)
 	if !ok {
 		dcs = dockercompose.State{}
@@ -47,6 +53,42 @@ func HandleDockerComposeServiceUpsertAction(state *store.EngineState, action Doc
 	mt.State.RuntimeState = dcs
 }
 
+// Print a message in the resource log when the container's healthcheck
+// starts or stops failing, so that the user can see why it's not ready.
+func logHealthTransition(state *store.EngineState, mn model.ManifestName, old, obj *v1alpha1.DockerComposeService) {
+	oldHealth := ""
+	if old != nil && old.Status.ContainerState != nil && old.Status.ContainerID == obj.Status.ContainerID {
+		oldHealth = old.Status.ContainerState.HealthStatus
+	}
+	cState := obj.Status.ContainerState
+	if cState == nil || cState.HealthStatus == oldHealth {
+		return
+	}
+
+	name := obj.Status.ContainerName
+	if name == "" {
+		name = container.ID(obj.Status.ContainerID).ShortStr()
+	}
+
+	var msg string
+	level := logger.InfoLvl
+	switch {
+	case cState.HealthStatus == types.Unhealthy:
+		level = logger.WarnLvl
+		msg = fmt.Sprintf("Container %s is unhealthy", name)
+		if cState.HealthLog != "" {
+			msg = fmt.Sprintf("%s: %s", msg, cState.HealthLog)
+		}
+	case cState.HealthStatus == types.Healthy && oldHealth == types.Unhealthy:
+		msg = fmt.Sprintf("Container %s is healthy again", name)
+	default:
+		return
+	}
+
+	a := store.NewLogAction(mn, SpanIDForDCService(mn), level, nil, []byte(msg+"\n"))
+	state.LogStore.Append(a, state.Secrets)
+}
+
 func HandleDockerComposeServiceDeleteAction(state *store.EngineState, action DockerComposeServiceDeleteAction) {
 	delete(state.DockerComposeServices, action.Name)
 }
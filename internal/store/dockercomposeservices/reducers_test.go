@@ -0,0 +1,75 @@
+package dockercomposeservices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestHealthTransitions(t *testing.T) {
+	state := newDCState("db")
+
+	upsert(state, "starting", "")
+	assert.Equal(t, v1alpha1.RuntimeStatusPending, state.ManifestTargets["db"].RuntimeStatus())
+	assert.Equal(t, "", state.LogStore.ManifestLog("db"))
+
+	upsert(state, "unhealthy", "connection refused")
+	assert.Equal(t, v1alpha1.RuntimeStatusError, state.ManifestTargets["db"].RuntimeStatus())
+	assert.Contains(t, state.LogStore.ManifestLog("db"), "Container db-1 is unhealthy: connection refused")
+
+	upsert(state, "healthy", "")
+	assert.Equal(t, v1alpha1.RuntimeStatusOK, state.ManifestTargets["db"].RuntimeStatus())
+	assert.Contains(t, state.LogStore.ManifestLog("db"), "Container db-1 is healthy again")
+}
+
+func TestHealthyOnFirstCheckIsQuiet(t *testing.T) {
+	state := newDCState("db")
+
+	upsert(state, "starting", "")
+	upsert(state, "healthy", "")
+	assert.Equal(t, v1alpha1.RuntimeStatusOK, state.ManifestTargets["db"].RuntimeStatus())
+	assert.True(t, state.ManifestTargets["db"].State.DCRuntimeState().HasEverBeenReadyOrSucceeded())
+	assert.Equal(t, "", state.LogStore.ManifestLog("db"))
+}
+
+func TestNotReadyUntilHealthy(t *testing.T) {
+	state := newDCState("db")
+
+	upsert(state, "starting", "")
+	assert.False(t, state.ManifestTargets["db"].State.DCRuntimeState().HasEverBeenReadyOrSucceeded())
+}
+
+func newDCState(name string) *store.EngineState {
+	state := store.NewState()
+	m := model.Manifest{Name: model.ManifestName(name)}.
+		WithDeployTarget(model.DockerComposeTarget{Name: model.TargetName(name)})
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	return state
+}
+
+func upsert(state *store.EngineState, health, healthLog string) {
+	HandleDockerComposeServiceUpsertAction(state, DockerComposeServiceUpsertAction{
+		DockerComposeService: &v1alpha1.DockerComposeService{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "db",
+				Annotations: map[string]string{v1alpha1.AnnotationManifest: "db"},
+			},
+			Status: v1alpha1.DockerComposeServiceStatus{
+				ContainerID:   "abc123",
+				ContainerName: "db-1",
+				ContainerState: &v1alpha1.DockerContainerState{
+					Status:       dockercompose.ContainerStatusRunning,
+					Running:      true,
+					HealthStatus: health,
+					HealthLog:    healthLog,
+				},
+			},
+		},
+	})
+}
@@ -102,6 +102,9 @@ type EngineState struct {
 
 	UserConfigState model.UserConfigState
 
+	// Tiltfile args defined by the main Tiltfile with config.define_*
+	TiltfileArgs []model.TiltfileArg
+
 	// The initialization sequence is unfortunate. Currently we have:
 	// 1) Dispatch an InitAction
 	// 1) InitAction sets DesiredTiltfilePath
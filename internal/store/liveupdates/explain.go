@@ -0,0 +1,104 @@
+package liveupdates
+
+import (
+	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// ExplainFiles evaluates each changed file against the rules in a LiveUpdateSpec,
+// and reports which rules it matched and what the live-updater will do with it.
+//
+// The action comes from NewLiveUpdatePlan, so it's always what the live-updater
+// does: a file that matches a stop path halts the update even if it also
+// matches a sync, and only synced files trigger execs.
+func ExplainFiles(luSpec v1alpha1.LiveUpdateSpec, filesChanged []string) ([]v1alpha1.LiveUpdateFileDecision, error) {
+	plan, err := NewLiveUpdatePlan(luSpec, filesChanged)
+	if err != nil {
+		return nil, err
+	}
+
+	containerPaths := make(map[string]string, len(plan.SyncPaths))
+	for _, pm := range plan.SyncPaths {
+		containerPaths[pm.LocalPath] = pm.ContainerPath
+	}
+	stopped := make(map[string]bool, len(plan.StopPaths))
+	for _, f := range plan.StopPaths {
+		stopped[f] = true
+	}
+
+	syncs := liveupdate.SyncSteps(luSpec)
+	result := make([]v1alpha1.LiveUpdateFileDecision, 0, len(filesChanged))
+	for _, f := range filesChanged {
+		d := v1alpha1.LiveUpdateFileDecision{LocalPath: f}
+
+		containerPath, synced := containerPaths[f]
+		if synced {
+			d.ContainerPath = containerPath
+			d.SyncLocalPath = matchingSync(syncs, f)
+		}
+
+		switch {
+		case stopped[f]:
+			d.Action = v1alpha1.LiveUpdateFileActionStop
+			d.StopPath, err = matchingPattern(luSpec.StopPaths, luSpec.BasePath, f)
+			if err != nil {
+				return nil, err
+			}
+		case synced:
+			d.Action = v1alpha1.LiveUpdateFileActionSync
+			d.TriggerPaths, err = matchingTriggers(luSpec, f)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			d.Action = v1alpha1.LiveUpdateFileActionNoMatch
+		}
+
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// The local path of the sync rule that build.FilesToPathMappings uses for the file.
+func matchingSync(syncs []model.Sync, f string) string {
+	for _, s := range syncs {
+		if _, isChild := ospath.Child(s.LocalPath, f); isChild {
+			return s.LocalPath
+		}
+	}
+	return ""
+}
+
+// The first pattern that matches the file.
+func matchingPattern(patterns []string, basePath string, f string) (string, error) {
+	for _, p := range patterns {
+		match, _, err := model.NewPathSet([]string{p}, basePath).AnyMatch([]string{f})
+		if err != nil {
+			return "", err
+		}
+		if match {
+			return p, nil
+		}
+	}
+	return "", nil
+}
+
+// The trigger paths that match the file, like build.BoilRuns does for
+// the synced files.
+func matchingTriggers(luSpec v1alpha1.LiveUpdateSpec, f string) ([]string, error) {
+	var result []string
+	for _, exec := range luSpec.Execs {
+		for _, p := range exec.TriggerPaths {
+			match, _, err := model.NewPathSet([]string{p}, luSpec.BasePath).AnyMatch([]string{f})
+			if err != nil {
+				return nil, err
+			}
+			if match {
+				result = append(result, p)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package liveupdates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestExplainFiles(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("src/vendor")
+	f.MkdirAll("web")
+
+	spec := v1alpha1.LiveUpdateSpec{
+		BasePath: f.Path(),
+		Syncs: []v1alpha1.LiveUpdateSync{
+			{LocalPath: "src", ContainerPath: "/app"},
+			{LocalPath: "src/vendor", ContainerPath: "/vendor"},
+		},
+		StopPaths: []string{"src/go.mod", "Dockerfile"},
+		Execs: []v1alpha1.LiveUpdateExec{
+			{Args: []string{"go", "build"}, TriggerPaths: []string{"src/main.go"}},
+			{Args: []string{"go", "mod", "download"}, TriggerPaths: []string{"src/go.mod", "src/go.sum"}},
+			{Args: []string{"npm", "install"}, TriggerPaths: []string{"web/package.json"}},
+			{Args: []string{"echo", "synced"}},
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		file     string
+		expected v1alpha1.LiveUpdateFileDecision
+	}{
+		{
+			name: "sync",
+			file: "src/util.go",
+			expected: v1alpha1.LiveUpdateFileDecision{
+				Action:        v1alpha1.LiveUpdateFileActionSync,
+				SyncLocalPath: f.JoinPath("src"),
+				ContainerPath: "/app/util.go",
+			},
+		},
+		{
+			name: "sync and trigger",
+			file: "src/main.go",
+			expected: v1alpha1.LiveUpdateFileDecision{
+				Action:        v1alpha1.LiveUpdateFileActionSync,
+				SyncLocalPath: f.JoinPath("src"),
+				ContainerPath: "/app/main.go",
+				TriggerPaths:  []string{"src/main.go"},
+			},
+		},
+		{
+			name: "first sync wins",
+			file: "src/vendor/lib.go",
+			expected: v1alpha1.LiveUpdateFileDecision{
+				Action:        v1alpha1.LiveUpdateFileActionSync,
+				SyncLocalPath: f.JoinPath("src"),
+				ContainerPath: "/app/vendor/lib.go",
+			},
+		},
+		{
+			name: "stop path beats sync and trigger",
+			file: "src/go.mod",
+			expected: v1alpha1.LiveUpdateFileDecision{
+				Action:        v1alpha1.LiveUpdateFileActionStop,
+				SyncLocalPath: f.JoinPath("src"),
+				ContainerPath: "/app/go.mod",
+				StopPath:      "src/go.mod",
+			},
+		},
+		{
+			name: "stop path without sync",
+			file: "Dockerfile",
+			expected: v1alpha1.LiveUpdateFileDecision{
+				Action:   v1alpha1.LiveUpdateFileActionStop,
+				StopPath: "Dockerfile",
+			},
+		},
+		{
+			name: "trigger without sync",
+			file: "web/package.json",
+			expected: v1alpha1.LiveUpdateFileDecision{
+				Action: v1alpha1.LiveUpdateFileActionNoMatch,
+			},
+		},
+		{
+			name: "no match",
+			file: "README.md",
+			expected: v1alpha1.LiveUpdateFileDecision{
+				Action: v1alpha1.LiveUpdateFileActionNoMatch,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			decisions, err := ExplainFiles(spec, []string{f.JoinPath(tc.file)})
+			require.NoError(t, err)
+			require.Len(t, decisions, 1)
+
+			tc.expected.LocalPath = f.JoinPath(tc.file)
+			assert.Equal(t, tc.expected, decisions[0])
+		})
+	}
+}
+
+func TestExplainFilesMatchesPlan(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("src")
+
+	spec := v1alpha1.LiveUpdateSpec{
+		BasePath:  f.Path(),
+		Syncs:     []v1alpha1.LiveUpdateSync{{LocalPath: "src", ContainerPath: "/app"}},
+		StopPaths: []string{"src/go.mod"},
+	}
+	files := []string{f.JoinPath("src/main.go"), f.JoinPath("src/go.mod"), f.JoinPath("README.md")}
+
+	plan, err := NewLiveUpdatePlan(spec, files)
+	require.NoError(t, err)
+	decisions, err := ExplainFiles(spec, files)
+	require.NoError(t, err)
+
+	var synced, stopped, noMatch []string
+	for _, d := range decisions {
+		switch d.Action {
+		case v1alpha1.LiveUpdateFileActionStop:
+			stopped = append(stopped, d.LocalPath)
+		case v1alpha1.LiveUpdateFileActionNoMatch:
+			noMatch = append(noMatch, d.LocalPath)
+		default:
+			synced = append(synced, d.LocalPath)
+		}
+	}
+	assert.Equal(t, plan.StopPaths, stopped)
+	assert.Equal(t, plan.NoMatchPaths, noMatch)
+	assert.Equal(t, []string{f.JoinPath("src/main.go")}, synced)
+}
@@ -1,4 +1,4 @@
-from typing import Dict, Union, List, Callable, Any, Optional
+from typing import Dict, Union, List, Callable, Any, Optional, Tuple
 
 # Our documentation generation framework doesn't properly handle __file__,
 # so we call it __file__ and edit it later.
@@ -194,8 +194,9 @@ def docker_build(ref: str,
                  extra_tag: Union[str, List[str]] = "",
                  container_args: List[str] = None,
                  cache_from: Union[str, List[str]] = [],
+                 cache_to: Union[str, List[str]] = [],
                  pull: bool = False,
-                 platform: str = "") -> None:
+                 platform: Union[str, List[str]] = "") -> None:
   """Builds a docker image.
 
   The invocation
@@ -237,13 +238,14 @@ def docker_build(ref: str,
     secret: Include secrets in your build in a way that won't show up in the image. Uses the same syntax as the `docker build --secret flag <https://docs.docker.com/develop/develop-images/build_enhancements/#new-docker-build-secret-information>`_.
     extra_tag: Tag an image with one or more extra references after each build. Useful when running Tilt in a CI pipeline, where you want each image to be tagged with the pipeline ID so you can find it later. Uses the same syntax as the ``docker build --tag`` flag.
     container_args: args to run when this container starts. Takes precedence over a `container args specified in k8s YAML <https://kubernetes.io/docs/tasks/inject-data-application/define-command-argument-container/>`_.
-    cache_from: Cache image builds from a remote registry. Uses the same syntax as `docker build --cache-from flag <https://docs.docker.com/engine/reference/commandline/build/#specifying-external-cache-sources>`_.
+    cache_from: Cache image builds from a remote registry. Uses the same syntax as `docker build --cache-from flag <https://docs.docker.com/engine/reference/commandline/build/#specifying-external-cache-sources>`_. Also accepts BuildKit cache backends, like ``type=registry,ref=gcr.io/my-project/cache`` or ``type=local,src=.cache``.
+    cache_to: Export the build cache, so that other machines can import it with ``cache_from``. Uses the same syntax as the `docker buildx build --cache-to flag <https://docs.docker.com/engine/reference/commandline/buildx_build/#cache-to>`_. Supports ``type=registry,ref=...``, ``type=local,dest=...``, and ``type=inline``. Only ``type=inline`` works with the Docker daemon; the rest need a standalone BuildKit daemon (see ``TILT_BUILDKIT_HOST``).
     pull: Force pull the latest version of parent images. Equivalent to the ``docker build --pull`` flag.
-    platform: Target platform for build (e.g. ``linux/amd64``). Defaults to the value of the ``DOCKER_DEFAULT_PLATFORM`` environment variable. Equivalent to the ``docker build --platform`` flag.
+    platform: Target platform for build (e.g. ``linux/amd64``). Defaults to the value of the ``DOCKER_DEFAULT_PLATFORM`` environment variable. Equivalent to the ``docker build --platform`` flag. Pass a list (or a comma-separated string) to build a multi-platform image index, e.g., ``['linux/amd64', 'linux/arm64']``. Multi-platform builds need a standalone BuildKit daemon (see ``TILT_BUILDKIT_HOST``).
   """
   pass
 
-def docker_compose(configPaths: Union[str, Blob, List[Union[str, Blob]]], env_file: str = None, project_name: str = "") -> None:
+def docker_compose(configPaths: Union[str, Blob, Dict[str, Any], List[Union[str, Blob, Dict[str, Any]]]], env_file: str = None, project_name: str = "", profiles: Union[str, List[str]] = []) -> None:
   """Run containers with Docker Compose.
 
   Tilt will read your Docker Compose YAML and separate out the services.
@@ -251,7 +253,8 @@ def docker_compose(configPaths: Union[str, Blob, List[Union[str, Blob]]], env_fi
   correspond to images defined elsewhere in your ``Tiltfile`` (matching based on
   the DockerImage ref).
 
-  You can set up Docker Compose with a path to a file, a Blob containing Compose YAML, or a list of paths and/or Blobs.
+  You can set up Docker Compose with a path to a file, a Blob containing Compose YAML, a dict of Compose config,
+  or a list of any of these. Like files passed to ``docker compose -f``, each one overrides the ones before it.
 
   Tilt will watch your Docker Compose YAML and reload if it changes.
 
@@ -275,15 +278,22 @@ def docker_compose(configPaths: Union[str, Blob, List[Union[str, Blob]]], env_fi
     services = {'app': {'environment': {'DEBUG': 'true'}}}
     docker_compose(['docker-compose.yml', encode_yaml({'services': services})])
 
+    # The same override, as a dict
+    docker_compose(['docker-compose.yml', {'services': {'app': {'environment': {'DEBUG': 'true'}, 'ports': ['9229:9229']}}}])
+
+    # Also run the services in the 'debug' profile
+    docker_compose('./docker-compose.yml', profiles=['debug'])
+
   Args:
-    configPaths: Path(s) and/or Blob(s) to Docker Compose yaml files or content.
-    env_file: Path to env file to use; defaults to ``.env`` in current directory.
+    configPaths: Path(s), Blob(s), and/or dict(s) of Docker Compose config.
+    env_file: Path to env file to use; defaults to ``.env`` in the project directory (the directory of the first compose file). Tilt reloads the Tiltfile when the env file changes.
     project_name: The Docker Compose project name. If unspecified, the main Tiltfile's directory name is used.
+    profiles: Compose profile(s) to enable, in addition to any in ``COMPOSE_PROFILES``. Services with profiles only become resources when one of their profiles is enabled.
   """
 
 
 
-def k8s_yaml(yaml: Union[str, List[str], Blob], allow_duplicates: bool = False) -> None:
+def k8s_yaml(yaml: Union[str, List[str], Blob], allow_duplicates: bool = False, cluster: str = "") -> None:
   """Call this with a path to a file that contains YAML, or with a ``Blob`` of YAML.
 
   We will infer what (if any) of the k8s resources defined in your YAML
@@ -312,7 +322,10 @@ def k8s_yaml(yaml: Union[str, List[str], Blob], allow_duplicates: bool = False)
     allow_duplicates: If you try to register the same Kubernetes
       resource twice, this function will assume this is a mistake and emit an error.
       Set allow_duplicates=True to allow duplicates. There are some Helm charts
-      that have duplicate resources for esoteric reasons.
+      that have duplicate resources for esoteric reasons. This also applies
+      to the same object loaded for two different clusters.
+    cluster: The name of a cluster declared with :meth:`k8s_cluster` to deploy
+      this YAML to. Defaults to the cluster of the current ``k8s_context()``.
   """
   pass
 
@@ -428,7 +441,8 @@ def dc_resource(name: str,
                 resource_deps: List[str] = [],
                 links: Union[str, Link, List[Union[str, Link]]] = [],
                 labels: Union[str, List[str]] = [],
-                auto_init: bool = True) -> None:
+                auto_init: bool = True,
+                replicas: int = None) -> None:
   """Configures the Docker Compose resource of the given name. Note: Tilt does an amount of resource configuration
   for you(for more info, see `Tiltfile Concepts: Resources <tiltfile_concepts.html#resources>`_); you only need
   to invoke this function if you want to configure your resource beyond what Tilt does automatically.
@@ -444,6 +458,9 @@ def dc_resource(name: str,
     labels: used to group resources in the Web UI, (e.g. you want all frontend services displayed together, while test and backend services are displayed seperately). A label must start and end with an alphanumeric character, can include ``_``, ``-``, and ``.``, and must be 63 characters or less. For an example, see `Resource Grouping <tiltfile_concepts.html#resource-groups>`_.
     auto_init: whether this resource runs on ``tilt up``. Defaults to ``True``. For more info, see the
       `Manual Update Control docs <manual_update_control.html>`_.
+    replicas: the number of containers to run for the service, like ``docker compose up --scale``.
+      Defaults to the ``deploy.replicas`` in the service config, or 1. Tilt shows the status and
+      port bindings of the first container, and the logs of all of them.
   """
 
   pass
@@ -457,7 +474,8 @@ def k8s_resource(workload: str = "", new_name: str = "",
                  pod_readiness: str = "",
                  links: Union[str, Link, List[Union[str, Link]]]=[],
                  labels: Union[str, List[str]] = [],
-                 discovery_strategy: str = "") -> None:
+                 discovery_strategy: str = "",
+                 cluster: str = "") -> None:
   """
 
   Configures or creates the specified Kubernetes resource.
@@ -556,6 +574,9 @@ def k8s_resource(workload: str = "", new_name: str = "",
       `Accessing Resource Endpoints <accessing_resource_endpoints.html#arbitrary-links>`_.
     labels: used to group resources in the Web UI, (e.g. you want all frontend services displayed together, while test and backend services are displayed seperately). A label must start and end with an alphanumeric character, can include ``_``, ``-``, and ``.``, and must be 63 characters or less. For an example, see `Resource Grouping <tiltfile_concepts.html#resource-groups>`_.
     discovery_strategy: Possible values: '', 'default', 'selectors-only'. When '' or 'default', Tilt both uses `extra_pod_selectors` and traces k8s owner references to identify this resource's pods. When 'selectors-only', Tilt uses only `extra_pod_selectors`.
+    cluster: The name of a cluster declared with :meth:`k8s_cluster` to deploy this resource to.
+      Defaults to the cluster its objects were loaded for with ``k8s_yaml``. A resource
+      deploys to exactly one cluster.
   """
   pass
 
@@ -664,9 +685,36 @@ def local(command: Union[str, List[str]],
           echo_off: bool = False,
           env: Dict[str, str] = {},
           dir: str = "",
-          stdin: Union[str, Blob, None] = None) -> Blob:
+          stdin: Union[str, Blob, None] = None,
+          cache_inputs: Union[str, List[str], None] = None) -> Blob:
   """Runs a command on the *host* machine, waits for it to finish, and returns its stdout as a ``Blob``
 
+  If ``cache_inputs`` is set, Tilt hashes those files and directories, and only
+  runs the command when they've changed since its last successful run (or when
+  the command, ``env``, ``dir``, or ``stdin`` changed). Otherwise, it returns the
+  stdout from that run. The cache is on disk, so it lasts across Tiltfile reloads
+  and Tilt restarts. For example::
+
+    local('helm dependency build ./chart', cache_inputs=['chart/Chart.yaml', 'chart/Chart.lock'])
+
+  Only use ``cache_inputs`` for commands whose output depends on nothing but
+  their inputs. ``cache_inputs`` doesn't make the Tiltfile reload when the inputs
+  change; use ``watch_file`` for that.
+
+  If ``async=True``, ``local`` starts the command and returns right away, and the
+  command runs alongside the rest of the Tiltfile. The returned ``Blob`` waits for
+  the command when it's used, e.g., by ``k8s_yaml``, ``decode_yaml``, or ``str``.
+  The command's log output appears at that point, too. If the command fails, the
+  Tiltfile fails where the result is first used, or at the end if it's never used.
+  For example::
+
+    frontend = local('./render.sh frontend', async=True)
+    backend = local('./render.sh backend', async=True)
+    k8s_yaml([frontend, backend])
+
+  (``async`` is a Python keyword, so it's missing from the signature above. It's
+  fine in a Tiltfile.)
+
   Args:
     command: Command to run. If a string, executed with ``sh -c`` on macOS/Linux, or ``cmd /S /C`` on Windows;
       if a list, will be passed to the operating system as program name and args.
@@ -678,6 +726,8 @@ def local(command: Union[str, List[str]],
     env: Environment variables to pass to the executed ``command``. Values specified here will override any variables passed to the Tilt parent process.
     dir: Working directory for ``command``. Defaults to the Tiltfile's location.
     stdin: If not ``None``, will be written to ``command``'s stdin.
+    cache_inputs: Paths to files or directories (searched recursively) that determine the command's output. If set, the command's stdout is cached as described above. An empty list caches the output until the command changes.
+    async: If True, runs the command in the background, as described above.
   """
   pass
 
@@ -693,6 +743,37 @@ def read_file(file_path: str, default: str = None) -> Blob:
     default: If not `None` and the file at `file_path` does not exist, this value will be returned."""
   pass
 
+def read_url(url: str, sha256: str = None, headers: Dict[str, str] = None) -> Blob:
+  """
+  Downloads a URL and returns its contents.
+
+  Downloads are cached on disk (in Tilt's XDG cache directory), keyed by
+  their SHA-256 checksum.
+
+  If ``sha256`` is given, the download must match it, or Tiltfile loading
+  fails. Once the content is cached, later loads use it without touching the
+  network.
+
+  Without ``sha256``, the URL is downloaded on every Tiltfile load. If the
+  server can't be reached, Tilt falls back to the last copy it downloaded, so
+  the Tiltfile still loads offline.
+
+  For example::
+
+    k8s_yaml(read_url(
+      'https://example.com/crds/v1.2.0/bundle.yaml',
+      sha256='9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08'))
+
+  Tilt can't watch URLs for changes, so a URL without a pinned checksum is
+  only re-downloaded when something else triggers a Tiltfile reload.
+
+  Args:
+    url: An http:// or https:// URL.
+    sha256: The expected SHA-256 checksum of the contents, as a hex string.
+    headers: HTTP headers to send with the request, e.g., ``{'Authorization': 'Bearer ' + token}``.
+  """
+  pass
+
 def watch_file(file_path: str) -> None:
   """Watches a file. If the file is changed a re-exectution of the Tiltfile is triggered.
 
@@ -703,31 +784,165 @@ def watch_file(file_path: str) -> None:
 
 
 def kustomize(pathToDir: str, kustomize_bin: str = None) -> Blob:
-  """Run `kustomize <https://github.com/kubernetes-sigs/kustomize>`_ on a given directory and return the resulting YAML as a Blob
-  Directory is watched (see ``watch_file``). Checks for and uses separately installed kustomize first, if it exists. Otherwise,
-  uses kubectl's kustomize. See `blog post <https://blog.tilt.dev/2020/02/04/are-you-my-kustomize.html>`_.
+  """Run `kustomize build <https://github.com/kubernetes-sigs/kustomize>`_ on a given directory and return the resulting YAML as a Blob
+  Directory is watched (see ``watch_file``).
+
+  Tilt builds the kustomization itself, so you don't need the ``kustomize`` or ``kubectl`` binary,
+  and the output is the same on every machine.
+
+  - Remote bases, like ``github.com/org/repo/deploy/base?ref=v1.0.0``, are cloned with ``git``
+    and cached. A base at a commit or a version tag is only cloned once. A base on a branch is
+    cloned again every time the Tiltfile loads.
+  - Charts in ``helmCharts`` are rendered with Tilt's built-in Helm, like ``kustomize build --enable-helm``,
+    but without the ``helm`` binary. Charts in the chart home are watched. Charts from a ``repo`` are cached
+    like remote charts in :meth:`helm`.
 
   Args:
     pathToDir: Path to the directory locally (absolute, or relative to the location of the Tiltfile).
-    kustomize_bin: Custom path to the ``kustomize`` binary executable. Defaults to searching $PATH for kustomize."""
+    kustomize_bin: Custom path to a ``kustomize`` binary executable, to run ``kustomize build`` with instead.
+    async: If True, runs kustomize in the background, so that it can run alongside other ``kustomize``, ``helm``, and ``local`` calls. See ``local`` for how the returned Blob behaves."""
   pass
 
 def helm(pathToChartDir: str, name: str = "", namespace: str = "", values: Union[str, List[str]]=[], set: Union[str, List[str]]=[], kube_version: str = "") -> Blob:
-  """Run `helm template <https://docs.helm.sh/helm/#helm-template>`_ on a given directory that contains a chart and return the fully rendered YAML as a Blob
-  Chart directory is watched (See ``watch_file``).
+  """Render a Helm chart, like `helm template <https://docs.helm.sh/helm/#helm-template>`_, and return the fully rendered YAML as a Blob.
+
+  Tilt renders the chart itself, so you don't need the ``helm`` binary. The chart can be:
+
+  - a local chart directory, or a packaged chart (``.tgz``). The chart, including its
+    ``values.yaml`` and any ``file://`` subcharts, is watched (see ``watch_file``).
+  - a chart in an OCI registry, like ``oci://ghcr.io/org/charts/app@1.2.3``.
+  - a chart in a repository from your Helm config (see ``helm repo add``),
+    like ``bitnami/nginx@13.2.0``.
+
+  Remote charts are cached. A chart with an exact version is only downloaded once. A chart
+  with a version range (like ``@^13.0.0``), or no version, is checked for updates every
+  time the Tiltfile loads.
+
+  Like ``helm template --include-crds``, the output includes CRDs and hooks, but not tests.
 
   For more examples, see the `Helm Cookbook <helm.html>`_.
 
   Args:
-    pathToChartDir: Path to the directory locally (absolute, or relative to the location of the Tiltfile).
+    pathToChartDir: Path to the chart locally (absolute, or relative to the location of the Tiltfile), or a remote chart, as described above.
     name: The release name. Equivalent to the helm `--name` flag
     namespace: The namespace to deploy the chart to. Equivalent to the helm `--namespace` flag
     values: Specify one or more values files (in addition to the `values.yaml` file in the chart). Equivalent to the Helm ``--values`` or ``-f`` flags (`see docs <https://helm.sh/docs/chart_template_guide/#values-files>`_).
     set: Specify one or more values. Equivalent to the Helm ``--set`` flag.
     kube_version: Specify for which kubernetes version template will be generated. Equivalent to the Helm ``--kube-version`` flag.
+    async: If True, renders the chart in the background, so that it can run alongside other ``helm``, ``kustomize``, and ``local`` calls. See ``local`` for how the returned Blob behaves.
 """
   pass
 
+def helm_release(name: str,
+                 chart: str,
+                 release_name: str = "",
+                 namespace: str = "",
+                 values: Union[str, List[str]]=[],
+                 set: Union[str, List[str]]=[],
+                 image_deps: List[str]=[],
+                 image_keys: List[Union[str, Tuple[str, str]]]=[],
+                 create_namespace: bool=False,
+                 atomic: bool=False,
+                 timeout: str="5m",
+                 deps: Union[str, List[str]]=[]) -> None:
+  """Install and upgrade a Helm release, like ``helm upgrade --install``.
+
+  Unlike :meth:`helm`, which renders the chart to YAML for :meth:`k8s_yaml`, ``helm_release``
+  deploys the chart as a real release. Chart hooks run, the release keeps its revision
+  history, and ``helm list`` and ``helm history`` show the release. Tilt uses the Helm SDK,
+  so you don't need the ``helm`` binary.
+
+  The release is upgraded whenever the chart, one of the ``values`` files, or a path in ``deps``
+  changes, and whenever an image in ``image_deps`` is rebuilt. The output of each hook, and the
+  revision and status of the release, are shown in the resource's logs. ``tilt down`` uninstalls the release.
+
+  If an upgrade fails, the release is left with a failed revision until the next upgrade succeeds.
+  Set ``atomic=True`` to roll back to the last deployed revision instead. If Tilt exits in the middle
+  of an upgrade, the next upgrade marks the interrupted revision failed and carries on.
+
+  The chart can be a local chart or a remote chart, as described in :meth:`helm`.
+
+  Tilt injects the images it builds through chart values. By default, a single image
+  in ``image_deps`` sets ``image.repository`` and ``image.tag``. Use ``image_keys`` to set other values,
+  with one entry for each image in ``image_deps``: either a single key for the whole image ref,
+  or a ``(repository, tag)`` tuple of keys. ``image_keys`` is required if there's more than one
+  image in ``image_deps``. For example::
+
+    helm_release('app', './charts/app',
+                 image_deps=['app', 'sidecar'],
+                 image_keys=[('image.repository', 'image.tag'), 'sidecar.image'])
+
+  Port forwards and other behavior can be configured using :meth:`k8s_resource`
+  using the ``name`` as specified here.
+
+  Args:
+    name: resource name to use in Tilt UI and for further customization via :meth:`k8s_resource`
+    chart: Path to the chart locally (absolute, or relative to the location of the Tiltfile), or a remote chart.
+    release_name: The release name. Defaults to ``name``.
+    namespace: The namespace of the release. Defaults to the namespace of the current kubeconfig context.
+    values: Specify one or more values files (in addition to the `values.yaml` file in the chart). Equivalent to the Helm ``--values`` or ``-f`` flags.
+    set: Specify one or more values. Equivalent to the Helm ``--set`` flag.
+    image_deps: Images that Tilt builds and injects into the release.
+    image_keys: The chart values to set to each image in ``image_deps``, as described above.
+    create_namespace: Create the namespace if it doesn't exist. Equivalent to the Helm ``--create-namespace`` flag.
+    atomic: Roll back a failed upgrade, or uninstall a failed install. Also waits for the release's objects to be ready. Equivalent to the Helm ``--atomic`` flag.
+    timeout: How long to wait for each hook, like ``"30s"`` or ``"10m"``. Equivalent to the Helm ``--timeout`` flag.
+    deps: Additional paths to watch and trigger an upgrade on change.
+"""
+  pass
+
+def template(src: Union[str, Blob], values: Dict[str, Any] = None) -> Blob:
+  """Renders a template and returns the result as a Blob.
+
+  Templates use a Jinja-like syntax (via `pongo2 <https://github.com/flosch/pongo2>`_),
+  with ``{{ expressions }}``, ``{% if %}`` conditionals, ``{% for %}`` loops, and filters.
+  Whitespace around block tags is trimmed, so that ``{% if %}`` lines don't leave blank
+  lines in the rendered YAML.
+
+  Values aren't HTML-escaped. The template file, and any files it pulls in with
+  ``{% include %}``, ``{% extends %}`` or ``{% import %}``, are watched (see ``watch_file``).
+  ``{% ssi %}`` isn't supported; use ``{% include %}`` instead.
+
+  For example::
+
+    k8s_yaml(template('deploy/app.yaml.tmpl', {
+      'name': 'frontend',
+      'replicas': 2,
+      'env': {'LOG_LEVEL': 'debug'},
+    }))
+
+  with ``deploy/app.yaml.tmpl``::
+
+    kind: Deployment
+    metadata:
+      name: {{ name }}
+    spec:
+      replicas: {{ replicas }}
+      template:
+        spec:
+          containers:
+          - name: {{ name }}
+            env:
+    {% for k, v in env sorted %}
+            - name: {{ k }}
+              value: {{ v|quote }}
+    {% endfor %}
+
+  In addition to pongo2's built-in filters, templates can use:
+
+  - ``to_yaml`` and ``to_json`` to serialize a value
+  - ``indent:N`` to indent every line but the first by N spaces (default 2)
+  - ``quote`` to quote a string, so that YAML always reads it as a string
+  - ``b64encode`` and ``b64decode``, e.g., for Secret data
+
+  The result composes with ``k8s_yaml``, ``filter_yaml``, and ``decode_yaml``.
+
+  Args:
+    src: Path to a template file (absolute, or relative to the location of the Tiltfile), or a Blob containing the template itself.
+    values: The variables available to the template.
+  """
+  pass
+
 def blob(contents: str) -> Blob:
   """Creates a Blob object that wraps the provided string. Useful for passing strings in to functions that expect a `Blob`, e.g. ``k8s_yaml``."""
   pass
@@ -886,6 +1101,100 @@ def encode_yaml_stream(objs: List[StructuredDataType]) -> Blob:
   """
   pass
 
+def read_toml(path: str, default: StructuredDataType = None) -> StructuredDataType:
+  """
+  Reads the file at `path` and deserializes its contents as TOML (e.g., ``pyproject.toml`` or ``Cargo.toml``).
+
+  Dates and times are returned as strings.
+
+  If the `path` does not exist and `default` is not `None`, `default` will be returned.
+  In any other case, an error reading `path` will be a Tiltfile load error.
+
+  Args:
+    path: Path to the file locally (absolute, or relative to the location of the Tiltfile).
+    default: If not `None` and the file at `path` does not exist, this value will be returned."""
+  pass
+
+def decode_toml(toml: Union[str, Blob]) -> StructuredDataType:
+  """
+  Deserializes the given TOML document into a starlark dict
+
+  Args:
+    toml: the TOML to deserialize
+  """
+  pass
+
+def encode_toml(obj: Dict[str, StructuredDataType]) -> Blob:
+  """
+  Serializes the given starlark dict into TOML.
+
+  Only supports maps with string keys, lists, strings, ints, floats, and bools.
+  TOML has no null value, so ``None`` is an error.
+
+  Args:
+    obj: the object to serialize
+  """
+  pass
+
+def read_ini(path: str, default: Dict[str, Dict[str, str]] = None) -> Dict[str, Dict[str, str]]:
+  """
+  Reads the file at `path` and deserializes its contents as INI (e.g., ``setup.cfg`` or ``tox.ini``).
+
+  Returns a dict of sections, each of which is a dict of keys. INI has no types, so every value is a string.
+  Keys before the first section header are in the ``DEFAULT`` section.
+
+  If the `path` does not exist and `default` is not `None`, `default` will be returned.
+  In any other case, an error reading `path` will be a Tiltfile load error.
+
+  Args:
+    path: Path to the file locally (absolute, or relative to the location of the Tiltfile).
+    default: If not `None` and the file at `path` does not exist, this value will be returned."""
+  pass
+
+def decode_ini(ini: Union[str, Blob]) -> Dict[str, Dict[str, str]]:
+  """
+  Deserializes the given INI document into a dict of sections.
+
+  Args:
+    ini: the INI to deserialize
+  """
+  pass
+
+def encode_ini(obj: Dict[str, Dict[str, Union[str, int, float, bool]]]) -> Blob:
+  """
+  Serializes the given dict of sections into INI.
+
+  Each section must be a dict of strings, numbers, or bools.
+
+  Args:
+    obj: the object to serialize
+  """
+  pass
+
+def read_dotenv(path: str, default: Dict[str, str] = None) -> Dict[str, str]:
+  """
+  Reads the environment variables in a dotenv file (like ``.env``) into a dict.
+
+  Uses the same syntax as Docker Compose env files. References to variables
+  defined earlier in the file (``${FOO}``) are expanded.
+
+  If the `path` does not exist and `default` is not `None`, `default` will be returned.
+  In any other case, an error reading `path` will be a Tiltfile load error.
+
+  Args:
+    path: Path to the file locally (absolute, or relative to the location of the Tiltfile).
+    default: If not `None` and the file at `path` does not exist, this value will be returned."""
+  pass
+
+def decode_dotenv(dotenv: Union[str, Blob]) -> Dict[str, str]:
+  """
+  Deserializes the given dotenv contents into a dict of environment variables.
+
+  Args:
+    dotenv: the dotenv contents to deserialize
+  """
+  pass
+
 def default_registry(host: str, host_from_cluster: str = None, single_name: str = "") -> None:
   """Specifies that any images that Tilt builds should be renamed so that they have the specified Docker registry.
 
@@ -1022,6 +1331,61 @@ def k8s_context() -> str:
   """
   pass
 
+def k8s_local_cluster(product: str, name: str = "tilt", nodes: int = 1, kubernetes_version: str = "", registry: bool = False, registry_port: int = 5005) -> None:
+  """Creates a local Kubernetes cluster for Tilt to deploy to, if it doesn't exist yet.
+
+  Tilt creates the cluster with the ``kind`` or ``k3d`` CLI, which must be on your ``PATH``, and
+  waits for it to be ready before connecting to it. The cluster's kubeconfig context is named
+  after the product and the cluster name, e.g., ``kind-tilt``. After this call, ``k8s_context()``
+  returns that context, and ``allow_k8s_contexts`` isn't needed, because Tilt deploys to
+  the local cluster rather than the current context.
+
+  If a cluster with the same name already exists, Tilt uses it as-is. If it has a different
+  number of nodes, Kubernetes version, or registry than you asked for, Tilt warns you. To
+  re-create it, run ``tilt down --delete-cluster``, then ``tilt up``.
+
+  The cluster isn't deleted by ``tilt down``, unless you pass ``--delete-cluster``.
+
+  Example ::
+
+    k8s_local_cluster('kind', nodes=2, registry=True)
+
+  Args:
+    product: The product to create the cluster with. Either ``'kind'`` or ``'k3d'``.
+    name: The name of the cluster.
+    nodes: The number of nodes in the cluster, including the control plane.
+    kubernetes_version: The Kubernetes version to run, e.g., ``'v1.27.3'``. Defaults to the product's default version.
+    registry: If True, creates a local registry and connects it to the cluster. Tilt pushes images to the registry, and advertises it in the cluster with the `local registry hosting ConfigMap <https://github.com/kubernetes/enhancements/tree/master/keps/sig-cluster-lifecycle/generic/1755-communicating-a-local-registry>`_.
+    registry_port: The port on localhost where the registry listens. Requires ``registry=True``.
+  """
+  pass
+
+def k8s_cluster(name: str, context: str, namespace: str = "") -> None:
+  """Declares an additional Kubernetes cluster for Tilt to deploy to.
+
+  By default, Tilt deploys everything to the cluster of the current
+  ``k8s_context()``. Pass the cluster's name to ``k8s_yaml`` or ``k8s_resource``
+  to deploy to this cluster instead. Each cluster has its own connection
+  health, registry, and architecture.
+
+  Like the current context, the cluster's context must be a local dev cluster,
+  or be allowed with ``allow_k8s_contexts``.
+
+  Example ::
+
+    k8s_cluster('edge', context='kind-edge')
+    k8s_yaml('control-plane.yaml')
+    k8s_yaml('edge.yaml', cluster='edge')
+
+  Args:
+    name: The name of the cluster. Must be a valid Kubernetes object name, and may
+      not be ``'default'`` or ``'docker'``.
+    context: The kubeconfig context to connect to the cluster with.
+    namespace: The default namespace for objects that don't specify one. Defaults to
+      the namespace of the context.
+  """
+  pass
+
 def allow_k8s_contexts(contexts: Union[str, List[str]]) -> None:
   """Specifies that Tilt is allowed to run against the specified k8s context names.
 
@@ -1101,7 +1465,8 @@ def local_resource(name: str,
                    readiness_probe: Probe = None,
                    dir: str = "",
                    serve_dir: str = "",
-                   labels: List[str] = []) -> None:
+                   labels: List[str] = [],
+                   dotenv_file: str = None) -> None:
   """Configures one or more commands to run on the *host* machine (not in a remote cluster).
 
   By default, Tilt performs an update on local resources on ``tilt up`` and whenever any of their ``deps`` change.
@@ -1144,6 +1509,7 @@ def local_resource(name: str,
     dir: Working directory for ``cmd``. Defaults to the Tiltfile directory.
     serve_dir: Working directory for ``serve_cmd``. Defaults to the Tiltfile directory.
     labels: used to group resources in the Web UI, (e.g. you want all frontend services displayed together, while test and backend services are displayed seperately). A label must start and end with an alphanumeric character, can include ``_``, ``-``, and ``.``, and must be 63 characters or less. For an example, see `Resource Grouping <tiltfile_concepts.html#resource-groups>`_.
+    dotenv_file: Path to a dotenv file (like ``.env``) with environment variables for both ``cmd`` and ``serve_cmd``. Variables in ``env`` and ``serve_env`` take precedence. Tilt reloads the Tiltfile when the file changes.
   """
   pass
 
@@ -0,0 +1,78 @@
+from typing import Any, Callable
+
+# The ``assert`` module is only available in Tiltfile unit tests, i.e., in
+# ``*_test.tilt`` files run by ``tilt tiltfile test``.
+#
+# A failed assertion fails the test. Any other error (a syntax error, a call to
+# ``fail()``, an unfaked ``local()``) is reported as a test error instead.
+
+def equals(expected: Any, actual: Any, msg: str=None) -> None:
+  """Asserts that two values are equal.
+
+  .. code-block:: python
+
+    def test_image_name():
+      assert.equals('gcr.io/foo:dev', image_name('foo'))
+
+  Args:
+    expected: The expected value.
+    actual: The actual value.
+    msg: If set, prefixes the failure message.
+  """
+  pass
+
+def not_equals(unexpected: Any, actual: Any, msg: str=None) -> None:
+  """Asserts that two values are not equal.
+
+  Args:
+    unexpected: The value that ``actual`` shouldn't equal.
+    actual: The actual value.
+    msg: If set, prefixes the failure message.
+  """
+  pass
+
+def true(cond: Any, msg: str=None) -> None:
+  """Asserts that a value is truthy.
+
+  Args:
+    cond: The value to check.
+    msg: If set, prefixes the failure message.
+  """
+  pass
+
+def false(cond: Any, msg: str=None) -> None:
+  """Asserts that a value is falsy.
+
+  Args:
+    cond: The value to check.
+    msg: If set, prefixes the failure message.
+  """
+  pass
+
+def contains(container: Any, item: Any, msg: str=None) -> None:
+  """Asserts that ``item in container``. Works on strings, lists, and dicts.
+
+  Args:
+    container: The string, list, or dict to search.
+    item: The item to look for.
+    msg: If set, prefixes the failure message.
+  """
+  pass
+
+def fails(fn: Callable[[], Any], contains: str=None, msg: str=None) -> str:
+  """Calls ``fn`` with no arguments, and asserts that it fails.
+
+  .. code-block:: python
+
+    def test_bad_port():
+      err = assert.fails(lambda: parse_port('http'), contains='invalid port')
+
+  Args:
+    fn: The function to call.
+    contains: If set, the error message must contain this string.
+    msg: If set, prefixes the failure message.
+
+  Returns:
+    The error message.
+  """
+  pass
@@ -88,6 +88,99 @@ def define_bool(name: str, args: bool=False, usage: str="") -> None:
       usage: When arg parsing fails, what to print for this setting's description.
     """
 
+def define_int(name: str, args: bool=False, usage: str="") -> None:
+    """
+    Defines a config setting of type `int`.
+
+    Allows the user invoking Tilt to configure a key named ``name`` to be in the
+    dict returned by :meth:`parse`. Values that aren't integers are rejected
+    when the config is parsed.
+
+    For instance, at runtime, to set a flag of this type named `replicas` to value `3`, run ``tilt up -- --replicas 3``.
+
+    Args:
+      name: The name of the config setting
+      args: If False, the config setting is specified by its name. (e.g., if it's named "foo",
+            ``tilt up -- --foo 3`` this setting would be ``3``.)
+
+            If True, the config setting is specified by unnamed positional args. (e.g.,
+            in ``tilt up -- 3``, this setting would be ``3``.)
+      usage: When arg parsing fails, what to print for this setting's description.
+    """
+
+def define_float(name: str, args: bool=False, usage: str="") -> None:
+    """
+    Defines a config setting of type `float`.
+
+    Allows the user invoking Tilt to configure a key named ``name`` to be in the
+    dict returned by :meth:`parse`. Integers are converted to floats.
+
+    For instance, at runtime, to set a flag of this type named `cpu` to value `0.5`, run ``tilt up -- --cpu 0.5``.
+
+    Args:
+      name: The name of the config setting
+      args: If False, the config setting is specified by its name. (e.g., if it's named "foo",
+            ``tilt up -- --foo 0.5`` this setting would be ``0.5``.)
+
+            If True, the config setting is specified by unnamed positional args. (e.g.,
+            in ``tilt up -- 0.5``, this setting would be ``0.5``.)
+      usage: When arg parsing fails, what to print for this setting's description.
+    """
+
+def define_choice(name: str, choices: List[str], args: bool=False, usage: str="") -> None:
+    """
+    Defines a config setting of type `str` that must be one of ``choices``.
+
+    Allows the user invoking Tilt to configure a key named ``name`` to be in the
+    dict returned by :meth:`parse`. Any other value is rejected when the config
+    is parsed, with an error listing the valid choices.
+
+    For instance::
+
+      config.define_choice('env', choices=['dev', 'staging'])
+      cfg = config.parse()
+      env = cfg.get('env', 'dev')
+
+    Then, at runtime, run ``tilt up -- --env staging``.
+
+    Args:
+      name: The name of the config setting
+      choices: The allowed values. Must not be empty.
+      args: If False, the config setting is specified by its name. (e.g., if it's named "foo",
+            ``tilt up -- --foo dev`` this setting would be ``"dev"``.)
+
+            If True, the config setting is specified by unnamed positional args. (e.g.,
+            in ``tilt up -- dev``, this setting would be ``"dev"``.)
+      usage: When arg parsing fails, what to print for this setting's description.
+    """
+
+def define_object(name: str, args: bool=False, usage: str="", schema: Union[Dict[str, Any], str, None]=None) -> None:
+    """
+    Defines a config setting that can be any JSON value (e.g., a dict or a list).
+
+    Allows the user invoking Tilt to configure a key named ``name`` to be in the
+    dict returned by :meth:`parse`.
+
+    For instance, at runtime, to set a flag of this type named `ports` to value ``[8080, 8081]``,
+    run ``tilt up -- --ports '[8080, 8081]'``.
+
+    If ``schema`` is given, values are validated against it when the config is parsed::
+
+      config.define_object('db', schema={
+        'type': 'object',
+        'properties': {'port': {'type': 'integer'}},
+        'required': ['port'],
+      })
+
+    Args:
+      name: The name of the config setting
+      args: If False, the config setting is specified by its name, as a JSON string.
+
+            If True, the config setting is specified by an unnamed positional arg.
+      usage: When arg parsing fails, what to print for this setting's description.
+      schema: A `JSON Schema <https://json-schema.org/>`_, as a dict or a JSON string.
+    """
+
 def parse() -> Dict[str, Any]:
     """
     Loads config settings from tilt_config.json, overlays config settings from
@@ -104,6 +197,10 @@ def parse() -> Dict[str, Any]:
     Tiltfile uses :meth:`parse` and also needs to allow specifying a set
     of resources to run, it needs to call :meth:`set_enabled_resources`.
 
+    The settings and their types are listed by ``tilt up -- --help`` and
+    ``tilt args -- --help``, and you can edit them with typed inputs in the
+    web UI, under "Args".
+
     See the `Tiltfile config documentation <tiltfile_config.html>`_ for examples
     and more information.
 
@@ -0,0 +1,43 @@
+from typing import List, Union
+
+# The ``fake`` module is only available in Tiltfile unit tests, i.e., in
+# ``*_test.tilt`` files run by ``tilt tiltfile test``.
+#
+# Tests don't touch the outside world. ``local()`` and ``read_url()`` fail
+# unless the command or URL has been faked, and ``k8s_context()`` returns
+# ``'tilt-test'``. Fakes last until the end of the test that sets them.
+
+def local(command: Union[str, List[str]], stdout: str='', stderr: str='', exit_code: int=0) -> None:
+  """Fakes the result of running a command with ``local()``.
+
+  .. code-block:: python
+
+    def test_version():
+      fake.local('git describe --tags', stdout='v1.2.3\n')
+      assert.equals('v1.2.3', app_version())
+
+  Args:
+    command: The command, exactly as it'll be passed to ``local()``.
+    stdout: What the command prints to stdout.
+    stderr: What the command prints to stderr.
+    exit_code: The command's exit code. If non-zero, ``local()`` fails.
+  """
+  pass
+
+def url(url: str, contents: str='', status: int=200) -> None:
+  """Fakes the response to ``read_url()``.
+
+  Args:
+    url: The URL, exactly as it'll be passed to ``read_url()``.
+    contents: The response body.
+    status: The HTTP status code. If not 2xx, ``read_url()`` fails.
+  """
+  pass
+
+def k8s_context(name: str) -> None:
+  """Sets the value that ``k8s_context()`` returns.
+
+  Args:
+    name: The name of the Kubernetes context.
+  """
+  pass
@@ -0,0 +1,162 @@
+from typing import Dict, Union, List, Any
+
+from .. import Blob
+
+# All of these functions take the same YAML inputs as ``k8s_yaml``: a path to a
+# YAML file, a Blob of YAML, or a list of either.
+#
+# They also take the same selector arguments as ``filter_yaml``, to choose which
+# objects to change. Objects that don't match pass through unchanged, and the
+# result is a Blob of all the objects, in their original order, that can be
+# passed to ``k8s_yaml``, ``filter_yaml``, or another ``k8s`` function.
+#
+# It's an error if nothing changes, because that almost always means a typo in
+# a selector.
+
+def select(yaml: Union[str, List[str], Blob], labels: Dict[str, str]=None, name: str=None, namespace: str=None, kind: str=None, api_version: str=None) -> Blob:
+  """Returns the objects that match all of the given selectors.
+
+  Like ``filter_yaml``, but returns only the matches.
+
+  .. code-block:: python
+
+    k8s_yaml(k8s.select('all.yaml', labels={'app': 'frontend'}))
+
+  Args:
+    yaml: Path(s) to YAML, or YAML as a Blob.
+    labels: Only match objects whose metadata has all of these labels.
+    name: Case-insensitive regexp matched against ``metadata.name``.
+    namespace: Case-insensitive regexp matched against ``metadata.namespace``.
+    kind: Case-insensitive regexp matched against ``kind``.
+    api_version: Case-insensitive regexp matched against ``apiVersion``.
+  """
+  pass
+
+def set_image(yaml: Union[str, List[str], Blob], image: str, container: str=None, labels: Dict[str, str]=None, name: str=None, namespace: str=None, kind: str=None, api_version: str=None) -> Blob:
+  """Sets the image of a container in each matching object's pod spec.
+
+  .. code-block:: python
+
+    k8s_yaml(k8s.set_image('deploy.yaml', 'frontend:dev', name='frontend'))
+
+  Args:
+    yaml: Path(s) to YAML, or YAML as a Blob.
+    image: The new image reference.
+    container: The name of the container (or init container) to change. If not set, each matching pod spec must have exactly one container.
+    labels: See ``k8s.select``.
+    name: See ``k8s.select``.
+    namespace: See ``k8s.select``.
+    kind: See ``k8s.select``.
+    api_version: See ``k8s.select``.
+  """
+  pass
+
+def set_env(yaml: Union[str, List[str], Blob], env: Dict[str, str], container: str=None, labels: Dict[str, str]=None, name: str=None, namespace: str=None, kind: str=None, api_version: str=None) -> Blob:
+  """Sets environment variables on containers in each matching object's pod spec.
+  Existing variables with the same name are replaced.
+
+  .. code-block:: python
+
+    k8s_yaml(k8s.set_env('deploy.yaml', {'LOG_LEVEL': 'debug'}))
+
+  Args:
+    yaml: Path(s) to YAML, or YAML as a Blob.
+    env: The environment variables to set.
+    container: The name of the container to change. If not set, changes every (non-init) container.
+    labels: See ``k8s.select``.
+    name: See ``k8s.select``.
+    namespace: See ``k8s.select``.
+    kind: See ``k8s.select``.
+    api_version: See ``k8s.select``.
+  """
+  pass
+
+def set_resources(yaml: Union[str, List[str], Blob], requests: Dict[str, str]=None, limits: Dict[str, str]=None, container: str=None, labels: Dict[str, str]=None, name: str=None, namespace: str=None, kind: str=None, api_version: str=None) -> Blob:
+  """Sets resource requests and limits on containers in each matching object's pod spec.
+  Resources that aren't mentioned are left alone.
+
+  .. code-block:: python
+
+    # Make room on a laptop cluster
+    k8s_yaml(k8s.set_resources('deploy.yaml', requests={'cpu': '10m', 'memory': '64Mi'}))
+
+  Args:
+    yaml: Path(s) to YAML, or YAML as a Blob.
+    requests: Resource requests, e.g., ``{'cpu': '100m'}``.
+    limits: Resource limits, e.g., ``{'memory': '1Gi'}``.
+    container: The name of the container to change. If not set, changes every (non-init) container.
+    labels: See ``k8s.select``.
+    name: See ``k8s.select``.
+    namespace: See ``k8s.select``.
+    kind: See ``k8s.select``.
+    api_version: See ``k8s.select``.
+  """
+  pass
+
+def set_replicas(yaml: Union[str, List[str], Blob], replicas: int, labels: Dict[str, str]=None, name: str=None, namespace: str=None, kind: str=None, api_version: str=None) -> Blob:
+  """Sets ``spec.replicas`` on each matching workload (Deployments, StatefulSets,
+  ReplicaSets, etc). Custom resources count as workloads if they already have
+  ``spec.replicas``. Custom resources with a pod template in ``spec.template``
+  but no ``spec.replicas`` are an error; use ``k8s.patch`` to set their replicas.
+
+  .. code-block:: python
+
+    k8s_yaml(k8s.set_replicas('deploy.yaml', 1))
+
+  Args:
+    yaml: Path(s) to YAML, or YAML as a Blob.
+    replicas: The number of replicas.
+    labels: See ``k8s.select``.
+    name: See ``k8s.select``.
+    namespace: See ``k8s.select``.
+    kind: See ``k8s.select``.
+    api_version: See ``k8s.select``.
+  """
+  pass
+
+def set_annotations(yaml: Union[str, List[str], Blob], annotations: Dict[str, str], labels: Dict[str, str]=None, name: str=None, namespace: str=None, kind: str=None, api_version: str=None) -> Blob:
+  """Adds annotations to each matching object's metadata. Existing annotations
+  with the same key are replaced.
+
+  Args:
+    yaml: Path(s) to YAML, or YAML as a Blob.
+    annotations: The annotations to add.
+    labels: See ``k8s.select``.
+    name: See ``k8s.select``.
+    namespace: See ``k8s.select``.
+    kind: See ``k8s.select``.
+    api_version: See ``k8s.select``.
+  """
+  pass
+
+def patch(yaml: Union[str, List[str], Blob], patch: Union[Dict[str, Any], List[Any], str, Blob], type: str='strategic', labels: Dict[str, str]=None, name: str=None, namespace: str=None, kind: str=None, api_version: str=None) -> Blob:
+  """Patches each matching object, like ``kubectl patch``.
+
+  .. code-block:: python
+
+    # Strategic merge patches merge lists of containers by name
+    k8s_yaml(k8s.patch('deploy.yaml', {
+      'spec': {'template': {'spec': {'containers': [
+        {'name': 'frontend', 'args': ['--debug']},
+      ]}}},
+    }, kind='deployment', name='frontend'))
+
+    # JSON patches
+    k8s_yaml(k8s.patch('deploy.yaml', [
+      {'op': 'remove', 'path': '/spec/template/spec/nodeSelector'},
+    ], type='json'))
+
+  Custom resources don't have strategic merge information, so a ``strategic``
+  patch on a custom resource is applied as a ``merge`` patch.
+
+  Args:
+    yaml: Path(s) to YAML, or YAML as a Blob.
+    patch: The patch, as a dict (or a list, for ``json`` patches), or as a string or Blob of YAML or JSON.
+    type: One of ``'strategic'`` (a `strategic merge patch <https://kubernetes.io/docs/tasks/manage-kubernetes-objects/update-api-object-kubectl-patch/>`_), ``'merge'`` (a JSON merge patch, RFC 7386), or ``'json'`` (a JSON patch, RFC 6902).
+    labels: See ``k8s.select``.
+    name: See ``k8s.select``.
+    namespace: See ``k8s.select``.
+    kind: See ``k8s.select``.
+    api_version: See ``k8s.select``.
+  """
+  pass
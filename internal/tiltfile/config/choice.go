@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// A string setting that must be one of a fixed set of values
+type choiceSetting struct {
+	choices []string
+	value   string
+	isSet   bool
+}
+
+var _ configValue = &choiceSetting{}
+var _ flag.Value = &choiceSetting{}
+
+func (s *choiceSetting) starlark() starlark.Value {
+	return starlark.String(s.value)
+}
+
+func (s *choiceSetting) IsSet() bool {
+	return s.isSet
+}
+
+// Shows up in usage output, e.g., `--env dev|staging|prod`
+func (s *choiceSetting) Type() string {
+	return strings.Join(s.choices, "|")
+}
+
+func (s *choiceSetting) setFromInterface(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	v, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("expected string, found %T", i)
+	}
+
+	return s.set(v)
+}
+
+func (s *choiceSetting) Set(v string) error {
+	if s.isSet {
+		return fmt.Errorf("choice settings can only be specified once. multiple values found (last value: %s)", v)
+	}
+
+	return s.set(v)
+}
+
+func (s *choiceSetting) set(v string) error {
+	for _, c := range s.choices {
+		if v == c {
+			s.value = v
+			s.isSet = true
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid choice %q, expected one of: %s", v, strings.Join(s.choices, ", "))
+}
+
+func (s *choiceSetting) String() string {
+	return s.value
+}
+
+func defineChoice(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var choices value.StringList
+	var isArgs bool
+	var usage string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"name",
+		&name,
+		"choices",
+		&choices,
+		"args?",
+		&isArgs,
+		"usage?",
+		&usage,
+	)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	if len(choices) == 0 {
+		return starlark.None, fmt.Errorf("%s: 'choices' must not be empty", fn.Name())
+	}
+
+	return defineConfigSetting(thread, fn, name, isArgs, usage, func() configValue {
+		return &choiceSetting{choices: choices}
+	})
+}
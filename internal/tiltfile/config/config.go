@@ -21,6 +21,9 @@ type Settings struct {
 
 	configParseCalled bool
 
+	// values read from tilt_config.json when parse was called
+	configFileValues configMap
+
 	// if parse has been called, the directory containing the Tiltfile that called it
 	seenWorkingDirectory string
 }
@@ -55,6 +58,17 @@ func GetState(m starkit.Model) (Settings, error) {
 	return state, err
 }
 
+// The args defined by the Tiltfile, sorted by name.
+//
+// Empty if the Tiltfile never called config.parse(), because in that case
+// Tiltfile args are the names of resources to enable.
+func (s Settings) TiltfileArgs() []model.TiltfileArg {
+	if !s.configParseCalled {
+		return nil
+	}
+	return s.configDef.tiltfileArgs(s.configFileValues)
+}
+
 func (e *Plugin) OnStart(env *starkit.Environment) error {
 	for _, b := range []struct {
 		name string
@@ -72,9 +86,14 @@ func (e *Plugin) OnStart(env *starkit.Environment) error {
 		{"config.define_bool", configSettingDefinitionBuiltin(func() configValue {
 			return &boolSetting{}
 		})},
-		{"config.define_object", configSettingDefinitionBuiltin(func() configValue {
-			return &objectSetting{}
+		{"config.define_int", configSettingDefinitionBuiltin(func() configValue {
+			return &intSetting{}
 		})},
+		{"config.define_float", configSettingDefinitionBuiltin(func() configValue {
+			return &floatSetting{}
+		})},
+		{"config.define_choice", defineChoice},
+		{"config.define_object", defineObject},
 	} {
 		err := env.AddBuiltin(b.name, b.f)
 		if err != nil {
@@ -145,7 +164,20 @@ func (e *Plugin) parse(thread *starlark.Thread, fn *starlark.Builtin, args starl
 		return starlark.None, err
 	}
 
-	ret, out, err := settings.configDef.parse(userConfigPath, tf.Spec.Args)
+	configFileValues, err := settings.configDef.readFromFile(userConfigPath)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	err = starkit.SetState(thread, func(settings Settings) (Settings, error) {
+		settings.configFileValues = configFileValues
+		return settings, nil
+	})
+	if err != nil {
+		return starlark.None, err
+	}
+
+	ret, out, err := settings.configDef.parse(configFileValues, tf.Spec.Args)
 	if out != "" {
 		thread.Print(thread, out)
 	}
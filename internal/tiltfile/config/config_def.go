@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
@@ -11,7 +12,9 @@ import (
 	flag "github.com/spf13/pflag"
 	"go.starlark.net/starlark"
 
+	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type configValue interface {
@@ -65,7 +68,7 @@ func (cd ConfigDef) incorporateArgs(config configMap, args []string) (ret config
 	var settingsFromArgs configMap
 	settingsFromArgs, output, err = cd.parseArgs(args)
 	if err != nil {
-		return nil, output, fmt.Errorf("invalid Tiltfile config args: %v", err)
+		return nil, output, fmt.Errorf("invalid Tiltfile config args: %w", err)
 	}
 
 	config = mergeConfigMaps(config, settingsFromArgs)
@@ -73,12 +76,7 @@ func (cd ConfigDef) incorporateArgs(config configMap, args []string) (ret config
 	return config, output, nil
 }
 
-func (cd ConfigDef) parse(configPath string, args []string) (v starlark.Value, output string, err error) {
-	config, err := cd.readFromFile(configPath)
-	if err != nil {
-		return starlark.None, "", err
-	}
-
+func (cd ConfigDef) parse(config configMap, args []string) (v starlark.Value, output string, err error) {
 	config, output, err = cd.incorporateArgs(config, args)
 	if err != nil {
 		return starlark.None, output, err
@@ -92,6 +90,41 @@ func (cd ConfigDef) parse(configPath string, args []string) (v starlark.Value, o
 	return ret, output, nil
 }
 
+// describe the defined settings, using values from the config file as defaults
+func (cd ConfigDef) tiltfileArgs(defaults configMap) []model.TiltfileArg {
+	var ret []model.TiltfileArg
+	for name, def := range cd.configSettings {
+		arg := model.TiltfileArg{
+			Name:       name,
+			Usage:      def.usage,
+			Positional: name == cd.positionalSettingName,
+		}
+
+		switch v := def.newValue().(type) {
+		case *choiceSetting:
+			arg.Type = "choice"
+			arg.Choices = v.choices
+		case *objectSetting:
+			arg.Type = "object"
+			arg.Schema = v.schemaSource
+		default:
+			arg.Type = v.Type()
+		}
+
+		if d, ok := defaults[name]; ok && d.IsSet() {
+			// all config values are JSON-able, since they can be read from JSON
+			arg.Default, _ = encoding.ConvertStarlarkToStructuredData(d.starlark())
+		}
+
+		ret = append(ret, arg)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
 // parse command-line args
 func (cd ConfigDef) parseArgs(args []string) (ret configMap, output string, err error) {
 	fs := flag.NewFlagSet("", flag.ContinueOnError)
@@ -117,7 +150,7 @@ func (cd ConfigDef) parseArgs(args []string) (ret configMap, output string, err
 		if strings.TrimSpace(usage) != "" {
 			usage = "\nUsage:\n" + usage
 		}
-		return nil, w.String(), fmt.Errorf("%v%s", err, usage)
+		return nil, w.String(), fmt.Errorf("%w%s", err, usage)
 	}
 
 	if len(fs.Args()) > 0 {
@@ -193,38 +226,43 @@ func configSettingDefinitionBuiltin(newConfigValue func() configValue) starkit.F
 			return starlark.None, err
 		}
 
-		if name == "" {
-			return starlark.None, errors.New("'name' is required")
-		}
+		return defineConfigSetting(thread, fn, name, isArgs, usage, newConfigValue)
+	}
+}
 
-		err = starkit.SetState(thread, func(settings Settings) (Settings, error) {
-			if settings.configParseCalled {
-				return settings, fmt.Errorf("%s cannot be called after config.parse is called", fn.Name())
-			}
+// saves a config setting definition, for builtins that have already unpacked their args
+func defineConfigSetting(thread *starlark.Thread, fn *starlark.Builtin, name string, isArgs bool, usage string, newConfigValue func() configValue) (starlark.Value, error) {
+	if name == "" {
+		return starlark.None, errors.New("'name' is required")
+	}
 
-			if _, ok := settings.configDef.configSettings[name]; ok {
-				return settings, fmt.Errorf("%s defined multiple times", name)
-			}
+	err := starkit.SetState(thread, func(settings Settings) (Settings, error) {
+		if settings.configParseCalled {
+			return settings, fmt.Errorf("%s cannot be called after config.parse is called", fn.Name())
+		}
 
-			if isArgs {
-				if settings.configDef.positionalSettingName != "" {
-					return settings, fmt.Errorf("both %s and %s are defined as positional args", name, settings.configDef.positionalSettingName)
-				}
+		if _, ok := settings.configDef.configSettings[name]; ok {
+			return settings, fmt.Errorf("%s defined multiple times", name)
+		}
 
-				settings.configDef.positionalSettingName = name
+		if isArgs {
+			if settings.configDef.positionalSettingName != "" {
+				return settings, fmt.Errorf("both %s and %s are defined as positional args", name, settings.configDef.positionalSettingName)
 			}
 
-			settings.configDef.configSettings[name] = configSetting{
-				newValue: newConfigValue,
-				usage:    usage,
-			}
+			settings.configDef.positionalSettingName = name
+		}
 
-			return settings, nil
-		})
-		if err != nil {
-			return starlark.None, err
+		settings.configDef.configSettings[name] = configSetting{
+			newValue: newConfigValue,
+			usage:    usage,
 		}
 
-		return starlark.None, nil
+		return settings, nil
+	})
+	if err != nil {
+		return starlark.None, err
 	}
+
+	return starlark.None, nil
 }
@@ -3,10 +3,12 @@ package config
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/tiltfile/include"
@@ -281,6 +283,97 @@ Usage:
 	require.EqualError(t, err, expected)
 }
 
+func TestUsageTypes(t *testing.T) {
+	f := NewFixture(t, []string{"--bar", "hello"}, "")
+
+	f.File("Tiltfile", `
+config.define_int('replicas', usage='how many replicas to run')
+config.define_choice('env', choices=['dev', 'staging'], usage='where to deploy')
+config.parse()
+`)
+
+	expected := `invalid Tiltfile config args: unknown flag: --bar
+Usage:
+      --env dev|staging   where to deploy
+      --replicas int      how many replicas to run
+`
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.EqualError(t, err, expected)
+}
+
+func TestIsHelp(t *testing.T) {
+	defs := []model.TiltfileArg{
+		{Name: "name", Type: "string"},
+		{Name: "debug", Type: "bool"},
+		{Name: "services", Type: "list[string]", Positional: true},
+	}
+	for _, tc := range []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"--help"}, true},
+		{[]string{"-h"}, true},
+		{[]string{"frontend", "-h"}, true},
+		{[]string{"--debug", "-h"}, true},
+		{[]string{"--unknown", "--help"}, true},
+		{[]string{"--name", "-h"}, false},
+		{[]string{"--name", "--help"}, false},
+		{[]string{"--name=-h"}, false},
+		{[]string{"--", "-h"}, false},
+		{[]string{"frontend"}, false},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			require.Equal(t, tc.expected, IsHelp(tc.args, defs))
+		})
+	}
+}
+
+func TestArgValues(t *testing.T) {
+	defs := []model.TiltfileArg{
+		{Name: "name", Type: "string"},
+		{Name: "debug", Type: "bool"},
+		{Name: "tags", Type: "list[string]"},
+		{Name: "services", Type: "list[string]", Positional: true},
+	}
+
+	values, err := ArgValues([]string{"--name", "fe", "--debug", "--tags=a", "--tags", "b", "web", "api"}, defs)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"name":     {"fe"},
+		"debug":    {"true"},
+		"tags":     {"a", "b"},
+		"services": {"web", "api"},
+	}, values)
+
+	_, err = ArgValues([]string{"--unknown"}, defs)
+	require.Error(t, err)
+
+	_, err = ArgValues([]string{"web"}, defs[:1])
+	require.Error(t, err)
+}
+
+func TestDefineChoiceNoChoices(t *testing.T) {
+	f := NewFixture(t, nil, "")
+
+	f.File("Tiltfile", "config.define_choice('env', choices=[])")
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "config.define_choice: 'choices' must not be empty")
+}
+
+func TestDefineObjectInvalidSchema(t *testing.T) {
+	f := NewFixture(t, nil, "")
+
+	f.File("Tiltfile", "config.define_object('foo', schema={'type': 'banana'})")
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "config.define_object: invalid schema")
+}
+
 // i.e., tilt up foo bar gets you resources foo and bar
 func TestDefaultTiltBehavior(t *testing.T) {
 	f := NewFixture(t, []string{"foo", "bar"}, "")
@@ -507,6 +600,68 @@ print(config.main_dir)
 	require.Equal(t, fmt.Sprintf("%s\n%s\n", val, val), f.PrintOutput())
 }
 
+func TestTiltfileArgs(t *testing.T) {
+	f := NewFixture(t, nil, "")
+
+	f.File("Tiltfile", `
+config.define_string_list('resources', args=True, usage='resources to enable')
+config.define_choice('env', choices=['dev', 'prod'])
+config.define_int('replicas')
+config.define_object('db', schema={'type': 'object'})
+config.parse()
+`)
+	f.File(UserConfigFileName, `{"env": "prod", "resources": ["frontend"]}`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	require.Equal(t, []model.TiltfileArg{
+		{Name: "db", Type: "object", Schema: map[string]interface{}{"type": "object"}},
+		{Name: "env", Type: "choice", Choices: []string{"dev", "prod"}, Default: "prod"},
+		{Name: "replicas", Type: "int"},
+		{Name: "resources", Type: "list[string]", Usage: "resources to enable", Positional: true, Default: []interface{}{"frontend"}},
+	}, MustState(result).TiltfileArgs())
+
+	require.Equal(t, `Flags:
+      --db object
+      --env dev|prod   (default "prod")
+      --replicas int
+
+Positional args:
+  resources (list[string])   resources to enable (default ["frontend"])
+`, Usage(MustState(result).TiltfileArgs()))
+}
+
+func TestTiltfileArgsNoParse(t *testing.T) {
+	f := NewFixture(t, nil, "")
+
+	f.File("Tiltfile", `
+config.define_string('env')
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	require.Empty(t, MustState(result).TiltfileArgs())
+}
+
+func TestTiltfileArgsHelp(t *testing.T) {
+	f := NewFixture(t, []string{"--help"}, "")
+
+	f.File("Tiltfile", `
+config.define_string('env', usage='where to deploy')
+config.parse()
+fail('should not get here')
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "help requested")
+	require.True(t, errors.Is(err, pflag.ErrHelp))
+	require.Equal(t, []model.TiltfileArg{
+		{Name: "env", Type: "string", Usage: "where to deploy"},
+	}, MustState(result).TiltfileArgs())
+}
+
 func NewFixture(tb testing.TB, args []string, tiltSubcommand model.TiltSubcommand) *starkit.Fixture {
 	ext := NewPlugin(tiltSubcommand)
 
@@ -582,6 +737,34 @@ func TestTypes(t *testing.T) {
 		newTypeTestCase("obj from config", "config.define_object('foo')").
 			withConfigFile(`{"foo": ["a", "b", "c"]}`).
 			withExpectedVal(`["a", "b", "c"]`),
+
+		newTypeTestCase("obj matching schema", `config.define_object('foo', schema={'type': 'object', 'required': ['port']})`).
+			withArgs(`--foo`, `{"port": 8080}`).
+			withExpectedVal(`{"port": 8080}`),
+		newTypeTestCase("obj from args not matching schema", `config.define_object('foo', schema={'type': 'object', 'required': ['port']})`).
+			withArgs(`--foo`, `{"host": "localhost"}`).
+			withExpectedError("does not match schema: (root): port is required"),
+		newTypeTestCase("obj from config not matching schema", `config.define_object('foo', schema='{"type": "array"}')`).
+			withConfigFile(`{"foo": {"a": "b"}}`).
+			withExpectedError("specified invalid value for setting foo: does not match schema: (root): Invalid type. Expected: array, given: object"),
+
+		newTypeTestCase("int from args", "config.define_int('foo')").withArgs("--foo", "3").withExpectedVal("3"),
+		newTypeTestCase("int from config", "config.define_int('foo')").withConfigFile(`{"foo": 3}`).withExpectedVal("3"),
+		newTypeTestCase("invalid int from args", "config.define_int('foo')").withArgs("--foo", "three").withExpectedError(`expected int, found "three"`),
+		newTypeTestCase("invalid int from config", "config.define_int('foo')").withConfigFile(`{"foo": 3.5}`).withExpectedError("expected int, found 3.5"),
+		newTypeTestCase("int out of range from config", "config.define_int('foo')").withConfigFile(`{"foo": 1e19}`).withExpectedError("int value 1e+19 is out of range"),
+		newTypeTestCase("int out of range from args", "config.define_int('foo')").withArgs("--foo", "10000000000000000000").withExpectedError(`expected int, found "10000000000000000000"`),
+		newTypeTestCase("int defined multiple times", "config.define_int('foo')").withArgs("--foo", "1", "--foo", "2").withExpectedError("int settings can only be specified once"),
+
+		newTypeTestCase("float from args", "config.define_float('foo')").withArgs("--foo", "0.5").withExpectedVal("0.5"),
+		newTypeTestCase("float from config", "config.define_float('foo')").withConfigFile(`{"foo": 2}`).withExpectedVal("2.0"),
+		newTypeTestCase("invalid float from config", "config.define_float('foo')").withConfigFile(`{"foo": "2"}`).withExpectedError("expected float, found string"),
+
+		newTypeTestCase("choice from args", "config.define_choice('foo', choices=['dev', 'prod'])").withArgs("--foo", "prod").withExpectedVal("'prod'"),
+		newTypeTestCase("choice from config", "config.define_choice('foo', choices=['dev', 'prod'])").withConfigFile(`{"foo": "dev"}`).withExpectedVal("'dev'"),
+		newTypeTestCase("positional choice", "config.define_choice('foo', choices=['dev', 'prod'], args=True)").withArgs("dev").withExpectedVal("'dev'"),
+		newTypeTestCase("invalid choice from args", "config.define_choice('foo', choices=['dev', 'prod'])").withArgs("--foo", "staging").withExpectedError(`invalid choice "staging", expected one of: dev, prod`),
+		newTypeTestCase("invalid choice from config", "config.define_choice('foo', choices=['dev', 'prod'])").withConfigFile(`{"foo": "staging"}`).withExpectedError(`invalid choice "staging", expected one of: dev, prod`),
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			f := NewFixture(t, tc.args, "")
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	flag "github.com/spf13/pflag"
+	"go.starlark.net/starlark"
+)
+
+type floatSetting struct {
+	value float64
+	isSet bool
+}
+
+var _ configValue = &floatSetting{}
+var _ flag.Value = &floatSetting{}
+
+func (s *floatSetting) starlark() starlark.Value {
+	return starlark.Float(s.value)
+}
+
+func (s *floatSetting) IsSet() bool {
+	return s.isSet
+}
+
+func (s *floatSetting) Type() string {
+	return "float"
+}
+
+func (s *floatSetting) setFromInterface(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+
+	switch v := i.(type) {
+	case float64:
+		s.value = v
+	case int:
+		s.value = float64(v)
+	case int64:
+		s.value = float64(v)
+	default:
+		return fmt.Errorf("expected float, found %T", i)
+	}
+
+	s.isSet = true
+	return nil
+}
+
+func (s *floatSetting) Set(v string) error {
+	if s.isSet {
+		return fmt.Errorf("float settings can only be specified once. multiple values found (last value: %s)", v)
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("expected float, found %q", v)
+	}
+	s.value = f
+	s.isSet = true
+	return nil
+}
+
+func (s *floatSetting) String() string {
+	return strconv.FormatFloat(s.value, 'g', -1, 64)
+}
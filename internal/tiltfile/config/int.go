@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	flag "github.com/spf13/pflag"
+	"go.starlark.net/starlark"
+)
+
+type intSetting struct {
+	value int
+	isSet bool
+}
+
+var _ configValue = &intSetting{}
+var _ flag.Value = &intSetting{}
+
+func (s *intSetting) starlark() starlark.Value {
+	return starlark.MakeInt(s.value)
+}
+
+func (s *intSetting) IsSet() bool {
+	return s.isSet
+}
+
+func (s *intSetting) Type() string {
+	return "int"
+}
+
+func (s *intSetting) setFromInterface(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+
+	// JSON numbers are decoded as floats, so accept any float with no fractional
+	// part that fits in an int. float64(math.MaxInt) rounds up to 2^63, so
+	// compare against the negated minimum instead.
+	switch v := i.(type) {
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("expected int, found %v", v)
+		}
+		if v < float64(math.MinInt) || v >= -float64(math.MinInt) {
+			return fmt.Errorf("int value %v is out of range", v)
+		}
+		s.value = int(v)
+	case int:
+		s.value = v
+	case int64:
+		if int64(int(v)) != v {
+			return fmt.Errorf("int value %v is out of range", v)
+		}
+		s.value = int(v)
+	default:
+		return fmt.Errorf("expected int, found %T", i)
+	}
+
+	s.isSet = true
+	return nil
+}
+
+func (s *intSetting) Set(v string) error {
+	if s.isSet {
+		return fmt.Errorf("int settings can only be specified once. multiple values found (last value: %s)", v)
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("expected int, found %q", v)
+	}
+	s.value = i
+	s.isSet = true
+	return nil
+}
+
+func (s *intSetting) String() string {
+	return strconv.Itoa(s.value)
+}
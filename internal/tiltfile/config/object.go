@@ -4,13 +4,21 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"strings"
 
+	"github.com/xeipuuv/gojsonschema"
 	"go.starlark.net/starlark"
 
 	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 )
 
 type objectSetting struct {
+	// if non-nil, values must validate against this JSON schema
+	schema *gojsonschema.Schema
+	// the schema as passed to define_object, for showing in help
+	schemaSource interface{}
+
 	value starlark.Value
 	isSet bool
 }
@@ -34,6 +42,10 @@ func (s *objectSetting) setFromInterface(i interface{}) error {
 	if i == nil {
 		return nil
 	}
+	err := s.validate(i)
+	if err != nil {
+		return err
+	}
 	v, err := encoding.ConvertStructuredDataToStarlark(i)
 	if err != nil {
 		return err
@@ -56,6 +68,11 @@ func (s *objectSetting) Set(str string) error {
 		return fmt.Errorf("decoding JSON, got %q: %v", str, err)
 	}
 
+	err = s.validate(decoded)
+	if err != nil {
+		return err
+	}
+
 	v, err := encoding.ConvertStructuredDataToStarlark(decoded)
 	if err != nil {
 		return err
@@ -72,3 +89,81 @@ func (s *objectSetting) String() string {
 	}
 	return s.value.String()
 }
+
+func (s *objectSetting) validate(i interface{}) error {
+	if s.schema == nil {
+		return nil
+	}
+
+	result, err := s.schema.Validate(gojsonschema.NewGoLoader(i))
+	if err != nil {
+		return fmt.Errorf("validating against schema: %v", err)
+	}
+	if !result.Valid() {
+		var msgs []string
+		for _, e := range result.Errors() {
+			msgs = append(msgs, e.String())
+		}
+		return fmt.Errorf("does not match schema: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+func defineObject(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var isArgs bool
+	var usage string
+	var schemaValue starlark.Value
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"name",
+		&name,
+		"args?",
+		&isArgs,
+		"usage?",
+		&usage,
+		"schema?",
+		&schemaValue,
+	)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	var schema *gojsonschema.Schema
+	var schemaSource interface{}
+	if schemaValue != nil && schemaValue != starlark.None {
+		schema, schemaSource, err = parseSchema(schemaValue)
+		if err != nil {
+			return starlark.None, fmt.Errorf("%s: invalid schema: %v", fn.Name(), err)
+		}
+	}
+
+	return defineConfigSetting(thread, fn, name, isArgs, usage, func() configValue {
+		return &objectSetting{schema: schema, schemaSource: schemaSource}
+	})
+}
+
+// Accepts either a dict or a JSON string.
+func parseSchema(v starlark.Value) (*gojsonschema.Schema, interface{}, error) {
+	var source interface{}
+	switch v := v.(type) {
+	case starlark.String:
+		err := json.Unmarshal([]byte(v.GoString()), &source)
+		if err != nil {
+			return nil, nil, err
+		}
+	case *starlark.Dict:
+		data, err := encoding.ConvertStarlarkToStructuredData(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		source = data
+	default:
+		return nil, nil, fmt.Errorf("expected dict or string, got %s", v.Type())
+	}
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(source))
+	if err != nil {
+		return nil, nil, err
+	}
+	return schema, source, nil
+}
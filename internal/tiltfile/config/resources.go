@@ -126,7 +126,7 @@ func match(manifests []model.Manifest, requestedManifests []model.ManifestName)
 	var result []model.ManifestName
 	for _, m := range manifests {
 		// Default to including UnresourcedYAML ("Uncategorized") to match historical behavior.
-		if manifestsToRun[m.Name] || m.Name == model.UnresourcedYAMLManifestName {
+		if manifestsToRun[m.Name] || model.IsUnresourcedYAMLManifest(m) {
 			result = append(result, m.Name)
 		}
 	}
@@ -134,10 +134,7 @@ func match(manifests []model.Manifest, requestedManifests []model.ManifestName)
 	if len(unknownNames) > 0 {
 		unmatchedNames := unmatchedManifestNames(manifests, requestedManifests)
 
-		return nil, fmt.Errorf(`You specified some resources that could not be found: %s
-Is this a typo? Existing resources in Tiltfile: %s`,
-			sliceutils.QuotedStringList(unknownNames),
-			sliceutils.QuotedStringList(unmatchedNames))
+		return nil, UnknownResourcesError{Unknown: unknownNames, Existing: unmatchedNames}
 	}
 
 	return result, nil
@@ -158,3 +155,16 @@ func unmatchedManifestNames(manifests []model.Manifest, requestedManifests []mod
 
 	return ret
 }
+
+// Returned when the Tiltfile args name resources that the Tiltfile doesn't define.
+type UnknownResourcesError struct {
+	Unknown  []string
+	Existing []string
+}
+
+func (e UnknownResourcesError) Error() string {
+	return fmt.Sprintf(`You specified some resources that could not be found: %s
+Is this a typo? Existing resources in Tiltfile: %s`,
+		sliceutils.QuotedStringList(e.Unknown),
+		sliceutils.QuotedStringList(e.Existing))
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Formats help for Tiltfile args, in the same format that config.parse()
+// uses when it can't parse the args it's given.
+func Usage(args []model.TiltfileArg) string {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	var positional *model.TiltfileArg
+	for i, arg := range args {
+		if arg.Positional {
+			positional = &args[i]
+			continue
+		}
+
+		f := fs.VarPF(usageValue{typ: usageType(arg)}, arg.Name, "", usageText(arg))
+		if arg.Type == "bool" {
+			f.NoOptDefVal = "true"
+		}
+	}
+
+	sb := strings.Builder{}
+	if fs.HasFlags() {
+		sb.WriteString("Flags:\n")
+		for _, line := range strings.Split(strings.TrimSuffix(fs.FlagUsagesWrapped(80), "\n"), "\n") {
+			sb.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+	}
+
+	if positional != nil {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("Positional args:\n")
+		line := fmt.Sprintf("  %s (%s)   %s", positional.Name, usageType(*positional), usageText(*positional))
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+
+	return sb.String()
+}
+
+// Returns true if config.parse() would treat the args as a request for help,
+// given the args the Tiltfile defines.
+//
+// e.g., "-h" asks for help, but in "--name -h", it's the value of --name.
+func IsHelp(args []string, defs []model.TiltfileArg) bool {
+	fs := newArgsFlagSet(defs, func(arg model.TiltfileArg) flag.Value {
+		return anyValue{typ: arg.Type}
+	})
+	fs.ParseErrorsWhitelist.UnknownFlags = true
+	return errors.Is(fs.Parse(args), flag.ErrHelp)
+}
+
+// Splits the args into the values of each arg the Tiltfile defines, the same
+// way config.parse() does, but without validating them. Positional args go
+// under the name of the positional arg, if there is one.
+//
+// Used to show the current args in a form.
+func ArgValues(args []string, defs []model.TiltfileArg) (map[string][]string, error) {
+	result := make(map[string][]string)
+	fs := newArgsFlagSet(defs, func(arg model.TiltfileArg) flag.Value {
+		return recordingValue{typ: arg.Type, name: arg.Name, values: result}
+	})
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fs.Args()) > 0 {
+		positional := ""
+		for _, arg := range defs {
+			if arg.Positional {
+				positional = arg.Name
+			}
+		}
+		if positional == "" {
+			return nil, fmt.Errorf("positional args (%q) were specified, but none were expected", strings.Join(fs.Args(), " "))
+		}
+		result[positional] = append(result[positional], fs.Args()...)
+	}
+	return result, nil
+}
+
+// A flag set with a flag for each non-positional arg.
+func newArgsFlagSet(defs []model.TiltfileArg, newValue func(arg model.TiltfileArg) flag.Value) *flag.FlagSet {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	for _, arg := range defs {
+		if arg.Positional {
+			continue
+		}
+		f := fs.VarPF(newValue(arg), arg.Name, "", "")
+		if arg.Type == "bool" {
+			f.NoOptDefVal = "true"
+		}
+	}
+	return fs
+}
+
+func usageType(arg model.TiltfileArg) string {
+	if arg.Type == "choice" {
+		return strings.Join(arg.Choices, "|")
+	}
+	return arg.Type
+}
+
+// The usage string, with the default from tilt_config.json appended.
+func usageText(arg model.TiltfileArg) string {
+	if arg.Default == nil {
+		return arg.Usage
+	}
+
+	def := fmt.Sprintf("%v", arg.Default)
+	if _, ok := arg.Default.(string); ok {
+		def = fmt.Sprintf("%q", arg.Default)
+	} else if b, err := json.Marshal(arg.Default); err == nil {
+		def = string(b)
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s (default %s)", arg.Usage, def))
+}
+
+// A flag.Value that's only used to print usage.
+type usageValue struct {
+	typ string
+}
+
+func (v usageValue) String() string     { return "" }
+func (v usageValue) Set(s string) error { return fmt.Errorf("cannot set usage-only flag") }
+func (v usageValue) Type() string       { return v.typ }
+
+// A flag.Value that accepts any value, so that we can find out how args
+// parse without validating them.
+type anyValue struct {
+	typ string
+}
+
+func (v anyValue) String() string     { return "" }
+func (v anyValue) Set(s string) error { return nil }
+func (v anyValue) Type() string       { return v.typ }
+
+// A flag.Value that records every value it's set to.
+type recordingValue struct {
+	typ    string
+	name   string
+	values map[string][]string
+}
+
+func (v recordingValue) String() string { return "" }
+func (v recordingValue) Set(s string) error {
+	v.values[v.name] = append(v.values[v.name], s)
+	return nil
+}
+func (v recordingValue) Type() string { return v.typ }
@@ -14,6 +14,7 @@ import (
 	"go.starlark.net/starlark"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker/buildkit"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
@@ -43,8 +44,9 @@ type dockerImage struct {
 	network          string
 	extraTags        []string // Extra tags added at build-time.
 	cacheFrom        []string
+	cacheTo          []string
 	pullParent       bool
-	platform         string
+	platforms        []string
 
 	// Overrides the container args. Used as an escape hatch in case people want the old entrypoint behavior.
 	// See discussion here:
@@ -111,8 +113,8 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		onlyVal,
 		entrypoint starlark.Value
 	var buildArgs value.StringStringMap
-	var network, platform value.Stringable
-	var ssh, secret, extraTags, cacheFrom value.StringOrStringList
+	var network value.Stringable
+	var ssh, secret, extraTags, cacheFrom, cacheTo, platform value.StringOrStringList
 	var matchInEnvVars, pullParent bool
 	var overrideArgsVal starlark.Sequence
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
@@ -134,6 +136,7 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		"network?", &network,
 		"extra_tag?", &extraTags,
 		"cache_from?", &cacheFrom,
+		"cache_to?", &cacheTo,
 		"pull?", &pullParent,
 		"platform?", &platform,
 	); err != nil {
@@ -215,11 +218,22 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		}
 	}
 
-	if platform.Value == "" {
+	cacheFromSpecs, err := resolveCacheSpecs(thread, "cache_from", cacheFrom.Values)
+	if err != nil {
+		return nil, err
+	}
+	cacheToSpecs, err := resolveCacheSpecs(thread, "cache_to", cacheTo.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms := platform.Values
+	if len(platforms) == 0 && os.Getenv(dockerPlatformEnv) != "" {
 		// for compatibility with Docker CLI, support the env var fallback
 		// see https://docs.docker.com/engine/reference/commandline/cli/#environment-variables
-		platform.Value = os.Getenv(dockerPlatformEnv)
+		platforms = []string{os.Getenv(dockerPlatformEnv)}
 	}
+	platforms = splitPlatforms(platforms)
 
 	buildArgsList := []string{}
 	for k, v := range buildArgs.AsMap() {
@@ -250,9 +264,10 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		targetStage:      targetStage,
 		network:          network.Value,
 		extraTags:        extraTags.Values,
-		cacheFrom:        cacheFrom.Values,
+		cacheFrom:        cacheFromSpecs,
+		cacheTo:          cacheToSpecs,
 		pullParent:       pullParent,
-		platform:         platform.Value,
+		platforms:        platforms,
 		tiltfilePath:     starkit.CurrentExecPath(thread),
 	}
 	err = s.buildIndex.addImage(r)
@@ -263,6 +278,49 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 	return starlark.None, nil
 }
 
+// Validates BuildKit cache specs, and resolves local cache directories
+// relative to the Tiltfile.
+func resolveCacheSpecs(thread *starlark.Thread, arg string, specs []string) ([]string, error) {
+	entries, err := buildkit.ParseCacheSpecs(specs)
+	if err != nil {
+		return nil, fmt.Errorf("Argument %s: %v", arg, err)
+	}
+
+	result := make([]string, 0, len(specs))
+	for i, e := range entries {
+		if e.Type != buildkit.CacheTypeLocal {
+			result = append(result, specs[i])
+			continue
+		}
+
+		for _, k := range []string{"src", "dest"} {
+			if v, ok := e.Attrs[k]; ok {
+				e.Attrs[k] = starkit.AbsPath(thread, v)
+			}
+		}
+		spec, err := buildkit.FormatCacheSpec(e)
+		if err != nil {
+			return nil, fmt.Errorf("Argument %s: %v", arg, err)
+		}
+		result = append(result, spec)
+	}
+	return result, nil
+}
+
+// Docker accepts a comma-separated list of platforms, so we do too.
+func splitPlatforms(values []string) []string {
+	var result []string
+	for _, v := range values {
+		for _, p := range strings.Split(v, ",") {
+			p = strings.TrimSpace(p)
+			if p != "" {
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
+
 func (s *tiltfileState) parseOnly(val starlark.Value) ([]string, error) {
 	paths, err := parseValuesToStrings(val, "only")
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -20,10 +21,12 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/pkg/errors"
 	"go.starlark.net/starlark"
+	"sigs.k8s.io/yaml"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/links"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
@@ -46,18 +49,26 @@ func (dc dcResourceSet) Empty() bool { return reflect.DeepEqual(dc, dcResourceSe
 func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var configPaths starlark.Value
 	var projectName string
+	var profiles value.StringOrStringList
 	envFile := value.NewLocalPathUnpacker(thread)
 
 	err := s.unpackArgs(fn.Name(), args, kwargs,
 		"configPaths", &configPaths,
 		"env_file?", &envFile,
 		"project_name?", &projectName,
+		"profiles?", &profiles,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	paths := starlarkValueOrSequenceToSlice(configPaths)
+	var paths []starlark.Value
+	if d, ok := configPaths.(*starlark.Dict); ok {
+		// A dict is a sequence of its keys, so don't iterate over it.
+		paths = []starlark.Value{d}
+	} else {
+		paths = starlarkValueOrSequenceToSlice(configPaths)
+	}
 
 	if len(paths) == 0 {
 		return nil, fmt.Errorf("Nothing to compose")
@@ -81,13 +92,7 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 		ProjectPath: dc.Project.ProjectPath,
 		Name:        projectName,
 		EnvFile:     envFile.Value,
-	}
-
-	if project.EnvFile != "" {
-		err = io.RecordReadPath(thread, io.WatchFileOnly, project.EnvFile)
-		if err != nil {
-			return nil, err
-		}
+		Profiles:    profiles.Values,
 	}
 
 	for _, val := range paths {
@@ -95,30 +100,30 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 		case nil:
 			continue
 		case io.Blob:
-			yaml := v.String()
-			message := "unable to store yaml blob"
-			tmpdir, err := s.tempDir()
+			path, err := s.writeDCYAML(v.String())
 			if err != nil {
-				return nil, errors.Wrap(err, message)
+				return nil, errors.Wrap(err, "unable to store yaml blob")
 			}
-			tmpfile, err := os.Create(filepath.Join(tmpdir.Path(), fmt.Sprintf("%x.yml", sha256.Sum256([]byte(yaml)))))
+			project.ConfigPaths = append(project.ConfigPaths, path)
+		case *starlark.Dict:
+			// Overrides for the config that comes before it, like an extra -f file.
+			data, err := encoding.ConvertStarlarkToStructuredData(v)
 			if err != nil {
-				return nil, errors.Wrap(err, message)
+				return nil, errors.Wrap(err, "converting dict to yaml")
 			}
-			_, err = tmpfile.WriteString(yaml)
+			content, err := yaml.Marshal(data)
 			if err != nil {
-				tmpfile.Close()
-				return nil, errors.Wrap(err, message)
+				return nil, errors.Wrap(err, "converting dict to yaml")
 			}
-			err = tmpfile.Close()
+			path, err := s.writeDCYAML(string(content))
 			if err != nil {
-				return nil, errors.Wrap(err, message)
+				return nil, errors.Wrap(err, "unable to store yaml dict")
 			}
-			project.ConfigPaths = append(project.ConfigPaths, tmpfile.Name())
+			project.ConfigPaths = append(project.ConfigPaths, path)
 		default:
 			path, err := value.ValueToAbsPath(thread, val)
 			if err != nil {
-				return starlark.None, fmt.Errorf("expected blob | path (string). Actual type: %T", val)
+				return starlark.None, fmt.Errorf("expected blob | dict | path (string). Actual type: %T", val)
 			}
 
 			// Set project path to dir of first compose file, like DC CLI does
@@ -139,6 +144,23 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 		project.ProjectPath = filepath.Dir(currentTiltfilePath)
 	}
 
+	// Docker Compose reads the env file (or the .env file in the project
+	// directory, if there isn't one) to interpolate variables in the config,
+	// so reload when it changes.
+	envFilePath := project.EnvFile
+	if envFilePath == "" {
+		defaultEnvFile := filepath.Join(project.ProjectPath, ".env")
+		if _, err := os.Stat(defaultEnvFile); err == nil {
+			envFilePath = defaultEnvFile
+		}
+	}
+	if envFilePath != "" {
+		err = io.RecordReadPath(thread, io.WatchFileOnly, envFilePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	services, err := parseDCConfig(s.ctx, s.dcCli, project)
 	if err != nil {
 		return nil, err
@@ -176,6 +198,29 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 	return starlark.None, nil
 }
 
+// Docker Compose only loads config from files, so write the
+// YAML to a temp file named after its contents.
+func (s *tiltfileState) writeDCYAML(yaml string) (string, error) {
+	tmpdir, err := s.tempDir()
+	if err != nil {
+		return "", err
+	}
+	tmpfile, err := os.Create(filepath.Join(tmpdir.Path(), fmt.Sprintf("%x.yml", sha256.Sum256([]byte(yaml)))))
+	if err != nil {
+		return "", err
+	}
+	_, err = tmpfile.WriteString(yaml)
+	if err != nil {
+		tmpfile.Close()
+		return "", err
+	}
+	err = tmpfile.Close()
+	if err != nil {
+		return "", err
+	}
+	return tmpfile.Name(), nil
+}
+
 // DCResource allows you to adjust specific settings on a DC resource that we assume
 // to be defined in a `docker_compose.yml`
 func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -186,6 +231,7 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 	var links links.LinkList
 	var labels value.LabelSet
 	var autoInit = value.Optional[starlark.Bool]{Value: true}
+	var replicas value.Optional[starlark.Int]
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"name", &name,
@@ -197,6 +243,7 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 		"links?", &links,
 		"labels?", &labels,
 		"auto_init?", &autoInit,
+		"replicas?", &replicas,
 	); err != nil {
 		return nil, err
 	}
@@ -253,6 +300,14 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 		options.AutoInit = autoInit
 	}
 
+	if replicas.IsSet {
+		n, ok := replicas.Value.Int64()
+		if !ok || n < 1 {
+			return nil, fmt.Errorf("%s: replicas must be at least 1, got %s", fn.Name(), replicas.Value)
+		}
+		options.Replicas = int32(n)
+	}
+
 	s.dcResOptions[name] = options
 	svc.Options = options
 	return starlark.None, nil
@@ -303,6 +358,9 @@ type dcResourceOptions struct {
 	Labels map[string]string
 
 	resourceDeps []string
+
+	// The number of containers to run, or 0 to use the compose config.
+	Replicas int32
 }
 
 func newDcResourceOptions() *dcResourceOptions {
@@ -341,8 +399,7 @@ func dockerComposeConfigToService(projectName string, svcConfig types.ServiceCon
 
 	imageName := svcConfig.Image
 	if imageName == "" {
-		// see https://github.com/docker/compose/blob/7b84f2c2a538a1241dcf65f4b2828232189ef0ad/pkg/compose/create.go#L221-L227
-		imageName = fmt.Sprintf("%s_%s", projectName, svcConfig.Name)
+		imageName = dockercompose.ImageName(projectName, svcConfig.Name)
 	}
 
 	imageRef, err := container.ParseNamed(imageName)
@@ -364,6 +421,28 @@ func dockerComposeConfigToService(projectName string, svcConfig types.ServiceCon
 	return svc, nil
 }
 
+// The services in the set that this service waits on with a
+// depends_on condition other than service_started, in a stable order.
+func dcConditionalDeps(svcConfig types.ServiceConfig, dcSet dcResourceSet) []string {
+	inSet := map[string]bool{}
+	for _, svc := range dcSet.services {
+		inSet[svc.Name] = true
+	}
+
+	var result []string
+	for name, dep := range svcConfig.DependsOn {
+		if !inSet[name] {
+			continue
+		}
+		if dep.Condition == types.ServiceConditionHealthy ||
+			dep.Condition == types.ServiceConditionCompletedSuccessfully {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 func parseDCConfig(ctx context.Context, dcc dockercompose.DockerComposeClient, spec v1alpha1.DockerComposeProject) ([]*dcService, error) {
 	proj, err := dcc.Project(ctx, spec)
 	if err != nil {
@@ -395,8 +474,9 @@ func (s *tiltfileState) dcServiceToManifest(service *dcService, dcSet dcResource
 	dcInfo := model.DockerComposeTarget{
 		Name: model.TargetName(service.Name),
 		Spec: v1alpha1.DockerComposeServiceSpec{
-			Service: service.Name,
-			Project: dcSet.Project,
+			Service:  service.Name,
+			Project:  dcSet.Project,
+			Replicas: options.Replicas,
 		},
 		ServiceYAML: string(service.ServiceYAML),
 		Links:       options.Links,
@@ -413,10 +493,26 @@ func (s *tiltfileState) dcServiceToManifest(service *dcService, dcSet dcResource
 	}
 
 	var mds []model.ManifestName
+	seen := map[string]bool{}
 	for _, md := range options.resourceDeps {
+		if seen[md] {
+			continue
+		}
+		seen[md] = true
 		mds = append(mds, model.ManifestName(md))
 	}
 
+	// A service that waits for a dependency to be healthy (or to finish)
+	// depends on that dependency's resource, so that Tilt doesn't bring
+	// it up until the dependency is ready.
+	for _, dep := range dcConditionalDeps(service.ServiceConfig, dcSet) {
+		if seen[dep] {
+			continue
+		}
+		seen[dep] = true
+		mds = append(mds, model.ManifestName(dep))
+	}
+
 	for i, iTarget := range iTargets {
 		if liveupdate.IsEmptySpec(iTarget.LiveUpdateSpec) {
 			continue
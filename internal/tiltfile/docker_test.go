@@ -8,8 +8,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tilt-dev/tilt/internal/docker/buildkit"
 	"github.com/tilt-dev/tilt/internal/testutils"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 func TestDockerignoreInSyncDir(t *testing.T) {
@@ -131,6 +133,27 @@ func TestCustomPlatform(t *testing.T) {
 	}
 }
 
+func TestDockerBuildMultiPlatform(t *testing.T) {
+	testutils.Unsetenv(t, dockerPlatformEnv)
+
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")), deployment("be", image("gcr.io/be")))
+	f.file("Dockerfile", `FROM alpine`)
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.', platform=['linux/amd64', 'linux/arm64'])
+docker_build('gcr.io/be', '.', platform='linux/amd64,linux/arm64')
+`)
+
+	f.load()
+	for _, name := range []model.ManifestName{"fe", "be"} {
+		spec := f.assertNextManifest(name).ImageTargetAt(0).DockerBuildInfo()
+		assert.Equal(t, "", spec.Platform)
+		assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, spec.Platforms)
+	}
+}
+
 func TestCustomBuildDepsAreLocalRepos(t *testing.T) {
 	f := newFixture(t)
 
@@ -204,3 +227,53 @@ custom_build('gcr.io/fe', 'export MY_REF="gcr.io/fe:dev" && docker build -t $MY_
 
 	f.loadErrString("Cannot specify both tag= and outputs_image_ref_to=")
 }
+
+func TestDockerBuildCacheSpecs(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("Dockerfile", `FROM alpine`)
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.',
+             cache_from=['gcr.io/fe-cache', 'type=local,src=.cache'],
+             cache_to='type=local,dest=.cache,mode=max')
+`)
+
+	f.load()
+	spec := f.assertNextManifest("fe").ImageTargetAt(0).DockerBuildInfo()
+	assert.Equal(t, []string{"gcr.io/fe-cache", "type=local,src=" + f.JoinPath(".cache")}, spec.CacheFrom)
+	assert.Equal(t, []string{"type=local,dest=" + f.JoinPath(".cache") + ",mode=max"}, spec.CacheTo)
+}
+
+func TestDockerBuildCacheSpecQuoting(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("Dockerfile", `FROM alpine`)
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.', cache_to='type=local,"dest=my,cache",mode=max')
+`)
+
+	f.load()
+	spec := f.assertNextManifest("fe").ImageTargetAt(0).DockerBuildInfo()
+	assert.Equal(t, []string{`type=local,"dest=` + f.JoinPath("my,cache") + `",mode=max`}, spec.CacheTo)
+
+	entries, err := buildkit.ParseCacheSpecs(spec.CacheTo)
+	require.NoError(t, err)
+	assert.Equal(t, f.JoinPath("my,cache"), entries[0].Attrs["dest"])
+}
+
+func TestDockerBuildBadCacheSpec(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("Dockerfile", `FROM alpine`)
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.', cache_to='type=s3,bucket=foo')
+`)
+
+	f.loadErrString(`Argument cache_to: unsupported cache type "s3"`)
+}
@@ -0,0 +1,85 @@
+package encoding
+
+import (
+	"os"
+	"sort"
+
+	"github.com/compose-spec/compose-go/dotenv"
+	"go.starlark.net/starlark"
+
+	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// reads a dotenv file into a dict
+func readDotenv(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	path := value.NewLocalPathUnpacker(thread)
+	var defaultValue starlark.Value
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "paths", &path, "default?", &defaultValue); err != nil {
+		return nil, err
+	}
+
+	env, err := ReadDotenv(thread, path.Value)
+	if err != nil {
+		// Return the default value if the file doesn't exist AND a default value was given
+		if os.IsNotExist(err) && defaultValue != nil {
+			return defaultValue, nil
+		}
+		return nil, err
+	}
+
+	return dotenvToStarlark(env)
+}
+
+// reads dotenv from a string
+func decodeDotenv(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var contents value.Stringable
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "dotenv", &contents); err != nil {
+		return nil, err
+	}
+
+	env, err := parseDotenv(contents.Value, "")
+	if err != nil {
+		return nil, err
+	}
+	return dotenvToStarlark(env)
+}
+
+// ReadDotenv reads the environment variables in a dotenv file,
+// and watches the file for changes.
+//
+// Uses the same syntax as Docker Compose env files. Variable references
+// like ${FOO} are expanded from variables earlier in the same file.
+func ReadDotenv(thread *starlark.Thread, localPath string) (map[string]string, error) {
+	contents, err := tiltfile_io.ReadFile(thread, localPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseDotenv(string(contents), localPath)
+}
+
+func parseDotenv(s string, source string) (map[string]string, error) {
+	env, err := dotenv.UnmarshalBytes([]byte(s))
+	if err != nil {
+		return nil, wrapError(err, "error parsing dotenv", source)
+	}
+	return env, nil
+}
+
+func dotenvToStarlark(env map[string]string) (starlark.Value, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := starlark.NewDict(len(env))
+	for _, k := range keys {
+		err := result.SetKey(starlark.String(k), starlark.String(env[k]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package encoding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
+)
+
+func TestReadDotenv(t *testing.T) {
+	f := newFixture(t)
+
+	f.File(".env", `
+# ports
+PORT=8080
+export HOST=localhost
+URL="http://${HOST}:${PORT}"
+FEATURE_FLAGS='a,b'
+`)
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = read_dotenv(".env")
+assert.equals({
+  'PORT': '8080',
+  'HOST': 'localhost',
+  'URL': 'http://localhost:8080',
+  'FEATURE_FLAGS': 'a,b',
+}, observed)
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+
+	rs, err := io.GetState(result)
+	require.NoError(t, err)
+	require.Contains(t, rs.Paths, f.JoinPath(".env"))
+}
+
+func TestReadDotenvDefaultValue(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = read_dotenv(".env", default={})
+assert.equals({}, observed)
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+}
+
+func TestDecodeDotenv(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = decode_dotenv('A=1\nB="two words"\n')
+assert.equals({'A': '1', 'B': 'two words'}, observed)
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+}
+
+func TestMalformedDotenv(t *testing.T) {
+	f := newFixture(t)
+
+	f.File(".env", "NOT A VARIABLE\n")
+	f.File("Tiltfile", `read_dotenv(".env")`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error parsing dotenv from")
+}
@@ -0,0 +1,152 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+	"gopkg.in/ini.v1"
+
+	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// Comments start with # or ;, but only at the start of a line or after
+// whitespace, so that values like URLs with fragments survive.
+var iniLoadOptions = ini.LoadOptions{SpaceBeforeInlineComment: true}
+
+// reads ini from a file
+func readINI(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	path := value.NewLocalPathUnpacker(thread)
+	var defaultValue starlark.Value
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "paths", &path, "default?", &defaultValue); err != nil {
+		return nil, err
+	}
+
+	localPath := path.Value
+	contents, err := tiltfile_io.ReadFile(thread, localPath)
+	if err != nil {
+		// Return the default value if the file doesn't exist AND a default value was given
+		if os.IsNotExist(err) && defaultValue != nil {
+			return defaultValue, nil
+		}
+		return nil, err
+	}
+
+	return iniStringToStarlark(string(contents), localPath)
+}
+
+// reads ini from a string
+func decodeINI(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var contents value.Stringable
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "ini", &contents); err != nil {
+		return nil, err
+	}
+
+	return iniStringToStarlark(contents.Value, "")
+}
+
+// INI files are untyped, so every value is a string.
+//
+// Keys that come before the first section header go in the DEFAULT section,
+// like in Python's configparser.
+func iniStringToStarlark(s string, source string) (starlark.Value, error) {
+	f, err := ini.LoadSources(iniLoadOptions, []byte(s))
+	if err != nil {
+		return nil, wrapError(err, "error parsing INI", source)
+	}
+
+	result := starlark.NewDict(len(f.Sections()))
+	for _, section := range f.Sections() {
+		if section.Name() == ini.DefaultSection && len(section.Keys()) == 0 {
+			continue
+		}
+
+		keys := starlark.NewDict(len(section.Keys()))
+		for _, key := range section.Keys() {
+			err := keys.SetKey(starlark.String(key.Name()), starlark.String(key.Value()))
+			if err != nil {
+				return nil, err
+			}
+		}
+		err := result.SetKey(starlark.String(section.Name()), keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// dumps ini to a string
+func encodeINI(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var obj *starlark.Dict
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "obj", &obj); err != nil {
+		return nil, err
+	}
+
+	ret, err := starlarkToINIString(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return tiltfile_io.NewBlob(ret, "encode_ini"), nil
+}
+
+// Sections and keys are written in the same order as the dicts.
+func starlarkToINIString(obj *starlark.Dict) (string, error) {
+	f := ini.Empty(iniLoadOptions)
+	for _, item := range obj.Items() {
+		sectionName, ok := starlark.AsString(item[0])
+		if !ok {
+			return "", fmt.Errorf("error serializing object to ini: section names must be strings, got %s", item[0].Type())
+		}
+		keys, ok := item[1].(*starlark.Dict)
+		if !ok {
+			return "", fmt.Errorf("error serializing object to ini: section %q must be a dict, got %s", sectionName, item[1].Type())
+		}
+
+		section, err := f.NewSection(sectionName)
+		if err != nil {
+			return "", errors.Wrap(err, "error serializing object to ini")
+		}
+		for _, kv := range keys.Items() {
+			k, ok := starlark.AsString(kv[0])
+			if !ok {
+				return "", fmt.Errorf("error serializing object to ini: keys in section %q must be strings, got %s", sectionName, kv[0].Type())
+			}
+			v, err := iniValue(kv[1])
+			if err != nil {
+				return "", fmt.Errorf("error serializing object to ini: %s.%s: %v", sectionName, k, err)
+			}
+			_, err = section.NewKey(k, v)
+			if err != nil {
+				return "", errors.Wrap(err, "error serializing object to ini")
+			}
+		}
+	}
+
+	w := bytes.Buffer{}
+	_, err := f.WriteTo(&w)
+	if err != nil {
+		return "", errors.Wrap(err, "error serializing object to ini")
+	}
+	return w.String(), nil
+}
+
+func iniValue(v starlark.Value) (string, error) {
+	switch v := v.(type) {
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int, starlark.Float:
+		return v.String(), nil
+	case starlark.Bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	return "", fmt.Errorf("INI values must be strings, numbers, or bools, got %s", v.Type())
+}
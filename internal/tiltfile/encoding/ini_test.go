@@ -0,0 +1,140 @@
+package encoding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
+)
+
+func TestReadINI(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("setup.cfg", `
+name = api
+
+[server]
+port = 8080
+debug = true
+url = http://localhost:8080/#/home ; the web UI
+
+# comments are ignored
+[metadata]
+version = 0.1.0
+`)
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = read_ini("setup.cfg")
+assert.equals({
+  'DEFAULT': {'name': 'api'},
+  'server': {'port': '8080', 'debug': 'true', 'url': 'http://localhost:8080/#/home'},
+  'metadata': {'version': '0.1.0'},
+}, observed)
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+
+	rs, err := io.GetState(result)
+	require.NoError(t, err)
+	require.Contains(t, rs.Paths, f.JoinPath("setup.cfg"))
+}
+
+func TestReadINIDefaultValue(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = read_ini("dne.ini", default={'server': {'port': '8080'}})
+assert.equals({'server': {'port': '8080'}}, observed)
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+}
+
+func TestINIDoesNotExist(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `result = read_ini("dne.ini")`)
+	result, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "dne.ini")
+	testutils.AssertIsNotExist(t, err)
+
+	rs, err := io.GetState(result)
+	require.NoError(t, err)
+	require.Contains(t, rs.Paths, f.JoinPath("dne.ini"))
+}
+
+func TestMalformedINI(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("tox.ini", `
+[tox
+envlist = py39
+`)
+	f.File("Tiltfile", `result = read_ini("tox.ini")`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error parsing INI from")
+	require.Contains(t, err.Error(), "tox.ini")
+}
+
+func TestDecodeINI(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = decode_ini(blob('[a]\nb = c\n'))
+assert.equals({'a': {'b': 'c'}}, observed)
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+}
+
+func TestEncodeINI(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = encode_ini({
+  'server': {'port': 8080, 'debug': True, 'host': 'localhost'},
+  'metadata': {'version': '0.1.0'},
+})
+assert.equals('[server]\nport  = 8080\ndebug = true\nhost  = localhost\n\n[metadata]\nversion = 0.1.0\n\n', str(observed))
+assert.equals({
+  'server': {'port': '8080', 'debug': 'true', 'host': 'localhost'},
+  'metadata': {'version': '0.1.0'},
+}, decode_ini(observed))
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+}
+
+func TestEncodeININested(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `encode_ini({'server': {'ports': [8080]}})`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "server.ports: INI values must be strings, numbers, or bools, got list")
+}
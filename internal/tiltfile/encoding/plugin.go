@@ -19,6 +19,17 @@ const (
 	readJSONN   = "read_json"
 	decodeJSONN = "decode_json"
 	encodeJSONN = "encode_json"
+
+	readTOMLN   = "read_toml"
+	decodeTOMLN = "decode_toml"
+	encodeTOMLN = "encode_toml"
+
+	readININ   = "read_ini"
+	decodeININ = "decode_ini"
+	encodeININ = "encode_ini"
+
+	readDotenvN   = "read_dotenv"
+	decodeDotenvN = "decode_dotenv"
 )
 
 func (Plugin) OnStart(env *starkit.Environment) error {
@@ -36,6 +47,17 @@ func (Plugin) OnStart(env *starkit.Environment) error {
 		{readJSONN, readJSON},
 		{decodeJSONN, decodeJSON},
 		{encodeJSONN, encodeJSON},
+
+		{readTOMLN, readTOML},
+		{decodeTOMLN, decodeTOML},
+		{encodeTOMLN, encodeTOML},
+
+		{readININ, readINI},
+		{decodeININ, decodeINI},
+		{encodeININ, encodeINI},
+
+		{readDotenvN, readDotenv},
+		{decodeDotenvN, decodeDotenv},
 	} {
 		err := env.AddBuiltin(b.name, b.f)
 		if err != nil {
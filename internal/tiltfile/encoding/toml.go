@@ -0,0 +1,156 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+
+	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// reads toml from a file
+func readTOML(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	path := value.NewLocalPathUnpacker(thread)
+	var defaultValue starlark.Value
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "paths", &path, "default?", &defaultValue); err != nil {
+		return nil, err
+	}
+
+	localPath := path.Value
+	contents, err := tiltfile_io.ReadFile(thread, localPath)
+	if err != nil {
+		// Return the default value if the file doesn't exist AND a default value was given
+		if os.IsNotExist(err) && defaultValue != nil {
+			return defaultValue, nil
+		}
+		return nil, err
+	}
+
+	return tomlStringToStarlark(string(contents), localPath)
+}
+
+// reads toml from a string
+func decodeTOML(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var contents value.Stringable
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "toml", &contents); err != nil {
+		return nil, err
+	}
+
+	return tomlStringToStarlark(contents.Value, "")
+}
+
+func tomlStringToStarlark(s string, source string) (starlark.Value, error) {
+	var decodedTOML map[string]interface{}
+	err := toml.Unmarshal([]byte(s), &decodedTOML)
+	if err != nil {
+		var derr *toml.DecodeError
+		if errors.As(err, &derr) {
+			row, col := derr.Position()
+			err = fmt.Errorf("line %d, column %d: %v", row, col, derr)
+		}
+		return nil, wrapError(err, "error parsing TOML", source)
+	}
+
+	v, err := ConvertStructuredDataToStarlark(normalizeTOMLValue(decodedTOML))
+	if err != nil {
+		return nil, wrapError(err, "error converting TOML to Starlark", source)
+	}
+	return v, nil
+}
+
+// Starlark has no date or time types, so we represent TOML dates and times
+// as strings in the same format they were written in.
+func normalizeTOMLValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeTOMLValue(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeTOMLValue(e)
+		}
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case toml.LocalDate:
+		return v.String()
+	case toml.LocalTime:
+		return v.String()
+	case toml.LocalDateTime:
+		return v.String()
+	}
+	return v
+}
+
+// dumps toml to a string
+func encodeTOML(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var obj *starlark.Dict
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "obj", &obj); err != nil {
+		return nil, err
+	}
+
+	ret, err := starlarkToTOMLString(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return tiltfile_io.NewBlob(ret, "encode_toml"), nil
+}
+
+func starlarkToTOMLString(obj starlark.Value) (string, error) {
+	v, err := ConvertStarlarkToStructuredData(obj)
+	if err != nil {
+		return "", errors.Wrap(err, "error converting object from starlark")
+	}
+
+	if path := findNil(v, ""); path != "" {
+		return "", fmt.Errorf("error serializing object to toml: TOML has no null value, but found None at %s", path)
+	}
+
+	w := bytes.Buffer{}
+	e := toml.NewEncoder(&w)
+	e.SetIndentTables(true)
+	err = e.Encode(v)
+	if err != nil {
+		return "", errors.Wrap(err, "error serializing object to toml")
+	}
+
+	return w.String(), nil
+}
+
+// Returns the path to the first nil value in v, or "" if there isn't one.
+func findNil(v interface{}, path string) string {
+	switch v := v.(type) {
+	case nil:
+		if path == "" {
+			return "<root>"
+		}
+		return path
+	case map[string]interface{}:
+		for k, e := range v {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if found := findNil(e, p); found != "" {
+				return found
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			if found := findNil(e, fmt.Sprintf("%s[%d]", path, i)); found != "" {
+				return found
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package encoding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
+)
+
+func TestReadTOML(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("pyproject.toml", `
+[tool.poetry]
+name = "api"
+version = "0.1.0"
+
+[tool.api]
+port = 8080
+debug = true
+ratio = 0.5
+released = 2022-04-01
+hosts = ["localhost", "127.0.0.1"]
+`)
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = read_toml("pyproject.toml")
+assert.equals({
+  'tool': {
+    'poetry': {'name': 'api', 'version': '0.1.0'},
+    'api': {
+      'port': 8080,
+      'debug': True,
+      'ratio': 0.5,
+      'released': '2022-04-01',
+      'hosts': ['localhost', '127.0.0.1'],
+    },
+  },
+}, observed)
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+
+	rs, err := io.GetState(result)
+	require.NoError(t, err)
+	require.Contains(t, rs.Paths, f.JoinPath("pyproject.toml"))
+}
+
+func TestReadTOMLDefaultValue(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = read_toml("dne.toml", default={'port': 8080})
+assert.equals({'port': 8080}, observed)
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+}
+
+func TestTOMLDoesNotExist(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `result = read_toml("dne.toml")`)
+	result, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "dne.toml")
+	testutils.AssertIsNotExist(t, err)
+
+	rs, err := io.GetState(result)
+	require.NoError(t, err)
+	require.Contains(t, rs.Paths, f.JoinPath("dne.toml"))
+}
+
+func TestMalformedTOML(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Cargo.toml", `
+[package]
+name = "api
+`)
+	f.File("Tiltfile", `result = read_toml("Cargo.toml")`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error parsing TOML from")
+	require.Contains(t, err.Error(), "Cargo.toml: line 3")
+}
+
+func TestDecodeTOML(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+observed = decode_toml(blob('a = 1\n[b]\nc = "d"\n'))
+assert.equals({'a': 1, 'b': {'c': 'd'}}, observed)
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+}
+
+func TestEncodeTOML(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+obj = {
+  'name': 'api',
+  'ports': [8080, 8081],
+  'server': {'debug': True},
+}
+observed = encode_toml(obj)
+assert.equals(obj, decode_toml(observed))
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if err != nil {
+		fmt.Println(f.PrintOutput())
+	}
+	require.NoError(t, err)
+}
+
+func TestEncodeTOMLNone(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `encode_toml({'server': {'port': None}})`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "TOML has no null value, but found None at server.port")
+}
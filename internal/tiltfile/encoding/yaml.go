@@ -173,7 +173,7 @@ func encodeYAMLStream(thread *starlark.Thread, fn *starlark.Builtin, args starla
 }
 
 func starlarkToYAMLString(obj starlark.Value) (string, error) {
-	v, err := convertStarlarkToStructuredData(obj)
+	v, err := ConvertStarlarkToStructuredData(obj)
 	if err != nil {
 		return "", errors.Wrap(err, "error converting object from starlark")
 	}
@@ -2,6 +2,7 @@ package tiltfile
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -9,8 +10,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/tiltfile/hasher"
 	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
@@ -40,8 +43,10 @@ func (s *tiltfileState) local(thread *starlark.Thread, fn *starlark.Builtin, arg
 	var commandValue, commandBatValue, commandDirValue starlark.Value
 	var commandEnv value.StringStringMap
 	var stdin value.Stringable
+	cacheInputs := value.NewLocalPathListUnpacker(thread)
 	quiet := false
 	echoOff := false
+	async := false
 	err := s.unpackArgs(fn.Name(), args, kwargs,
 		"command", &commandValue,
 		"quiet?", &quiet,
@@ -50,6 +55,8 @@ func (s *tiltfileState) local(thread *starlark.Thread, fn *starlark.Builtin, arg
 		"env", &commandEnv,
 		"dir?", &commandDirValue,
 		"stdin?", &stdin,
+		"cache_inputs?", &cacheInputs,
+		"async?", &async,
 	)
 	if err != nil {
 		return nil, err
@@ -69,32 +76,114 @@ func (s *tiltfileState) local(thread *starlark.Thread, fn *starlark.Builtin, arg
 		s := stdin.Value
 		execOptions.stdin = &s
 	}
-	out, err := s.execLocalCmd(thread, cmd, execOptions)
+	inputs := cacheInputs.Value
+	return s.renderBlob(thread, fn, async, fmt.Sprintf("exec: %s", cmd), fmt.Sprintf("local: %s", cmd),
+		func(ctx context.Context, l logger.Logger) (string, error) {
+			if inputs != nil {
+				return s.runCachedLocalCmd(ctx, l, cmd, inputs, execOptions)
+			}
+			return s.runLocalCmd(ctx, l, cmd, execOptions)
+		})
+}
+
+// Runs render, which shells out to produce some text, and returns the text
+// as a blob.
+//
+// With async, render runs in the background, and we return a lazy blob
+// instead. Its logs are held back until the blob is used, so that output
+// from concurrent commands doesn't interleave.
+//
+// render runs off the starlark thread, so it must not use it.
+func (s *tiltfileState) renderBlob(t *starlark.Thread, fn *starlark.Builtin, async bool, span string, source string,
+	render func(ctx context.Context, l logger.Logger) (string, error)) (starlark.Value, error) {
+	ctx, err := starkit.ContextFromThread(t)
 	if err != nil {
 		return nil, err
 	}
 
-	return tiltfile_io.NewBlob(out, fmt.Sprintf("local: %s", cmd)), nil
+	if !async {
+		endSpan := starkit.StartProfileSpan(t, span)
+		text, err := render(ctx, s.logger)
+		endSpan()
+		if err != nil {
+			return nil, err
+		}
+		return tiltfile_io.NewBlob(text, source), nil
+	}
+
+	l := logger.NewDeferredLogger(ctx)
+	future := starkit.Go(t, func() (starlark.Value, error) {
+		text, err := render(ctx, l)
+		if err != nil {
+			return nil, fmt.Errorf("%s(async=True): %v", fn.Name(), err)
+		}
+		return tiltfile_io.NewBlob(text, source), nil
+	}, func() {
+		l.SetOutput(s.logger)
+	})
+	return tiltfile_io.NewLazyBlob(future), nil
 }
 
-func (s *tiltfileState) execLocalCmd(t *starlark.Thread, cmd model.Cmd, options execCommandOptions) (string, error) {
-	var stdoutBuf, stderrBuf bytes.Buffer
-	ctx, err := starkit.ContextFromThread(t)
+// Runs the command only if its inputs changed since the last time it ran
+// successfully. Otherwise, returns the stdout from that run.
+func (s *tiltfileState) runCachedLocalCmd(ctx context.Context, l logger.Logger, cmd model.Cmd, inputs []string, options execCommandOptions) (string, error) {
+	key, err := localCacheKey(cmd, options.stdin)
 	if err != nil {
 		return "", err
 	}
 
+	inputsHash, err := hasher.HashPaths(inputs)
+	if err != nil {
+		return "", errors.Wrap(err, "hashing cache_inputs")
+	}
+
+	cache := localCache{base: s.base}
+	stdout, ok, err := cache.get(key, inputsHash)
+	if err != nil {
+		l.Debugf("local: reading cache for %s: %v", cmd, err)
+	}
+	if ok {
+		if options.logCommand {
+			l.Infof("%s %s [cached]", options.logCommandPrefix, cmd)
+		}
+		if options.logOutput && stdout != "" {
+			_, _ = logger.NewPrefixedLogger(localLogPrefix, l).Writer(logger.InfoLvl).Write([]byte(stdout))
+		}
+		return stdout, nil
+	}
+
+	stdout, err = s.runLocalCmd(ctx, l, cmd, options)
+	if err != nil {
+		return "", err
+	}
+
+	// Hash again, because commands like `helm dependency build` write
+	// into their own inputs.
+	inputsHash, err = hasher.HashPaths(inputs)
+	if err == nil {
+		err = cache.put(key, localCacheEntry{InputsSHA256: inputsHash, Stdout: stdout})
+	}
+	if err != nil {
+		// The cache only exists to make later loads faster.
+		l.Debugf("local: caching %s: %v", cmd, err)
+	}
+	return stdout, nil
+}
+
+func (s *tiltfileState) runLocalCmd(ctx context.Context, l logger.Logger, cmd model.Cmd, options execCommandOptions) (string, error) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+
 	if options.logCommand {
 		prefix := options.logCommandPrefix
 		if prefix == "" {
 			prefix = "Running:"
 		}
-		s.logger.Infof("%s %s", prefix, cmd)
+		l.Infof("%s %s", prefix, cmd)
 	}
 
 	var runIO localexec.RunIO
 	if options.logOutput {
-		logOutput := logger.NewMutexWriter(logger.NewPrefixedLogger(localLogPrefix, s.logger).Writer(logger.InfoLvl))
+		logOutput := logger.NewMutexWriter(logger.NewPrefixedLogger(localLogPrefix, l).Writer(logger.InfoLvl))
 		runIO.Stdout = io.MultiWriter(&stdoutBuf, logOutput)
 		runIO.Stderr = io.MultiWriter(&stderrBuf, logOutput)
 	} else {
@@ -130,32 +219,28 @@ func (s *tiltfileState) execLocalCmd(t *starlark.Thread, cmd model.Cmd, options
 	// only show that there was no output if the command was echoed AND we wanted output logged
 	// otherwise, it's confusing to get "[no output]" without context of _what_ didn't have output
 	if options.logCommand && options.logOutput && stdoutBuf.Len() == 0 && stderrBuf.Len() == 0 {
-		s.logger.Infof("%s[no output]", localLogPrefix)
+		l.Infof("%s[no output]", localLogPrefix)
 	}
 
 	return stdoutBuf.String(), nil
 }
 
+// Remote bases, and charts rendered for kustomizations, are cached under the xdg cache dir.
+const kustomizeCacheDir = "tiltfile/kustomize"
+
 func (s *tiltfileState) kustomize(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	path, kustomizeBin := value.NewLocalPathUnpacker(thread), value.NewLocalPathUnpacker(thread)
-	err := s.unpackArgs(fn.Name(), args, kwargs, "paths", &path, "kustomize_bin?", &kustomizeBin)
+	async := false
+	err := s.unpackArgs(fn.Name(), args, kwargs, "paths", &path, "kustomize_bin?", &kustomizeBin, "async?", &async)
 	if err != nil {
 		return nil, err
 	}
 
-	kustomizeArgs := []string{"kustomize", "build"}
-
 	if kustomizeBin.Value != "" {
-		kustomizeArgs[0] = kustomizeBin.Value
-	}
-
-	_, err = exec.LookPath(kustomizeArgs[0])
-	if err != nil {
-		if kustomizeBin.Value != "" {
+		_, err = exec.LookPath(kustomizeBin.Value)
+		if err != nil {
 			return nil, err
 		}
-		s.logger.Infof("Falling back to `kubectl kustomize` since `%s` was not found in PATH", kustomizeArgs[0])
-		kustomizeArgs = []string{"kubectl", "kustomize"}
 	}
 
 	// NOTE(nick): There's a bug in kustomize where it doesn't properly
@@ -166,18 +251,10 @@ func (s *tiltfileState) kustomize(thread *starlark.Thread, fn *starlark.Builtin,
 		return nil, err
 	}
 
-	cmd := model.Cmd{Argv: append(kustomizeArgs, relKustomizePath), Dir: starkit.AbsWorkingDir(thread)}
-	yaml, err := s.execLocalCmd(thread, cmd, execCommandOptions{
-		logOutput:  false,
-		logCommand: false,
-	})
-	if err != nil {
-		return nil, err
-	}
-	deps, err := kustomize.Deps(path.Value)
-	if err != nil {
-		return nil, fmt.Errorf("resolving deps: %v", err)
-	}
+	// Watch the deps now, because we can't touch the thread once the
+	// command is running in the background. But report kustomize's own
+	// error first if it has one, because it's more helpful.
+	deps, depsErr := kustomize.Deps(path.Value)
 	for _, d := range deps {
 		err := tiltfile_io.RecordReadPath(thread, tiltfile_io.WatchRecursive, d)
 		if err != nil {
@@ -185,171 +262,229 @@ func (s *tiltfileState) kustomize(thread *starlark.Thread, fn *starlark.Builtin,
 		}
 	}
 
-	return tiltfile_io.NewBlob(yaml, fmt.Sprintf("kustomize: %s", path.Value)), nil
+	if kustomizeBin.Value == "" {
+		builder, err := s.kustomizeBuilder()
+		if err != nil {
+			return nil, err
+		}
+		return s.renderBlob(thread, fn, async, fmt.Sprintf("kustomize build %s", relKustomizePath), fmt.Sprintf("kustomize: %s", path.Value),
+			func(ctx context.Context, l logger.Logger) (string, error) {
+				yaml, err := builder.Build(ctx, path.Value)
+				if err != nil {
+					return "", err
+				}
+				if depsErr != nil {
+					return "", fmt.Errorf("resolving deps: %v", depsErr)
+				}
+				return yaml, nil
+			})
+	}
+
+	cmd := model.Cmd{Argv: []string{kustomizeBin.Value, "build", relKustomizePath}, Dir: starkit.AbsWorkingDir(thread)}
+	return s.renderBlob(thread, fn, async, fmt.Sprintf("exec: %s", cmd), fmt.Sprintf("kustomize: %s", path.Value),
+		func(ctx context.Context, l logger.Logger) (string, error) {
+			yaml, err := s.runLocalCmd(ctx, l, cmd, execCommandOptions{
+				logOutput:  false,
+				logCommand: false,
+			})
+			if err != nil {
+				return "", err
+			}
+			if depsErr != nil {
+				return "", fmt.Errorf("resolving deps: %v", depsErr)
+			}
+			return yaml, nil
+		})
 }
 
 func (s *tiltfileState) helm(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	path := value.NewLocalPathUnpacker(thread)
+	var chartValue starlark.Value
 	var name string
 	var namespace string
 	var valueFiles value.StringOrStringList
 	var set value.StringOrStringList
 	var kubeVersion string
+	async := false
 
 	err := s.unpackArgs(fn.Name(), args, kwargs,
-		"paths", &path,
+		"paths", &chartValue,
 		"name?", &name,
 		"namespace?", &namespace,
 		"values?", &valueFiles,
 		"set?", &set,
 		"kube_version?", &kubeVersion,
+		"async?", &async,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	localPath := path.Value
-	info, err := os.Stat(localPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("Could not read Helm chart directory %q: does not exist", localPath)
-		}
-		return nil, fmt.Errorf("Could not read Helm chart directory %q: %v", localPath, err)
-	} else if !info.IsDir() {
-		return nil, fmt.Errorf("helm() may only be called on directories with Chart.yaml: %q", localPath)
-	}
-
-	err = tiltfile_io.RecordReadPath(thread, tiltfile_io.WatchRecursive, localPath)
+	ref, err := helmChartRef(thread, fn.Name(), "paths", chartValue)
 	if err != nil {
 		return nil, err
 	}
 
-	deps, err := localSubchartDependenciesFromPath(localPath)
-	if err != nil {
-		return nil, err
+	if ref.Kind == helm.ChartRefLocal {
+		err := recordHelmChartReads(thread, ref.Name)
+		if err != nil {
+			return nil, err
+		}
 	}
-	for _, d := range deps {
-		err = tiltfile_io.RecordReadPath(thread, tiltfile_io.WatchRecursive, starkit.AbsPath(thread, d))
+
+	var absValueFiles []string
+	for _, valueFile := range valueFiles.Values {
+		absValueFile := starkit.AbsPath(thread, valueFile)
+		err := tiltfile_io.RecordReadPath(thread, tiltfile_io.WatchFileOnly, absValueFile)
 		if err != nil {
 			return nil, err
 		}
+		absValueFiles = append(absValueFiles, absValueFile)
 	}
 
-	version, err := getHelmVersion()
+	fetcher, err := s.helmChartFetcher()
 	if err != nil {
 		return nil, err
 	}
 
-	var cmd []string
+	return s.renderBlob(thread, fn, async, fmt.Sprintf("helm template %s", ref), fmt.Sprintf("helm: %s", ref.Name),
+		func(ctx context.Context, l logger.Logger) (string, error) {
+			if ref.Kind != helm.ChartRefLocal {
+				l.Debugf("helm: fetching %s", ref)
+			}
+			chartPath, err := fetcher.Fetch(ref)
+			if err != nil {
+				return "", err
+			}
 
-	if name == "" {
-		// Use 'chart' as the release name, so that the release name is stable
-		// across Tiltfile loads.
-		// This looks like what helm does.
-		// https://github.com/helm/helm/blob/e672a42efae30d45ddd642a26557dcdbf5a9f5f0/pkg/action/install.go#L562
-		name = "chart"
-	}
+			chart, err := helm.LoadChart(chartPath)
+			if err != nil {
+				return "", err
+			}
 
-	if version == helmV3_1andAbove {
-		cmd = []string{"helm", "template", name, localPath, "--include-crds"}
-	} else if version == helmV3_0 {
-		cmd = []string{"helm", "template", name, localPath}
-	} else {
-		cmd = []string{"helm", "template", localPath, "--name", name}
-	}
+			yaml, err := helm.Template(chart, helm.TemplateOptions{
+				ReleaseName: name,
+				Namespace:   namespace,
+				ValueFiles:  absValueFiles,
+				Set:         set.Values,
+				KubeVersion: kubeVersion,
+			})
+			if err != nil {
+				return "", err
+			}
 
-	if namespace != "" {
-		cmd = append(cmd, "--namespace", namespace)
+			yaml = filterHelmTestYAML(yaml)
+
+			if namespace != "" {
+				// Helm doesn't inject the namespace, nor provide YAML that
+				// defines the namespace, so we have to do both ourselves :\
+				// https://github.com/helm/helm/issues/5465
+				parsed, err := k8s.ParseYAMLFromString(yaml)
+				if err != nil {
+					return "", err
+				}
+
+				for i, e := range parsed {
+					parsed[i] = e.WithNamespace(e.NamespaceOrDefault(namespace))
+				}
+
+				yaml, err = k8s.SerializeSpecYAML(parsed)
+				if err != nil {
+					return "", err
+				}
+			}
+
+			return yaml, nil
+		})
+}
+
+// The chart argument to helm() and helm_release() is either a path to a local
+// chart (a string or a path from a repo), or a remote chart, like
+// oci://ghcr.io/org/charts/app or bitnami/nginx@13.2.0.
+func helmChartRef(t *starlark.Thread, fnName, param string, v starlark.Value) (helm.ChartRef, error) {
+	str, ok := starlark.AsString(v)
+	if ok {
+		// repo/chart refs look like relative paths, so a chart on disk wins.
+		_, err := os.Stat(starkit.AbsPath(t, str))
+		if os.IsNotExist(err) {
+			ref, ok := helm.ParseRemoteChartRef(str)
+			if ok {
+				return ref, nil
+			}
+		}
 	}
 
-	if kubeVersion != "" {
-		cmd = append(cmd, "--kube-version", kubeVersion)
+	localPath, err := value.ValueToAbsPath(t, v)
+	if err != nil {
+		return helm.ChartRef{}, fmt.Errorf("for parameter %q: %v", param, err)
 	}
 
-	for _, valueFile := range valueFiles.Values {
-		cmd = append(cmd, "--values", valueFile)
-		err := tiltfile_io.RecordReadPath(thread, tiltfile_io.WatchFileOnly, starkit.AbsPath(thread, valueFile))
-		if err != nil {
-			return nil, err
+	info, err := os.Stat(localPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return helm.ChartRef{}, fmt.Errorf("Could not read Helm chart directory %q: does not exist", localPath)
 		}
+		return helm.ChartRef{}, fmt.Errorf("Could not read Helm chart directory %q: %v", localPath, err)
+	} else if !info.IsDir() && !strings.HasSuffix(localPath, ".tgz") {
+		return helm.ChartRef{}, fmt.Errorf("%s() may only be called on directories with Chart.yaml, or packaged charts: %q", fnName, localPath)
 	}
-	for _, setArg := range set.Values {
-		cmd = append(cmd, "--set", setArg)
-	}
+	return helm.NewLocalChartRef(localPath), nil
+}
 
-	stdout, err := s.execLocalCmd(thread, model.Cmd{Argv: cmd, Dir: starkit.AbsWorkingDir(thread)}, execCommandOptions{
-		logOutput:  false,
-		logCommand: true,
-	})
+// Watches a local chart, including its values.yaml and its subcharts.
+func recordHelmChartReads(t *starlark.Thread, chartPath string) error {
+	err := tiltfile_io.RecordReadPath(t, tiltfile_io.WatchRecursive, chartPath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	yaml := filterHelmTestYAML(stdout)
+	info, err := os.Stat(chartPath)
+	if err != nil || !info.IsDir() {
+		return err
+	}
 
-	if version == helmV3_0 {
-		// Helm v3.0 has a bug where it doesn't include CRDs in the template output
-		// https://github.com/tilt-dev/tilt/issues/3605
-		crds, err := getHelmCRDs(localPath)
+	deps, err := localSubchartDependenciesFromPath(chartPath)
+	if err != nil {
+		return err
+	}
+	for _, d := range deps {
+		err = tiltfile_io.RecordReadPath(t, tiltfile_io.WatchRecursive, d)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		yaml = strings.Join(append([]string{yaml}, crds...), "\n---\n")
 	}
+	return nil
+}
 
-	if namespace != "" {
-		// helm template --namespace doesn't inject the namespace, nor provide
-		// YAML that defines the namespace, so we have to do both ourselves :\
-		// https://github.com/helm/helm/issues/5465
-		parsed, err := k8s.ParseYAMLFromString(yaml)
+func (s *tiltfileState) kustomizeBuilder() (*kustomize.Builder, error) {
+	if s.kustomizer == nil {
+		charts, err := s.helmChartFetcher()
 		if err != nil {
 			return nil, err
 		}
-
-		for i, e := range parsed {
-			parsed[i] = e.WithNamespace(e.NamespaceOrDefault(namespace))
-		}
-
-		yaml, err = k8s.SerializeSpecYAML(parsed)
+		dir, err := s.base.CacheFile(kustomizeCacheDir)
 		if err != nil {
 			return nil, err
 		}
+		if s.kustomizeGit != nil {
+			s.kustomizer = kustomize.NewBuilderWithGit(dir, charts, s.kustomizeGit)
+		} else {
+			s.kustomizer = kustomize.NewBuilder(dir, charts)
+		}
 	}
-
-	return tiltfile_io.NewBlob(yaml, fmt.Sprintf("helm: %s", localPath)), nil
+	return s.kustomizer, nil
 }
 
-// NOTE(nick): This isn't perfect. For example, it doesn't handle chart deps
-// properly. When possible, prefer Helm 3.1's --include-crds
-func getHelmCRDs(path string) ([]string, error) {
-	crdPath := filepath.Join(path, "crds")
-	result := []string{}
-	err := filepath.Walk(crdPath, func(path string, info os.FileInfo, err error) error {
+func (s *tiltfileState) helmChartFetcher() (*helm.ChartFetcher, error) {
+	if s.helmCharts == nil {
+		dir, err := s.base.CacheFile(helmChartCacheDir)
 		if err != nil {
-			return err
-		}
-		isYAML := info != nil && info.Mode().IsRegular() && hasYAMLExtension(path)
-		if !isYAML {
-			return nil
+			return nil, err
 		}
-		contents, err := os.ReadFile(path)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return nil
-			}
-			return err
+		if s.chartDownload != nil {
+			s.helmCharts = helm.NewChartFetcherWithDownload(dir, s.chartDownload)
+		} else {
+			s.helmCharts = helm.NewChartFetcher(dir)
 		}
-		result = append(result, string(contents))
-		return nil
-	})
-
-	if err != nil && !os.IsNotExist(err) {
-		return nil, err
 	}
-	return result, nil
-}
-
-func hasYAMLExtension(fname string) bool {
-	ext := filepath.Ext(fname)
-	return strings.EqualFold(ext, ".yaml") || strings.EqualFold(ext, ".yml")
+	return s.helmCharts, nil
 }
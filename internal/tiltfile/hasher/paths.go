@@ -0,0 +1,88 @@
+package hasher
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// HashPaths hashes the contents of the given files, and of every file under
+// the given directories, so that callers can tell if any of them changed.
+//
+// The hash covers file names (relative to each path), contents, and whether
+// a path exists at all. Directories named .git are skipped.
+func HashPaths(paths []string) (string, error) {
+	h := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintf(h, "path\x00%s\x00", p)
+
+		info, err := os.Stat(p)
+		if os.IsNotExist(err) {
+			fmt.Fprintf(h, "missing\x00")
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		if !info.IsDir() {
+			err := hashFile(h, p)
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			rel, err := filepath.Rel(p, path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "file\x00%s\x00", filepath.ToSlash(rel))
+
+			if d.Type()&fs.ModeSymlink != 0 {
+				target, err := os.Readlink(path)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(h, "symlink\x00%s\x00", target)
+				return nil
+			}
+			return hashFile(h, path)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func hashFile(h io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	contents := sha256.New()
+	_, err = io.Copy(contents, f)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(h, "%x\x00", contents.Sum(nil))
+	return nil
+}
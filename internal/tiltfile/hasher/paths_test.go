@@ -0,0 +1,49 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestHashPaths(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("dir/a.txt", "a")
+	f.WriteFile("dir/sub/b.txt", "b")
+	f.WriteFile("dir/.git/HEAD", "main")
+	f.WriteFile("c.txt", "c")
+
+	paths := []string{f.JoinPath("dir"), f.JoinPath("c.txt"), f.JoinPath("missing.txt")}
+	hash := func() string {
+		h, err := HashPaths(paths)
+		require.NoError(t, err)
+		return h
+	}
+
+	orig := hash()
+	assert.Equal(t, orig, hash())
+
+	// .git is ignored
+	f.WriteFile("dir/.git/HEAD", "other")
+	assert.Equal(t, orig, hash())
+
+	f.WriteFile("dir/sub/b.txt", "b2")
+	changed := hash()
+	assert.NotEqual(t, orig, changed)
+
+	f.WriteFile("dir/sub/b.txt", "b")
+	assert.Equal(t, orig, hash())
+
+	// Renames change the hash, even with the same contents.
+	f.Rm("dir/a.txt")
+	f.WriteFile("dir/a2.txt", "a")
+	assert.NotEqual(t, orig, hash())
+	f.Rm("dir/a2.txt")
+	f.WriteFile("dir/a.txt", "a")
+
+	f.WriteFile("missing.txt", "")
+	assert.NotEqual(t, orig, hash())
+}
@@ -1,12 +1,9 @@
 package tiltfile
 
 import (
-	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"strings"
 
 	"github.com/ghodss/yaml"
@@ -18,6 +15,9 @@ const helmSeparator = "---\n"
 
 const helmFileRepository = "file://"
 
+// Remote charts are cached under the xdg cache dir.
+const helmChartCacheDir = "tiltfile/helm/charts"
+
 var helmTestYAMLMatcher = regexp.MustCompile("^# Source: .*/tests/")
 
 func filterHelmTestYAML(resourceBlob string) string {
@@ -43,96 +43,30 @@ func isHelmTestYAML(resource string) bool {
 	return false
 }
 
-type helmVersion int
-
-const (
-	unknownHelmVersion helmVersion = iota
-	helmV2
-	helmV3_0
-	helmV3_1andAbove
-)
-
-func parseVersion(versionOutput string) (helmVersion, error) {
-	// helm v3.3.3 throws warnings on stdout, which messes up version parsing;
-	// if we have multiple lines of version output, assume version info is in
-	// the last line (see https://github.com/tilt-dev/tilt/issues/3788)
-	version := versionOutput
-	lines := strings.Split(strings.TrimSpace(versionOutput), "\n")
-	if len(lines) > 1 {
-		version = lines[(len(lines) - 1)]
-	}
-
-	if strings.HasPrefix(version, "v3.0.") {
-		return helmV3_0, nil
-	} else if strings.HasPrefix(version, "v3.") {
-		return helmV3_1andAbove, nil
-	} else if strings.HasPrefix(version, "Client: v2") {
-		return helmV2, nil
-	}
-
-	return unknownHelmVersion, fmt.Errorf("could not parse Helm version from string: %q", versionOutput)
-}
-
-func isHelmInstalled() bool {
-	if runtime.GOOS == "windows" {
-		cmd := exec.Command("where", "helm.exe")
-		if err := cmd.Run(); err != nil {
-			return false
+// Finds the chart's file:// dependencies, in Chart.yaml (Helm 3) or
+// requirements.yaml (Helm 2), as absolute paths.
+func localSubchartDependenciesFromPath(chartPath string) ([]string, error) {
+	var result []string
+	for _, f := range []string{"Chart.yaml", "requirements.yaml"} {
+		dat, err := os.ReadFile(filepath.Join(chartPath, f))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
 		}
 
-		return true
-	}
-
-	cmd := exec.Command("/bin/sh", "-c", "command -v helm")
-	if err := cmd.Run(); err != nil {
-		return false
-	}
-
-	return true
-}
-
-func getHelmVersion() (helmVersion, error) {
-	if !isHelmInstalled() {
-		return unknownHelmVersion, unableToFindHelmErrorMessage()
-	}
-
-	// NOTE(dmiller): I pass `--client` here even though that doesn't do anything in Helm v3.
-	// In Helm v2 that causes `helm version` to not reach out to tiller. Doing so can cause the
-	// command to fail, even though Tilt doesn't use the server at all (it just calls
-	// `helm template`).
-	// In Helm v3, it has no effect, not even an unknown flag error.
-	cmd := exec.Command("helm", "version", "--client", "--short")
-
-	out, err := cmd.Output()
-	if err != nil {
-		return unknownHelmVersion, err
-	}
-
-	return parseVersion(string(out))
-}
-
-func unableToFindHelmErrorMessage() error {
-	var binaryName string
-	if runtime.GOOS == "windows" {
-		binaryName = "helm.exe"
-	} else {
-		binaryName = "helm"
-	}
-
-	return fmt.Errorf("Unable to find Helm installation. Make sure `%s` is on your $PATH.", binaryName)
-}
-
-func localSubchartDependenciesFromPath(chartPath string) ([]string, error) {
-	var deps []string
-	requirementsPath := filepath.Join(chartPath, "requirements.yaml")
-	dat, err := os.ReadFile(requirementsPath)
-	if os.IsNotExist(err) {
-		return deps, nil
-	} else if err != nil {
-		return deps, err
+		deps, err := localSubchartDependencies(dat)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range deps {
+			if !filepath.IsAbs(d) {
+				d = filepath.Join(chartPath, d)
+			}
+			result = append(result, d)
+		}
 	}
-
-	return localSubchartDependencies(dat)
+	return result, nil
 }
 
 type chartDependency struct {
@@ -0,0 +1,202 @@
+package tiltfile
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/helm"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// The chart values that helm_release() sets to each image it depends on,
+// unless image_keys says otherwise.
+const (
+	defaultHelmImageRepositoryKey = "image.repository"
+	defaultHelmImageTagKey        = "image.tag"
+)
+
+type k8sHelmRelease struct {
+	releaseName     string
+	namespace       string
+	chart           string
+	valueFiles      []string
+	set             []string
+	imageKeys       []helmImageKeys
+	createNamespace bool
+	atomic          bool
+	timeout         time.Duration
+	deps            []string
+}
+
+// The chart values to inject an image into. The image map name
+// is filled in once we know which image target builds the image.
+type helmImageKeys struct {
+	ref  reference.Named
+	keys v1alpha1.KubernetesApplyHelmImageKeys
+}
+
+func (s *tiltfileState) helmRelease(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var chartValue starlark.Value
+	var releaseName string
+	var namespace string
+	var valueFiles value.StringOrStringList
+	var set value.StringOrStringList
+	var imageDeps value.ImageList
+	var imageKeysVal starlark.Value
+	var createNamespace bool
+	var atomic bool
+	timeout := value.Duration(helm.DefaultReleaseTimeout)
+
+	deps := value.NewLocalPathListUnpacker(thread)
+
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"chart", &chartValue,
+		"release_name?", &releaseName,
+		"namespace?", &namespace,
+		"values?", &valueFiles,
+		"set?", &set,
+		"image_deps?", &imageDeps,
+		"image_keys?", &imageKeysVal,
+		"create_namespace?", &createNamespace,
+		"atomic?", &atomic,
+		"timeout?", &timeout,
+		"deps?", &deps,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if releaseName == "" {
+		releaseName = name
+	}
+
+	ref, err := helmChartRef(thread, fn.Name(), "chart", chartValue)
+	if err != nil {
+		return nil, err
+	}
+
+	// The release is installed by the KubernetesApply reconciler, so changes to
+	// a local chart or its values only need a new upgrade, not a Tiltfile reload.
+	releaseDeps := append([]string{}, deps.Value...)
+	chartPath := ref.Name
+	if ref.Kind == helm.ChartRefLocal {
+		releaseDeps = append(releaseDeps, chartPath)
+		subcharts, err := localSubchartDependenciesFromPath(chartPath)
+		if err != nil {
+			return nil, err
+		}
+		releaseDeps = append(releaseDeps, subcharts...)
+	} else {
+		fetcher, err := s.helmChartFetcher()
+		if err != nil {
+			return nil, err
+		}
+		chartPath, err = fetcher.Fetch(ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+	}
+
+	var absValueFiles []string
+	for _, valueFile := range valueFiles.Values {
+		absValueFile := starkit.AbsPath(thread, valueFile)
+		absValueFiles = append(absValueFiles, absValueFile)
+		releaseDeps = append(releaseDeps, absValueFile)
+	}
+
+	imageKeys, err := unpackHelmImageKeys(fn.Name(), imageDeps, imageKeysVal)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.makeK8sResource(name)
+	if err != nil {
+		return nil, fmt.Errorf("error making resource for %s: %v", name, err)
+	}
+
+	res.helmRelease = &k8sHelmRelease{
+		releaseName:     releaseName,
+		namespace:       namespace,
+		chart:           chartPath,
+		valueFiles:      absValueFiles,
+		set:             set.Values,
+		imageKeys:       imageKeys,
+		createNamespace: createNamespace,
+		atomic:          atomic,
+		timeout:         timeout.AsDuration(),
+		deps:            releaseDeps,
+	}
+	for _, imageDep := range imageDeps {
+		res.addImageDep(imageDep, true)
+	}
+
+	return starlark.None, nil
+}
+
+// image_keys has one entry per image in image_deps. Each entry is either the
+// key for the whole image ref, like 'image', or a (repository, tag) tuple of keys.
+func unpackHelmImageKeys(fnName string, imageDeps value.ImageList, v starlark.Value) ([]helmImageKeys, error) {
+	var entries []starlark.Value
+	switch x := v.(type) {
+	case nil, starlark.NoneType:
+	case *starlark.List:
+		for i := 0; i < x.Len(); i++ {
+			entries = append(entries, x.Index(i))
+		}
+	case starlark.Tuple:
+		entries = append(entries, x...)
+	default:
+		return nil, fmt.Errorf("%s: for parameter \"image_keys\": value should be a List, but is of type %s", fnName, v.Type())
+	}
+
+	if len(entries) == 0 {
+		// The default keys can only hold one image.
+		if len(imageDeps) > 1 {
+			return nil, fmt.Errorf("%s: image_keys is required when there are multiple image_deps (got %d), "+
+				"so that each image goes to its own chart values", fnName, len(imageDeps))
+		}
+		result := make([]helmImageKeys, 0, len(imageDeps))
+		for _, ref := range imageDeps {
+			result = append(result, helmImageKeys{
+				ref: ref,
+				keys: v1alpha1.KubernetesApplyHelmImageKeys{
+					Repository: defaultHelmImageRepositoryKey,
+					Tag:        defaultHelmImageTagKey,
+				},
+			})
+		}
+		return result, nil
+	}
+
+	if len(entries) != len(imageDeps) {
+		return nil, fmt.Errorf("%s: image_keys must have one entry for each of the %d image_deps, but has %d",
+			fnName, len(imageDeps), len(entries))
+	}
+
+	result := make([]helmImageKeys, 0, len(entries))
+	for i, entry := range entries {
+		keys := v1alpha1.KubernetesApplyHelmImageKeys{}
+		if s, ok := value.AsString(entry); ok {
+			keys.Image = s
+		} else if t, ok := entry.(starlark.Tuple); ok && len(t) == 2 {
+			repo, repoOK := value.AsString(t[0])
+			tag, tagOK := value.AsString(t[1])
+			if !repoOK || !tagOK {
+				return nil, fmt.Errorf("%s: image_keys[%d]: (repository, tag) keys must be strings, got %s", fnName, i, entry.String())
+			}
+			keys.Repository = repo
+			keys.Tag = tag
+		} else {
+			return nil, fmt.Errorf("%s: image_keys[%d]: expected a key or a (repository, tag) tuple of keys, got %s", fnName, i, entry.String())
+		}
+		result = append(result, helmImageKeys{ref: imageDeps[i], keys: keys})
+	}
+	return result, nil
+}
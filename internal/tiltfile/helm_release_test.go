@@ -0,0 +1,152 @@
+package tiltfile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestHelmRelease(t *testing.T) {
+	f := newFixture(t)
+	f.setupHelm()
+
+	f.file("Tiltfile", `
+helm_release('hello',
+             'helm',
+             namespace='garnet',
+             values=['./dev/helm/values-dev.yaml'],
+             set=['replicaCount=2'],
+             create_namespace=True,
+             atomic=True)
+`)
+
+	f.load()
+	m := f.assertNextManifest("hello")
+	assert.Empty(t, m.ImageTargets)
+
+	spec := m.K8sTarget().KubernetesApplySpec
+	assert.Empty(t, spec.YAML)
+	assert.Nil(t, spec.ApplyCmd)
+	assert.Equal(t, 5*time.Minute, spec.Timeout.Duration)
+	assert.Equal(t, &v1alpha1.KubernetesApplyHelmRelease{
+		ReleaseName:     "hello",
+		Namespace:       "garnet",
+		Chart:           f.JoinPath("helm"),
+		ValueFiles:      []string{f.JoinPath("dev/helm/values-dev.yaml")},
+		Set:             []string{"replicaCount=2"},
+		CreateNamespace: true,
+		Atomic:          true,
+	}, spec.HelmRelease)
+	require.NotNil(t, spec.RestartOn)
+	assert.Equal(t, []string{"hello:apply"}, spec.RestartOn.FileWatches)
+
+	// The chart is deployed by the reconciler, so edits to it
+	// re-run the upgrade without reloading the Tiltfile.
+	assert.ElementsMatch(t,
+		[]string{f.JoinPath("helm"), f.JoinPath("dev/helm/values-dev.yaml")},
+		m.K8sTarget().Dependencies())
+	f.assertConfigFiles("Tiltfile", ".tiltignore")
+}
+
+func TestHelmReleaseRenamedAndTimeout(t *testing.T) {
+	f := newFixture(t)
+	f.setupHelm()
+
+	f.file("Tiltfile", `
+helm_release('hello', 'helm', release_name='hello-dev', timeout='30s')
+`)
+
+	f.load()
+	spec := f.assertNextManifest("hello").K8sTarget().KubernetesApplySpec
+	assert.Equal(t, "hello-dev", spec.HelmRelease.ReleaseName)
+	assert.Equal(t, 30*time.Second, spec.Timeout.Duration)
+}
+
+func TestHelmReleaseImageDeps(t *testing.T) {
+	f := newFixture(t)
+	f.setupHelm()
+
+	f.file("Dockerfile", "FROM golang:1.10")
+	f.file("Tiltfile", `
+docker_build('image-a', '.')
+docker_build('image-b', '.')
+helm_release('hello', 'helm', image_deps=['image-a', 'image-b'],
+             image_keys=[('image.repository', 'image.tag'), 'sidecar.image'])
+`)
+
+	f.load()
+	m := f.assertNextManifest("hello")
+	assert.Equal(t, 2, len(m.ImageTargets))
+
+	spec := m.K8sTarget().KubernetesApplySpec
+	assert.Equal(t, []string{"image-a", "image-b"}, spec.ImageMaps)
+	assert.Equal(t, []v1alpha1.KubernetesApplyHelmImageKeys{
+		{ImageMap: "image-a", Repository: "image.repository", Tag: "image.tag"},
+		{ImageMap: "image-b", Image: "sidecar.image"},
+	}, spec.HelmRelease.ImageKeys)
+}
+
+func TestHelmReleaseDefaultImageKeys(t *testing.T) {
+	f := newFixture(t)
+	f.setupHelm()
+
+	f.file("Dockerfile", "FROM golang:1.10")
+	f.file("Tiltfile", `
+docker_build('image-a', '.')
+helm_release('hello', 'helm', image_deps=['image-a'])
+`)
+
+	f.load()
+	spec := f.assertNextManifest("hello").K8sTarget().KubernetesApplySpec
+	assert.Equal(t, []v1alpha1.KubernetesApplyHelmImageKeys{
+		{ImageMap: "image-a", Repository: "image.repository", Tag: "image.tag"},
+	}, spec.HelmRelease.ImageKeys)
+}
+
+func TestHelmReleaseImageKeysMismatch(t *testing.T) {
+	f := newFixture(t)
+	f.setupHelm()
+
+	f.file("Tiltfile", `
+helm_release('hello', 'helm', image_deps=['image-a', 'image-b'], image_keys=['image'])
+`)
+
+	f.loadErrString("helm_release: image_keys must have one entry for each of the 2 image_deps, but has 1")
+}
+
+func TestHelmReleaseMultipleImagesNeedImageKeys(t *testing.T) {
+	f := newFixture(t)
+	f.setupHelm()
+
+	f.file("Tiltfile", `
+helm_release('hello', 'helm', image_deps=['image-a', 'image-b'])
+`)
+
+	f.loadErrString("helm_release: image_keys is required when there are multiple image_deps (got 2)")
+}
+
+func TestHelmReleaseImageDepsMissing(t *testing.T) {
+	f := newFixture(t)
+	f.setupHelm()
+
+	f.file("Tiltfile", `
+helm_release('hello', 'helm', image_deps=['image-a'])
+`)
+
+	f.loadErrString(`resource "hello": image build "image-a" not found`)
+}
+
+func TestHelmReleaseNotAChart(t *testing.T) {
+	f := newFixture(t)
+	f.file("values.yaml", "")
+
+	f.file("Tiltfile", `
+helm_release('hello', 'values.yaml')
+`)
+
+	f.loadErrString("helm_release() may only be called on directories with Chart.yaml, or packaged charts")
+}
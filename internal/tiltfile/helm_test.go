@@ -7,8 +7,6 @@
 package tiltfile
 
 import (
-	"os/exec"
-	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -243,48 +241,6 @@ k8s_yaml(yml)
 	f.loadErrString("helm: for parameter \"set\"", "string", "List", "type dict")
 }
 
-const exampleHelmV2VersionOutput = `Client: v2.12.3geecf22f`
-const exampleHelmV3_0VersionOutput = `v3.0.0`
-const exampleHelmV3_1VersionOutput = `v3.1.0`
-const exampleHelmV3_2VersionOutput = `v3.2.4`
-
-// see https://github.com/tilt-dev/tilt/issues/3788
-const exampleHelmV3_3VersionOutput = `WARNING: Kubernetes configuration file is group-readable. This is insecure. Location: /Users/someone/.kube/config
-WARNING: Kubernetes configuration file is world-readable. This is insecure. Location: /Users/someone/.kube/config
-v3.3.3+g55e3ca0
-`
-
-func TestParseHelmV2Version(t *testing.T) {
-	expected := helmV2
-	assertHelmVersion(t, exampleHelmV2VersionOutput, expected)
-}
-
-func TestParseHelmV3Version(t *testing.T) {
-	expected := helmV3_0
-	assertHelmVersion(t, exampleHelmV3_0VersionOutput, expected)
-}
-
-func TestParseHelmV3_1Version(t *testing.T) {
-	expected := helmV3_1andAbove
-	assertHelmVersion(t, exampleHelmV3_1VersionOutput, expected)
-}
-
-func TestParseHelmV3_2Version(t *testing.T) {
-	expected := helmV3_1andAbove
-	assertHelmVersion(t, exampleHelmV3_2VersionOutput, expected)
-}
-
-func TestParseHelmV3_3Version(t *testing.T) {
-	expected := helmV3_1andAbove
-	assertHelmVersion(t, exampleHelmV3_3VersionOutput, expected)
-}
-
-func TestHelmUnknownVersionError(t *testing.T) {
-	_, err := parseVersion("v4.1.2")
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "could not parse Helm version from string")
-}
-
 const fileRequirementsYAML = `dependencies:
   - name: foobar
     version: 1.0.1
@@ -378,20 +334,8 @@ k8s_yaml(helm('./helm'))
 
 	m := manifests[0]
 	yaml := m.K8sTarget().YAML
-	v, err := getHelmVersion()
-	assert.NoError(t, err)
 	assert.Contains(t, yaml, "kind: ServiceAccount")
-	if v == helmV3_0 || v == helmV3_1andAbove {
-		assert.Contains(t, yaml, "kind: UselessMachine")
-	} else {
-		assert.NotContains(t, yaml, "kind: UselessMachine")
-	}
-}
-
-func assertHelmVersion(t *testing.T, versionOutput string, expectedV helmVersion) {
-	actualV, err := parseVersion(versionOutput)
-	require.NoError(t, err, "parsing helm version")
-	require.Equal(t, expectedV, actualV)
+	assert.Contains(t, yaml, "kind: UselessMachine")
 }
 
 func TestYamlErrorFromHelm(t *testing.T) {
@@ -402,17 +346,7 @@ func TestYamlErrorFromHelm(t *testing.T) {
 k8s_yaml(helm('helm'))
 `)
 
-	// TODO(dmiller): there should be a better assertion here
-
-	version, err := getHelmVersion()
-	if err != nil {
-		t.Fatal(err)
-	}
-	if version == helmV2 {
-		f.loadErrString("from helm")
-	} else {
-		f.loadErrString("in helm")
-	}
+	f.loadErrString("in helm", "templates/foo.yaml")
 }
 
 func TestHelmSkipsTests(t *testing.T) {
@@ -434,23 +368,7 @@ k8s_yaml(yml)
 	)
 }
 
-// There's a major helm regression that's breaking everything
-// https://github.com/helm/helm/issues/6708
-func isBuggyHelm(t *testing.T) bool {
-	cmd := exec.Command("helm", "version", "-c", "--short")
-	out, err := cmd.Output()
-	if err != nil {
-		t.Fatalf("Error running helm: %v", err)
-	}
-
-	return strings.Contains(string(out), "v2.15.0")
-}
-
 func TestHelmIncludesRequirements(t *testing.T) {
-	if isBuggyHelm(t) {
-		t.Skipf("Helm v2.15.0 has a major regression, skipping test. See: https://github.com/helm/helm/issues/6708")
-	}
-
 	f := newFixture(t)
 
 	f.setupHelmWithRequirements()
@@ -462,3 +380,66 @@ k8s_yaml(yml)
 	f.load()
 	f.assertNextManifest("chart-nginx-ingress-controller")
 }
+
+func TestHelmPackagedChart(t *testing.T) {
+	f := newFixture(t)
+
+	f.CopyFile(testdata.NginxIngressChartPath(), "nginx-ingress.tgz")
+	f.file("Tiltfile", `
+k8s_yaml(helm('nginx-ingress.tgz'))
+`)
+
+	f.load()
+	f.assertNextManifest("chart-nginx-ingress-controller")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "nginx-ingress.tgz")
+}
+
+func TestHelmWatchesLocalSubcharts(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupHelm()
+	f.file("helm/Chart.yaml", chartYAML+`
+dependencies:
+- name: sub
+  version: 0.1.0
+  repository: file://../sub
+`)
+	f.file("sub/Chart.yaml", `apiVersion: v1
+name: sub
+version: 0.1.0`)
+	f.file("helm/charts/sub/Chart.yaml", `apiVersion: v1
+name: sub
+version: 0.1.0`)
+	f.file("Tiltfile", `
+k8s_yaml(helm('helm'))
+`)
+
+	f.load()
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "helm", "sub")
+}
+
+func TestHelmRemoteChartUnknownRepo(t *testing.T) {
+	f := newFixture(t)
+	t.Setenv("HELM_REPOSITORY_CONFIG", f.JoinPath("helm-config", "repositories.yaml"))
+
+	f.file("Tiltfile", `
+k8s_yaml(helm('nope/nginx@1.0.0'))
+`)
+
+	f.loadErrString("fetching chart nope/nginx@1.0.0", `no Helm repository named "nope"`)
+}
+
+func TestHelmChartDirectoryWinsOverRepoRef(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("deploy/helm/Chart.yaml", chartYAML)
+	f.file("deploy/helm/templates/service.yaml", serviceYAML)
+	f.file("deploy/helm/templates/_helpers.tpl", helpersTPL)
+	f.file("deploy/helm/values.yaml", valuesYAML)
+	f.file("Tiltfile", `
+k8s_yaml(helm('deploy/helm'))
+`)
+
+	f.load()
+	f.assertNextManifestUnresourced("chart-helloworld-chart")
+}
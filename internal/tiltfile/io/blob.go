@@ -5,6 +5,7 @@ import (
 
 	"go.starlark.net/starlark"
 
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 )
 
@@ -42,3 +43,53 @@ func (b Blob) Hash() (uint32, error) {
 }
 
 var _ value.ImplicitStringer = Blob{}
+
+// A blob that's still being computed in the background,
+// e.g., by helm(..., async=True).
+//
+// Builtins wait for it automatically, and fail with its error. Anything else
+// that needs the text (like str()) blocks until it's ready. If it failed,
+// the text is empty, and the Tiltfile is stopped with the error.
+type LazyBlob struct {
+	future *starkit.Future
+}
+
+var _ starkit.Awaitable = LazyBlob{}
+
+// future must resolve to a Blob.
+func NewLazyBlob(future *starkit.Future) LazyBlob {
+	return LazyBlob{future: future}
+}
+
+func (b LazyBlob) Await() (starlark.Value, error) {
+	return b.future.Await()
+}
+
+func (b LazyBlob) text() string {
+	blob, _ := b.future.AwaitOrCancel().(Blob)
+	return blob.Text
+}
+
+func (b LazyBlob) ImplicitString() string {
+	return b.text()
+}
+
+func (b LazyBlob) String() string {
+	return b.text()
+}
+
+func (b LazyBlob) Type() string {
+	return "blob"
+}
+
+func (b LazyBlob) Freeze() {}
+
+func (b LazyBlob) Truth() starlark.Bool {
+	return len(b.text()) > 0
+}
+
+func (b LazyBlob) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: blob")
+}
+
+var _ value.ImplicitStringer = LazyBlob{}
@@ -1,6 +1,7 @@
 package io
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/internal/xdg"
 )
 
 type WatchType int
@@ -24,17 +26,35 @@ const (
 	WatchRecursive
 )
 
-type Plugin struct{}
+type Plugin struct {
+	// Where read_url() caches downloads
+	base xdg.Base
+
+	fetch URLFetcher
+}
+
+// Downloads a URL for read_url().
+type URLFetcher func(ctx context.Context, url string, headers map[string]string) ([]byte, error)
 
 func NewPlugin() Plugin {
-	return Plugin{}
+	return NewPluginWithBase(xdg.NewTiltDevBase())
+}
+
+func NewPluginWithBase(base xdg.Base) Plugin {
+	return Plugin{base: base, fetch: fetchURL}
+}
+
+// Replaces the network for read_url(), e.g., with fakes in Tiltfile tests.
+func (p Plugin) WithURLFetcher(fetch URLFetcher) Plugin {
+	p.fetch = fetch
+	return p
 }
 
 func (Plugin) NewState() interface{} {
 	return ReadState{}
 }
 
-func (Plugin) OnStart(e *starkit.Environment) error {
+func (p Plugin) OnStart(e *starkit.Environment) error {
 	err := e.AddBuiltin("read_file", readFile)
 	if err != nil {
 		return err
@@ -55,6 +75,11 @@ func (Plugin) OnStart(e *starkit.Environment) error {
 		return err
 	}
 
+	err = e.AddBuiltin("read_url", p.readURL)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -153,6 +178,10 @@ func blob(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kw
 // Track all the paths read while loading
 type ReadState struct {
 	Paths []string
+
+	// URLs fetched with read_url(). We can't watch these for changes,
+	// but they're part of what the Tiltfile result depends on.
+	URLs []string
 }
 
 func ReadFile(thread *starlark.Thread, p string) ([]byte, error) {
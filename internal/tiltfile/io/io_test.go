@@ -8,6 +8,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/testutils"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starlarkstruct"
+	"github.com/tilt-dev/tilt/internal/xdg"
 )
 
 func TestReadFile(t *testing.T) {
@@ -84,7 +85,7 @@ s = read_file('dne.txt')
 }
 
 func newFixture(t *testing.T) *starkit.Fixture {
-	f := starkit.NewFixture(t, NewPlugin(), starlarkstruct.NewPlugin())
+	f := starkit.NewFixture(t, NewPluginWithBase(xdg.FakeBase{Dir: t.TempDir()}), starlarkstruct.NewPlugin())
 	f.UseRealFS()
 	f.File("assert.tilt", `
 def equals(expected, observed):
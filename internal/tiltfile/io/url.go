@@ -0,0 +1,262 @@
+package io
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/sliceutils"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+const urlCacheDir = "tiltfile/urls"
+
+// Tiltfiles load synchronously, so don't let a slow server hang them forever.
+const readURLTimeout = 2 * time.Minute
+
+// read_url(url, sha256=None, headers=None)
+//
+// Downloads are stored in a content-addressed cache, keyed by their SHA-256.
+//
+// If the Tiltfile pins the checksum, and we already have the content,
+// we never hit the network. Otherwise, we download the URL on every
+// Tiltfile load, and fall back to the last copy we downloaded if the
+// server is unreachable.
+func (p Plugin) readURL(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var url string
+	var checksum string
+	var headers value.StringStringMap
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"url", &url,
+		"sha256?", &checksum,
+		"headers?", &headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("%s: url must start with http:// or https://, got %q", fn.Name(), url)
+	}
+	checksum = strings.ToLower(checksum)
+	if checksum != "" && !isSHA256(checksum) {
+		return nil, fmt.Errorf("%s: sha256 must be 64 hex characters, got %q", fn.Name(), checksum)
+	}
+
+	err = recordURL(thread, url)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := urlCache{base: p.base}
+	if checksum != "" {
+		contents, ok, err := cache.get(checksum)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", fn.Name())
+		}
+		if ok {
+			return NewBlob(string(contents), fmt.Sprintf("url: %s", url)), nil
+		}
+	}
+
+	ctx, err := starkit.ContextFromThread(thread)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, fetchErr := p.fetch(ctx, url, headers)
+	if fetchErr != nil {
+		// If we can't reach the server, use the last copy we downloaded.
+		// But if the server says the url is bad, believe it.
+		var statusErr httpStatusError
+		if errors.As(fetchErr, &statusErr) && statusErr.code < 500 {
+			return nil, errors.Wrapf(fetchErr, "%s", fn.Name())
+		}
+		digest, ok, err := cache.lookupURL(url)
+		if err != nil || !ok {
+			return nil, errors.Wrapf(fetchErr, "%s", fn.Name())
+		}
+		if checksum != "" && digest != checksum {
+			return nil, errors.Wrapf(fetchErr, "%s", fn.Name())
+		}
+		contents, ok, err := cache.get(digest)
+		if err != nil || !ok {
+			return nil, errors.Wrapf(fetchErr, "%s", fn.Name())
+		}
+		logger.Get(ctx).Warnf("%s: %v\nUsing cached copy", fn.Name(), fetchErr)
+		return NewBlob(string(contents), fmt.Sprintf("url: %s", url)), nil
+	}
+
+	digest := sha256Hex(contents)
+	if checksum != "" && digest != checksum {
+		return nil, fmt.Errorf("%s: checksum mismatch for %s\nexpected sha256: %s\n  actual sha256: %s",
+			fn.Name(), url, checksum, digest)
+	}
+
+	err = cache.put(url, digest, contents)
+	if err != nil {
+		// The cache only exists to make later loads faster and work offline.
+		logger.Get(ctx).Debugf("%s: caching %s: %v", fn.Name(), url, err)
+	}
+
+	return NewBlob(string(contents), fmt.Sprintf("url: %s", url)), nil
+}
+
+func fetchURL(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Timeout: readURLTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching %s", url)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, httpStatusError{url: url, code: resp.StatusCode, status: resp.Status}
+	}
+
+	contents, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", url)
+	}
+	return contents, nil
+}
+
+type httpStatusError struct {
+	url    string
+	code   int
+	status string
+}
+
+func (e httpStatusError) Error() string {
+	return fmt.Sprintf("fetching %s: server returned %s", e.url, e.status)
+}
+
+func recordURL(t *starlark.Thread, url string) error {
+	err := starkit.SetState(t, func(s ReadState) ReadState {
+		s.URLs = sliceutils.AppendWithoutDupes(s.URLs, url)
+		return s
+	})
+	return errors.Wrap(err, "error recording read url")
+}
+
+func isSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Stores url contents by SHA-256 under the xdg cache dir, plus an index
+// from each url to the digest of its last successful download.
+type urlCache struct {
+	base xdg.Base
+}
+
+func (c urlCache) contentPath(digest string) (string, error) {
+	return c.base.CacheFile(filepath.Join(urlCacheDir, "sha256", digest))
+}
+
+func (c urlCache) indexPath(url string) (string, error) {
+	return c.base.CacheFile(filepath.Join(urlCacheDir, "index", sha256Hex([]byte(url))))
+}
+
+func (c urlCache) get(digest string) ([]byte, bool, error) {
+	p, err := c.contentPath(digest)
+	if err != nil {
+		return nil, false, err
+	}
+	contents, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	// Don't trust anything that was corrupted on disk.
+	if sha256Hex(contents) != digest {
+		return nil, false, nil
+	}
+	return contents, true, nil
+}
+
+func (c urlCache) lookupURL(url string) (string, bool, error) {
+	p, err := c.indexPath(url)
+	if err != nil {
+		return "", false, err
+	}
+	b, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	digest := strings.TrimSpace(string(b))
+	if !isSHA256(digest) {
+		return "", false, nil
+	}
+	return digest, true, nil
+}
+
+func (c urlCache) put(url string, digest string, contents []byte) error {
+	p, err := c.contentPath(digest)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(p, contents)
+	if err != nil {
+		return err
+	}
+
+	indexPath, err := c.indexPath(url)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(indexPath, []byte(digest+"\n"))
+}
+
+// Write to a temp file and rename, so that concurrent Tilts never see
+// a partial file.
+func writeFileAtomic(p string, contents []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(contents)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
@@ -0,0 +1,139 @@
+package io
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const crdYAML = "kind: CustomResourceDefinition\n"
+
+var wrongSHA256 = strings.Repeat("0", 64)
+
+func TestReadURL(t *testing.T) {
+	f := newFixture(t)
+	s := newURLServer(t)
+
+	f.File("Tiltfile", fmt.Sprintf(`
+load('assert.tilt', 'assert')
+s = read_url('%s/crd.yaml', headers={'Authorization': 'Bearer xyz'})
+assert.equals(%q, str(s))
+`, s.URL, crdYAML))
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	require.Equal(t, "Bearer xyz", s.lastAuth)
+
+	rs, err := GetState(result)
+	require.NoError(t, err)
+	require.Equal(t, []string{s.URL + "/crd.yaml"}, rs.URLs)
+}
+
+func TestReadURLOfflineUsesCache(t *testing.T) {
+	f := newFixture(t)
+	s := newURLServer(t)
+
+	f.File("Tiltfile", fmt.Sprintf(`
+load('assert.tilt', 'assert')
+s = read_url('%s/crd.yaml')
+assert.equals(%q, str(s))
+`, s.URL, crdYAML))
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	s.Close()
+
+	_, err = f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	require.Contains(t, f.PrintOutput(), "Using cached copy")
+}
+
+func TestReadURLNotFound(t *testing.T) {
+	f := newFixture(t)
+	s := newURLServer(t)
+
+	f.File("Tiltfile", fmt.Sprintf(`read_url('%s/missing.yaml')`, s.URL))
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "server returned 404 Not Found")
+}
+
+func TestReadURLChecksum(t *testing.T) {
+	f := newFixture(t)
+	s := newURLServer(t)
+	digest := sha256Hex([]byte(crdYAML))
+
+	f.File("Tiltfile", fmt.Sprintf(`
+load('assert.tilt', 'assert')
+s = read_url('%s/crd.yaml', sha256='%s')
+assert.equals(%q, str(s))
+`, s.URL, digest, crdYAML))
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	require.Equal(t, 1, s.requests)
+
+	// Pinned content that's already cached is never re-downloaded.
+	_, err = f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	require.Equal(t, 1, s.requests)
+}
+
+func TestReadURLChecksumMismatch(t *testing.T) {
+	f := newFixture(t)
+	s := newURLServer(t)
+
+	f.File("Tiltfile", fmt.Sprintf(`read_url('%s/crd.yaml', sha256='%s')`, s.URL, wrongSHA256))
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "checksum mismatch")
+	require.Contains(t, err.Error(), sha256Hex([]byte(crdYAML)))
+}
+
+func TestReadURLInvalidChecksum(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `read_url('https://example.com/crd.yaml', sha256='abc')`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sha256 must be 64 hex characters")
+}
+
+func TestReadURLNotHTTP(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `read_url('file:///etc/passwd')`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "url must start with http:// or https://")
+}
+
+type urlServer struct {
+	*httptest.Server
+	requests int
+	lastAuth string
+}
+
+func newURLServer(t *testing.T) *urlServer {
+	s := &urlServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		s.lastAuth = r.Header.Get("Authorization")
+		if r.URL.Path != "/crd.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(crdYAML))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
@@ -13,13 +13,17 @@ import (
 	"go.starlark.net/syntax"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/tilt-dev/tilt/internal/tiltfile/links"
 
+	"github.com/tilt-dev/clusterid"
+	"github.com/tilt-dev/tilt/internal/clusterprovision"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	tiltfile_k8s "github.com/tilt-dev/tilt/internal/tiltfile/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -71,6 +75,15 @@ type k8sResource struct {
 	labels map[string]string
 
 	customDeploy *k8sCustomDeploy
+
+	helmRelease *k8sHelmRelease
+
+	// The cluster to deploy to. Empty means the default cluster.
+	cluster string
+
+	// The clusters that k8s_yaml() loaded this resource's objects for.
+	// Empty string means objects loaded without a cluster.
+	yamlClusters []string
 }
 
 // holds options passed to `k8s_resource` until assembly happens
@@ -90,6 +103,7 @@ type k8sResourceOptions struct {
 	discoveryStrategy v1alpha1.KubernetesDiscoveryStrategy
 	links             []model.Link
 	labels            map[string]string
+	cluster           string
 }
 
 // Count image injection for analytics.
@@ -122,6 +136,16 @@ func (r *k8sResource) addImageDep(image reference.Named, required bool) {
 	metadata.required = metadata.required || required
 }
 
+// Record that some of this resource's objects were loaded for the given cluster.
+func (r *k8sResource) addYAMLCluster(cluster string) {
+	for _, c := range r.yamlClusters {
+		if c == cluster {
+			return
+		}
+	}
+	r.yamlClusters = append(r.yamlClusters, cluster)
+}
+
 func (r *k8sResource) addEntities(entities []k8s.K8sEntity,
 	locators []k8s.ImageLocator, envVarImages []container.RefSelector) error {
 	r.entities = append(r.entities, entities...)
@@ -142,10 +166,12 @@ func (r *k8sResource) addEntities(entities []k8s.K8sEntity,
 func (s *tiltfileState) k8sYaml(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var yamlValue starlark.Value
 	var allowDuplicates bool
+	var cluster string
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"yaml", &yamlValue,
 		"allow_duplicates?", &allowDuplicates,
+		"cluster?", &cluster,
 	); err != nil {
 		return nil, err
 	}
@@ -171,7 +197,11 @@ func (s *tiltfileState) k8sYaml(thread *starlark.Thread, fn *starlark.Builtin, a
 			return nil, err
 		}
 
-		s.k8sUnresourced = append(s.k8sUnresourced, entities...)
+		if cluster == "" {
+			s.k8sUnresourced = append(s.k8sUnresourced, entities...)
+		} else {
+			s.k8sClusterUnresourced[cluster] = append(s.k8sClusterUnresourced[cluster], entities...)
+		}
 
 	} else {
 		return nil, emptyYAMLError
@@ -182,7 +212,7 @@ func (s *tiltfileState) k8sYaml(thread *starlark.Thread, fn *starlark.Builtin, a
 
 func (s *tiltfileState) extractSecrets() model.SecretSet {
 	result := model.SecretSet{}
-	for _, e := range s.k8sUnresourced {
+	for _, e := range s.allUnresourced() {
 		secrets := s.maybeExtractSecrets(e)
 		result.AddAll(secrets)
 	}
@@ -305,6 +335,7 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 	var autoInit = value.Optional[starlark.Bool]{Value: true}
 	var labels value.LabelSet
 	var discoveryStrategy tiltfile_k8s.DiscoveryStrategy
+	var cluster string
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"workload?", &workload,
@@ -319,6 +350,7 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 		"links?", &links,
 		"labels?", &labels,
 		"discovery_strategy?", &discoveryStrategy,
+		"cluster?", &cluster,
 	); err != nil {
 		return nil, err
 	}
@@ -379,6 +411,7 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 		links:             links.Links,
 		labels:            labelMap,
 		discoveryStrategy: v1alpha1.KubernetesDiscoveryStrategy(discoveryStrategy),
+		cluster:           cluster,
 	})
 
 	return starlark.None, nil
@@ -541,6 +574,82 @@ func (s *tiltfileState) k8sKind(thread *starlark.Thread, fn *starlark.Builtin, a
 	return starlark.None, nil
 }
 
+func (s *tiltfileState) k8sLocalCluster(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if s.clusterProvision != nil {
+		return nil, fmt.Errorf("%s: local cluster already defined", fn.Name())
+	}
+
+	var product, name, kubernetesVersion string
+	var nodes, registryPort int
+	var registry bool
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"product", &product,
+		"name?", &name,
+		"nodes?", &nodes,
+		"kubernetes_version?", &kubernetesVersion,
+		"registry?", &registry,
+		"registry_port?", &registryPort,
+	); err != nil {
+		return nil, err
+	}
+
+	provision := &v1alpha1.ClusterProvision{
+		Product:           product,
+		Name:              name,
+		Nodes:             int32(nodes),
+		KubernetesVersion: kubernetesVersion,
+	}
+	if registry {
+		provision.Registry = &v1alpha1.ClusterProvisionRegistry{Port: int32(registryPort)}
+	} else if registryPort != 0 {
+		return nil, fmt.Errorf("%s: registry_port requires registry=True", fn.Name())
+	}
+
+	cluster := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{Provision: provision}}
+	if err := cluster.Validate(s.ctx); len(err) > 0 {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err.ToAggregate())
+	}
+
+	err := k8scontext.SetProvisionedCluster(thread,
+		k8s.KubeContext(clusterprovision.KubeContext(*provision)), clusterid.Product(product))
+	if err != nil {
+		return nil, err
+	}
+
+	s.clusterProvision = provision
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) k8sCluster(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, context, namespace string
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"context", &context,
+		"namespace?", &namespace,
+	); err != nil {
+		return nil, err
+	}
+
+	if name == v1alpha1.ClusterNameDefault || name == v1alpha1.ClusterNameDocker {
+		return nil, fmt.Errorf("%s: cluster name %q is reserved", fn.Name(), name)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, fmt.Errorf("%s: invalid cluster name %q: %s", fn.Name(), name, strings.Join(errs, "; "))
+	}
+	if context == "" {
+		return nil, fmt.Errorf("%s: cluster %q must have a context", fn.Name(), name)
+	}
+	if _, ok := s.k8sClusters[name]; ok {
+		return nil, fmt.Errorf("%s: cluster %q already defined", fn.Name(), name)
+	}
+
+	s.k8sClusters[name] = &v1alpha1.KubernetesClusterConnection{
+		Context:   context,
+		Namespace: namespace,
+	}
+	return starlark.None, nil
+}
+
 func (s *tiltfileState) workloadToResourceFunctionFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var wtrf *starlark.Function
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
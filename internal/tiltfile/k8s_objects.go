@@ -0,0 +1,328 @@
+package tiltfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/yaml"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// The k8s.* builtins edit YAML objects, so that Tiltfiles don't have to
+// decode_yaml_stream, walk dicts, and encode_yaml_stream.
+//
+// Each takes the same YAML inputs as k8s_yaml, plus the same selector
+// arguments as filter_yaml to choose which objects to edit. Objects that
+// don't match are passed through unchanged.
+
+// Selector arguments shared by all the k8s.* builtins.
+type k8sObjectSelectorArgs struct {
+	labels     value.StringStringMap
+	name       string
+	namespace  string
+	kind       string
+	apiVersion string
+}
+
+func (a *k8sObjectSelectorArgs) unpackSpec() []interface{} {
+	return []interface{}{
+		"labels?", &a.labels,
+		"name?", &a.name,
+		"namespace?", &a.namespace,
+		"kind?", &a.kind,
+		"api_version?", &a.apiVersion,
+	}
+}
+
+func (a *k8sObjectSelectorArgs) String() string {
+	var parts []string
+	for _, p := range []struct{ k, v string }{
+		{"kind", a.kind},
+		{"name", a.name},
+		{"namespace", a.namespace},
+		{"api_version", a.apiVersion},
+	} {
+		if p.v != "" {
+			parts = append(parts, fmt.Sprintf("%s=%q", p.k, p.v))
+		}
+	}
+	for _, k := range sortedLabelKeys(a.labels) {
+		parts = append(parts, fmt.Sprintf("labels[%q]=%q", k, a.labels[k]))
+	}
+	if len(parts) == 0 {
+		return "all objects"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sortedLabelKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (a *k8sObjectSelectorArgs) matcher() (func(e k8s.K8sEntity) bool, error) {
+	sel, err := k8s.NewPartialMatchObjectSelector(a.apiVersion, a.kind, a.name, a.namespace)
+	if err != nil {
+		return nil, err
+	}
+	return func(e k8s.K8sEntity) bool {
+		if !sel.Matches(e) {
+			return false
+		}
+		objLabels := e.Labels()
+		for k, v := range a.labels {
+			if objLabels[k] != v {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func yamlSource(v starlark.Value, fn *starlark.Builtin) string {
+	if b, ok := v.(io.Blob); ok {
+		return b.Source
+	}
+	return fn.Name()
+}
+
+// Applies edit to every object that matches the selector, and returns all
+// the objects (edited or not) as a blob, in their original order.
+//
+// It's an error if nothing changes, because that almost always means a typo
+// in the selector.
+func (s *tiltfileState) editK8sObjects(thread *starlark.Thread, fn *starlark.Builtin, yamlValue starlark.Value,
+	sel *k8sObjectSelectorArgs, edit func(e k8s.K8sEntity) (k8s.K8sEntity, bool, error)) (starlark.Value, error) {
+	entities, err := s.yamlEntitiesFromSkylarkValueOrList(thread, yamlValue)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", fn.Name())
+	}
+
+	matches, err := sel.matcher()
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", fn.Name())
+	}
+
+	changed := false
+	result := make([]k8s.K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		if !matches(e) {
+			result = append(result, e)
+			continue
+		}
+
+		edited, ok, err := edit(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", fn.Name())
+		}
+		if ok {
+			changed = true
+			e = edited
+		}
+		result = append(result, e)
+	}
+
+	if !changed {
+		return nil, fmt.Errorf("%s: nothing to change in objects matching %s", fn.Name(), sel)
+	}
+
+	out, err := k8s.SerializeSpecYAML(result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", fn.Name())
+	}
+	return io.NewBlob(out, yamlSource(yamlValue, fn)), nil
+}
+
+func (s *tiltfileState) unpackK8sObjectArgs(fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+	sel *k8sObjectSelectorArgs, pairs ...interface{}) error {
+	return s.unpackArgs(fn.Name(), args, kwargs, append(pairs, sel.unpackSpec()...)...)
+}
+
+// k8s.select(yaml, labels=None, name=None, namespace=None, kind=None, api_version=None)
+//
+// Like filter_yaml, but only returns the matches.
+func (s *tiltfileState) k8sSelect(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var yamlValue starlark.Value
+	var sel k8sObjectSelectorArgs
+	err := s.unpackK8sObjectArgs(fn, args, kwargs, &sel, "yaml", &yamlValue)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := s.yamlEntitiesFromSkylarkValueOrList(thread, yamlValue)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", fn.Name())
+	}
+
+	matches, err := sel.matcher()
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", fn.Name())
+	}
+
+	var result []k8s.K8sEntity
+	for _, e := range entities {
+		if matches(e) {
+			result = append(result, e)
+		}
+	}
+
+	out, err := k8s.SerializeSpecYAML(result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", fn.Name())
+	}
+	return io.NewBlob(out, yamlSource(yamlValue, fn)), nil
+}
+
+func (s *tiltfileState) k8sSetImage(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var yamlValue starlark.Value
+	var image, container string
+	var sel k8sObjectSelectorArgs
+	err := s.unpackK8sObjectArgs(fn, args, kwargs, &sel,
+		"yaml", &yamlValue,
+		"image", &image,
+		"container?", &container)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.editK8sObjects(thread, fn, yamlValue, &sel, func(e k8s.K8sEntity) (k8s.K8sEntity, bool, error) {
+		return k8s.SetImage(e, container, image)
+	})
+}
+
+func (s *tiltfileState) k8sSetEnv(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var yamlValue starlark.Value
+	var env value.StringStringMap
+	var container string
+	var sel k8sObjectSelectorArgs
+	err := s.unpackK8sObjectArgs(fn, args, kwargs, &sel,
+		"yaml", &yamlValue,
+		"env", &env,
+		"container?", &container)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.editK8sObjects(thread, fn, yamlValue, &sel, func(e k8s.K8sEntity) (k8s.K8sEntity, bool, error) {
+		return k8s.SetEnv(e, container, env)
+	})
+}
+
+func (s *tiltfileState) k8sSetResources(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var yamlValue starlark.Value
+	var requests, limits value.StringStringMap
+	var container string
+	var sel k8sObjectSelectorArgs
+	err := s.unpackK8sObjectArgs(fn, args, kwargs, &sel,
+		"yaml", &yamlValue,
+		"requests?", &requests,
+		"limits?", &limits,
+		"container?", &container)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.editK8sObjects(thread, fn, yamlValue, &sel, func(e k8s.K8sEntity) (k8s.K8sEntity, bool, error) {
+		return k8s.SetResources(e, container, requests, limits)
+	})
+}
+
+func (s *tiltfileState) k8sSetReplicas(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var yamlValue starlark.Value
+	var replicas int
+	var sel k8sObjectSelectorArgs
+	err := s.unpackK8sObjectArgs(fn, args, kwargs, &sel,
+		"yaml", &yamlValue,
+		"replicas", &replicas)
+	if err != nil {
+		return nil, err
+	}
+	if replicas < 0 {
+		return nil, fmt.Errorf("%s: replicas must be non-negative, got %d", fn.Name(), replicas)
+	}
+
+	return s.editK8sObjects(thread, fn, yamlValue, &sel, func(e k8s.K8sEntity) (k8s.K8sEntity, bool, error) {
+		return k8s.SetReplicas(e, int32(replicas))
+	})
+}
+
+func (s *tiltfileState) k8sSetAnnotations(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var yamlValue starlark.Value
+	var annotations value.StringStringMap
+	var sel k8sObjectSelectorArgs
+	err := s.unpackK8sObjectArgs(fn, args, kwargs, &sel,
+		"yaml", &yamlValue,
+		"annotations", &annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.editK8sObjects(thread, fn, yamlValue, &sel, func(e k8s.K8sEntity) (k8s.K8sEntity, bool, error) {
+		e, changed := k8s.SetAnnotations(e, annotations)
+		return e, changed, nil
+	})
+}
+
+// k8s.patch(yaml, patch, type='strategic', ...)
+//
+// The patch may be a dict (or list, for JSON patches), or a string or blob
+// of YAML or JSON.
+func (s *tiltfileState) k8sPatch(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var yamlValue, patchValue starlark.Value
+	patchType := string(k8s.PatchTypeStrategic)
+	var sel k8sObjectSelectorArgs
+	err := s.unpackK8sObjectArgs(fn, args, kwargs, &sel,
+		"yaml", &yamlValue,
+		"patch", &patchValue,
+		"type?", &patchType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k8s.PatchType(patchType) {
+	case k8s.PatchTypeStrategic, k8s.PatchTypeMerge, k8s.PatchTypeJSON:
+	default:
+		return nil, fmt.Errorf("%s: type must be one of 'strategic', 'merge', or 'json', got %q", fn.Name(), patchType)
+	}
+
+	patch, err := patchToJSON(patchValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: for parameter %q: %v", fn.Name(), "patch", err)
+	}
+
+	return s.editK8sObjects(thread, fn, yamlValue, &sel, func(e k8s.K8sEntity) (k8s.K8sEntity, bool, error) {
+		patched, err := k8s.Patch(e, k8s.PatchType(patchType), patch)
+		if err != nil {
+			return k8s.K8sEntity{}, false, errors.Wrapf(err, "%s %s", e.GVK().Kind, e.Name())
+		}
+		return patched, true, nil
+	})
+}
+
+func patchToJSON(v starlark.Value) ([]byte, error) {
+	switch v := v.(type) {
+	case *starlark.Dict, *starlark.List:
+		data, err := encoding.ConvertStarlarkToStructuredData(v)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(data)
+	case io.Blob:
+		return yaml.YAMLToJSON([]byte(v.Text))
+	case starlark.String:
+		return yaml.YAMLToJSON([]byte(v.GoString()))
+	}
+	return nil, fmt.Errorf("expected dict | list | string | blob. Actual type: %s", v.Type())
+}
@@ -0,0 +1,124 @@
+package tiltfile
+
+import (
+	"testing"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/internal/yaml"
+)
+
+func TestK8sSelect(t *testing.T) {
+	f := newFixture(t)
+	f.file("k8s.yaml", yaml.ConcatYAML(
+		testyaml.DoggosDeploymentYaml, testyaml.DoggosServiceYaml,
+		testyaml.SnackYaml, testyaml.SanchoYAML))
+	f.file("Tiltfile", `
+k8s_yaml(k8s.select('k8s.yaml', labels={'app': 'doggos'}, kind='deployment'))
+`)
+
+	f.load()
+	f.assertNextManifest("doggos", deployment("doggos"))
+	f.assertNoMoreManifests()
+}
+
+func TestK8sSetImage(t *testing.T) {
+	f := newFixture(t)
+	f.file("k8s.yaml", yaml.ConcatYAML(testyaml.DoggosDeploymentYaml, testyaml.SnackYaml))
+	f.file("Tiltfile", `
+k8s_yaml(k8s.set_image('k8s.yaml', 'gcr.io/doggos:dev', name='doggos'))
+`)
+
+	f.load()
+	f.assertNextManifest("doggos", deployment("doggos", image("gcr.io/doggos:dev")))
+	f.assertNextManifest("snack", deployment("snack", image(testyaml.SnackImage)))
+	f.assertNoMoreManifests()
+}
+
+func TestK8sSetImageAmbiguous(t *testing.T) {
+	f := newFixture(t)
+	f.file("k8s.yaml", testyaml.SanchoSidecarYAML)
+	f.file("Tiltfile", `
+k8s_yaml(k8s.set_image('k8s.yaml', 'sancho:dev'))
+`)
+
+	f.loadErrString("k8s.set_image: Deployment sancho has 2 containers; specify which container")
+}
+
+func TestK8sSetEnv(t *testing.T) {
+	f := newFixture(t)
+	f.file("k8s.yaml", testyaml.SanchoYAML)
+	f.file("Tiltfile", `
+k8s_yaml(k8s.set_env('k8s.yaml', {'LOG_LEVEL': 'debug'}, container='sancho'))
+`)
+
+	f.load()
+	f.assertNextManifest("sancho", deployment("sancho", withEnvVars("token", "", "LOG_LEVEL", "debug")))
+}
+
+func TestK8sSetResourcesReplicasAnnotations(t *testing.T) {
+	f := newFixture(t)
+	f.file("k8s.yaml", yaml.ConcatYAML(testyaml.SanchoYAML, testyaml.DoggosServiceYaml))
+	f.file("Tiltfile", `
+objs = k8s.set_resources('k8s.yaml', requests={'cpu': '100m'}, limits={'memory': '1Gi'})
+objs = k8s.set_replicas(objs, 3, kind='deployment')
+objs = k8s.set_annotations(objs, {'team': 'dogs'}, kind='service')
+
+d, svc = decode_yaml_stream(objs)
+container = d['spec']['template']['spec']['containers'][0]
+if container['resources'] != {'requests': {'cpu': '100m'}, 'limits': {'memory': '1Gi'}}:
+  fail('resources: %s' % container['resources'])
+if d['spec']['replicas'] != 3:
+  fail('replicas: %s' % d['spec']['replicas'])
+if svc['metadata']['annotations'] != {'team': 'dogs'}:
+  fail('annotations: %s' % svc['metadata']['annotations'])
+
+k8s_yaml(objs)
+`)
+
+	f.load()
+}
+
+func TestK8sSetNothingMatches(t *testing.T) {
+	f := newFixture(t)
+	f.file("k8s.yaml", testyaml.SanchoYAML)
+	f.file("Tiltfile", `
+k8s_yaml(k8s.set_replicas('k8s.yaml', 3, name='santa', labels={'app': 'sancho'}))
+`)
+
+	f.loadErrString(`k8s.set_replicas: nothing to change in objects matching name="santa", labels["app"]="sancho"`)
+}
+
+func TestK8sPatch(t *testing.T) {
+	f := newFixture(t)
+	f.file("k8s.yaml", testyaml.SanchoSidecarYAML)
+	f.file("Tiltfile", `
+objs = k8s.patch('k8s.yaml', {
+  'spec': {'template': {'spec': {'containers': [{'name': 'sancho-sidecar', 'image': 'sidecar:dev'}]}}},
+})
+objs = k8s.patch(objs, '[{"op": "add", "path": "/metadata/labels/env", "value": "dev"}]', type='json')
+objs = k8s.patch(objs, blob("spec:\n  paused: true\n"), type='merge')
+
+d = decode_yaml(objs)
+containers = d['spec']['template']['spec']['containers']
+if [c['image'] for c in containers] != ['gcr.io/some-project-162817/sancho', 'sidecar:dev']:
+  fail('containers: %s' % containers)
+if d['metadata']['labels']['env'] != 'dev':
+  fail('labels: %s' % d['metadata']['labels'])
+if d['spec']['paused'] != True:
+  fail('paused: %s' % d['spec'])
+
+k8s_yaml(objs)
+`)
+
+	f.load()
+}
+
+func TestK8sPatchBadType(t *testing.T) {
+	f := newFixture(t)
+	f.file("k8s.yaml", testyaml.SanchoYAML)
+	f.file("Tiltfile", `
+k8s_yaml(k8s.patch('k8s.yaml', {}, type='yolo'))
+`)
+
+	f.loadErrString(`k8s.patch: type must be one of 'strategic', 'merge', or 'json', got "yolo"`)
+}
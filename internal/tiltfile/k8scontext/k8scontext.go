@@ -4,8 +4,10 @@ import (
 	"fmt"
 
 	"go.starlark.net/starlark"
+	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/tilt-dev/clusterid"
+
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
@@ -17,18 +19,39 @@ import (
 // Exposes an API for other plugins to get and validate the allowed k8s context.
 type Plugin struct {
 	context k8s.KubeContext
-	env     clusterid.Product
+	profile k8s.ClusterProfile
+
+	// Used to check the other contexts that the Tiltfile
+	// deploys to with k8s_cluster().
+	kubeconfig    *api.Config
+	clusterConfig k8s.ClusterConfig
 }
 
-func NewPlugin(context k8s.KubeContext, env clusterid.Product) Plugin {
+func NewPlugin(context k8s.KubeContext, profile k8s.ClusterProfile) Plugin {
 	return Plugin{
 		context: context,
-		env:     env,
+		profile: profile,
+	}
+}
+
+// Like NewPlugin, but also reads the kubeconfig and clusters.yaml,
+// so that we can tell whether other contexts are dev clusters.
+func ProvidePlugin(context k8s.KubeContext, profile k8s.ClusterProfile, configOrError k8s.APIConfigOrError, clusterConfig k8s.ClusterConfig) Plugin {
+	return Plugin{
+		context:       context,
+		profile:       profile,
+		kubeconfig:    configOrError.Config,
+		clusterConfig: clusterConfig,
 	}
 }
 
 func (e Plugin) NewState() interface{} {
-	return State{context: e.context, env: e.env}
+	return State{
+		context:       e.context,
+		profile:       e.profile,
+		kubeconfig:    e.kubeconfig,
+		clusterConfig: e.clusterConfig,
+	}
 }
 
 func (e Plugin) OnStart(env *starkit.Environment) error {
@@ -45,7 +68,36 @@ func (e Plugin) OnStart(env *starkit.Environment) error {
 }
 
 func (e Plugin) k8sContext(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	return starlark.String(e.context), nil
+	model, err := starkit.ModelFromThread(thread)
+	if err != nil {
+		return nil, err
+	}
+	state, err := GetState(model)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(state.context), nil
+}
+
+// Overrides the kube context for the rest of the execution.
+//
+// Tiltfile tests use this to fake the cluster.
+func SetKubeContext(t *starlark.Thread, context k8s.KubeContext) error {
+	return starkit.SetState(t, func(existing State) State {
+		existing.context = context
+		return existing
+	})
+}
+
+// Points the rest of the execution at a cluster that the Tiltfile provisions,
+// so that k8s_context() and the allow_k8s_contexts check are about the
+// cluster we'll deploy to, not the one that was current at startup.
+func SetProvisionedCluster(t *starlark.Thread, context k8s.KubeContext, product clusterid.Product) error {
+	return starkit.SetState(t, func(existing State) State {
+		existing.context = context
+		existing.profile = k8s.ProfileForProduct(product)
+		return existing
+	})
 }
 
 func (e Plugin) allowK8sContexts(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -68,11 +120,8 @@ func (e Plugin) allowK8sContexts(thread *starlark.Thread, fn *starlark.Builtin,
 	}
 
 	err := starkit.SetState(thread, func(existing State) State {
-		return State{
-			context: existing.context,
-			env:     existing.env,
-			allowed: append(newContexts, existing.allowed...),
-		}
+		existing.allowed = append(newContexts, existing.allowed...)
+		return existing
 	})
 
 	return starlark.None, err
@@ -82,8 +131,11 @@ var _ starkit.StatefulPlugin = &Plugin{}
 
 type State struct {
 	context k8s.KubeContext
-	env     clusterid.Product
+	profile k8s.ClusterProfile
 	allowed []k8s.KubeContext
+
+	kubeconfig    *api.Config
+	clusterConfig k8s.ClusterConfig
 }
 
 func (s State) KubeContext() k8s.KubeContext {
@@ -92,8 +144,8 @@ func (s State) KubeContext() k8s.KubeContext {
 
 // Returns whether we're allowed to deploy to this kubecontext.
 //
-// Checks against a manually specified list and a baked-in list
-// with known dev cluster names.
+// Checks against a manually specified list, a baked-in list
+// with known dev cluster names, and clusters the user declared as dev clusters.
 //
 // Currently, only the tiltfile executor knows about "allowed" kubecontexts.
 //
@@ -106,16 +158,34 @@ func (s State) KubeContext() k8s.KubeContext {
 // A more compatible solution would be to have api server objects
 // for the kubecontexts that tilt is aware of, and ways to mark them safe.
 func (s State) IsAllowed(tf *v1alpha1.Tiltfile) bool {
+	return s.isAllowed(tf, s.context, s.profile)
+}
+
+// Returns whether we're allowed to deploy to a cluster declared
+// with k8s_cluster(), by the same rules as IsAllowed.
+//
+// The cluster's context doesn't have to be the current one,
+// so we detect its profile from the kubeconfig.
+func (s State) IsClusterAllowed(tf *v1alpha1.Tiltfile, conn *v1alpha1.KubernetesClusterConnection) bool {
+	context := k8s.KubeContext(conn.Context)
+	profile := s.profile
+	if context != s.context {
+		profile = k8s.ClusterProfileForContext(s.kubeconfig, s.clusterConfig, context, conn)
+	}
+	return s.isAllowed(tf, context, profile)
+}
+
+func (s State) isAllowed(tf *v1alpha1.Tiltfile, context k8s.KubeContext, profile k8s.ClusterProfile) bool {
 	if tf.Name != model.MainTiltfileManifestName.String() {
 		return true
 	}
 
-	if s.env == k8s.ProductNone || s.env.IsDevCluster() {
+	if profile.Product == k8s.ProductNone || profile.DevCluster {
 		return true
 	}
 
 	for _, c := range s.allowed {
-		if c == s.context {
+		if c == context {
 			return true
 		}
 	}
@@ -4,10 +4,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/tilt-dev/clusterid"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 )
 
 func TestAllowK8sContext(t *testing.T) {
@@ -38,6 +40,62 @@ func TestForbidK8sContext(t *testing.T) {
 	assert.True(t, MustState(model).IsAllowed(f.Tiltfile()))
 }
 
+func TestAllowDeclaredDevCluster(t *testing.T) {
+	profile := k8s.ProfileForProduct(clusterid.ProductUnknown)
+	assert.False(t, profile.DevCluster)
+
+	profile.DevCluster = true
+	f := starkit.NewFixture(t, NewPlugin("talos-dev", profile))
+	f.File("Tiltfile", `
+`)
+	model, err := f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+	assert.True(t, MustState(model).IsAllowed(f.Tiltfile()))
+}
+
+func TestAllowDetectedDevCluster(t *testing.T) {
+	for _, p := range []clusterid.Product{k8s.ProductVCluster, k8s.ProductOrbStack} {
+		t.Run(string(p), func(t *testing.T) {
+			f := NewFixture(t, "my-context", p)
+			f.File("Tiltfile", `
+`)
+			model, err := f.ExecFile("Tiltfile")
+			assert.NoError(t, err)
+			assert.True(t, MustState(model).IsAllowed(f.Tiltfile()))
+		})
+	}
+}
+
+func TestClusterAllowed(t *testing.T) {
+	kubeconfig := &api.Config{
+		CurrentContext: "kind-kind",
+		Contexts: map[string]*api.Context{
+			"kind-kind": {Cluster: "kind-kind"},
+			"kind-edge": {Cluster: "kind-edge"},
+			"gke-blorg": {Cluster: "gke_blorg"},
+		},
+	}
+	f := starkit.NewFixture(t, ProvidePlugin("kind-kind", k8s.ProfileForProduct(clusterid.ProductKIND),
+		k8s.APIConfigOrError{Config: kubeconfig}, k8s.ClusterConfig{}))
+	f.File("Tiltfile", `
+`)
+	model, err := f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+
+	state := MustState(model)
+	assert.True(t, state.IsAllowed(f.Tiltfile()))
+	assert.True(t, state.IsClusterAllowed(f.Tiltfile(), &v1alpha1.KubernetesClusterConnection{Context: "kind-edge"}))
+	assert.False(t, state.IsClusterAllowed(f.Tiltfile(), &v1alpha1.KubernetesClusterConnection{Context: "gke-blorg"}))
+	assert.False(t, state.IsClusterAllowed(f.Tiltfile(), &v1alpha1.KubernetesClusterConnection{Context: "missing"}))
+
+	f.File("Tiltfile", `
+allow_k8s_contexts('gke-blorg')
+`)
+	model, err = f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+	assert.True(t, MustState(model).IsClusterAllowed(f.Tiltfile(), &v1alpha1.KubernetesClusterConnection{Context: "gke-blorg"}))
+}
+
 func NewFixture(tb testing.TB, ctx k8s.KubeContext, env clusterid.Product) *starkit.Fixture {
-	return starkit.NewFixture(tb, NewPlugin(ctx, env))
+	return starkit.NewFixture(tb, NewPlugin(ctx, k8s.ProfileForProduct(env)))
 }
@@ -0,0 +1,103 @@
+package tiltfile
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const localCacheDir = "tiltfile/local"
+
+// Stores the stdout of local(..., cache_inputs=[...]) under the xdg cache
+// dir, so that it survives Tiltfile reloads and Tilt restarts.
+//
+// There's one entry per command, which remembers the hash of the inputs
+// from the last run. So the cache doesn't grow as the inputs change.
+type localCache struct {
+	base xdg.Base
+}
+
+type localCacheEntry struct {
+	InputsSHA256 string `json:"inputsSHA256"`
+	Stdout       string `json:"stdout"`
+}
+
+// Identifies a command by everything that could change its output,
+// except for its inputs.
+func localCacheKey(cmd model.Cmd, stdin *string) (string, error) {
+	b, err := json.Marshal(struct {
+		Argv  []string
+		Dir   string
+		Env   []string
+		Stdin *string
+	}{cmd.Argv, cmd.Dir, cmd.Env, stdin})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+func (c localCache) path(key string) (string, error) {
+	return c.base.CacheFile(filepath.Join(localCacheDir, key+".json"))
+}
+
+func (c localCache) get(key string, inputsSHA256 string) (string, bool, error) {
+	p, err := c.path(key)
+	if err != nil {
+		return "", false, err
+	}
+
+	contents, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	var entry localCacheEntry
+	err = json.Unmarshal(contents, &entry)
+	if err != nil {
+		return "", false, fmt.Errorf("reading %s: %v", p, err)
+	}
+	if entry.InputsSHA256 != inputsSHA256 {
+		return "", false, nil
+	}
+	return entry.Stdout, true, nil
+}
+
+func (c localCache) put(key string, entry localCacheEntry) error {
+	p, err := c.path(key)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename, so that a concurrent reader
+	// never sees half an entry.
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(contents)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package tiltfile
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const cachedLocalTiltfile = `
+out = local('echo run >> runs.txt && cat inputs/*', cache_inputs=['inputs', 'Chart.yaml'])
+watch_settings(ignore=[str(out).strip()])
+`
+
+func (f *fixture) assertLocalRuns(expected int) {
+	f.t.Helper()
+	runs := strings.Count(f.ReadFile("runs.txt"), "run")
+	assert.Equal(f.t, expected, runs, "number of times local() ran")
+}
+
+func (f *fixture) assertLocalOutput(expected string) {
+	f.t.Helper()
+	assert.Equal(f.t, []string{expected}, f.loadResult.WatchSettings.Ignores[0].Patterns)
+}
+
+func TestLocalCacheInputs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	f := newFixture(t)
+	f.file("inputs/a.txt", "hello")
+	f.file("Tiltfile", cachedLocalTiltfile)
+
+	f.load()
+	f.assertLocalRuns(1)
+	f.assertLocalOutput("hello")
+	assert.NotContains(t, f.out.String(), "[cached]")
+
+	f.load()
+	f.assertLocalRuns(1)
+	f.assertLocalOutput("hello")
+	assert.Contains(t, f.out.String(), "local: echo run >> runs.txt && cat inputs/* [cached]")
+	assert.Contains(t, f.out.String(), " → hello")
+
+	f.file("inputs/a.txt", "goodbye")
+	f.load()
+	f.assertLocalRuns(2)
+	f.assertLocalOutput("goodbye")
+
+	// A new file, even one that doesn't change the output, is a new input.
+	f.file("inputs/b.txt", "")
+	f.load()
+	f.assertLocalRuns(3)
+
+	// So is a file that didn't exist before.
+	f.file("Chart.yaml", "name: foo")
+	f.load()
+	f.assertLocalRuns(4)
+
+	f.load()
+	f.assertLocalRuns(4)
+}
+
+func TestLocalCacheInputsCommandChanged(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	f := newFixture(t)
+	f.file("inputs/a.txt", "hello")
+	f.file("Tiltfile", cachedLocalTiltfile)
+
+	f.load()
+	f.assertLocalRuns(1)
+
+	f.file("Tiltfile", `
+out = local('echo run >> runs.txt && cat inputs/*', cache_inputs=['inputs', 'Chart.yaml'], env={'FOO': 'bar'})
+watch_settings(ignore=[str(out).strip()])
+`)
+	f.load()
+	f.assertLocalRuns(2)
+}
+
+func TestLocalCacheInputsFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	f := newFixture(t)
+	f.file("inputs/a.txt", "hello")
+	f.file("Tiltfile", `
+local('echo run >> runs.txt && exit 1', cache_inputs=['inputs'])
+`)
+
+	f.loadErrString("exit status 1")
+	f.loadErrString("exit status 1")
+	f.assertLocalRuns(2)
+}
+
+func TestLocalCacheInputsWritesToInputs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	f := newFixture(t)
+	f.file("chart/Chart.yaml", "name: foo")
+	f.file("Tiltfile", `
+local('echo run >> runs.txt && echo dep > chart/charts.lock', cache_inputs=['chart'])
+`)
+
+	f.load()
+	f.assertLocalRuns(1)
+
+	f.load()
+	f.assertLocalRuns(1)
+}
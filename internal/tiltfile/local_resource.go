@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	"go.starlark.net/starlark"
 
+	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
 	"github.com/tilt-dev/tilt/internal/tiltfile/links"
 	"github.com/tilt-dev/tilt/internal/tiltfile/probe"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
@@ -46,6 +47,7 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 	var updateCmdDirVal, serveCmdDirVal starlark.Value
 
 	deps := value.NewLocalPathListUnpacker(thread)
+	dotenvFile := value.NewLocalPathUnpacker(thread)
 
 	var resourceDepsVal starlark.Sequence
 	var ignoresVal starlark.Value
@@ -78,10 +80,20 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 		"readiness_probe?", &readinessProbe,
 		"dir?", &updateCmdDirVal,
 		"serve_dir?", &serveCmdDirVal,
+		"dotenv_file?", &dotenvFile,
 	); err != nil {
 		return nil, err
 	}
 
+	if dotenvFile.Value != "" {
+		dotenv, err := encoding.ReadDotenv(thread, dotenvFile.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: dotenv_file", fn.Name())
+		}
+		updateEnv = withDotenv(updateEnv, dotenv)
+		serveEnv = withDotenv(serveEnv, dotenv)
+	}
+
 	resourceDeps, err := value.SequenceToStringSlice(resourceDepsVal)
 	if err != nil {
 		return nil, errors.Wrapf(err, "%s: resource_deps", fn.Name())
@@ -137,3 +149,15 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 
 	return starlark.None, nil
 }
+
+// Variables set explicitly with env= take precedence over the dotenv file.
+func withDotenv(env value.StringStringMap, dotenv map[string]string) value.StringStringMap {
+	result := make(value.StringStringMap, len(env)+len(dotenv))
+	for k, v := range dotenv {
+		result[k] = v
+	}
+	for k, v := range env {
+		result[k] = v
+	}
+	return result
+}
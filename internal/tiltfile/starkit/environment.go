@@ -26,7 +26,25 @@ func init() {
 // The main entrypoint to starkit.
 // Execute a file with a set of starlark plugins.
 func ExecFile(tf *v1alpha1.Tiltfile, plugins ...Plugin) (Model, error) {
-	return newEnvironment(plugins...).start(tf)
+	return newEnvironment(plugins...).start(tf, nil)
+}
+
+// Execute a file, then call one of its top-level functions with no arguments.
+//
+// Used to run the test_* functions in Tiltfile unit tests.
+func CallFunction(tf *v1alpha1.Tiltfile, fnName string, plugins ...Plugin) (Model, error) {
+	return newEnvironment(plugins...).start(tf, func(t *starlark.Thread, globals starlark.StringDict) error {
+		fn, ok := globals[fnName]
+		if !ok {
+			return fmt.Errorf("%s: no function named %s", tf.Spec.Path, fnName)
+		}
+		callable, ok := fn.(starlark.Callable)
+		if !ok {
+			return fmt.Errorf("%s: %s is a %s, not a function", tf.Spec.Path, fnName, fn.Type())
+		}
+		_, err := starlark.Call(t, callable, nil, nil)
+		return err
+	})
 }
 
 const argUnpackerKey = "starkit.ArgUnpacker"
@@ -63,6 +81,8 @@ type Environment struct {
 	loadInterceptors []LoadInterceptor
 
 	builtinCalls []BuiltinCall
+	profiler     *profiler
+	futures      *futureSet
 }
 
 func NewThread(ctx context.Context, model Model) *starlark.Thread {
@@ -80,6 +100,7 @@ func newEnvironment(plugins ...Plugin) *Environment {
 		predeclared:    starlark.StringDict{},
 		fakeFileSystem: nil,
 		builtinCalls:   []BuiltinCall{},
+		futures:        newFutureSet(),
 	}
 }
 
@@ -110,6 +131,11 @@ func (e *Environment) AddBuiltin(name string, f Function) error {
 			}
 		}
 
+		if e.profiler != nil {
+			e.profiler.builtinStart(thread)
+			defer e.profiler.builtinEnd(thread)
+		}
+
 		start := time.Now()
 		defer func() {
 			e.builtinCalls = append(e.builtinCalls, BuiltinCall{
@@ -118,7 +144,12 @@ func (e *Environment) AddBuiltin(name string, f Function) error {
 				Dur:  time.Since(start),
 			})
 		}()
-		return f(thread, fn, args, kwargs)
+
+		awaitedArgs, awaitedKwargs, err := awaitArgs(thread, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return f(thread, fn, awaitedArgs, awaitedKwargs)
 	})
 
 	return e.AddValue(name, wrapped)
@@ -177,10 +208,12 @@ func (e *Environment) newThread(model Model) *starlark.Thread {
 	t.Print = e.print
 	t.SetLocal(argUnpackerKey, e.unpackArgs)
 	t.SetLocal(startTfKey, e.startTf)
+	t.SetLocal(profilerKey, e.profiler)
+	t.SetLocal(futuresKey, e.futures)
 	return t
 }
 
-func (e *Environment) start(tf *v1alpha1.Tiltfile) (Model, error) {
+func (e *Environment) start(tf *v1alpha1.Tiltfile, afterExec func(t *starlark.Thread, globals starlark.StringDict) error) (Model, error) {
 	// NOTE(dmiller): we only call Abs here because it's the root of the stack
 	path, err := filepath.Abs(tf.Spec.Path)
 	if err != nil {
@@ -201,9 +234,29 @@ func (e *Environment) start(tf *v1alpha1.Tiltfile) (Model, error) {
 		}
 	}
 
+	if profilingEnabled(e.ctx) {
+		e.profiler = newProfiler(path)
+	}
 	t := e.newThread(model)
-	_, err = e.exec(t, path)
+	globals, err := e.exec(t, path)
+	if err == nil && afterExec != nil {
+		// Resolve relative paths as if we were still executing the main file.
+		t.SetLocal(execingTiltfileKey, path)
+		err = afterExec(t, globals)
+	}
+
+	// Wait for background work even if execution failed, so that nothing
+	// outlives the Tiltfile.
+	futuresErr := e.futures.wait()
+	if err == nil {
+		err = futuresErr
+	}
+
 	model.BuiltinCalls = e.builtinCalls
+	if e.profiler != nil {
+		e.profiler.finish()
+		model.Profile = e.profiler.profile()
+	}
 	if errors.Is(err, ErrStopExecution) {
 		return model, nil
 	}
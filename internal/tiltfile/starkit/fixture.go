@@ -83,7 +83,7 @@ func (f *Fixture) ExecFile(name string) (Model, error) {
 		env.AddLoadInterceptor(i)
 	}
 	f.tf.Spec.Path = filepath.Join(f.path, name)
-	return env.start(f.tf)
+	return env.start(f.tf, nil)
 }
 
 func (f *Fixture) SetLoadInterceptor(i LoadInterceptor) {
@@ -0,0 +1,223 @@
+package starkit
+
+import (
+	"runtime"
+	"sync"
+
+	"go.starlark.net/starlark"
+)
+
+const futuresKey = "starkit.Futures"
+
+// A value that's still being computed in the background.
+//
+// Builtins never see Awaitables. Before calling a builtin, starkit waits
+// for any Awaitables in its arguments (including inside lists and dicts),
+// and passes their values instead.
+type Awaitable interface {
+	starlark.Value
+	Await() (starlark.Value, error)
+}
+
+// The result of a function running in the background. See Go.
+type Future struct {
+	done  chan struct{}
+	value starlark.Value
+	err   error
+
+	onAwait   func()
+	awaitOnce sync.Once
+
+	// The thread that started the future, if it's running in an Environment.
+	thread *starlark.Thread
+}
+
+// Blocks until the result is ready.
+//
+// Only the thread that started the future awaits it.
+func (f *Future) Await() (starlark.Value, error) {
+	<-f.done
+	if f.onAwait != nil {
+		f.awaitOnce.Do(f.onAwait)
+	}
+	return f.value, f.err
+}
+
+// Like Await, for callers that can't return an error (e.g., String()).
+//
+// If the future failed, cancels the thread that started it, so that the
+// Tiltfile fails where it used the value, rather than carrying on with an
+// empty one.
+func (f *Future) AwaitOrCancel() starlark.Value {
+	v, err := f.Await()
+	if err != nil && f.thread != nil {
+		f.thread.Cancel(err.Error())
+	}
+	return v
+}
+
+// Every future started during execution, so that we can wait for them
+// when execution ends.
+type futureSet struct {
+	mu      sync.Mutex
+	futures []*Future
+
+	// Limits how many futures run at once, so that a Tiltfile with
+	// a hundred helm charts doesn't start a hundred processes.
+	sem chan struct{}
+}
+
+func newFutureSet() *futureSet {
+	// Futures mostly wait on subprocesses, so allow a few even on
+	// small machines.
+	n := runtime.NumCPU()
+	if n < 4 {
+		n = 4
+	}
+	return &futureSet{sem: make(chan struct{}, n)}
+}
+
+func (s *futureSet) add(f *Future) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.futures = append(s.futures, f)
+}
+
+// Waits for every future, and returns the first error, in the order
+// the futures were started.
+func (s *futureSet) wait() error {
+	s.mu.Lock()
+	futures := append([]*Future{}, s.futures...)
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, f := range futures {
+		_, err := f.Await()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Runs fn in the background, and returns a Future for its result.
+//
+// fn runs on another goroutine, so it must not use the thread.
+// If onAwait is non-nil, it runs on the thread the first time the result
+// is awaited (e.g., to log output that fn buffered).
+//
+// Execution doesn't finish until fn does. If fn fails, execution fails,
+// even if nothing awaited the result.
+func Go(t *starlark.Thread, fn func() (starlark.Value, error), onAwait func()) *Future {
+	f := &Future{done: make(chan struct{}), onAwait: onAwait}
+
+	set, ok := t.Local(futuresKey).(*futureSet)
+	if !ok || set == nil {
+		// Not running in an Environment, so there's nothing to wait for us.
+		f.value, f.err = fn()
+		close(f.done)
+		return f
+	}
+
+	f.thread = t
+	set.add(f)
+	go func() {
+		set.sem <- struct{}{}
+		defer func() { <-set.sem }()
+
+		f.value, f.err = fn()
+		close(f.done)
+	}()
+	return f
+}
+
+// Replaces any Awaitables in the args with their values.
+func awaitArgs(t *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Tuple, []starlark.Tuple, error) {
+	newArgs, _, err := awaitValue(t, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var newKwargs []starlark.Tuple
+	for _, kwarg := range kwargs {
+		newKwarg, _, err := awaitValue(t, kwarg)
+		if err != nil {
+			return nil, nil, err
+		}
+		newKwargs = append(newKwargs, newKwarg.(starlark.Tuple))
+	}
+	return newArgs.(starlark.Tuple), newKwargs, nil
+}
+
+// Replaces any Awaitables in v with their values, and reports whether
+// there were any.
+//
+// Lists and dicts that contain Awaitables are copied, rather than modified,
+// because they may be frozen.
+func awaitValue(t *starlark.Thread, v starlark.Value) (starlark.Value, bool, error) {
+	switch v := v.(type) {
+	case Awaitable:
+		endSpan := StartProfileSpan(t, "await")
+		defer endSpan()
+		result, err := v.Await()
+		return result, true, err
+
+	case starlark.Tuple:
+		items, changed, err := awaitItems(t, v)
+		if err != nil || !changed {
+			return v, false, err
+		}
+		return starlark.Tuple(items), true, nil
+
+	case *starlark.List:
+		items := make([]starlark.Value, v.Len())
+		for i := range items {
+			items[i] = v.Index(i)
+		}
+		items, changed, err := awaitItems(t, items)
+		if err != nil || !changed {
+			return v, false, err
+		}
+		return starlark.NewList(items), true, nil
+
+	case *starlark.Dict:
+		anyChanged := false
+		result := starlark.NewDict(v.Len())
+		for _, item := range v.Items() {
+			value, changed, err := awaitValue(t, item[1])
+			if err != nil {
+				return nil, false, err
+			}
+			anyChanged = anyChanged || changed
+			err = result.SetKey(item[0], value)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		if !anyChanged {
+			return v, false, nil
+		}
+		return result, true, nil
+	}
+	return v, false, nil
+}
+
+func awaitItems(t *starlark.Thread, items []starlark.Value) ([]starlark.Value, bool, error) {
+	var result []starlark.Value
+	for i, item := range items {
+		value, changed, err := awaitValue(t, item)
+		if err != nil {
+			return nil, false, err
+		}
+		if changed && result == nil {
+			result = append([]starlark.Value{}, items...)
+		}
+		if result != nil {
+			result[i] = value
+		}
+	}
+	if result == nil {
+		return items, false, nil
+	}
+	return result, true, nil
+}
@@ -0,0 +1,150 @@
+package starkit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/starlark"
+)
+
+type lazyString struct {
+	*Future
+}
+
+func (s lazyString) String() string        { return "lazy" }
+func (s lazyString) Type() string          { return "lazy" }
+func (s lazyString) Freeze()               {}
+func (s lazyString) Truth() starlark.Bool  { return true }
+func (s lazyString) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable") }
+
+// Adds:
+//   - compute(value, ms, fail=False), which returns a lazyString that
+//     resolves to value after ms.
+//   - show(value), which returns its argument as a string, so tests can
+//     check what builtins see.
+type futurePlugin struct {
+	awaited []string
+}
+
+func (p *futurePlugin) OnStart(env *Environment) error {
+	err := env.AddBuiltin("compute", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var value string
+		var ms int
+		fail := false
+		err := UnpackArgs(thread, fn.Name(), args, kwargs, "value", &value, "ms", &ms, "fail?", &fail)
+		if err != nil {
+			return nil, err
+		}
+
+		return lazyString{Go(thread, func() (starlark.Value, error) {
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+			if fail {
+				return nil, fmt.Errorf("computing %s failed", value)
+			}
+			return starlark.String(value), nil
+		}, func() {
+			p.awaited = append(p.awaited, value)
+		})}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return env.AddBuiltin("show", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var v starlark.Value
+		err := UnpackArgs(thread, fn.Name(), args, kwargs, "value", &v)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.String(v.String()), nil
+	})
+}
+
+func TestFutureAwaitedByBuiltins(t *testing.T) {
+	f := NewFixture(t, &futurePlugin{})
+	f.File("Tiltfile", `
+a = compute('a', 10)
+b = compute('b', 10)
+print(show(a))
+print(show([a, b]))
+print(show({'a': a, 'b': (b, 'c')}))
+print(show(value=a))
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, `"a"
+["a", "b"]
+{"a": "a", "b": ("b", "c")}
+"a"
+`, f.PrintOutput())
+}
+
+func TestFutureDoesNotModifyArgs(t *testing.T) {
+	f := NewFixture(t, &futurePlugin{})
+	f.File("Tiltfile", `
+l = [compute('a', 0)]
+show(l)
+print(type(l[0]))
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, "lazy\n", f.PrintOutput())
+}
+
+func TestFutureRunsConcurrently(t *testing.T) {
+	f := NewFixture(t, &futurePlugin{})
+	f.File("Tiltfile", `
+a = compute('a', 200)
+b = compute('b', 200)
+show([a, b])
+`)
+
+	start := time.Now()
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 390*time.Millisecond)
+}
+
+func TestFutureErrorOnAwait(t *testing.T) {
+	f := NewFixture(t, &futurePlugin{})
+	f.File("Tiltfile", `
+a = compute('a', 0, fail=True)
+show(a)
+print('unreachable')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "computing a failed")
+	assert.NotContains(t, f.PrintOutput(), "unreachable")
+}
+
+func TestFutureErrorWithoutAwait(t *testing.T) {
+	f := NewFixture(t, &futurePlugin{})
+	f.File("Tiltfile", `
+compute('a', 0)
+compute('b', 10, fail=True)
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "computing b failed")
+}
+
+func TestFutureWaitsAtEnd(t *testing.T) {
+	p := &futurePlugin{}
+	f := NewFixture(t, p)
+	f.File("Tiltfile", `
+compute('a', 50)
+show(compute('b', 0))
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "a"}, p.awaited)
+}
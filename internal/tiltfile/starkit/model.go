@@ -14,6 +14,7 @@ type Model struct {
 	state map[reflect.Type]interface{}
 
 	BuiltinCalls []BuiltinCall
+	Profile      Profile
 }
 
 func NewModel(plugins ...Plugin) (Model, error) {
@@ -0,0 +1,212 @@
+package starkit
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+const profilerKey = "starkit.Profiler"
+
+type profilingKey struct{}
+
+// Turns on profiling for Tiltfiles executed with this context.
+//
+// Profiling hooks into every builtin call, so it's off by default.
+func WithProfiling(ctx context.Context) context.Context {
+	return context.WithValue(ctx, profilingKey{}, true)
+}
+
+func profilingEnabled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	enabled, _ := ctx.Value(profilingKey{}).(bool)
+	return enabled
+}
+
+// A frame in a profiled call stack.
+type ProfileFrame struct {
+	// The name of the function or builtin.
+	//
+	// For the top level of a file, the path to the file.
+	// For time spent outside the interpreter (e.g., in a subprocess),
+	// a description of what it was doing.
+	Name string
+
+	// The file where the function is defined, if it's defined in Starlark.
+	File string
+}
+
+// Wall time spent with a particular call stack.
+type ProfileSample struct {
+	// Outermost frame first.
+	Stack []ProfileFrame
+
+	Dur time.Duration
+}
+
+// Where time went during Tiltfile execution.
+//
+// Empty unless the Tiltfile was executed with WithProfiling.
+//
+// The profile is collected from the same hooks as BuiltinCalls, so it's
+// precise about time spent in builtins (and in the subprocesses they run),
+// and attributes time spent in pure Starlark code to the innermost function
+// that was on the stack the whole time.
+type Profile struct {
+	Samples []ProfileSample
+
+	// The directory of the main Tiltfile. File paths are relative to this dir.
+	BaseDir string
+}
+
+func (p Profile) Total() time.Duration {
+	var total time.Duration
+	for _, s := range p.Samples {
+		total += s.Dur
+	}
+	return total
+}
+
+type profiler struct {
+	baseDir   string
+	mainFrame ProfileFrame
+	samples   []ProfileSample
+	index     map[string]int
+
+	// The stack that was running after the last event, and when that was.
+	last      time.Time
+	lastStack []ProfileFrame
+}
+
+func newProfiler(mainPath string) *profiler {
+	p := &profiler{
+		baseDir: filepath.Dir(mainPath),
+		index:   make(map[string]int),
+		last:    time.Now(),
+	}
+	p.mainFrame = p.fileFrame(mainPath)
+	p.lastStack = []ProfileFrame{p.mainFrame}
+	return p
+}
+
+func profilerFromThread(t *starlark.Thread) *profiler {
+	p, _ := t.Local(profilerKey).(*profiler)
+	return p
+}
+
+// Records the time since the last event.
+//
+// Between two events, we know that every frame the two stacks have in
+// common was running the whole time, so we attribute the time to the
+// innermost of them.
+func (p *profiler) event(before, after []ProfileFrame) {
+	now := time.Now()
+	dur := now.Sub(p.last)
+
+	stack := commonPrefix(p.lastStack, before)
+	if len(stack) > 0 && dur > 0 {
+		p.add(stack, dur)
+	}
+
+	p.last = now
+	p.lastStack = after
+}
+
+func (p *profiler) add(stack []ProfileFrame, dur time.Duration) {
+	var key strings.Builder
+	for _, f := range stack {
+		key.WriteString(f.Name)
+		key.WriteByte(0)
+		key.WriteString(f.File)
+		key.WriteByte(0)
+	}
+
+	i, ok := p.index[key.String()]
+	if !ok {
+		i = len(p.samples)
+		p.index[key.String()] = i
+		p.samples = append(p.samples, ProfileSample{
+			Stack: append([]ProfileFrame{}, stack...),
+		})
+	}
+	p.samples[i].Dur += dur
+}
+
+// Records the time since the last event, when execution ends.
+func (p *profiler) finish() {
+	p.event([]ProfileFrame{p.mainFrame}, nil)
+}
+
+func (p *profiler) profile() Profile {
+	return Profile{
+		Samples: append([]ProfileSample{}, p.samples...),
+		BaseDir: p.baseDir,
+	}
+}
+
+func (p *profiler) fileFrame(path string) ProfileFrame {
+	name := path
+	if rel, err := filepath.Rel(p.baseDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+	return ProfileFrame{Name: name, File: path}
+}
+
+func (p *profiler) stack(t *starlark.Thread) []ProfileFrame {
+	callStack := t.CallStack()
+	result := make([]ProfileFrame, 0, len(callStack))
+	for _, f := range callStack {
+		file := f.Pos.Filename()
+		if f.Name == "<toplevel>" {
+			result = append(result, p.fileFrame(file))
+			continue
+		}
+		if file == "<builtin>" {
+			file = ""
+		}
+		result = append(result, ProfileFrame{Name: f.Name, File: file})
+	}
+	return result
+}
+
+func (p *profiler) builtinStart(t *starlark.Thread) {
+	stack := p.stack(t)
+	p.event(stack[:len(stack)-1], stack)
+}
+
+func (p *profiler) builtinEnd(t *starlark.Thread) {
+	stack := p.stack(t)
+	p.event(stack, stack[:len(stack)-1])
+}
+
+// Records time spent outside the interpreter (e.g., waiting on a
+// subprocess) as a child of the current builtin, so that it shows up
+// separately in the profile.
+//
+// Call the returned function when the work is done.
+func StartProfileSpan(t *starlark.Thread, name string) func() {
+	p := profilerFromThread(t)
+	if p == nil {
+		return func() {}
+	}
+
+	stack := p.stack(t)
+	spanStack := append(append([]ProfileFrame{}, stack...), ProfileFrame{Name: name})
+	p.event(stack, spanStack)
+	return func() {
+		p.event(spanStack, stack)
+	}
+}
+
+func commonPrefix(a, b []ProfileFrame) []ProfileFrame {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
@@ -0,0 +1,127 @@
+package starkit
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/google/pprof/profile"
+)
+
+// Writes the profile in pprof format, for `go tool pprof`.
+func (p Profile) WritePprof(w io.Writer) error {
+	prof := &profile.Profile{
+		SampleType:    []*profile.ValueType{{Type: "wall", Unit: "nanoseconds"}},
+		PeriodType:    &profile.ValueType{Type: "wall", Unit: "nanoseconds"},
+		Period:        1,
+		DurationNanos: p.Total().Nanoseconds(),
+	}
+
+	locations := make(map[ProfileFrame]*profile.Location)
+	location := func(f ProfileFrame) *profile.Location {
+		loc, ok := locations[f]
+		if ok {
+			return loc
+		}
+
+		fn := &profile.Function{
+			ID:         uint64(len(prof.Function) + 1),
+			Name:       f.Name,
+			SystemName: f.Name,
+			Filename:   f.File,
+		}
+		prof.Function = append(prof.Function, fn)
+
+		loc = &profile.Location{
+			ID:   uint64(len(prof.Location) + 1),
+			Line: []profile.Line{{Function: fn}},
+		}
+		prof.Location = append(prof.Location, loc)
+		locations[f] = loc
+		return loc
+	}
+
+	for _, s := range p.Samples {
+		// pprof wants the innermost frame first.
+		locs := make([]*profile.Location, len(s.Stack))
+		for i, f := range s.Stack {
+			locs[len(s.Stack)-1-i] = location(f)
+		}
+		prof.Sample = append(prof.Sample, &profile.Sample{
+			Location: locs,
+			Value:    []int64{s.Dur.Nanoseconds()},
+		})
+	}
+
+	return prof.Write(w)
+}
+
+// https://github.com/jlfwong/speedscope/wiki/Importing-from-custom-sources
+type speedscopeFile struct {
+	Schema   string              `json:"$schema"`
+	Name     string              `json:"name"`
+	Exporter string              `json:"exporter"`
+	Shared   speedscopeShared    `json:"shared"`
+	Profiles []speedscopeProfile `json:"profiles"`
+}
+
+type speedscopeShared struct {
+	Frames []speedscopeFrame `json:"frames"`
+}
+
+type speedscopeFrame struct {
+	Name string `json:"name"`
+	File string `json:"file,omitempty"`
+}
+
+type speedscopeProfile struct {
+	Type       string  `json:"type"`
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`
+	StartValue int64   `json:"startValue"`
+	EndValue   int64   `json:"endValue"`
+	Samples    [][]int `json:"samples"`
+	Weights    []int64 `json:"weights"`
+}
+
+// Writes the profile in speedscope's JSON format, for https://www.speedscope.app.
+func (p Profile) WriteSpeedscope(w io.Writer, name string) error {
+	var frames []speedscopeFrame
+	index := make(map[ProfileFrame]int)
+	prof := speedscopeProfile{
+		Type:     "sampled",
+		Name:     name,
+		Unit:     "nanoseconds",
+		EndValue: p.Total().Nanoseconds(),
+		Samples:  [][]int{},
+		Weights:  []int64{},
+	}
+
+	for _, s := range p.Samples {
+		stack := make([]int, 0, len(s.Stack))
+		for _, f := range s.Stack {
+			i, ok := index[f]
+			if !ok {
+				i = len(frames)
+				index[f] = i
+				frames = append(frames, speedscopeFrame{Name: f.Name, File: f.File})
+			}
+			stack = append(stack, i)
+		}
+		prof.Samples = append(prof.Samples, stack)
+		prof.Weights = append(prof.Weights, s.Dur.Nanoseconds())
+	}
+
+	if frames == nil {
+		frames = []speedscopeFrame{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(speedscopeFile{
+		Schema:   "https://www.speedscope.app/file-format-schema.json",
+		Name:     name,
+		Exporter: "tilt",
+		Shared:   speedscopeShared{Frames: frames},
+		Profiles: []speedscopeProfile{prof},
+	})
+}
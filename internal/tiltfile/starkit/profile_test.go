@@ -0,0 +1,156 @@
+package starkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/starlark"
+)
+
+type sleepPlugin struct{}
+
+func (sleepPlugin) OnStart(env *Environment) error {
+	return env.AddBuiltin("sleep", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var ms int
+		err := UnpackArgs(thread, fn.Name(), args, kwargs, "ms", &ms)
+		if err != nil {
+			return nil, err
+		}
+
+		defer StartProfileSpan(thread, "exec: sleep")()
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+		return starlark.None, nil
+	})
+}
+
+func newProfileFixture(t *testing.T) *Fixture {
+	f := NewFixture(t, sleepPlugin{})
+	f.SetContext(WithProfiling(f.ctx))
+	f.File("Tiltfile", `
+load('./lib/lib.tilt', 'slow')
+slow()
+`)
+	f.File("lib/lib.tilt", `
+def slow():
+  sleep(30)
+
+sleep(10)
+`)
+	return f
+}
+
+// Stacks in the profile, as "a;b;c" strings.
+func stackDurs(p Profile) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, s := range p.Samples {
+		var names []string
+		for _, f := range s.Stack {
+			names = append(names, f.Name)
+		}
+		result[strings.Join(names, ";")] += s.Dur
+	}
+	return result
+}
+
+func TestProfile(t *testing.T) {
+	f := newProfileFixture(t)
+
+	model, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	durs := stackDurs(model.Profile)
+	assert.GreaterOrEqual(t, durs["Tiltfile;slow;sleep;exec: sleep"], 30*time.Millisecond)
+	assert.GreaterOrEqual(t, durs["Tiltfile;lib/lib.tilt;sleep;exec: sleep"], 10*time.Millisecond)
+
+	// Time in the builtin, outside the span, is tiny.
+	assert.Less(t, durs["Tiltfile;slow;sleep"], 10*time.Millisecond)
+
+	for stack := range durs {
+		assert.True(t, strings.HasPrefix(stack, "Tiltfile"), "stack should start at the Tiltfile: %s", stack)
+	}
+}
+
+func TestProfileOffByDefault(t *testing.T) {
+	f := NewFixture(t, sleepPlugin{})
+	f.File("Tiltfile", `
+sleep(10)
+`)
+
+	model, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Empty(t, model.Profile.Samples)
+}
+
+func TestProfileError(t *testing.T) {
+	f := NewFixture(t, sleepPlugin{})
+	f.SetContext(WithProfiling(f.ctx))
+	f.File("Tiltfile", `
+sleep(10)
+fail('oops')
+`)
+
+	model, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+
+	durs := stackDurs(model.Profile)
+	assert.GreaterOrEqual(t, durs["Tiltfile;sleep;exec: sleep"], 10*time.Millisecond)
+}
+
+func TestProfileWritePprof(t *testing.T) {
+	f := newProfileFixture(t)
+
+	model, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, model.Profile.WritePprof(out))
+
+	prof, err := profile.Parse(out)
+	require.NoError(t, err)
+	require.NoError(t, prof.CheckValid())
+	assert.Equal(t, "wall", prof.SampleType[0].Type)
+
+	var found bool
+	for _, s := range prof.Sample {
+		leaf := s.Location[0].Line[0].Function
+		root := s.Location[len(s.Location)-1].Line[0].Function
+		if leaf.Name == "exec: sleep" && len(s.Location) == 4 && s.Location[2].Line[0].Function.Name == "slow" {
+			found = true
+			assert.Equal(t, "Tiltfile", root.Name)
+			assert.Equal(t, f.JoinPath("lib", "lib.tilt"), s.Location[2].Line[0].Function.Filename)
+			assert.GreaterOrEqual(t, s.Value[0], (30 * time.Millisecond).Nanoseconds())
+		}
+	}
+	assert.True(t, found, "no sample for slow()")
+}
+
+func TestProfileWriteSpeedscope(t *testing.T) {
+	f := newProfileFixture(t)
+
+	model, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, model.Profile.WriteSpeedscope(out, "Tiltfile"))
+
+	var file speedscopeFile
+	require.NoError(t, json.Unmarshal(out.Bytes(), &file))
+	require.Len(t, file.Profiles, 1)
+
+	prof := file.Profiles[0]
+	assert.Equal(t, "sampled", prof.Type)
+	require.Equal(t, len(prof.Samples), len(prof.Weights))
+
+	var total int64
+	for i, s := range prof.Samples {
+		assert.Equal(t, "Tiltfile", file.Shared.Frames[s[0]].Name)
+		total += prof.Weights[i]
+	}
+	assert.Equal(t, prof.EndValue, total)
+}
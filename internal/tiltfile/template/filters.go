@@ -0,0 +1,105 @@
+package template
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/flosch/pongo2/v6"
+	"sigs.k8s.io/yaml"
+)
+
+var registerFiltersOnce sync.Once
+
+// pongo2 only has a global filter registry, so we add our filters to it the
+// first time a template is rendered. They're new names, so this doesn't change
+// how any existing filter behaves.
+func registerFilters() {
+	registerFiltersOnce.Do(func() {
+		for name, fn := range map[string]pongo2.FilterFunction{
+			"to_yaml":   filterToYAML,
+			"to_json":   filterToJSON,
+			"b64encode": filterB64Encode,
+			"b64decode": filterB64Decode,
+			"indent":    filterIndent,
+			"quote":     filterQuote,
+		} {
+			err := pongo2.RegisterFilter(name, fn)
+			if err != nil {
+				panic(err)
+			}
+		}
+	})
+}
+
+func filterError(name string, err error) *pongo2.Error {
+	return &pongo2.Error{Sender: "filter:" + name, OrigError: err}
+}
+
+// {{ obj | to_yaml }}
+func filterToYAML(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	b, err := yaml.Marshal(in.Interface())
+	if err != nil {
+		return nil, filterError("to_yaml", err)
+	}
+	return pongo2.AsValue(strings.TrimSuffix(string(b), "\n")), nil
+}
+
+// {{ obj | to_json }}
+func filterToJSON(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	b, err := json.Marshal(in.Interface())
+	if err != nil {
+		return nil, filterError("to_json", err)
+	}
+	return pongo2.AsValue(string(b)), nil
+}
+
+// {{ secret | b64encode }}
+func filterB64Encode(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	return pongo2.AsValue(base64.StdEncoding.EncodeToString([]byte(in.String()))), nil
+}
+
+// {{ secret | b64decode }}
+func filterB64Decode(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	b, err := base64.StdEncoding.DecodeString(in.String())
+	if err != nil {
+		return nil, filterError("b64decode", err)
+	}
+	return pongo2.AsValue(string(b)), nil
+}
+
+// {{ obj | to_yaml | indent:4 }}
+//
+// Indents every line but the first, so the filter can be used
+// after a key on the same line as the template tag.
+func filterIndent(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	n := 2
+	if !param.IsNil() {
+		if !param.IsInteger() || param.Integer() < 0 {
+			return nil, filterError("indent", fmt.Errorf("expected a non-negative int, got %q", param.String()))
+		}
+		n = param.Integer()
+	}
+
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(in.String(), "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = pad + lines[i]
+		}
+	}
+	return pongo2.AsValue(strings.Join(lines, "\n")), nil
+}
+
+// {{ value | quote }}
+//
+// Quotes a string so that YAML always reads it as a string.
+func filterQuote(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	b, err := json.Marshal(in.String())
+	if err != nil {
+		return nil, filterError("quote", err)
+	}
+	return pongo2.AsValue(string(b)), nil
+}
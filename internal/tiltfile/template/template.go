@@ -0,0 +1,181 @@
+package template
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/flosch/pongo2/v6"
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
+	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+type Plugin struct{}
+
+func NewPlugin() Plugin {
+	return Plugin{}
+}
+
+func (Plugin) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("template", render)
+}
+
+// template(src, values=None)
+//
+// src is either a path to a template file, or a blob containing the template
+// text. Returns the rendered template as a blob.
+func render(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var src starlark.Value
+	var values *starlark.Dict
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"src", &src,
+		"values?", &values)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := pongo2.Context{}
+	if values != nil {
+		data, err := encoding.ConvertStarlarkToStructuredData(values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: values", fn.Name())
+		}
+		for k, v := range data.(map[string]interface{}) {
+			ctx[k] = v
+		}
+	}
+
+	registerFilters()
+
+	loader := &fileLoader{thread: thread, dir: starkit.AbsWorkingDir(thread), wrapped: make(map[string]string)}
+	set := pongo2.NewSet("tiltfile", loader)
+
+	// Whitespace around block tags is almost never what you want in YAML.
+	set.Options.TrimBlocks = true
+	set.Options.LStripBlocks = true
+
+	// ssi reads files directly instead of through the loader,
+	// so Tilt wouldn't watch them.
+	err = set.BanTag("ssi")
+	if err != nil {
+		return nil, err
+	}
+
+	var tpl *pongo2.Template
+	var source string
+	if blob, ok := src.(tiltfile_io.Blob); ok {
+		source = "template: " + blob.Source
+		tpl, err = set.FromString(loader.withoutAutoescape(blobFilename, blob.Text))
+	} else {
+		var path string
+		path, err = value.ValueToAbsPath(thread, src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: for parameter %q: %v", fn.Name(), "src", err)
+		}
+		source = "template: " + path
+		tpl, err = set.FromFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), loader.formatError(err))
+	}
+
+	out, err := tpl.Execute(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), loader.formatError(err))
+	}
+
+	return tiltfile_io.NewBlob(out, source), nil
+}
+
+// The filename pongo2 gives templates created from a string.
+const blobFilename = "<string>"
+
+const autoescapeOff = "{% autoescape off %}"
+
+// Matches a template that extends another.
+var extendsRE = regexp.MustCompile(`{%\s*extends\s`)
+
+// Reads templates (including anything pulled in with {% include %},
+// {% extends %} or {% import %}) relative to the current Tiltfile,
+// and watches them for changes.
+type fileLoader struct {
+	thread *starlark.Thread
+	dir    string
+
+	// The original text of templates we wrapped in an autoescape block,
+	// by filename. nil if we shouldn't wrap templates.
+	wrapped map[string]string
+}
+
+func (l *fileLoader) Abs(base, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	if base == "" {
+		return filepath.Join(l.dir, name)
+	}
+	return filepath.Join(filepath.Dir(base), name)
+}
+
+func (l *fileLoader) Get(path string) (io.Reader, error) {
+	// Templates from blobs pass us their include paths unresolved.
+	path = l.Abs("", path)
+	contents, err := tiltfile_io.ReadFile(l.thread, path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(l.withoutAutoescape(path, string(contents))), nil
+}
+
+// We render YAML, not HTML, so nothing should be HTML-escaped. pongo2 can only
+// turn autoescaping off globally, so instead we wrap each template in an
+// autoescape block.
+//
+// A template that extends another has to keep {% extends %} at the root.
+// Its blocks render inside the outermost parent, which does get wrapped.
+func (l *fileLoader) withoutAutoescape(filename, text string) string {
+	if l.wrapped == nil || extendsRE.MatchString(text) {
+		return text
+	}
+	l.wrapped[filename] = text
+	return autoescapeOff + text + "{% endautoescape %}"
+}
+
+func (l *fileLoader) formatError(err error) error {
+	var perr *pongo2.Error
+	if !errors.As(err, &perr) || perr.OrigError == nil {
+		return err
+	}
+
+	filename := perr.Filename
+	line, column := perr.Line, perr.Column
+	if text, ok := l.wrapped[filename]; ok {
+		if strings.Contains(perr.OrigError.Error(), "endautoescape") {
+			// A block in the template isn't closed, so pongo2 complains about our
+			// wrapper instead. Parse the original text to get the real error.
+			raw := pongo2.NewSet("tiltfile-raw", &fileLoader{thread: l.thread, dir: l.dir})
+			_, rawErr := raw.FromString(text)
+			var rawPerr *pongo2.Error
+			if errors.As(rawErr, &rawPerr) && rawPerr.OrigError != nil {
+				perr = rawPerr
+				line, column = perr.Line, perr.Column
+			}
+		} else if line == 1 {
+			column -= len(autoescapeOff)
+		}
+	}
+	if filename == "" || filename == blobFilename {
+		filename = "<blob>"
+	}
+	if line > 0 {
+		return fmt.Errorf("%s:%d:%d: %v", filename, line, column, perr.OrigError)
+	}
+	return fmt.Errorf("%s: %v", filename, perr.OrigError)
+}
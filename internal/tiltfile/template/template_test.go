@@ -0,0 +1,200 @@
+package template
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/flosch/pongo2/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
+	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starlarkstruct"
+)
+
+func newFixture(t *testing.T) *starkit.Fixture {
+	f := starkit.NewFixture(t, NewPlugin(), tiltfile_io.NewPlugin(), encoding.NewPlugin(), starlarkstruct.NewPlugin())
+	f.UseRealFS()
+	f.File("assert.tilt", `
+def equals(expected, observed):
+	if expected != observed:
+		fail("expected: '%s'. observed: '%s'" % (expected, observed))
+
+assert = struct(equals=equals)
+`)
+	return f
+}
+
+func TestTemplateFile(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("deploy.yaml.tmpl", `kind: Deployment
+metadata:
+  name: {{ name }}
+spec:
+  replicas: {{ replicas }}
+{% if debug %}
+  paused: true
+{% endif %}
+  env:
+{% for k, v in env sorted %}
+  - name: {{ k|upper }}
+    value: {{ v|quote }}
+{% endfor %}
+`)
+	f.File("Tiltfile", `
+print(template('deploy.yaml.tmpl', {'name': 'foo', 'replicas': 2, 'debug': False, 'env': {'b': 'y', 'a': 'x'}}))
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	assert.Equal(t, `kind: Deployment
+metadata:
+  name: foo
+spec:
+  replicas: 2
+  env:
+  - name: A
+    value: "x"
+  - name: B
+    value: "y"
+
+`, f.PrintOutput())
+
+	rs, err := tiltfile_io.GetState(result)
+	require.NoError(t, err)
+	assert.Contains(t, rs.Paths, f.JoinPath("deploy.yaml.tmpl"))
+}
+
+func TestTemplateBlob(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", fmt.Sprintf(`
+load('assert.tilt', 'assert')
+b = template(blob(%q), values={'labels': {'app': 'foo'}})
+assert.equals('blob', type(b))
+assert.equals('labels:\n  app: foo\n', str(b))
+`, "labels:\n  {{ labels|to_yaml|indent }}\n"))
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+}
+
+func TestTemplateComposesWithDecodeYAML(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("secret.yaml.tmpl", `kind: Secret
+data:
+  password: {{ password|b64encode }}
+`)
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+obj = decode_yaml(template('secret.yaml.tmpl', {'password': 'hunter2'}))
+assert.equals('aHVudGVyMg==', obj['data']['password'])
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+}
+
+func TestTemplateInclude(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("templates/labels.tmpl", "    app: {{ name }}\n")
+	f.File("templates/service.yaml.tmpl", `kind: Service
+metadata:
+  labels:
+{% include "labels.tmpl" %}
+`)
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+s = template('templates/service.yaml.tmpl', {'name': 'foo'})
+assert.equals('kind: Service\nmetadata:\n  labels:\n    app: foo\n', str(s))
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	rs, err := tiltfile_io.GetState(result)
+	require.NoError(t, err)
+	assert.Contains(t, rs.Paths, f.JoinPath("templates/labels.tmpl"))
+}
+
+func TestTemplateDoesNotEscape(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("base.tmpl", "cmd: {% block cmd %}{% endblock %}\n")
+	f.File("child.tmpl", `{% extends "base.tmpl" %}{% block cmd %}{{ cmd }}{% endblock %}`)
+	f.File("Tiltfile", `
+load('assert.tilt', 'assert')
+assert.equals('cmd: a && b > "c"', str(template(blob('cmd: {{ cmd }}'), {'cmd': 'a && b > "c"'})))
+assert.equals('cmd: a && b > "c"\n', str(template('child.tmpl', {'cmd': 'a && b > "c"'})))
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	// pongo2's own default is left alone.
+	out, err := pongo2.RenderTemplateString("{{ cmd }}", pongo2.Context{"cmd": "a && b"})
+	require.NoError(t, err)
+	assert.Equal(t, "a &amp;&amp; b", out)
+}
+
+func TestTemplateSSIBanned(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("secret.txt", "hunter2")
+	f.File("Tiltfile", `
+template(blob('{% ssi "secret.txt" %}'))
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ssi")
+	assert.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestTemplateErrorColumn(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("bad.tmpl", "a: {{ x|nope }}\n")
+	f.File("Tiltfile", `
+template('bad.tmpl')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "template: "+f.JoinPath("bad.tmpl")+":1:9:")
+}
+
+func TestTemplateSyntaxError(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("bad.tmpl", "a: b\n{% if x %}\nc: d\n")
+	f.File("Tiltfile", `
+template('bad.tmpl')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "template: "+f.JoinPath("bad.tmpl")+":2:")
+}
+
+func TestTemplateMissingFile(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+template('missing.tmpl')
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.tmpl")
+
+	rs, err := tiltfile_io.GetState(result)
+	require.NoError(t, err)
+	assert.Contains(t, rs.Paths, f.JoinPath("missing.tmpl"))
+}
@@ -0,0 +1,360 @@
+package tiltfile
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/syntax"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/clusterid"
+	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/config"
+	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/tiltextension"
+	"github.com/tilt-dev/tilt/internal/tiltfile/unittest"
+	"github.com/tilt-dev/tilt/internal/tiltfile/version"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	corev1alpha1 "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const TestFileSuffix = "_test.tilt"
+const testFuncPrefix = "test_"
+
+// The kube context that k8s_context() returns in tests,
+// unless the test calls fake.k8s_context().
+const FakeKubeContext = k8s.KubeContext("tilt-test")
+
+// Tests pretend to run against a local dev cluster, so that
+// local() isn't blocked by the production-cluster check.
+const FakeClusterProduct = clusterid.ProductKIND
+
+// Runs the test_* functions in *_test.tilt files.
+//
+// Each test gets a fresh Tiltfile environment: the test file is executed
+// from scratch, then the test function is called. So tests can't leak
+// resources or fakes into each other.
+type TestRunner struct {
+	versionPlugin   version.Plugin
+	extensionPlugin *tiltextension.Plugin
+	webHost         model.WebHost
+	fDefaults       feature.Defaults
+}
+
+func ProvideTestRunner(
+	versionPlugin version.Plugin,
+	extensionPlugin *tiltextension.Plugin,
+	webHost model.WebHost,
+	fDefaults feature.Defaults) *TestRunner {
+	return &TestRunner{
+		versionPlugin:   versionPlugin,
+		extensionPlugin: extensionPlugin,
+		webHost:         webHost,
+		fDefaults:       fDefaults,
+	}
+}
+
+type TestResult struct {
+	// Absolute path to the test file
+	File string
+
+	// Name of the test function
+	Name string
+
+	Duration time.Duration
+
+	// An assertion failed.
+	Failure string
+
+	// The test hit some other error, e.g., a syntax error or an unfaked local().
+	Error string
+
+	// Everything the test printed or logged.
+	Output string
+}
+
+func (r TestResult) Passed() bool {
+	return r.Failure == "" && r.Error == ""
+}
+
+// Finds test files in the given paths.
+//
+// Directories are searched recursively, skipping hidden directories and
+// node_modules. Files are used as-is.
+func FindTestFiles(paths []string) ([]string, error) {
+	var result []string
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			result = append(result, abs)
+			continue
+		}
+
+		err = filepath.WalkDir(abs, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != abs && (strings.HasPrefix(name, ".") || name == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(d.Name(), TestFileSuffix) {
+				result = append(result, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(result)
+	return dedupeSorted(result), nil
+}
+
+func dedupeSorted(s []string) []string {
+	result := s[:0]
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Finds the test functions declared at the top level of a test file,
+// in the order they're declared.
+func findTestFuncs(path string) ([]string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := syntax.Parse(path, contents, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, stmt := range f.Stmts {
+		def, ok := stmt.(*syntax.DefStmt)
+		if ok && strings.HasPrefix(def.Name.Name, testFuncPrefix) {
+			result = append(result, def.Name.Name)
+		}
+	}
+	return result, nil
+}
+
+// Runs the tests in a file whose names match the filter (or all of them,
+// if the filter is nil).
+//
+// If the file can't be parsed, returns a single errored result for the file.
+func (r *TestRunner) RunFile(ctx context.Context, path string, filter *regexp.Regexp) []TestResult {
+	funcs, err := findTestFuncs(path)
+	if err != nil {
+		return []TestResult{{File: path, Name: filepath.Base(path), Error: err.Error()}}
+	}
+
+	var results []TestResult
+	for _, name := range funcs {
+		if filter != nil && !filter.MatchString(name) {
+			continue
+		}
+		results = append(results, r.runTest(ctx, path, name))
+	}
+	return results
+}
+
+func (r *TestRunner) runTest(ctx context.Context, path string, name string) TestResult {
+	start := time.Now()
+	result := TestResult{File: path, Name: name}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := &bytes.Buffer{}
+	ctx = logger.WithLogger(ctx, logger.NewLogger(logger.InfoLvl, out))
+
+	// Don't let read_url(), helm() or kustomize() touch the real cache.
+	cacheDir, err := os.MkdirTemp("", "tilt-tiltfile-test-")
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer func() {
+		_ = os.RemoveAll(cacheDir)
+	}()
+
+	fakes := unittest.NewFakes()
+	s := newTiltfileState(ctx, unittest.DockerComposeClient{}, r.webHost, fakes,
+		k8scontext.NewPlugin(FakeKubeContext, k8s.ProfileForProduct(FakeClusterProduct)),
+		r.versionPlugin,
+		config.NewPlugin("up"),
+		r.extensionPlugin,
+		feature.FromDefaults(r.fDefaults),
+		xdg.FakeBase{Dir: cacheDir})
+	s.urlFetcher = fakes.FetchURL
+	s.chartDownload = fakes.FetchChart
+	s.kustomizeGit = fakes.RunGit
+
+	tf := &corev1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+		Spec:       corev1alpha1.TiltfileSpec{Path: path},
+	}
+	plugins := append(s.plugins(), unittest.NewPlugin(fakes))
+	_, err = starkit.CallFunction(tf, name, plugins...)
+
+	result.Duration = time.Since(start)
+	result.Output = out.String()
+	if err != nil {
+		var assertErr unittest.AssertionError
+		if errors.As(err, &assertErr) {
+			result.Failure = starkit.UnpackBacktrace(err).Error()
+		} else {
+			result.Error = starkit.UnpackBacktrace(err).Error()
+		}
+	}
+	return result
+}
+
+func (r TestResult) String() string {
+	status := "PASS"
+	if r.Failure != "" {
+		status = "FAIL"
+	} else if r.Error != "" {
+		status = "ERROR"
+	}
+	return fmt.Sprintf("--- %s: %s (%.2fs)", status, r.Name, r.Duration.Seconds())
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// Puts the first line of the error (after any backtrace frames) in the
+// message attribute, and the whole backtrace in the body.
+func junitMessageFor(body string) *junitMessage {
+	if body == "" {
+		return nil
+	}
+	msg := body
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "Traceback ") || strings.HasPrefix(line, " ") {
+			continue
+		}
+		msg = line
+		break
+	}
+	return &junitMessage{Message: msg, Body: body}
+}
+
+// Writes test results as JUnit XML, with one test suite per file,
+// so that CI systems can display them.
+//
+// Each suite is named by the test file's path relative to baseDir.
+func WriteJUnitXML(w io.Writer, baseDir string, results []TestResult) error {
+	var suites []junitTestSuite
+	var durations []time.Duration
+	suiteIndex := make(map[string]int)
+	for _, r := range results {
+		name := r.File
+		if rel, err := filepath.Rel(baseDir, r.File); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+
+		i, ok := suiteIndex[r.File]
+		if !ok {
+			i = len(suites)
+			suiteIndex[r.File] = i
+			suites = append(suites, junitTestSuite{Name: name})
+			durations = append(durations, 0)
+		}
+		durations[i] += r.Duration
+
+		suite := &suites[i]
+		suite.Tests++
+		if r.Failure != "" {
+			suite.Failures++
+		} else if r.Error != "" {
+			suite.Errors++
+		}
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      r.Name,
+			ClassName: name,
+			Time:      junitSeconds(r.Duration),
+			Failure:   junitMessageFor(r.Failure),
+			Error:     junitMessageFor(r.Error),
+			SystemOut: r.Output,
+		})
+	}
+
+	for i := range suites {
+		suites[i].Time = junitSeconds(durations[i])
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(junitTestSuites{Suites: suites})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
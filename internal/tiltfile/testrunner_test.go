@@ -0,0 +1,207 @@
+package tiltfile
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/tiltextension"
+	"github.com/tilt-dev/tilt/internal/tiltfile/version"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestTestRunnerPassAndFail(t *testing.T) {
+	f := newFixture(t)
+	f.file("Tiltfile", `
+def double(x):
+  return x * 2
+`)
+	f.file("lib_test.tilt", `
+load('./Tiltfile', 'double')
+
+def test_double():
+  print('doubling')
+  assert.equals(4, double(2))
+
+def test_double_wrong():
+  assert.equals(5, double(2), msg='math')
+
+def test_error():
+  fail('oh no')
+
+def helper():
+  fail('not a test')
+`)
+
+	results := f.runTests(nil)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "test_double", results[0].Name)
+	assert.True(t, results[0].Passed())
+	assert.Contains(t, results[0].Output, "doubling")
+
+	assert.Equal(t, "test_double_wrong", results[1].Name)
+	assert.Contains(t, results[1].Failure, "math: expected 5, got 4")
+	assert.Empty(t, results[1].Error)
+
+	assert.Equal(t, "test_error", results[2].Name)
+	assert.Contains(t, results[2].Error, "oh no")
+	assert.Empty(t, results[2].Failure)
+}
+
+func TestTestRunnerFakes(t *testing.T) {
+	f := newFixture(t)
+	f.file("fakes_test.tilt", `
+def test_local():
+  fake.local('git rev-parse HEAD', stdout='abc123\n')
+  assert.equals('abc123', str(local('git rev-parse HEAD', quiet=True)).strip())
+
+def test_local_not_faked():
+  assert.fails(lambda: local('rm -rf /'), contains='not faked')
+
+def test_url():
+  fake.url('https://example.com/a.yaml', contents='kind: Pod')
+  assert.equals('kind: Pod', str(read_url('https://example.com/a.yaml')))
+
+def test_k8s_context():
+  assert.equals('tilt-test', k8s_context())
+  fake.k8s_context('kind-kind')
+  assert.equals('kind-kind', k8s_context())
+`)
+
+	results := f.runTests(nil)
+	require.Len(t, results, 4)
+	for _, r := range results {
+		assert.True(t, r.Passed(), "%s: %s%s", r.Name, r.Failure, r.Error)
+	}
+}
+
+func TestTestRunnerRemoteFetchesFail(t *testing.T) {
+	f := newFixture(t)
+	f.file("kustomize/kustomization.yaml", `
+resources:
+- github.com/tilt-dev/tilt-example-k8s//base?ref=main
+`)
+	f.file("docker-compose.yml", `
+services:
+  redis:
+    image: redis
+`)
+	f.file("remote_test.tilt", `
+def test_helm():
+  assert.fails(lambda: helm('oci://registry.example.com/charts/app'), contains="remote charts can't be fetched in tests")
+
+def test_kustomize():
+  assert.fails(lambda: kustomize('kustomize'), contains="remote kustomize bases can't be fetched in tests")
+
+def test_docker_compose():
+  docker_compose('docker-compose.yml')
+`)
+
+	results := f.runTests(nil)
+	require.Len(t, results, 3)
+	for _, r := range results {
+		assert.True(t, r.Passed(), "%s: %s%s", r.Name, r.Failure, r.Error)
+	}
+}
+
+func TestTestRunnerIsolation(t *testing.T) {
+	f := newFixture(t)
+	f.file("isolation_test.tilt", `
+def test_a():
+  fake.local('echo hi', stdout='hi')
+
+def test_b():
+  assert.fails(lambda: local('echo hi'), contains='not faked')
+`)
+
+	results := f.runTests(nil)
+	require.Len(t, results, 2)
+	assert.True(t, results[1].Passed(), results[1].Failure+results[1].Error)
+}
+
+func TestTestRunnerFilter(t *testing.T) {
+	f := newFixture(t)
+	f.file("a/a_test.tilt", `
+def test_foo():
+  pass
+
+def test_bar():
+  pass
+`)
+	f.file("b/b_test.tilt", `
+def test_foo():
+  pass
+`)
+	f.file("b/helpers.tilt", `
+def test_foo():
+  pass
+`)
+
+	results := f.runTests(regexp.MustCompile("foo"))
+	require.Len(t, results, 2)
+	assert.Equal(t, f.JoinPath("a", "a_test.tilt"), results[0].File)
+	assert.Equal(t, f.JoinPath("b", "b_test.tilt"), results[1].File)
+}
+
+func TestTestRunnerSyntaxError(t *testing.T) {
+	f := newFixture(t)
+	f.file("bad_test.tilt", `
+def test_foo(:
+`)
+
+	results := f.runTests(nil)
+	require.Len(t, results, 1)
+	assert.Equal(t, "bad_test.tilt", results[0].Name)
+	assert.Contains(t, results[0].Error, "want ')'")
+}
+
+func TestTestRunnerJUnitXML(t *testing.T) {
+	f := newFixture(t)
+	f.file("lib_test.tilt", `
+def test_pass():
+  print('hello')
+
+def test_fail():
+  assert.true(False)
+
+def test_error():
+  fail('oh no')
+`)
+
+	results := f.runTests(nil)
+	out := &bytes.Buffer{}
+	err := WriteJUnitXML(out, f.Path(), results)
+	require.NoError(t, err)
+
+	xml := out.String()
+	assert.Contains(t, xml, `<testsuite name="lib_test.tilt" tests="3" failures="1" errors="1"`)
+	assert.Contains(t, xml, `<testcase name="test_pass" classname="lib_test.tilt"`)
+	assert.Contains(t, xml, `<system-out>hello`)
+	assert.Contains(t, xml, `<failure message="Error in assert.true: expected a true value, got False">`)
+	assert.Contains(t, xml, `<error message="Error in fail: oh no">`)
+}
+
+func (f *fixture) runTests(filter *regexp.Regexp) []TestResult {
+	f.t.Helper()
+
+	runner := ProvideTestRunner(
+		version.NewPlugin(model.TiltBuild{Version: "0.5.0"}),
+		tiltextension.NewFakePlugin(
+			tiltextension.NewFakeExtRepoReconciler(f.Path()),
+			tiltextension.NewFakeExtReconciler(f.Path())),
+		f.webHost,
+		f.features)
+
+	files, err := FindTestFiles([]string{f.Path()})
+	require.NoError(f.t, err)
+
+	var results []TestResult
+	for _, file := range files {
+		results = append(results, runner.RunFile(f.ctx, file, filter)...)
+	}
+	return results
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 	"github.com/tilt-dev/tilt/internal/tiltfile/version"
 	"github.com/tilt-dev/tilt/internal/tiltfile/watch"
+	"github.com/tilt-dev/tilt/internal/xdg"
 	corev1alpha1 "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 	wmanalytics "github.com/tilt-dev/wmclient/pkg/analytics"
@@ -57,11 +58,18 @@ type TiltfileLoadResult struct {
 	UpdateSettings      model.UpdateSettings
 	WatchSettings       model.WatchSettings
 	DefaultRegistry     *corev1alpha1.RegistryHosting
+	ClusterProvision    *corev1alpha1.ClusterProvision
+	K8sClusters         map[string]*corev1alpha1.KubernetesClusterConnection
 	ObjectSet           apiset.ObjectSet
 	Hashes              hasher.Hashes
+	TiltfileArgs        []model.TiltfileArg
+
+	// URLs fetched with read_url()
+	ReadURLs []string
 
 	// For diagnostic purposes only
 	BuiltinCalls []starkit.BuiltinCall `json:"-"`
+	Profile      starkit.Profile       `json:"-"`
 }
 
 func (r TiltfileLoadResult) HasOrchestrator(orc model.Orchestrator) bool {
@@ -103,7 +111,8 @@ func ProvideTiltfileLoader(
 	webHost model.WebHost,
 	execer localexec.Execer,
 	fDefaults feature.Defaults,
-	env clusterid.Product) TiltfileLoader {
+	env clusterid.Product,
+	base xdg.Base) TiltfileLoader {
 	return tiltfileLoader{
 		analytics:        analytics,
 		k8sContextPlugin: k8sContextPlugin,
@@ -115,6 +124,7 @@ func ProvideTiltfileLoader(
 		execer:           execer,
 		fDefaults:        fDefaults,
 		env:              env,
+		base:             base,
 	}
 }
 
@@ -130,6 +140,7 @@ type tiltfileLoader struct {
 	extensionPlugin  *tiltextension.Plugin
 	fDefaults        feature.Defaults
 	env              clusterid.Product
+	base             xdg.Base
 }
 
 var _ TiltfileLoader = &tiltfileLoader{}
@@ -169,12 +180,15 @@ func (tfl tiltfileLoader) Load(ctx context.Context, tf *corev1alpha1.Tiltfile, p
 	tlr.Tiltignore = tiltignore
 
 	s := newTiltfileState(ctx, tfl.dcCli, tfl.webHost, tfl.execer, tfl.k8sContextPlugin, tfl.versionPlugin,
-		tfl.configPlugin, tfl.extensionPlugin, feature.FromDefaults(tfl.fDefaults))
+		tfl.configPlugin, tfl.extensionPlugin, feature.FromDefaults(tfl.fDefaults), tfl.base)
 
 	manifests, result, err := s.loadManifests(tf)
 
 	tlr.BuiltinCalls = result.BuiltinCalls
+	tlr.Profile = result.Profile
 	tlr.DefaultRegistry = s.defaultReg
+	tlr.ClusterProvision = s.clusterProvision
+	tlr.K8sClusters = s.k8sClusters
 
 	// All data models are loaded with GetState. We ignore the error if the state
 	// isn't properly loaded. This is necessary for handling partial Tiltfile
@@ -189,6 +203,7 @@ func (tfl tiltfileLoader) Load(ctx context.Context, tf *corev1alpha1.Tiltfile, p
 
 	ioState, _ := io.GetState(result)
 
+	tlr.ReadURLs = ioState.URLs
 	tlr.ConfigFiles = append(tlr.ConfigFiles, ioState.Paths...)
 	tlr.ConfigFiles = append(tlr.ConfigFiles, s.postExecReadFiles...)
 	tlr.ConfigFiles = sliceutils.DedupedAndSorted(tlr.ConfigFiles)
@@ -218,6 +233,7 @@ func (tfl tiltfileLoader) Load(ctx context.Context, tf *corev1alpha1.Tiltfile, p
 	tlr.UpdateSettings = us
 
 	configSettings, _ := config.GetState(result)
+	tlr.TiltfileArgs = configSettings.TiltfileArgs()
 	if tlr.Error == nil {
 		tlr.EnabledManifests, tlr.Error = configSettings.EnabledResources(tf, manifests)
 	}
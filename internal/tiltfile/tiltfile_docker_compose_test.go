@@ -7,11 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/mod/semver"
 
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
-	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/apis/tiltfile"
-	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -97,7 +94,7 @@ func TestDockerComposeBadTypeError(t *testing.T) {
 
 	f.file("Tiltfile", "docker_compose(True)")
 
-	f.loadErrString("expected blob | path (string). Actual type: starlark.Bool")
+	f.loadErrString("expected blob | dict | path (string). Actual type: starlark.Bool")
 }
 
 func TestDockerComposeManifest(t *testing.T) {
@@ -147,6 +144,30 @@ func TestDockerComposeEnvFile(t *testing.T) {
 	f.assertConfigFiles(expectedConfFiles...)
 }
 
+func TestDockerComposeDefaultEnvFile(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", `services:
+  bar:
+    image: bar-image
+    ports:
+      - "$BAR_PORT:$BAR_PORT"
+`)
+	f.file(".env", "BAR_PORT=4000\n")
+	f.file("Tiltfile", "docker_compose('docker-compose.yml')")
+
+	f.load()
+	f.assertDcManifest("bar", dcPublishedPorts(4000))
+
+	expectedConfFiles := []string{
+		"Tiltfile",
+		".tiltignore",
+		".env",
+		"docker-compose.yml",
+	}
+	f.assertConfigFiles(expectedConfFiles...)
+}
+
 func TestDockerComposeServiceEnvFile(t *testing.T) {
 	f := newFixture(t)
 
@@ -247,6 +268,112 @@ func TestDockerComposeBlobAndFileUsesFileDirForProjectPath(t *testing.T) {
 	)
 }
 
+func TestDockerComposeDictOverride(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose.yml", simpleConfig)
+	f.file("Tiltfile", `
+docker_compose(['docker-compose.yml', {
+  'services': {
+    'foo': {
+      'environment': {'DEBUG': 'true'},
+      'ports': ['9229:9229'],
+      'volumes': ['./data:/data'],
+    },
+  },
+}])
+`)
+
+	f.load()
+	m := f.assertDcManifest("foo", dcPublishedPorts(12312, 9229))
+	svcYAML := m.DockerComposeTarget().ServiceYAML
+	assert.Contains(t, svcYAML, "DEBUG: \"true\"")
+	assert.Contains(t, svcYAML, "target: /data")
+}
+
+func TestDockerComposeDictOnly(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+docker_compose({'services': {'redis': {'image': 'redis', 'ports': ['6379:6379']}}})
+`)
+
+	f.load()
+	f.assertDcManifest("redis", dcPublishedPorts(6379))
+}
+
+func TestDockerComposeProfiles(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", `services:
+  app:
+    image: app
+  debugger:
+    image: debugger
+    profiles: [debug]
+  metrics:
+    image: metrics
+    profiles: [monitoring]
+`)
+	f.file("Tiltfile", `docker_compose('docker-compose.yml', profiles=['debug'])`)
+
+	f.load()
+	f.assertNextManifest("app")
+	m := f.assertNextManifest("debugger")
+	f.assertNoMoreManifests()
+	assert.Equal(t, []string{"debug"}, m.DockerComposeTarget().Spec.Project.Profiles)
+}
+
+func TestDockerComposeNoProfiles(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", `services:
+  app:
+    image: app
+  debugger:
+    image: debugger
+    profiles: [debug]
+`)
+	f.file("Tiltfile", `docker_compose('docker-compose.yml')`)
+
+	f.load()
+	f.assertNextManifest("app")
+	f.assertNoMoreManifests()
+}
+
+func TestDCResourceReplicas(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", `services:
+  worker:
+    image: worker
+`)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+dc_resource('worker', replicas=3)
+`)
+
+	f.load()
+	m := f.assertDcManifest("worker")
+	assert.Equal(t, int32(3), m.DockerComposeTarget().Spec.Replicas)
+}
+
+func TestDCResourceReplicasInvalid(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", `services:
+  worker:
+    image: worker
+`)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+dc_resource('worker', replicas=0)
+`)
+
+	f.loadErrString("dc_resource: replicas must be at least 1, got 0")
+}
+
 func TestDockerComposeManifestNoDockerfile(t *testing.T) {
 	f := newFixture(t)
 
@@ -883,58 +1010,36 @@ dc_resource('bar', resource_deps=['foo'])
 	f.assertNextManifest("bar", resourceDeps("foo"))
 }
 
-func TestDockerComposeVersionWarnings(t *testing.T) {
-	type tc struct {
-		version string
-		warning string
-		error   string
-	}
-	tcs := []tc{
-		{version: "v1.28.0", error: "Tilt requires Docker Compose v1.28.3+ (you have v1.28.0). Please upgrade and re-launch Tilt."},
-		{version: "v2.0.0-rc.3", warning: "Using Docker Compose v2.0.0-rc.3 (version < 2.2) may result in errors or broken functionality.\n" +
-			"For best results, we recommend upgrading to Docker Compose >= v2.2.0."},
-		{version: "v1.29.2" /* no errors or warnings */},
-		{version: "v2.2.0" /* no errors or warnings */},
-		{version: "v1.99.0-beta.4", warning: "You are running a pre-release version of Docker Compose (v1.99.0-beta.4), which is unsupported.\n" +
-			"You might encounter errors or broken functionality."},
-	}
-
-	for _, tc := range tcs {
-		t.Run(tc.version, func(t *testing.T) {
-			f := newFixture(t)
-
-			f.dockerfile(filepath.Join("foo", "Dockerfile"))
-			f.file("docker-compose.yml", simpleConfig)
-			f.file("Tiltfile", "docker_compose('docker-compose.yml')")
-
-			f.load("foo")
-
-			loader := f.newTiltfileLoader()
-			if tl, ok := loader.(tiltfileLoader); ok {
-				dcCli := dockercompose.NewFakeDockerComposeClient(t, f.ctx)
-				dcCli.ConfigOutput = simpleConfig
-				dcCli.VersionOutput = semver.Canonical(tc.version)
-				tl.dcCli = dcCli
-				loader = tl
-			} else {
-				require.Fail(t, "Could not set up fake Docker Compose client")
-			}
+func TestDCDependsOnCondition(t *testing.T) {
+	f := newFixture(t)
 
-			f.loadResult = loader.Load(f.ctx, ctrltiltfile.MainTiltfile(f.JoinPath("Tiltfile"), nil), nil)
-			if tc.error == "" {
-				require.NoError(t, f.loadResult.Error, "Tiltfile load result had unexpected error")
-			} else {
-				require.Contains(t, f.loadResult.Error.Error(), tc.error)
-			}
+	f.file("docker-compose.yml", `services:
+  db:
+    image: postgres
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+  migrate:
+    image: migrate
+    depends_on:
+      db:
+        condition: service_healthy
+  app:
+    image: app
+    depends_on:
+      db:
+        condition: service_started
+      migrate:
+        condition: service_completed_successfully
+`)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+dc_resource('app', resource_deps=['migrate'])
+`)
 
-			if tc.warning != "" {
-				require.Len(t, f.warnings, 1)
-				require.Contains(t, f.warnings[0], tc.warning)
-			} else {
-				require.Empty(t, f.warnings, "Tiltfile load result had unexpected warning(s)")
-			}
-		})
-	}
+	f.load()
+	f.assertNextManifest("db", resourceDeps())
+	f.assertNextManifest("migrate", resourceDeps("db"))
+	f.assertNextManifest("app", resourceDeps("migrate"))
 }
 
 func (f *fixture) assertDcManifest(name model.ManifestName, opts ...interface{}) model.Manifest {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,7 +12,6 @@ import (
 	"github.com/pkg/errors"
 	"go.starlark.net/starlark"
 	"go.starlark.net/syntax"
-	"golang.org/x/mod/semver"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/tilt-dev/tilt/internal/controllers/apis/cmdimage"
@@ -30,7 +30,9 @@ import (
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/helm"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/kustomize"
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/tiltfile/analytics"
@@ -50,11 +52,13 @@ import (
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starlarkstruct"
 	"github.com/tilt-dev/tilt/internal/tiltfile/telemetry"
+	"github.com/tilt-dev/tilt/internal/tiltfile/template"
 	"github.com/tilt-dev/tilt/internal/tiltfile/updatesettings"
 	tfv1alpha1 "github.com/tilt-dev/tilt/internal/tiltfile/v1alpha1"
 	"github.com/tilt-dev/tilt/internal/tiltfile/version"
 	"github.com/tilt-dev/tilt/internal/tiltfile/watch"
 	fwatch "github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -85,6 +89,17 @@ type tiltfileState struct {
 	configPlugin     *config.Plugin
 	extensionPlugin  *tiltextension.Plugin
 	features         feature.FeatureSet
+	base             xdg.Base
+	helmCharts       *helm.ChartFetcher
+	kustomizer       *kustomize.Builder
+
+	// If set, replaces the network for read_url()
+	urlFetcher io.URLFetcher
+
+	// If set, replace the network for remote helm charts
+	// and remote kustomize bases
+	chartDownload helm.ChartDownloadFunc
+	kustomizeGit  kustomize.GitRunner
 
 	// added to during execution
 	buildIndex     *buildIndex
@@ -101,6 +116,11 @@ type tiltfileState struct {
 	k8sByName      map[string]*k8sResource
 	k8sUnresourced []k8s.K8sEntity
 
+	// Objects that k8s_yaml() loaded for a named cluster, by cluster name.
+	// They move into resources like k8sUnresourced, but only group
+	// with objects loaded for the same cluster.
+	k8sClusterUnresourced map[string][]k8s.K8sEntity
+
 	dc           dcResourceSet // currently only support one d-c.yml
 	dcByName     map[string]*dcService
 	dcResOptions map[string]*dcResourceOptions
@@ -112,6 +132,12 @@ type tiltfileState struct {
 	// ensure that any images are pushed to/pulled from this registry, rewriting names if needed
 	defaultReg *v1alpha1.RegistryHosting
 
+	// a local cluster to create before deploying to it
+	clusterProvision *v1alpha1.ClusterProvision
+
+	// additional clusters declared with k8s_cluster(), by name
+	k8sClusters map[string]*v1alpha1.KubernetesClusterConnection
+
 	k8sKinds map[k8s.ObjectSelector]*tiltfile_k8s.KindInfo
 
 	workloadToResourceFunction workloadToResourceFunction
@@ -164,7 +190,8 @@ func newTiltfileState(
 	versionPlugin version.Plugin,
 	configPlugin *config.Plugin,
 	extensionPlugin *tiltextension.Plugin,
-	features feature.FeatureSet) *tiltfileState {
+	features feature.FeatureSet,
+	base xdg.Base) *tiltfileState {
 	return &tiltfileState{
 		ctx:                       ctx,
 		dcCli:                     dcCli,
@@ -177,6 +204,8 @@ func newTiltfileState(
 		buildIndex:                newBuildIndex(),
 		k8sObjectIndex:            tiltfile_k8s.NewState(),
 		k8sByName:                 make(map[string]*k8sResource),
+		k8sClusters:               make(map[string]*v1alpha1.KubernetesClusterConnection),
+		k8sClusterUnresourced:     make(map[string][]k8s.K8sEntity),
 		dcByName:                  make(map[string]*dcService),
 		dcResOptions:              make(map[string]*dcResourceOptions),
 		localByName:               make(map[string]*localResource),
@@ -188,6 +217,7 @@ func newTiltfileState(
 		localResources:            []*localResource{},
 		triggerMode:               TriggerModeAuto,
 		features:                  features,
+		base:                      base,
 		secretSettings:            model.DefaultSecretSettings(),
 		apiObjects:                apiset.ObjectSet{},
 		k8sKinds:                  tiltfile_k8s.InitialKinds(),
@@ -199,23 +229,20 @@ func (s *tiltfileState) print(_ *starlark.Thread, msg string) {
 	s.logger.Infof("%s", msg)
 }
 
-// Load loads the Tiltfile in `filename`, and returns the manifests matching `matching`.
-//
-// This often returns a starkit.Model even on error, because the starkit.Model
-// has a record of what happened during the execution (what files were read, etc).
-//
-// TODO(nick): Eventually this will just return a starkit.Model, which will contain
-// all the mutable state collected by execution.
-func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest, starkit.Model, error) {
-	s.logger.Infof("Loading Tiltfile at: %s", tf.Spec.Path)
+// The plugins that make up the Tiltfile API.
+func (s *tiltfileState) plugins() []starkit.Plugin {
+	ioPlugin := io.NewPluginWithBase(s.base)
+	if s.urlFetcher != nil {
+		ioPlugin = ioPlugin.WithURLFetcher(s.urlFetcher)
+	}
 
-	result, err := starkit.ExecFile(tf,
+	return []starkit.Plugin{
 		s,
 		include.IncludeFn{},
 		git.NewPlugin(),
 		os.NewPlugin(),
 		sys.NewPlugin(),
-		io.NewPlugin(),
+		ioPlugin,
 		s.k8sContextPlugin,
 		dockerprune.NewPlugin(),
 		analytics.NewPlugin(),
@@ -228,6 +255,7 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 		secretsettings.NewPlugin(),
 		encoding.NewPlugin(),
 		shlex.NewPlugin(),
+		template.NewPlugin(),
 		watch.NewPlugin(),
 		loaddynamic.NewPlugin(),
 		s.extensionPlugin,
@@ -236,7 +264,20 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 		probe.NewPlugin(),
 		tfv1alpha1.NewPlugin(),
 		hasher.NewPlugin(),
-	)
+	}
+}
+
+// Load loads the Tiltfile in `filename`, and returns the manifests matching `matching`.
+//
+// This often returns a starkit.Model even on error, because the starkit.Model
+// has a record of what happened during the execution (what files were read, etc).
+//
+// TODO(nick): Eventually this will just return a starkit.Model, which will contain
+// all the mutable state collected by execution.
+func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest, starkit.Model, error) {
+	s.logger.Infof("Loading Tiltfile at: %s", tf.Spec.Path)
+
+	result, err := starkit.ExecFile(tf, s.plugins()...)
 	if err != nil {
 		return nil, result, starkit.UnpackBacktrace(err)
 	}
@@ -269,9 +310,8 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 		}
 		manifests = append(manifests, ms...)
 
-		isAllowed := k8sContextState.IsAllowed(tf)
+		kubeContext, isAllowed := s.firstDisallowedK8sContext(tf, k8sContextState)
 		if !isAllowed {
-			kubeContext := k8sContextState.KubeContext()
 			return nil, result, fmt.Errorf(`Stop! %s might be production.
 If you're sure you want to deploy there, add:
 	allow_k8s_contexts('%s')
@@ -280,10 +320,6 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 	}
 
 	if !resources.dc.Empty() {
-		if err := s.validateDockerComposeVersion(); err != nil {
-			return nil, result, err
-		}
-
 		ms, err := s.translateDC(resources.dc)
 		if err != nil {
 			return nil, result, err
@@ -307,21 +343,11 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 	}
 	manifests = append(manifests, localManifests...)
 
-	if len(unresourced) > 0 {
-		mn := model.UnresourcedYAMLManifestName
-		r := &k8sResource{
-			name:             mn.String(),
-			entities:         unresourced,
-			podReadinessMode: model.PodReadinessIgnore,
-		}
-		kt, err := s.k8sDeployTarget(mn.TargetName(), r, nil, us)
-		if err != nil {
-			return nil, starkit.Model{}, err
-		}
-
-		yamlManifest := model.Manifest{Name: mn}.WithDeployTarget(kt)
-		manifests = append(manifests, yamlManifest)
+	unresourcedManifests, err := s.translateK8sUnresourced(us)
+	if err != nil {
+		return nil, starkit.Model{}, err
 	}
+	manifests = append(manifests, unresourcedManifests...)
 
 	err = s.sanitizeDependencies(manifests)
 	if err != nil {
@@ -369,6 +395,18 @@ const (
 	k8sImageJSONPathN           = "k8s_image_json_path"
 	workloadToResourceFunctionN = "workload_to_resource_function"
 	k8sCustomDeployN            = "k8s_custom_deploy"
+	helmReleaseN                = "helm_release"
+	k8sLocalClusterN            = "k8s_local_cluster"
+	k8sClusterN                 = "k8s_cluster"
+
+	// k8s object helpers
+	k8sSelectN         = "k8s.select"
+	k8sSetImageN       = "k8s.set_image"
+	k8sSetEnvN         = "k8s.set_env"
+	k8sSetResourcesN   = "k8s.set_resources"
+	k8sSetReplicasN    = "k8s.set_replicas"
+	k8sSetAnnotationsN = "k8s.set_annotations"
+	k8sPatchN          = "k8s.patch"
 
 	// local resource functions
 	localResourceN = "local_resource"
@@ -491,9 +529,8 @@ func (s *tiltfileState) potentiallyK8sUnsafeBuiltin(f starkit.Function) starkit.
 			return nil, err
 		}
 
-		isAllowed := k8sContextState.IsAllowed(tf)
+		kubeContext, isAllowed := s.firstDisallowedK8sContext(tf, k8sContextState)
 		if !isAllowed {
-			kubeContext := k8sContextState.KubeContext()
 			return nil, fmt.Errorf(`Refusing to run '%s' because %s might be a production kube context.
 If you're sure you want to continue add:
 	allow_k8s_contexts('%s')
@@ -504,6 +541,29 @@ before this function call in your Tiltfile. Otherwise, switch k8s contexts and r
 	}
 }
 
+// Checks the current k8s context, then the context of each cluster
+// declared with k8s_cluster(). Returns the first one that we're not
+// allowed to deploy to.
+func (s *tiltfileState) firstDisallowedK8sContext(tf *v1alpha1.Tiltfile, k8sContextState k8scontext.State) (k8s.KubeContext, bool) {
+	if !k8sContextState.IsAllowed(tf) {
+		return k8sContextState.KubeContext(), false
+	}
+
+	names := make([]string, 0, len(s.k8sClusters))
+	for name := range s.k8sClusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		conn := s.k8sClusters[name]
+		if !k8sContextState.IsClusterAllowed(tf, conn) {
+			return k8s.KubeContext(conn.Context), false
+		}
+	}
+	return "", true
+}
+
 func (s *tiltfileState) unpackArgs(fnname string, args starlark.Tuple, kwargs []starlark.Tuple, pairs ...interface{}) error {
 	err := starlark.UnpackArgs(fnname, args, kwargs, pairs...)
 	if err == nil {
@@ -551,10 +611,20 @@ func (s *tiltfileState) OnStart(e *starkit.Environment) error {
 		{filterYamlN, s.filterYaml},
 		{k8sResourceN, s.k8sResource},
 		{k8sCustomDeployN, s.k8sCustomDeploy},
+		{helmReleaseN, s.helmRelease},
+		{k8sSelectN, s.k8sSelect},
+		{k8sSetImageN, s.k8sSetImage},
+		{k8sSetEnvN, s.k8sSetEnv},
+		{k8sSetResourcesN, s.k8sSetResources},
+		{k8sSetReplicasN, s.k8sSetReplicas},
+		{k8sSetAnnotationsN, s.k8sSetAnnotations},
+		{k8sPatchN, s.k8sPatch},
 		{localResourceN, s.localResource},
 		{testN, s.localResource},
 		{portForwardN, s.portForward},
 		{k8sKindN, s.k8sKind},
+		{k8sLocalClusterN, s.k8sLocalCluster},
+		{k8sClusterN, s.k8sCluster},
 		{k8sImageJSONPathN, s.k8sImageJsonPath},
 		{workloadToResourceFunctionN, s.workloadToResourceFunctionFn},
 		{kustomizeN, s.kustomize},
@@ -610,7 +680,7 @@ func (s *tiltfileState) assemble() (resourceSet, []k8s.K8sEntity, error) {
 	return resourceSet{
 		dc:  s.dc,
 		k8s: s.k8s,
-	}, s.k8sUnresourced, nil
+	}, s.allUnresourced(), nil
 }
 
 // Emit an error if there are unmatches images.
@@ -635,11 +705,12 @@ func (s *tiltfileState) assertAllImagesMatched(us model.UpdateSettings) error {
 		return nil
 	}
 
-	if len(s.dc.services) == 0 && len(s.k8s) == 0 && len(s.k8sUnresourced) == 0 {
+	unresourced := s.allUnresourced()
+	if len(s.dc.services) == 0 && len(s.k8s) == 0 && len(unresourced) == 0 {
 		return fmt.Errorf(unmatchedImageNoConfigsWarning)
 	}
 
-	if len(s.k8s) == 0 && len(s.k8sUnresourced) != 0 {
+	if len(s.k8s) == 0 && len(unresourced) != 0 {
 		return fmt.Errorf(unmatchedImageAllUnresourcedWarning)
 	}
 
@@ -766,7 +837,7 @@ func (s *tiltfileState) assembleK8s() error {
 		resourcedEntities = append(resourcedEntities, r.entities...)
 	}
 
-	allEntities := append(resourcedEntities, s.k8sUnresourced...)
+	allEntities := append(resourcedEntities, s.allUnresourced()...)
 
 	fragmentsToEntities := k8s.FragmentsToEntities(allEntities)
 
@@ -793,6 +864,9 @@ func (s *tiltfileState) assembleK8s() error {
 			if opts.discoveryStrategy != "" {
 				r.discoveryStrategy = opts.discoveryStrategy
 			}
+			if opts.cluster != "" {
+				r.cluster = opts.cluster
+			}
 			r.portForwards = append(r.portForwards, opts.portForwards...)
 			if opts.triggerMode != TriggerModeUnset {
 				r.triggerMode = opts.triggerMode
@@ -838,11 +912,12 @@ func (s *tiltfileState) assembleK8s() error {
 					return fmt.Errorf("%q is not a unique fragment. Objects that match %q are %s", o, o, sliceutils.QuotedStringList(matchingObjects))
 				}
 
-				entitiesToRemove := filterEntitiesBySelector(s.k8sUnresourced, selectors[i])
+				unresourced := s.allUnresourced()
+				entitiesToRemove := filterEntitiesBySelector(unresourced, selectors[i])
 				if len(entitiesToRemove) == 0 {
 					// we've already taken these entities out of unresourced
-					remainingUnresourced := make([]string, len(s.k8sUnresourced))
-					for i, entity := range s.k8sUnresourced {
+					remainingUnresourced := make([]string, len(unresourced))
+					for i, entity := range unresourced {
 						remainingUnresourced[i] = fullNameFromK8sEntity(entity)
 					}
 					return fmt.Errorf("No object identified by the fragment %q could be found in remaining YAML. Valid remaining fragments are: %s", o, sliceutils.QuotedStringList(remainingUnresourced))
@@ -865,6 +940,9 @@ func (s *tiltfileState) assembleK8s() error {
 	}
 
 	for _, r := range s.k8s {
+		if err := s.assignK8sCluster(r); err != nil {
+			return err
+		}
 		if err := s.validateK8s(r); err != nil {
 			return err
 		}
@@ -872,6 +950,83 @@ func (s *tiltfileState) assembleK8s() error {
 	return nil
 }
 
+// The clusters with objects that aren't in a resource yet: objects
+// loaded without a cluster first, then each named cluster in order.
+func (s *tiltfileState) unresourcedClusters() []string {
+	clusters := []string{""}
+	for cluster := range s.k8sClusterUnresourced {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters[1:])
+	return clusters
+}
+
+func (s *tiltfileState) unresourced(cluster string) []k8s.K8sEntity {
+	if cluster == "" {
+		return s.k8sUnresourced
+	}
+	return s.k8sClusterUnresourced[cluster]
+}
+
+func (s *tiltfileState) setUnresourced(cluster string, entities []k8s.K8sEntity) {
+	if cluster == "" {
+		s.k8sUnresourced = entities
+		return
+	}
+	s.k8sClusterUnresourced[cluster] = entities
+}
+
+// All the objects that aren't in a resource yet, in any cluster.
+func (s *tiltfileState) allUnresourced() []k8s.K8sEntity {
+	var result []k8s.K8sEntity
+	for _, cluster := range s.unresourcedClusters() {
+		result = append(result, s.unresourced(cluster)...)
+	}
+	return result
+}
+
+// Each resource deploys to exactly one cluster: the one named by
+// k8s_resource(), or else the one its objects were loaded for.
+//
+// Objects loaded without a cluster follow the resource, so
+// k8s_resource(cluster=...) can move them.
+func (s *tiltfileState) assignK8sCluster(r *k8sResource) error {
+	explicit := r.cluster != ""
+	hasUntagged := false
+	for _, cluster := range r.yamlClusters {
+		if cluster == "" {
+			hasUntagged = true
+			continue
+		}
+		if r.cluster == "" {
+			r.cluster = cluster
+		} else if r.cluster != cluster {
+			return fmt.Errorf("resource %q: can't deploy to both cluster %q and cluster %q",
+				r.name, r.cluster, cluster)
+		}
+	}
+
+	if !explicit && hasUntagged && r.cluster != "" && r.cluster != v1alpha1.ClusterNameDefault {
+		return fmt.Errorf("resource %q: can't deploy to both cluster %q and cluster %q",
+			r.name, v1alpha1.ClusterNameDefault, r.cluster)
+	}
+
+	if r.cluster == "" {
+		r.cluster = v1alpha1.ClusterNameDefault
+	}
+	if err := s.checkK8sCluster(r.cluster); err != nil {
+		return fmt.Errorf("resource %q: %v", r.name, err)
+	}
+	return nil
+}
+
+func (s *tiltfileState) checkK8sCluster(cluster string) error {
+	if cluster == v1alpha1.ClusterNameDefault || s.k8sClusters[cluster] != nil {
+		return nil
+	}
+	return fmt.Errorf("unknown cluster %q. Declare it with k8s_cluster()", cluster)
+}
+
 // NOTE(dmiller): This isn't _technically_ a fullname since it is missing "group" (core, apps, data, etc)
 // A true full name would look like "foo:secret:mynamespace:core"
 // However because we
@@ -900,11 +1055,15 @@ func filterEntitiesBySelector(entities []k8s.K8sEntity, sel k8s.ObjectSelector)
 
 func (s *tiltfileState) addEntityToResourceAndRemoveFromUnresourced(e k8s.K8sEntity, r *k8sResource) {
 	r.entities = append(r.entities, e)
-	for i, ur := range s.k8sUnresourced {
-		if ur == e {
-			// delete from unresourced
-			s.k8sUnresourced = append(s.k8sUnresourced[:i], s.k8sUnresourced[i+1:]...)
-			return
+	for _, cluster := range s.unresourcedClusters() {
+		unresourced := s.unresourced(cluster)
+		for i, ur := range unresourced {
+			if ur == e {
+				// delete from unresourced
+				s.setUnresourced(cluster, append(unresourced[:i], unresourced[i+1:]...))
+				r.addYAMLCluster(cluster)
+				return
+			}
 		}
 	}
 
@@ -914,19 +1073,24 @@ func (s *tiltfileState) addEntityToResourceAndRemoveFromUnresourced(e k8s.K8sEnt
 func (s *tiltfileState) assembleK8sByWorkload() error {
 	locators := s.k8sImageLocatorsList()
 
-	var workloads, rest []k8s.K8sEntity
-	for _, e := range s.k8sUnresourced {
-		isWorkload, err := s.isWorkload(e, locators)
-		if err != nil {
-			return err
-		}
-		if isWorkload {
-			workloads = append(workloads, e)
-		} else {
-			rest = append(rest, e)
+	var workloads []k8s.K8sEntity
+	var workloadClusters []string
+	for _, cluster := range s.unresourcedClusters() {
+		var rest []k8s.K8sEntity
+		for _, e := range s.unresourced(cluster) {
+			isWorkload, err := s.isWorkload(e, locators)
+			if err != nil {
+				return err
+			}
+			if isWorkload {
+				workloads = append(workloads, e)
+				workloadClusters = append(workloadClusters, cluster)
+			} else {
+				rest = append(rest, e)
+			}
 		}
+		s.setUnresourced(cluster, rest)
 	}
-	s.k8sUnresourced = rest
 
 	resourceNames, err := s.calculateResourceNames(workloads)
 	if err != nil {
@@ -935,6 +1099,7 @@ func (s *tiltfileState) assembleK8sByWorkload() error {
 
 	for i, resourceName := range resourceNames {
 		workload := workloads[i]
+		cluster := workloadClusters[i]
 		res, err := s.makeK8sResource(resourceName)
 		if err != nil {
 			return errors.Wrapf(err, "error making resource for workload %s", newK8sObjectID(workload))
@@ -943,10 +1108,11 @@ func (s *tiltfileState) assembleK8sByWorkload() error {
 		if err != nil {
 			return err
 		}
+		res.addYAMLCluster(cluster)
 
-		// find any other entities that match the workload's labels (e.g., services),
-		// and move them from unresourced to this resource
-		match, rest, err := k8s.FilterByMatchesPodTemplateSpec(workload, s.k8sUnresourced)
+		// find any other entities in the same cluster that match the workload's
+		// labels (e.g., services), and move them from unresourced to this resource
+		match, rest, err := k8s.FilterByMatchesPodTemplateSpec(workload, s.unresourced(cluster))
 		if err != nil {
 			return err
 		}
@@ -956,7 +1122,7 @@ func (s *tiltfileState) assembleK8sByWorkload() error {
 			return err
 		}
 
-		s.k8sUnresourced = rest
+		s.setUnresourced(cluster, rest)
 	}
 
 	return nil
@@ -996,33 +1162,36 @@ func (s *tiltfileState) isWorkload(e k8s.K8sEntity, locators []k8s.ImageLocator)
 // (We smartly grouping pod-creating entities with some kinds of
 // corresponding entities, e.g. services),
 func (s *tiltfileState) assembleK8sUnresourced() error {
-	withPodSpec, allRest, err := k8s.FilterByHasPodTemplateSpec(s.k8sUnresourced)
-	if err != nil {
-		return nil
-	}
-	for _, e := range withPodSpec {
-		target, err := s.k8sResourceForName(e.Name())
+	for _, cluster := range s.unresourcedClusters() {
+		withPodSpec, allRest, err := k8s.FilterByHasPodTemplateSpec(s.unresourced(cluster))
 		if err != nil {
-			return err
+			return nil
 		}
-		target.entities = append(target.entities, e)
+		for _, e := range withPodSpec {
+			target, err := s.k8sResourceForName(e.Name())
+			if err != nil {
+				return err
+			}
+			target.entities = append(target.entities, e)
+			target.addYAMLCluster(cluster)
 
-		match, rest, err := k8s.FilterByMatchesPodTemplateSpec(e, allRest)
-		if err != nil {
-			return err
+			match, rest, err := k8s.FilterByMatchesPodTemplateSpec(e, allRest)
+			if err != nil {
+				return err
+			}
+			target.entities = append(target.entities, match...)
+			allRest = rest
 		}
-		target.entities = append(target.entities, match...)
-		allRest = rest
-	}
 
-	s.k8sUnresourced = allRest
+		s.setUnresourced(cluster, allRest)
+	}
 
 	return nil
 }
 
 func (s *tiltfileState) validateK8s(r *k8sResource) error {
-	if len(r.entities) == 0 && r.customDeploy == nil {
-		return fmt.Errorf("resource %q: could not associate any k8s_yaml() or k8s_custom_deploy() with this resource", r.name)
+	if len(r.entities) == 0 && r.customDeploy == nil && r.helmRelease == nil {
+		return fmt.Errorf("resource %q: could not associate any k8s_yaml(), k8s_custom_deploy(), or helm_release() with this resource", r.name)
 	}
 
 	for _, ref := range r.imageRefs {
@@ -1156,6 +1325,48 @@ func (s *tiltfileState) translateK8s(resources []*k8sResource, updateSettings mo
 	return result, nil
 }
 
+// Objects that don't belong to any resource are deployed in one
+// manifest per cluster.
+func (s *tiltfileState) translateK8sUnresourced(us model.UpdateSettings) ([]model.Manifest, error) {
+	var clusters []string
+	byCluster := make(map[string][]k8s.K8sEntity)
+	for _, cluster := range s.unresourcedClusters() {
+		entities := s.unresourced(cluster)
+		if len(entities) == 0 {
+			continue
+		}
+		if cluster == "" {
+			cluster = v1alpha1.ClusterNameDefault
+		}
+		if _, ok := byCluster[cluster]; !ok {
+			clusters = append(clusters, cluster)
+		}
+		byCluster[cluster] = append(byCluster[cluster], entities...)
+	}
+
+	var result []model.Manifest
+	for _, cluster := range clusters {
+		mn := model.UnresourcedYAMLManifestNameForCluster(cluster)
+		if err := s.checkK8sCluster(cluster); err != nil {
+			return nil, fmt.Errorf("resource %q: %v", mn, err)
+		}
+
+		r := &k8sResource{
+			name:             mn.String(),
+			entities:         byCluster[cluster],
+			podReadinessMode: model.PodReadinessIgnore,
+			cluster:          cluster,
+		}
+		kt, err := s.k8sDeployTarget(mn.TargetName(), r, nil, us)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, model.Manifest{Name: mn}.WithDeployTarget(kt))
+	}
+	return result, nil
+}
+
 func (s *tiltfileState) k8sDeployTarget(targetName model.TargetName, r *k8sResource, imageTargets []model.ImageTarget, updateSettings model.UpdateSettings) (model.K8sTarget, error) {
 	var kdTemplateSpec *v1alpha1.KubernetesDiscoveryTemplateSpec
 	if len(r.extraPodSelectors) != 0 {
@@ -1165,8 +1376,12 @@ func (s *tiltfileState) k8sDeployTarget(targetName model.TargetName, r *k8sResou
 	}
 
 	sinceTime := apis.NewTime(pkgInitTime)
+	cluster := r.cluster
+	if cluster == "" {
+		cluster = v1alpha1.ClusterNameDefault
+	}
 	applySpec := v1alpha1.KubernetesApplySpec{
-		Cluster:                         v1alpha1.ClusterNameDefault,
+		Cluster:                         cluster,
 		Timeout:                         metav1.Duration{Duration: updateSettings.K8sUpsertTimeout()},
 		PortForwardTemplateSpec:         k8s.PortForwardTemplateSpec(s.defaultedPortForwards(r.portForwards)),
 		DiscoveryStrategy:               r.discoveryStrategy,
@@ -1190,6 +1405,31 @@ func (s *tiltfileState) k8sDeployTarget(targetName model.TargetName, r *k8sResou
 		applySpec.RestartOn = &v1alpha1.RestartOnSpec{
 			FileWatches: []string{apis.SanitizeName(fmt.Sprintf("%s:apply", targetName.String()))},
 		}
+	} else if r.helmRelease != nil {
+		hr := r.helmRelease
+		deps = hr.deps
+		applySpec.Timeout = metav1.Duration{Duration: hr.timeout}
+		applySpec.HelmRelease = &v1alpha1.KubernetesApplyHelmRelease{
+			ReleaseName:     hr.releaseName,
+			Namespace:       hr.namespace,
+			Chart:           hr.chart,
+			ValueFiles:      hr.valueFiles,
+			Set:             hr.set,
+			CreateNamespace: hr.createNamespace,
+			Atomic:          hr.atomic,
+		}
+		for _, ik := range hr.imageKeys {
+			builder := s.buildIndex.findBuilderForConsumedImage(ik.ref)
+			if builder == nil {
+				continue
+			}
+			keys := ik.keys
+			keys.ImageMap = builder.ImageMapName()
+			applySpec.HelmRelease.ImageKeys = append(applySpec.HelmRelease.ImageKeys, keys)
+		}
+		applySpec.RestartOn = &v1alpha1.RestartOnSpec{
+			FileWatches: []string{apis.SanitizeName(fmt.Sprintf("%s:apply", targetName.String()))},
+		}
 	} else {
 		entities := k8s.SortedEntities(r.entities)
 		var err error
@@ -1327,27 +1567,6 @@ func (s *tiltfileState) validateLiveUpdate(iTarget model.ImageTarget, g model.Ta
 	return nil
 }
 
-func (s *tiltfileState) validateDockerComposeVersion() error {
-	const minimumDockerComposeVersion = "v1.28.3"
-
-	dcVersion, _, err := s.dcCli.Version(s.ctx)
-	if err != nil {
-		logger.Get(s.ctx).Debugf("Failed to determine Docker Compose version: %v", err)
-	} else if semver.Compare(dcVersion, minimumDockerComposeVersion) == -1 {
-		return fmt.Errorf(
-			"Tilt requires Docker Compose %s+ (you have %s). Please upgrade and re-launch Tilt.",
-			minimumDockerComposeVersion,
-			dcVersion)
-	} else if semver.Major(dcVersion) == "v2" && semver.Compare(dcVersion, "v2.2") < 0 {
-		logger.Get(s.ctx).Warnf("Using Docker Compose %s (version < 2.2) may result in errors or broken functionality.\n"+
-			"For best results, we recommend upgrading to Docker Compose >= v2.2.0.", dcVersion)
-	} else if semver.Prerelease(dcVersion) != "" {
-		logger.Get(s.ctx).Warnf("You are running a pre-release version of Docker Compose (%s), which is unsupported.\n"+
-			"You might encounter errors or broken functionality.", dcVersion)
-	}
-	return nil
-}
-
 func maybeRestartContainerDeprecationError(manifests []model.Manifest) error {
 	var needsError []model.ManifestName
 	for _, m := range manifests {
@@ -1441,11 +1660,16 @@ func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapD
 				Secrets:            image.secretSpecs,
 				Network:            image.network,
 				CacheFrom:          image.cacheFrom,
+				CacheTo:            image.cacheTo,
 				Pull:               image.pullParent,
-				Platform:           image.platform,
 				ExtraTags:          image.extraTags,
 				ContextIgnores:     contextIgnores,
 			}
+			if len(image.platforms) == 1 {
+				spec.Platform = image.platforms[0]
+			} else {
+				spec.Platforms = image.platforms
+			}
 			iTarget = iTarget.WithBuildDetails(model.DockerBuild{DockerImageSpec: spec})
 		case CustomBuild:
 			iTarget.CmdImageName = cmdimage.GetName(mn, iTarget.ID())
@@ -19,6 +19,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/tilt-dev/clusterid"
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
@@ -43,6 +44,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/tiltfile/testdata"
 	"github.com/tilt-dev/tilt/internal/tiltfile/tiltextension"
 	"github.com/tilt-dev/tilt/internal/tiltfile/version"
+	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/internal/yaml"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -463,6 +465,100 @@ func TestLocalArgvCmd(t *testing.T) {
 	assert.Contains(t, f.out.String(), `a"b`)
 }
 
+func TestLocalAsync(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	f := newFixture(t)
+
+	f.setupFoo()
+
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+yaml = local('cat foo.yaml', async=True)
+print(type(yaml))
+k8s_yaml(yaml)
+`)
+
+	f.load()
+
+	f.assertNextManifest("foo",
+		db(image("gcr.io/foo")),
+		deployment("foo"))
+	assert.Contains(t, f.out.String(), "blob")
+	assert.Contains(t, f.out.String(), "local: cat foo.yaml")
+	assert.Contains(t, f.out.String(), " → kind: Deployment")
+}
+
+func TestLocalAsyncConcurrent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+a = local('sleep 0.3 && echo a', async=True)
+b = local('sleep 0.3 && echo b', async=True)
+watch_settings(ignore=[str(a).strip(), str(b).strip()])
+`)
+
+	start := time.Now()
+	f.load()
+	assert.Less(t, time.Since(start), 550*time.Millisecond)
+	assert.Equal(t, []string{"a", "b"}, f.loadResult.WatchSettings.Ignores[0].Patterns)
+
+	// Output isn't interleaved.
+	assert.Contains(t, f.out.String(), "local: sleep 0.3 && echo a\n → a\n")
+	assert.Contains(t, f.out.String(), "local: sleep 0.3 && echo b\n → b\n")
+}
+
+func TestLocalAsyncError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local('exit 1', async=True)
+`)
+
+	f.loadErrString("local(async=True)", "exit status 1")
+}
+
+func TestLocalAsyncErrorWhenUsed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+out = local('exit 1', async=True)
+decode_yaml(out)
+print('after decode_yaml')
+`)
+
+	f.loadErrString("decode_yaml", "local(async=True)", "exit status 1")
+	assert.NotContains(t, f.out.String(), "after decode_yaml")
+}
+
+func TestLocalAsyncErrorWhenStringified(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+out = local('exit 1', async=True)
+if not str(out):
+  print('empty output')
+print('after str')
+`)
+
+	f.loadErrString("local(async=True)", "exit status 1")
+	assert.NotContains(t, f.out.String(), "empty output")
+	assert.NotContains(t, f.out.String(), "after str")
+}
+
 func TestLocalTiltEnvPropagation(t *testing.T) {
 	f := newFixture(t)
 
@@ -535,6 +631,42 @@ k8s_yaml(yaml)
 	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
 }
 
+func TestTemplate(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("deployment.yaml.tmpl", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ name }}
+spec:
+  selector:
+    matchLabels:
+      app: {{ name }}
+  template:
+    metadata:
+      labels:
+        app: {{ name }}
+    spec:
+      containers:
+{% for image in images %}
+      - name: {{ image|cut:"gcr.io/" }}
+        image: {{ image }}
+{% endfor %}
+`)
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml(template('deployment.yaml.tmpl', {'name': 'foo', 'images': ['gcr.io/foo']}))
+`)
+
+	f.load()
+
+	f.assertNextManifest("foo",
+		db(image("gcr.io/foo")),
+		deployment("foo"))
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "deployment.yaml.tmpl")
+}
+
 func TestKustomize(t *testing.T) {
 	f := newFixture(t)
 
@@ -554,6 +686,25 @@ k8s_resource("the-deployment", "foo")
 	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "configMap.yaml", "deployment.yaml", "kustomization.yaml", "service.yaml")
 }
 
+func TestKustomizeAsync(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("kustomization.yaml", kustomizeFileText)
+	f.file("configMap.yaml", kustomizeConfigMapText)
+	f.file("deployment.yaml", kustomizeDeploymentText)
+	f.file("service.yaml", kustomizeServiceText)
+	f.file("Tiltfile", `
+
+docker_build("gcr.io/foo", "foo")
+k8s_yaml([kustomize(".", async=True)])
+k8s_resource("the-deployment", "foo")
+`)
+	f.load()
+	f.assertNextManifest("foo", deployment("the-deployment"), numEntities(2))
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "configMap.yaml", "deployment.yaml", "kustomization.yaml", "service.yaml")
+}
+
 func TestKustomizeBin(t *testing.T) {
 	f := newFixture(t)
 	f.file("kustomization.yaml", kustomizeFileText)
@@ -587,6 +738,41 @@ k8s_resource("the-deployment", "foo")
 	assert.EqualValues(t, "build .", strings.Trim(string(sentinelContents), " \r\n"))
 }
 
+func TestKustomizeInProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	f := newFixture(t)
+	f.setupFoo()
+	f.file("kustomization.yaml", kustomizeFileText)
+	f.file("configMap.yaml", kustomizeConfigMapText)
+	f.file("deployment.yaml", kustomizeDeploymentText)
+	f.file("service.yaml", kustomizeServiceText)
+
+	// Binaries on the PATH that would fail, and leave a trace, if we ran them.
+	sentinel := f.WriteFile("called.txt", "")
+	for _, name := range []string{"kustomize", "kubectl"} {
+		bin := f.WriteFile(filepath.Join("bin", name), fmt.Sprintf(`#!/bin/sh
+echo %s >> %s
+exit 1
+`, name, sentinel))
+		_ = os.Chmod(bin, 0755)
+	}
+	t.Setenv("PATH", f.JoinPath("bin")+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	f.file("Tiltfile", `
+docker_build("gcr.io/foo", "foo")
+k8s_yaml(kustomize("."))
+k8s_resource("the-deployment", "foo")
+`)
+	f.load()
+	f.assertNextManifest("foo", deployment("the-deployment"), numEntities(2))
+
+	sentinelContents, err := os.ReadFile(sentinel)
+	require.NoError(t, err)
+	assert.Empty(t, string(sentinelContents))
+}
+
 func TestKustomizeError(t *testing.T) {
 	f := newFixture(t)
 
@@ -613,6 +799,26 @@ k8s_resource("the-deployment", "foo")
 	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "configMap.yaml", "deployment.yaml", "Kustomization", "service.yaml")
 }
 
+func TestKustomizeHelmChart(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupHelm()
+	f.file("kustomization.yaml", `
+helmGlobals:
+  chartHome: .
+helmCharts:
+- name: helm
+  releaseName: rose-quartz
+  valuesFile: dev/helm/values-dev.yaml
+`)
+	f.file("Tiltfile", `
+k8s_yaml(kustomize("."))
+`)
+	f.load()
+	f.assertNextManifestUnresourced("rose-quartz-helloworld-chart")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "kustomization.yaml", "helm", "dev/helm/values-dev.yaml")
+}
+
 func TestDockerBuildTarget(t *testing.T) {
 	f := newFixture(t)
 
@@ -1288,6 +1494,29 @@ k8s_yaml('service.yaml')
 	require.Equal(t, []model.ManifestName{"foo", "uncategorized"}, f.loadResult.EnabledManifests)
 }
 
+func TestResourceNamedLikeUncategorizedNotAlwaysEnabled(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFooAndBar()
+	f.yaml("edge-secrets.yaml", secret("edge-secret"))
+
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='kind-edge')
+
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml('bar.yaml')
+k8s_resource('bar', new_name='uncategorized-jobs')
+
+k8s_yaml('edge-secrets.yaml', cluster='edge')
+`)
+
+	f.load("foo")
+	require.Equal(t, []model.ManifestName{"foo", "uncategorized-edge"}, f.loadResult.EnabledManifests)
+}
+
 func TestLoadTypoManifest(t *testing.T) {
 	f := newFixture(t)
 
@@ -2969,6 +3198,241 @@ default_registry('bar.com')
 	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
 }
 
+func TestK8sLocalCluster(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_local_cluster('kind', nodes=2, kubernetes_version='v1.27.3', registry=True, registry_port=5001)
+k8s_yaml('foo.yaml')
+`)
+
+	f.load()
+
+	assert.Equal(t, &v1alpha1.ClusterProvision{
+		Product:           "kind",
+		Nodes:             2,
+		KubernetesVersion: "v1.27.3",
+		Registry:          &v1alpha1.ClusterProvisionRegistry{Port: 5001},
+	}, f.loadResult.ClusterProvision)
+}
+
+func TestK8sLocalClusterInvalid(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_local_cluster('minikube')
+`)
+
+	f.loadErrString(`k8s_local_cluster: .spec.provision.product: Unsupported value: "minikube"`)
+}
+
+func TestK8sLocalClusterRegistryPortWithoutRegistry(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_local_cluster('k3d', registry_port=5001)
+`)
+
+	f.loadErrString("k8s_local_cluster: registry_port requires registry=True")
+}
+
+func TestK8sLocalClusterTwice(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_local_cluster('kind')
+k8s_local_cluster('k3d')
+`)
+
+	f.loadErrString("k8s_local_cluster: local cluster already defined")
+}
+
+func TestK8sCluster(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='kind-edge', namespace='edge-ns')
+k8s_yaml('foo.yaml')
+k8s_yaml('bar.yaml', cluster='edge')
+`)
+
+	f.load()
+
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, v1alpha1.ClusterNameDefault, foo.K8sTarget().KubernetesApplySpec.Cluster)
+	assert.Equal(t, v1alpha1.ClusterNameDefault, foo.ClusterName())
+
+	bar := f.assertNextManifest("bar")
+	assert.Equal(t, "edge", bar.K8sTarget().KubernetesApplySpec.Cluster)
+	assert.Equal(t, "edge", bar.ClusterName())
+
+	assert.Equal(t, map[string]*v1alpha1.KubernetesClusterConnection{
+		"edge": {Context: "kind-edge", Namespace: "edge-ns"},
+	}, f.loadResult.K8sClusters)
+}
+
+func TestK8sResourceCluster(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='kind-edge')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('bar', cluster='edge')
+`)
+
+	f.load()
+
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, v1alpha1.ClusterNameDefault, foo.K8sTarget().KubernetesApplySpec.Cluster)
+	bar := f.assertNextManifest("bar")
+	assert.Equal(t, "edge", bar.K8sTarget().KubernetesApplySpec.Cluster)
+}
+
+func TestK8sClusterUnresourced(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("secrets.yaml", secret("default-secret"))
+	f.yaml("edge-secrets.yaml", secret("edge-secret"))
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='kind-edge')
+k8s_yaml('secrets.yaml')
+k8s_yaml('edge-secrets.yaml', cluster='edge')
+`)
+
+	f.load()
+
+	f.assertNextManifestUnresourced("default-secret")
+	edge := f.assertNextManifest("uncategorized-edge")
+	assert.Equal(t, "edge", edge.K8sTarget().KubernetesApplySpec.Cluster)
+	assert.Contains(t, edge.K8sTarget().YAML, "edge-secret")
+}
+
+func TestK8sClusterServiceNotGroupedAcrossClusters(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("default.yaml",
+		deployment("foo", withLabels(map[string]string{"app": "foo"})),
+		service("foo-svc", withLabels(map[string]string{"app": "foo"})))
+	f.yaml("edge.yaml", service("edge-svc", withLabels(map[string]string{"app": "foo"})))
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='kind-edge')
+k8s_yaml('default.yaml')
+k8s_yaml('edge.yaml', cluster='edge')
+`)
+
+	f.load()
+
+	foo := f.assertNextManifest("foo")
+	assert.Contains(t, foo.K8sTarget().YAML, "foo-svc")
+	assert.NotContains(t, foo.K8sTarget().YAML, "edge-svc")
+	f.assertNextManifest("uncategorized-edge")
+}
+
+func TestK8sClusterUnknown(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml', cluster='edge')
+`)
+
+	f.loadErrString(`resource "foo": unknown cluster "edge". Declare it with k8s_cluster()`)
+}
+
+func TestK8sClusterConflict(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.yaml("edge-secrets.yaml", secret("edge-secret"))
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='kind-edge')
+k8s_yaml('foo.yaml')
+k8s_yaml('edge-secrets.yaml', cluster='edge')
+k8s_resource('foo', objects=['edge-secret'])
+`)
+
+	f.loadErrString(`resource "foo": can't deploy to both cluster`)
+}
+
+func TestK8sClusterReservedName(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_cluster('default', context='kind-kind')
+`)
+
+	f.loadErrString(`k8s_cluster: cluster name "default" is reserved`)
+}
+
+func TestK8sClusterInvalidName(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_cluster('Edge_1', context='kind-edge')
+`)
+
+	f.loadErrString(`k8s_cluster: invalid cluster name "Edge_1"`)
+}
+
+func TestK8sClusterNotAllowed(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_cluster('prod', context='gke_prod')
+k8s_yaml('foo.yaml', cluster='prod')
+`)
+
+	f.loadErrString("Stop! gke_prod might be production", "allow_k8s_contexts('gke_prod')")
+
+	f.file("Tiltfile", `
+allow_k8s_contexts('gke_prod')
+k8s_cluster('prod', context='gke_prod')
+k8s_yaml('foo.yaml', cluster='prod')
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, "prod", foo.ClusterName())
+}
+
+func TestK8sClusterNotAllowedLocal(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_cluster('prod', context='gke_prod')
+local('echo hi')
+`)
+
+	f.loadErrString("Refusing to run 'local' because gke_prod might be a production kube context")
+}
+
+func TestK8sClusterUnknownContextNotAllowed(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='not-in-kubeconfig')
+k8s_yaml('foo.yaml', cluster='edge')
+`)
+
+	f.loadErrString("Stop! not-in-kubeconfig might be production")
+}
+
+func TestK8sClusterTwice(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='kind-edge')
+k8s_cluster('edge', context='kind-edge2')
+`)
+
+	f.loadErrString(`k8s_cluster: cluster "edge" already defined`)
+}
+
 func TestDefaultRegistryTwoImagesOnlyDifferByTag(t *testing.T) {
 	f := newFixture(t)
 
@@ -4224,6 +4688,25 @@ allow_k8s_contexts("allowed-context")
 	f.loadErrString("If you're sure", "switch k8s contexts", "allow_k8s_contexts")
 }
 
+func TestCheckK8SContextOfLocalCluster(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_local_cluster('kind')
+k8s_yaml('foo.yaml')
+local('echo hi')
+print(k8s_context())
+`)
+
+	// We deploy to the cluster we provision, so the startup context doesn't matter.
+	f.k8sContext = "gke"
+	f.k8sEnv = clusterid.ProductGKE
+
+	f.load()
+	assert.Contains(t, f.out.String(), "kind-tilt")
+}
+
 func TestLocalObeysAllowedK8sContexts(t *testing.T) {
 	for _, test := range []struct {
 		name                    string
@@ -4447,6 +4930,33 @@ local_resource("test", "echo hi", serve_cmd="sleep 1000", serve_env={"KEY1": "va
 	))
 }
 
+func TestLocalResourceDotenvFile(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("local.env", "KEY1=fromfile\nKEY2=fromfile\n")
+	f.file("Tiltfile", `
+local_resource("test", "echo hi", serve_cmd="sleep 1000", dotenv_file="local.env", env={"KEY2": "value2"})
+`)
+
+	f.load()
+	f.assertNumManifests(1)
+	f.assertNextManifest("test", localTarget(
+		updateCmd(f.Path(), "echo hi", []string{"KEY1=fromfile", "KEY2=value2"}),
+		serveCmd(f.Path(), "sleep 1000", []string{"KEY1=fromfile", "KEY2=fromfile"}),
+	))
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "local.env")
+}
+
+func TestLocalResourceDotenvFileMissing(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource("test", "echo hi", dotenv_file="local.env")
+`)
+
+	f.loadErrString("local_resource: dotenv_file", "local.env")
+}
+
 func TestLocalResourceUpdateCmdDir(t *testing.T) {
 	f := newFixture(t)
 
@@ -5752,6 +6262,7 @@ type fixture struct {
 	*tempdir.TempDirFixture
 	k8sContext k8s.KubeContext
 	k8sEnv     clusterid.Product
+	kubeconfig *clientcmdapi.Config
 	webHost    model.WebHost
 
 	ta *tiltanalytics.TiltAnalytics
@@ -5760,12 +6271,16 @@ type fixture struct {
 	loadResult TiltfileLoadResult
 	warnings   []string
 	features   feature.Defaults
+
+	// Shared across loads, like Tilt's cache dir is shared across restarts.
+	xdgBase xdg.FakeBase
 }
 
 func (f *fixture) newTiltfileLoader() TiltfileLoader {
 	dcc := dockercompose.NewDockerComposeClient(docker.LocalEnv{})
 
-	k8sContextPlugin := k8scontext.NewPlugin(f.k8sContext, f.k8sEnv)
+	k8sContextPlugin := k8scontext.ProvidePlugin(f.k8sContext, k8s.ProfileForProduct(f.k8sEnv),
+		k8s.APIConfigOrError{Config: f.kubeconfig}, k8s.ClusterConfig{})
 	versionPlugin := version.NewPlugin(model.TiltBuild{Version: "0.5.0"})
 	configPlugin := config.NewPlugin("up")
 	localEnv := localexec.DefaultEnv(12345, f.webHost)
@@ -5774,7 +6289,7 @@ func (f *fixture) newTiltfileLoader() TiltfileLoader {
 	extrr := tiltextension.NewFakeExtRepoReconciler(f.Path())
 	extPlugin := tiltextension.NewFakePlugin(extrr, extr)
 	return ProvideTiltfileLoader(f.ta, k8sContextPlugin, versionPlugin, configPlugin,
-		extPlugin, dcc, f.webHost, execer, f.features, f.k8sEnv)
+		extPlugin, dcc, f.webHost, execer, f.features, f.k8sEnv, f.xdgBase)
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -5798,7 +6313,14 @@ func newFixture(t *testing.T) *fixture {
 		ta:             ta,
 		k8sContext:     "fake-context",
 		k8sEnv:         clusterid.ProductDockerDesktop,
-		features:       features,
+		kubeconfig: &clientcmdapi.Config{
+			Contexts: map[string]*clientcmdapi.Context{
+				"kind-edge": {Cluster: "kind-edge"},
+				"gke_prod":  {Cluster: "gke_prod"},
+			},
+		},
+		features: features,
+		xdgBase:  xdg.FakeBase{Dir: t.TempDir()},
 	}
 
 	// Collect the warnings
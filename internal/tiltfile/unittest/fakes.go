@@ -0,0 +1,142 @@
+package unittest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/compose-spec/compose-go/types"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/helm"
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type fakeCommand struct {
+	stdout   string
+	stderr   string
+	exitCode int
+}
+
+type fakeURL struct {
+	contents string
+	status   int
+}
+
+// Fakes stand in for the outside world while a test runs, so that tests
+// are hermetic and fast.
+//
+// Anything that isn't faked is an error, rather than silently reaching
+// out to the real machine.
+type Fakes struct {
+	mu       sync.Mutex
+	commands map[string]fakeCommand
+	urls     map[string]fakeURL
+}
+
+var _ localexec.Execer = &Fakes{}
+
+func NewFakes() *Fakes {
+	return &Fakes{
+		commands: make(map[string]fakeCommand),
+		urls:     make(map[string]fakeURL),
+	}
+}
+
+func (f *Fakes) setCommand(cmd model.Cmd, c fakeCommand) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands[cmd.String()] = c
+}
+
+func (f *Fakes) setURL(url string, u fakeURL) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.urls[url] = u
+}
+
+// Run implements localexec.Execer, for local().
+func (f *Fakes) Run(ctx context.Context, cmd model.Cmd, runIO localexec.RunIO) (int, error) {
+	f.mu.Lock()
+	c, ok := f.commands[cmd.String()]
+	f.mu.Unlock()
+	if !ok {
+		return 1, fmt.Errorf("command is not faked in tests. To fake it, call fake.local(%q, stdout=...)", cmd.String())
+	}
+
+	if runIO.Stdout != nil {
+		_, _ = runIO.Stdout.Write([]byte(c.stdout))
+	}
+	if runIO.Stderr != nil {
+		_, _ = runIO.Stderr.Write([]byte(c.stderr))
+	}
+	return c.exitCode, nil
+}
+
+// FetchURL implements io.URLFetcher, for read_url().
+func (f *Fakes) FetchURL(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+	f.mu.Lock()
+	u, ok := f.urls[url]
+	f.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("url is not faked in tests. To fake it, call fake.url(%q, contents=...)", url)
+	}
+	if u.status < 200 || u.status >= 300 {
+		return nil, fmt.Errorf("fetching %s: server returned %d %s", url, u.status, http.StatusText(u.status))
+	}
+	return []byte(u.contents), nil
+}
+
+// FetchChart implements helm.ChartDownloadFunc, for remote charts in helm(),
+// helm_release() and kustomize(). Tests can only use local charts.
+func (f *Fakes) FetchChart(ref helm.ChartRef, cacheDir string) (string, error) {
+	return "", fmt.Errorf("remote charts can't be fetched in tests. Use a local chart instead of %s", ref)
+}
+
+// RunGit implements kustomize.GitRunner, for remote bases in kustomize().
+// Tests can only use local bases.
+func (f *Fakes) RunGit(ctx context.Context, dir string, args ...string) error {
+	return fmt.Errorf("remote kustomize bases can't be fetched in tests (git %s)", strings.Join(args, " "))
+}
+
+// A DockerComposeClient for docker_compose(), which reads the project
+// from disk. Tests never run containers, so anything else is an error.
+type DockerComposeClient struct{}
+
+var _ dockercompose.DockerComposeClient = DockerComposeClient{}
+
+var errDockerComposeInTests = fmt.Errorf("docker compose isn't available in tests")
+
+func (DockerComposeClient) Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, shouldBuild bool, stdout, stderr io.Writer) error {
+	return errDockerComposeInTests
+}
+
+func (DockerComposeClient) Down(ctx context.Context, spec v1alpha1.DockerComposeProject, stdout, stderr io.Writer) error {
+	return errDockerComposeInTests
+}
+
+func (DockerComposeClient) Rm(ctx context.Context, specs []v1alpha1.DockerComposeServiceSpec, stdout, stderr io.Writer) error {
+	return errDockerComposeInTests
+}
+
+func (DockerComposeClient) StreamLogs(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) io.ReadCloser {
+	return io.NopCloser(strings.NewReader(""))
+}
+
+func (DockerComposeClient) StreamEvents(ctx context.Context, spec v1alpha1.DockerComposeProject) (<-chan string, error) {
+	return nil, errDockerComposeInTests
+}
+
+func (DockerComposeClient) Project(ctx context.Context, spec v1alpha1.DockerComposeProject) (*types.Project, error) {
+	return dockercompose.LoadProject(spec)
+}
+
+func (DockerComposeClient) ContainerID(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (container.ID, error) {
+	return "", errDockerComposeInTests
+}
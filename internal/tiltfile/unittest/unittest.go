@@ -0,0 +1,247 @@
+// Package unittest adds the builtins that are only available in Tiltfile
+// tests (*_test.tilt files): assertions, and fakes for the outside world.
+package unittest
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// An assertion in a test didn't hold.
+//
+// Test runners report these as test failures, and any other error
+// as a test error.
+type AssertionError struct {
+	Message string
+}
+
+func (e AssertionError) Error() string {
+	return e.Message
+}
+
+func assertionErrorf(msg string, format string, args ...interface{}) error {
+	m := fmt.Sprintf(format, args...)
+	if msg != "" {
+		m = fmt.Sprintf("%s: %s", msg, m)
+	}
+	return AssertionError{Message: m}
+}
+
+type Plugin struct {
+	fakes *Fakes
+}
+
+func NewPlugin(fakes *Fakes) Plugin {
+	return Plugin{fakes: fakes}
+}
+
+func (p Plugin) OnStart(env *starkit.Environment) error {
+	for _, b := range []struct {
+		name string
+		fn   starkit.Function
+	}{
+		{"assert.equals", assertEquals},
+		{"assert.not_equals", assertNotEquals},
+		{"assert.true", assertTrue},
+		{"assert.false", assertFalse},
+		{"assert.contains", assertContains},
+		{"assert.fails", assertFails},
+		{"fake.local", p.fakeLocal},
+		{"fake.url", p.fakeURL},
+		{"fake.k8s_context", fakeK8sContext},
+	} {
+		err := env.AddBuiltin(b.name, b.fn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func assertEquals(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var expected, actual starlark.Value
+	var msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"expected", &expected,
+		"actual", &actual,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	eq, err := starlark.Equal(expected, actual)
+	if err != nil {
+		return nil, err
+	}
+	if !eq {
+		return nil, assertionErrorf(msg, "expected %s, got %s", expected, actual)
+	}
+	return starlark.None, nil
+}
+
+func assertNotEquals(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var unexpected, actual starlark.Value
+	var msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"unexpected", &unexpected,
+		"actual", &actual,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	eq, err := starlark.Equal(unexpected, actual)
+	if err != nil {
+		return nil, err
+	}
+	if eq {
+		return nil, assertionErrorf(msg, "expected a value other than %s", actual)
+	}
+	return starlark.None, nil
+}
+
+func assertTrue(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var cond starlark.Value
+	var msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"cond", &cond,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+	if !cond.Truth() {
+		return nil, assertionErrorf(msg, "expected a true value, got %s", cond)
+	}
+	return starlark.None, nil
+}
+
+func assertFalse(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var cond starlark.Value
+	var msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"cond", &cond,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+	if cond.Truth() {
+		return nil, assertionErrorf(msg, "expected a false value, got %s", cond)
+	}
+	return starlark.None, nil
+}
+
+func assertContains(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var container, item starlark.Value
+	var msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"container", &container,
+		"item", &item,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := starlark.Binary(syntax.IN, item, container)
+	if err != nil {
+		return nil, err
+	}
+	if !found.Truth() {
+		return nil, assertionErrorf(msg, "expected %s to contain %s", container, item)
+	}
+	return starlark.None, nil
+}
+
+// assert.fails(fn, contains=None, msg=None)
+//
+// Calls fn with no arguments, and returns its error message.
+func assertFails(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var callable starlark.Callable
+	var contains, msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"fn", &callable,
+		"contains?", &contains,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	_, callErr := starlark.Call(thread, callable, nil, nil)
+	if callErr == nil {
+		return nil, assertionErrorf(msg, "expected %s to fail", callable.Name())
+	}
+
+	errMsg := callErr.Error()
+	if evalErr, ok := callErr.(*starlark.EvalError); ok {
+		errMsg = evalErr.Msg
+	}
+	if !strings.Contains(errMsg, contains) {
+		return nil, assertionErrorf(msg, "expected error containing %q, got %q", contains, errMsg)
+	}
+	return starlark.String(errMsg), nil
+}
+
+// fake.local(command, stdout="", stderr="", exit_code=0)
+func (p Plugin) fakeLocal(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var command starlark.Value
+	var stdout, stderr string
+	var exitCode int
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"command", &command,
+		"stdout?", &stdout,
+		"stderr?", &stderr,
+		"exit_code?", &exitCode)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := value.ValueToHostCmd(thread, command, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	if cmd.Empty() {
+		return nil, fmt.Errorf("%s: command must not be empty", fn.Name())
+	}
+
+	p.fakes.setCommand(cmd, fakeCommand{stdout: stdout, stderr: stderr, exitCode: exitCode})
+	return starlark.None, nil
+}
+
+// fake.url(url, contents="", status=200)
+func (p Plugin) fakeURL(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var url, contents string
+	status := 200
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"url", &url,
+		"contents?", &contents,
+		"status?", &status)
+	if err != nil {
+		return nil, err
+	}
+
+	p.fakes.setURL(url, fakeURL{contents: contents, status: status})
+	return starlark.None, nil
+}
+
+// fake.k8s_context(name)
+func fakeK8sContext(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"name", &name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = k8scontext.SetKubeContext(thread, k8s.KubeContext(name))
+	if err != nil {
+		return nil, err
+	}
+	return starlark.None, nil
+}
@@ -0,0 +1,76 @@
+package unittest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+)
+
+func newFixture(t *testing.T) *starkit.Fixture {
+	return starkit.NewFixture(t, NewPlugin(NewFakes()))
+}
+
+func TestAssertionsPass(t *testing.T) {
+	f := newFixture(t)
+	f.File("Tiltfile", `
+assert.equals({'a': [1, 2]}, {'a': [1, 2]})
+assert.not_equals(1, 2)
+assert.true([1])
+assert.false('')
+assert.contains('hello world', 'world')
+assert.contains({'a': 1}, 'a')
+
+def boom():
+  fail('kaboom')
+
+msg = assert.fails(boom, contains='kab')
+assert.equals('fail: kaboom', msg)
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+}
+
+func TestAssertionErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		tiltfile string
+		expected string
+	}{
+		{"equals", `assert.equals(1, 2)`, "expected 1, got 2"},
+		{"equals msg", `assert.equals(1, 2, msg='replicas')`, "replicas: expected 1, got 2"},
+		{"not_equals", `assert.not_equals(1, 1)`, "expected a value other than 1"},
+		{"true", `assert.true(None)`, "expected a true value, got None"},
+		{"false", `assert.false(1)`, "expected a false value, got 1"},
+		{"contains", `assert.contains([1, 2], 3)`, "expected [1, 2] to contain 3"},
+		{"fails", `assert.fails(lambda: None)`, "expected lambda to fail"},
+		{"fails contains", `assert.fails(lambda: fail('x'), contains='y')`, `expected error containing "y", got "fail: x"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.File("Tiltfile", tc.tiltfile)
+
+			_, err := f.ExecFile("Tiltfile")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+
+			var assertErr AssertionError
+			assert.True(t, errors.As(err, &assertErr), "expected an AssertionError, got %T", err)
+		})
+	}
+}
+
+func TestNonAssertionError(t *testing.T) {
+	f := newFixture(t)
+	f.File("Tiltfile", `assert.equals(1)`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+
+	var assertErr AssertionError
+	assert.False(t, errors.As(err, &assertErr))
+}
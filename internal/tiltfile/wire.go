@@ -11,7 +11,8 @@ import (
 
 var WireSet = wire.NewSet(
 	ProvideTiltfileLoader,
-	k8scontext.NewPlugin,
+	ProvideTestRunner,
+	k8scontext.ProvidePlugin,
 	version.NewPlugin,
 	config.NewPlugin,
 	tiltextension.NewPlugin,
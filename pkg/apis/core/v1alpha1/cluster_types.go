@@ -75,6 +75,24 @@ type ClusterSpec struct {
 	//
 	// +optional
 	DefaultRegistry *RegistryHosting `json:"defaultRegistry,omitempty" protobuf:"bytes,2,opt,name=defaultRegistry"`
+
+	// Buildkit configures a standalone BuildKit daemon to build images
+	// for this Cluster, instead of building through the Docker API.
+	//
+	// If not specified, falls back to the TILT_BUILDKIT_HOST env variable.
+	// If neither is set, images are built with Docker.
+	//
+	// +optional
+	Buildkit *BuildkitConnection `json:"buildkit,omitempty" protobuf:"bytes,3,opt,name=buildkit"`
+
+	// Provision describes a local cluster for Tilt to create if it doesn't
+	// exist yet.
+	//
+	// Tilt connects to the provisioned cluster's kubeconfig context, and
+	// deletes the cluster on `tilt down --delete-cluster`.
+	//
+	// +optional
+	Provision *ClusterProvision `json:"provision,omitempty" protobuf:"bytes,4,opt,name=provision"`
 }
 
 // Connection spec for an existing cluster.
@@ -100,6 +118,52 @@ type KubernetesClusterConnection struct {
 	//
 	// +optional
 	Namespace string `json:"namespace,omitempty" protobuf:"bytes,2,opt,name=namespace"`
+
+	// The product name for this cluster (e.g., "kind", "vcluster").
+	//
+	// If not specified, Tilt detects the product from the kubeconfig.
+	// Useful for clusters that Tilt can't recognize on its own.
+	//
+	// +optional
+	Product string `json:"product,omitempty" protobuf:"bytes,3,opt,name=product"`
+
+	// Whether this is a local development cluster.
+	//
+	// Tilt refuses to deploy to a cluster it doesn't recognize as a dev cluster
+	// unless the context is listed in allow_k8s_contexts().
+	//
+	// If not specified, Tilt infers it from the product.
+	//
+	// +optional
+	DevCluster *bool `json:"devCluster,omitempty" protobuf:"varint,4,opt,name=devCluster"`
+
+	// How built images get into the cluster.
+	//
+	// If not specified, Tilt picks a strategy based on the product.
+	//
+	// +optional
+	ImageLoad ClusterImageLoad `json:"imageLoad,omitempty" protobuf:"bytes,5,opt,name=imageLoad,casttype=ClusterImageLoad"`
+}
+
+// ClusterImageLoad describes how built images get into a cluster.
+type ClusterImageLoad string
+
+const (
+	// Push images to a registry that the cluster pulls from.
+	ClusterImageLoadRegistry ClusterImageLoad = "registry"
+
+	// Load images into the cluster nodes with `kind load`.
+	ClusterImageLoadKind ClusterImageLoad = "kind"
+
+	// The cluster runs containers from the local Docker daemon,
+	// so images don't need to be pushed at all.
+	ClusterImageLoadDocker ClusterImageLoad = "docker"
+)
+
+var clusterImageLoads = []string{
+	string(ClusterImageLoadRegistry),
+	string(ClusterImageLoadKind),
+	string(ClusterImageLoadDocker),
 }
 
 type DockerClusterConnection struct {
@@ -110,6 +174,83 @@ type DockerClusterConnection struct {
 	Host string `json:"host,omitempty" protobuf:"bytes,1,opt,name=host"`
 }
 
+// Connection spec for a standalone BuildKit daemon.
+type BuildkitConnection struct {
+	// The address of the buildkitd gRPC API.
+	//
+	// Examples: unix:///run/buildkit/buildkitd.sock, tcp://127.0.0.1:1234
+	Address string `json:"address" protobuf:"bytes,1,opt,name=address"`
+
+	// Whether the daemon stores images where the cluster's container runtime
+	// can see them (e.g., buildkitd running on the cluster's containerd).
+	//
+	// If true, Tilt skips the registry push.
+	//
+	// +optional
+	SharesClusterRuntime bool `json:"sharesClusterRuntime,omitempty" protobuf:"varint,2,opt,name=sharesClusterRuntime"`
+}
+
+// The local cluster products that Tilt knows how to create.
+const (
+	ClusterProvisionProductKind = "kind"
+	ClusterProvisionProductK3d  = "k3d"
+)
+
+// Spec for a local cluster that Tilt creates.
+type ClusterProvision struct {
+	// The product to create the cluster with. Either "kind" or "k3d".
+	Product string `json:"product" protobuf:"bytes,1,opt,name=product"`
+
+	// The name of the cluster.
+	//
+	// The kubeconfig context is named after the product and the cluster name,
+	// e.g., kind-tilt or k3d-tilt.
+	//
+	// If not specified, defaults to "tilt".
+	//
+	// +optional
+	Name string `json:"name,omitempty" protobuf:"bytes,2,opt,name=name"`
+
+	// The number of nodes in the cluster, including the control plane.
+	//
+	// If not specified, defaults to 1.
+	//
+	// +optional
+	Nodes int32 `json:"nodes,omitempty" protobuf:"varint,3,opt,name=nodes"`
+
+	// The Kubernetes version to run, e.g., v1.27.3.
+	//
+	// If not specified, uses the default version of the product.
+	//
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty" protobuf:"bytes,4,opt,name=kubernetesVersion"`
+
+	// A local registry to create and connect to the cluster.
+	//
+	// Tilt advertises the registry in the cluster with the local registry
+	// hosting ConfigMap, and pushes images to it.
+	//
+	// +optional
+	Registry *ClusterProvisionRegistry `json:"registry,omitempty" protobuf:"bytes,5,opt,name=registry"`
+}
+
+// Spec for a local registry container that Tilt creates.
+type ClusterProvisionRegistry struct {
+	// The name of the registry container.
+	//
+	// If not specified, defaults to the cluster name with a "-registry" suffix.
+	//
+	// +optional
+	Name string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+
+	// The port on localhost where the registry listens.
+	//
+	// If not specified, defaults to 5005.
+	//
+	// +optional
+	Port int32 `json:"port,omitempty" protobuf:"varint,2,opt,name=port"`
+}
+
 var _ resource.Object = &Cluster{}
 var _ resourcestrategy.Validater = &Cluster{}
 
@@ -151,6 +292,46 @@ func (in *Cluster) Validate(ctx context.Context) field.ErrorList {
 		errors = append(errors,
 			in.Spec.DefaultRegistry.validateAsSubfield(ctx, field.NewPath(".spec.defaultRegistry"))...)
 	}
+	if in.Spec.Buildkit != nil && in.Spec.Buildkit.Address == "" {
+		errors = append(errors,
+			field.Required(field.NewPath(".spec.buildkit.address"), "address of the buildkitd gRPC API"))
+	}
+	if in.Spec.Provision != nil {
+		errors = append(errors, in.Spec.Provision.validateAsSubfield(field.NewPath(".spec.provision"))...)
+	}
+	if in.Spec.Connection != nil && in.Spec.Connection.Kubernetes != nil {
+		errors = append(errors, in.Spec.Connection.Kubernetes.validateAsSubfield(
+			field.NewPath(".spec.connection.kubernetes"))...)
+	}
+	return errors
+}
+
+func (in *KubernetesClusterConnection) validateAsSubfield(path *field.Path) field.ErrorList {
+	var errors field.ErrorList
+	switch in.ImageLoad {
+	case "", ClusterImageLoadRegistry, ClusterImageLoadKind, ClusterImageLoadDocker:
+	default:
+		errors = append(errors, field.NotSupported(path.Child("imageLoad"), in.ImageLoad, clusterImageLoads))
+	}
+	return errors
+}
+
+func (in *ClusterProvision) validateAsSubfield(path *field.Path) field.ErrorList {
+	var errors field.ErrorList
+	switch in.Product {
+	case ClusterProvisionProductKind, ClusterProvisionProductK3d:
+	case "":
+		errors = append(errors, field.Required(path.Child("product"), "kind or k3d"))
+	default:
+		errors = append(errors, field.NotSupported(path.Child("product"), in.Product,
+			[]string{ClusterProvisionProductKind, ClusterProvisionProductK3d}))
+	}
+	if in.Nodes < 0 {
+		errors = append(errors, field.Invalid(path.Child("nodes"), in.Nodes, "must be at least 1"))
+	}
+	if in.Registry != nil && (in.Registry.Port < 0 || in.Registry.Port > 65535) {
+		errors = append(errors, field.Invalid(path.Child("registry", "port"), in.Registry.Port, "must be a valid port"))
+	}
 	return errors
 }
 
@@ -203,6 +384,15 @@ type ClusterStatus struct {
 	//
 	// +optional
 	Version string `json:"version,omitempty" protobuf:"bytes,6,opt,name=version"`
+
+	// ReconnectedAt is the last time the connection came back after
+	// failing health checks (e.g., after the laptop woke from sleep).
+	//
+	// When this happens, ConnectedAt is bumped too, so that watches, port
+	// forwards, and log streams on the cluster are re-established.
+	//
+	// +optional
+	ReconnectedAt *metav1.MicroTime `json:"reconnectedAt,omitempty" protobuf:"bytes,7,opt,name=reconnectedAt"`
 }
 
 // Cluster implements ObjectWithStatusSubResource interface.
@@ -248,6 +438,14 @@ type KubernetesClusterConnectionStatus struct {
 	// Subprocesses that depend on this cluster can find this file
 	// by reading the KUBECONFIG env var.
 	ConfigPath string `json:"configPath,omitempty" protobuf:"bytes,5,opt,name=configPath"`
+
+	// Whether Tilt treats this cluster as a local development cluster.
+	DevCluster bool `json:"devCluster,omitempty" protobuf:"varint,6,opt,name=devCluster"`
+
+	// The image loading strategy declared for this cluster, if any.
+	//
+	// Empty if Tilt picks a strategy based on the product.
+	ImageLoad ClusterImageLoad `json:"imageLoad,omitempty" protobuf:"bytes,7,opt,name=imageLoad,casttype=ClusterImageLoad"`
 }
 
 // ClusterImageNeeds describes the ways that a cluster
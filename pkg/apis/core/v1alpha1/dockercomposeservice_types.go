@@ -70,6 +70,14 @@ type DockerComposeServiceSpec struct {
 	//
 	// +optional
 	DisableSource *DisableSource `json:"disableSource,omitempty" protobuf:"bytes,4,opt,name=disableSource"`
+
+	// The number of containers to run for the service.
+	//
+	// If omitted, uses the scale (or deploy.replicas) in the service
+	// config, which defaults to 1. The status reports on the first container.
+	//
+	// +optional
+	Replicas int32 `json:"replicas,omitempty" protobuf:"varint,5,opt,name=replicas"`
 }
 
 var _ resource.Object = &DockerComposeService{}
@@ -113,8 +121,12 @@ func (in *DockerComposeService) IsStorageVersion() bool {
 }
 
 func (in *DockerComposeService) Validate(ctx context.Context) field.ErrorList {
-	// TODO(user): Modify it, adding your API validation here.
-	return nil
+	var fieldErrors field.ErrorList
+	if in.Spec.Replicas < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(field.NewPath("spec.replicas"), in.Spec.Replicas,
+			"Replicas cannot be negative"))
+	}
+	return fieldErrors
 }
 
 var _ resource.ObjectList = &DockerComposeServiceList{}
@@ -211,6 +223,14 @@ type DockerComposeProject struct {
 
 	// Path to an env file to use. Passed to docker-compose as `--env-file FILE`.
 	EnvFile string `json:"envFile,omitempty" protobuf:"bytes,5,opt,name=envFile"`
+
+	// The profiles to enable, in addition to any in COMPOSE_PROFILES.
+	//
+	// Services with profiles are only part of the project when one
+	// of their profiles is enabled. Passed to docker-compose as `--profile NAME`.
+	//
+	// +optional
+	Profiles []string `json:"profiles,omitempty" protobuf:"bytes,6,rep,name=profiles"`
 }
 
 // State of a standalone container in Docker.
@@ -242,6 +262,15 @@ type DockerContainerState struct {
 	// When the container process finished.
 	// +optional
 	FinishedAt metav1.MicroTime `json:"finishedAt,omitempty" protobuf:"bytes,6,opt,name=finishedAt"`
+
+	// The health of the container, if it has a healthcheck.
+	// Can be one of "starting", "healthy", or "unhealthy".
+	// +optional
+	HealthStatus string `json:"healthStatus,omitempty" protobuf:"bytes,7,opt,name=healthStatus"`
+
+	// The output of the most recent healthcheck, if it failed.
+	// +optional
+	HealthLog string `json:"healthLog,omitempty" protobuf:"bytes,8,opt,name=healthLog"`
 }
 
 // How docker binds container ports to the host network
@@ -130,9 +130,23 @@ type DockerImageSpec struct {
 
 	// Images to use as cache sources.
 	//
+	// Each entry is either an image ref, or a BuildKit cache backend
+	// (e.g., type=registry,ref=gcr.io/my-project/cache or type=local,src=/tmp/cache).
+	//
 	// Equivalent to `--cache-from` in the Docker CLI.
 	CacheFrom []string `json:"cacheFrom,omitempty" protobuf:"bytes,9,rep,name=cacheFrom"`
 
+	// BuildKit cache backends to export the build cache to, so that
+	// other machines can import it with CacheFrom.
+	//
+	// Supported backends: type=registry,ref=..., type=local,dest=..., and type=inline.
+	// The Docker daemon only supports type=inline.
+	//
+	// Equivalent to `--cache-to` in `docker buildx build`.
+	//
+	// +optional
+	CacheTo []string `json:"cacheTo,omitempty" protobuf:"bytes,18,rep,name=cacheTo"`
+
 	// Platform specifies architecture information for target image.
 	//
 	// https://docs.docker.com/desktop/multi-arch/
@@ -140,6 +154,16 @@ type DockerImageSpec struct {
 	// Equivalent to `--platform` in the Docker CLI.
 	Platform string `json:"platform,omitempty" protobuf:"bytes,10,opt,name=platform"`
 
+	// Platforms to build a multi-platform image for, e.g., linux/amd64 and
+	// linux/arm64. Produces an OCI image index with one image per platform.
+	//
+	// If set, Platform is ignored. Requires a standalone BuildKit daemon.
+	//
+	// Equivalent to `--platform` with a comma-separated list in `docker buildx build`.
+	//
+	// +optional
+	Platforms []string `json:"platforms,omitempty" protobuf:"bytes,17,rep,name=platforms"`
+
 	// By default, Tilt creates a new temporary image reference for each build.
 	// The user can also specify their own reference, to integrate with other tooling
 	// (like build IDs for Jenkins build pipelines)
@@ -249,6 +273,12 @@ type DockerImageStatus struct {
 	// Status information about each individual build stage
 	// of the most recent image build.
 	StageStatuses []DockerImageStageStatus `json:"stageStatuses,omitempty" protobuf:"bytes,5,rep,name=stageStatuses"`
+
+	// How many build steps in each Dockerfile stage of the most recent
+	// image build were served from the build cache.
+	//
+	// +optional
+	CacheHits []DockerImageCacheHits `json:"cacheHits,omitempty" protobuf:"bytes,6,rep,name=cacheHits"`
 }
 
 // DockerImage implements ObjectWithStatusSubResource interface.
@@ -300,6 +330,19 @@ type DockerImageStateCompleted struct {
 	FinishedAt metav1.MicroTime `json:"finishedAt,omitempty" protobuf:"bytes,4,opt,name=finishedAt"`
 }
 
+// DockerImageCacheHits counts the build cache hits in one Dockerfile stage.
+type DockerImageCacheHits struct {
+	// The name of the Dockerfile stage, or "default" for
+	// a Dockerfile without named stages.
+	Stage string `json:"stage" protobuf:"bytes,1,opt,name=stage"`
+
+	// The number of build steps that were cached.
+	Cached int32 `json:"cached" protobuf:"varint,2,opt,name=cached"`
+
+	// The number of build steps in the stage.
+	Total int32 `json:"total" protobuf:"varint,3,opt,name=total"`
+}
+
 // DockerImageStageStatus gives detailed report of each stage
 // of the most recent image build.
 //
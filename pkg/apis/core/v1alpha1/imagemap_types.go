@@ -214,6 +214,21 @@ type ImageMapStatus struct {
 	// may not be included in the image.
 	BuildStartTime *metav1.MicroTime `json:"buildStartTime,omitempty" protobuf:"bytes,2,opt,name=buildStartTime"`
 
+	// The digest of the image manifest (or multi-platform image index) in the
+	// registry, if the build pushed one.
+	//
+	// When set, Tilt deploys the image by digest, so that each node pulls
+	// exactly the image that was built.
+	//
+	// +optional
+	Digest string `json:"digest,omitempty" protobuf:"bytes,5,opt,name=digest"`
+
+	// For multi-platform images, the digest of the image for each platform
+	// in the image index.
+	//
+	// +optional
+	PlatformDigests []ImageMapPlatformDigest `json:"platformDigests,omitempty" protobuf:"bytes,6,rep,name=platformDigests"`
+
 	// TODO(nick): I'm not totally sure how we should model registries in this system.
 	//
 	// We need to be able to support an image existing at multiple URLs in
@@ -228,6 +243,15 @@ type ImageMapStatus struct {
 	// It might make sense for a Registry to be its own API object.
 }
 
+// The image built for one platform of a multi-platform image.
+type ImageMapPlatformDigest struct {
+	// The platform of the image, e.g., linux/arm64.
+	Platform string `json:"platform" protobuf:"bytes,1,opt,name=platform"`
+
+	// The digest of the platform-specific image manifest.
+	Digest string `json:"digest" protobuf:"bytes,2,opt,name=digest"`
+}
+
 // ImageMap implements ObjectWithStatusSubResource interface.
 var _ resource.ObjectWithStatusSubResource = &ImageMap{}
 
@@ -78,7 +78,7 @@ type KubernetesApplyList struct {
 type KubernetesApplySpec struct {
 	// YAML to apply to the cluster.
 	//
-	// Exactly one of YAML OR ApplyCmd MUST be provided.
+	// Exactly one of YAML, ApplyCmd, OR HelmRelease MUST be provided.
 	//
 	// +optional
 	YAML string `json:"yaml,omitempty" protobuf:"bytes,1,opt,name=yaml"`
@@ -159,7 +159,7 @@ type KubernetesApplySpec struct {
 	//
 	// The ApplyCmd MUST return valid Kubernetes YAML for the entities it applied to the cluster.
 	//
-	// Exactly one of YAML OR ApplyCmd MUST be provided.
+	// Exactly one of YAML, ApplyCmd, OR HelmRelease MUST be provided.
 	//
 	// +optional
 	ApplyCmd *KubernetesApplyCmd `json:"applyCmd,omitempty" protobuf:"bytes,10,opt,name=applyCmd"`
@@ -181,6 +181,17 @@ type KubernetesApplySpec struct {
 	//
 	// +optional
 	Cluster string `json:"cluster" protobuf:"bytes,13,opt,name=cluster"`
+
+	// HelmRelease is a Helm release to install or upgrade on the cluster.
+	//
+	// Unlike rendering the chart to YAML, the release runs chart hooks and
+	// keeps its revision history, as it would with `helm upgrade --install`.
+	// The release is uninstalled when the KubernetesApply is deleted.
+	//
+	// Exactly one of YAML, ApplyCmd, OR HelmRelease MUST be provided.
+	//
+	// +optional
+	HelmRelease *KubernetesApplyHelmRelease `json:"helmRelease,omitempty" protobuf:"bytes,14,opt,name=helmRelease"`
 }
 
 var _ resource.Object = &KubernetesApply{}
@@ -251,14 +262,28 @@ func (in *KubernetesApply) Validate(ctx context.Context) field.ErrorList {
 			fieldErrors = append(fieldErrors, field.Invalid(
 				field.NewPath("spec.applyCmd"),
 				in.Spec.ApplyCmd,
-				"must specify exactly ONE of .spec.yaml or .spec.applyCmd"))
+				"must specify exactly ONE of .spec.yaml, .spec.applyCmd, or .spec.helmRelease"))
+		}
+		if in.Spec.HelmRelease != nil {
+			fieldErrors = append(fieldErrors, field.Invalid(
+				field.NewPath("spec.helmRelease"),
+				in.Spec.HelmRelease,
+				"must specify exactly ONE of .spec.yaml, .spec.applyCmd, or .spec.helmRelease"))
 		}
 	} else if in.Spec.ApplyCmd != nil {
+		if in.Spec.HelmRelease != nil {
+			fieldErrors = append(fieldErrors, field.Invalid(
+				field.NewPath("spec.helmRelease"),
+				in.Spec.HelmRelease,
+				"must specify exactly ONE of .spec.yaml, .spec.applyCmd, or .spec.helmRelease"))
+		}
 		fieldErrors = append(fieldErrors, in.Spec.ApplyCmd.validateAsSubfield(ctx, field.NewPath("spec.applyCmd"))...)
+	} else if in.Spec.HelmRelease != nil {
+		fieldErrors = append(fieldErrors, in.Spec.HelmRelease.validateAsSubfield(ctx, field.NewPath("spec.helmRelease"))...)
 	} else {
 		fieldErrors = append(fieldErrors, field.Required(
 			field.NewPath("spec.yaml"),
-			"must specify exactly ONE of .spec.yaml or .spec.applyCmd"))
+			"must specify exactly ONE of .spec.yaml, .spec.applyCmd, or .spec.helmRelease"))
 	}
 
 	return fieldErrors
@@ -320,6 +345,13 @@ type KubernetesApplyStatus struct {
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,7,rep,name=conditions"`
 
+	// The state of the Helm release, for KubernetesApply objects with a HelmRelease.
+	//
+	// Populated after every install or upgrade, including failed ones.
+	//
+	// +optional
+	HelmRelease *KubernetesApplyHelmReleaseStatus `json:"helmRelease,omitempty" protobuf:"bytes,8,opt,name=helmRelease"`
+
 	// TODO(nick): We should also add some sort of status field to this
 	// status (like waiting, active, done).
 }
@@ -438,3 +470,113 @@ func (c *KubernetesApplyCmd) validateAsSubfield(_ context.Context, rootField *fi
 	}
 	return fieldErrors
 }
+
+// KubernetesApplyHelmRelease describes a Helm release that Tilt installs and upgrades
+// with the Helm SDK.
+type KubernetesApplyHelmRelease struct {
+	// The name of the release.
+	ReleaseName string `json:"releaseName" protobuf:"bytes,1,opt,name=releaseName"`
+
+	// The namespace of the release.
+	//
+	// If not specified, uses the namespace of the current kubeconfig context.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty" protobuf:"bytes,2,opt,name=namespace"`
+
+	// Path to a chart directory or a packaged chart.
+	//
+	// +tilt:local-path=true
+	Chart string `json:"chart" protobuf:"bytes,3,opt,name=chart"`
+
+	// Paths to values files, applied in order after the chart's values.yaml.
+	//
+	// +optional
+	ValueFiles []string `json:"valueFiles,omitempty" protobuf:"bytes,4,rep,name=valueFiles"`
+
+	// Values in the same syntax as `helm --set`, e.g., "replicas=2".
+	//
+	// Applied after the values files.
+	//
+	// +optional
+	Set []string `json:"set,omitempty" protobuf:"bytes,5,rep,name=set"`
+
+	// The chart values to inject images into.
+	//
+	// +optional
+	ImageKeys []KubernetesApplyHelmImageKeys `json:"imageKeys,omitempty" protobuf:"bytes,6,rep,name=imageKeys"`
+
+	// Whether to create the namespace of the release if it doesn't exist.
+	//
+	// +optional
+	CreateNamespace bool `json:"createNamespace,omitempty" protobuf:"varint,7,opt,name=createNamespace"`
+
+	// Whether to roll back a failed upgrade to the last deployed revision
+	// (or uninstall a failed install), like `helm upgrade --atomic`.
+	//
+	// Also waits for the release's objects to be ready.
+	//
+	// +optional
+	Atomic bool `json:"atomic,omitempty" protobuf:"varint,8,opt,name=atomic"`
+}
+
+// validateAsSubfield performs validation prepending the rootField (if non-nil) to paths in returned errors.
+func (r *KubernetesApplyHelmRelease) validateAsSubfield(_ context.Context, rootField *field.Path) field.ErrorList {
+	var fieldErrors field.ErrorList
+	if r.ReleaseName == "" {
+		fieldErrors = append(fieldErrors, field.Required(rootField.Child("releaseName"), "releaseName cannot be empty"))
+	}
+	if r.Chart == "" {
+		fieldErrors = append(fieldErrors, field.Required(rootField.Child("chart"), "chart cannot be empty"))
+	}
+	for i, keys := range r.ImageKeys {
+		path := rootField.Child("imageKeys").Index(i)
+		if keys.ImageMap == "" {
+			fieldErrors = append(fieldErrors, field.Required(path.Child("imageMap"), "imageMap cannot be empty"))
+		}
+		if keys.Image == "" && (keys.Repository == "" || keys.Tag == "") {
+			fieldErrors = append(fieldErrors, field.Required(path,
+				"must specify either image, or both repository and tag"))
+		} else if keys.Image != "" && (keys.Repository != "" || keys.Tag != "") {
+			fieldErrors = append(fieldErrors, field.Invalid(path, keys,
+				"must specify either image, or both repository and tag"))
+		}
+	}
+	return fieldErrors
+}
+
+// KubernetesApplyHelmImageKeys describes where to inject an image into the
+// chart values.
+//
+// Specify either Image, or both Repository and Tag.
+type KubernetesApplyHelmImageKeys struct {
+	// The name of the image map with the image to inject.
+	ImageMap string `json:"imageMap" protobuf:"bytes,1,opt,name=imageMap"`
+
+	// A value that holds the whole image reference, e.g., "image".
+	//
+	// +optional
+	Image string `json:"image,omitempty" protobuf:"bytes,2,opt,name=image"`
+
+	// A value that holds the image repository, e.g., "image.repository".
+	//
+	// +optional
+	Repository string `json:"repository,omitempty" protobuf:"bytes,3,opt,name=repository"`
+
+	// A value that holds the image tag, e.g., "image.tag".
+	//
+	// +optional
+	Tag string `json:"tag,omitempty" protobuf:"bytes,4,opt,name=tag"`
+}
+
+// KubernetesApplyHelmReleaseStatus describes the latest revision of a Helm release.
+type KubernetesApplyHelmReleaseStatus struct {
+	// The namespace of the release.
+	Namespace string `json:"namespace" protobuf:"bytes,1,opt,name=namespace"`
+
+	// The revision number. Starts at 1, and goes up on every install or upgrade.
+	Revision int32 `json:"revision" protobuf:"varint,2,opt,name=revision"`
+
+	// The status of the revision, e.g., "deployed" or "failed".
+	Status string `json:"status" protobuf:"bytes,3,opt,name=status"`
+}
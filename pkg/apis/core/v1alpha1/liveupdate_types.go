@@ -225,6 +225,15 @@ type LiveUpdateStatus struct {
 	//
 	// +optional
 	Failed *LiveUpdateStateFailed `json:"failed,omitempty" protobuf:"bytes,2,opt,name=failed"`
+
+	// A record of how the live-updater handled each file in the most recent
+	// batch of file changes, and which rule in the spec each file matched.
+	//
+	// Useful for understanding why a file change fell back to a full rebuild
+	// instead of a live update.
+	//
+	// +optional
+	Decisions []LiveUpdateFileDecision `json:"decisions,omitempty" protobuf:"bytes,3,rep,name=decisions"`
 }
 
 // LiveUpdate implements ObjectWithStatusSubResource interface.
@@ -439,3 +448,46 @@ type LiveUpdateContainerStateWaiting struct {
 	// +optional
 	Message string `json:"message,omitempty" protobuf:"bytes,2,opt,name=message"`
 }
+
+// LiveUpdateFileDecision explains what the live-updater decided to do with a
+// single changed file, and which rules in the spec led to that decision.
+type LiveUpdateFileDecision struct {
+	// The absolute local path of the changed file.
+	LocalPath string `json:"localPath" protobuf:"bytes,1,opt,name=localPath"`
+
+	// What the live-updater does with this file.
+	Action LiveUpdateFileAction `json:"action" protobuf:"bytes,2,opt,name=action,casttype=LiveUpdateFileAction"`
+
+	// The local path of the sync rule that matched this file, if any.
+	// +optional
+	SyncLocalPath string `json:"syncLocalPath,omitempty" protobuf:"bytes,3,opt,name=syncLocalPath"`
+
+	// The path inside the container that this file is copied to, if it
+	// matched a sync rule.
+	// +optional
+	ContainerPath string `json:"containerPath,omitempty" protobuf:"bytes,4,opt,name=containerPath"`
+
+	// The stop path that matched this file, if any.
+	// +optional
+	StopPath string `json:"stopPath,omitempty" protobuf:"bytes,5,opt,name=stopPath"`
+
+	// The trigger paths of any execs that this file triggers.
+	// +optional
+	TriggerPaths []string `json:"triggerPaths,omitempty" protobuf:"bytes,6,rep,name=triggerPaths"`
+}
+
+// LiveUpdateFileAction describes what the live-updater does with a changed file.
+type LiveUpdateFileAction string
+
+var (
+	// The file is copied into the container.
+	LiveUpdateFileActionSync LiveUpdateFileAction = "Sync"
+
+	// The file matched a stop path, so the live-updater stops and
+	// the image is rebuilt.
+	LiveUpdateFileActionStop LiveUpdateFileAction = "Stop"
+
+	// The file didn't match any sync, so the live-updater stops and
+	// the image is rebuilt.
+	LiveUpdateFileActionNoMatch LiveUpdateFileAction = "NoMatch"
+)
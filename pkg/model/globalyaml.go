@@ -1,4 +1,28 @@
 package model
 
+import (
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
 // TODO(maia): throw an error if you try to name a manifest this in your Tiltfile?
 const UnresourcedYAMLManifestName = ManifestName("uncategorized")
+
+// Objects for clusters other than the default get their own manifest.
+func UnresourcedYAMLManifestNameForCluster(cluster string) ManifestName {
+	if cluster == "" || cluster == v1alpha1.ClusterNameDefault {
+		return UnresourcedYAMLManifestName
+	}
+	return ManifestName(string(UnresourcedYAMLManifestName) + "-" + cluster)
+}
+
+// Whether this is the manifest that holds the objects that don't belong
+// to any resource in its cluster.
+//
+// Only the exact name for the manifest's own cluster counts, so that
+// user resources that happen to start with "uncategorized-" don't.
+func IsUnresourcedYAMLManifest(m Manifest) bool {
+	if !m.IsK8s() {
+		return false
+	}
+	return m.Name == UnresourcedYAMLManifestNameForCluster(m.ClusterName())
+}
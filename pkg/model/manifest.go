@@ -263,6 +263,9 @@ func (m *Manifest) ClusterName() string {
 		return v1alpha1.ClusterNameDocker
 	}
 	if m.IsK8s() {
+		if cluster := m.K8sTarget().KubernetesApplySpec.Cluster; cluster != "" {
+			return cluster
+		}
 		return v1alpha1.ClusterNameDefault
 	}
 	return ""
@@ -0,0 +1,36 @@
+package model
+
+// A Tiltfile arg defined with one of the config.define_* builtins.
+//
+// Used to print help for Tiltfile args. The HUD server also serves them, so
+// that the web UI's args editor can render typed inputs for them.
+type TiltfileArg struct {
+	Name string `json:"name"`
+
+	// One of "string", "list[string]", "bool", "int", "float", "choice", or "object".
+	Type string `json:"type"`
+
+	Usage string `json:"usage,omitempty"`
+
+	// If true, this arg is set from positional args rather than a --flag.
+	Positional bool `json:"positional,omitempty"`
+
+	// The value from tilt_config.json, if any. Used when the arg isn't passed
+	// on the command line.
+	Default interface{} `json:"default,omitempty"`
+
+	// For "choice" args, the allowed values.
+	Choices []string `json:"choices,omitempty"`
+
+	// For "object" args, the JSON schema that values must match, if any.
+	Schema interface{} `json:"schema,omitempty"`
+}
+
+// The response body of the HUD server's /api/tiltfile_args_schema endpoint.
+type TiltfileArgsSchema struct {
+	Args []TiltfileArg `json:"args"`
+
+	// The values of the args that the Tiltfile is running with, by arg name,
+	// as they were passed on the command line. Empty if we can't tell.
+	Values map[string][]string `json:"values,omitempty"`
+}
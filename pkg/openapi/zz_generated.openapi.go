@@ -31,10 +31,13 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.BuildkitConnection":                schema_pkg_apis_core_v1alpha1_BuildkitConnection(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Cluster":                           schema_pkg_apis_core_v1alpha1_Cluster(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnection":                 schema_pkg_apis_core_v1alpha1_ClusterConnection(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnectionStatus":           schema_pkg_apis_core_v1alpha1_ClusterConnectionStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterList":                       schema_pkg_apis_core_v1alpha1_ClusterList(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterProvision":                  schema_pkg_apis_core_v1alpha1_ClusterProvision(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterProvisionRegistry":          schema_pkg_apis_core_v1alpha1_ClusterProvisionRegistry(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterSpec":                       schema_pkg_apis_core_v1alpha1_ClusterSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterStatus":                     schema_pkg_apis_core_v1alpha1_ClusterStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Cmd":                               schema_pkg_apis_core_v1alpha1_Cmd(ref),
@@ -76,6 +79,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeServiceStatus":        schema_pkg_apis_core_v1alpha1_DockerComposeServiceStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerContainerState":              schema_pkg_apis_core_v1alpha1_DockerContainerState(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImage":                       schema_pkg_apis_core_v1alpha1_DockerImage(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageCacheHits":              schema_pkg_apis_core_v1alpha1_DockerImageCacheHits(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageList":                   schema_pkg_apis_core_v1alpha1_DockerImageList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageSpec":                   schema_pkg_apis_core_v1alpha1_DockerImageSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStageStatus":            schema_pkg_apis_core_v1alpha1_DockerImageStageStatus(ref),
@@ -108,10 +112,14 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapList":                      schema_pkg_apis_core_v1alpha1_ImageMapList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapOverrideArgs":              schema_pkg_apis_core_v1alpha1_ImageMapOverrideArgs(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapOverrideCommand":           schema_pkg_apis_core_v1alpha1_ImageMapOverrideCommand(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapPlatformDigest":            schema_pkg_apis_core_v1alpha1_ImageMapPlatformDigest(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapSpec":                      schema_pkg_apis_core_v1alpha1_ImageMapSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapStatus":                    schema_pkg_apis_core_v1alpha1_ImageMapStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApply":                   schema_pkg_apis_core_v1alpha1_KubernetesApply(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyCmd":                schema_pkg_apis_core_v1alpha1_KubernetesApplyCmd(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyHelmImageKeys":      schema_pkg_apis_core_v1alpha1_KubernetesApplyHelmImageKeys(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyHelmRelease":        schema_pkg_apis_core_v1alpha1_KubernetesApplyHelmRelease(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyHelmReleaseStatus":  schema_pkg_apis_core_v1alpha1_KubernetesApplyHelmReleaseStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyList":               schema_pkg_apis_core_v1alpha1_KubernetesApplyList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplySpec":               schema_pkg_apis_core_v1alpha1_KubernetesApplySpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyStatus":             schema_pkg_apis_core_v1alpha1_KubernetesApplyStatus(ref),
@@ -132,6 +140,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateContainerStatus":         schema_pkg_apis_core_v1alpha1_LiveUpdateContainerStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateDockerComposeSelector":   schema_pkg_apis_core_v1alpha1_LiveUpdateDockerComposeSelector(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateExec":                    schema_pkg_apis_core_v1alpha1_LiveUpdateExec(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateFileDecision":            schema_pkg_apis_core_v1alpha1_LiveUpdateFileDecision(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateKubernetesSelector":      schema_pkg_apis_core_v1alpha1_LiveUpdateKubernetesSelector(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateList":                    schema_pkg_apis_core_v1alpha1_LiveUpdateList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateSelector":                schema_pkg_apis_core_v1alpha1_LiveUpdateSelector(ref),
@@ -274,6 +283,35 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_BuildkitConnection(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Connection spec for a standalone BuildKit daemon.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"address": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The address of the buildkitd gRPC API.\n\nExamples: unix:///run/buildkit/buildkitd.sock, tcp://127.0.0.1:1234",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sharesClusterRuntime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the daemon stores images where the cluster's container runtime can see them (e.g., buildkitd running on the cluster's containerd).\n\nIf true, Tilt skips the registry push.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"address"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_Cluster(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -418,6 +456,84 @@ func schema_pkg_apis_core_v1alpha1_ClusterList(ref common.ReferenceCallback) com
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_ClusterProvision(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Spec for a local cluster that Tilt creates.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"product": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The product to create the cluster with. Either \"kind\" or \"k3d\".",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the cluster.\n\nThe kubeconfig context is named after the product and the cluster name, e.g., kind-tilt or k3d-tilt.\n\nIf not specified, defaults to \"tilt\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of nodes in the cluster, including the control plane.\n\nIf not specified, defaults to 1.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"kubernetesVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The Kubernetes version to run, e.g., v1.27.3.\n\nIf not specified, uses the default version of the product.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"registry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A local registry to create and connect to the cluster.\n\nTilt advertises the registry in the cluster with the local registry hosting ConfigMap, and pushes images to it.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterProvisionRegistry"),
+						},
+					},
+				},
+				Required: []string{"product"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterProvisionRegistry"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_ClusterProvisionRegistry(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Spec for a local registry container that Tilt creates.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the registry container.\n\nIf not specified, defaults to the cluster name with a \"-registry\" suffix.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"port": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The port on localhost where the registry listens.\n\nIf not specified, defaults to 5005.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_ClusterSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -437,11 +553,23 @@ func schema_pkg_apis_core_v1alpha1_ClusterSpec(ref common.ReferenceCallback) com
 							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RegistryHosting"),
 						},
 					},
+					"buildkit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Buildkit configures a standalone BuildKit daemon to build images for this Cluster, instead of building through the Docker API.\n\nIf not specified, falls back to the TILT_BUILDKIT_HOST env variable. If neither is set, images are built with Docker.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.BuildkitConnection"),
+						},
+					},
+					"provision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Provision describes a local cluster for Tilt to create if it doesn't exist yet.\n\nTilt connects to the provisioned cluster's kubeconfig context, and deletes the cluster on `tilt down --delete-cluster`.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterProvision"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnection", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RegistryHosting"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.BuildkitConnection", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnection", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterProvision", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RegistryHosting"},
 	}
 }
 
@@ -491,6 +619,12 @@ func schema_pkg_apis_core_v1alpha1_ClusterStatus(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"reconnectedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReconnectedAt is the last time the connection came back after failing health checks (e.g., after the laptop woke from sleep).\n\nWhen this happens, ConnectedAt is bumped too, so that watches, port forwards, and log streams on the cluster are re-established.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
 				},
 			},
 		},
@@ -1919,6 +2053,21 @@ func schema_pkg_apis_core_v1alpha1_DockerComposeProject(ref common.ReferenceCall
 							Format:      "",
 						},
 					},
+					"profiles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The profiles to enable, in addition to any in COMPOSE_PROFILES.\n\nServices with profiles are only part of the project when one of their profiles is enabled. Passed to docker-compose as `--profile NAME`.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -2064,6 +2213,13 @@ func schema_pkg_apis_core_v1alpha1_DockerComposeServiceSpec(ref common.Reference
 							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource"),
 						},
 					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of containers to run for the service.\n\nIf omitted, uses the scale (or deploy.replicas) in the service config, which defaults to 1. The status reports on the first container.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 				},
 				Required: []string{"service", "project"},
 			},
@@ -2198,6 +2354,20 @@ func schema_pkg_apis_core_v1alpha1_DockerContainerState(ref common.ReferenceCall
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
 						},
 					},
+					"healthStatus": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The health of the container, if it has a healthcheck. Can be one of \"starting\", \"healthy\", or \"unhealthy\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"healthLog": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The output of the most recent healthcheck, if it failed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -2253,6 +2423,44 @@ func schema_pkg_apis_core_v1alpha1_DockerImage(ref common.ReferenceCallback) com
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_DockerImageCacheHits(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DockerImageCacheHits counts the build cache hits in one Dockerfile stage.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"stage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the Dockerfile stage, or \"default\" for a Dockerfile without named stages.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cached": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of build steps that were cached.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"total": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of build steps in the stage.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"stage", "cached", "total"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_DockerImageList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2413,7 +2621,22 @@ func schema_pkg_apis_core_v1alpha1_DockerImageSpec(ref common.ReferenceCallback)
 					},
 					"cacheFrom": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Images to use as cache sources.\n\nEquivalent to `--cache-from` in the Docker CLI.",
+							Description: "Images to use as cache sources.\n\nEach entry is either an image ref, or a BuildKit cache backend (e.g., type=registry,ref=gcr.io/my-project/cache or type=local,src=/tmp/cache).\n\nEquivalent to `--cache-from` in the Docker CLI.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"cacheTo": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BuildKit cache backends to export the build cache to, so that other machines can import it with CacheFrom.\n\nSupported backends: type=registry,ref=..., type=local,dest=..., and type=inline. The Docker daemon only supports type=inline.\n\nEquivalent to `--cache-to` in `docker buildx build`.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -2433,6 +2656,21 @@ func schema_pkg_apis_core_v1alpha1_DockerImageSpec(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"platforms": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Platforms to build a multi-platform image for, e.g., linux/amd64 and linux/arm64. Produces an OCI image index with one image per platform.\n\nIf set, Platform is ignored. Requires a standalone BuildKit daemon.\n\nEquivalent to `--platform` with a comma-separated list in `docker buildx build`.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 					"extraTags": {
 						SchemaProps: spec.SchemaProps{
 							Description: "By default, Tilt creates a new temporary image reference for each build. The user can also specify their own reference, to integrate with other tooling (like build IDs for Jenkins build pipelines)\n\nEquivalent to the docker build --tag flag.",
@@ -2674,11 +2912,25 @@ func schema_pkg_apis_core_v1alpha1_DockerImageStatus(ref common.ReferenceCallbac
 							},
 						},
 					},
+					"cacheHits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How many build steps in each Dockerfile stage of the most recent image build were served from the build cache.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageCacheHits"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStageStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateBuilding", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateCompleted", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateWaiting"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageCacheHits", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStageStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateBuilding", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateCompleted", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateWaiting"},
 	}
 }
 
@@ -3744,6 +3996,36 @@ func schema_pkg_apis_core_v1alpha1_ImageMapOverrideCommand(ref common.ReferenceC
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_ImageMapPlatformDigest(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "The image built for one platform of a multi-platform image.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"platform": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The platform of the image, e.g., linux/arm64.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"digest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The digest of the platform-specific image manifest.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"platform", "digest"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_ImageMapSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3828,11 +4110,32 @@ func schema_pkg_apis_core_v1alpha1_ImageMapStatus(ref common.ReferenceCallback)
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
 						},
 					},
+					"digest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The digest of the image manifest (or multi-platform image index) in the registry, if the build pushed one.\n\nWhen set, Tilt deploys the image by digest, so that each node pulls exactly the image that was built.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"platformDigests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "For multi-platform images, the digest of the image for each platform in the image index.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapPlatformDigest"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapPlatformDigest", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
 	}
 }
 
@@ -3934,6 +4237,184 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplyCmd(ref common.ReferenceCallba
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_KubernetesApplyHelmImageKeys(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "KubernetesApplyHelmImageKeys describes where to inject an image into the chart values.\n\nSpecify either Image, or both Repository and Tag.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"imageMap": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the image map with the image to inject.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A value that holds the whole image reference, e.g., \"image\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"repository": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A value that holds the image repository, e.g., \"image.repository\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tag": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A value that holds the image tag, e.g., \"image.tag\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"imageMap"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_KubernetesApplyHelmRelease(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "KubernetesApplyHelmRelease describes a Helm release that Tilt installs and upgrades with the Helm SDK.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"releaseName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the release.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The namespace of the release.\n\nIf not specified, uses the namespace of the current kubeconfig context.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"chart": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path to a chart directory or a packaged chart.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"valueFiles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Paths to values files, applied in order after the chart's values.yaml.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"set": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Values in the same syntax as `helm --set`, e.g., \"replicas=2\".\n\nApplied after the values files.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"imageKeys": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The chart values to inject images into.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyHelmImageKeys"),
+									},
+								},
+							},
+						},
+					},
+					"createNamespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether to create the namespace of the release if it doesn't exist.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"atomic": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether to roll back a failed upgrade to the last deployed revision (or uninstall a failed install), like `helm upgrade --atomic`.\n\nAlso waits for the release's objects to be ready.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"releaseName", "chart"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyHelmImageKeys"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_KubernetesApplyHelmReleaseStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "KubernetesApplyHelmReleaseStatus describes the latest revision of a Helm release.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The namespace of the release.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The revision number. Starts at 1, and goes up on every install or upgrade.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The status of the revision, e.g., \"deployed\" or \"failed\".",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"namespace", "revision", "status"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_KubernetesApplyList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3992,7 +4473,7 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplySpec(ref common.ReferenceCallb
 				Properties: map[string]spec.Schema{
 					"yaml": {
 						SchemaProps: spec.SchemaProps{
-							Description: "YAML to apply to the cluster.\n\nExactly one of YAML OR ApplyCmd MUST be provided.",
+							Description: "YAML to apply to the cluster.\n\nExactly one of YAML, ApplyCmd, OR HelmRelease MUST be provided.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -4065,7 +4546,7 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplySpec(ref common.ReferenceCallb
 					},
 					"applyCmd": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ApplyCmd is a custom command to execute to deploy entities to the Kubernetes cluster.\n\nThe command must be idempotent, e.g. it must not fail if some or all entities already exist.\n\nThe ApplyCmd MUST return valid Kubernetes YAML for the entities it applied to the cluster.\n\nExactly one of YAML OR ApplyCmd MUST be provided.",
+							Description: "ApplyCmd is a custom command to execute to deploy entities to the Kubernetes cluster.\n\nThe command must be idempotent, e.g. it must not fail if some or all entities already exist.\n\nThe ApplyCmd MUST return valid Kubernetes YAML for the entities it applied to the cluster.\n\nExactly one of YAML, ApplyCmd, OR HelmRelease MUST be provided.",
 							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyCmd"),
 						},
 					},
@@ -4089,11 +4570,17 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplySpec(ref common.ReferenceCallb
 							Format:      "",
 						},
 					},
+					"helmRelease": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HelmRelease is a Helm release to install or upgrade on the cluster.\n\nUnlike rendering the chart to YAML, the release runs chart hooks and keeps its revision history, as it would with `helm upgrade --install`. The release is uninstalled when the KubernetesApply is deleted.\n\nExactly one of YAML, ApplyCmd, OR HelmRelease MUST be provided.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyHelmRelease"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyCmd", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesImageLocator", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.PodLogStreamTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.PortForwardTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RestartOnSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyCmd", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyHelmRelease", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesImageLocator", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.PodLogStreamTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.PortForwardTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RestartOnSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -4159,11 +4646,17 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplyStatus(ref common.ReferenceCal
 							},
 						},
 					},
+					"helmRelease": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The state of the Helm release, for KubernetesApply objects with a HelmRelease.\n\nPopulated after every install or upgrade, including failed ones.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyHelmReleaseStatus"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyHelmReleaseStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
 	}
 }
 
@@ -4187,6 +4680,27 @@ func schema_pkg_apis_core_v1alpha1_KubernetesClusterConnection(ref common.Refere
 							Format:      "",
 						},
 					},
+					"product": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The product name for this cluster (e.g., \"kind\", \"vcluster\").\n\nIf not specified, Tilt detects the product from the kubeconfig. Useful for clusters that Tilt can't recognize on its own.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"devCluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether this is a local development cluster.\n\nTilt refuses to deploy to a cluster it doesn't recognize as a dev cluster unless the context is listed in allow_k8s_contexts().\n\nIf not specified, Tilt infers it from the product.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"imageLoad": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How built images get into the cluster.\n\nIf not specified, Tilt picks a strategy based on the product.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -4238,6 +4752,20 @@ func schema_pkg_apis_core_v1alpha1_KubernetesClusterConnectionStatus(ref common.
 							Format:      "",
 						},
 					},
+					"devCluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether Tilt treats this cluster as a local development cluster.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"imageLoad": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The image loading strategy declared for this cluster, if any.\n\nEmpty if Tilt picks a strategy based on the product.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"context", "namespace", "cluster"},
 			},
@@ -4833,6 +5361,72 @@ func schema_pkg_apis_core_v1alpha1_LiveUpdateExec(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_LiveUpdateFileDecision(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LiveUpdateFileDecision explains what the live-updater decided to do with a single changed file, and which rules in the spec led to that decision.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"localPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The absolute local path of the changed file.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"action": {
+						SchemaProps: spec.SchemaProps{
+							Description: "What the live-updater does with this file.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"syncLocalPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The local path of the sync rule that matched this file, if any.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"containerPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The path inside the container that this file is copied to, if it matched a sync rule.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"stopPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The stop path that matched this file, if any.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"triggerPaths": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The trigger paths of any execs that this file triggers.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"localPath", "action"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_LiveUpdateKubernetesSelector(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -5144,11 +5738,25 @@ func schema_pkg_apis_core_v1alpha1_LiveUpdateStatus(ref common.ReferenceCallback
 							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateStateFailed"),
 						},
 					},
+					"decisions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A record of how the live-updater handled each file in the most recent batch of file changes, and which rule in the spec each file matched.\n\nUseful for understanding why a file change fell back to a full rebuild instead of a live update.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateFileDecision"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateContainerStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateStateFailed"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateContainerStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateFileDecision", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateStateFailed"},
 	}
 }
 
@@ -78,6 +78,24 @@ describe("ClusterStatusDialog", () => {
     expect(k8sDescriptions).toStrictEqual(expectedDescriptions)
   })
 
+  it("displays when the cluster last reconnected", () => {
+    const reconnectedAt = "2022-01-01T12:00:00.000000Z"
+    const cluster = clusterConnection()
+    cluster.status = { ...cluster.status, reconnectedAt }
+
+    render(
+      <ClusterStatusDialog
+        {...DEFAULT_TEST_PROPS}
+        clusterConnection={cluster}
+      />
+    )
+
+    expect(screen.getByText("Last reconnected")).toBeTruthy()
+    expect(
+      screen.getByText(new Date(reconnectedAt).toLocaleString())
+    ).toBeTruthy()
+  })
+
   it("displays `healthy` status with healthy icon if there is no error", () => {
     render(
       <ClusterStatusDialog
@@ -108,6 +108,14 @@ function ClusterProperty({
   )
 }
 
+function formatReconnectedAt(reconnectedAt?: string) {
+  if (!reconnectedAt) {
+    return
+  }
+
+  return new Date(reconnectedAt).toLocaleString()
+}
+
 function K8sClusterProperties({
   clusterStatus,
 }: {
@@ -133,6 +141,10 @@ function K8sClusterProperties({
         displayName="Local registry"
         details={clusterStatus?.registry?.host}
       />
+      <ClusterProperty
+        displayName="Last reconnected"
+        details={formatReconnectedAt(clusterStatus?.reconnectedAt)}
+      />
     </ClusterPropertyList>
   )
 }
@@ -1,3 +1,4 @@
+import TuneIcon from "@material-ui/icons/Tune"
 import React, { Component, useMemo, useRef, useState } from "react"
 import styled from "styled-components"
 import { AnalyticsAction, AnalyticsType, incr } from "./analytics"
@@ -18,6 +19,7 @@ import {
   mixinResetButtonStyle,
   SizeUnit,
 } from "./style-helpers"
+import TiltfileArgsDialog from "./TiltfileArgsDialog"
 import { Cluster } from "./types"
 import UpdateDialog from "./UpdateDialog"
 
@@ -178,6 +180,7 @@ export type GlobalNavProps = {
 // since it requires access to HUD state.
 enum NavDialog {
   Account = "account",
+  Args = "args",
   Cluster = "cluster",
   Help = "help",
   Update = "update",
@@ -185,6 +188,7 @@ enum NavDialog {
 
 const DIALOG_TO_ANALYTICS_TYPE = {
   [NavDialog.Account]: AnalyticsType.Account,
+  [NavDialog.Args]: AnalyticsType.Args,
   [NavDialog.Cluster]: AnalyticsType.Cluster,
   [NavDialog.Help]: AnalyticsType.Shortcut,
   [NavDialog.Update]: AnalyticsType.Update,
@@ -194,6 +198,7 @@ export function GlobalNav(props: GlobalNavProps) {
   const helpButton = useRef<HTMLButtonElement | null>(null)
   const accountButton = useRef<HTMLButtonElement | null>(null)
   const updateButton = useRef<HTMLButtonElement | null>(null)
+  const argsButton = useRef<HTMLButtonElement | null>(null)
   const clusterButton = useRef<HTMLButtonElement | null>(null)
   const snapshotButton = useRef<HTMLButtonElement | null>(null)
 
@@ -288,6 +293,20 @@ export function GlobalNav(props: GlobalNavProps) {
 
       {snapshotMenuItem}
 
+      <MenuButtonLabeled label="Args">
+        <MenuButton
+          ref={argsButton}
+          onClick={() => toggleDialog(NavDialog.Args)}
+          data-open={openDialog === NavDialog.Args}
+          aria-expanded={openDialog === NavDialog.Args}
+          aria-label="Tiltfile args"
+          aria-haspopup="true"
+          role="menuitem"
+        >
+          <TuneIcon />
+        </MenuButton>
+      </MenuButtonLabeled>
+
       <MenuButtonLabeled label="Help">
         <MenuButton
           ref={helpButton}
@@ -308,6 +327,11 @@ export function GlobalNav(props: GlobalNavProps) {
         anchorEl={clusterButton?.current}
         clusterConnection={defaultClusterInfo}
       />
+      <TiltfileArgsDialog
+        open={openDialog === NavDialog.Args}
+        anchorEl={argsButton?.current}
+        onClose={() => toggleDialog(NavDialog.Args)}
+      />
       <HelpDialog
         open={openDialog === NavDialog.Help}
         anchorEl={helpButton?.current}
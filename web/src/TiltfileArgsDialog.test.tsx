@@ -0,0 +1,164 @@
+import { render, screen, waitFor } from "@testing-library/react"
+import userEvent from "@testing-library/user-event"
+import fetchMock from "fetch-mock"
+import React from "react"
+import TiltfileArgsDialog, {
+  argsFromFormValues,
+  formValuesFromSchema,
+  TiltfileArg,
+  TiltfileArgsSchema,
+  validateArgValue,
+} from "./TiltfileArgsDialog"
+
+const ARGS: TiltfileArg[] = [
+  { name: "env", type: "choice", choices: ["dev", "prod"], default: "dev" },
+  { name: "replicas", type: "int", usage: "how many replicas to run" },
+  { name: "ratio", type: "float" },
+  { name: "debug", type: "bool" },
+  { name: "name", type: "string" },
+  { name: "limits", type: "object" },
+  { name: "resources", type: "list[string]", positional: true },
+]
+
+const SCHEMA: TiltfileArgsSchema = {
+  args: ARGS,
+  values: { env: ["prod"], resources: ["frontend", "backend"] },
+}
+
+const TEST_BUTTON = document.createElement("button")
+
+function renderDialog(onClose = () => {}) {
+  return render(
+    <TiltfileArgsDialog open={true} onClose={onClose} anchorEl={TEST_BUTTON} />
+  )
+}
+
+describe("argsFromFormValues", () => {
+  it("leaves out args without values", () => {
+    expect(argsFromFormValues(ARGS, {})).toEqual([])
+  })
+
+  it("turns typed values into flags and positional args", () => {
+    expect(
+      argsFromFormValues(ARGS, {
+        env: "prod",
+        replicas: " 3 ",
+        ratio: "0.5",
+        debug: "false",
+        name: "my app",
+        limits: '{ "cpu": 2 }',
+        resources: "frontend\n\n backend \n",
+      })
+    ).toEqual([
+      "--env=prod",
+      "--replicas=3",
+      "--ratio=0.5",
+      "--debug=false",
+      "--name=my app",
+      '--limits={"cpu":2}',
+      "frontend",
+      "backend",
+    ])
+  })
+
+  it("ends the flags before positional args that look like flags", () => {
+    expect(argsFromFormValues(ARGS, { resources: "-weird" })).toEqual([
+      "--",
+      "-weird",
+    ])
+  })
+})
+
+describe("validateArgValue", () => {
+  it.each([
+    ["int", "3", ""],
+    ["int", "3.5", "must be an integer"],
+    ["float", "3.5", ""],
+    ["float", "fast", "must be a number"],
+    ["object", '{"a": 1}', ""],
+    ["object", "{a: 1}", "must be valid JSON"],
+    ["int", "", ""],
+  ])("validates %s value %p", (type, value, expected) => {
+    expect(validateArgValue({ name: "x", type }, value)).toEqual(expected)
+  })
+})
+
+describe("formValuesFromSchema", () => {
+  it("fills in the current values", () => {
+    expect(formValuesFromSchema(SCHEMA)).toEqual({
+      env: "prod",
+      replicas: "",
+      ratio: "",
+      debug: "",
+      name: "",
+      limits: "",
+      resources: "frontend\nbackend",
+    })
+  })
+})
+
+describe("TiltfileArgsDialog", () => {
+  beforeEach(() => {
+    fetchMock.reset()
+    fetchMock.get("/api/tiltfile_args_schema", JSON.stringify(SCHEMA))
+    fetchMock.post("/api/set_tiltfile_args", 200)
+  })
+
+  afterEach(() => {
+    fetchMock.reset()
+  })
+
+  it("renders a typed input for each arg", async () => {
+    renderDialog()
+
+    const env = (await screen.findByLabelText(/^env/)) as HTMLSelectElement
+    expect(env.tagName).toEqual("SELECT")
+    expect(env.value).toEqual("prod")
+
+    const replicas = screen.getByLabelText(/^replicas/) as HTMLInputElement
+    expect(replicas.type).toEqual("number")
+    expect(replicas.step).toEqual("1")
+    expect(screen.getByText("how many replicas to run")).toBeInTheDocument()
+
+    const resources = screen.getByLabelText(/^resources/)
+    expect(resources.tagName).toEqual("TEXTAREA")
+  })
+
+  it("doesn't save invalid values", async () => {
+    renderDialog()
+
+    const limits = await screen.findByLabelText(/^limits/)
+    userEvent.type(limits, "not json")
+
+    expect(screen.getByRole("alert")).toHaveTextContent("must be valid JSON")
+    expect(screen.getByRole("button", { name: /save/i })).toBeDisabled()
+  })
+
+  it("saves the args", async () => {
+    const onClose = jest.fn()
+    renderDialog(onClose)
+
+    const replicas = await screen.findByLabelText(/^replicas/)
+    userEvent.type(replicas, "3")
+    userEvent.click(screen.getByRole("button", { name: /save/i }))
+
+    await waitFor(() => expect(onClose).toHaveBeenCalled())
+    const call = fetchMock.lastCall("/api/set_tiltfile_args")
+    expect(JSON.parse(call![1]!.body!.toString())).toEqual([
+      "--env=prod",
+      "--replicas=3",
+      "frontend",
+      "backend",
+    ])
+  })
+
+  it("says when there are no args", async () => {
+    fetchMock.reset()
+    fetchMock.get("/api/tiltfile_args_schema", JSON.stringify({ args: [] }))
+    renderDialog()
+
+    expect(
+      await screen.findByText(/doesn't define any args/)
+    ).toBeInTheDocument()
+  })
+})
@@ -0,0 +1,370 @@
+import React, { useEffect, useState } from "react"
+import styled from "styled-components"
+import FloatDialog, { HR } from "./FloatDialog"
+import {
+  Color,
+  Font,
+  FontSize,
+  mixinResetButtonStyle,
+  SizeUnit,
+} from "./style-helpers"
+
+// An arg defined in the Tiltfile with config.define_*.
+//
+// Matches model.TiltfileArg on the server.
+export type TiltfileArg = {
+  name: string
+  type: string // "string", "list[string]", "bool", "int", "float", "choice", or "object"
+  usage?: string
+  positional?: boolean
+  default?: any
+  choices?: string[]
+  schema?: any
+}
+
+// Matches model.TiltfileArgsSchema on the server.
+export type TiltfileArgsSchema = {
+  args: TiltfileArg[]
+  values?: { [name: string]: string[] }
+}
+
+// The text of each input, by arg name. An empty string means the arg
+// isn't set, so the Tiltfile uses its default.
+export type ArgFormValues = { [name: string]: string }
+
+type TiltfileArgsDialogProps = {
+  open: boolean
+  onClose: () => void
+  anchorEl: Element | null
+}
+
+const ArgField = styled.div`
+  display: flex;
+  flex-direction: column;
+  margin-bottom: ${SizeUnit(0.5)};
+`
+
+const ArgLabel = styled.label`
+  font-family: ${Font.monospace};
+  font-size: ${FontSize.small};
+`
+
+const ArgType = styled.span`
+  color: ${Color.gray50};
+  font-size: ${FontSize.smallest};
+  margin-left: ${SizeUnit(0.25)};
+`
+
+const ArgUsage = styled.div`
+  color: ${Color.gray50};
+  font-size: ${FontSize.smallest};
+  line-height: 1.4;
+`
+
+const inputMixin = `
+  font-family: ${Font.monospace};
+  font-size: ${FontSize.smallest};
+  border: 1px solid ${Color.gray70};
+  border-radius: 4px;
+  padding: 4px 8px;
+  margin-top: 4px;
+
+  &[aria-invalid="true"] {
+    border-color: ${Color.red};
+  }
+`
+
+const ArgInput = styled.input`
+  ${inputMixin}
+`
+
+const ArgSelect = styled.select`
+  ${inputMixin}
+`
+
+const ArgTextArea = styled.textarea`
+  ${inputMixin}
+  resize: vertical;
+`
+
+const ArgError = styled.div`
+  color: ${Color.red};
+  font-size: ${FontSize.smallest};
+  line-height: 1.4;
+`
+
+const SaveButton = styled.button`
+  ${mixinResetButtonStyle};
+  background-color: ${Color.gray30};
+  border-radius: 4px;
+  color: ${Color.white};
+  font-family: ${Font.monospace};
+  font-size: ${FontSize.smallest};
+  padding: 4px 12px;
+
+  &:disabled {
+    opacity: 0.33;
+  }
+`
+
+export async function fetchTiltfileArgsSchema(): Promise<TiltfileArgsSchema> {
+  const resp = await fetch("/api/tiltfile_args_schema")
+  if (!resp.ok) {
+    throw await resp.text()
+  }
+  return await resp.json()
+}
+
+export async function setTiltfileArgs(args: string[]) {
+  const resp = await fetch("/api/set_tiltfile_args", {
+    method: "post",
+    body: JSON.stringify(args),
+  })
+  if (!resp.ok) {
+    throw await resp.text()
+  }
+}
+
+function isList(arg: TiltfileArg): boolean {
+  return arg.type === "list[string]"
+}
+
+// The form values for the args that Tilt is running with.
+export function formValuesFromSchema(
+  schema: TiltfileArgsSchema
+): ArgFormValues {
+  const result: ArgFormValues = {}
+  schema.args.forEach((arg) => {
+    const values = schema.values?.[arg.name] ?? []
+    if (isList(arg)) {
+      result[arg.name] = values.join("\n")
+    } else {
+      // Like config.parse(), the last value wins.
+      result[arg.name] = values.length ? values[values.length - 1] : ""
+    }
+  })
+  return result
+}
+
+// Returns an error message if the value can't be parsed as the arg's type.
+export function validateArgValue(arg: TiltfileArg, value: string): string {
+  if (value === "") {
+    return ""
+  }
+  switch (arg.type) {
+    case "int":
+      return /^[-+]?\d+$/.test(value.trim()) ? "" : "must be an integer"
+    case "float":
+      return value.trim() !== "" && isFinite(Number(value))
+        ? ""
+        : "must be a number"
+    case "object":
+      try {
+        JSON.parse(value)
+        return ""
+      } catch (e) {
+        return "must be valid JSON"
+      }
+  }
+  return ""
+}
+
+// Turns the form values into Tiltfile args, e.g., ["--env=prod", "frontend"].
+//
+// Args without a value are left out, so the Tiltfile uses their defaults.
+export function argsFromFormValues(
+  args: TiltfileArg[],
+  values: ArgFormValues
+): string[] {
+  const flags: string[] = []
+  const positional: string[] = []
+  args.forEach((arg) => {
+    const value = values[arg.name] ?? ""
+    let argValues: string[]
+    if (isList(arg)) {
+      argValues = value
+        .split("\n")
+        .map((v) => v.trim())
+        .filter((v) => v !== "")
+    } else if (value === "") {
+      argValues = []
+    } else if (arg.type === "object") {
+      // Compact the JSON, so that it's easy to read in `tilt args`.
+      argValues = [JSON.stringify(JSON.parse(value))]
+    } else if (arg.type === "int" || arg.type === "float") {
+      argValues = [value.trim()]
+    } else {
+      argValues = [value]
+    }
+
+    if (arg.positional) {
+      positional.push(...argValues)
+    } else {
+      argValues.forEach((v) => flags.push(`--${arg.name}=${v}`))
+    }
+  })
+
+  if (positional.some((v) => v.startsWith("-"))) {
+    // Make sure config.parse() doesn't read positional values as flags.
+    flags.push("--")
+  }
+  return flags.concat(positional)
+}
+
+function argTypeLabel(arg: TiltfileArg): string {
+  let label = arg.type
+  if (arg.positional) {
+    label += ", positional"
+  }
+  if (arg.default !== undefined) {
+    label += `, default ${JSON.stringify(arg.default)}`
+  }
+  return label
+}
+
+function ArgInputField(props: {
+  arg: TiltfileArg
+  value: string
+  error: string
+  onChange: (value: string) => void
+}) {
+  const { arg, value, error, onChange } = props
+  const id = `tiltfile-arg-${arg.name}`
+  const common = {
+    id,
+    value,
+    "aria-invalid": error !== "",
+    onChange: (e: React.ChangeEvent<any>) => onChange(e.target.value),
+  }
+
+  let input: JSX.Element
+  switch (arg.type) {
+    case "bool":
+      input = (
+        <ArgSelect {...common}>
+          <option value="">(default)</option>
+          <option value="true">true</option>
+          <option value="false">false</option>
+        </ArgSelect>
+      )
+      break
+    case "choice":
+      input = (
+        <ArgSelect {...common}>
+          <option value="">(default)</option>
+          {(arg.choices ?? []).map((c) => (
+            <option key={c} value={c}>
+              {c}
+            </option>
+          ))}
+        </ArgSelect>
+      )
+      break
+    case "int":
+      input = <ArgInput {...common} type="number" step="1" />
+      break
+    case "float":
+      input = <ArgInput {...common} type="number" step="any" />
+      break
+    case "list[string]":
+      input = <ArgTextArea {...common} rows={3} placeholder="One per line" />
+      break
+    case "object":
+      input = <ArgTextArea {...common} rows={3} placeholder="JSON" />
+      break
+    default:
+      input = <ArgInput {...common} type="text" />
+  }
+
+  return (
+    <ArgField>
+      <ArgLabel htmlFor={id}>
+        {arg.name}
+        <ArgType>({argTypeLabel(arg)})</ArgType>
+      </ArgLabel>
+      {arg.usage && <ArgUsage>{arg.usage}</ArgUsage>}
+      {input}
+      {error && <ArgError role="alert">{error}</ArgError>}
+    </ArgField>
+  )
+}
+
+// Edits the Tiltfile args with an input for each arg that the Tiltfile
+// defines, like `tilt args`, but typed.
+export default function TiltfileArgsDialog(props: TiltfileArgsDialogProps) {
+  const [schema, setSchema] = useState<TiltfileArgsSchema | null>(null)
+  const [values, setValues] = useState<ArgFormValues>({})
+  const [error, setError] = useState("")
+
+  useEffect(() => {
+    if (!props.open) {
+      return
+    }
+
+    let cancelled = false
+    setError("")
+    fetchTiltfileArgsSchema()
+      .then((schema) => {
+        if (!cancelled) {
+          setSchema(schema)
+          setValues(formValuesFromSchema(schema))
+        }
+      })
+      .catch((err) => {
+        if (!cancelled) {
+          setError(`Error loading Tiltfile args: ${err}`)
+        }
+      })
+    return () => {
+      cancelled = true
+    }
+  }, [props.open])
+
+  const args = schema?.args ?? []
+  const errors: ArgFormValues = {}
+  args.forEach((arg) => {
+    errors[arg.name] = validateArgValue(arg, values[arg.name] ?? "")
+  })
+  const isValid = Object.values(errors).every((e) => e === "")
+
+  const onSave = (e: React.FormEvent) => {
+    e.preventDefault()
+    setTiltfileArgs(argsFromFormValues(args, values))
+      .then(() => props.onClose())
+      .catch((err) => setError(`Error setting Tiltfile args: ${err}`))
+  }
+
+  let content: JSX.Element | null = null
+  if (schema && args.length === 0) {
+    content = (
+      <div>
+        This Tiltfile doesn't define any args with config.define_*.
+      </div>
+    )
+  } else if (schema) {
+    content = (
+      <form onSubmit={onSave}>
+        {args.map((arg) => (
+          <ArgInputField
+            key={arg.name}
+            arg={arg}
+            value={values[arg.name] ?? ""}
+            error={errors[arg.name]}
+            onChange={(value) => setValues({ ...values, [arg.name]: value })}
+          />
+        ))}
+        <HR />
+        <SaveButton type="submit" disabled={!isValid}>
+          Save and reload Tiltfile
+        </SaveButton>
+      </form>
+    )
+  }
+
+  return (
+    <FloatDialog id="tiltfile-args" title="Tiltfile Args" {...props}>
+      {content}
+      {error && <ArgError role="alert">{error}</ArgError>}
+    </FloatDialog>
+  )
+}
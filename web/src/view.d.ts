@@ -932,6 +932,33 @@ declare namespace Proto {
      * +optional
      */
     version?: string;
+    /**
+     * ReconnectedAt is the last time the connection came back after
+     * failing health checks (e.g., after the laptop woke from sleep).
+     *
+     * When this happens, ConnectedAt is bumped too, so that watches, port
+     * forwards, and log streams on the cluster are re-established.
+     *
+     * +optional
+     */
+    reconnectedAt?: string;
+  }
+  export interface v1alpha1BuildkitConnection {
+    /**
+     * The address of the buildkitd gRPC API.
+     *
+     * Examples: unix:///run/buildkit/buildkitd.sock, tcp://127.0.0.1:1234
+     */
+    address?: string;
+    /**
+     * Whether the daemon stores images where the cluster's container runtime
+     * can see them (e.g., buildkitd running on the cluster's containerd).
+     *
+     * If true, Tilt skips the registry push.
+     *
+     * +optional
+     */
+    sharesClusterRuntime?: boolean;
   }
   export interface v1alpha1ClusterSpec {
     /**
@@ -950,6 +977,16 @@ declare namespace Proto {
      * +optional
      */
     defaultRegistry?: v1alpha1RegistryHosting;
+    /**
+     * Buildkit configures a standalone BuildKit daemon to build images
+     * for this Cluster, instead of building through the Docker API.
+     *
+     * If not specified, falls back to the TILT_BUILDKIT_HOST env variable.
+     * If neither is set, images are built with Docker.
+     *
+     * +optional
+     */
+    buildkit?: v1alpha1BuildkitConnection;
   }
   export interface v1alpha1ClusterConnectionStatus {
     /**